@@ -41,6 +41,8 @@ import (
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
 	"github.com/openyurtio/openyurt/pkg/util/profile"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/poolcreation"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/tokenexchange"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/webhook"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/webhook/util"
 )
@@ -184,6 +186,18 @@ func Run(c *config.CompletedConfig, stopCh <-chan struct{}) error {
 		os.Exit(1)
 	}
 
+	setupLog.Info("setup token exchange")
+	if err = tokenexchange.SetupWithManager(c, mgr); err != nil {
+		setupLog.Error(err, "unable to setup token exchange")
+		os.Exit(1)
+	}
+
+	setupLog.Info("setup nodepool creation")
+	if err = poolcreation.SetupWithManager(c, mgr); err != nil {
+		setupLog.Error(err, "unable to setup nodepool creation")
+		os.Exit(1)
+	}
+
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("initialize webhook")
 	if err := webhook.Initialize(ctx, c, mgr.GetConfig()); err != nil {
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openyurtio/openyurt/pkg/util/spiffe"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
+)
+
+type CsrApproverControllerOptions struct {
+	*config.CsrApproverControllerConfiguration
+}
+
+func NewCsrApproverControllerOptions() *CsrApproverControllerOptions {
+	return &CsrApproverControllerOptions{
+		&config.CsrApproverControllerConfiguration{
+			NodeApprovalsPerHour:    10,
+			ClusterApprovalsPerHour: 300,
+		},
+	}
+}
+
+// AddFlags adds flags related to csrapprover for yurt-manager to the specified FlagSet.
+func (o *CsrApproverControllerOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+
+	fs.StringSliceVar(&o.SignerNames, "csrapprover-signer-names", o.SignerNames, "the additional signers whose csrs requested by nodes for their own identities "+
+		"(system:node:<node-name> in organization system:nodes) are auto approved, signers of kubernetes(kubernetes.io/*) are not allowed, "+
+		"yurt-manager should be granted to approve these signers.")
+	fs.StringVar(&o.RulesConfigMap, "csrapprover-rules-configmap", o.RulesConfigMap, "the name of ConfigMap in the working namespace which holds the approval rules "+
+		"of additional signers in the data key rules, the rules of kubernetes signers(kubernetes.io/*) are ignored, "+
+		"yurt-manager should be granted to approve these signers.")
+	fs.IntVar(&o.NodeApprovalsPerHour, "csrapprover-node-approvals-per-hour", o.NodeApprovalsPerHour, "the max number of csrs auto approved for a requester in an hour, "+
+		"the csrs of bootstrap tokens are limited by the token, so nodes joining in batch should use separate tokens or raise the limit. the approvals are not limited if it's 0.")
+	fs.IntVar(&o.ClusterApprovalsPerHour, "csrapprover-cluster-approvals-per-hour", o.ClusterApprovalsPerHour, "the max number of csrs auto approved in the cluster in an hour, "+
+		"the approvals are not limited if it's 0.")
+	fs.StringVar(&o.SPIFFETrustDomain, "csrapprover-spiffe-trust-domain", o.SPIFFETrustDomain, "the trust domain of SPIFFE identities registered for edge components, "+
+		"only the node and nodepool scoped identities of the requesting node are approved, and csrs with SPIFFE identities are not approved if it's empty. "+
+		"X509-SVIDs of edge components are approved under signer openyurt.io/spiffe-svid, which should be signed by the signer of the trust domain.")
+}
+
+// ApplyTo fills up csrapprover config with options.
+func (o *CsrApproverControllerOptions) ApplyTo(cfg *config.CsrApproverControllerConfiguration) error {
+	if o == nil {
+		return nil
+	}
+	cfg.SignerNames = o.SignerNames
+	cfg.RulesConfigMap = o.RulesConfigMap
+	cfg.NodeApprovalsPerHour = o.NodeApprovalsPerHour
+	cfg.ClusterApprovalsPerHour = o.ClusterApprovalsPerHour
+	cfg.SPIFFETrustDomain = o.SPIFFETrustDomain
+	return nil
+}
+
+// Validate checks validation of CsrApproverControllerOptions.
+func (o *CsrApproverControllerOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errs := []error{}
+	for _, signer := range o.SignerNames {
+		if len(strings.TrimSpace(signer)) == 0 || !strings.Contains(signer, "/") {
+			errs = append(errs, fmt.Errorf("csrapprover-signer-names %q should be qualified like example.com/signer", signer))
+		} else if strings.HasPrefix(signer, config.ReservedSignerPrefix) {
+			errs = append(errs, fmt.Errorf("csrapprover-signer-names %q should not be a signer of kubernetes", signer))
+		}
+	}
+	if len(o.RulesConfigMap) != 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(o.RulesConfigMap) {
+			errs = append(errs, fmt.Errorf("csrapprover-rules-configmap %q is invalid, %s", o.RulesConfigMap, msg))
+		}
+	}
+	if o.NodeApprovalsPerHour < 0 {
+		errs = append(errs, fmt.Errorf("csrapprover-node-approvals-per-hour should not be negative"))
+	}
+	if o.ClusterApprovalsPerHour < 0 {
+		errs = append(errs, fmt.Errorf("csrapprover-cluster-approvals-per-hour should not be negative"))
+	}
+	if len(o.SPIFFETrustDomain) != 0 {
+		if err := spiffe.ValidateTrustDomain(o.SPIFFETrustDomain); err != nil {
+			errs = append(errs, fmt.Errorf("csrapprover-spiffe-trust-domain is invalid, %v", err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaycertificate/config"
+)
+
+type GatewayCertificateControllerOptions struct {
+	*config.GatewayCertificateControllerConfiguration
+}
+
+func NewGatewayCertificateControllerOptions() *GatewayCertificateControllerOptions {
+	return &GatewayCertificateControllerOptions{
+		&config.GatewayCertificateControllerConfiguration{
+			IssuerKind:  "ClusterIssuer",
+			IssuerGroup: "cert-manager.io",
+		},
+	}
+}
+
+// AddFlags adds flags related to gateway certificate for yurt-manager to the specified FlagSet.
+func (o *GatewayCertificateControllerOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+
+	fs.StringVar(&o.IssuerName, "raven-cert-issuer-name", o.IssuerName, "the name of cert-manager issuer which signs the certificates of raven proxy, "+
+		"the built-in certificates of raven are used if it's empty.")
+	fs.StringVar(&o.IssuerKind, "raven-cert-issuer-kind", o.IssuerKind, "the kind of cert-manager issuer, Issuer in the working namespace or ClusterIssuer.")
+	fs.StringVar(&o.IssuerGroup, "raven-cert-issuer-group", o.IssuerGroup, "the api group of cert-manager issuer.")
+}
+
+// ApplyTo fills up gateway certificate config with options.
+func (o *GatewayCertificateControllerOptions) ApplyTo(cfg *config.GatewayCertificateControllerConfiguration) error {
+	if o == nil {
+		return nil
+	}
+	cfg.IssuerName = o.IssuerName
+	cfg.IssuerKind = o.IssuerKind
+	cfg.IssuerGroup = o.IssuerGroup
+	return nil
+}
+
+// Validate checks validation of GatewayCertificateControllerOptions.
+func (o *GatewayCertificateControllerOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errs := []error{}
+	if len(o.IssuerName) != 0 && len(o.IssuerKind) == 0 {
+		errs = append(errs, fmt.Errorf("raven-cert-issuer-kind should be specified with raven-cert-issuer-name"))
+	}
+	return errs
+}
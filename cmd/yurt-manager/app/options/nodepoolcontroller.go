@@ -17,7 +17,12 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"net/url"
+	"time"
+
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool/config"
 )
@@ -30,6 +35,7 @@ func NewNodePoolControllerOptions() *NodePoolControllerOptions {
 	return &NodePoolControllerOptions{
 		&config.NodePoolControllerConfiguration{
 			EnableSyncNodePoolConfigurations: true,
+			AutoscalingCooldown:              metav1.Duration{Duration: 5 * time.Minute},
 		},
 	}
 }
@@ -41,6 +47,11 @@ func (n *NodePoolControllerOptions) AddFlags(fs *pflag.FlagSet) {
 	}
 
 	fs.BoolVar(&n.EnableSyncNodePoolConfigurations, "enable-sync-nodepool-configurations", n.EnableSyncNodePoolConfigurations, "enable to sync nodepool configurations(including labels, annotations, taints in spec) to nodes in the nodepool.")
+	fs.StringVar(&n.AutoscalingProviderEndpoint, "nodepool-autoscaling-provider-endpoint", n.AutoscalingProviderEndpoint, "the http(s) endpoint of external provisioner which adds or removes nodes for nodepools with spec.desiredSize, "+
+		"pending pods trigger adding nodes only for nodepools with spec.maxSize, and nodes are drained before removed. nodepool autoscaling is disabled if it's empty.")
+	fs.DurationVar(&n.AutoscalingCooldown.Duration, "nodepool-autoscaling-cooldown", n.AutoscalingCooldown.Duration, "the minimum interval between two scaling requests for the same nodepool.")
+	fs.StringVar(&n.NodeServantImage, "node-servant-image", n.NodeServantImage, "the image of node-servant for applying node configurations of nodepool on nodes, node configurations are only rendered into ConfigMaps if it's empty.")
+	fs.BoolVar(&n.EnableDisruptionBudget, "enable-nodepool-disruption-budget", n.EnableDisruptionBudget, "enable to create and maintain PodDisruptionBudgets for the workloads selected by spec.disruptionBudget of nodepools.")
 }
 
 // ApplyTo fills up nodepool config with options.
@@ -49,6 +60,10 @@ func (o *NodePoolControllerOptions) ApplyTo(cfg *config.NodePoolControllerConfig
 		return nil
 	}
 	cfg.EnableSyncNodePoolConfigurations = o.EnableSyncNodePoolConfigurations
+	cfg.AutoscalingProviderEndpoint = o.AutoscalingProviderEndpoint
+	cfg.AutoscalingCooldown = o.AutoscalingCooldown
+	cfg.NodeServantImage = o.NodeServantImage
+	cfg.EnableDisruptionBudget = o.EnableDisruptionBudget
 
 	return nil
 }
@@ -59,5 +74,13 @@ func (o *NodePoolControllerOptions) Validate() []error {
 		return nil
 	}
 	errs := []error{}
+	if len(o.AutoscalingProviderEndpoint) != 0 {
+		if u, err := url.Parse(o.AutoscalingProviderEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("nodepool-autoscaling-provider-endpoint %s should be a http(s) url", o.AutoscalingProviderEndpoint))
+		}
+	}
+	if o.AutoscalingCooldown.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("nodepool-autoscaling-cooldown should be positive"))
+	}
 	return errs
 }
@@ -25,28 +25,36 @@ import (
 
 // YurtManagerOptions is the main context object for the yurt-manager.
 type YurtManagerOptions struct {
-	Generic                    *GenericOptions
-	NodePoolController         *NodePoolControllerOptions
-	GatewayPickupController    *GatewayPickupControllerOptions
-	YurtStaticSetController    *YurtStaticSetControllerOptions
-	YurtAppSetController       *YurtAppSetControllerOptions
-	YurtAppDaemonController    *YurtAppDaemonControllerOptions
-	PlatformAdminController    *PlatformAdminControllerOptions
-	YurtAppOverriderController *YurtAppOverriderControllerOptions
+	Generic                      *GenericOptions
+	NodePoolController           *NodePoolControllerOptions
+	GatewayPickupController      *GatewayPickupControllerOptions
+	YurtStaticSetController      *YurtStaticSetControllerOptions
+	YurtAppSetController         *YurtAppSetControllerOptions
+	YurtAppDaemonController      *YurtAppDaemonControllerOptions
+	PlatformAdminController      *PlatformAdminControllerOptions
+	YurtAppOverriderController   *YurtAppOverriderControllerOptions
+	TokenExchange                *TokenExchangeOptions
+	NodePoolCreation             *NodePoolCreationOptions
+	CsrApproverController        *CsrApproverControllerOptions
+	GatewayCertificateController *GatewayCertificateControllerOptions
 }
 
 // NewYurtManagerOptions creates a new YurtManagerOptions with a default config.
 func NewYurtManagerOptions() (*YurtManagerOptions, error) {
 
 	s := YurtManagerOptions{
-		Generic:                    NewGenericOptions(),
-		NodePoolController:         NewNodePoolControllerOptions(),
-		GatewayPickupController:    NewGatewayPickupControllerOptions(),
-		YurtStaticSetController:    NewYurtStaticSetControllerOptions(),
-		YurtAppSetController:       NewYurtAppSetControllerOptions(),
-		YurtAppDaemonController:    NewYurtAppDaemonControllerOptions(),
-		PlatformAdminController:    NewPlatformAdminControllerOptions(),
-		YurtAppOverriderController: NewYurtAppOverriderControllerOptions(),
+		Generic:                      NewGenericOptions(),
+		NodePoolController:           NewNodePoolControllerOptions(),
+		GatewayPickupController:      NewGatewayPickupControllerOptions(),
+		YurtStaticSetController:      NewYurtStaticSetControllerOptions(),
+		YurtAppSetController:         NewYurtAppSetControllerOptions(),
+		YurtAppDaemonController:      NewYurtAppDaemonControllerOptions(),
+		PlatformAdminController:      NewPlatformAdminControllerOptions(),
+		YurtAppOverriderController:   NewYurtAppOverriderControllerOptions(),
+		TokenExchange:                NewTokenExchangeOptions(),
+		NodePoolCreation:             NewNodePoolCreationOptions(),
+		CsrApproverController:        NewCsrApproverControllerOptions(),
+		GatewayCertificateController: NewGatewayCertificateControllerOptions(),
 	}
 
 	return &s, nil
@@ -61,6 +69,10 @@ func (y *YurtManagerOptions) Flags(allControllers, disabledByDefaultControllers
 	y.YurtAppDaemonController.AddFlags(fss.FlagSet("yurtappdaemon controller"))
 	y.PlatformAdminController.AddFlags(fss.FlagSet("iot controller"))
 	y.YurtAppOverriderController.AddFlags(fss.FlagSet("yurtappoverrider controller"))
+	y.TokenExchange.AddFlags(fss.FlagSet("token exchange"))
+	y.NodePoolCreation.AddFlags(fss.FlagSet("nodepool creation"))
+	y.CsrApproverController.AddFlags(fss.FlagSet("csrapprover controller"))
+	y.GatewayCertificateController.AddFlags(fss.FlagSet("gateway certificate controller"))
 	// Please Add Other controller flags @kadisi
 
 	return fss
@@ -76,6 +88,10 @@ func (y *YurtManagerOptions) Validate(allControllers []string, controllerAliases
 	errs = append(errs, y.YurtAppDaemonController.Validate()...)
 	errs = append(errs, y.PlatformAdminController.Validate()...)
 	errs = append(errs, y.YurtAppOverriderController.Validate()...)
+	errs = append(errs, y.TokenExchange.Validate()...)
+	errs = append(errs, y.NodePoolCreation.Validate()...)
+	errs = append(errs, y.CsrApproverController.Validate()...)
+	errs = append(errs, y.GatewayCertificateController.Validate()...)
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -102,6 +118,18 @@ func (y *YurtManagerOptions) ApplyTo(c *config.Config, controllerAliases map[str
 	if err := y.GatewayPickupController.ApplyTo(&c.ComponentConfig.GatewayPickupController); err != nil {
 		return err
 	}
+	if err := y.TokenExchange.ApplyTo(&c.ComponentConfig.TokenExchange); err != nil {
+		return err
+	}
+	if err := y.NodePoolCreation.ApplyTo(&c.ComponentConfig.NodePoolCreation); err != nil {
+		return err
+	}
+	if err := y.CsrApproverController.ApplyTo(&c.ComponentConfig.CsrApproverController); err != nil {
+		return err
+	}
+	if err := y.GatewayCertificateController.ApplyTo(&c.ComponentConfig.GatewayCertificateController); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/poolcreation/config"
+)
+
+type NodePoolCreationOptions struct {
+	*config.NodePoolCreationConfiguration
+}
+
+func NewNodePoolCreationOptions() *NodePoolCreationOptions {
+	return &NodePoolCreationOptions{
+		&config.NodePoolCreationConfiguration{
+			AllowedGroups: []string{"system:bootstrappers"},
+		},
+	}
+}
+
+// AddFlags adds flags related to nodepool creation for yurt-manager to the specified FlagSet.
+func (o *NodePoolCreationOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+
+	fs.StringVar(&o.TemplatesConfigMap, "nodepool-creation-templates-configmap", o.TemplatesConfigMap, "the namespace/name of configmap which contains the nodepool templates for joining nodes, nodepool creation is disabled if it's empty.")
+	fs.StringSliceVar(&o.AllowedGroups, "nodepool-creation-allowed-groups", o.AllowedGroups, "the groups of users who are allowed to create nodepools from templates.")
+}
+
+// ApplyTo fills up nodepool creation config with options.
+func (o *NodePoolCreationOptions) ApplyTo(cfg *config.NodePoolCreationConfiguration) error {
+	if o == nil {
+		return nil
+	}
+	cfg.TemplatesConfigMap = o.TemplatesConfigMap
+	cfg.AllowedGroups = o.AllowedGroups
+
+	return nil
+}
+
+// Validate checks validation of NodePoolCreationOptions.
+func (o *NodePoolCreationOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errs := []error{}
+	if len(o.TemplatesConfigMap) != 0 {
+		if parts := strings.Split(o.TemplatesConfigMap, "/"); len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			errs = append(errs, fmt.Errorf("nodepool-creation-templates-configmap %s should be in the format of namespace/name", o.TemplatesConfigMap))
+		}
+		if len(o.AllowedGroups) == 0 {
+			errs = append(errs, fmt.Errorf("nodepool-creation-allowed-groups should be specified with nodepool-creation-templates-configmap"))
+		}
+	}
+	return errs
+}
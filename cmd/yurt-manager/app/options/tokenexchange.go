@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/tokenexchange/config"
+)
+
+type TokenExchangeOptions struct {
+	*config.TokenExchangeConfiguration
+}
+
+func NewTokenExchangeOptions() *TokenExchangeOptions {
+	return &TokenExchangeOptions{
+		&config.TokenExchangeConfiguration{
+			InstanceIdentityMaxAge: metav1.Duration{Duration: time.Hour},
+			TokenTTL:               metav1.Duration{Duration: 15 * time.Minute},
+		},
+	}
+}
+
+// AddFlags adds flags related to token exchange for yurt-manager to the specified FlagSet.
+func (o *TokenExchangeOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+
+	fs.StringVar(&o.OIDCIssuerURL, "token-exchange-oidc-issuer-url", o.OIDCIssuerURL, "the issuer url of OIDC id tokens which can be exchanged for bootstrap tokens, OIDC id tokens are not accepted if it's empty.")
+	fs.StringSliceVar(&o.OIDCAudiences, "token-exchange-oidc-audiences", o.OIDCAudiences, "the accepted audiences of OIDC id tokens.")
+	fs.StringVar(&o.InstanceIdentityCertFile, "token-exchange-instance-identity-cert-file", o.InstanceIdentityCertFile, "the PEM file of certificates which sign cloud instance identity documents, instance identity documents are not accepted if it's empty.")
+	fs.StringSliceVar(&o.InstanceIdentityAccounts, "token-exchange-instance-identity-accounts", o.InstanceIdentityAccounts, "the cloud accounts whose instances are allowed to join, it's required with token-exchange-instance-identity-cert-file.")
+	fs.DurationVar(&o.InstanceIdentityMaxAge.Duration, "token-exchange-instance-identity-max-age", o.InstanceIdentityMaxAge.Duration, "the max age of instances which are allowed to join by instance identity documents, "+
+		"the age is counted from the pending time in document, and the node should be named after the instance id.")
+	fs.DurationVar(&o.TokenTTL.Duration, "token-exchange-token-ttl", o.TokenTTL.Duration, "the lifetime of bootstrap tokens exchanged for external identities.")
+}
+
+// ApplyTo fills up token exchange config with options.
+func (o *TokenExchangeOptions) ApplyTo(cfg *config.TokenExchangeConfiguration) error {
+	if o == nil {
+		return nil
+	}
+	cfg.OIDCIssuerURL = o.OIDCIssuerURL
+	cfg.OIDCAudiences = o.OIDCAudiences
+	cfg.InstanceIdentityCertFile = o.InstanceIdentityCertFile
+	cfg.InstanceIdentityAccounts = o.InstanceIdentityAccounts
+	cfg.InstanceIdentityMaxAge = o.InstanceIdentityMaxAge
+	cfg.TokenTTL = o.TokenTTL
+
+	return nil
+}
+
+// Validate checks validation of TokenExchangeOptions.
+func (o *TokenExchangeOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errs := []error{}
+	if len(o.OIDCIssuerURL) != 0 {
+		if u, err := url.Parse(o.OIDCIssuerURL); err != nil || u.Scheme != "https" {
+			errs = append(errs, fmt.Errorf("token-exchange-oidc-issuer-url %s should be a https url", o.OIDCIssuerURL))
+		}
+		if len(o.OIDCAudiences) == 0 {
+			errs = append(errs, fmt.Errorf("token-exchange-oidc-audiences should be specified with token-exchange-oidc-issuer-url"))
+		}
+	}
+	if len(o.InstanceIdentityCertFile) != 0 {
+		if len(o.InstanceIdentityAccounts) == 0 {
+			errs = append(errs, fmt.Errorf("token-exchange-instance-identity-accounts should be specified with token-exchange-instance-identity-cert-file"))
+		}
+		if o.InstanceIdentityMaxAge.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("token-exchange-instance-identity-max-age should be positive"))
+		}
+	}
+	if o.TokenTTL.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("token-exchange-token-ttl should be positive"))
+	}
+	return errs
+}
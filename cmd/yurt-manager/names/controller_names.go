@@ -34,6 +34,11 @@ const (
 	GatewayInternalServiceController       = "gateway-internal-service-controller"
 	GatewayPublicServiceController         = "gateway-public-service"
 	GatewayDNSController                   = "gateway-dns-controller"
+	GatewayCertificateController           = "gateway-certificate-controller"
+	NodeMigrationController                = "node-migration-controller"
+	LoadBalancerSetController              = "load-balancer-set-controller"
+	ExternalTrafficPolicyController        = "external-traffic-policy-controller"
+	ServiceVariantController               = "service-variant-controller"
 )
 
 func YurtManagerControllerAliases() map[string]string {
@@ -56,5 +61,10 @@ func YurtManagerControllerAliases() map[string]string {
 		"gatewayinternalservice":        GatewayInternalServiceController,
 		"gatewaypublicservice":          GatewayPublicServiceController,
 		"gatewaydns":                    GatewayDNSController,
+		"gatewaycertificate":            GatewayCertificateController,
+		"nodemigration":                 NodeMigrationController,
+		"loadbalancerset":               LoadBalancerSetController,
+		"externaltrafficpolicy":         ExternalTrafficPolicyController,
+		"servicevariant":                ServiceVariantController,
 	}
 }
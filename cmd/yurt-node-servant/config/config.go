@@ -33,10 +33,11 @@ func NewConfigCmd() *cobra.Command {
 		Use:       "config",
 		Short:     "manage configuration of OpenYurt cluster",
 		RunE:      cobra.OnlyValidArgs,
-		ValidArgs: []string{"control-plane"},
+		ValidArgs: []string{"control-plane", "node"},
 		Args:      cobra.MaximumNArgs(1),
 	}
 	cmd.AddCommand(newCmdConfigControlPlane())
+	cmd.AddCommand(newCmdConfigNode())
 
 	return cmd
 }
@@ -76,3 +77,35 @@ func newCmdConfigControlPlane() *cobra.Command {
 	o.AddFlags(cmd.Flags())
 	return cmd
 }
+
+func newCmdConfigNode() *cobra.Command {
+	o := config.NewNodeConfigOptions()
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "apply the node configurations of nodepool, like kubelet flags, sysctls and registry settings",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Printf("node-servant version: %#v\n", projectinfo.Get())
+			if o.Version {
+				return nil
+			}
+
+			cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+				klog.Infof("FLAG: --%s=%q", flag.Name, flag.Value)
+			})
+
+			if err := o.Validate(); err != nil {
+				klog.Fatalf("validate options: %v", err)
+			}
+
+			if err := config.NewNodeConfigRunner(o).Do(); err != nil {
+				return fmt.Errorf("failed to config node, %v", err)
+			}
+
+			klog.Info("node-servant config node success")
+			return nil
+		},
+		Args: cobra.NoArgs,
+	}
+	o.AddFlags(cmd.Flags())
+	return cmd
+}
@@ -18,6 +18,7 @@ package convert
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -48,6 +49,14 @@ func NewConvertCmd() *cobra.Command {
 			}
 
 			converter := nodeconverter.NewConverterWithOptions(o)
+			if o.DryRun() {
+				report := converter.Preflight(nodeName())
+				fmt.Print(report)
+				if !report.Ready() {
+					klog.Fatalf("node is not ready to be converted")
+				}
+				return
+			}
 			if err := converter.Do(); err != nil {
 				klog.Fatalf("fail to convert the kubernetes node to a yurt node: %s", err)
 			}
@@ -59,3 +68,12 @@ func NewConvertCmd() *cobra.Command {
 
 	return cmd
 }
+
+// nodeName returns the name of node that the job runs on, or the hostname if it's not specified.
+func nodeName() string {
+	if name := os.Getenv("NODE_NAME"); len(name) != 0 {
+		return name
+	}
+	name, _ := os.Hostname()
+	return name
+}
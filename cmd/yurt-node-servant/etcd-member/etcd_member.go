@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdmember
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	etcdmember "github.com/openyurtio/openyurt/pkg/node-servant/etcd-member"
+)
+
+// NewEtcdMemberCmd generates a new coordinator-etcd-member command
+func NewEtcdMemberCmd() *cobra.Command {
+	o := etcdmember.NewEtcdMemberOptions()
+	cmd := &cobra.Command{
+		Use:   "coordinator-etcd-member",
+		Short: "prepare the etcd member of yurt-coordinator pod for the etcd cluster of nodepool",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+				klog.Infof("FLAG: --%s=%q", flag.Name, flag.Value)
+			})
+
+			if err := o.Validate(); err != nil {
+				klog.Fatalf("Fail to validate coordinator-etcd-member args, %v", err)
+			}
+
+			if err := etcdmember.Run(o); err != nil {
+				klog.Fatalf("Fail to prepare etcd member, %v", err)
+			}
+			klog.Info("Etcd member is prepared")
+		},
+		Args: cobra.NoArgs,
+	}
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
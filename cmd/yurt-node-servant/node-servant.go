@@ -26,6 +26,8 @@ import (
 
 	"github.com/openyurtio/openyurt/cmd/yurt-node-servant/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-node-servant/convert"
+	etcdmember "github.com/openyurtio/openyurt/cmd/yurt-node-servant/etcd-member"
+	"github.com/openyurtio/openyurt/cmd/yurt-node-servant/prepull"
 	"github.com/openyurtio/openyurt/cmd/yurt-node-servant/revert"
 	upgrade "github.com/openyurtio/openyurt/cmd/yurt-node-servant/static-pod-upgrade"
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
@@ -49,6 +51,8 @@ func main() {
 	rootCmd.AddCommand(revert.NewRevertCmd())
 	rootCmd.AddCommand(config.NewConfigCmd())
 	rootCmd.AddCommand(upgrade.NewUpgradeCmd())
+	rootCmd.AddCommand(prepull.NewPrePullCmd())
+	rootCmd.AddCommand(etcdmember.NewEtcdMemberCmd())
 
 	if err := rootCmd.Execute(); err != nil { // run command
 		os.Exit(1)
@@ -17,6 +17,8 @@ limitations under the License.
 package revert
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
@@ -35,6 +37,14 @@ func NewRevertCmd() *cobra.Command {
 			}
 
 			r := revert.NewReverterWithOptions(o)
+			if o.DryRun() {
+				report := r.Preflight()
+				fmt.Print(report)
+				if !report.Ready() {
+					klog.Fatalf("node is not ready to be reverted")
+				}
+				return
+			}
 			if err := r.Do(); err != nil {
 				klog.Fatalf("fail to revert the yurt node to a kubernetes node: %s", err)
 			}
@@ -51,4 +61,6 @@ func NewRevertCmd() *cobra.Command {
 func setFlags(cmd *cobra.Command) {
 	cmd.Flags().String("kubeadm-conf-path", "",
 		"The path to kubelet service conf that is used by kubelet component to join the cluster on the edge node.")
+	cmd.Flags().Bool("dry-run", false,
+		"Only run the preflight checks and report whether the node is ready to be reverted, no changes are made.")
 }
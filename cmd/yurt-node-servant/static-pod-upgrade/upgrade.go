@@ -17,8 +17,13 @@ limitations under the License.
 package upgrade
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
 	upgrade "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade"
@@ -45,6 +50,13 @@ func NewUpgradeCmd() *cobra.Command {
 			}
 
 			if err = ctrl.Upgrade(); err != nil {
+				if errors.Is(err, upgrade.ErrRolledBack) {
+					// let the YurtStaticSet controller know the static pod is rolled back
+					msg := fmt.Sprintf("%s: %v", upgrade.RolledBackTerminationMessage, err)
+					if err := os.WriteFile(corev1.TerminationMessagePathDefault, []byte(msg), 0644); err != nil {
+						klog.Errorf("Fail to write termination message, %v", err)
+					}
+				}
 				klog.Fatalf("Fail to upgrade static pod, %v", err)
 			}
 
@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +48,7 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurthub/cachemanager"
 	"github.com/openyurtio/openyurt/pkg/yurthub/certificate"
 	certificatemgr "github.com/openyurtio/openyurt/pkg/yurthub/certificate/manager"
+	"github.com/openyurtio/openyurt/pkg/yurthub/faultinjection"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/manager"
 	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/meta"
 	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/serializer"
@@ -61,12 +63,14 @@ type YurtHubConfiguration struct {
 	RemoteServers                   []*url.URL
 	GCFrequency                     int
 	NodeName                        string
+	NodePoolName                    string
 	HeartbeatFailedRetry            int
 	HeartbeatHealthyThreshold       int
 	HeartbeatTimeoutSeconds         int
 	HeartbeatIntervalSeconds        int
 	MaxRequestInFlight              int
 	EnableProfiling                 bool
+	FaultInjector                   *faultinjection.Injector
 	StorageWrapper                  cachemanager.StorageWrapper
 	SerializerManager               *serializer.SerializerManager
 	RESTMapperManager               *meta.RESTMapperManager
@@ -77,13 +81,18 @@ type YurtHubConfiguration struct {
 	FilterManager                   *manager.Manager
 	CoordinatorServer               *url.URL
 	MinRequestTimeout               time.Duration
+	ShutdownGracePeriod             time.Duration
 	TenantNs                        string
 	NetworkMgr                      *network.NetworkManager
 	CertManager                     certificate.YurtCertificateManager
 	YurtHubServerServing            *apiserver.DeprecatedInsecureServingInfo
+	YurtHubSecondaryServerServing   *apiserver.DeprecatedInsecureServingInfo
 	YurtHubProxyServerServing       *apiserver.DeprecatedInsecureServingInfo
+	YurtHubSecondaryProxyServing    *apiserver.DeprecatedInsecureServingInfo
 	YurtHubDummyProxyServerServing  *apiserver.DeprecatedInsecureServingInfo
 	YurtHubSecureProxyServerServing *apiserver.SecureServingInfo
+	YurtHubSecondarySecureServing   *apiserver.SecureServingInfo
+	YurtHubJoinInfoServerServing    *apiserver.DeprecatedInsecureServingInfo
 	YurtHubProxyServerAddr          string
 	YurtHubNamespace                string
 	ProxiedClient                   kubernetes.Interface
@@ -93,8 +102,13 @@ type YurtHubConfiguration struct {
 	CoordinatorServerURL            *url.URL
 	CoordinatorStoragePrefix        string
 	CoordinatorStorageAddr          string // ip:port
+	CoordinatorStorageQuota         int64
 	CoordinatorClient               kubernetes.Interface
 	LeaderElection                  componentbaseconfig.LeaderElectionConfiguration
+	HubLeaderTerm                   time.Duration
+	DelegateLeaseJitter             time.Duration
+	DelegateLeaseQPS                float32
+	DelegateLeaseBurst              int
 }
 
 // Complete converts *options.YurtHubOptions to *YurtHubConfiguration
@@ -143,6 +157,7 @@ func Complete(options *options.YurtHubOptions) (*YurtHubConfiguration, error) {
 		RemoteServers:             us,
 		GCFrequency:               options.GCFrequency,
 		NodeName:                  options.NodeName,
+		NodePoolName:              options.NodePoolName,
 		HeartbeatFailedRetry:      options.HeartbeatFailedRetry,
 		HeartbeatHealthyThreshold: options.HeartbeatHealthyThreshold,
 		HeartbeatTimeoutSeconds:   options.HeartbeatTimeoutSeconds,
@@ -158,6 +173,7 @@ func Complete(options *options.YurtHubOptions) (*YurtHubConfiguration, error) {
 		KubeletHealthGracePeriod:  options.KubeletHealthGracePeriod,
 		FilterManager:             filterManager,
 		MinRequestTimeout:         options.MinRequestTimeout,
+		ShutdownGracePeriod:       options.ShutdownGracePeriod,
 		TenantNs:                  tenantNs,
 		YurtHubProxyServerAddr:    fmt.Sprintf("%s:%d", options.YurtHubProxyHost, options.YurtHubProxyPort),
 		YurtHubNamespace:          options.YurtHubNamespace,
@@ -168,7 +184,17 @@ func Complete(options *options.YurtHubOptions) (*YurtHubConfiguration, error) {
 		CoordinatorServerURL:      coordinatorServerURL,
 		CoordinatorStoragePrefix:  options.CoordinatorStoragePrefix,
 		CoordinatorStorageAddr:    options.CoordinatorStorageAddr,
+		CoordinatorStorageQuota:   options.CoordinatorStorageQuota,
 		LeaderElection:            options.LeaderElection,
+		HubLeaderTerm:             options.HubLeaderTerm,
+		DelegateLeaseJitter:       options.DelegateLeaseJitter,
+		DelegateLeaseQPS:          options.DelegateLeaseQPS,
+		DelegateLeaseBurst:        options.DelegateLeaseBurst,
+	}
+
+	if options.EnableFaultInjection {
+		klog.Warningf("fault injection is enabled, it should only be used for testing")
+		cfg.FaultInjector = faultinjection.NewInjector(us)
 	}
 
 	certMgr, err := certificatemgr.NewYurtHubCertManager(options, us)
@@ -314,6 +340,47 @@ func prepareServerServing(options *options.YurtHubOptions, certMgr certificate.Y
 		return err
 	}
 
+	// serve on the other ip family as well when dual-stack bind addresses are configured,
+	// so that clients on ipv6-only stacks(like kubelet configured with ::1) can reach yurthub.
+	if len(options.YurtHubSecondaryHost) != 0 {
+		if err := (&apiserveroptions.DeprecatedInsecureServingOptions{
+			BindAddress: net.ParseIP(options.YurtHubSecondaryHost),
+			BindPort:    options.YurtHubPort,
+			BindNetwork: "tcp",
+		}).ApplyTo(&cfg.YurtHubSecondaryServerServing); err != nil {
+			return err
+		}
+	}
+
+	if len(options.YurtHubSecondaryProxyHost) != 0 {
+		if err := (&apiserveroptions.DeprecatedInsecureServingOptions{
+			BindAddress: net.ParseIP(options.YurtHubSecondaryProxyHost),
+			BindPort:    options.YurtHubProxyPort,
+			BindNetwork: "tcp",
+		}).ApplyTo(&cfg.YurtHubSecondaryProxyServing); err != nil {
+			return err
+		}
+	}
+
+	// join info is served on a dedicated address which is reachable from the joining nodes in nodepool
+	if len(options.JoinInfoServerAddr) != 0 {
+		host, port, err := net.SplitHostPort(options.JoinInfoServerAddr)
+		if err != nil {
+			return err
+		}
+		bindPort, err := strconv.Atoi(port)
+		if err != nil {
+			return err
+		}
+		if err := (&apiserveroptions.DeprecatedInsecureServingOptions{
+			BindAddress: net.ParseIP(host),
+			BindPort:    bindPort,
+			BindNetwork: "tcp",
+		}).ApplyTo(&cfg.YurtHubJoinInfoServerServing); err != nil {
+			return err
+		}
+	}
+
 	yurtHubSecureProxyHost := options.YurtHubProxyHost
 	if options.EnableDummyIf {
 		yurtHubSecureProxyHost = options.HubAgentDummyIfIP
@@ -350,5 +417,25 @@ func prepareServerServing(options *options.YurtHubOptions, certMgr certificate.Y
 	cfg.YurtHubSecureProxyServerServing.ClientCA = caBundleProvider
 	cfg.YurtHubSecureProxyServerServing.DisableHTTP2 = true
 
+	// the dummy interface only holds one ip address, so the secure proxy server is served on
+	// the other ip family only when it's bound to the proxy address instead of the dummy interface.
+	if !options.EnableDummyIf && len(options.YurtHubSecondaryProxyHost) != 0 {
+		if err := (&apiserveroptions.SecureServingOptions{
+			BindAddress: net.ParseIP(options.YurtHubSecondaryProxyHost),
+			BindPort:    options.YurtHubProxySecurePort,
+			BindNetwork: "tcp",
+			ServerCert: apiserveroptions.GeneratableKeyCert{
+				CertKey: apiserveroptions.CertKey{
+					CertFile: serverCertPath,
+					KeyFile:  serverCertPath,
+				},
+			},
+		}).ApplyTo(&cfg.YurtHubSecondarySecureServing); err != nil {
+			return err
+		}
+		cfg.YurtHubSecondarySecureServing.ClientCA = caBundleProvider
+		cfg.YurtHubSecondarySecureServing.DisableHTTP2 = true
+	}
+
 	return nil
 }
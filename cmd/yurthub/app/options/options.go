@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -31,6 +32,7 @@ import (
 	utilnet "k8s.io/utils/net"
 
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
+	"github.com/openyurtio/openyurt/pkg/util/spiffe"
 	"github.com/openyurtio/openyurt/pkg/yurthub/certificate"
 	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
@@ -48,12 +50,16 @@ type YurtHubOptions struct {
 	ServerAddr                string
 	YurtHubHost               string // YurtHub server host (e.g.: expose metrics API)
 	YurtHubProxyHost          string // YurtHub proxy server host
+	YurtHubSecondaryHost      string // YurtHub server host of the other ip family for dual-stack serving
+	YurtHubSecondaryProxyHost string // YurtHub proxy server host of the other ip family for dual-stack serving
+	JoinInfoServerAddr        string // the address of join info server for nodes joining from the nodepool
 	YurtHubPort               int
 	YurtHubProxyPort          int
 	YurtHubProxySecurePort    int
 	YurtHubNamespace          string
 	GCFrequency               int
 	YurtHubCertOrganizations  []string
+	SPIFFETrustDomain         string
 	NodeName                  string
 	NodePoolName              string
 	LBMode                    string
@@ -68,6 +74,7 @@ type YurtHubOptions struct {
 	RootDir                   string
 	Version                   bool
 	EnableProfiling           bool
+	EnableFaultInjection      bool
 	EnableDummyIf             bool
 	EnableIptables            bool
 	HubAgentDummyIfIP         string
@@ -80,6 +87,7 @@ type YurtHubOptions struct {
 	KubeletHealthGracePeriod  time.Duration
 	EnableNodePool            bool
 	MinRequestTimeout         time.Duration
+	ShutdownGracePeriod       time.Duration
 	CACertHashes              []string
 	UnsafeSkipCAVerification  bool
 	ClientForTest             kubernetes.Interface
@@ -87,7 +95,12 @@ type YurtHubOptions struct {
 	CoordinatorServerAddr     string
 	CoordinatorStoragePrefix  string
 	CoordinatorStorageAddr    string
+	CoordinatorStorageQuota   int64
 	LeaderElection            componentbaseconfig.LeaderElectionConfiguration
+	HubLeaderTerm             time.Duration
+	DelegateLeaseJitter       time.Duration
+	DelegateLeaseQPS          float32
+	DelegateLeaseBurst        int
 }
 
 // NewYurtHubOptions creates a new YurtHubOptions with a default config.
@@ -121,6 +134,7 @@ func NewYurtHubOptions() *YurtHubOptions {
 		KubeletHealthGracePeriod:  time.Second * 40,
 		EnableNodePool:            true,
 		MinRequestTimeout:         time.Second * 1800,
+		ShutdownGracePeriod:       time.Second * 15,
 		CACertHashes:              make([]string, 0),
 		UnsafeSkipCAVerification:  true,
 		CoordinatorServerAddr:     fmt.Sprintf("https://%s:%s", util.DefaultYurtCoordinatorAPIServerSvcName, util.DefaultYurtCoordinatorAPIServerSvcPort),
@@ -135,6 +149,10 @@ func NewYurtHubOptions() *YurtHubOptions {
 			ResourceName:      projectinfo.GetHubName(),
 			ResourceNamespace: "kube-system",
 		},
+		HubLeaderTerm:       time.Hour,
+		DelegateLeaseJitter: 2 * time.Second,
+		DelegateLeaseQPS:    100,
+		DelegateLeaseBurst:  50,
 	}
 	return o
 }
@@ -163,6 +181,14 @@ func (options *YurtHubOptions) Validate() error {
 		return fmt.Errorf("working mode %s is not supported", options.WorkingMode)
 	}
 
+	if err := options.verifySecondaryHosts(); err != nil {
+		return err
+	}
+
+	if err := options.verifyJoinInfoServerAddr(); err != nil {
+		return err
+	}
+
 	if err := options.verifyDummyIP(); err != nil {
 		return fmt.Errorf("dummy ip %s is not invalid, %w", options.HubAgentDummyIfIP, err)
 	}
@@ -171,6 +197,28 @@ func (options *YurtHubOptions) Validate() error {
 		return fmt.Errorf("set --discovery-token-unsafe-skip-ca-verification flag as true or pass CACertHashes to continue")
 	}
 
+	if options.CoordinatorStorageQuota < 0 {
+		return fmt.Errorf("coordinator storage quota %d should not be negative", options.CoordinatorStorageQuota)
+	}
+
+	if options.HubLeaderTerm < 0 {
+		return fmt.Errorf("hub leader term %v should not be negative", options.HubLeaderTerm)
+	}
+
+	if options.DelegateLeaseJitter < 0 || options.DelegateLeaseQPS < 0 {
+		return fmt.Errorf("delegate lease jitter %v and qps %v should not be negative", options.DelegateLeaseJitter, options.DelegateLeaseQPS)
+	}
+
+	if options.DelegateLeaseQPS > 0 && options.DelegateLeaseBurst < 1 {
+		return fmt.Errorf("delegate lease burst %d should be at least 1 when qps is set", options.DelegateLeaseBurst)
+	}
+
+	if len(options.SPIFFETrustDomain) != 0 {
+		if err := spiffe.ValidateTrustDomain(options.SPIFFETrustDomain); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -179,6 +227,9 @@ func (o *YurtHubOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.YurtHubHost, "bind-address", o.YurtHubHost, "the IP address of YurtHub Server")
 	fs.IntVar(&o.YurtHubPort, "serve-port", o.YurtHubPort, "the port on which to serve HTTP requests(like profiling, metrics) for hub agent.")
 	fs.StringVar(&o.YurtHubProxyHost, "bind-proxy-address", o.YurtHubProxyHost, "the IP address of YurtHub Proxy Server")
+	fs.StringVar(&o.YurtHubSecondaryHost, "secondary-bind-address", o.YurtHubSecondaryHost, "the IP address of YurtHub Server in the other ip family of --bind-address(e.g. ::1), used for dual-stack serving")
+	fs.StringVar(&o.YurtHubSecondaryProxyHost, "secondary-bind-proxy-address", o.YurtHubSecondaryProxyHost, "the IP address of YurtHub Proxy Server in the other ip family of --bind-proxy-address(e.g. ::1), used for dual-stack serving of the proxy and secure proxy ports. the listeners on dummy interface are not dual-stack, so the secure proxy port is served only on --dummy-if-ip when dummy interface is enabled")
+	fs.StringVar(&o.JoinInfoServerAddr, "join-info-server-addr", o.JoinInfoServerAddr, "the address(ip:port, e.g. 0.0.0.0:10269) on which to serve the join info cached in nodepool for yurtadm join when kube-apiserver is unreachable, it should be reachable from the joining nodes and requires --enable-coordinator on edge nodes. join info is not served if it's empty.")
 	fs.IntVar(&o.YurtHubProxyPort, "proxy-port", o.YurtHubProxyPort, "the port on which to proxy HTTP requests to kube-apiserver")
 	fs.IntVar(&o.YurtHubProxySecurePort, "proxy-secure-port", o.YurtHubProxySecurePort, "the port on which to proxy HTTPS requests to kube-apiserver")
 	fs.StringVar(&o.YurtHubNamespace, "namespace", o.YurtHubNamespace, "the namespace of YurtHub Server")
@@ -199,26 +250,34 @@ func (o *YurtHubOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.RootDir, "root-dir", o.RootDir, "directory path for managing hub agent files(pki, cache etc).")
 	fs.BoolVar(&o.Version, "version", o.Version, "print the version information.")
 	fs.BoolVar(&o.EnableProfiling, "profiling", o.EnableProfiling, "enable profiling via web interface host:port/debug/pprof/")
+	fs.BoolVar(&o.EnableFaultInjection, "enable-fault-injection", o.EnableFaultInjection, "enable debug api host:port/v1/debug/faults for simulating cloud unreachability, latency and packet loss(only used for testing)")
 	fs.BoolVar(&o.EnableDummyIf, "enable-dummy-if", o.EnableDummyIf, "enable dummy interface or not")
 	fs.BoolVar(&o.EnableIptables, "enable-iptables", o.EnableIptables, "enable iptables manager to setup rules for accessing hub agent")
-	fs.StringVar(&o.HubAgentDummyIfIP, "dummy-if-ip", o.HubAgentDummyIfIP, "the ip address of dummy interface that used for container connect hub agent(exclusive ips: 169.254.31.0/24, 169.254.1.1/32)")
+	fs.StringVar(&o.HubAgentDummyIfIP, "dummy-if-ip", o.HubAgentDummyIfIP, "the ip address of dummy interface that used for container connect hub agent(exclusive ips: 169.254.31.0/24, 169.254.1.1/32), only one ip family is served on the dummy interface")
 	fs.StringVar(&o.HubAgentDummyIfName, "dummy-if-name", o.HubAgentDummyIfName, "the name of dummy interface that is used for hub agent")
 	fs.StringVar(&o.DiskCachePath, "disk-cache-path", o.DiskCachePath, "the path for kubernetes to storage metadata")
 	fs.BoolVar(&o.AccessServerThroughHub, "access-server-through-hub", o.AccessServerThroughHub, "enable pods access kube-apiserver through yurthub or not")
 	fs.BoolVar(&o.EnableResourceFilter, "enable-resource-filter", o.EnableResourceFilter, "enable to filter response that comes back from reverse proxy")
 	fs.StringSliceVar(&o.DisabledResourceFilters, "disabled-resource-filters", o.DisabledResourceFilters, "disable resource filters to handle response")
 	fs.StringVar(&o.NodePoolName, "nodepool-name", o.NodePoolName, "the name of node pool that runs hub agent")
+	fs.StringVar(&o.SPIFFETrustDomain, "spiffe-trust-domain", o.SPIFFETrustDomain, "the trust domain of SPIFFE identities that are added into hub's apiserver client certificate as URI SANs, node and nodepool(if --nodepool-name is set) scoped identities are added, the nodepool scoped identity is added when the certificate is rotated after the node is registered. empty means no SPIFFE identities.")
 	fs.StringVar(&o.WorkingMode, "working-mode", o.WorkingMode, "the working mode of yurthub(edge, cloud).")
 	fs.DurationVar(&o.KubeletHealthGracePeriod, "kubelet-health-grace-period", o.KubeletHealthGracePeriod, "the amount of time which we allow kubelet to be unresponsive before stop renew node lease")
 	fs.BoolVar(&o.EnableNodePool, "enable-node-pool", o.EnableNodePool, "enable list/watch nodepools resource or not for filters(only used for testing)")
 	fs.DurationVar(&o.MinRequestTimeout, "min-request-timeout", o.MinRequestTimeout, "An optional field indicating at least how long a proxy handler must keep a request open before timing it out. Currently only honored by the local watch request handler(use request parameter timeoutSeconds firstly), which picks a randomized value above this number as the connection timeout, to spread out load.")
+	fs.DurationVar(&o.ShutdownGracePeriod, "shutdown-grace-period", o.ShutdownGracePeriod, "the amount of time for draining in-flight watch requests and persisting in-flight cache writes when hub agent is shutting down.")
 	fs.StringSliceVar(&o.CACertHashes, "discovery-token-ca-cert-hash", o.CACertHashes, "For token-based discovery, validate that the root CA public key matches this hash (format: \"<type>:<value>\").")
 	fs.BoolVar(&o.UnsafeSkipCAVerification, "discovery-token-unsafe-skip-ca-verification", o.UnsafeSkipCAVerification, "For token-based discovery, allow joining without --discovery-token-ca-cert-hash pinning.")
 	fs.BoolVar(&o.EnableCoordinator, "enable-coordinator", o.EnableCoordinator, "make yurthub aware of the yurt coordinator")
 	fs.StringVar(&o.CoordinatorServerAddr, "coordinator-server-addr", o.CoordinatorServerAddr, "Coordinator APIServer address in format https://host:port")
 	fs.StringVar(&o.CoordinatorStoragePrefix, "coordinator-storage-prefix", o.CoordinatorStoragePrefix, "Yurt-Coordinator etcd storage prefix, same as etcd-prefix of Kube-APIServer")
 	fs.StringVar(&o.CoordinatorStorageAddr, "coordinator-storage-addr", o.CoordinatorStorageAddr, "Address of Yurt-Coordinator etcd, in the format host:port")
+	fs.Int64Var(&o.CoordinatorStorageQuota, "coordinator-storage-quota", o.CoordinatorStorageQuota, "the max bytes of Yurt-Coordinator storage in use, the leader yurthub evicts completed pods, configmaps, running pods and nodes in order when it's exceeded, while leases, services and endpoints are always kept. it should be less than the quota-backend-bytes of Yurt-Coordinator etcd, and the quota is disabled if it's 0.")
 	bindFlags(&o.LeaderElection, fs)
+	fs.DurationVar(&o.HubLeaderTerm, "hub-leader-term", o.HubLeaderTerm, "the duration that a yurthub holds the leadership in the nodepool before yielding it to other healthy yurthubs, the leadership is kept if there's no other healthy yurthub. leadership rotation is disabled if it's 0.")
+	fs.DurationVar(&o.DelegateLeaseJitter, "delegate-lease-jitter", o.DelegateLeaseJitter, "the max random delay before the leader yurthub delegates a node lease to cloud, it spreads the leases renewed at the same time. jitter is disabled if it's 0.")
+	fs.Float32Var(&o.DelegateLeaseQPS, "delegate-lease-qps", o.DelegateLeaseQPS, "the max number of node leases delegated to cloud by the leader yurthub per second, rate limit is disabled if it's 0.")
+	fs.IntVar(&o.DelegateLeaseBurst, "delegate-lease-burst", o.DelegateLeaseBurst, "the max burst of node leases delegated to cloud by the leader yurthub.")
 }
 
 // bindFlags binds the LeaderElectionConfiguration struct fields to a flagset
@@ -249,6 +308,62 @@ func bindFlags(l *componentbaseconfig.LeaderElectionConfiguration, fs *pflag.Fla
 		"leader election.")
 }
 
+// verifyJoinInfoServerAddr verify the join info server address is ip:port, and the join info is only
+// cached by yurthub on edge nodes with yurt coordinator enabled.
+func (o *YurtHubOptions) verifyJoinInfoServerAddr() error {
+	if len(o.JoinInfoServerAddr) == 0 {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(o.JoinInfoServerAddr)
+	if err != nil {
+		return fmt.Errorf("join info server address %s is invalid, %w", o.JoinInfoServerAddr, err)
+	}
+	if net.ParseIP(host) == nil {
+		return fmt.Errorf("host of join info server address %s should be an ip", o.JoinInfoServerAddr)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("port of join info server address %s is invalid", o.JoinInfoServerAddr)
+	}
+	if !o.EnableCoordinator || util.WorkingMode(o.WorkingMode) != util.WorkingModeEdge {
+		return fmt.Errorf("join info server address should be set only on edge nodes with yurt coordinator enabled")
+	}
+	return nil
+}
+
+// verifySecondaryHosts verify the secondary bind addresses are valid and belong to
+// the other ip family of the corresponding primary bind addresses.
+func (o *YurtHubOptions) verifySecondaryHosts() error {
+	hosts := []struct {
+		primary   string
+		secondary string
+	}{
+		{primary: o.YurtHubHost, secondary: o.YurtHubSecondaryHost},
+		{primary: o.YurtHubProxyHost, secondary: o.YurtHubSecondaryProxyHost},
+	}
+
+	for _, h := range hosts {
+		if len(h.secondary) == 0 {
+			continue
+		}
+
+		secondaryIP := net.ParseIP(h.secondary)
+		if secondaryIP == nil {
+			return fmt.Errorf("secondary bind address %s is invalid", h.secondary)
+		}
+
+		primaryIP := net.ParseIP(h.primary)
+		if primaryIP == nil {
+			return fmt.Errorf("bind address %s is invalid, secondary bind address %s can not be used", h.primary, h.secondary)
+		}
+
+		if utilnet.IsIPv6(primaryIP) == utilnet.IsIPv6(secondaryIP) {
+			return fmt.Errorf("secondary bind address %s should not be in the same ip family as bind address %s", h.secondary, h.primary)
+		}
+	}
+
+	return nil
+}
+
 // verifyDummyIP verify the specified ip is valid or not and set the default ip if empty
 func (o *YurtHubOptions) verifyDummyIP() error {
 	if o.HubAgentDummyIfIP == "" {
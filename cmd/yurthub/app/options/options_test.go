@@ -63,6 +63,7 @@ func TestNewYurtHubOptions(t *testing.T) {
 		KubeletHealthGracePeriod:  time.Second * 40,
 		EnableNodePool:            true,
 		MinRequestTimeout:         time.Second * 1800,
+		ShutdownGracePeriod:       time.Second * 15,
 		CACertHashes:              make([]string, 0),
 		UnsafeSkipCAVerification:  true,
 		CoordinatorServerAddr:     fmt.Sprintf("https://%s:%s", util.DefaultYurtCoordinatorAPIServerSvcName, util.DefaultYurtCoordinatorAPIServerSvcPort),
@@ -77,6 +78,10 @@ func TestNewYurtHubOptions(t *testing.T) {
 			ResourceName:      projectinfo.GetHubName(),
 			ResourceNamespace: "kube-system",
 		},
+		HubLeaderTerm:       time.Hour,
+		DelegateLeaseJitter: 2 * time.Second,
+		DelegateLeaseQPS:    100,
+		DelegateLeaseBurst:  50,
 	}
 
 	options := NewYurtHubOptions()
@@ -182,6 +187,44 @@ func TestValidate(t *testing.T) {
 			},
 			isErr: true,
 		},
+		"join info server without coordinator": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "edge",
+				UnsafeSkipCAVerification: true,
+				JoinInfoServerAddr:       "0.0.0.0:10269",
+			},
+			isErr: true,
+		},
+		"invalid join info server address": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "edge",
+				UnsafeSkipCAVerification: true,
+				EnableCoordinator:        true,
+				JoinInfoServerAddr:       "0.0.0.0",
+			},
+			isErr: true,
+		},
+		"join info server with coordinator": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "edge",
+				UnsafeSkipCAVerification: true,
+				EnableCoordinator:        true,
+				JoinInfoServerAddr:       "0.0.0.0:10269",
+			},
+			isErr: false,
+		},
 		"normal options": {
 			options: &YurtHubOptions{
 				NodeName:                 "foo",
@@ -193,6 +236,42 @@ func TestValidate(t *testing.T) {
 			},
 			isErr: false,
 		},
+		"delegate lease burst is not set": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "cloud",
+				UnsafeSkipCAVerification: true,
+				DelegateLeaseQPS:         10,
+			},
+			isErr: true,
+		},
+		"negative coordinator storage quota": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "cloud",
+				UnsafeSkipCAVerification: true,
+				CoordinatorStorageQuota:  -1,
+			},
+			isErr: true,
+		},
+		"negative delegate lease jitter": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "cloud",
+				UnsafeSkipCAVerification: true,
+				DelegateLeaseJitter:      -time.Second,
+			},
+			isErr: true,
+		},
 		"normal options with ipv4": {
 			options: &YurtHubOptions{
 				NodeName:                 "foo",
@@ -205,6 +284,47 @@ func TestValidate(t *testing.T) {
 			},
 			isErr: false,
 		},
+		"secondary bind address is invalid": {
+			options: &YurtHubOptions{
+				NodeName:                 "foo",
+				ServerAddr:               "1.2.3.4:56",
+				JoinToken:                "xxxx",
+				LBMode:                   "rr",
+				WorkingMode:              "cloud",
+				UnsafeSkipCAVerification: true,
+				YurtHubHost:              "127.0.0.1",
+				YurtHubSecondaryHost:     "invalid ip",
+			},
+			isErr: true,
+		},
+		"secondary bind address in the same ip family": {
+			options: &YurtHubOptions{
+				NodeName:                  "foo",
+				ServerAddr:                "1.2.3.4:56",
+				JoinToken:                 "xxxx",
+				LBMode:                    "rr",
+				WorkingMode:               "cloud",
+				UnsafeSkipCAVerification:  true,
+				YurtHubProxyHost:          "127.0.0.1",
+				YurtHubSecondaryProxyHost: "127.0.0.2",
+			},
+			isErr: true,
+		},
+		"normal options with dual-stack bind addresses": {
+			options: &YurtHubOptions{
+				NodeName:                  "foo",
+				ServerAddr:                "1.2.3.4:56",
+				JoinToken:                 "xxxx",
+				LBMode:                    "rr",
+				WorkingMode:               "cloud",
+				UnsafeSkipCAVerification:  true,
+				YurtHubHost:               "127.0.0.1",
+				YurtHubProxyHost:          "127.0.0.1",
+				YurtHubSecondaryHost:      "::1",
+				YurtHubSecondaryProxyHost: "::1",
+			},
+			isErr: false,
+		},
 		"normal options with ipv6": {
 			options: &YurtHubOptions{
 				NodeName:                 "foo",
@@ -39,6 +39,7 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurthub/healthchecker"
 	hubrest "github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/rest"
 	"github.com/openyurtio/openyurt/pkg/yurthub/proxy"
+	proxyutil "github.com/openyurtio/openyurt/pkg/yurthub/proxy/util"
 	"github.com/openyurtio/openyurt/pkg/yurthub/server"
 	"github.com/openyurtio/openyurt/pkg/yurthub/tenant"
 	"github.com/openyurtio/openyurt/pkg/yurthub/transport"
@@ -97,6 +98,9 @@ func Run(ctx context.Context, cfg *config.YurtHubConfiguration) error {
 	if err != nil {
 		return fmt.Errorf("could not new transport manager, %w", err)
 	}
+	if cfg.FaultInjector != nil {
+		transportManager = cfg.FaultInjector.WrapTransportManager(transportManager)
+	}
 	trace++
 
 	klog.Infof("%d. prepare cloud kube clients", trace)
@@ -179,12 +183,14 @@ func Run(ctx context.Context, cfg *config.YurtHubConfiguration) error {
 	cfg.NodePoolInformerFactory.Start(ctx.Done())
 
 	klog.Infof("%d. new reverse proxy handler for remote servers", trace)
+	watchDrainer := proxyutil.NewWatchDrainer()
 	yurtProxyHandler, err := proxy.NewYurtReverseProxyHandler(
 		cfg,
 		cacheMgr,
 		transportManager,
 		cloudHealthChecker,
 		tenantMgr,
+		watchDrainer,
 		coordinatorGetter,
 		coordinatorTransportManagerGetter,
 		coordinatorHealthCheckerGetter,
@@ -200,14 +206,38 @@ func Run(ctx context.Context, cfg *config.YurtHubConfiguration) error {
 	}
 
 	klog.Infof("%d. new %s server and begin to serve", trace, projectinfo.GetHubName())
-	if err := server.RunYurtHubServers(cfg, yurtProxyHandler, restConfigMgr, ctx.Done()); err != nil {
+	// servers are stopped after in-flight watch requests have been drained,
+	// so they will not be cut off by closing listeners.
+	serverStopCh := make(chan struct{})
+	defer close(serverStopCh)
+	if err := server.RunYurtHubServers(cfg, yurtProxyHandler, restConfigMgr, serverStopCh); err != nil {
 		return fmt.Errorf("could not run hub servers, %w", err)
 	}
 	<-ctx.Done()
+	gracefulShutdown(cfg, watchDrainer, cacheMgr)
 	klog.Info("hub agent exited")
 	return nil
 }
 
+// gracefulShutdown closes in-flight watch requests gracefully so clients can re-watch from the
+// last resourceVersion instead of relisting, and waits for in-flight cache writes to be persisted.
+func gracefulShutdown(cfg *config.YurtHubConfiguration, watchDrainer *proxyutil.WatchDrainer, cacheMgr cachemanager.CacheManager) {
+	if cfg.ShutdownGracePeriod <= 0 {
+		return
+	}
+
+	klog.Infof("start to shutdown %s gracefully in %v", projectinfo.GetHubName(), cfg.ShutdownGracePeriod)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
+
+	watchDrainer.Drain(ctx)
+	if cacheMgr != nil {
+		if err := cacheMgr.WaitForInFlightWrites(ctx); err != nil {
+			klog.Errorf("could not wait for in-flight cache writes to be persisted, %v", err)
+		}
+	}
+}
+
 // createClients will create clients for all cloud APIServer
 // It will return a map, mapping cloud APIServer URL to its client
 func createClients(heartbeatTimeoutSeconds int, remoteServers []*url.URL, tp transport.Interface) (map[string]kubernetes.Interface, error) {
@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+
+	"golang.org/x/sys/windows/svc"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/projectinfo"
+)
+
+// serviceContext returns a context which is canceled when yurthub is stopped by the windows service
+// control manager, ctx is returned as it is if yurthub is not running as a windows service.
+func serviceContext(ctx context.Context) context.Context {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		klog.Errorf("could not determine whether yurthub is running as a windows service, %v", err)
+		return ctx
+	}
+	if !isService {
+		return ctx
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		if err := svc.Run(projectinfo.GetHubName(), &serviceHandler{cancel: cancel}); err != nil {
+			klog.Errorf("could not run yurthub as a windows service, %v", err)
+		}
+	}()
+	return ctx
+}
+
+// serviceHandler handles the requests of windows service control manager.
+type serviceHandler struct {
+	cancel context.CancelFunc
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			status <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			klog.Infof("yurthub is stopped by windows service control manager")
+			status <- svc.Status{State: svc.StopPending}
+			h.cancel()
+			return false, 0
+		}
+	}
+	return false, 0
+}
@@ -30,7 +30,7 @@ func main() {
 	newRand := rand.New(rand.NewSource(time.Now().UnixNano()))
 	newRand.Seed(time.Now().UnixNano())
 
-	cmd := app.NewCmdStartYurtHub(server.SetupSignalContext())
+	cmd := app.NewCmdStartYurtHub(serviceContext(server.SetupSignalContext()))
 	cmd.Flags().AddGoFlagSet(flag.CommandLine)
 	if err := cmd.Execute(); err != nil {
 		panic(err)
@@ -189,11 +189,14 @@ EOF
 
    ${YURT_ROOT}/bin/kustomize build ${output_crd_dir} -o ${crd_dir}
    # TODO currently kustomize may not support custom generate names, find more elegant way generate crds
+   mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_edgeippools.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_edgeippools.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_nodepools.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_nodepools.yaml
+   mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_nodepoolquotas.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_nodepoolquotas.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_yurtstaticsets.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_yurtstaticsets.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_yurtappdaemons.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_yurtappdaemons.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_yurtappsets.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_yurtappsets.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_yurtappoverriders.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_yurtappoverriders.yaml
+   mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_yurtcoordinators.apps.openyurt.io.yaml ${crd_dir}/apps.openyurt.io_yurtcoordinators.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_gateways.raven.openyurt.io.yaml ${crd_dir}/raven.openyurt.io_gateways.yaml
    mv ${crd_dir}/apiextensions.k8s.io_v1_customresourcedefinition_platformadmins.iot.openyurt.io.yaml ${crd_dir}/iot.openyurt.io_platformadmins.yaml
    # TODO: In the future, the crd generation process of yurt-manager and yurt-iot-dock will be split. For now, manually remove it from the yurt-manager script
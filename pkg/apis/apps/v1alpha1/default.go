@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	v1 "k8s.io/kubernetes/pkg/apis/core/v1"
 	utilpointer "k8s.io/utils/pointer"
@@ -39,6 +42,16 @@ func SetDefaultsYurtAppSet(obj *YurtAppSet) {
 		obj.Spec.RevisionHistoryLimit = utilpointer.Int32Ptr(10)
 	}
 
+	if obj.Spec.Autoscaling != nil && obj.Spec.Autoscaling.MinReplicas == nil {
+		obj.Spec.Autoscaling.MinReplicas = utilpointer.Int32Ptr(1)
+	}
+
+	for i := range obj.Spec.ConfigTemplates {
+		if obj.Spec.ConfigTemplates[i].Kind == "" {
+			obj.Spec.ConfigTemplates[i].Kind = ConfigMapConfigTemplateKind
+		}
+	}
+
 	if obj.Spec.WorkloadTemplate.StatefulSetTemplate != nil {
 		SetDefaultPodSpec(&obj.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.Template.Spec)
 		for i := range obj.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.VolumeClaimTemplates {
@@ -54,6 +67,15 @@ func SetDefaultsYurtAppSet(obj *YurtAppSet) {
 	}
 }
 
+// SetDefaultJobPodSpec sets default pod spec of Job, the restart policy defaults to OnFailure
+// because Always is not allowed for pods of Job.
+func SetDefaultJobPodSpec(in *corev1.PodSpec) {
+	if in.RestartPolicy == "" {
+		in.RestartPolicy = corev1.RestartPolicyOnFailure
+	}
+	SetDefaultPodSpec(in)
+}
+
 // SetDefaultPodSpec sets default pod spec
 func SetDefaultPodSpec(in *corev1.PodSpec) {
 	v1.SetDefaults_PodSpec(in)
@@ -216,6 +238,14 @@ func SetDefaultsYurtStaticSet(obj *YurtStaticSet) {
 		v := intstr.FromString("10%")
 		strategy.MaxUnavailable = &v
 	}
+	// Set default rollback window to 2 minutes
+	if strategy.RollbackWindow == nil {
+		strategy.RollbackWindow = &metav1.Duration{Duration: 2 * time.Minute}
+	}
+	// Set default soak period of canary NodePools to 10 minutes
+	if strategy.Canary != nil && strategy.Canary.SoakPeriod == nil {
+		strategy.Canary.SoakPeriod = &metav1.Duration{Duration: 10 * time.Minute}
+	}
 
 	// Set default RevisionHistoryLimit to 10
 	if obj.Spec.RevisionHistoryLimit == nil {
@@ -231,6 +261,16 @@ func SetDefaultsYurtStaticSet(obj *YurtStaticSet) {
 	// use YurtStaticSet name and namespace to replace name and namespace in template metadata
 	obj.Spec.Template.Name = obj.Name
 	obj.Spec.Template.Namespace = obj.Namespace
+
+	// bundled static pods are named after the manifest by default, and they are in the same namespace
+	for i := range obj.Spec.BundledManifests {
+		bundled := &obj.Spec.BundledManifests[i]
+		SetDefaultPodSpec(&bundled.Template.Spec)
+		if bundled.Template.Name == "" {
+			bundled.Template.Name = obj.Name + "-" + bundled.StaticPodManifest
+		}
+		bundled.Template.Namespace = obj.Namespace
+	}
 }
 
 // SetDefaultsYurtAppDaemon set default values for YurtAppDaemon.
@@ -253,4 +293,10 @@ func SetDefaultsYurtAppDaemon(obj *YurtAppDaemon) {
 	if obj.Spec.WorkloadTemplate.DeploymentTemplate != nil {
 		SetDefaultPodSpec(&obj.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Spec)
 	}
+	if obj.Spec.WorkloadTemplate.JobTemplate != nil {
+		SetDefaultJobPodSpec(&obj.Spec.WorkloadTemplate.JobTemplate.Spec.Template.Spec)
+	}
+	if obj.Spec.WorkloadTemplate.CronJobTemplate != nil {
+		SetDefaultJobPodSpec(&obj.Spec.WorkloadTemplate.CronJobTemplate.Spec.JobTemplate.Spec.Template.Spec)
+	}
 }
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EdgeIPPoolSpec defines the desired state of EdgeIPPool
+type EdgeIPPoolSpec struct {
+	// NodePool is the name of NodePool which the addresses are used in.
+	NodePool string `json:"nodePool"`
+
+	// Addresses is the list of address ranges, each item is a CIDR(like 192.168.10.0/28),
+	// a range(like 192.168.10.100-192.168.10.120) or a single address.
+	Addresses []string `json:"addresses"`
+}
+
+// EdgeIPPoolStatus defines the observed state of EdgeIPPool
+type EdgeIPPoolStatus struct {
+	// Capacity is the number of addresses in the pool.
+	// +optional
+	Capacity int64 `json:"capacity,omitempty"`
+
+	// Allocated is the number of addresses allocated to LoadBalancer Services.
+	// +optional
+	Allocated int64 `json:"allocated,omitempty"`
+
+	// Allocations records the addresses allocated to LoadBalancer Services, the address
+	// is kept for the Service until the Service doesn't request an address from the NodePool.
+	// +optional
+	Allocations []EdgeIPAllocation `json:"allocations,omitempty"`
+
+	// Conditions represents the latest available observations of EdgeIPPool,
+	// includes Exhausted and Conflicted.
+	// +optional
+	Conditions []EdgeIPPoolCondition `json:"conditions,omitempty"`
+}
+
+// EdgeIPAllocation is an address allocated to a LoadBalancer Service.
+type EdgeIPAllocation struct {
+	// IP is the allocated address.
+	IP string `json:"ip"`
+
+	// Service is the namespace/name of Service which the address is allocated to.
+	Service string `json:"service"`
+}
+
+// EdgeIPPoolConditionType indicates valid conditions type of a EdgeIPPool.
+type EdgeIPPoolConditionType string
+
+const (
+	// EdgeIPPoolExhausted means there're Services which can not get an address from the NodePool.
+	EdgeIPPoolExhausted EdgeIPPoolConditionType = "Exhausted"
+	// EdgeIPPoolConflicted means the addresses of pool overlap with other EdgeIPPools, or are
+	// used by Services statically, the conflicted addresses are never allocated.
+	EdgeIPPoolConflicted EdgeIPPoolConditionType = "Conflicted"
+)
+
+// EdgeIPPoolCondition describes current state of a EdgeIPPool.
+type EdgeIPPoolCondition struct {
+	// Type of EdgeIPPool condition.
+	Type EdgeIPPoolConditionType `json:"type,omitempty"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=eip
+// +kubebuilder:printcolumn:name="NodePool",type="string",JSONPath=".spec.nodePool",description="The NodePool which the addresses are used in."
+// +kubebuilder:printcolumn:name="Capacity",type="integer",JSONPath=".status.capacity",description="The number of addresses in the pool."
+// +kubebuilder:printcolumn:name="Allocated",type="integer",JSONPath=".status.allocated",description="The number of allocated addresses."
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// EdgeIPPool is a set of addresses in the NodePool, which are allocated to LoadBalancer Services
+// as virtual ips by the load-balancer-set controller.
+type EdgeIPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EdgeIPPoolSpec   `json:"spec,omitempty"`
+	Status EdgeIPPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EdgeIPPoolList contains a list of EdgeIPPool
+type EdgeIPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EdgeIPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EdgeIPPool{}, &EdgeIPPoolList{})
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the License);
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an AS IS BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodePoolQuotaSpec defines the desired state of NodePoolQuota
+type NodePoolQuotaSpec struct {
+	// NodePool is the name of NodePool which the quota is applied to.
+	NodePool string `json:"nodePool"`
+
+	// Selector is a label query over pods that are counted against the quota,
+	// all pods of the namespace in the NodePool are counted if it's not specified.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Hard is the set of hard limits for each named resource, only requests.<resource>
+	// and limits.<resource>(like requests.cpu and limits.memory) are supported.
+	Hard corev1.ResourceList `json:"hard"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=npq
+// +kubebuilder:printcolumn:name="NodePool",type="string",JSONPath=".spec.nodePool",description="The NodePool which the quota is applied to."
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// NodePoolQuota limits the total resource requests and limits of pods in a namespace that
+// are scheduled into the NodePool, so workloads of one team can not consume the entire NodePool.
+type NodePoolQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NodePoolQuotaSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodePoolQuotaList contains a list of NodePoolQuota
+type NodePoolQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodePoolQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodePoolQuota{}, &NodePoolQuotaList{})
+}
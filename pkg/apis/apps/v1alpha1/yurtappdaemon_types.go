@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // YurtAppDaemonConditionType indicates valid conditions type of a YurtAppDaemon.
@@ -51,6 +52,28 @@ type YurtAppDaemonSpec struct {
 	// If unspecified, defaults to 10.
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// UpdateStrategy indicates how the workloads of nodepools are updated to the new revision.
+	// If unspecified, the workloads of all nodepools are updated at the same time.
+	// +optional
+	UpdateStrategy *YurtAppDaemonUpdateStrategy `json:"updateStrategy,omitempty"`
+}
+
+// YurtAppDaemonUpdateStrategy defines how the workloads are rolled out nodepool by nodepool,
+// the workload of a nodepool is available when it is updated and all of its replicas are ready.
+type YurtAppDaemonUpdateStrategy struct {
+	// MaxUnavailable is the maximum number of nodepools whose workloads can be unavailable
+	// during the update. Value can be an absolute number (ex: 1) or a percentage of the
+	// selected nodepools (ex: 10%). Absolute number is calculated from percentage by rounding up.
+	// Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// NodePools indicates the order in which the workloads of nodepools are updated.
+	// The nodepools that are not listed here are updated after all the listed nodepools
+	// in the order of their names.
+	// +optional
+	NodePools []string `json:"nodepools,omitempty"`
 }
 
 // YurtAppDaemonStatus defines the observed state of YurtAppDaemon
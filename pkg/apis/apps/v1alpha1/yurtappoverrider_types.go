@@ -34,11 +34,24 @@ type ImageItem struct {
 	ImageClaim string `json:"imageClaim"`
 }
 
+// ImageRegistryItem rewrites the registry or prefix of the images of all containers
+type ImageRegistryItem struct {
+	// From is the image prefix to be rewritten, like "docker.io/library", and it matches
+	// whole path components of image name. If unspecified, the registry of all images is rewritten
+	// +optional
+	From string `json:"from,omitempty"`
+	// To is the registry or prefix which replaces From, like "eu.mirror.example.com/library"
+	To string `json:"to"`
+}
+
 // Item represents configuration to be injected.
 // Only one of its members may be specified.
 type Item struct {
 	// +optional
 	Image *ImageItem `json:"image,omitempty"`
+	// ImageRegistry is applied to all containers, and the images claimed by Image are not rewritten
+	// +optional
+	ImageRegistry *ImageRegistryItem `json:"imageRegistry,omitempty"`
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
 }
@@ -62,15 +75,40 @@ type Patch struct {
 	Value apiextensionsv1.JSON `json:"value,omitempty"`
 }
 
+// JSONPatchOperation is an operation of RFC 6902 JSON patch
+type JSONPatchOperation struct {
+	// Op represents the operation of json patch
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+	// Path represents the target location of the operation
+	Path string `json:"path"`
+	// From represents the source location of move and copy operations
+	// +optional
+	From string `json:"from,omitempty"`
+	// Indicates the value of add, replace and test operations
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
 // Describe detailed multi-region configuration of the subject
 // Entry describe a set of nodepools and their shared or identical configurations
 type Entry struct {
-	Pools []string `json:"pools"`
+	// Pools are the names of target pools, "*" means all pools and "-" prefix excludes the pool.
+	// +optional
+	Pools []string `json:"pools,omitempty"`
+	// PoolSelector selects target pools by the labels of nodepools, so the entry is applied to
+	// the pools created later as long as their nodepools match the selector.
+	// +optional
+	PoolSelector *metav1.LabelSelector `json:"poolSelector,omitempty"`
 	// +optional
 	Items []Item `json:"items,omitempty"`
 	// Convert Patch struct into json patch operation
 	// +optional
 	Patches []Patch `json:"patches,omitempty"`
+	// JSONPatch is applied to the workload as a RFC 6902 JSON patch after Items and Patches,
+	// it supports fine-grained modifications, like inserting an element at the specified index of a list
+	// +optional
+	JSONPatch []JSONPatchOperation `json:"jsonPatch,omitempty"`
 }
 
 // Describe the object Entries belongs
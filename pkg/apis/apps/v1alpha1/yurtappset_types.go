@@ -23,6 +23,8 @@ package v1alpha1
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +35,8 @@ type TemplateType string
 const (
 	StatefulSetTemplateType TemplateType = "StatefulSet"
 	DeploymentTemplateType  TemplateType = "Deployment"
+	JobTemplateType         TemplateType = "Job"
+	CronJobTemplateType     TemplateType = "CronJob"
 )
 
 // YurtAppSetConditionType indicates valid conditions type of a YurtAppSet.
@@ -45,6 +49,10 @@ const (
 	PoolUpdated YurtAppSetConditionType = "PoolUpdated"
 	// PoolFailure is added to a YurtAppSet when one of its pools has failure during its own reconciling.
 	PoolFailure YurtAppSetConditionType = "PoolFailure"
+	// RolloutPaused means the rollout of new revision across pools is paused by RolloutStrategy.
+	RolloutPaused YurtAppSetConditionType = "RolloutPaused"
+	// PoolReady means all the replicas of the workload in a pool are ready, it is only used in PoolStatus.
+	PoolReady YurtAppSetConditionType = "PoolReady"
 )
 
 // YurtAppSetSpec defines the desired state of YurtAppSet.
@@ -61,10 +69,140 @@ type YurtAppSetSpec struct {
 	// +optional
 	Topology Topology `json:"topology,omitempty"`
 
+	// Replicas is the total number of pods distributed across pools by the weights of pools,
+	// and the pods are rebalanced when pools are added or removed.
+	// The replicas of pools are ignored if Replicas is set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Indicates the number of histories to be conserved.
 	// If unspecified, defaults to 10.
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// RolloutStrategy indicates how the new revision is rolled out across pools.
+	// If unspecified, all pools are updated to the new revision at the same time.
+	// +optional
+	RolloutStrategy *YurtAppSetRolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// RollbackTo is the config of rollback, the workloadTemplate of YurtAppSet will be
+	// reverted to the specified revision, and RollbackTo will be cleared after that.
+	// +optional
+	RollbackTo *YurtAppSetRollbackConfig `json:"rollbackTo,omitempty"`
+
+	// Autoscaling indicates a HorizontalPodAutoscaler is generated for the workload of each pool,
+	// the replicas of pools are managed by the HorizontalPodAutoscalers instead of YurtAppSet.
+	// +optional
+	Autoscaling *YurtAppSetAutoscaling `json:"autoscaling,omitempty"`
+
+	// ConfigTemplates are rendered into a ConfigMap or Secret for each pool with the variables of the pool,
+	// and the rendered object is added to the workload of the pool as a volume.
+	// +optional
+	ConfigTemplates []PoolConfigTemplate `json:"configTemplates,omitempty"`
+
+	// Bundle is the objects instantiated for each pool together with the workload of pool,
+	// they are named consistently and owned by the YurtAppSet.
+	// +optional
+	Bundle []BundleTemplate `json:"bundle,omitempty"`
+}
+
+// ConfigTemplateKind is the kind of object rendered from PoolConfigTemplate.
+type ConfigTemplateKind string
+
+const (
+	ConfigMapConfigTemplateKind ConfigTemplateKind = "ConfigMap"
+	SecretConfigTemplateKind    ConfigTemplateKind = "Secret"
+)
+
+// PoolConfigTemplate defines the template of ConfigMap or Secret rendered for each pool.
+// The values of Data are go templates, and the variables of pool can be referenced by
+// {{ .PoolName }}, {{ .NodePoolName }}, {{ .Region }}, {{ .Zone }}, {{ .Site }}, {{ .Gateway }} and {{ .GatewayIP }}.
+type PoolConfigTemplate struct {
+	// Name is the name of template. The object rendered for each pool is named <yurtappset>-<pool>-<name>,
+	// and it is added to the pod template as a volume named <name>, which replaces the volume with
+	// the same name in the workloadTemplate.
+	Name string `json:"name"`
+
+	// Kind is the kind of object rendered, ConfigMap or Secret. Defaults to ConfigMap.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +optional
+	Kind ConfigTemplateKind `json:"kind,omitempty"`
+
+	// Data is the data of object rendered, and its values are go templates.
+	Data map[string]string `json:"data"`
+
+	// MountPath is the path in containers at which the volume is mounted. If unspecified,
+	// the volume is not mounted, and containers in the workloadTemplate should mount a placeholder
+	// volume with the same name declared in the workloadTemplate, which is replaced by the rendered one.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// YurtAppSetAutoscaling defines the HorizontalPodAutoscaler generated for the workload of each pool.
+type YurtAppSetAutoscaling struct {
+	// MinReplicas is the lower limit of replicas of each pool, defaults to 1.
+	// It can be overridden by the autoscaling of pool.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas of each pool.
+	// It can be overridden by the autoscaling of pool.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Metrics contains the specifications for which to use to calculate the desired replicas,
+	// it is the same as the metrics of autoscaling/v2beta2 HorizontalPodAutoscaler.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Metrics []autoscalingv2beta2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// PoolAutoscaling defines the bounds of replicas of a pool, which override the ones of YurtAppSet.
+type PoolAutoscaling struct {
+	// MinReplicas is the lower limit of replicas of the pool.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas of the pool.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// YurtAppSetRollbackConfig defines the revision that YurtAppSet rolls back to.
+type YurtAppSetRollbackConfig struct {
+	// Revision is the revision to rollback to. If set to 0, rollback to the last revision.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+}
+
+// YurtAppSetRolloutStrategy defines the order and health gates for rolling out
+// the new revision across pools, so a bad revision stops at the first pools.
+type YurtAppSetRolloutStrategy struct {
+	// Pools indicates the order in which pools are updated to the new revision.
+	// A pool is updated only after all the pools before it are updated and pass their health gates.
+	// Pools that are not listed here are updated together after all the listed pools.
+	// +optional
+	Pools []RolloutPool `json:"pools,omitempty"`
+
+	// Paused indicates that pools which are not updated yet will not be updated
+	// to the new revision. Set it to false to resume the rollout.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RolloutPool defines a pool in the rollout order and its health gate.
+type RolloutPool struct {
+	// Name is the name of pool in Topology.
+	Name string `json:"name"`
+
+	// MinReadyPercent is the percentage of replicas in the pool which should be updated
+	// and ready before the rollout moves on to the next pool. Defaults to 100.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinReadyPercent *int32 `json:"minReadyPercent,omitempty"`
 }
 
 // WorkloadTemplate defines the pool template under the YurtAppSet.
@@ -79,6 +217,14 @@ type WorkloadTemplate struct {
 	// Deployment template
 	// +optional
 	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// Job template, it is only supported by YurtAppDaemon
+	// +optional
+	JobTemplate *JobTemplateSpec `json:"jobTemplate,omitempty"`
+
+	// CronJob template, it is only supported by YurtAppDaemon
+	// +optional
+	CronJobTemplate *CronJobTemplateSpec `json:"cronJobTemplate,omitempty"`
 }
 
 // StatefulSetTemplateSpec defines the pool template of StatefulSet.
@@ -91,6 +237,40 @@ type StatefulSetTemplateSpec struct {
 	Spec appsv1.StatefulSetSpec `json:"spec"`
 }
 
+// BundleTemplate defines an object instantiated for each pool, only one of the templates should be set.
+type BundleTemplate struct {
+	// Name is the name of template. The object instantiated for each pool is named <yurtappset>-<pool>-<name>,
+	// and the references to the ConfigMap <name> in the workloadTemplate are replaced with the one of pool.
+	Name string `json:"name"`
+
+	// ServiceTemplate describes the Service of pool, which only selects the pods of pool.
+	// +optional
+	ServiceTemplate *ServiceTemplateSpec `json:"serviceTemplate,omitempty"`
+
+	// ConfigMapTemplate describes the ConfigMap of pool.
+	// +optional
+	ConfigMapTemplate *ConfigMapTemplateSpec `json:"configMapTemplate,omitempty"`
+}
+
+// ServiceTemplateSpec defines the pool template of Service.
+type ServiceTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec corev1.ServiceSpec `json:"spec"`
+}
+
+// ConfigMapTemplateSpec defines the pool template of ConfigMap.
+type ConfigMapTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
 // DeploymentTemplateSpec defines the pool template of Deployment.
 type DeploymentTemplateSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -101,6 +281,26 @@ type DeploymentTemplateSpec struct {
 	Spec appsv1.DeploymentSpec `json:"spec"`
 }
 
+// JobTemplateSpec defines the pool template of Job.
+type JobTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec batchv1.JobSpec `json:"spec"`
+}
+
+// CronJobTemplateSpec defines the pool template of CronJob.
+type CronJobTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec batchv1.CronJobSpec `json:"spec"`
+}
+
 // Topology defines the spread detail of each pool under YurtAppSet.
 // A YurtAppSet manages multiple homogeneous workloads which are called pool.
 // Each of pools under the YurtAppSet is described in Topology.
@@ -109,6 +309,50 @@ type Topology struct {
 	// which will be provisioned and managed by YurtAppSet.
 	// +optional
 	Pools []Pool `json:"pools,omitempty"`
+
+	// SpreadConstraints describe how the replicas are spread across pools. They are enforced
+	// by moving replicas between pools after the replicas of pools are computed.
+	// +optional
+	SpreadConstraints []PoolSpreadConstraint `json:"spreadConstraints,omitempty"`
+}
+
+// SpreadTopologyKey is the topology of NodePools by which pools are grouped into domains.
+type SpreadTopologyKey string
+
+const (
+	PoolSpreadTopologyKey   SpreadTopologyKey = "Pool"
+	RegionSpreadTopologyKey SpreadTopologyKey = "Region"
+	ZoneSpreadTopologyKey   SpreadTopologyKey = "Zone"
+	SiteSpreadTopologyKey   SpreadTopologyKey = "Site"
+)
+
+// PoolSpreadConstraint defines a constraint on the replicas of the pools selected by PoolSelector.
+type PoolSpreadConstraint struct {
+	// PoolSelector is a label query over the NodePools of pools, and the constraint only applies
+	// to the selected pools. All pools are selected if unspecified.
+	// +optional
+	PoolSelector *metav1.LabelSelector `json:"poolSelector,omitempty"`
+
+	// MinReplicasPerPool is the lower limit of replicas of each selected pool. The replicas are taken
+	// from the pools with the most replicas above their own lower limits, and the total replicas grow
+	// if no pool can spare them.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicasPerPool *int32 `json:"minReplicasPerPool,omitempty"`
+
+	// TopologyKey is the topology of NodePools by which the selected pools are grouped into domains,
+	// one of Pool, Region, Zone and Site. Pools whose NodePools have no such topology are not grouped.
+	// Defaults to Pool.
+	// +kubebuilder:validation:Enum=Pool;Region;Zone;Site
+	// +optional
+	TopologyKey SpreadTopologyKey `json:"topologyKey,omitempty"`
+
+	// MaxPercentPerDomain is the upper limit of the percentage of total replicas in a domain,
+	// the replicas beyond it are moved to the pools out of the domain with the fewest replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxPercentPerDomain *int32 `json:"maxPercentPerDomain,omitempty"`
 }
 
 // Pool defines the detail of a pool.
@@ -134,6 +378,18 @@ type Pool struct {
 	// +required
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Indicates the number of the pod under this pool is proportional to the number of ready
+	// nodes in the NodePool, and it is recomputed as the NodePool grows or shrinks.
+	// Replicas is ignored if ProportionalReplicas is set.
+	// +optional
+	ProportionalReplicas *ProportionalReplicas `json:"proportionalReplicas,omitempty"`
+
+	// Indicates the weight of pool when the replicas of YurtAppSet are distributed across pools,
+	// it only takes effect when the replicas of YurtAppSet are set. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+
 	// Indicates the patch for the templateSpec
 	// Now support strategic merge path :https://kubernetes.io/docs/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/#notes-on-the-strategic-merge-patch
 	// Patch takes precedence over Replicas fields
@@ -141,6 +397,53 @@ type Pool struct {
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Patch *runtime.RawExtension `json:"patch,omitempty"`
+
+	// Indicates the volumeClaimTemplates of the StatefulSet under this pool, which override
+	// the templates with the same name in StatefulSetTemplate and add the others, so each
+	// pool can claim volumes from its local storage class. It only works for StatefulSetTemplate.
+	// A pool's volumeClaimTemplates is not allowed to be updated.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
+	// Indicates the bounds of replicas of the HorizontalPodAutoscaler generated for this pool.
+	// It only works when the autoscaling of YurtAppSet is specified.
+	// +optional
+	Autoscaling *PoolAutoscaling `json:"autoscaling,omitempty"`
+
+	// Indicates the replicas beyond the capacity of this pool are shifted to the overflow pools
+	// when the pool shrinks, and shifted back when the capacity of the pool recovers.
+	// +optional
+	Overflow *PoolOverflow `json:"overflow,omitempty"`
+}
+
+// PoolOverflow defines the capacity of a pool and where the replicas beyond the capacity go.
+type PoolOverflow struct {
+	// ReplicasPerNode is the number of replicas that a ready node of the pool can hold,
+	// the capacity of the pool is the number of ready nodes in the NodePool multiplied by it.
+	// +kubebuilder:validation:Minimum=1
+	ReplicasPerNode int32 `json:"replicasPerNode"`
+
+	// Pools are the overflow pools in order of preference. The replicas beyond the capacity are
+	// shifted to the first pool which has spare capacity, and a pool without overflow config is
+	// regarded as having unlimited capacity.
+	Pools []string `json:"pools"`
+}
+
+// ProportionalReplicas defines how to compute the replicas of a pool from the number of ready nodes.
+type ProportionalReplicas struct {
+	// Ratio is the percentage of replicas to ready nodes in the pool, for example, 50 means
+	// one replica for every two ready nodes. The result is rounded up.
+	// +kubebuilder:validation:Minimum=1
+	Ratio int32 `json:"ratio"`
+
+	// Min is the lower limit of replicas.
+	// +optional
+	Min *int32 `json:"min,omitempty"`
+
+	// Max is the upper limit of replicas.
+	// +optional
+	Max *int32 `json:"max,omitempty"`
 }
 
 // YurtAppSetStatus defines the observed state of YurtAppSet.
@@ -182,6 +485,76 @@ type YurtAppSetStatus struct {
 
 	// TemplateType indicates the type of PoolTemplate
 	TemplateType TemplateType `json:"templateType"`
+
+	// RolloutStatus records the progress of rollout across pools, it is set only
+	// when RolloutStrategy is specified.
+	// +optional
+	RolloutStatus *YurtAppSetRolloutStatus `json:"rolloutStatus,omitempty"`
+
+	// PoolStatuses records the status of workload in each pool, sorted by pool name.
+	// +optional
+	PoolStatuses []YurtAppSetPoolStatus `json:"poolStatuses,omitempty"`
+
+	// NotReadyPools are the pools whose workloads are not ready, not updated or failed.
+	// +optional
+	NotReadyPools []string `json:"notReadyPools,omitempty"`
+
+	// OverflowReplicas records the replicas which are shifted from pools to their overflow pools.
+	// +optional
+	OverflowReplicas []YurtAppSetOverflowReplicas `json:"overflowReplicas,omitempty"`
+}
+
+// YurtAppSetOverflowReplicas records the replicas shifted from a pool to an overflow pool.
+type YurtAppSetOverflowReplicas struct {
+	// Pool is the pool whose capacity is not enough for its replicas.
+	Pool string `json:"pool"`
+
+	// OverflowPool is the pool which the replicas are shifted to.
+	OverflowPool string `json:"overflowPool"`
+
+	// Replicas is the number of replicas shifted.
+	Replicas int32 `json:"replicas"`
+}
+
+// YurtAppSetPoolStatus defines the observed state of workload in a pool.
+type YurtAppSetPoolStatus struct {
+	// Name is the name of pool.
+	Name string `json:"name"`
+
+	// WorkloadName is the name of workload in the pool.
+	// +optional
+	WorkloadName string `json:"workloadName,omitempty"`
+
+	// Revision is the revision of workload in the pool.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Replicas is the most recently observed number of replicas in the pool.
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of ready replicas in the pool.
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of replicas updated to the revision of workload in the pool.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// Conditions of the pool, includes PoolReady, PoolUpdated and PoolFailure.
+	// +optional
+	Conditions []YurtAppSetCondition `json:"conditions,omitempty"`
+}
+
+// YurtAppSetRolloutStatus defines the progress of rollout across pools.
+type YurtAppSetRolloutStatus struct {
+	// Revision is the revision which is rolled out across pools.
+	Revision string `json:"revision"`
+
+	// UpdatedPools are the pools which are updated to the revision and pass their health gates.
+	// +optional
+	UpdatedPools []string `json:"updatedPools,omitempty"`
+
+	// UpdatingPools are the pools which are being updated to the revision or waiting for their health gates.
+	// +optional
+	UpdatingPools []string `json:"updatingPools,omitempty"`
 }
 
 type WorkloadSummary struct {
@@ -215,6 +588,7 @@ type YurtAppSetCondition struct {
 // +kubebuilder:resource:shortName=yas
 // +kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.readyReplicas",description="The number of pods ready."
 // +kubebuilder:printcolumn:name="WorkloadTemplate",type="string",JSONPath=".status.templateType",description="The WorkloadTemplate Type."
+// +kubebuilder:printcolumn:name="NOT-READY-POOLS",type="string",JSONPath=".status.notReadyPools",description="The pools whose workloads are not ready, not updated or failed."
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
 // +kubebuilder:printcolumn:name="OverriderRef",type="string",JSONPath=".status.overriderRef",description="The name of overrider bound to this yurtappset"
 
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// YurtCoordinatorStatus defines the observed state of yurt-coordinator in a NodePool
+type YurtCoordinatorStatus struct {
+	// LeaderHub is the name of node whose yurthub is the leader in the NodePool
+	// and reports the status.
+	// +optional
+	LeaderHub string `json:"leaderHub,omitempty"`
+
+	// StorageBackend is the storage backend of yurt-coordinator, etcd or kine.
+	// +optional
+	StorageBackend string `json:"storageBackend,omitempty"`
+
+	// StorageSize is the size of yurt-coordinator storage in bytes.
+	// +optional
+	StorageSize int64 `json:"storageSize,omitempty"`
+
+	// LastSyncTime is the last time when the pool-scoped resources in yurt-coordinator
+	// are confirmed to be synced with cloud.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// ConnectedYurtHubs is the number of yurthubs which renew their node leases
+	// in yurt-coordinator recently.
+	// +optional
+	ConnectedYurtHubs int32 `json:"connectedYurtHubs,omitempty"`
+
+	// DelegatedLeases is the number of node leases which are delegated to cloud
+	// by the leader yurthub currently.
+	// +optional
+	DelegatedLeases int32 `json:"delegatedLeases,omitempty"`
+
+	// LastUpdateTime is the last time when the status is reported by the leader yurthub,
+	// the yurt-coordinator is probably unreachable if it's not updated for a while.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Conditions represents the latest available observations of yurt-coordinator's current state.
+	// +optional
+	Conditions []YurtCoordinatorCondition `json:"conditions,omitempty"`
+}
+
+// YurtCoordinatorConditionType indicates valid conditions type of a YurtCoordinator.
+type YurtCoordinatorConditionType string
+
+const (
+	// YurtCoordinatorReady means the pool-scoped resources in yurt-coordinator are synced
+	// with cloud and its storage is available.
+	YurtCoordinatorReady YurtCoordinatorConditionType = "Ready"
+)
+
+// YurtCoordinatorCondition describes current state of a YurtCoordinator.
+type YurtCoordinatorCondition struct {
+	// Type of YurtCoordinator condition.
+	Type YurtCoordinatorConditionType `json:"type,omitempty"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=yc
+// +kubebuilder:printcolumn:name="LeaderHub",type="string",JSONPath=".status.leaderHub",description="The node whose yurthub is the leader in the NodePool."
+// +kubebuilder:printcolumn:name="Backend",type="string",JSONPath=".status.storageBackend",description="The storage backend of yurt-coordinator."
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether yurt-coordinator is ready."
+// +kubebuilder:printcolumn:name="YurtHubs",type="integer",JSONPath=".status.connectedYurtHubs",description="The number of yurthubs connected to yurt-coordinator."
+// +kubebuilder:printcolumn:name="LastUpdate",type="date",JSONPath=".status.lastUpdateTime",description="The last time when the status is reported."
+
+// YurtCoordinator records the status of yurt-coordinator in the NodePool with the same name,
+// it's reported by the leader yurthub in the NodePool periodically.
+type YurtCoordinator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status YurtCoordinatorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// YurtCoordinatorList contains a list of YurtCoordinator
+type YurtCoordinatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []YurtCoordinator `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&YurtCoordinator{}, &YurtCoordinatorList{})
+}
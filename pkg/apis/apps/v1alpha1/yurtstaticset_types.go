@@ -30,6 +30,87 @@ type YurtStaticSetUpgradeStrategy struct {
 	// AdvancedRollingUpdate upgrade config params. Present only if type = "AdvancedRollingUpdate".
 	//+optional
 	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// RollbackWindow is the duration in which the upgraded static pod must be running and ready,
+	// otherwise the previous manifest is restored on the node automatically. Defaults to 2m.
+	//+optional
+	RollbackWindow *metav1.Duration `json:"rollbackWindow,omitempty"`
+
+	// MaintenanceWindows are the periods in which static pods can be upgraded, they are evaluated
+	// in the time zone of the NodePool that each node belongs to. If not specified, static pods can
+	// be upgraded at any time.
+	//+optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Canary upgrades the static pods in the canary NodePools first, and promotes the upgrade to
+	// the remaining nodes only after the canary NodePools stay healthy for the soak period.
+	//+optional
+	Canary *YurtStaticSetCanaryStrategy `json:"canary,omitempty"`
+
+	// PrePull downloads the images of the latest static pods to nodes before their manifests are switched,
+	// so that the static pods are only unavailable for the time of restart even on slow links. Nodes are
+	// not upgraded until the images are pulled if it's specified.
+	//+optional
+	PrePull *YurtStaticSetPrePullStrategy `json:"prePull,omitempty"`
+
+	// HealthGate is the checks which the upgraded static pod must pass on node before the node is counted
+	// as upgraded, the previous manifest is restored if they are not passed within the rollback window.
+	// If not specified, the node is counted as upgraded once the latest static pod is running.
+	//+optional
+	HealthGate *YurtStaticSetHealthGate `json:"healthGate,omitempty"`
+}
+
+// YurtStaticSetHealthGate defines the post-upgrade checks of static pod.
+type YurtStaticSetHealthGate struct {
+	// ReadinessPassCount is the number of consecutive checks, every 5 seconds, in which the upgraded
+	// static pod must be ready. Defaults to 1.
+	//+optional
+	ReadinessPassCount int32 `json:"readinessPassCount,omitempty"`
+
+	// HTTPGet is the HTTP check performed on node after the static pod is ready, it passes if the
+	// response code is in [200, 400). The host defaults to 127.0.0.1.
+	//+optional
+	HTTPGet *corev1.HTTPGetAction `json:"httpGet,omitempty"`
+
+	// MinUptime is how long the upgraded static pod must stay ready without container restarts.
+	// It extends the rollback window, because it's counted after the static pod is ready.
+	//+optional
+	MinUptime *metav1.Duration `json:"minUptime,omitempty"`
+}
+
+// YurtStaticSetPrePullStrategy defines when and how fast the images of static pods are pre-pulled.
+type YurtStaticSetPrePullStrategy struct {
+	// Windows are the periods in which image pulls can be started, they are evaluated in the time zone
+	// of the NodePool that each node belongs to. If not specified, images can be pulled at any time.
+	//+optional
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// MaxPullingNodesPerPool caps the bandwidth consumed by image pulls in each NodePool, it's the maximum
+	// number or percentage of nodes in a NodePool which pull images at the same time. Nodes which don't
+	// belong to any NodePool are treated as a pool. Defaults to 1.
+	//+optional
+	MaxPullingNodesPerPool *intstr.IntOrString `json:"maxPullingNodesPerPool,omitempty"`
+}
+
+// YurtStaticSetCanaryStrategy defines the canary NodePools and how long they must stay healthy.
+type YurtStaticSetCanaryStrategy struct {
+	// PoolSelector selects the canary NodePools by their labels.
+	PoolSelector *metav1.LabelSelector `json:"poolSelector"`
+
+	// SoakPeriod is how long all static pods in the canary NodePools must be upgraded and ready
+	// before the upgrade is promoted to the remaining nodes. Defaults to 10m.
+	//+optional
+	SoakPeriod *metav1.Duration `json:"soakPeriod,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring period in which node components can be upgraded.
+type MaintenanceWindow struct {
+	// Schedule is the start time of the window in cron format with five fields:
+	// minute, hour, day of month, month and day of week, like "0 2 * * 6" for 2am on every Saturday.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts after it starts.
+	Duration metav1.Duration `json:"duration"`
 }
 
 // YurtStaticSetUpgradeStrategyType is a strategy according to which static pods gets upgraded.
@@ -55,10 +136,31 @@ type YurtStaticSetSpec struct {
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 
 	// An object that describes the desired spec of static pod.
+	// The placeholders {{NODE_NAME}}, {{NODE_IP}}, {{POOL_NAME}} and {{NODE_LABEL:<key>}} in string fields
+	// are rendered with the values of each node when the static pod is upgraded on it.
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Schemaless
 	Template corev1.PodTemplateSpec `json:"template,omitempty"`
+
+	// BundledManifests are the static pods coupled with the main one, like a node-local registry mirror of yurthub.
+	// They are upgraded together with the main static pod on each node, and all of them are rolled back if any
+	// of them is not ready within the rollback window. Static pods removed from the bundle are kept on nodes.
+	// +optional
+	BundledManifests []YurtStaticSetBundledManifest `json:"bundledManifests,omitempty"`
+}
+
+// YurtStaticSetBundledManifest defines a static pod which is upgraded together with the main static pod.
+type YurtStaticSetBundledManifest struct {
+	// StaticPodManifest indicates the file name of the static pod manifest, it must be different from the
+	// manifest of main static pod and other bundled ones.
+	StaticPodManifest string `json:"staticPodManifest"`
+
+	// An object that describes the desired spec of the static pod. Its name defaults to
+	// `<YurtStaticSet name>-<StaticPodManifest>`, and the placeholders are rendered like the main template.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Template corev1.PodTemplateSpec `json:"template"`
 }
 
 // YurtStaticSetStatus defines the observed state of YurtStaticSet
@@ -75,6 +177,54 @@ type YurtStaticSetStatus struct {
 	// The most recent generation observed by the static pod controller.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// NodeStatuses records the upgrade state of static pod on each node.
+	// +optional
+	NodeStatuses []YurtStaticSetNodeStatus `json:"nodeStatuses,omitempty"`
+
+	// CanaryStatus records the canary state of the latest static pod.
+	// +optional
+	CanaryStatus *YurtStaticSetCanaryStatus `json:"canaryStatus,omitempty"`
+}
+
+// YurtStaticSetCanaryStatus defines the canary state of the latest static pod.
+type YurtStaticSetCanaryStatus struct {
+	// Hash is the hash of the static pod which is in canary.
+	Hash string `json:"hash"`
+
+	// HealthySince is the time since which all static pods in the canary NodePools are upgraded and ready.
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+
+	// Promoted means the canary NodePools have soaked and the upgrade is promoted to the remaining nodes.
+	// +optional
+	Promoted bool `json:"promoted,omitempty"`
+}
+
+// YurtStaticSetNodeUpgradeState is the upgrade state of static pod on a node.
+type YurtStaticSetNodeUpgradeState string
+
+const (
+	// NodeUpgradePending means the static pod on the node is not up-to-date, and the upgrade is not staged.
+	NodeUpgradePending YurtStaticSetNodeUpgradeState = "Pending"
+	// NodeUpgradeStaged means the latest manifest is staged on the node and waits for confirmation.
+	NodeUpgradeStaged YurtStaticSetNodeUpgradeState = "Staged"
+	// NodeUpgradeUpgrading means the upgrade is confirmed and the latest static pod is not running yet.
+	NodeUpgradeUpgrading YurtStaticSetNodeUpgradeState = "Upgrading"
+	// NodeUpgradeUpgraded means the node is running the latest static pod.
+	NodeUpgradeUpgraded YurtStaticSetNodeUpgradeState = "Upgraded"
+	// NodeUpgradeRolledBack means the latest static pod failed to be ready within the rollback window,
+	// and the previous manifest is restored on the node.
+	NodeUpgradeRolledBack YurtStaticSetNodeUpgradeState = "RolledBack"
+)
+
+// YurtStaticSetNodeStatus defines the upgrade state of static pod on a node.
+type YurtStaticSetNodeStatus struct {
+	// NodeName is the name of node.
+	NodeName string `json:"nodeName"`
+
+	// State is the upgrade state of static pod on the node.
+	State YurtStaticSetNodeUpgradeState `json:"state"`
 }
 
 // +genclient
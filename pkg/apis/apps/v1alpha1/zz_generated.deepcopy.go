@@ -22,105 +22,113 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
+func (in *BundleTemplate) DeepCopyInto(out *BundleTemplate) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.ServiceTemplate != nil {
+		in, out := &in.ServiceTemplate, &out.ServiceTemplate
+		*out = new(ServiceTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapTemplate != nil {
+		in, out := &in.ConfigMapTemplate, &out.ConfigMapTemplate
+		*out = new(ConfigMapTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTemplateSpec.
-func (in *DeploymentTemplateSpec) DeepCopy() *DeploymentTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleTemplate.
+func (in *BundleTemplate) DeepCopy() *BundleTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentTemplateSpec)
+	out := new(BundleTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Entry) DeepCopyInto(out *Entry) {
+func (in *ConfigMapTemplateSpec) DeepCopyInto(out *ConfigMapTemplateSpec) {
 	*out = *in
-	if in.Pools != nil {
-		in, out := &in.Pools, &out.Pools
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Item, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Patches != nil {
-		in, out := &in.Patches, &out.Patches
-		*out = make([]Patch, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Entry.
-func (in *Entry) DeepCopy() *Entry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapTemplateSpec.
+func (in *ConfigMapTemplateSpec) DeepCopy() *ConfigMapTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Entry)
+	out := new(ConfigMapTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageItem) DeepCopyInto(out *ImageItem) {
+func (in *CronJobTemplateSpec) DeepCopyInto(out *CronJobTemplateSpec) {
 	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageItem.
-func (in *ImageItem) DeepCopy() *ImageItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobTemplateSpec.
+func (in *CronJobTemplateSpec) DeepCopy() *CronJobTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageItem)
+	out := new(CronJobTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Item) DeepCopyInto(out *Item) {
+func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
 	*out = *in
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(ImageItem)
-		**out = **in
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTemplateSpec.
+func (in *DeploymentTemplateSpec) DeepCopy() *DeploymentTemplateSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(DeploymentTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Item.
-func (in *Item) DeepCopy() *Item {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIPAllocation) DeepCopyInto(out *EdgeIPAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPAllocation.
+func (in *EdgeIPAllocation) DeepCopy() *EdgeIPAllocation {
 	if in == nil {
 		return nil
 	}
-	out := new(Item)
+	out := new(EdgeIPAllocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePool) DeepCopyInto(out *NodePool) {
+func (in *EdgeIPPool) DeepCopyInto(out *EdgeIPPool) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -128,18 +136,18 @@ func (in *NodePool) DeepCopyInto(out *NodePool) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
-func (in *NodePool) DeepCopy() *NodePool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPPool.
+func (in *EdgeIPPool) DeepCopy() *EdgeIPPool {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePool)
+	out := new(EdgeIPPool)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodePool) DeepCopyObject() runtime.Object {
+func (in *EdgeIPPool) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -147,31 +155,47 @@ func (in *NodePool) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePoolList) DeepCopyInto(out *NodePoolList) {
+func (in *EdgeIPPoolCondition) DeepCopyInto(out *EdgeIPPoolCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPPoolCondition.
+func (in *EdgeIPPoolCondition) DeepCopy() *EdgeIPPoolCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIPPoolCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIPPoolList) DeepCopyInto(out *EdgeIPPoolList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NodePool, len(*in))
+		*out = make([]EdgeIPPool, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolList.
-func (in *NodePoolList) DeepCopy() *NodePoolList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPPoolList.
+func (in *EdgeIPPoolList) DeepCopy() *EdgeIPPoolList {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePoolList)
+	out := new(EdgeIPPoolList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodePoolList) DeepCopyObject() runtime.Object {
+func (in *EdgeIPPoolList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -179,212 +203,1004 @@ func (in *NodePoolList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
+func (in *EdgeIPPoolSpec) DeepCopyInto(out *EdgeIPPoolSpec) {
 	*out = *in
-	if in.Selector != nil {
-		in, out := &in.Selector, &out.Selector
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPPoolSpec.
+func (in *EdgeIPPoolSpec) DeepCopy() *EdgeIPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIPPoolStatus) DeepCopyInto(out *EdgeIPPoolStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]EdgeIPAllocation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]EdgeIPPoolCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIPPoolStatus.
+func (in *EdgeIPPoolStatus) DeepCopy() *EdgeIPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Entry) DeepCopyInto(out *Entry) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PoolSelector != nil {
+		in, out := &in.PoolSelector, &out.PoolSelector
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Item, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]Patch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Taints != nil {
-		in, out := &in.Taints, &out.Taints
-		*out = make([]corev1.Taint, len(*in))
+	if in.JSONPatch != nil {
+		in, out := &in.JSONPatch, &out.JSONPatch
+		*out = make([]JSONPatchOperation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
-func (in *NodePoolSpec) DeepCopy() *NodePoolSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Entry.
+func (in *Entry) DeepCopy() *Entry {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePoolSpec)
+	out := new(Entry)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
+func (in *ImageItem) DeepCopyInto(out *ImageItem) {
 	*out = *in
-	if in.Nodes != nil {
-		in, out := &in.Nodes, &out.Nodes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
-func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageItem.
+func (in *ImageItem) DeepCopy() *ImageItem {
 	if in == nil {
 		return nil
 	}
-	out := new(NodePoolStatus)
+	out := new(ImageItem)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Patch) DeepCopyInto(out *Patch) {
+func (in *ImageRegistryItem) DeepCopyInto(out *ImageRegistryItem) {
 	*out = *in
-	in.Value.DeepCopyInto(&out.Value)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Patch.
-func (in *Patch) DeepCopy() *Patch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRegistryItem.
+func (in *ImageRegistryItem) DeepCopy() *ImageRegistryItem {
 	if in == nil {
 		return nil
 	}
-	out := new(Patch)
+	out := new(ImageRegistryItem)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Pool) DeepCopyInto(out *Pool) {
+func (in *Item) DeepCopyInto(out *Item) {
 	*out = *in
-	in.NodeSelectorTerm.DeepCopyInto(&out.NodeSelectorTerm)
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageItem)
+		**out = **in
+	}
+	if in.ImageRegistry != nil {
+		in, out := &in.ImageRegistry, &out.ImageRegistry
+		*out = new(ImageRegistryItem)
+		**out = **in
 	}
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
 		**out = **in
 	}
-	if in.Patch != nil {
-		in, out := &in.Patch, &out.Patch
-		*out = new(runtime.RawExtension)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pool.
-func (in *Pool) DeepCopy() *Pool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Item.
+func (in *Item) DeepCopy() *Item {
 	if in == nil {
 		return nil
 	}
-	out := new(Pool)
+	out := new(Item)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatefulSetTemplateSpec) DeepCopyInto(out *StatefulSetTemplateSpec) {
+func (in *JSONPatchOperation) DeepCopyInto(out *JSONPatchOperation) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetTemplateSpec.
-func (in *StatefulSetTemplateSpec) DeepCopy() *StatefulSetTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSONPatchOperation.
+func (in *JSONPatchOperation) DeepCopy() *JSONPatchOperation {
 	if in == nil {
 		return nil
 	}
-	out := new(StatefulSetTemplateSpec)
+	out := new(JSONPatchOperation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Subject) DeepCopyInto(out *Subject) {
+func (in *JobTemplateSpec) DeepCopyInto(out *JobTemplateSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subject.
-func (in *Subject) DeepCopy() *Subject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobTemplateSpec.
+func (in *JobTemplateSpec) DeepCopy() *JobTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Subject)
+	out := new(JobTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Topology) DeepCopyInto(out *Topology) {
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
-	if in.Pools != nil {
-		in, out := &in.Pools, &out.Pools
-		*out = make([]Pool, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.Duration = in.Duration
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
-func (in *Topology) DeepCopy() *Topology {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(Topology)
+	out := new(MaintenanceWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WorkloadSummary) DeepCopyInto(out *WorkloadSummary) {
+func (in *NodePool) DeepCopyInto(out *NodePool) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSummary.
-func (in *WorkloadSummary) DeepCopy() *WorkloadSummary {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
+func (in *NodePool) DeepCopy() *NodePool {
 	if in == nil {
 		return nil
 	}
-	out := new(WorkloadSummary)
+	out := new(NodePool)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WorkloadTemplate) DeepCopyInto(out *WorkloadTemplate) {
+func (in *NodePoolList) DeepCopyInto(out *NodePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolList.
+func (in *NodePoolList) DeepCopy() *NodePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolQuota) DeepCopyInto(out *NodePoolQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolQuota.
+func (in *NodePoolQuota) DeepCopy() *NodePoolQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolQuotaList) DeepCopyInto(out *NodePoolQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodePoolQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolQuotaList.
+func (in *NodePoolQuotaList) DeepCopy() *NodePoolQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodePoolQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolQuotaSpec) DeepCopyInto(out *NodePoolQuotaSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hard != nil {
+		in, out := &in.Hard, &out.Hard
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolQuotaSpec.
+func (in *NodePoolQuotaSpec) DeepCopy() *NodePoolQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
+func (in *NodePoolSpec) DeepCopy() *NodePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
+func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Patch) DeepCopyInto(out *Patch) {
+	*out = *in
+	in.Value.DeepCopyInto(&out.Value)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Patch.
+func (in *Patch) DeepCopy() *Patch {
+	if in == nil {
+		return nil
+	}
+	out := new(Patch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pool) DeepCopyInto(out *Pool) {
+	*out = *in
+	in.NodeSelectorTerm.DeepCopyInto(&out.NodeSelectorTerm)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProportionalReplicas != nil {
+		in, out := &in.ProportionalReplicas, &out.ProportionalReplicas
+		*out = new(ProportionalReplicas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Patch != nil {
+		in, out := &in.Patch, &out.Patch
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]corev1.PersistentVolumeClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(PoolAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overflow != nil {
+		in, out := &in.Overflow, &out.Overflow
+		*out = new(PoolOverflow)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pool.
+func (in *Pool) DeepCopy() *Pool {
+	if in == nil {
+		return nil
+	}
+	out := new(Pool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolAutoscaling) DeepCopyInto(out *PoolAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolAutoscaling.
+func (in *PoolAutoscaling) DeepCopy() *PoolAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolConfigTemplate) DeepCopyInto(out *PoolConfigTemplate) {
+	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolConfigTemplate.
+func (in *PoolConfigTemplate) DeepCopy() *PoolConfigTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolConfigTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolOverflow) DeepCopyInto(out *PoolOverflow) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolOverflow.
+func (in *PoolOverflow) DeepCopy() *PoolOverflow {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolOverflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolSpreadConstraint) DeepCopyInto(out *PoolSpreadConstraint) {
+	*out = *in
+	if in.PoolSelector != nil {
+		in, out := &in.PoolSelector, &out.PoolSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinReplicasPerPool != nil {
+		in, out := &in.MinReplicasPerPool, &out.MinReplicasPerPool
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPercentPerDomain != nil {
+		in, out := &in.MaxPercentPerDomain, &out.MaxPercentPerDomain
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpreadConstraint.
+func (in *PoolSpreadConstraint) DeepCopy() *PoolSpreadConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolSpreadConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProportionalReplicas) DeepCopyInto(out *ProportionalReplicas) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProportionalReplicas.
+func (in *ProportionalReplicas) DeepCopy() *ProportionalReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(ProportionalReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPool) DeepCopyInto(out *RolloutPool) {
+	*out = *in
+	if in.MinReadyPercent != nil {
+		in, out := &in.MinReadyPercent, &out.MinReadyPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPool.
+func (in *RolloutPool) DeepCopy() *RolloutPool {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceTemplateSpec) DeepCopyInto(out *ServiceTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceTemplateSpec.
+func (in *ServiceTemplateSpec) DeepCopy() *ServiceTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetTemplateSpec) DeepCopyInto(out *StatefulSetTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetTemplateSpec.
+func (in *StatefulSetTemplateSpec) DeepCopy() *StatefulSetTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Subject) DeepCopyInto(out *Subject) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subject.
+func (in *Subject) DeepCopy() *Subject {
+	if in == nil {
+		return nil
+	}
+	out := new(Subject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Topology) DeepCopyInto(out *Topology) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]Pool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SpreadConstraints != nil {
+		in, out := &in.SpreadConstraints, &out.SpreadConstraints
+		*out = make([]PoolSpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
+func (in *Topology) DeepCopy() *Topology {
+	if in == nil {
+		return nil
+	}
+	out := new(Topology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSummary) DeepCopyInto(out *WorkloadSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSummary.
+func (in *WorkloadSummary) DeepCopy() *WorkloadSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadTemplate) DeepCopyInto(out *WorkloadTemplate) {
 	*out = *in
 	if in.StatefulSetTemplate != nil {
 		in, out := &in.StatefulSetTemplate, &out.StatefulSetTemplate
 		*out = new(StatefulSetTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.DeploymentTemplate != nil {
-		in, out := &in.DeploymentTemplate, &out.DeploymentTemplate
-		*out = new(DeploymentTemplateSpec)
+	if in.DeploymentTemplate != nil {
+		in, out := &in.DeploymentTemplate, &out.DeploymentTemplate
+		*out = new(DeploymentTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobTemplate != nil {
+		in, out := &in.JobTemplate, &out.JobTemplate
+		*out = new(JobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CronJobTemplate != nil {
+		in, out := &in.CronJobTemplate, &out.CronJobTemplate
+		*out = new(CronJobTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTemplate.
+func (in *WorkloadTemplate) DeepCopy() *WorkloadTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppDaemon) DeepCopyInto(out *YurtAppDaemon) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemon.
+func (in *YurtAppDaemon) DeepCopy() *YurtAppDaemon {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppDaemon)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *YurtAppDaemon) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppDaemonCondition) DeepCopyInto(out *YurtAppDaemonCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonCondition.
+func (in *YurtAppDaemonCondition) DeepCopy() *YurtAppDaemonCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppDaemonCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppDaemonList) DeepCopyInto(out *YurtAppDaemonList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]YurtAppDaemon, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonList.
+func (in *YurtAppDaemonList) DeepCopy() *YurtAppDaemonList {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppDaemonList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *YurtAppDaemonList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppDaemonSpec) DeepCopyInto(out *YurtAppDaemonSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.WorkloadTemplate.DeepCopyInto(&out.WorkloadTemplate)
+	if in.NodePoolSelector != nil {
+		in, out := &in.NodePoolSelector, &out.NodePoolSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(YurtAppDaemonUpdateStrategy)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTemplate.
-func (in *WorkloadTemplate) DeepCopy() *WorkloadTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonSpec.
+func (in *YurtAppDaemonSpec) DeepCopy() *YurtAppDaemonSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(WorkloadTemplate)
+	out := new(YurtAppDaemonSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppDaemon) DeepCopyInto(out *YurtAppDaemon) {
+func (in *YurtAppDaemonStatus) DeepCopyInto(out *YurtAppDaemonStatus) {
+	*out = *in
+	if in.CollisionCount != nil {
+		in, out := &in.CollisionCount, &out.CollisionCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]YurtAppDaemonCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WorkloadSummaries != nil {
+		in, out := &in.WorkloadSummaries, &out.WorkloadSummaries
+		*out = make([]WorkloadSummary, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonStatus.
+func (in *YurtAppDaemonStatus) DeepCopy() *YurtAppDaemonStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppDaemonStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppDaemonUpdateStrategy) DeepCopyInto(out *YurtAppDaemonUpdateStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonUpdateStrategy.
+func (in *YurtAppDaemonUpdateStrategy) DeepCopy() *YurtAppDaemonUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppDaemonUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppOverrider) DeepCopyInto(out *YurtAppOverrider) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Subject = in.Subject
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]Entry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppOverrider.
+func (in *YurtAppOverrider) DeepCopy() *YurtAppOverrider {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppOverrider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *YurtAppOverrider) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppOverriderList) DeepCopyInto(out *YurtAppOverriderList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]YurtAppOverrider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppOverriderList.
+func (in *YurtAppOverriderList) DeepCopy() *YurtAppOverriderList {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppOverriderList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *YurtAppOverriderList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSet) DeepCopyInto(out *YurtAppSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -392,18 +1208,18 @@ func (in *YurtAppDaemon) DeepCopyInto(out *YurtAppDaemon) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemon.
-func (in *YurtAppDaemon) DeepCopy() *YurtAppDaemon {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSet.
+func (in *YurtAppSet) DeepCopy() *YurtAppSet {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppDaemon)
+	out := new(YurtAppSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppDaemon) DeepCopyObject() runtime.Object {
+func (in *YurtAppSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -411,55 +1227,181 @@ func (in *YurtAppDaemon) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppDaemonCondition) DeepCopyInto(out *YurtAppDaemonCondition) {
+func (in *YurtAppSetAutoscaling) DeepCopyInto(out *YurtAppSetAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2beta2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetAutoscaling.
+func (in *YurtAppSetAutoscaling) DeepCopy() *YurtAppSetAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetCondition) DeepCopyInto(out *YurtAppSetCondition) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonCondition.
-func (in *YurtAppDaemonCondition) DeepCopy() *YurtAppDaemonCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetCondition.
+func (in *YurtAppSetCondition) DeepCopy() *YurtAppSetCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppDaemonCondition)
+	out := new(YurtAppSetCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppDaemonList) DeepCopyInto(out *YurtAppDaemonList) {
+func (in *YurtAppSetList) DeepCopyInto(out *YurtAppSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]YurtAppDaemon, len(*in))
+		*out = make([]YurtAppSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonList.
-func (in *YurtAppDaemonList) DeepCopy() *YurtAppDaemonList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetList.
+func (in *YurtAppSetList) DeepCopy() *YurtAppSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppDaemonList)
+	out := new(YurtAppSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppDaemonList) DeepCopyObject() runtime.Object {
+func (in *YurtAppSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetOverflowReplicas) DeepCopyInto(out *YurtAppSetOverflowReplicas) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetOverflowReplicas.
+func (in *YurtAppSetOverflowReplicas) DeepCopy() *YurtAppSetOverflowReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetOverflowReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetPoolStatus) DeepCopyInto(out *YurtAppSetPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]YurtAppSetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetPoolStatus.
+func (in *YurtAppSetPoolStatus) DeepCopy() *YurtAppSetPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetRollbackConfig) DeepCopyInto(out *YurtAppSetRollbackConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetRollbackConfig.
+func (in *YurtAppSetRollbackConfig) DeepCopy() *YurtAppSetRollbackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetRollbackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetRolloutStatus) DeepCopyInto(out *YurtAppSetRolloutStatus) {
+	*out = *in
+	if in.UpdatedPools != nil {
+		in, out := &in.UpdatedPools, &out.UpdatedPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdatingPools != nil {
+		in, out := &in.UpdatingPools, &out.UpdatingPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetRolloutStatus.
+func (in *YurtAppSetRolloutStatus) DeepCopy() *YurtAppSetRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtAppSetRolloutStrategy) DeepCopyInto(out *YurtAppSetRolloutStrategy) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]RolloutPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetRolloutStrategy.
+func (in *YurtAppSetRolloutStrategy) DeepCopy() *YurtAppSetRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtAppSetRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppDaemonSpec) DeepCopyInto(out *YurtAppDaemonSpec) {
+func (in *YurtAppSetSpec) DeepCopyInto(out *YurtAppSetSpec) {
 	*out = *in
 	if in.Selector != nil {
 		in, out := &in.Selector, &out.Selector
@@ -467,30 +1409,60 @@ func (in *YurtAppDaemonSpec) DeepCopyInto(out *YurtAppDaemonSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.WorkloadTemplate.DeepCopyInto(&out.WorkloadTemplate)
-	if in.NodePoolSelector != nil {
-		in, out := &in.NodePoolSelector, &out.NodePoolSelector
-		*out = new(v1.LabelSelector)
-		(*in).DeepCopyInto(*out)
+	in.Topology.DeepCopyInto(&out.Topology)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
 	}
 	if in.RevisionHistoryLimit != nil {
 		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(YurtAppSetRolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(YurtAppSetRollbackConfig)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(YurtAppSetAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigTemplates != nil {
+		in, out := &in.ConfigTemplates, &out.ConfigTemplates
+		*out = make([]PoolConfigTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bundle != nil {
+		in, out := &in.Bundle, &out.Bundle
+		*out = make([]BundleTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonSpec.
-func (in *YurtAppDaemonSpec) DeepCopy() *YurtAppDaemonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetSpec.
+func (in *YurtAppSetSpec) DeepCopy() *YurtAppSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppDaemonSpec)
+	out := new(YurtAppSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppDaemonStatus) DeepCopyInto(out *YurtAppDaemonStatus) {
+func (in *YurtAppSetStatus) DeepCopyInto(out *YurtAppSetStatus) {
 	*out = *in
 	if in.CollisionCount != nil {
 		in, out := &in.CollisionCount, &out.CollisionCount
@@ -499,7 +1471,7 @@ func (in *YurtAppDaemonStatus) DeepCopyInto(out *YurtAppDaemonStatus) {
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]YurtAppDaemonCondition, len(*in))
+		*out = make([]YurtAppSetCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -509,50 +1481,67 @@ func (in *YurtAppDaemonStatus) DeepCopyInto(out *YurtAppDaemonStatus) {
 		*out = make([]WorkloadSummary, len(*in))
 		copy(*out, *in)
 	}
-	if in.NodePools != nil {
-		in, out := &in.NodePools, &out.NodePools
+	if in.PoolReplicas != nil {
+		in, out := &in.PoolReplicas, &out.PoolReplicas
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RolloutStatus != nil {
+		in, out := &in.RolloutStatus, &out.RolloutStatus
+		*out = new(YurtAppSetRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PoolStatuses != nil {
+		in, out := &in.PoolStatuses, &out.PoolStatuses
+		*out = make([]YurtAppSetPoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NotReadyPools != nil {
+		in, out := &in.NotReadyPools, &out.NotReadyPools
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OverflowReplicas != nil {
+		in, out := &in.OverflowReplicas, &out.OverflowReplicas
+		*out = make([]YurtAppSetOverflowReplicas, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppDaemonStatus.
-func (in *YurtAppDaemonStatus) DeepCopy() *YurtAppDaemonStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetStatus.
+func (in *YurtAppSetStatus) DeepCopy() *YurtAppSetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppDaemonStatus)
+	out := new(YurtAppSetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppOverrider) DeepCopyInto(out *YurtAppOverrider) {
+func (in *YurtCoordinator) DeepCopyInto(out *YurtCoordinator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Subject = in.Subject
-	if in.Entries != nil {
-		in, out := &in.Entries, &out.Entries
-		*out = make([]Entry, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppOverrider.
-func (in *YurtAppOverrider) DeepCopy() *YurtAppOverrider {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtCoordinator.
+func (in *YurtCoordinator) DeepCopy() *YurtCoordinator {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppOverrider)
+	out := new(YurtCoordinator)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppOverrider) DeepCopyObject() runtime.Object {
+func (in *YurtCoordinator) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -560,31 +1549,47 @@ func (in *YurtAppOverrider) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppOverriderList) DeepCopyInto(out *YurtAppOverriderList) {
+func (in *YurtCoordinatorCondition) DeepCopyInto(out *YurtCoordinatorCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtCoordinatorCondition.
+func (in *YurtCoordinatorCondition) DeepCopy() *YurtCoordinatorCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtCoordinatorCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtCoordinatorList) DeepCopyInto(out *YurtCoordinatorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]YurtAppOverrider, len(*in))
+		*out = make([]YurtCoordinator, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppOverriderList.
-func (in *YurtAppOverriderList) DeepCopy() *YurtAppOverriderList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtCoordinatorList.
+func (in *YurtCoordinatorList) DeepCopy() *YurtCoordinatorList {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppOverriderList)
+	out := new(YurtCoordinatorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppOverriderList) DeepCopyObject() runtime.Object {
+func (in *YurtCoordinatorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -592,7 +1597,37 @@ func (in *YurtAppOverriderList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppSet) DeepCopyInto(out *YurtAppSet) {
+func (in *YurtCoordinatorStatus) DeepCopyInto(out *YurtCoordinatorStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]YurtCoordinatorCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtCoordinatorStatus.
+func (in *YurtCoordinatorStatus) DeepCopy() *YurtCoordinatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtCoordinatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtStaticSet) DeepCopyInto(out *YurtStaticSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -600,18 +1635,18 @@ func (in *YurtAppSet) DeepCopyInto(out *YurtAppSet) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSet.
-func (in *YurtAppSet) DeepCopy() *YurtAppSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSet.
+func (in *YurtStaticSet) DeepCopy() *YurtStaticSet {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppSet)
+	out := new(YurtStaticSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppSet) DeepCopyObject() runtime.Object {
+func (in *YurtStaticSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -619,140 +1654,116 @@ func (in *YurtAppSet) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppSetCondition) DeepCopyInto(out *YurtAppSetCondition) {
+func (in *YurtStaticSetBundledManifest) DeepCopyInto(out *YurtStaticSetBundledManifest) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetCondition.
-func (in *YurtAppSetCondition) DeepCopy() *YurtAppSetCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetBundledManifest.
+func (in *YurtStaticSetBundledManifest) DeepCopy() *YurtStaticSetBundledManifest {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppSetCondition)
+	out := new(YurtStaticSetBundledManifest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppSetList) DeepCopyInto(out *YurtAppSetList) {
+func (in *YurtStaticSetCanaryStatus) DeepCopyInto(out *YurtStaticSetCanaryStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]YurtAppSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.HealthySince != nil {
+		in, out := &in.HealthySince, &out.HealthySince
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetList.
-func (in *YurtAppSetList) DeepCopy() *YurtAppSetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetCanaryStatus.
+func (in *YurtStaticSetCanaryStatus) DeepCopy() *YurtStaticSetCanaryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppSetList)
+	out := new(YurtStaticSetCanaryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtAppSetList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppSetSpec) DeepCopyInto(out *YurtAppSetSpec) {
+func (in *YurtStaticSetCanaryStrategy) DeepCopyInto(out *YurtStaticSetCanaryStrategy) {
 	*out = *in
-	if in.Selector != nil {
-		in, out := &in.Selector, &out.Selector
+	if in.PoolSelector != nil {
+		in, out := &in.PoolSelector, &out.PoolSelector
 		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	in.WorkloadTemplate.DeepCopyInto(&out.WorkloadTemplate)
-	in.Topology.DeepCopyInto(&out.Topology)
-	if in.RevisionHistoryLimit != nil {
-		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
-		*out = new(int32)
+	if in.SoakPeriod != nil {
+		in, out := &in.SoakPeriod, &out.SoakPeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetSpec.
-func (in *YurtAppSetSpec) DeepCopy() *YurtAppSetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetCanaryStrategy.
+func (in *YurtStaticSetCanaryStrategy) DeepCopy() *YurtStaticSetCanaryStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppSetSpec)
+	out := new(YurtStaticSetCanaryStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtAppSetStatus) DeepCopyInto(out *YurtAppSetStatus) {
+func (in *YurtStaticSetHealthGate) DeepCopyInto(out *YurtStaticSetHealthGate) {
 	*out = *in
-	if in.CollisionCount != nil {
-		in, out := &in.CollisionCount, &out.CollisionCount
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]YurtAppSetCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.WorkloadSummaries != nil {
-		in, out := &in.WorkloadSummaries, &out.WorkloadSummaries
-		*out = make([]WorkloadSummary, len(*in))
-		copy(*out, *in)
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(corev1.HTTPGetAction)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.PoolReplicas != nil {
-		in, out := &in.PoolReplicas, &out.PoolReplicas
-		*out = make(map[string]int32, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.MinUptime != nil {
+		in, out := &in.MinUptime, &out.MinUptime
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetStatus.
-func (in *YurtAppSetStatus) DeepCopy() *YurtAppSetStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetHealthGate.
+func (in *YurtStaticSetHealthGate) DeepCopy() *YurtStaticSetHealthGate {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtAppSetStatus)
+	out := new(YurtStaticSetHealthGate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtStaticSet) DeepCopyInto(out *YurtStaticSet) {
+func (in *YurtStaticSetList) DeepCopyInto(out *YurtStaticSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]YurtStaticSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSet.
-func (in *YurtStaticSet) DeepCopy() *YurtStaticSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetList.
+func (in *YurtStaticSetList) DeepCopy() *YurtStaticSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtStaticSet)
+	out := new(YurtStaticSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtStaticSet) DeepCopyObject() runtime.Object {
+func (in *YurtStaticSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -760,35 +1771,43 @@ func (in *YurtStaticSet) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *YurtStaticSetList) DeepCopyInto(out *YurtStaticSetList) {
+func (in *YurtStaticSetNodeStatus) DeepCopyInto(out *YurtStaticSetNodeStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]YurtStaticSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetList.
-func (in *YurtStaticSetList) DeepCopy() *YurtStaticSetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetNodeStatus.
+func (in *YurtStaticSetNodeStatus) DeepCopy() *YurtStaticSetNodeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(YurtStaticSetList)
+	out := new(YurtStaticSetNodeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *YurtStaticSetList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *YurtStaticSetPrePullStrategy) DeepCopyInto(out *YurtStaticSetPrePullStrategy) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
 	}
-	return nil
+	if in.MaxPullingNodesPerPool != nil {
+		in, out := &in.MaxPullingNodesPerPool, &out.MaxPullingNodesPerPool
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetPrePullStrategy.
+func (in *YurtStaticSetPrePullStrategy) DeepCopy() *YurtStaticSetPrePullStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(YurtStaticSetPrePullStrategy)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -801,6 +1820,13 @@ func (in *YurtStaticSetSpec) DeepCopyInto(out *YurtStaticSetSpec) {
 		**out = **in
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.BundledManifests != nil {
+		in, out := &in.BundledManifests, &out.BundledManifests
+		*out = make([]YurtStaticSetBundledManifest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetSpec.
@@ -816,6 +1842,16 @@ func (in *YurtStaticSetSpec) DeepCopy() *YurtStaticSetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *YurtStaticSetStatus) DeepCopyInto(out *YurtStaticSetStatus) {
 	*out = *in
+	if in.NodeStatuses != nil {
+		in, out := &in.NodeStatuses, &out.NodeStatuses
+		*out = make([]YurtStaticSetNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.CanaryStatus != nil {
+		in, out := &in.CanaryStatus, &out.CanaryStatus
+		*out = new(YurtStaticSetCanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetStatus.
@@ -836,6 +1872,31 @@ func (in *YurtStaticSetUpgradeStrategy) DeepCopyInto(out *YurtStaticSetUpgradeSt
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.RollbackWindow != nil {
+		in, out := &in.RollbackWindow, &out.RollbackWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(YurtStaticSetCanaryStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrePull != nil {
+		in, out := &in.PrePull, &out.PrePull
+		*out = new(YurtStaticSetPrePullStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthGate != nil {
+		in, out := &in.HealthGate, &out.HealthGate
+		*out = new(YurtStaticSetHealthGate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtStaticSetUpgradeStrategy.
@@ -19,6 +19,7 @@ package v1beta1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type NodePoolType string
@@ -26,8 +27,77 @@ type NodePoolType string
 const (
 	Edge  NodePoolType = "Edge"
 	Cloud NodePoolType = "Cloud"
+	// Satellite is the edge site which connects to the cloud through an intermittent link
+	// with public ip, like satellite or cellular links. The NodePool of Satellite type is
+	// defaulted with constraints.requirePublicIP, gatewayEndpoints, node autonomy and
+	// podDefaults.unreachableTolerationSeconds.
+	Satellite NodePoolType = "Satellite"
 )
 
+const (
+	// DefaultSatelliteTolerationSeconds is the default seconds that pods in Satellite NodePool
+	// tolerate the not-ready and unreachable taints of nodes before they are evicted.
+	DefaultSatelliteTolerationSeconds int64 = 3600
+)
+
+// ConflictPolicy specifies how to resolve the conflict between the attributes
+// of NodePool and the attributes that are set on the node directly.
+type ConflictPolicy string
+
+const (
+	// PoolWins means the attributes of NodePool will overwrite the attributes
+	// with the same keys on the nodes.
+	PoolWins ConflictPolicy = "pool-wins"
+	// NodeWins means the attributes that are modified on the nodes directly
+	// will not be overwritten or removed by NodePool.
+	NodeWins ConflictPolicy = "node-wins"
+	// Merge means only the attributes that are changed in NodePool will be
+	// synced to the nodes, and the other attributes modified on the nodes
+	// directly will be kept.
+	Merge ConflictPolicy = "merge"
+)
+
+// DeletionPolicy specifies how to handle the nodes of NodePool when the NodePool is deleted.
+type DeletionPolicy string
+
+const (
+	// ForbidIfNonEmpty means the NodePool can not be deleted until all nodes leave the NodePool.
+	ForbidIfNonEmpty DeletionPolicy = "ForbidIfNonEmpty"
+	// Orphan means the nodes will be detached from the NodePool, and the nodepool labels
+	// and attributes synced from NodePool will be removed from the nodes.
+	Orphan DeletionPolicy = "Orphan"
+	// Evacuate means the nodes will be detached from the NodePool like Orphan, and the
+	// pods bound to the NodePool(like pods of YurtAppSet) will be evicted from the nodes.
+	Evacuate DeletionPolicy = "Evacuate"
+)
+
+const (
+	// NodePoolFinalizer is added on NodePool for handling nodes according to DeletionPolicy
+	// before NodePool is deleted.
+	NodePoolFinalizer = "apps.openyurt.io/nodepool"
+)
+
+// NodePoolConflictPolicy defines the conflict policy for each kind of attributes
+// that are synced from NodePool to nodes, the default policy is pool-wins. the
+// attributes are synced only when they are changed in NodePool if the policy is not
+// specified, and the specified policy is enforced on every sync of nodes.
+type NodePoolConflictPolicy struct {
+	// Labels is the conflict policy for labels.
+	// +kubebuilder:validation:Enum=pool-wins;node-wins;merge
+	// +optional
+	Labels ConflictPolicy `json:"labels,omitempty"`
+
+	// Annotations is the conflict policy for annotations.
+	// +kubebuilder:validation:Enum=pool-wins;node-wins;merge
+	// +optional
+	Annotations ConflictPolicy `json:"annotations,omitempty"`
+
+	// Taints is the conflict policy for taints.
+	// +kubebuilder:validation:Enum=pool-wins;node-wins;merge
+	// +optional
+	Taints ConflictPolicy `json:"taints,omitempty"`
+}
+
 // NodePoolSpec defines the desired state of NodePool
 type NodePoolSpec struct {
 	// The type of the NodePool
@@ -53,6 +123,206 @@ type NodePoolSpec struct {
 	// If specified, the Taints will be added to all nodes.
 	// +optional
 	Taints []v1.Taint `json:"taints,omitempty"`
+
+	// ConflictPolicy specifies how to resolve the conflict between the Labels,
+	// Annotations, Taints of NodePool and the ones modified on the nodes directly.
+	// The specified policy is enforced on every sync of nodes, otherwise the attributes
+	// are synced with pool-wins only when they are changed in NodePool.
+	// +optional
+	ConflictPolicy NodePoolConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// DesiredSize is the number of nodes expected in the NodePool. If specified, nodes
+	// will be added into or removed from the NodePool through the autoscaling provider
+	// configured in yurt-manager, and nodes are cordoned and drained before removed.
+	// Pending pods trigger adding nodes beyond DesiredSize only when MaxSize is specified.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DesiredSize *int32 `json:"desiredSize,omitempty"`
+
+	// MaxSize is the upper limit of nodes in the NodePool when nodes are added for
+	// the pending pods that can not be scheduled into the NodePool. If not specified,
+	// pending pods will not trigger adding nodes beyond the DesiredSize.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSize *int32 `json:"maxSize,omitempty"`
+
+	// Constraints are the requirements that nodes should satisfy when joining the NodePool.
+	// +optional
+	Constraints *NodePoolConstraints `json:"constraints,omitempty"`
+
+	// Gateway is the name of raven Gateway that nodes of the NodePool should use.
+	// If specified, the raven.openyurt.io/gateway label will be added to all nodes.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// GatewayEndpoints means the nodes of NodePool with public ip label are added as
+	// the tunnel endpoints of Gateway, so they can be elected as the active endpoints.
+	// +optional
+	GatewayEndpoints *bool `json:"gatewayEndpoints,omitempty"`
+
+	// Topology is the location of the NodePool, it will be added to all nodes as
+	// the well-known topology labels, so topology-aware scheduling and storage
+	// provisioning can work for each edge site.
+	// +optional
+	Topology *NodePoolTopology `json:"topology,omitempty"`
+
+	// TimeZone is the IANA time zone of the NodePool, like Asia/Shanghai. The maintenance
+	// windows of node component upgrades are evaluated in this time zone, the default is UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// NodeConfig is the kubelet and system configurations for nodes of the NodePool,
+	// it will be rendered into the ConfigMap of NodePool and applied by node-servant.
+	// +optional
+	NodeConfig *NodeConfig `json:"nodeConfig,omitempty"`
+
+	// DisruptionBudget specifies the PodDisruptionBudgets that cover the selected
+	// workloads in the NodePool, so the voluntary disruptions(like draining nodes)
+	// can not take down all replicas of the workloads in the NodePool simultaneously.
+	// +optional
+	DisruptionBudget *NodePoolDisruptionBudget `json:"disruptionBudget,omitempty"`
+
+	// DeletionPolicy specifies how to handle the nodes of NodePool when the NodePool is deleted,
+	// the default policy is ForbidIfNonEmpty.
+	// +kubebuilder:validation:Enum=ForbidIfNonEmpty;Orphan;Evacuate
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// PodDefaults specifies the default fields for pods that are scheduled into the NodePool,
+	// pods are regarded as scheduled into the NodePool when they select the NodePool by
+	// nodeSelector or required node affinity on label apps.openyurt.io/nodepool.
+	// +optional
+	PodDefaults *NodePoolPodDefaults `json:"podDefaults,omitempty"`
+}
+
+// NodePoolPodDefaults defines the default fields for pods in the NodePool, the fields
+// are only set for pods which don't specify them.
+type NodePoolPodDefaults struct {
+	// PriorityClassName is the default PriorityClass of pods in the NodePool.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RuntimeClassName is the default RuntimeClass of pods in the NodePool, like
+	// the nvidia runtime for pools of GPU nodes.
+	// +optional
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+
+	// UnreachableTolerationSeconds is the default seconds that pods in the NodePool tolerate
+	// the not-ready and unreachable taints of nodes, it overrides the 300 seconds set by the
+	// DefaultTolerationSeconds admission plugin.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	UnreachableTolerationSeconds *int64 `json:"unreachableTolerationSeconds,omitempty"`
+}
+
+// NodePoolDisruptionBudget defines the PodDisruptionBudgets of workloads in the NodePool.
+type NodePoolDisruptionBudget struct {
+	// Selector is the label query over pods that should be covered by the
+	// PodDisruptionBudgets, only pods in the NodePool are selected.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// MaxUnavailable is the max number or percentage of selected pods in the NodePool
+	// that can be unavailable after the eviction, the default value is 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// NodePoolTopology defines the location of the NodePool.
+type NodePoolTopology struct {
+	// Region is added to nodes as the label topology.kubernetes.io/region.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Zone is added to nodes as the label topology.kubernetes.io/zone.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Site is added to nodes as the label topology.openyurt.io/site.
+	// +optional
+	Site string `json:"site,omitempty"`
+}
+
+// NodeConfig defines the pool-scoped configurations of nodes.
+type NodeConfig struct {
+	// KubeletArgs are the extra flags of kubelet without the leading "--",
+	// like max-pods: "110".
+	// +optional
+	KubeletArgs map[string]string `json:"kubeletArgs,omitempty"`
+
+	// Sysctls are the kernel parameters that will be set on nodes,
+	// like net.ipv4.ip_forward: "1".
+	// +optional
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// InsecureRegistries are the registries which will be accessed by container
+	// runtime without tls verification.
+	// +optional
+	InsecureRegistries []string `json:"insecureRegistries,omitempty"`
+
+	// RegistryMirrors are the mirrors or pull-through caches of registries, so nodes
+	// of the NodePool can pull images from the mirrors in local site.
+	// +optional
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+}
+
+// RegistryMirror defines the mirrors of an image registry.
+type RegistryMirror struct {
+	// Registry is the host of the registry which is mirrored, like docker.io.
+	Registry string `json:"registry"`
+
+	// Endpoints are the addresses of mirrors, like http://10.0.0.1:5000.
+	// mirrors are tried in order before falling back to the registry.
+	Endpoints []string `json:"endpoints"`
+
+	// SkipVerify means the tls certificates of mirrors are not verified.
+	// +optional
+	SkipVerify bool `json:"skipVerify,omitempty"`
+}
+
+// ConstraintEnforcement specifies how to handle the nodes that don't satisfy
+// the constraints of NodePool.
+type ConstraintEnforcement string
+
+const (
+	// EnforcementReject means nodes that don't satisfy the constraints are rejected.
+	EnforcementReject ConstraintEnforcement = "Reject"
+	// EnforcementFlag means nodes that don't satisfy the constraints are admitted,
+	// and the violations are recorded in the annotation of nodes.
+	EnforcementFlag ConstraintEnforcement = "Flag"
+)
+
+// NodePoolConstraints defines the requirements that nodes should satisfy when joining the NodePool.
+type NodePoolConstraints struct {
+	// RequiredLabels are the labels that nodes should have, empty value means
+	// any value of the label is acceptable.
+	// +optional
+	RequiredLabels map[string]string `json:"requiredLabels,omitempty"`
+
+	// OperatingSystems are the acceptable operating systems of nodes, like linux.
+	// They are also added into the node affinity of workloads generated by YurtAppSet.
+	// +optional
+	OperatingSystems []string `json:"operatingSystems,omitempty"`
+
+	// Architectures are the acceptable architectures of nodes, like amd64, arm64.
+	// They are also added into the node affinity of workloads generated by YurtAppSet.
+	// +optional
+	Architectures []string `json:"architectures,omitempty"`
+
+	// MinKernelVersion is the minimum kernel version of nodes, like 4.19.
+	// +optional
+	MinKernelVersion string `json:"minKernelVersion,omitempty"`
+
+	// RequirePublicIP means nodes should have the public ip label, it is used
+	// for satellite pools whose nodes are connected from other pools directly,
+	// and it is always enabled for Satellite NodePool.
+	// +optional
+	RequirePublicIP bool `json:"requirePublicIP,omitempty"`
+
+	// Enforcement specifies how to handle the nodes that don't satisfy the constraints,
+	// the default value is Reject.
+	// +kubebuilder:validation:Enum=Reject;Flag
+	// +optional
+	Enforcement ConstraintEnforcement `json:"enforcement,omitempty"`
 }
 
 // NodePoolStatus defines the observed state of NodePool
@@ -68,6 +338,222 @@ type NodePoolStatus struct {
 	// The list of nodes' names in the pool
 	// +optional
 	Nodes []string `json:"nodes,omitempty"`
+
+	// NodeCounts is the breakdown of node counts by condition in the pool.
+	// +optional
+	NodeCounts NodePoolNodeCounts `json:"nodeCounts,omitempty"`
+
+	// Capacity is the aggregated cpu, memory and gpu capacity of all nodes in the pool.
+	// +optional
+	Capacity v1.ResourceList `json:"capacity,omitempty"`
+
+	// Allocatable is the aggregated cpu, memory and gpu allocatable of all nodes in the pool.
+	// +optional
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+
+	// GPUInventory is the aggregated gpu inventory of nodes in the pool, it's
+	// empty if there's no gpu in the pool.
+	// +optional
+	GPUInventory *GPUInventory `json:"gpuInventory,omitempty"`
+
+	// HubLeader is the name of node whose yurthub is the leader in the pool currently.
+	// +optional
+	HubLeader string `json:"hubLeader,omitempty"`
+
+	// HubLeaderHistory records the recent elections of hub leader in the pool,
+	// the latest election comes first.
+	// +optional
+	HubLeaderHistory []HubLeaderRecord `json:"hubLeaderHistory,omitempty"`
+
+	// Membership is the statistics of nodes joining, leaving and changing readiness
+	// in the pool during the recent window.
+	// +optional
+	Membership *NodePoolMembership `json:"membership,omitempty"`
+
+	// Autoscaling is the state of adding or removing nodes of the pool through the autoscaling
+	// provider, so the scaling is continued as expected after yurt-manager restarts.
+	// +optional
+	Autoscaling *NodePoolAutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// Conditions represents the latest available observations of the pool's current state.
+	// +optional
+	Conditions []NodePoolCondition `json:"conditions,omitempty"`
+
+	// Reachability records whether nodes of the pool can reach each of the other pools
+	// through raven gateways currently, it's derived from the health of gateway tunnels
+	// and empty if there's no raven gateway in the cluster.
+	// +optional
+	Reachability []NodePoolReachability `json:"reachability,omitempty"`
+}
+
+// NodePoolReachability describes whether another pool can be reached from the pool.
+type NodePoolReachability struct {
+	// NodePool is the name of the pool which is reached.
+	NodePool string `json:"nodePool"`
+
+	// Reachable means the pool can be reached through the gateways currently.
+	Reachable bool `json:"reachable"`
+
+	// Reason is a brief CamelCase string that explains the reachability, like
+	// SameGateway, TunnelEstablished and GatewayUnhealthy.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// NodePoolMembership is the statistics of membership changes of the pool during a window.
+type NodePoolMembership struct {
+	// WindowStart is the start time of the current statistics window.
+	WindowStart metav1.Time `json:"windowStart"`
+
+	// LastObservedTime is the last time when the membership of pool is observed.
+	LastObservedTime metav1.Time `json:"lastObservedTime"`
+
+	// Joined is the number of nodes joined the pool in the window.
+	Joined int32 `json:"joined"`
+
+	// Left is the number of nodes left the pool in the window.
+	Left int32 `json:"left"`
+
+	// ReadinessChanges is the number of times that nodes in the pool changed
+	// readiness in the window.
+	ReadinessChanges int32 `json:"readinessChanges"`
+}
+
+// NodePoolAutoscalingStatus records the scaling of NodePool through the autoscaling provider.
+type NodePoolAutoscalingStatus struct {
+	// LastScaleTime is the last time when nodes were requested to be added or removed,
+	// the pool is not scaled again until the cooldown elapses.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// ScaleUpTarget is the number of nodes expected in the pool after the requested nodes join,
+	// the nodes which have been requested but not joined are not requested again.
+	// +optional
+	ScaleUpTarget *int32 `json:"scaleUpTarget,omitempty"`
+
+	// ScaleUpDeadline is the time before which the requested nodes are expected to join.
+	// +optional
+	ScaleUpDeadline *metav1.Time `json:"scaleUpDeadline,omitempty"`
+
+	// DrainingNodes are the nodes which are being drained, and they will be removed
+	// after the pods on them are evicted.
+	// +optional
+	DrainingNodes []string `json:"drainingNodes,omitempty"`
+
+	// CordonedNodes are the draining nodes which are cordoned by autoscaling, and they
+	// are uncordoned if the draining is aborted.
+	// +optional
+	CordonedNodes []string `json:"cordonedNodes,omitempty"`
+
+	// DrainStartTime is the time when the draining nodes started to be drained.
+	// +optional
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+}
+
+// NodePoolConditionType indicates valid conditions type of a NodePool.
+type NodePoolConditionType string
+
+const (
+	// NodePoolNodesReady means all nodes in the pool are ready.
+	NodePoolNodesReady NodePoolConditionType = "NodesReady"
+	// NodePoolStable means nodes in the pool don't change readiness frequently in the recent window.
+	NodePoolStable NodePoolConditionType = "Stable"
+)
+
+// NodePoolCondition describes current state of a NodePool.
+type NodePoolCondition struct {
+	// Type of NodePool condition.
+	Type NodePoolConditionType `json:"type,omitempty"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// GPUInventory is the inventory of gpus in the pool.
+type GPUInventory struct {
+	// Capacity is the aggregated capacity of gpu resources, including the
+	// whole gpus(like nvidia.com/gpu) and MIG devices(like nvidia.com/mig-1g.5gb).
+	// +optional
+	Capacity v1.ResourceList `json:"capacity,omitempty"`
+
+	// Allocatable is the aggregated allocatable of gpu resources.
+	// +optional
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+
+	// Models is the breakdown of gpus by model, the model of gpu is
+	// read from the node label nvidia.com/gpu.product.
+	// +optional
+	Models []GPUModelInventory `json:"models,omitempty"`
+}
+
+// GPUModelInventory is the inventory of gpus of the same model.
+type GPUModelInventory struct {
+	// Model is the product name of gpu, like NVIDIA-A100-SXM4-40GB.
+	Model string `json:"model"`
+
+	// Nodes is the number of nodes with this gpu model.
+	Nodes int32 `json:"nodes"`
+
+	// Count is the total number of gpus of this model.
+	Count int64 `json:"count"`
+}
+
+// HubLeaderRecord records an election of hub leader.
+type HubLeaderRecord struct {
+	// NodeName is the name of node whose yurthub is elected as leader.
+	NodeName string `json:"nodeName"`
+
+	// ElectedTime is the time when the yurthub became leader.
+	ElectedTime metav1.Time `json:"electedTime"`
+
+	// PreviousNodeName is the name of node whose yurthub held the leadership
+	// before this election, it's empty if it's unknown.
+	// +optional
+	PreviousNodeName string `json:"previousNodeName,omitempty"`
+
+	// Reason is how the leadership is handed over from the previous leader.
+	// +optional
+	Reason HubLeaderHandoverReason `json:"reason,omitempty"`
+}
+
+// HubLeaderHandoverReason is the reason of handing over hub leadership.
+type HubLeaderHandoverReason string
+
+const (
+	// HubLeaderHandoverReleased means the previous leader released the leadership,
+	// like its tenure is expired or it's disconnected from cloud.
+	HubLeaderHandoverReleased HubLeaderHandoverReason = "Released"
+	// HubLeaderHandoverExpired means the previous leader did not renew the leadership
+	// in time, like it's crashed or disconnected from yurt-coordinator.
+	HubLeaderHandoverExpired HubLeaderHandoverReason = "Expired"
+)
+
+// NodePoolNodeCounts is the breakdown of node counts by condition.
+type NodePoolNodeCounts struct {
+	// Ready is the number of nodes whose Ready condition is True.
+	// +optional
+	Ready int32 `json:"ready"`
+
+	// NotReady is the number of nodes whose Ready condition is False.
+	// +optional
+	NotReady int32 `json:"notReady"`
+
+	// Unknown is the number of nodes whose Ready condition is Unknown or not reported.
+	// +optional
+	Unknown int32 `json:"unknown"`
+
+	// Cordoned is the number of nodes that are marked as unschedulable.
+	// +optional
+	Cordoned int32 `json:"cordoned"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -77,6 +563,7 @@ type NodePoolStatus struct {
 // +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type",description="The type of nodepool"
 // +kubebuilder:printcolumn:name="ReadyNodes",type="integer",JSONPath=".status.readyNodeNum",description="The number of ready nodes in the pool"
 // +kubebuilder:printcolumn:name="NotReadyNodes",type="integer",JSONPath=".status.unreadyNodeNum"
+// +kubebuilder:printcolumn:name="HubLeader",type="string",JSONPath=".status.hubLeader",description="The node whose yurthub is the leader in the pool",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +genclient:nonNamespaced
@@ -23,9 +23,117 @@ package v1beta1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUInventory) DeepCopyInto(out *GPUInventory) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Models != nil {
+		in, out := &in.Models, &out.Models
+		*out = make([]GPUModelInventory, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUInventory.
+func (in *GPUInventory) DeepCopy() *GPUInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUModelInventory) DeepCopyInto(out *GPUModelInventory) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUModelInventory.
+func (in *GPUModelInventory) DeepCopy() *GPUModelInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUModelInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HubLeaderRecord) DeepCopyInto(out *HubLeaderRecord) {
+	*out = *in
+	in.ElectedTime.DeepCopyInto(&out.ElectedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HubLeaderRecord.
+func (in *HubLeaderRecord) DeepCopy() *HubLeaderRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(HubLeaderRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConfig) DeepCopyInto(out *NodeConfig) {
+	*out = *in
+	if in.KubeletArgs != nil {
+		in, out := &in.KubeletArgs, &out.KubeletArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InsecureRegistries != nil {
+		in, out := &in.InsecureRegistries, &out.InsecureRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConfig.
+func (in *NodeConfig) DeepCopy() *NodeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodePool) DeepCopyInto(out *NodePool) {
 	*out = *in
@@ -53,6 +161,136 @@ func (in *NodePool) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolAutoscalingStatus) DeepCopyInto(out *NodePoolAutoscalingStatus) {
+	*out = *in
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ScaleUpTarget != nil {
+		in, out := &in.ScaleUpTarget, &out.ScaleUpTarget
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleUpDeadline != nil {
+		in, out := &in.ScaleUpDeadline, &out.ScaleUpDeadline
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainingNodes != nil {
+		in, out := &in.DrainingNodes, &out.DrainingNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CordonedNodes != nil {
+		in, out := &in.CordonedNodes, &out.CordonedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DrainStartTime != nil {
+		in, out := &in.DrainStartTime, &out.DrainStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolAutoscalingStatus.
+func (in *NodePoolAutoscalingStatus) DeepCopy() *NodePoolAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolCondition) DeepCopyInto(out *NodePoolCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolCondition.
+func (in *NodePoolCondition) DeepCopy() *NodePoolCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolConflictPolicy) DeepCopyInto(out *NodePoolConflictPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolConflictPolicy.
+func (in *NodePoolConflictPolicy) DeepCopy() *NodePoolConflictPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolConflictPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolConstraints) DeepCopyInto(out *NodePoolConstraints) {
+	*out = *in
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OperatingSystems != nil {
+		in, out := &in.OperatingSystems, &out.OperatingSystems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolConstraints.
+func (in *NodePoolConstraints) DeepCopy() *NodePoolConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolDisruptionBudget) DeepCopyInto(out *NodePoolDisruptionBudget) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolDisruptionBudget.
+func (in *NodePoolDisruptionBudget) DeepCopy() *NodePoolDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodePoolList) DeepCopyInto(out *NodePoolList) {
 	*out = *in
@@ -85,6 +323,73 @@ func (in *NodePoolList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolMembership) DeepCopyInto(out *NodePoolMembership) {
+	*out = *in
+	in.WindowStart.DeepCopyInto(&out.WindowStart)
+	in.LastObservedTime.DeepCopyInto(&out.LastObservedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolMembership.
+func (in *NodePoolMembership) DeepCopy() *NodePoolMembership {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolMembership)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolNodeCounts) DeepCopyInto(out *NodePoolNodeCounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolNodeCounts.
+func (in *NodePoolNodeCounts) DeepCopy() *NodePoolNodeCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolNodeCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolPodDefaults) DeepCopyInto(out *NodePoolPodDefaults) {
+	*out = *in
+	if in.UnreachableTolerationSeconds != nil {
+		in, out := &in.UnreachableTolerationSeconds, &out.UnreachableTolerationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolPodDefaults.
+func (in *NodePoolPodDefaults) DeepCopy() *NodePoolPodDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolPodDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolReachability) DeepCopyInto(out *NodePoolReachability) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolReachability.
+func (in *NodePoolReachability) DeepCopy() *NodePoolReachability {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolReachability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 	*out = *in
@@ -109,6 +414,47 @@ func (in *NodePoolSpec) DeepCopyInto(out *NodePoolSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.ConflictPolicy = in.ConflictPolicy
+	if in.DesiredSize != nil {
+		in, out := &in.DesiredSize, &out.DesiredSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = new(NodePoolConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayEndpoints != nil {
+		in, out := &in.GatewayEndpoints, &out.GatewayEndpoints
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(NodePoolTopology)
+		**out = **in
+	}
+	if in.NodeConfig != nil {
+		in, out := &in.NodeConfig, &out.NodeConfig
+		*out = new(NodeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(NodePoolDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDefaults != nil {
+		in, out := &in.PodDefaults, &out.PodDefaults
+		*out = new(NodePoolPodDefaults)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolSpec.
@@ -129,6 +475,55 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	out.NodeCounts = in.NodeCounts
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.GPUInventory != nil {
+		in, out := &in.GPUInventory, &out.GPUInventory
+		*out = new(GPUInventory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HubLeaderHistory != nil {
+		in, out := &in.HubLeaderHistory, &out.HubLeaderHistory
+		*out = make([]HubLeaderRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Membership != nil {
+		in, out := &in.Membership, &out.Membership
+		*out = new(NodePoolMembership)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(NodePoolAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodePoolCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Reachability != nil {
+		in, out := &in.Reachability, &out.Reachability
+		*out = make([]NodePoolReachability, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
@@ -140,3 +535,38 @@ func (in *NodePoolStatus) DeepCopy() *NodePoolStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolTopology) DeepCopyInto(out *NodePoolTopology) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolTopology.
+func (in *NodePoolTopology) DeepCopy() *NodePoolTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryMirror) DeepCopyInto(out *RegistryMirror) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryMirror.
+func (in *RegistryMirror) DeepCopy() *RegistryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -32,6 +32,54 @@ const (
 	AnnotationPatchKey = "apps.openyurt.io/patch"
 
 	AnnotationRefNodePool = "apps.openyurt.io/ref-nodepool"
+
+	// AnnotationInjectGatewayConfig is added on YurtAppSet by users with value "true" for injecting
+	// the raven gateway endpoints and proxy addresses of each pool into the containers as env vars.
+	// The workloads of pools are updated to refresh the env vars when the gateway of their NodePools changes.
+	AnnotationInjectGatewayConfig = "apps.openyurt.io/inject-gateway-config"
+	// AnnotationGatewayConfigHash records the hash of gateway config injected into the workload of pool,
+	// it is used to find out the workloads need to be updated when the gateway changes.
+	AnnotationGatewayConfigHash = "apps.openyurt.io/gateway-config-hash"
+
+	// AnnotationAdoptExistingWorkloads is added on YurtAppSet by users with value "true" for adopting the
+	// existing Deployments which are not owned by any controller as the workloads of pools, instead of
+	// creating new ones. A Deployment is adopted by a pool if it is labeled with the pool name or named
+	// <yurtappset>-<pool>, and its selector selects the pods of pool.
+	AnnotationAdoptExistingWorkloads = "apps.openyurt.io/adopt-existing-workloads"
+
+	// ConfigTemplateLabelKey is used to record the name of config template which the ConfigMap
+	// or Secret of pool is rendered from.
+	ConfigTemplateLabelKey = "apps.openyurt.io/config-template"
+
+	// BundleTemplateLabelKey is used to record the name of bundle template which the object of pool is instantiated from.
+	BundleTemplateLabelKey = "apps.openyurt.io/bundle-template"
+	// AnnotationBundleTemplateHash records the hash of bundle template which the object of pool is instantiated from,
+	// it is used to find out the objects need to be updated.
+	AnnotationBundleTemplateHash = "apps.openyurt.io/bundle-template-hash"
+)
+
+// LoadBalancerSet related labels and annotations
+const (
+	// AnnotationVRRPVips is added on LoadBalancer Services by users for serving the Service with on-site
+	// virtual ips by keepalived, the value is a comma-separated list of <nodepool>=<vip>, like
+	// hangzhou=192.168.10.100,shanghai=192.168.20.100. The vip floats among the nodes of the NodePool by VRRP.
+	AnnotationVRRPVips = "service.openyurt.io/vrrp-vips"
+	// AnnotationVRRPInterface is added on NodePool by users for specifying the network interface
+	// which keepalived binds the virtual ips on, eth0 is used if it's not specified.
+	AnnotationVRRPInterface = "nodepool.openyurt.io/vrrp-interface"
+	// KeepalivedConfigLabel is added on the keepalived ConfigMap of NodePool, and the value is the name of NodePool.
+	KeepalivedConfigLabel = "nodepool.openyurt.io/keepalived"
+)
+
+// ServiceVariant related labels and annotations
+const (
+	// AnnotationServiceVariantPools is added on Services by users for creating a variant Service in each specified
+	// NodePool, the value is a comma-separated list of NodePools or * for all NodePools. The variant Service has its
+	// own cluster ip, and only the endpoints in the NodePool are kept, it's used by the clients which can't rely on
+	// the servicetopology filter of yurthub, like the clients with the cluster ip configured statically.
+	AnnotationServiceVariantPools = "service.openyurt.io/pool-variants"
+	// ServiceVariantParentLabel is added on the variant Services and Endpoints, and the value is the name of parent Service.
+	ServiceVariantParentLabel = "service.openyurt.io/variant-of"
 )
 
 // NodePool related labels and annotations
@@ -41,4 +89,44 @@ const (
 	NodePoolTypeLabel        = "nodepool.openyurt.io/type"
 	NodePoolHostNetworkLabel = "nodepool.openyurt.io/hostnetwork"
 	NodePoolChangedEvent     = "NodePoolChanged"
+
+	// LabelTopologySite is added to nodes of the NodePool with spec.topology.site,
+	// it's used as the topology key of edge site like topology.kubernetes.io/zone.
+	LabelTopologySite = "topology.openyurt.io/site"
+
+	// NodePublicIPLabel is used to record the public ip of node, and it is required
+	// for nodes of the NodePool with constraints.requirePublicIP.
+	NodePublicIPLabel = "nodepool.openyurt.io/public-ip"
+	// NodeAcceleratorLabel is used to record the vendor of accelerators on node, like nvidia,
+	// it's set by yurtadm join when the gpu preflight is passed.
+	NodeAcceleratorLabel = "nodepool.openyurt.io/accelerator"
+	// AnnotationConstraintViolations records the NodePool constraints that the node
+	// doesn't satisfy when the enforcement of constraints is Flag.
+	AnnotationConstraintViolations = "nodepool.openyurt.io/constraint-violations"
+	// AnnotationNodePoolTemplate is added on the NodePool which is created from a template for a joining node,
+	// and the value is the name of template.
+	AnnotationNodePoolTemplate = "nodepool.openyurt.io/template"
+	// AnnotationNodePoolCreatedBy records the user who created the NodePool from a template, like a bootstrap token.
+	AnnotationNodePoolCreatedBy = "nodepool.openyurt.io/created-by"
+	// AnnotationHubLeaderSince is added on the node whose yurthub is the leader
+	// in the NodePool, and the value is the time when the yurthub became leader.
+	AnnotationHubLeaderSince = "nodepool.openyurt.io/hub-leader-since"
+	// AnnotationHubLeaderPrevious is added on the node whose yurthub is the leader in the NodePool,
+	// and the value is the name of node whose yurthub held the leadership before it.
+	AnnotationHubLeaderPrevious = "nodepool.openyurt.io/hub-leader-previous"
+	// AnnotationHubLeaderHandover is added on the node whose yurthub is the leader in the NodePool,
+	// and the value is the reason how the leadership is handed over, like Released or Expired.
+	AnnotationHubLeaderHandover = "nodepool.openyurt.io/hub-leader-handover"
+
+	// AnnotationMigrateTo is added on node by users for moving the node into another NodePool,
+	// the value is the name of target NodePool.
+	AnnotationMigrateTo = "nodepool.openyurt.io/migrate-to"
+	// AnnotationMigrationStatus records the progress of moving node into another NodePool,
+	// it is managed by node-migration-controller.
+	AnnotationMigrationStatus = "nodepool.openyurt.io/migration-status"
+
+	// NodePoolCoordinatorStorageLabel is added on NodePool by users for selecting the storage backend of
+	// yurt-coordinator in the pool, the value is etcd or kine. Kine with SQLite is a lighter substitute of
+	// etcd for the pools with constrained resources, and etcd is used if the label is not set.
+	NodePoolCoordinatorStorageLabel = "nodepool.openyurt.io/coordinator-storage"
 )
@@ -101,12 +101,23 @@ type NodeInfo struct {
 	Subnets []string `json:"subnets"`
 }
 
+// PinnedService stores the addresses of Service whose cross-pool traffic is pinned to the Gateway.
+type PinnedService struct {
+	// Service is the namespace/name of the Service.
+	Service string `json:"service"`
+	// Addresses is the endpoint addresses of the Service, which are routed through the tunnel of Gateway.
+	Addresses []string `json:"addresses,omitempty"`
+}
+
 // GatewayStatus defines the observed state of Gateway
 type GatewayStatus struct {
 	// Nodes contains all information of nodes managed by Gateway.
 	Nodes []NodeInfo `json:"nodes,omitempty"`
 	// ActiveEndpoints is the reference of the active endpoint.
 	ActiveEndpoints []*Endpoint `json:"activeEndpoints,omitempty"`
+	// PinnedServices contains the Services which are annotated with raven.openyurt.io/pinned-gateway,
+	// the raven agents route the traffic of these Services through the tunnel of Gateway.
+	PinnedServices []PinnedService `json:"pinnedServices,omitempty"`
 }
 
 // +genclient
@@ -157,6 +157,13 @@ func (in *GatewayStatus) DeepCopyInto(out *GatewayStatus) {
 			}
 		}
 	}
+	if in.PinnedServices != nil {
+		in, out := &in.PinnedServices, &out.PinnedServices
+		*out = make([]PinnedService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayStatus.
@@ -189,6 +196,26 @@ func (in *NodeInfo) DeepCopy() *NodeInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedService) DeepCopyInto(out *PinnedService) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedService.
+func (in *PinnedService) DeepCopy() *PinnedService {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyConfiguration) DeepCopyInto(out *ProxyConfiguration) {
 	*out = *in
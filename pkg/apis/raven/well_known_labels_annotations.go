@@ -20,4 +20,23 @@ const (
 	// LabelCurrentGateway indicates which gateway the node is currently belonging to
 	LabelCurrentGateway     = "raven.openyurt.io/gateway"
 	LabelCurrentGatewayType = "raven.openyurt.io/gateway-type"
+
+	// LabelEndpointCandidate indicates the node has a public ip and can be elected as
+	// the tunnel endpoint of gateway, the public ip is recorded by nodepool.openyurt.io/public-ip.
+	LabelEndpointCandidate = "raven.openyurt.io/endpoint-candidate"
+
+	// AnnotationSplitHorizonDNS indicates the service is resolved to different addresses for cloud and edge
+	// clients when it's set to true, the cloud clients get the address of raven proxy and the edge clients get
+	// the cluster ip of service, so the service in edge can be accessed from cloud through raven.
+	AnnotationSplitHorizonDNS = "raven.openyurt.io/split-horizon-dns"
+
+	// AnnotationPinnedGateway indicates the name of gateway whose tunnel carries the cross-pool traffic
+	// of the service, the endpoint addresses of service are recorded in the status of gateway.
+	AnnotationPinnedGateway = "raven.openyurt.io/pinned-gateway"
+
+	// AnnotationServerCertificateSecret and AnnotationClientCertificateSecret indicate the namespace/name of secrets
+	// which hold the server and client certificates of raven proxy in gateway, they are issued by cert-manager and
+	// set on gateway when the certificates are ready, the raven agents use the built-in certificates without them.
+	AnnotationServerCertificateSecret = "raven.openyurt.io/server-certificate-secret"
+	AnnotationClientCertificateSecret = "raven.openyurt.io/client-certificate-secret"
 )
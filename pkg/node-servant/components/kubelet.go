@@ -64,7 +64,7 @@ func (op *kubeletOperator) RedirectTrafficToYurtHub() error {
 	}
 
 	// 3. restart
-	return restartKubeletService()
+	return RestartKubeletService()
 }
 
 // UndoRedirectTrafficToYurtHub
@@ -75,7 +75,7 @@ func (op *kubeletOperator) UndoRedirectTrafficToYurtHub() error {
 		return err
 	}
 
-	if err := restartKubeletService(); err != nil {
+	if err := RestartKubeletService(); err != nil {
 		return err
 	}
 
@@ -169,7 +169,8 @@ func (op *kubeletOperator) getYurthubKubeletConf() string {
 	return filepath.Join(op.openyurtDir, constants.KubeletKubeConfigFileName)
 }
 
-func restartKubeletService() error {
+// RestartKubeletService reloads systemd units and restarts kubelet service
+func RestartKubeletService() error {
 	klog.Info("restartKubelet: " + constants.DaemonReload)
 	cmd := exec.Command("bash", "-c", constants.DaemonReload)
 	if err := enutil.Exec(cmd); err != nil {
@@ -41,6 +41,7 @@ type ContainerRuntimeForImage interface {
 	IsDocker() bool
 	PullImage(image string) error
 	ImageExists(image string) (bool, error)
+	LoadImage(archive string) error
 }
 
 // CRIRuntime is a struct that interfaces with the CRI
@@ -127,6 +128,29 @@ func (runtime *DockerRuntime) ImageExists(image string) (bool, error) {
 	return err == nil, nil
 }
 
+// LoadImage imports the image archive into containerd, other CRI runtimes are not supported
+// because crictl can't load images.
+func (runtime *CRIRuntime) LoadImage(archive string) error {
+	address := strings.TrimPrefix(runtime.criSocket, "unix://")
+	if filepath.Base(address) != filepath.Base(containerdSocket) {
+		return errors.Errorf("loading image archive is not supported by CRI runtime %s", runtime.criSocket)
+	}
+	out, err := runtime.exec.Command("ctr", "--address", address, "-n", "k8s.io", "images", "import", archive).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "output: %s, error", out)
+	}
+	return nil
+}
+
+// LoadImage loads the image archive into docker
+func (runtime *DockerRuntime) LoadImage(archive string) error {
+	out, err := runtime.exec.Command("docker", "load", "-i", archive).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "output: %s, error", out)
+	}
+	return nil
+}
+
 // detectCRISocketImpl is separated out only for test purposes, DON'T call it directly, use DetectCRISocket instead
 func detectCRISocketImpl(isSocket func(string) bool) (string, error) {
 	foundCRISockets := []string{}
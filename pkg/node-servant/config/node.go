@@ -0,0 +1,218 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/util/nodeconfig"
+)
+
+const (
+	kubeletExtraArgsEnv = "KUBELET_EXTRA_ARGS"
+	// managedHostsFile records the registry hosts whose hosts.toml are managed by node-servant
+	managedHostsFile = ".openyurt-managed-hosts"
+
+	fileMode = 0644
+	dirMode  = 0755
+)
+
+// nodeConfigRunner applies the rendered node configurations of nodepool on the node.
+type nodeConfigRunner struct {
+	configDir          string
+	kubeletEnvFile     string
+	sysctlFile         string
+	containerdCertsDir string
+	restartKubelet     func() error
+	reloadSysctl       func(path string) error
+}
+
+// NewNodeConfigRunner creates a Runner which applies node configurations of nodepool.
+func NewNodeConfigRunner(o *NodeConfigOptions) Runner {
+	return &nodeConfigRunner{
+		configDir:          o.ConfigDir,
+		kubeletEnvFile:     o.KubeletEnvFile,
+		sysctlFile:         o.SysctlFile,
+		containerdCertsDir: o.ContainerdCertsDir,
+		restartKubelet:     components.RestartKubeletService,
+		reloadSysctl: func(path string) error {
+			return exec.Command("sysctl", "-p", path).Run()
+		},
+	}
+}
+
+func (r *nodeConfigRunner) Do() error {
+	if err := r.applySysctls(); err != nil {
+		return err
+	}
+
+	if err := r.applyContainerdHosts(); err != nil {
+		return err
+	}
+
+	// kubelet is restarted at last, so other configurations can take effect before pods are restarted.
+	return r.applyKubeletExtraArgs()
+}
+
+func (r *nodeConfigRunner) readConfig(key string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(r.configDir, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (r *nodeConfigRunner) applySysctls() error {
+	sysctls, err := r.readConfig(nodeconfig.SysctlKey)
+	if err != nil {
+		return err
+	}
+
+	changed, err := writeFileIfChanged(r.sysctlFile, sysctls)
+	if err != nil || !changed {
+		return err
+	}
+	klog.Infof("sysctl config %s is updated", r.sysctlFile)
+
+	if len(sysctls) == 0 {
+		return nil
+	}
+	if err := r.reloadSysctl(r.sysctlFile); err != nil {
+		return fmt.Errorf("could not load sysctl config %s, %w", r.sysctlFile, err)
+	}
+	return nil
+}
+
+func (r *nodeConfigRunner) applyContainerdHosts() error {
+	content, err := r.readConfig(nodeconfig.ContainerdHostsKey)
+	if err != nil {
+		return err
+	}
+
+	hosts := make(map[string]string)
+	if len(content) != 0 {
+		if err := json.Unmarshal([]byte(content), &hosts); err != nil {
+			return fmt.Errorf("could not decode containerd hosts, %w", err)
+		}
+	}
+
+	// remove hosts.toml of registries that are not managed any more
+	managedPath := filepath.Join(r.containerdCertsDir, managedHostsFile)
+	previous, err := os.ReadFile(managedPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, host := range strings.Fields(string(previous)) {
+		if _, ok := hosts[host]; ok {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(r.containerdCertsDir, host)); err != nil {
+			return err
+		}
+		klog.Infof("containerd hosts config for %s is removed", host)
+	}
+
+	managed := make([]string, 0, len(hosts))
+	for host, toml := range hosts {
+		if strings.Contains(host, "..") || strings.ContainsAny(host, "/\\") {
+			return fmt.Errorf("registry host %q is invalid", host)
+		}
+		changed, err := writeFileIfChanged(filepath.Join(r.containerdCertsDir, host, "hosts.toml"), toml)
+		if err != nil {
+			return err
+		}
+		if changed {
+			klog.Infof("containerd hosts config for %s is updated", host)
+		}
+		managed = append(managed, host)
+	}
+	sort.Strings(managed)
+
+	_, err = writeFileIfChanged(managedPath, strings.Join(managed, "\n"))
+	return err
+}
+
+func (r *nodeConfigRunner) applyKubeletExtraArgs() error {
+	args, err := r.readConfig(nodeconfig.KubeletExtraArgsKey)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(r.kubeletEnvFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	line := fmt.Sprintf("%s=%q", kubeletExtraArgsEnv, strings.TrimSpace(args))
+	re := regexp.MustCompile(fmt.Sprintf(`(?m)^%s=.*$`, kubeletExtraArgsEnv))
+	var newContent string
+	if re.Match(content) {
+		newContent = re.ReplaceAllLiteralString(string(content), line)
+	} else {
+		newContent = strings.TrimRight(string(content), "\n")
+		if len(newContent) != 0 {
+			newContent += "\n"
+		}
+		newContent += line + "\n"
+	}
+
+	changed, err := writeFileIfChanged(r.kubeletEnvFile, newContent)
+	if err != nil || !changed {
+		return err
+	}
+	klog.Infof("kubelet extra args in %s is updated, restart kubelet", r.kubeletEnvFile)
+	return r.restartKubelet()
+}
+
+// writeFileIfChanged writes the content into the file if the content is changed,
+// and an empty content means the file should be removed.
+func writeFileIfChanged(path, content string) (bool, error) {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	exist := err == nil
+
+	if len(content) == 0 {
+		if !exist {
+			return false, nil
+		}
+		return true, os.Remove(path)
+	}
+
+	if exist && string(old) == content {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(content), fileMode)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openyurtio/openyurt/pkg/util/nodeconfig"
+)
+
+func TestNodeConfigRunner(t *testing.T) {
+	baseDir := t.TempDir()
+	configDir := filepath.Join(baseDir, "data")
+	if err := os.MkdirAll(configDir, dirMode); err != nil {
+		t.Fatalf("could not create config dir, %v", err)
+	}
+
+	var restarted, reloaded int
+	r := &nodeConfigRunner{
+		configDir:          configDir,
+		kubeletEnvFile:     filepath.Join(baseDir, "etc/default/kubelet"),
+		sysctlFile:         filepath.Join(baseDir, "etc/sysctl.d/99-openyurt-nodepool.conf"),
+		containerdCertsDir: filepath.Join(baseDir, "etc/containerd/certs.d"),
+		restartKubelet: func() error {
+			restarted++
+			return nil
+		},
+		reloadSysctl: func(path string) error {
+			reloaded++
+			return nil
+		},
+	}
+
+	writeConfig := func(data map[string]string) {
+		for k, v := range data {
+			if err := os.WriteFile(filepath.Join(configDir, k), []byte(v), fileMode); err != nil {
+				t.Fatalf("could not write config %s, %v", k, err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.kubeletEnvFile), dirMode); err != nil {
+		t.Fatalf("could not create dir, %v", err)
+	}
+	if err := os.WriteFile(r.kubeletEnvFile, []byte("KUBELET_EXTRA_ARGS=--v=2\nFOO=bar\n"), fileMode); err != nil {
+		t.Fatalf("could not write kubelet env file, %v", err)
+	}
+
+	writeConfig(map[string]string{
+		nodeconfig.KubeletExtraArgsKey: "--max-pods=110",
+		nodeconfig.SysctlKey:           "vm.max_map_count = 262144\n",
+		nodeconfig.ContainerdHostsKey:  `{"registry.local:5000":"server = \"https://registry.local:5000\"\n"}`,
+	})
+	if err := r.Do(); err != nil {
+		t.Fatalf("expect no error, but got %v", err)
+	}
+
+	if content, _ := os.ReadFile(r.kubeletEnvFile); string(content) != "KUBELET_EXTRA_ARGS=\"--max-pods=110\"\nFOO=bar\n" {
+		t.Errorf("unexpected kubelet env file: %q", string(content))
+	}
+	if content, _ := os.ReadFile(r.sysctlFile); string(content) != "vm.max_map_count = 262144\n" {
+		t.Errorf("unexpected sysctl file: %q", string(content))
+	}
+	hostsFile := filepath.Join(r.containerdCertsDir, "registry.local:5000", "hosts.toml")
+	if _, err := os.Stat(hostsFile); err != nil {
+		t.Errorf("expect hosts.toml is written, but got %v", err)
+	}
+	if restarted != 1 || reloaded != 1 {
+		t.Errorf("expect kubelet restarted and sysctl reloaded once, but got %d and %d", restarted, reloaded)
+	}
+
+	// nothing changed, kubelet should not be restarted again
+	if err := r.Do(); err != nil {
+		t.Fatalf("expect no error, but got %v", err)
+	}
+	if restarted != 1 || reloaded != 1 {
+		t.Errorf("expect nothing is reloaded, but got %d and %d", restarted, reloaded)
+	}
+
+	// registry is removed from nodepool
+	writeConfig(map[string]string{nodeconfig.ContainerdHostsKey: "{}"})
+	if err := r.Do(); err != nil {
+		t.Fatalf("expect no error, but got %v", err)
+	}
+	if _, err := os.Stat(hostsFile); !os.IsNotExist(err) {
+		t.Errorf("expect hosts.toml is removed, but got %v", err)
+	}
+}
@@ -19,6 +19,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/pflag"
 )
@@ -66,3 +67,47 @@ func (o *ControlPlaneOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.PodManifestsPath, "pod-manifests-path", o.PodManifestsPath, "The path of pod manifests on the worker node.")
 	fs.BoolVar(&o.Version, "version", o.Version, "print the version information.")
 }
+
+// NodeConfigOptions has the information that required by node-servant config node
+type NodeConfigOptions struct {
+	ConfigDir          string
+	KubeletEnvFile     string
+	SysctlFile         string
+	ContainerdCertsDir string
+	Version            bool
+}
+
+// NewNodeConfigOptions creates a new NodeConfigOptions
+func NewNodeConfigOptions() *NodeConfigOptions {
+	return &NodeConfigOptions{
+		ConfigDir:          "/data",
+		KubeletEnvFile:     "/etc/default/kubelet",
+		SysctlFile:         "/etc/sysctl.d/99-openyurt-nodepool.conf",
+		ContainerdCertsDir: "/etc/containerd/certs.d",
+	}
+}
+
+// Validate validates NodeConfigOptions
+func (o *NodeConfigOptions) Validate() error {
+	if info, err := os.Stat(o.ConfigDir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("config dir(%s) should be a directory", o.ConfigDir)
+	}
+
+	for _, path := range []string{o.KubeletEnvFile, o.SysctlFile, o.ContainerdCertsDir} {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("path(%s) should be an absolute path", path)
+		}
+	}
+	return nil
+}
+
+// AddFlags sets flags.
+func (o *NodeConfigOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ConfigDir, "config-dir", o.ConfigDir, "The directory where the rendered node configurations of nodepool are mounted.")
+	fs.StringVar(&o.KubeletEnvFile, "kubelet-env-file", o.KubeletEnvFile, "The environment file of kubelet service which KUBELET_EXTRA_ARGS is written into.")
+	fs.StringVar(&o.SysctlFile, "sysctl-file", o.SysctlFile, "The sysctl config file which the kernel parameters are written into.")
+	fs.StringVar(&o.ContainerdCertsDir, "containerd-certs-dir", o.ContainerdCertsDir, "The config_path of containerd registry where hosts.toml of registries are written into.")
+	fs.BoolVar(&o.Version, "version", o.Version, "print the version information.")
+}
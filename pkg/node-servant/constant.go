@@ -22,6 +22,8 @@ const (
 	ConvertJobNameBase = "node-servant-convert"
 	// RevertJobNameBase is the prefix of the revert ServantJob name
 	RevertJobNameBase = "node-servant-revert"
+	// ConfigNodeJobNameBase is the prefix of the config node ServantJob name
+	ConfigNodeJobNameBase = "node-servant-config-node"
 
 	// ConvertServantJobTemplate defines the node convert servant job in yaml format
 	ConvertServantJobTemplate = `
@@ -54,7 +56,7 @@ spec:
         - /bin/sh
         - -c
         args:
-        - "/usr/local/bin/entry.sh convert --working-mode={{.working_mode}} --yurthub-image={{.yurthub_image}} {{if .yurthub_healthcheck_timeout}}--yurthub-healthcheck-timeout={{.yurthub_healthcheck_timeout}} {{end}}--join-token={{.joinToken}} {{if .enable_dummy_if}}--enable-dummy-if={{.enable_dummy_if}}{{end}} {{if .enable_node_pool}}--enable-node-pool={{.enable_node_pool}}{{end}}"
+        - "/usr/local/bin/entry.sh convert --working-mode={{.working_mode}} --yurthub-image={{.yurthub_image}} {{if .yurthub_healthcheck_timeout}}--yurthub-healthcheck-timeout={{.yurthub_healthcheck_timeout}} {{end}}--join-token={{.joinToken}} {{if .enable_dummy_if}}--enable-dummy-if={{.enable_dummy_if}}{{end}} {{if .enable_node_pool}}--enable-node-pool={{.enable_node_pool}}{{end}} {{if .dry_run}}--dry-run{{end}}"
         securityContext:
           privileged: true
         volumeMounts:
@@ -99,7 +101,7 @@ spec:
         - /bin/sh
         - -c
         args:
-        - "/usr/local/bin/entry.sh revert"
+        - "/usr/local/bin/entry.sh revert {{if .dry_run}}--dry-run{{end}}"
         securityContext:
           privileged: true
         volumeMounts:
@@ -114,5 +116,53 @@ spec:
         - name: KUBELET_SVC
           value: {{.kubeadm_conf_path}}
           {{end}}
+`
+	// ConfigNodeServantJobTemplate defines the node servant job which applies the node configurations of nodepool
+	ConfigNodeServantJobTemplate = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{.jobName}}
+  namespace: {{.namespace}}
+spec:
+  backoffLimit: 3
+  template:
+    spec:
+      hostPID: true
+      hostNetwork: true
+      restartPolicy: OnFailure
+      nodeName: {{.nodeName}}
+      tolerations:
+      - operator: Exists
+      volumes:
+      - name: host-root
+        hostPath:
+          path: /
+          type: Directory
+      - name: configmap
+        configMap:
+          defaultMode: 420
+          name: {{.configmap_name}}
+      containers:
+      - name: node-servant
+        image: {{.node_servant_image}}
+        imagePullPolicy: IfNotPresent
+        command:
+        - /bin/sh
+        - -c
+        args:
+        - "/usr/local/bin/entry.sh config node --config-dir=/data"
+        securityContext:
+          privileged: true
+        volumeMounts:
+        - mountPath: /openyurt
+          name: host-root
+        - mountPath: /openyurt/data
+          name: configmap
+        env:
+        - name: NODE_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: spec.nodeName
 `
 )
@@ -22,6 +22,9 @@ import (
 	"time"
 
 	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	enutil "github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
 
@@ -35,6 +38,7 @@ type Config struct {
 	openyurtDir               string
 	enableDummyIf             bool
 	enableNodePool            bool
+	minKubeletVersion         string
 }
 
 // nodeConverter do the convert job
@@ -54,6 +58,7 @@ func NewConverterWithOptions(o *Options) *nodeConverter {
 			openyurtDir:               o.openyurtDir,
 			enableDummyIf:             o.enableDummyIf,
 			enableNodePool:            o.enableNodePool,
+			minKubeletVersion:         o.minKubeletVersion,
 		},
 	}
 }
@@ -71,6 +76,19 @@ func (n *nodeConverter) Do() error {
 	return nil
 }
 
+// Preflight checks whether the node is ready to be converted without making any changes.
+func (n *nodeConverter) Preflight(nodeName string) *preflight.Report {
+	return preflight.Run(nodeName,
+		preflight.DiskSpaceCheck{Path: components.DefaultRootDir, MinBytes: preflight.DefaultMinDiskSpace},
+		preflight.KubeletVersionCheck{MinVersion: n.minKubeletVersion},
+		preflight.ContainerRuntimeCheck{},
+		preflight.StaticPodCheck{
+			ManifestPath: enutil.GetPodManifestPath(),
+			ManifestName: constants.YurthubYamlName,
+			Installing:   true,
+		})
+}
+
 func (n *nodeConverter) installYurtHub() error {
 	apiServerAddress, err := components.GetApiServerAddress(n.kubeadmConfPaths)
 	if err != nil {
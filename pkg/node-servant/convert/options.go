@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
 	hubutil "github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
@@ -43,6 +44,8 @@ type Options struct {
 	openyurtDir               string
 	enableDummyIf             bool
 	enableNodePool            bool
+	dryRun                    bool
+	minKubeletVersion         string
 	Version                   bool
 }
 
@@ -56,6 +59,7 @@ func NewConvertOptions() *Options {
 		openyurtDir:               constants.OpenyurtDir,
 		enableDummyIf:             true,
 		enableNodePool:            true,
+		minKubeletVersion:         preflight.DefaultMinKubeletVersion,
 	}
 }
 
@@ -72,6 +76,11 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// DryRun returns whether only the preflight checks are run.
+func (o *Options) DryRun() bool {
+	return o.dryRun
+}
+
 // AddFlags sets flags.
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.yurthubImage, "yurthub-image", o.yurthubImage, "The yurthub image.")
@@ -81,5 +90,7 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.workingMode, "working-mode", o.workingMode, "The node type cloud/edge, effect yurthub workingMode.")
 	fs.BoolVar(&o.enableDummyIf, "enable-dummy-if", o.enableDummyIf, "Enable dummy interface for yurthub or not.")
 	fs.BoolVar(&o.enableNodePool, "enable-node-pool", o.enableNodePool, "Enable list/watch nodepools for yurthub or not.")
+	fs.BoolVar(&o.dryRun, "dry-run", o.dryRun, "Only run the preflight checks and report whether the node is ready to be converted, no changes are made.")
+	fs.StringVar(&o.minKubeletVersion, "min-kubelet-version", o.minKubeletVersion, "The minimum kubelet version checked in preflight.")
 	fs.BoolVar(&o.Version, "version", o.Version, "print the version information.")
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdmember
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	peerCommonName   = "openyurt:yurt-coordinator:etcd-peer"
+	peerOrganization = "openyurt:yurt-coordinator"
+	peerCertDuration = time.Hour * 24 * 365 * 100
+)
+
+// signPeerCert signs the etcd peer certificate of node with yurt-coordinator CA. The certificate is used
+// as both server and client certificate between etcd members, so it's issued for the node IP.
+func signPeerCert(caDir, outputDir string, ip net.IP) error {
+	caCerts, err := certutil.CertsFromFile(filepath.Join(caDir, "ca.crt"))
+	if err != nil {
+		return err
+	}
+	caKey, err := keyutil.PrivateKeyFromFile(filepath.Join(caDir, "ca.key"))
+	if err != nil {
+		return err
+	}
+	signer, ok := caKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("the private key of CA is not a signer")
+	}
+
+	key, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	serial, err := cryptorand.Int(cryptorand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return err
+	}
+	tmpl := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:   peerCommonName,
+			Organization: []string{peerOrganization},
+		},
+		IPAddresses:  []net.IP{ip, net.ParseIP("127.0.0.1")},
+		SerialNumber: serial,
+		NotBefore:    caCerts[0].NotBefore,
+		NotAfter:     time.Now().Add(peerCertDuration).UTC(),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, &tmpl, caCerts[0], key.Public(), signer)
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return err
+	}
+	if err := keyutil.WriteKey(filepath.Join(outputDir, "peer.key"), keyPEM); err != nil {
+		return err
+	}
+	return certutil.WriteCert(filepath.Join(outputDir, "peer.crt"), pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der}))
+}
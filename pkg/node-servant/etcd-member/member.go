@@ -0,0 +1,391 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdmember
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+const (
+	ConfigFileName = "etcd.yaml"
+
+	stateNew      = "new"
+	stateExisting = "existing"
+
+	etcdDialTimeout = 5 * time.Second
+	etcdOpTimeout   = 10 * time.Second
+)
+
+// peer is a yurt-coordinator replica in the nodepool, the etcd member of it is named with the node name.
+type peer struct {
+	name string
+	ip   string
+}
+
+// Run prepares the etcd member of yurt-coordinator pod. The member joins the running etcd cluster of
+// nodepool if there is one, the stale member of the same node is replaced because the data of etcd
+// is not kept across pods. Otherwise a new etcd cluster is bootstrapped with all replicas of nodepool.
+func Run(o *Options) error {
+	if err := signPeerCert(o.caDir, o.outputDir, net.ParseIP(o.nodeIP)); err != nil {
+		return errors.Wrap(err, "could not sign etcd peer certificate")
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pod, err := kubeClient.CoreV1().Pods(o.namespace).Get(ctx, o.podName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	pool := pod.Labels[apps.PoolNameLabelKey]
+	if len(pool) == 0 {
+		return fmt.Errorf("pod %s/%s doesn't belong to any nodepool", o.namespace, o.podName)
+	}
+	selector, err := peerSelector(o.selector, pool)
+	if err != nil {
+		return err
+	}
+
+	self := peer{name: o.nodeName, ip: o.nodeIP}
+	initialCluster, state, err := joinRunningCluster(ctx, kubeClient, o, self, selector)
+	if err != nil {
+		return err
+	}
+	if len(initialCluster) == 0 {
+		klog.Infof("no etcd cluster is running in nodepool %s, bootstrap a new one", pool)
+		peers, err := waitForPeers(ctx, kubeClient, o.namespace, selector, o.replicas, o.bootstrapTimeout)
+		if err != nil {
+			return err
+		}
+		initialCluster, err = bootstrapCluster(self, peers, o.replicas, o.peerPort)
+		if err != nil {
+			return err
+		}
+		state = stateNew
+	}
+	klog.Infof("etcd member %s starts with initial cluster %s, state %s", self.name, initialCluster, state)
+
+	base, err := os.ReadFile(o.baseConfig)
+	if err != nil {
+		return err
+	}
+	data, err := renderConfig(base, &memberConfig{
+		name:           self.name,
+		ip:             self.ip,
+		clientPort:     o.clientPort,
+		peerPort:       o.peerPort,
+		initialCluster: initialCluster,
+		state:          state,
+		token:          "yurt-coordinator-" + pool,
+		certDir:        o.certDir,
+		peerCertDir:    o.outputDir,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(o.outputDir, ConfigFileName), data, 0600)
+}
+
+// peerSelector selects the yurt-coordinator pods in the nodepool.
+func peerSelector(selector, pool string) (labels.Selector, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	req, err := labels.NewRequirement(apps.PoolNameLabelKey, selection.Equals, []string{pool})
+	if err != nil {
+		return nil, err
+	}
+	return s.Add(*req), nil
+}
+
+// listPeers returns the scheduled yurt-coordinator pods in the nodepool, sorted by node name.
+func listPeers(ctx context.Context, kubeClient kubernetes.Interface, namespace string, selector labels.Selector) ([]peer, error) {
+	pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return scheduledPeers(pods.Items), nil
+}
+
+func scheduledPeers(pods []corev1.Pod) []peer {
+	seen := map[string]bool{}
+	peers := make([]peer, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || len(pod.Spec.NodeName) == 0 || len(pod.Status.HostIP) == 0 {
+			continue
+		}
+		if seen[pod.Spec.NodeName] {
+			continue
+		}
+		seen[pod.Spec.NodeName] = true
+		peers = append(peers, peer{name: pod.Spec.NodeName, ip: pod.Status.HostIP})
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].name < peers[j].name })
+	return peers
+}
+
+// waitForPeers waits until all replicas are scheduled, the scheduled ones are returned after timeout
+// because some replicas may never be scheduled in a small nodepool.
+func waitForPeers(ctx context.Context, kubeClient kubernetes.Interface, namespace string, selector labels.Selector, replicas int, timeout time.Duration) ([]peer, error) {
+	var peers []peer
+	err := wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		ps, err := listPeers(ctx, kubeClient, namespace, selector)
+		if err != nil {
+			klog.Warningf("could not list yurt-coordinator pods, %v", err)
+			return false, nil
+		}
+		peers = ps
+		return len(peers) >= replicas, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return nil, err
+	}
+	if len(peers) < replicas {
+		klog.Warningf("only %d of %d yurt-coordinator replicas are scheduled, bootstrap etcd cluster with them", len(peers), replicas)
+	}
+	return peers, nil
+}
+
+// bootstrapCluster returns the initial cluster of a new etcd cluster. All replicas should get the same
+// initial cluster, so the first replicas sorted by node name are selected as members.
+func bootstrapCluster(self peer, peers []peer, replicas, peerPort int) (string, error) {
+	if len(peers) > replicas {
+		peers = peers[:replicas]
+	}
+	found := false
+	members := make([]string, 0, len(peers))
+	for _, p := range peers {
+		if p.name == self.name {
+			found = true
+		}
+		members = append(members, fmt.Sprintf("%s=%s", p.name, peerURL(p.ip, peerPort)))
+	}
+	if !found {
+		return "", fmt.Errorf("node %s is not selected as the initial member of etcd cluster %v", self.name, members)
+	}
+	return strings.Join(members, ","), nil
+}
+
+// joinRunningCluster adds the member into the running etcd cluster of nodepool and returns the initial cluster
+// for it. Empty initial cluster is returned if there is no running etcd cluster.
+func joinRunningCluster(ctx context.Context, kubeClient kubernetes.Interface, o *Options, self peer, selector labels.Selector) (string, string, error) {
+	svc, err := kubeClient.CoreV1().Services(o.namespace).Get(ctx, o.service, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	if len(svc.Spec.ClusterIP) == 0 || svc.Spec.ClusterIP == corev1.ClusterIPNone || len(svc.Spec.Ports) == 0 {
+		return "", "", fmt.Errorf("service %s/%s has no cluster ip", o.namespace, o.service)
+	}
+
+	tlsInfo := transport.TLSInfo{
+		CertFile:      filepath.Join(o.certDir, "apiserver-etcd-client.crt"),
+		KeyFile:       filepath.Join(o.certDir, "apiserver-etcd-client.key"),
+		TrustedCAFile: filepath.Join(o.certDir, "ca.crt"),
+	}
+	tlsConfig, err := tlsInfo.ClientConfig()
+	if err != nil {
+		return "", "", err
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"https://" + net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(svc.Spec.Ports[0].Port)))},
+		TLS:         tlsConfig,
+		DialTimeout: etcdDialTimeout,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	defer client.Close()
+
+	listCtx, cancel := context.WithTimeout(ctx, etcdOpTimeout)
+	defer cancel()
+	if _, err := client.MemberList(listCtx); err != nil {
+		klog.Infof("etcd cluster is not reachable through service %s/%s, %v", o.namespace, o.service, err)
+		return "", "", nil
+	}
+
+	peers, err := listPeers(ctx, kubeClient, o.namespace, selector)
+	if err != nil {
+		return "", "", err
+	}
+	opCtx, opCancel := context.WithTimeout(ctx, etcdOpTimeout)
+	defer opCancel()
+	initialCluster, err := joinCluster(opCtx, client.Cluster, self, peers, o.peerPort)
+	if err != nil {
+		return "", "", err
+	}
+	return initialCluster, stateExisting, nil
+}
+
+// joinCluster adds self into the etcd cluster. The stale member of self and the members whose replicas
+// are not in the nodepool any more are removed before that, so the quorum of cluster is kept.
+func joinCluster(ctx context.Context, cluster clientv3.Cluster, self peer, peers []peer, peerPort int) (string, error) {
+	resp, err := cluster.MemberList(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	selfURL := peerURL(self.ip, peerPort)
+	names := map[string]string{selfURL: self.name}
+	for _, p := range peers {
+		names[peerURL(p.ip, peerPort)] = p.name
+	}
+
+	added := false
+	for _, m := range resp.Members {
+		if hasPeerURL(m, selfURL) && len(m.Name) == 0 {
+			// self is added already by the previous attempt, but it's not started yet.
+			added = true
+			continue
+		}
+		if m.Name != self.name && !hasPeerURL(m, selfURL) && hasAnyPeerURL(m, names) {
+			continue
+		}
+		if _, err := cluster.MemberRemove(ctx, m.ID); err != nil {
+			return "", errors.Wrapf(err, "could not remove stale etcd member %s(%x)", m.Name, m.ID)
+		}
+		klog.Infof("stale etcd member %s(%x) with peer urls %v is removed", m.Name, m.ID, m.PeerURLs)
+	}
+
+	var members []*etcdserverpb.Member
+	if added {
+		listResp, err := cluster.MemberList(ctx)
+		if err != nil {
+			return "", err
+		}
+		members = listResp.Members
+	} else {
+		addResp, err := cluster.MemberAdd(ctx, []string{selfURL})
+		if err != nil {
+			return "", errors.Wrapf(err, "could not add etcd member %s", self.name)
+		}
+		klog.Infof("etcd member %s(%x) is added with peer url %s", self.name, addResp.Member.ID, selfURL)
+		members = addResp.Members
+	}
+
+	initialCluster := make([]string, 0, len(members))
+	for _, m := range members {
+		name := m.Name
+		if len(name) == 0 {
+			// the members which are not started have no names yet
+			for _, u := range m.PeerURLs {
+				if n, ok := names[u]; ok {
+					name = n
+					break
+				}
+			}
+		}
+		if len(name) == 0 {
+			name = fmt.Sprintf("member-%x", m.ID)
+		}
+		for _, u := range m.PeerURLs {
+			initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", name, u))
+		}
+	}
+	sort.Strings(initialCluster)
+	return strings.Join(initialCluster, ","), nil
+}
+
+func hasPeerURL(m *etcdserverpb.Member, url string) bool {
+	for _, u := range m.PeerURLs {
+		if u == url {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPeerURL(m *etcdserverpb.Member, urls map[string]string) bool {
+	for _, u := range m.PeerURLs {
+		if _, ok := urls[u]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func peerURL(ip string, port int) string {
+	return "https://" + net.JoinHostPort(ip, strconv.Itoa(port))
+}
+
+// memberConfig is the member specific settings of etcd.
+type memberConfig struct {
+	name           string
+	ip             string
+	clientPort     int
+	peerPort       int
+	initialCluster string
+	state          string
+	token          string
+	certDir        string
+	peerCertDir    string
+}
+
+// renderConfig adds the member specific settings into the base etcd config file.
+func renderConfig(base []byte, m *memberConfig) ([]byte, error) {
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(base, &cfg); err != nil {
+		return nil, errors.Wrap(err, "could not parse base etcd config")
+	}
+
+	cfg["name"] = m.name
+	cfg["listen-peer-urls"] = fmt.Sprintf("https://0.0.0.0:%d", m.peerPort)
+	cfg["initial-advertise-peer-urls"] = peerURL(m.ip, m.peerPort)
+	cfg["advertise-client-urls"] = "https://" + net.JoinHostPort(m.ip, strconv.Itoa(m.clientPort))
+	cfg["initial-cluster"] = m.initialCluster
+	cfg["initial-cluster-state"] = m.state
+	cfg["initial-cluster-token"] = m.token
+	cfg["peer-transport-security"] = map[string]interface{}{
+		"cert-file":        filepath.Join(m.peerCertDir, "peer.crt"),
+		"key-file":         filepath.Join(m.peerCertDir, "peer.key"),
+		"trusted-ca-file":  filepath.Join(m.certDir, "ca.crt"),
+		"client-cert-auth": true,
+	}
+	return yaml.Marshal(cfg)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdmember
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"sigs.k8s.io/yaml"
+)
+
+type fakeCluster struct {
+	clientv3.Cluster
+	members []*etcdserverpb.Member
+	removed []uint64
+	nextID  uint64
+}
+
+func (c *fakeCluster) MemberList(ctx context.Context) (*clientv3.MemberListResponse, error) {
+	return &clientv3.MemberListResponse{Members: c.members}, nil
+}
+
+func (c *fakeCluster) MemberAdd(ctx context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error) {
+	m := &etcdserverpb.Member{ID: c.nextID, PeerURLs: peerAddrs}
+	c.members = append(c.members, m)
+	return &clientv3.MemberAddResponse{Member: m, Members: c.members}, nil
+}
+
+func (c *fakeCluster) MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error) {
+	c.removed = append(c.removed, id)
+	members := make([]*etcdserverpb.Member, 0, len(c.members))
+	for _, m := range c.members {
+		if m.ID != id {
+			members = append(members, m)
+		}
+	}
+	c.members = members
+	return &clientv3.MemberRemoveResponse{Members: members}, nil
+}
+
+func TestJoinCluster(t *testing.T) {
+	self := peer{name: "node-c", ip: "192.168.0.3"}
+	peers := []peer{{name: "node-a", ip: "192.168.0.1"}, {name: "node-b", ip: "192.168.0.2"}, self}
+	testcases := map[string]struct {
+		members        []*etcdserverpb.Member
+		removed        []uint64
+		initialCluster string
+	}{
+		"join as a new member": {
+			members: []*etcdserverpb.Member{
+				{ID: 1, Name: "node-a", PeerURLs: []string{"https://192.168.0.1:12380"}},
+				{ID: 2, Name: "node-b", PeerURLs: []string{"https://192.168.0.2:12380"}},
+			},
+			initialCluster: "node-a=https://192.168.0.1:12380,node-b=https://192.168.0.2:12380,node-c=https://192.168.0.3:12380",
+		},
+		"replace the stale member of node": {
+			members: []*etcdserverpb.Member{
+				{ID: 1, Name: "node-a", PeerURLs: []string{"https://192.168.0.1:12380"}},
+				{ID: 2, Name: "node-b", PeerURLs: []string{"https://192.168.0.2:12380"}},
+				{ID: 3, Name: "node-c", PeerURLs: []string{"https://192.168.0.3:12380"}},
+			},
+			removed:        []uint64{3},
+			initialCluster: "node-a=https://192.168.0.1:12380,node-b=https://192.168.0.2:12380,node-c=https://192.168.0.3:12380",
+		},
+		"remove the member of node out of nodepool": {
+			members: []*etcdserverpb.Member{
+				{ID: 1, Name: "node-a", PeerURLs: []string{"https://192.168.0.1:12380"}},
+				{ID: 2, Name: "node-b", PeerURLs: []string{"https://192.168.0.2:12380"}},
+				{ID: 4, Name: "node-d", PeerURLs: []string{"https://192.168.0.4:12380"}},
+			},
+			removed:        []uint64{4},
+			initialCluster: "node-a=https://192.168.0.1:12380,node-b=https://192.168.0.2:12380,node-c=https://192.168.0.3:12380",
+		},
+		"member is added but not started": {
+			members: []*etcdserverpb.Member{
+				{ID: 1, Name: "node-a", PeerURLs: []string{"https://192.168.0.1:12380"}},
+				{ID: 2, PeerURLs: []string{"https://192.168.0.2:12380"}},
+				{ID: 3, PeerURLs: []string{"https://192.168.0.3:12380"}},
+			},
+			initialCluster: "node-a=https://192.168.0.1:12380,node-b=https://192.168.0.2:12380,node-c=https://192.168.0.3:12380",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			cluster := &fakeCluster{members: tc.members, nextID: 10}
+			initialCluster, err := joinCluster(context.TODO(), cluster, self, peers, 12380)
+			if err != nil {
+				t.Fatalf("could not join cluster, %v", err)
+			}
+			if initialCluster != tc.initialCluster {
+				t.Errorf("expect initial cluster %s, but got %s", tc.initialCluster, initialCluster)
+			}
+			if !reflect.DeepEqual(cluster.removed, tc.removed) {
+				t.Errorf("expect removed members %v, but got %v", tc.removed, cluster.removed)
+			}
+		})
+	}
+}
+
+func TestBootstrapCluster(t *testing.T) {
+	peers := []peer{{name: "node-a", ip: "192.168.0.1"}, {name: "node-b", ip: "192.168.0.2"}, {name: "node-c", ip: "192.168.0.3"}}
+	testcases := map[string]struct {
+		self           peer
+		replicas       int
+		initialCluster string
+		isErr          bool
+	}{
+		"all replicas are members": {
+			self:           peers[2],
+			replicas:       3,
+			initialCluster: "node-a=https://192.168.0.1:12380,node-b=https://192.168.0.2:12380,node-c=https://192.168.0.3:12380",
+		},
+		"node is not selected": {
+			self:     peers[2],
+			replicas: 2,
+			isErr:    true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			initialCluster, err := bootstrapCluster(tc.self, peers, tc.replicas, 12380)
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if initialCluster != tc.initialCluster {
+				t.Errorf("expect initial cluster %s, but got %s", tc.initialCluster, initialCluster)
+			}
+		})
+	}
+}
+
+func TestScheduledPeers(t *testing.T) {
+	now := metav1.Now()
+	pods := []corev1.Pod{
+		{Spec: corev1.PodSpec{NodeName: "node-b"}, Status: corev1.PodStatus{HostIP: "192.168.0.2"}},
+		{Spec: corev1.PodSpec{NodeName: "node-a"}, Status: corev1.PodStatus{HostIP: "192.168.0.1"}},
+		{Spec: corev1.PodSpec{NodeName: "node-c"}},
+		{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}, Spec: corev1.PodSpec{NodeName: "node-d"}, Status: corev1.PodStatus{HostIP: "192.168.0.4"}},
+	}
+	expected := []peer{{name: "node-a", ip: "192.168.0.1"}, {name: "node-b", ip: "192.168.0.2"}}
+	if peers := scheduledPeers(pods); !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expect peers %v, but got %v", expected, peers)
+	}
+}
+
+func TestRenderConfig(t *testing.T) {
+	base := []byte("data-dir: /var/lib/etcd\nlisten-client-urls: https://0.0.0.0:12379\n")
+	data, err := renderConfig(base, &memberConfig{
+		name:           "node-a",
+		ip:             "192.168.0.1",
+		clientPort:     12379,
+		peerPort:       12380,
+		initialCluster: "node-a=https://192.168.0.1:12380",
+		state:          stateNew,
+		token:          "yurt-coordinator-hangzhou",
+		certDir:        "/etc/kubernetes/pki",
+		peerCertDir:    "/etc/etcd",
+	})
+	if err != nil {
+		t.Fatalf("could not render config, %v", err)
+	}
+
+	cfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("could not parse config, %v", err)
+	}
+	expected := map[string]interface{}{
+		"data-dir":                    "/var/lib/etcd",
+		"listen-client-urls":          "https://0.0.0.0:12379",
+		"advertise-client-urls":       "https://192.168.0.1:12379",
+		"initial-advertise-peer-urls": "https://192.168.0.1:12380",
+		"initial-cluster-state":       "new",
+		"initial-cluster-token":       "yurt-coordinator-hangzhou",
+	}
+	for k, v := range expected {
+		if cfg[k] != v {
+			t.Errorf("expect %s to be %v, but got %v", k, v, cfg[k])
+		}
+	}
+	peerSecurity, ok := cfg["peer-transport-security"].(map[string]interface{})
+	if !ok || peerSecurity["cert-file"] != "/etc/etcd/peer.crt" || peerSecurity["client-cert-auth"] != true {
+		t.Errorf("unexpected peer transport security %v", cfg["peer-transport-security"])
+	}
+}
+
+func TestSignPeerCert(t *testing.T) {
+	dir := t.TempDir()
+	caKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key, %v", err)
+	}
+	caCert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "openyurt:yurt-coordinator"}, caKey)
+	if err != nil {
+		t.Fatalf("could not create ca, %v", err)
+	}
+	keyPEM, _ := keyutil.MarshalPrivateKeyToPEM(caKey)
+	if err := keyutil.WriteKey(filepath.Join(dir, "ca.key"), keyPEM); err != nil {
+		t.Fatalf("could not write ca key, %v", err)
+	}
+	if err := certutil.WriteCert(filepath.Join(dir, "ca.crt"), pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: caCert.Raw})); err != nil {
+		t.Fatalf("could not write ca cert, %v", err)
+	}
+
+	if err := signPeerCert(dir, dir, net.ParseIP("192.168.0.1")); err != nil {
+		t.Fatalf("could not sign peer cert, %v", err)
+	}
+	certs, err := certutil.CertsFromFile(filepath.Join(dir, "peer.crt"))
+	if err != nil {
+		t.Fatalf("could not load peer cert, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "peer.key")); err != nil {
+		t.Errorf("peer key is not written, %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	for _, usage := range []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth} {
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, DNSName: "192.168.0.1", KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Errorf("peer cert is not valid for usage %v, %v", usage, err)
+		}
+	}
+	if !strings.Contains(certs[0].Subject.CommonName, "etcd-peer") {
+		t.Errorf("unexpected common name %s", certs[0].Subject.CommonName)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdmember
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	DefaultEtcdService      = "yurt-coordinator-etcd"
+	DefaultClientPort       = 12379
+	DefaultPeerPort         = 12380
+	DefaultCertDir          = "/etc/kubernetes/pki"
+	DefaultCADir            = "/etc/yurt-coordinator/ca"
+	DefaultBaseConfig       = "/etc/yurt-coordinator/etcd/base.yaml"
+	DefaultOutputDir        = "/etc/etcd"
+	DefaultBootstrapTimeout = 2 * time.Minute
+)
+
+// Options has the information that required by coordinator-etcd-member operation
+type Options struct {
+	namespace        string
+	podName          string
+	nodeName         string
+	nodeIP           string
+	selector         string
+	service          string
+	replicas         int
+	clientPort       int
+	peerPort         int
+	certDir          string
+	caDir            string
+	baseConfig       string
+	outputDir        string
+	bootstrapTimeout time.Duration
+}
+
+// NewEtcdMemberOptions creates a new Options
+func NewEtcdMemberOptions() *Options {
+	return &Options{
+		service:          DefaultEtcdService,
+		replicas:         3,
+		clientPort:       DefaultClientPort,
+		peerPort:         DefaultPeerPort,
+		certDir:          DefaultCertDir,
+		caDir:            DefaultCADir,
+		baseConfig:       DefaultBaseConfig,
+		outputDir:        DefaultOutputDir,
+		bootstrapTimeout: DefaultBootstrapTimeout,
+	}
+}
+
+// AddFlags sets flags.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.namespace, "namespace", o.namespace, "The namespace of yurt-coordinator pods.")
+	fs.StringVar(&o.podName, "pod-name", o.podName, "The name of yurt-coordinator pod which the etcd member belongs to.")
+	fs.StringVar(&o.nodeName, "node-name", o.nodeName, "The name of node, it's used as the name of etcd member.")
+	fs.StringVar(&o.nodeIP, "node-ip", o.nodeIP, "The IP of node, etcd peers and clients are advertised with it.")
+	fs.StringVar(&o.selector, "selector", o.selector, "The label selector of yurt-coordinator pods, the pods in the same nodepool are peers of each other.")
+	fs.StringVar(&o.service, "service", o.service, "The service of yurt-coordinator etcd, it's used to find the running etcd cluster of nodepool.")
+	fs.IntVar(&o.replicas, "replicas", o.replicas, "The number of yurt-coordinator replicas in each nodepool.")
+	fs.IntVar(&o.clientPort, "client-port", o.clientPort, "The port of etcd for client traffic.")
+	fs.IntVar(&o.peerPort, "peer-port", o.peerPort, "The port of etcd for peer traffic.")
+	fs.StringVar(&o.certDir, "cert-dir", o.certDir, "The directory of yurt-coordinator certificates, ca.crt and apiserver-etcd-client.crt/key are used.")
+	fs.StringVar(&o.caDir, "ca-dir", o.caDir, "The directory of yurt-coordinator CA, ca.crt and ca.key are used to sign the etcd peer certificate.")
+	fs.StringVar(&o.baseConfig, "base-config", o.baseConfig, "The etcd config file which member specific settings are added into.")
+	fs.StringVar(&o.outputDir, "output-dir", o.outputDir, "The directory where etcd config file and peer certificate are written.")
+	fs.DurationVar(&o.bootstrapTimeout, "bootstrap-timeout", o.bootstrapTimeout, "The time to wait for all replicas to be scheduled when a new etcd cluster is bootstrapped.")
+}
+
+// Validate validates Options
+func (o *Options) Validate() error {
+	if len(o.namespace) == 0 || len(o.podName) == 0 || len(o.nodeName) == 0 || len(o.selector) == 0 {
+		return fmt.Errorf("args can not be empty, namespace is %s, pod-name is %s, node-name is %s, selector is %s",
+			o.namespace, o.podName, o.nodeName, o.selector)
+	}
+	if net.ParseIP(o.nodeIP) == nil {
+		return fmt.Errorf("node-ip %q is not a valid IP", o.nodeIP)
+	}
+	if o.replicas < 1 {
+		return fmt.Errorf("replicas should be at least 1, but got %d", o.replicas)
+	}
+	return nil
+}
@@ -18,10 +18,13 @@ package node_servant
 
 import (
 	"fmt"
+	"hash/fnv"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/scheme"
 
 	tmplutil "github.com/openyurtio/openyurt/pkg/util/templates"
@@ -46,9 +49,12 @@ func RenderNodeServantJob(action string, renderCtx map[string]string, nodeName s
 	case "revert":
 		servantJobTemplate = RevertServantJobTemplate
 		jobBaseName = RevertJobNameBase
+	case "config-node":
+		servantJobTemplate = ConfigNodeServantJobTemplate
+		jobBaseName = ConfigNodeJobNameBase
 	}
 
-	tmplCtx["jobName"] = jobBaseName + "-" + nodeName
+	tmplCtx["jobName"] = jobName(jobBaseName, nodeName, tmplCtx["jobNameSuffix"])
 	tmplCtx["nodeName"] = nodeName
 	jobYaml, err := tmplutil.SubsituteTemplate(servantJobTemplate, tmplCtx)
 	if err != nil {
@@ -67,6 +73,33 @@ func RenderNodeServantJob(action string, renderCtx map[string]string, nodeName s
 	return srvJob, nil
 }
 
+// jobName returns the name of servant job for the node. the node name in job name is truncated
+// and appended with its hash if the job name is longer than 63 characters, so the job name is
+// still a valid label value and the prefix and suffix of job name are kept.
+func jobName(base, nodeName, suffix string) string {
+	tail := ""
+	if len(suffix) != 0 {
+		tail = "-" + suffix
+	}
+	name := base + "-" + nodeName + tail
+	if len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+
+	hasher := fnv.New32a()
+	hasher.Write([]byte(nodeName))
+	hash := fmt.Sprintf("%08x", hasher.Sum32())
+	maxLen := validation.DNS1123LabelMaxLength - len(base) - len(hash) - len(tail) - 2
+	if maxLen < 0 {
+		maxLen = 0
+	}
+	truncated := strings.TrimRight(nodeName[:maxLen], "-.")
+	if len(truncated) == 0 {
+		return base + "-" + hash + tail
+	}
+	return base + "-" + truncated + "-" + hash + tail
+}
+
 // YamlToObject deserializes object in yaml format to a runtime.Object
 func YamlToObject(yamlContent []byte) (k8sruntime.Object, error) {
 	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
@@ -89,6 +122,9 @@ func validate(action string, tmplCtx map[string]string, nodeName string) error {
 	case "revert":
 		keysMustHave := []string{"node_servant_image"}
 		return checkKeys(keysMustHave, tmplCtx)
+	case "config-node":
+		keysMustHave := []string{"node_servant_image", "configmap_name", "namespace"}
+		return checkKeys(keysMustHave, tmplCtx)
 	default:
 		return fmt.Errorf("action invalied: %s ", action)
 	}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node_servant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJobName(t *testing.T) {
+	longNodeName := "cn-hangzhou.i-bp1f8ha3kd9zxxxxxxxx.edge-node-with-a-very-long-name"
+	testcases := map[string]struct {
+		nodeName string
+		suffix   string
+		expect   string
+	}{
+		"short node name": {
+			nodeName: "node1",
+			suffix:   "abcdef",
+			expect:   "node-servant-config-node-node1-abcdef",
+		},
+		"short node name without suffix": {
+			nodeName: "node1",
+			expect:   "node-servant-config-node-node1",
+		},
+		"long node name": {
+			nodeName: longNodeName,
+			suffix:   "abcdef",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			name := jobName(ConfigNodeJobNameBase, tc.nodeName, tc.suffix)
+			if len(tc.expect) != 0 && name != tc.expect {
+				t.Errorf("expect job name %s, but got %s", tc.expect, name)
+			}
+			if len(name) > 63 {
+				t.Errorf("job name %s is longer than 63 characters", name)
+			}
+			if !strings.HasPrefix(name, ConfigNodeJobNameBase+"-") || (len(tc.suffix) != 0 && !strings.HasSuffix(name, "-"+tc.suffix)) {
+				t.Errorf("expect prefix and suffix of job name %s are kept", name)
+			}
+		})
+	}
+
+	if jobName(ConfigNodeJobNameBase, longNodeName, "abcdef") == jobName(ConfigNodeJobNameBase, longNodeName+"2", "abcdef") {
+		t.Errorf("expect different job names for different long node names")
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+const (
+	// DefaultMinNvidiaDriverVersion is the minimum nvidia driver version which supports CUDA 11
+	// and the nvidia device plugin.
+	DefaultMinNvidiaDriverVersion = "450.80.02"
+
+	// GPUPresentLabel, GPUProductLabel and GPUCountLabel are the labels of gpu feature discovery,
+	// they're set on node before the device plugin and gpu feature discovery are running.
+	GPUPresentLabel = "nvidia.com/gpu.present"
+	GPUProductLabel = "nvidia.com/gpu.product"
+	GPUCountLabel   = "nvidia.com/gpu.count"
+
+	// AcceleratorNvidia is the value of accelerator label for nodes with nvidia gpus.
+	AcceleratorNvidia = "nvidia"
+)
+
+var (
+	// nvidiaContainerRuntimes are the binaries installed by nvidia-container-toolkit
+	nvidiaContainerRuntimes = []string{"nvidia-container-runtime", "nvidia-container-runtime-hook"}
+	// containerRuntimeConfigs are the config files of container runtimes where the nvidia runtime is registered
+	containerRuntimeConfigs = []string{
+		"/etc/containerd/config.toml",
+		"/etc/docker/daemon.json",
+		"/var/lib/rancher/k3s/agent/etc/containerd/config.toml",
+		"/var/lib/rancher/rke2/agent/etc/containerd/config.toml",
+	}
+
+	invalidLabelValueChars = regexp.MustCompile(`[^-A-Za-z0-9_.]`)
+)
+
+// NvidiaGPU is a gpu reported by nvidia-smi.
+type NvidiaGPU struct {
+	Product       string
+	DriverVersion string
+}
+
+// DetectNvidiaGPUs returns the gpus on node reported by nvidia-smi.
+func DetectNvidiaGPUs() ([]NvidiaGPU, error) {
+	out, err := exec.Command("nvidia-smi", "--query-gpu=name,driver_version", "--format=csv,noheader").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v, output: %s", err, bytes.TrimSpace(out))
+	}
+	return parseNvidiaSMI(string(out)), nil
+}
+
+// parseNvidiaSMI parses the output of nvidia-smi, e.g. "NVIDIA A100-SXM4-40GB, 535.104.05"
+func parseNvidiaSMI(out string) []NvidiaGPU {
+	var gpus []NvidiaGPU
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			continue
+		}
+		gpus = append(gpus, NvidiaGPU{
+			Product:       strings.TrimSpace(fields[0]),
+			DriverVersion: strings.TrimSpace(fields[1]),
+		})
+	}
+	return gpus
+}
+
+// GPULabels returns the labels of node with gpus, the product is the model of the first gpu.
+func GPULabels(gpus []NvidiaGPU) map[string]string {
+	if len(gpus) == 0 {
+		return nil
+	}
+	labels := map[string]string{
+		GPUPresentLabel:           "true",
+		GPUCountLabel:             strconv.Itoa(len(gpus)),
+		apps.NodeAcceleratorLabel: AcceleratorNvidia,
+	}
+	// the label value is like NVIDIA-A100-SXM4-40GB
+	product := invalidLabelValueChars.ReplaceAllString(strings.ReplaceAll(gpus[0].Product, " ", "-"), "")
+	if len(product) > 63 {
+		product = product[:63]
+	}
+	if product = strings.Trim(product, "-_."); len(product) != 0 {
+		labels[GPUProductLabel] = product
+	}
+	return labels
+}
+
+// GPUCheck checks the prerequisites of nvidia device plugin on node: the nvidia driver is not older than
+// MinDriverVersion, nvidia-container-toolkit is installed and the gpu devices are present.
+type GPUCheck struct {
+	MinDriverVersion string
+	// Root is prefixed to the paths checked on node, it's only overridden in tests.
+	Root string
+	// detectFunc and lookPathFunc are only overridden in tests.
+	detectFunc   func() ([]NvidiaGPU, error)
+	lookPathFunc func(string) (string, error)
+}
+
+func (c GPUCheck) Name() string {
+	return "GPU"
+}
+
+func (c GPUCheck) Check() (warnings, errorList []error) {
+	detectFunc, lookPathFunc := c.detectFunc, c.lookPathFunc
+	if detectFunc == nil {
+		detectFunc = DetectNvidiaGPUs
+	}
+	if lookPathFunc == nil {
+		lookPathFunc = exec.LookPath
+	}
+
+	gpus, err := detectFunc()
+	if err != nil {
+		errorList = append(errorList, fmt.Errorf("nvidia driver is not working, nvidia-smi failed: %v", err))
+	} else if len(gpus) == 0 {
+		errorList = append(errorList, fmt.Errorf("no nvidia gpu is found by nvidia-smi"))
+	} else if err := checkDriverVersion(gpus[0].DriverVersion, c.MinDriverVersion); err != nil {
+		errorList = append(errorList, err)
+	}
+
+	toolkitFound := false
+	for _, runtime := range nvidiaContainerRuntimes {
+		if _, err := lookPathFunc(runtime); err == nil {
+			toolkitFound = true
+			break
+		}
+	}
+	if !toolkitFound {
+		errorList = append(errorList, fmt.Errorf("nvidia-container-toolkit is not installed, none of %s is found", strings.Join(nvidiaContainerRuntimes, ", ")))
+	} else if !c.nvidiaRuntimeConfigured() {
+		warnings = append(warnings, fmt.Errorf("nvidia runtime is not registered in containerd or docker, pods using gpus need a RuntimeClass whose handler is nvidia"))
+	}
+
+	// the device plugin mounts these devices into containers
+	if _, err := os.Stat(filepath.Join(c.Root, "/dev/nvidiactl")); err != nil {
+		errorList = append(errorList, fmt.Errorf("gpu device /dev/nvidiactl is not present, %v", err))
+	}
+	if _, err := os.Stat(filepath.Join(c.Root, "/dev/nvidia-uvm")); err != nil {
+		warnings = append(warnings, fmt.Errorf("gpu device /dev/nvidia-uvm is not present, please load the nvidia-uvm kernel module"))
+	}
+	return warnings, errorList
+}
+
+func checkDriverVersion(driverVersion, minVersion string) error {
+	if len(minVersion) == 0 {
+		return nil
+	}
+	min, err := version.ParseGeneric(minVersion)
+	if err != nil {
+		return fmt.Errorf("invalid min nvidia driver version %q, %v", minVersion, err)
+	}
+	v, err := version.ParseGeneric(driverVersion)
+	if err != nil {
+		return fmt.Errorf("nvidia driver version %q can not be parsed, %v", driverVersion, err)
+	}
+	if !v.AtLeast(min) {
+		return fmt.Errorf("nvidia driver version %s is lower than %s", driverVersion, minVersion)
+	}
+	return nil
+}
+
+// nvidiaRuntimeConfigured checks whether the nvidia runtime is registered in any config of container runtime
+func (c GPUCheck) nvidiaRuntimeConfigured() bool {
+	for _, config := range containerRuntimeConfigs {
+		data, err := os.ReadFile(filepath.Join(c.Root, config))
+		if err == nil && bytes.Contains(data, []byte("nvidia")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+func TestParseNvidiaSMI(t *testing.T) {
+	out := "NVIDIA A100-SXM4-40GB, 535.104.05\nNVIDIA A100-SXM4-40GB, 535.104.05\n"
+	want := []NvidiaGPU{
+		{Product: "NVIDIA A100-SXM4-40GB", DriverVersion: "535.104.05"},
+		{Product: "NVIDIA A100-SXM4-40GB", DriverVersion: "535.104.05"},
+	}
+	if got := parseNvidiaSMI(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("expect %v, but got %v", want, got)
+	}
+}
+
+func TestGPULabels(t *testing.T) {
+	labels := GPULabels([]NvidiaGPU{{Product: "Tesla T4 (PCIe)", DriverVersion: "470.57.02"}})
+	want := map[string]string{
+		GPUPresentLabel:           "true",
+		GPUCountLabel:             "1",
+		GPUProductLabel:           "Tesla-T4-PCIe",
+		apps.NodeAcceleratorLabel: AcceleratorNvidia,
+	}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("expect %v, but got %v", want, labels)
+	}
+
+	if labels := GPULabels(nil); labels != nil {
+		t.Errorf("expect no labels without gpus, but got %v", labels)
+	}
+}
+
+func TestGPUCheck(t *testing.T) {
+	gpus := []NvidiaGPU{{Product: "NVIDIA A10", DriverVersion: "470.57.02"}}
+	tests := []struct {
+		name         string
+		detectErr    error
+		minVersion   string
+		toolkit      bool
+		devices      []string
+		runtimeCfg   bool
+		wantErrs     int
+		wantWarnings int
+	}{
+		{
+			name:       "gpu is ready",
+			minVersion: DefaultMinNvidiaDriverVersion,
+			toolkit:    true,
+			devices:    []string{"nvidiactl", "nvidia-uvm"},
+			runtimeCfg: true,
+		},
+		{
+			name:         "runtime is not configured and uvm is not loaded",
+			minVersion:   DefaultMinNvidiaDriverVersion,
+			toolkit:      true,
+			devices:      []string{"nvidiactl"},
+			wantWarnings: 2,
+		},
+		{
+			name:       "driver is too old",
+			minVersion: "525.60.13",
+			toolkit:    true,
+			devices:    []string{"nvidiactl", "nvidia-uvm"},
+			runtimeCfg: true,
+			wantErrs:   1,
+		},
+		{
+			name:         "driver and toolkit are not installed",
+			detectErr:    errors.New("nvidia-smi: not found"),
+			minVersion:   DefaultMinNvidiaDriverVersion,
+			wantErrs:     3,
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(root, "dev"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, dev := range tt.devices {
+				if err := os.WriteFile(filepath.Join(root, "dev", dev), nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.runtimeCfg {
+				config := filepath.Join(root, "etc/containerd/config.toml")
+				if err := os.MkdirAll(filepath.Dir(config), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(config, []byte(`[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.nvidia]`), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c := GPUCheck{
+				MinDriverVersion: tt.minVersion,
+				Root:             root,
+				detectFunc: func() ([]NvidiaGPU, error) {
+					return gpus, tt.detectErr
+				},
+				lookPathFunc: func(file string) (string, error) {
+					if tt.toolkit {
+						return "/usr/bin/" + file, nil
+					}
+					return "", errors.New("not found")
+				},
+			}
+			warnings, errs := c.Check()
+			if len(errs) != tt.wantErrs || len(warnings) != tt.wantWarnings {
+				t.Errorf("expect %d errors and %d warnings, but got %v and %v", tt.wantErrs, tt.wantWarnings, errs, warnings)
+			}
+		})
+	}
+}
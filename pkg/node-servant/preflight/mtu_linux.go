@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// pathMTU returns the MTU of route to endpoint known by kernel, which includes the path MTU
+// learned from ICMP "fragmentation needed" messages.
+func pathMTU(endpoint string) (int, error) {
+	conn, err := net.Dial("udp", endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	raw, err := conn.(*net.UDPConn).SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	level, opt := unix.IPPROTO_IP, unix.IP_MTU
+	if addr := conn.RemoteAddr().(*net.UDPAddr); addr.IP.To4() == nil {
+		level, opt = unix.IPPROTO_IPV6, unix.IPV6_MTU
+	}
+
+	var mtu int
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		mtu, sockErr = unix.GetsockoptInt(int(fd), level, opt)
+	}); err != nil {
+		return 0, err
+	}
+	return mtu, sockErr
+}
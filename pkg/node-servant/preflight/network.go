@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxAPIServerLatency is the latency to kube-apiserver above which a warning is reported,
+	// yurthub and kubelet still work on slow links but their requests may time out.
+	DefaultMaxAPIServerLatency = 500 * time.Millisecond
+	// DefaultMinPathMTU is the path MTU to kube-apiserver below which a warning is reported.
+	DefaultMinPathMTU = 1500
+	// DefaultMaxClockSkew is the clock skew from kube-apiserver above which the check fails, certificates
+	// issued by the cluster are rejected as not yet valid or expired if the clocks drift too much.
+	DefaultMaxClockSkew = 30 * time.Second
+
+	dialTimeout = 5 * time.Second
+)
+
+// APIServerCheck checks kube-apiserver endpoints are reachable from node, and the latency of connection.
+type APIServerCheck struct {
+	// Endpoints are host:port of kube-apiserver
+	Endpoints  []string
+	MaxLatency time.Duration
+}
+
+func (c APIServerCheck) Name() string {
+	return "APIServerReachability"
+}
+
+func (c APIServerCheck) Check() (warnings, errorList []error) {
+	for _, endpoint := range c.Endpoints {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", endpoint, dialTimeout)
+		if err != nil {
+			errorList = append(errorList, fmt.Errorf("kube-apiserver %s is unreachable, %v, please check the route and firewall between node and kube-apiserver", endpoint, err))
+			continue
+		}
+		latency := time.Since(start)
+		conn.Close()
+		if c.MaxLatency > 0 && latency > c.MaxLatency {
+			warnings = append(warnings, fmt.Errorf("latency to kube-apiserver %s is %s, more than %s, requests of yurthub may time out", endpoint, latency, c.MaxLatency))
+		}
+	}
+	return warnings, errorList
+}
+
+// PathMTUCheck checks the MTU of path to kube-apiserver, packets larger than it are dropped
+// if ICMP is filtered on the way, which makes TLS handshakes and large responses hang.
+type PathMTUCheck struct {
+	Endpoint string
+	MinMTU   int
+}
+
+func (c PathMTUCheck) Name() string {
+	return "PathMTU"
+}
+
+func (c PathMTUCheck) Check() (warnings, errorList []error) {
+	mtu, err := pathMTU(c.Endpoint)
+	if err != nil {
+		return []error{fmt.Errorf("fail to get path MTU to %s, %v", c.Endpoint, err)}, nil
+	}
+	if mtu < c.MinMTU {
+		return []error{fmt.Errorf("path MTU to %s is %d, less than %d, the MTU of CNI should be lowered to avoid dropped packets", c.Endpoint, mtu, c.MinMTU)}, nil
+	}
+	return nil, nil
+}
+
+// ClockSkewCheck checks the clock of node against the Date header of kube-apiserver response.
+type ClockSkewCheck struct {
+	// URL is requested to get the time of kube-apiserver
+	URL     string
+	Client  *http.Client
+	MaxSkew time.Duration
+}
+
+func (c ClockSkewCheck) Name() string {
+	return "ClockSkew"
+}
+
+func (c ClockSkewCheck) Check() (warnings, errorList []error) {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: dialTimeout}
+	}
+	start := time.Now()
+	resp, err := client.Get(c.URL)
+	if err != nil {
+		return nil, []error{fmt.Errorf("fail to get time of kube-apiserver from %s, %v", c.URL, err)}
+	}
+	resp.Body.Close()
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return []error{fmt.Errorf("kube-apiserver response has no valid Date header, clock skew is not checked")}, nil
+	}
+
+	// the server time is taken at the middle of request, and the Date header is accurate to a second
+	local := start.Add(time.Since(start) / 2)
+	skew := local.Sub(serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > c.MaxSkew+time.Second {
+		return nil, []error{fmt.Errorf("clock of node is %s away from kube-apiserver, more than %s, please sync the clock by NTP", skew.Round(time.Second), c.MaxSkew)}
+	}
+	return nil, nil
+}
+
+// PortCheck checks the ports required by node components are not in use.
+type PortCheck struct {
+	Ports []int
+}
+
+func (c PortCheck) Name() string {
+	return "Ports"
+}
+
+func (c PortCheck) Check() (warnings, errorList []error) {
+	for _, port := range c.Ports {
+		ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+		if err != nil {
+			errorList = append(errorList, fmt.Errorf("port %d is in use, please stop the process listening on it", port))
+			continue
+		}
+		ln.Close()
+	}
+	return nil, errorList
+}
+
+// DNSCheck checks the host names can be resolved, IP addresses are skipped. Hosts are required,
+// and failures of OptionalHosts are reported as warnings. Empty hosts are skipped.
+type DNSCheck struct {
+	Hosts         []string
+	OptionalHosts []string
+}
+
+func (c DNSCheck) Name() string {
+	return "DNS"
+}
+
+func (c DNSCheck) Check() (warnings, errorList []error) {
+	for _, host := range c.Hosts {
+		if err := resolve(host); err != nil {
+			errorList = append(errorList, err)
+		}
+	}
+	for _, host := range c.OptionalHosts {
+		if err := resolve(host); err != nil {
+			warnings = append(warnings, err)
+		}
+	}
+	return warnings, errorList
+}
+
+func resolve(host string) error {
+	if len(host) == 0 || net.ParseIP(host) != nil {
+		return nil
+	}
+	if _, err := net.LookupHost(host); err != nil {
+		return fmt.Errorf("fail to resolve %s, please check the nameservers in /etc/resolv.conf, %v", host, err)
+	}
+	return nil
+}
+
+// HostOf returns the host of endpoint, which may be host:port, a URL or a host.
+func HostOf(endpoint string) string {
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		endpoint = endpoint[i+3:]
+	}
+	endpoint = strings.SplitN(endpoint, "/", 2)[0]
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return strings.Trim(endpoint, "[]")
+}
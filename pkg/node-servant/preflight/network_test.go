@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIServerCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to listen, %v", err)
+	}
+	reachable := ln.Addr().String()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to listen, %v", err)
+	}
+	unreachable := closed.Addr().String()
+	closed.Close()
+	defer ln.Close()
+
+	if _, errs := (APIServerCheck{Endpoints: []string{reachable}, MaxLatency: time.Minute}).Check(); len(errs) != 0 {
+		t.Errorf("expect kube-apiserver reachable, but got %v", errs)
+	}
+	if _, errs := (APIServerCheck{Endpoints: []string{reachable, unreachable}}).Check(); len(errs) != 1 {
+		t.Errorf("expect one unreachable kube-apiserver, but got %v", errs)
+	}
+}
+
+func TestClockSkewCheck(t *testing.T) {
+	var offset time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		offset  time.Duration
+		wantErr bool
+	}{
+		{name: "clock is synced"},
+		{name: "node is behind kube-apiserver", offset: time.Hour, wantErr: true},
+		{name: "node is ahead of kube-apiserver", offset: -time.Hour, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset = tt.offset
+			_, errs := ClockSkewCheck{URL: server.URL, MaxSkew: DefaultMaxClockSkew}.Check()
+			if (len(errs) != 0) != tt.wantErr {
+				t.Errorf("expect error %v, but got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestPortCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("fail to listen, %v", err)
+	}
+	defer ln.Close()
+
+	if _, errs := (PortCheck{Ports: []int{ln.Addr().(*net.TCPAddr).Port}}).Check(); len(errs) != 1 {
+		t.Errorf("expect port in use, but got %v", errs)
+	}
+}
+
+func TestDNSCheck(t *testing.T) {
+	if _, errs := (DNSCheck{Hosts: []string{"127.0.0.1", "::1"}}).Check(); len(errs) != 0 {
+		t.Errorf("expect IP addresses skipped, but got %v", errs)
+	}
+	warnings, errs := DNSCheck{OptionalHosts: []string{"nonexistent.invalid"}}.Check()
+	if len(errs) != 0 || len(warnings) != 1 {
+		t.Errorf("expect a warning of optional host, but got warnings %v, errors %v", warnings, errs)
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	tests := map[string]string{
+		"1.2.3.4:6443":             "1.2.3.4",
+		"[::1]:6443":               "::1",
+		"apiserver.example.com":    "apiserver.example.com",
+		"https://dl.k8s.io/v1.22":  "dl.k8s.io",
+		"https://apiserver:6443/x": "apiserver",
+	}
+	for endpoint, want := range tests {
+		if got := HostOf(endpoint); got != want {
+			t.Errorf("expect host of %s is %s, but got %s", endpoint, want, got)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	utilsexec "k8s.io/utils/exec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+)
+
+const (
+	// DefaultMinDiskSpace is the minimum free disk space required to convert a node,
+	// which is enough to pull the yurthub image and store its cache.
+	DefaultMinDiskSpace = 1 << 30
+	// DefaultMinKubeletVersion is the minimum kubelet version supported by OpenYurt.
+	DefaultMinKubeletVersion = "v1.18.0"
+)
+
+// Checker validates the state of node before it's converted or reverted.
+type Checker interface {
+	// Name returns the name of check.
+	Name() string
+	// Check returns warnings which don't block the operation, and errors which do.
+	Check() (warnings, errorList []error)
+}
+
+// Result is the result of a check on node.
+type Result struct {
+	Name     string
+	Warnings []error
+	Errors   []error
+}
+
+// Report is the readiness verdict of a node, it's ready only if no check returns an error.
+type Report struct {
+	NodeName string
+	Results  []Result
+}
+
+// Run runs all checks on node and returns the report.
+func Run(nodeName string, checks ...Checker) *Report {
+	report := &Report{NodeName: nodeName}
+	for _, c := range checks {
+		warnings, errs := c.Check()
+		report.Results = append(report.Results, Result{Name: c.Name(), Warnings: warnings, Errors: errs})
+	}
+	return report
+}
+
+// Ready checks whether all checks are passed.
+func (r *Report) Ready() bool {
+	for _, result := range r.Results {
+		if len(result.Errors) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreErrors shows the errors of checks whose names are in ignored as warnings, the names are case
+// insensitive and "all" ignores errors of all checks.
+func (r *Report) IgnoreErrors(ignored []string) {
+	names := make(map[string]bool, len(ignored))
+	for _, name := range ignored {
+		names[strings.ToLower(name)] = true
+	}
+	for i := range r.Results {
+		result := &r.Results[i]
+		if len(result.Errors) != 0 && (names["all"] || names[strings.ToLower(result.Name)]) {
+			result.Warnings = append(result.Warnings, result.Errors...)
+			result.Errors = nil
+		}
+	}
+}
+
+// String prints the result of every check and the verdict of node.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		state := "PASS"
+		if len(result.Errors) != 0 {
+			state = "FAIL"
+		} else if len(result.Warnings) != 0 {
+			state = "WARN"
+		}
+		fmt.Fprintf(&b, "[%s] %s\n", state, result.Name)
+		for _, err := range result.Errors {
+			fmt.Fprintf(&b, "\terror: %v\n", err)
+		}
+		for _, err := range result.Warnings {
+			fmt.Fprintf(&b, "\twarning: %v\n", err)
+		}
+	}
+
+	verdict := "ready"
+	if !r.Ready() {
+		verdict = "not ready"
+	}
+	fmt.Fprintf(&b, "node %s is %s\n", r.NodeName, verdict)
+	return b.String()
+}
+
+// DiskSpaceCheck checks the free space of file system which contains Path.
+type DiskSpaceCheck struct {
+	Path     string
+	MinBytes uint64
+}
+
+func (c DiskSpaceCheck) Name() string {
+	return "DiskSpace"
+}
+
+func (c DiskSpaceCheck) Check() (warnings, errorList []error) {
+	free, err := freeSpace(c.Path)
+	if err != nil {
+		return nil, []error{fmt.Errorf("fail to stat file system of %s, %v", c.Path, err)}
+	}
+	if free < c.MinBytes {
+		return nil, []error{fmt.Errorf("free space of %s is %d bytes, less than %d bytes", c.Path, free, c.MinBytes)}
+	}
+	return nil, nil
+}
+
+// KubeletVersionCheck checks the kubelet on node is not older than MinVersion.
+type KubeletVersionCheck struct {
+	MinVersion string
+	// versionFunc returns the output of `kubelet --version`, it's only overridden in tests.
+	versionFunc func() (string, error)
+}
+
+func (c KubeletVersionCheck) Name() string {
+	return "KubeletVersion"
+}
+
+func (c KubeletVersionCheck) Check() (warnings, errorList []error) {
+	minVersion, err := version.ParseGeneric(c.MinVersion)
+	if err != nil {
+		return nil, []error{fmt.Errorf("invalid min kubelet version %q, %v", c.MinVersion, err)}
+	}
+
+	versionFunc := c.versionFunc
+	if versionFunc == nil {
+		versionFunc = kubeletVersion
+	}
+	out, err := versionFunc()
+	if err != nil {
+		return nil, []error{fmt.Errorf("fail to get kubelet version, %v", err)}
+	}
+	// the output is like "Kubernetes v1.22.7"
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return nil, []error{fmt.Errorf("kubelet version is empty")}
+	}
+	v, err := version.ParseGeneric(fields[len(fields)-1])
+	if err != nil {
+		return nil, []error{fmt.Errorf("kubelet version %q can not be parsed, %v", out, err)}
+	}
+	if !v.AtLeast(minVersion) {
+		return nil, []error{fmt.Errorf("kubelet version %s is lower than %s", v, minVersion)}
+	}
+	return nil, nil
+}
+
+func kubeletVersion() (string, error) {
+	out, err := exec.Command("kubelet", "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v, output: %s", err, out)
+	}
+	return string(out), nil
+}
+
+// ContainerRuntimeCheck checks a container runtime is detected on node, and its cli tool is installed.
+type ContainerRuntimeCheck struct{}
+
+func (c ContainerRuntimeCheck) Name() string {
+	return "ContainerRuntime"
+}
+
+func (c ContainerRuntimeCheck) Check() (warnings, errorList []error) {
+	criSocket, err := components.DetectCRISocket()
+	if err != nil {
+		return nil, []error{err}
+	}
+	if _, err := components.NewContainerRuntimeForImage(utilsexec.New(), criSocket); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
+
+// StaticPodCheck checks the static pod manifests on node. Yurthub manifests other than
+// ManifestName conflict with the one to be installed; whether ManifestName exists is
+// reported as a warning, because it will be overwritten by convert or removed by revert.
+type StaticPodCheck struct {
+	ManifestPath string
+	ManifestName string
+	// Installing means the manifest is going to be installed rather than removed.
+	Installing bool
+}
+
+func (c StaticPodCheck) Name() string {
+	return "StaticPod"
+}
+
+func (c StaticPodCheck) Check() (warnings, errorList []error) {
+	entries, err := os.ReadDir(c.ManifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if !c.Installing {
+				warnings = append(warnings, fmt.Errorf("manifest %s doesn't exist, nothing to revert", c.ManifestName))
+			}
+			return warnings, nil
+		}
+		return nil, []error{err}
+	}
+
+	found := false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if entry.Name() == c.ManifestName {
+			found = true
+			continue
+		}
+		path := filepath.Join(c.ManifestPath, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errorList = append(errorList, err)
+			continue
+		}
+		pod := &corev1.Pod{}
+		if err := yaml.Unmarshal(content, pod); err != nil || pod.Kind != "Pod" {
+			// not a pod manifest
+			continue
+		}
+		if isYurthubPod(pod) {
+			errorList = append(errorList, fmt.Errorf("static pod %s/%s in %s conflicts with %s", pod.Namespace, pod.Name, path, c.ManifestName))
+		}
+	}
+
+	if found && c.Installing {
+		warnings = append(warnings, fmt.Errorf("manifest %s already exists and will be overwritten", c.ManifestName))
+	} else if !found && !c.Installing {
+		warnings = append(warnings, fmt.Errorf("manifest %s doesn't exist, nothing to revert", c.ManifestName))
+	}
+	return warnings, errorList
+}
+
+func isYurthubPod(pod *corev1.Pod) bool {
+	// the yurthub static pod is named as yurt-hub or yurt-hub-cloud
+	return pod.Name == constants.YurthubYurtStaticSetName || strings.HasPrefix(pod.Name, constants.YurthubYurtStaticSetName+"-")
+}
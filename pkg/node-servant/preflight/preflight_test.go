@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const yurthubPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: yurt-hub
+  namespace: kube-system
+`
+
+func TestKubeletVersionCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{name: "newer kubelet", output: "Kubernetes v1.22.7\n"},
+		{name: "same kubelet", output: "Kubernetes v1.18.0"},
+		{name: "older kubelet", output: "Kubernetes v1.16.2", wantErr: true},
+		{name: "invalid version", output: "unknown", wantErr: true},
+		{name: "empty version", output: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := KubeletVersionCheck{
+				MinVersion:  DefaultMinKubeletVersion,
+				versionFunc: func() (string, error) { return tt.output, nil },
+			}
+			if _, errs := c.Check(); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("expect error %v, but got %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestStaticPodCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		installing   bool
+		wantWarnings int
+		wantErrors   int
+	}{
+		{
+			name:       "no manifest to install",
+			files:      map[string]string{"etcd.yaml": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: etcd\n"},
+			installing: true,
+		},
+		{
+			name:         "manifest to install exists",
+			files:        map[string]string{"yurthub.yaml": yurthubPod},
+			installing:   true,
+			wantWarnings: 1,
+		},
+		{
+			name:       "yurthub in another manifest",
+			files:      map[string]string{"yurt-hub.yaml": yurthubPod, "invalid.yaml": "{"},
+			installing: true,
+			wantErrors: 1,
+		},
+		{
+			name:         "no manifest to revert",
+			wantWarnings: 1,
+		},
+		{
+			name:  "manifest to revert exists",
+			files: map[string]string{"yurthub.yaml": yurthubPod},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c := StaticPodCheck{ManifestPath: dir, ManifestName: "yurthub.yaml", Installing: tt.installing}
+			warnings, errs := c.Check()
+			if len(warnings) != tt.wantWarnings || len(errs) != tt.wantErrors {
+				t.Errorf("expect %d warnings and %d errors, but got %v and %v", tt.wantWarnings, tt.wantErrors, warnings, errs)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	report := Run("node1",
+		DiskSpaceCheck{Path: dir},
+		StaticPodCheck{ManifestPath: dir, ManifestName: "yurthub.yaml", Installing: true})
+	if !report.Ready() {
+		t.Errorf("expect node ready, but got\n%s", report)
+	}
+	if !strings.Contains(report.String(), "node node1 is ready") {
+		t.Errorf("expect verdict in report, but got\n%s", report)
+	}
+
+	report = Run("node1", DiskSpaceCheck{Path: dir, MinBytes: math.MaxUint64})
+	if report.Ready() {
+		t.Errorf("expect node not ready, but got\n%s", report)
+	}
+	if s := report.String(); !strings.Contains(s, "[FAIL] DiskSpace") || !strings.Contains(s, "node node1 is not ready") {
+		t.Errorf("expect failed disk space check in report, but got\n%s", s)
+	}
+}
+
+func TestIgnoreErrors(t *testing.T) {
+	dir := t.TempDir()
+	report := Run("node1", DiskSpaceCheck{Path: dir, MinBytes: math.MaxUint64})
+	report.IgnoreErrors([]string{"Swap"})
+	if report.Ready() {
+		t.Errorf("expect node not ready, but got\n%s", report)
+	}
+
+	report.IgnoreErrors([]string{"diskspace"})
+	if !report.Ready() {
+		t.Errorf("expect node ready, but got\n%s", report)
+	}
+	if s := report.String(); !strings.Contains(s, "[WARN] DiskSpace") {
+		t.Errorf("expect ignored error shown as warning, but got\n%s", s)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+	utilsexec "k8s.io/utils/exec"
+
+	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+)
+
+// Options has the information that required by prepull operation
+type Options struct {
+	images    []string
+	criSocket string
+}
+
+// NewPrePullOptions creates a new Options
+func NewPrePullOptions() *Options {
+	return &Options{}
+}
+
+// AddFlags sets flags.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.images, "images", o.images, "The images which are pulled to the node, separated by comma.")
+	fs.StringVar(&o.criSocket, "cri-socket", o.criSocket, "The CRI socket used to pull images, it's detected if not specified.")
+}
+
+// Validate validates Options
+func (o *Options) Validate() error {
+	if len(o.images) == 0 {
+		return fmt.Errorf("images can not be empty")
+	}
+	return nil
+}
+
+// Run pulls the images which don't exist on node yet.
+func Run(o *Options) error {
+	criSocket := o.criSocket
+	if len(criSocket) == 0 {
+		socket, err := components.DetectCRISocket()
+		if err != nil {
+			return err
+		}
+		criSocket = socket
+	}
+	runtime, err := components.NewContainerRuntimeForImage(utilsexec.New(), criSocket)
+	if err != nil {
+		return err
+	}
+	return pullImages(runtime, o.images)
+}
+
+func pullImages(runtime components.ContainerRuntimeForImage, images []string) error {
+	for _, image := range images {
+		exist, err := runtime.ImageExists(image)
+		if err != nil {
+			return err
+		}
+		if exist {
+			klog.Infof("image %s already exists, skip pulling", image)
+			continue
+		}
+		klog.Infof("pulling image %s", image)
+		if err := runtime.PullImage(image); err != nil {
+			return fmt.Errorf("fail to pull image %s, %v", image, err)
+		}
+	}
+	return nil
+}
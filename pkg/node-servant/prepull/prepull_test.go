@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeRuntime struct {
+	images map[string]bool
+	pulled []string
+	err    error
+}
+
+func (r *fakeRuntime) IsDocker() bool {
+	return false
+}
+
+func (r *fakeRuntime) PullImage(image string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.pulled = append(r.pulled, image)
+	return nil
+}
+
+func (r *fakeRuntime) ImageExists(image string) (bool, error) {
+	return r.images[image], nil
+}
+
+func (r *fakeRuntime) LoadImage(archive string) error {
+	return r.err
+}
+
+func TestPullImages(t *testing.T) {
+	runtime := &fakeRuntime{images: map[string]bool{"busybox": true}}
+	if err := pullImages(runtime, []string{"busybox", "nginx"}); err != nil {
+		t.Fatalf("fail to pull images, %v", err)
+	}
+	if expect := []string{"nginx"}; !reflect.DeepEqual(runtime.pulled, expect) {
+		t.Errorf("expect images %v pulled, but got %v", expect, runtime.pulled)
+	}
+
+	runtime = &fakeRuntime{err: errors.New("network is unreachable")}
+	if err := pullImages(runtime, []string{"nginx"}); err == nil {
+		t.Errorf("expect error when image fails to be pulled")
+	}
+}
@@ -30,6 +30,7 @@ type Options struct {
 	kubeadmConfPath string
 	openyurtDir     string
 	nodeName        string
+	dryRun          bool
 }
 
 // NewRevertOptions creates a new Options
@@ -64,5 +65,14 @@ func (o *Options) Complete(flags *pflag.FlagSet) error {
 	}
 	o.openyurtDir = openyurtDir
 
+	if o.dryRun, err = flags.GetBool("dry-run"); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// DryRun returns whether only the preflight checks are run.
+func (o *Options) DryRun() bool {
+	return o.dryRun
+}
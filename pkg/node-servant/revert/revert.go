@@ -20,6 +20,9 @@ import (
 	"time"
 
 	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	enutil "github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
 
@@ -55,6 +58,15 @@ func (n *nodeReverter) Do() error {
 	return nil
 }
 
+// Preflight checks whether the node is ready to be reverted without making any changes.
+func (n *nodeReverter) Preflight() *preflight.Report {
+	return preflight.Run(n.nodeName,
+		preflight.StaticPodCheck{
+			ManifestPath: enutil.GetPodManifestPath(),
+			ManifestName: constants.YurthubYamlName,
+		})
+}
+
 func (n *nodeReverter) revertKubelet() error {
 	op := components.NewKubeletOperator(n.openyurtDir)
 	return op.UndoRedirectTrafficToYurtHub()
@@ -17,6 +17,7 @@ limitations under the License.
 package upgrade
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -32,8 +33,17 @@ var (
 	DefaultConfigmapPath = "/data"
 	DefaultManifestPath  = "/etc/kubernetes/manifests"
 	DefaultUpgradePath   = "/tmp/manifests"
+	DefaultPublicKeyPath = util.DefaultManifestPublicKeyPath
+
+	// ErrRolledBack means the latest static pod failed to be ready and the previous manifest is restored
+	ErrRolledBack = errors.New("static pod is rolled back to the previous manifest")
 )
 
+// RolledBackTerminationMessage is written to the termination message of the upgrade worker pod
+// when the static pod is rolled back, so that the YurtStaticSet controller can tell a rollback
+// from other failures.
+const RolledBackTerminationMessage = "RolledBack"
+
 type Controller struct {
 	// Name of static pod
 	name string
@@ -47,6 +57,12 @@ type Controller struct {
 	upgradeMode string
 	// Timeout for upgrade success check
 	timeout time.Duration
+	// Values of the node to render the placeholders in manifest
+	templateValues *util.TemplateValues
+	// Signature of the latest manifest, it's verified if the public key exists on node
+	signature string
+	// The checks which the upgraded static pod must pass after it's running
+	healthGate *appsv1alpha1.YurtStaticSetHealthGate
 
 	// Manifest path of static pod, default `/etc/kubernetes/manifests/manifestName.yaml`
 	manifestPath string
@@ -56,13 +72,34 @@ type Controller struct {
 	configMapDataPath string
 	// The latest manifest path, default `/etc/kubernetes/manifests/openyurtio-upgrade/manifestName.upgrade`
 	upgradeManifestPath string
+
+	// The static pods bundled with this one, they are upgraded and rolled back together
+	bundle []*Controller
 }
 
 func NewWithOptions(o *Options) (*Controller, error) {
 	ctrl := New(o.name, o.namespace, o.manifest, o.mode)
 	ctrl.hash = o.hash
 	ctrl.timeout = o.timeout
-	return ctrl, nil
+
+	values, err := util.TemplateValuesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	ctrl.templateValues = values
+	ctrl.signature = os.Getenv(util.ManifestSignatureEnv)
+
+	gate, err := util.HealthGateFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	ctrl.healthGate = gate
+
+	bundle, nodeName, err := util.BundleFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.WithBundle(bundle, nodeName), nil
 }
 
 func New(name, namespace, manifest, mode string) *Controller {
@@ -80,6 +117,35 @@ func New(name, namespace, manifest, mode string) *Controller {
 	return ctrl
 }
 
+// WithBundle adds the static pods bundled with this one, the name of their pods on node is suffixed with nodeName.
+func (ctrl *Controller) WithBundle(bundle []util.BundledManifest, nodeName string) *Controller {
+	for _, m := range bundle {
+		member := New(m.Name+"-"+nodeName, ctrl.namespace, m.Manifest, ctrl.upgradeMode)
+		member.signature = m.Signature
+		ctrl.bundle = append(ctrl.bundle, member)
+	}
+	return ctrl
+}
+
+// members returns this static pod and the ones bundled with it
+func (ctrl *Controller) members() []*Controller {
+	return append([]*Controller{ctrl}, ctrl.bundle...)
+}
+
+// WithVerification sets the latest static pod hash and the window in which the upgraded static pod
+// must be ready, it's used by the callers who verify the upgrade outside Upgrade.
+func (ctrl *Controller) WithVerification(hash string, timeout time.Duration) *Controller {
+	ctrl.hash = hash
+	ctrl.timeout = timeout
+	return ctrl
+}
+
+// WithHealthGate sets the checks which the upgraded static pod must pass before the upgrade succeeds.
+func (ctrl *Controller) WithHealthGate(gate *appsv1alpha1.YurtStaticSetHealthGate) *Controller {
+	ctrl.healthGate = gate
+	return ctrl
+}
+
 func (ctrl *Controller) Upgrade() error {
 	if err := ctrl.createUpgradeSpace(); err != nil {
 		return err
@@ -97,53 +163,73 @@ func (ctrl *Controller) Upgrade() error {
 	return nil
 }
 
+// AutoUpgrade upgrades the static pod and the ones bundled with it. All manifests are prepared before
+// any of them is replaced, so a bundle with an invalid manifest is not upgraded at all.
 func (ctrl *Controller) AutoUpgrade() error {
-	// (1) Prepare the latest manifest
-	if err := ctrl.prepareManifest(); err != nil {
-		return err
+	// (1) Prepare the latest manifests
+	for _, m := range ctrl.members() {
+		if err := m.prepareManifest(ctrl.templateValues); err != nil {
+			return err
+		}
 	}
 	klog.Info("Auto prepare upgrade manifest success")
 
-	// (2) Back up the old manifest in case of upgrade failure
-	if err := ctrl.backupManifest(); err != nil {
+	// (2) Back up the old manifests in case of upgrade failure
+	if err := ctrl.backupManifests(); err != nil {
 		return err
 	}
 	klog.Info("Auto upgrade backupManifest success")
 
-	// (3) Replace manifest and kubelet will upgrade the static pod automatically
-	if err := ctrl.replaceManifest(); err != nil {
+	// (3) Replace manifests and kubelet will upgrade the static pods automatically
+	if err := ctrl.replaceManifests(); err != nil {
 		return err
 	}
 	klog.Info("Auto upgrade replaceManifest success")
 
-	// (4) Verify the new static pod is running
-	ok, err := ctrl.verify()
-	if err != nil {
-		if err := ctrl.rollbackManifest(); err != nil {
-			klog.Errorf("Fail to rollback manifest when upgrade failed, %v", err)
-		}
+	// (4) Verify the new static pod is running and roll back if not
+	if err := ctrl.VerifyOrRollback(); err != nil {
 		return err
 	}
-	if !ok {
-		if err := ctrl.rollbackManifest(); err != nil {
-			klog.Errorf("Fail to rollback manifest when upgrade failed, %v", err)
-		}
-		return fmt.Errorf("the latest static pod is not running")
-	}
 	klog.Info("Auto upgrade verify success")
 
 	return nil
 }
 
+// VerifyOrRollback waits for the latest static pods to be ready within the timeout, and restores
+// the backup manifests of all bundled static pods if any of them failed, crash looped or was not
+// ready in time. An error wrapping ErrRolledBack is returned when the rollback succeeds.
+func (ctrl *Controller) VerifyOrRollback() error {
+	ok, err := ctrl.verify()
+	if err == nil && ok {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("the latest static pod is not running")
+	}
+
+	var rollbackErr error
+	for _, m := range ctrl.members() {
+		if e := m.rollbackManifest(); e != nil {
+			klog.Errorf("Fail to rollback manifest %s when upgrade failed, %v", m.manifest, e)
+			rollbackErr = e
+		}
+	}
+	if rollbackErr != nil {
+		return err
+	}
+	klog.Warningf("Static pod %s/%s is rolled back, %v", ctrl.namespace, ctrl.name, err)
+	return fmt.Errorf("%w, %v", ErrRolledBack, err)
+}
+
 func (ctrl *Controller) OTAUpgrade() error {
-	// (1) Back up the old manifest in case of upgrade failure
-	if err := ctrl.backupManifest(); err != nil {
+	// (1) Back up the old manifests in case of upgrade failure
+	if err := ctrl.backupManifests(); err != nil {
 		return err
 	}
 	klog.Info("OTA upgrade backupManifest success")
 
-	// (2) Replace manifest and kubelet will upgrade the static pod automatically
-	if err := ctrl.replaceManifest(); err != nil {
+	// (2) Replace manifests and kubelet will upgrade the static pods automatically
+	if err := ctrl.replaceManifests(); err != nil {
 		return err
 	}
 	klog.Info("OTA upgrade replaceManifest success")
@@ -162,13 +248,59 @@ func (ctrl *Controller) createUpgradeSpace() error {
 	return nil
 }
 
-// prepareManifest move the latest manifest to DefaultUpgradePath and set `.upgrade` suffix
-func (ctrl *Controller) prepareManifest() error {
-	return util.CopyFile(ctrl.configMapDataPath, ctrl.upgradeManifestPath)
+// prepareManifest verifies the signature of the latest manifest, renders it with the values of node,
+// and writes it to DefaultUpgradePath with `.upgrade` suffix
+func (ctrl *Controller) prepareManifest(values *util.TemplateValues) error {
+	data, err := os.ReadFile(ctrl.configMapDataPath)
+	if err != nil {
+		return err
+	}
+	key, err := util.LoadPublicKey(DefaultPublicKeyPath)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if err := util.VerifyManifest(key, data, ctrl.signature); err != nil {
+			return err
+		}
+		klog.Info("Verify signature of upgrade manifest success")
+	}
+	manifest, err := util.RenderManifest(string(data), values)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ctrl.upgradeManifestPath, []byte(manifest), 0666)
+}
+
+// backupManifests backs up the old manifests of this static pod and the bundled ones
+func (ctrl *Controller) backupManifests() error {
+	for _, m := range ctrl.members() {
+		if err := m.backupManifest(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceManifests replaces the manifests of this static pod and the bundled ones
+func (ctrl *Controller) replaceManifests() error {
+	for _, m := range ctrl.members() {
+		if err := m.replaceManifest(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// backUpManifest backup the old manifest in order to roll back when errors occur
+// backUpManifest backup the old manifest in order to roll back when errors occur.
+// A bundled static pod may not exist before upgrade, then no backup is left and it's removed in rollback.
 func (ctrl *Controller) backupManifest() error {
+	if _, err := os.Stat(ctrl.manifestPath); os.IsNotExist(err) {
+		if err := os.Remove(ctrl.bakManifestPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
 	return util.CopyFile(ctrl.manifestPath, ctrl.bakManifestPath)
 }
 
@@ -177,13 +309,34 @@ func (ctrl *Controller) replaceManifest() error {
 	return util.CopyFile(ctrl.upgradeManifestPath, ctrl.manifestPath)
 }
 
-// rollbackManifest replace new manifest with the backup
+// rollbackManifest replace new manifest with the backup, the manifest is removed if it has no backup
 func (ctrl *Controller) rollbackManifest() error {
+	if _, err := os.Stat(ctrl.bakManifestPath); os.IsNotExist(err) {
+		if err := os.Remove(ctrl.manifestPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
 	return util.CopyFile(ctrl.bakManifestPath, ctrl.manifestPath)
 }
 
-// verify make sure the latest static pod is running
-// return false when the latest static pod failed or check status time out
+// verify make sure the latest static pod and the bundled ones are running in the timeout, and the latest
+// static pod passes the health gate, whose min uptime extends the timeout
+// return false when any latest static pod failed or check status time out
 func (ctrl *Controller) verify() (bool, error) {
-	return util.WaitForPodRunning(ctrl.namespace, ctrl.name, ctrl.hash, ctrl.timeout)
+	deadline := time.Now().Add(ctrl.timeout)
+	for _, m := range ctrl.members() {
+		ok, err := util.WaitForPodRunning(m.namespace, m.name, ctrl.hash, time.Until(deadline))
+		if err != nil || !ok {
+			return ok, err
+		}
+	}
+
+	if ctrl.healthGate == nil {
+		return true, nil
+	}
+	if ctrl.healthGate.MinUptime != nil {
+		deadline = deadline.Add(ctrl.healthGate.MinUptime.Duration)
+	}
+	return util.WaitForHealthGate(ctrl.namespace, ctrl.name, ctrl.hash, ctrl.healthGate, time.Until(deadline))
 }
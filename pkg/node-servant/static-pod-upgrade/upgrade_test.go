@@ -107,3 +107,49 @@ func Test(t *testing.T) {
 		}
 	}
 }
+
+func TestBundleRollback(t *testing.T) {
+	DefaultManifestPath = t.TempDir()
+	DefaultConfigmapPath = t.TempDir()
+	DefaultUpgradePath = t.TempDir()
+	DefaultPublicKeyPath = filepath.Join(t.TempDir(), "not-exist.pub")
+	if err := os.WriteFile(filepath.Join(DefaultManifestPath, upgradeUtil.WithYamlSuffix(TestManifest)), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, manifest := range []string{TestManifest, "mirror"} {
+		if err := os.WriteFile(filepath.Join(DefaultConfigmapPath, manifest), []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctrl := New(TestPodName, metav1.NamespaceDefault, TestManifest, "AdvancedRollingUpdate").
+		WithBundle([]upgradeUtil.BundledManifest{{Manifest: "mirror", Name: "mirror"}}, "node")
+	if name := ctrl.bundle[0].name; name != "mirror-node" {
+		t.Errorf("Expect bundled static pod mirror-node, but got %s", name)
+	}
+
+	for _, m := range ctrl.members() {
+		if err := m.prepareManifest(nil); err != nil {
+			t.Fatalf("Fail to prepare manifest, %v", err)
+		}
+	}
+	if err := ctrl.backupManifests(); err != nil {
+		t.Fatalf("Fail to backup manifests, %v", err)
+	}
+	if err := ctrl.replaceManifests(); err != nil {
+		t.Fatalf("Fail to replace manifests, %v", err)
+	}
+	for _, m := range ctrl.members() {
+		if err := m.rollbackManifest(); err != nil {
+			t.Fatalf("Fail to rollback manifest, %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(DefaultManifestPath, upgradeUtil.WithYamlSuffix(TestManifest)))
+	if err != nil || string(content) != "old" {
+		t.Errorf("Expect the main manifest restored, but got %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(DefaultManifestPath, upgradeUtil.WithYamlSuffix("mirror"))); !os.IsNotExist(err) {
+		t.Errorf("Expect the new bundled manifest removed, but got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	// BundleEnv is the environment variable of upgrade worker which contains the bundled manifests in json.
+	BundleEnv = "STATIC_POD_BUNDLE"
+
+	// NodeNameEnv is the environment variable of upgrade worker which contains the name of its node.
+	NodeNameEnv = "NODE_NAME"
+)
+
+// BundledManifest is a static pod manifest which is upgraded together with the main manifest of YurtStaticSet.
+type BundledManifest struct {
+	// Manifest is the file name of the manifest.
+	Manifest string `json:"manifest"`
+	// Name is the name of static pod in the manifest, the pod on node is named with the node name as suffix.
+	Name string `json:"name"`
+	// Signature is the signature of the manifest, it's verified if the public key exists on node.
+	Signature string `json:"signature,omitempty"`
+}
+
+// EncodeBundle encodes the bundled manifests in json, an empty string is returned if there is no bundled manifest.
+func EncodeBundle(bundle []BundledManifest) (string, error) {
+	if len(bundle) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecodeBundle decodes the bundled manifests from json, nil is returned if data is empty.
+func DecodeBundle(data string) ([]BundledManifest, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var bundle []BundledManifest
+	if err := json.Unmarshal([]byte(data), &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundled manifests, %v", err)
+	}
+	return bundle, nil
+}
+
+// BundleFromEnv returns the bundled manifests in BundleEnv and the node name in NodeNameEnv.
+func BundleFromEnv() ([]BundledManifest, string, error) {
+	bundle, err := DecodeBundle(os.Getenv(BundleEnv))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid env %s, %v", BundleEnv, err)
+	}
+	nodeName := os.Getenv(NodeNameEnv)
+	if len(bundle) != 0 && len(nodeName) == 0 {
+		return nil, "", fmt.Errorf("env %s is required for bundled manifests", NodeNameEnv)
+	}
+	return bundle, nodeName, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBundleFromEnv(t *testing.T) {
+	bundle := []BundledManifest{{Manifest: "mirror", Name: "yurt-hub-mirror", Signature: "sig"}}
+	data, err := EncodeBundle(bundle)
+	if err != nil {
+		t.Fatalf("Fail to encode bundle, %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		bundle   string
+		nodeName string
+		want     []BundledManifest
+		wantErr  bool
+	}{
+		{name: "no bundle"},
+		{name: "bundle with node name", bundle: data, nodeName: "node1", want: bundle},
+		{name: "bundle without node name", bundle: data, wantErr: true},
+		{name: "invalid bundle", bundle: "{", nodeName: "node1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(BundleEnv, tt.bundle)
+			t.Setenv(NodeNameEnv, tt.nodeName)
+			got, nodeName, err := BundleFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect error %v, but got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expect bundle %v, but got %v", tt.want, got)
+			}
+			if !tt.wantErr && len(tt.want) != 0 && nodeName != tt.nodeName {
+				t.Errorf("expect node %s, but got %s", tt.nodeName, nodeName)
+			}
+		})
+	}
+
+	if data, _ := EncodeBundle(nil); data != "" {
+		t.Errorf("expect empty bundle encoded to empty string, but got %q", data)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// HealthGateEnv is the environment variable of upgrade worker which contains the health gate in json.
+const HealthGateEnv = "STATIC_POD_HEALTH_GATE"
+
+var (
+	// HealthCheckInterval is the interval of health gate checks.
+	HealthCheckInterval = 5 * time.Second
+
+	healthCheckClient = &http.Client{Timeout: 5 * time.Second}
+)
+
+// EncodeHealthGate encodes the health gate in json, an empty string is returned if gate is nil.
+func EncodeHealthGate(gate *appsv1alpha1.YurtStaticSetHealthGate) (string, error) {
+	if gate == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(gate)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DecodeHealthGate decodes the health gate in json, nil is returned if data is empty.
+func DecodeHealthGate(data string) (*appsv1alpha1.YurtStaticSetHealthGate, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	gate := &appsv1alpha1.YurtStaticSetHealthGate{}
+	if err := json.Unmarshal([]byte(data), gate); err != nil {
+		return nil, fmt.Errorf("invalid health gate %q, %v", data, err)
+	}
+	return gate, nil
+}
+
+// HealthGateFromEnv returns the health gate in HealthGateEnv, nil is returned if it's not set.
+func HealthGateFromEnv() (*appsv1alpha1.YurtStaticSetHealthGate, error) {
+	return DecodeHealthGate(os.Getenv(HealthGateEnv))
+}
+
+// healthGateState tracks the checks of the upgraded static pod against the health gate.
+type healthGateState struct {
+	gate       *appsv1alpha1.YurtStaticSetHealthGate
+	passes     int32
+	readySince time.Time
+	restarts   map[string]int32
+}
+
+func newHealthGateState(gate *appsv1alpha1.YurtStaticSetHealthGate) *healthGateState {
+	return &healthGateState{gate: gate}
+}
+
+// observe records a check of pod at now, it returns whether the readiness and uptime requirements are met,
+// and an error if the pod failed, crash looped or restarted its containers after it was ready.
+func (s *healthGateState) observe(pod *v1.Pod, hash string, now time.Time) (bool, error) {
+	hasResult, ready := CheckPodUpgraded(pod, hash)
+	if hasResult && !ready {
+		return false, fmt.Errorf("static pod %s/%s failed", pod.Namespace, pod.Name)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if restarts, ok := s.restarts[cs.Name]; ok && cs.RestartCount > restarts {
+			return false, fmt.Errorf("container %s of static pod %s/%s restarted", cs.Name, pod.Namespace, pod.Name)
+		}
+	}
+	if !ready {
+		s.passes = 0
+		s.readySince = time.Time{}
+		return false, nil
+	}
+
+	if s.readySince.IsZero() {
+		s.readySince = now
+		s.restarts = make(map[string]int32)
+		for _, cs := range pod.Status.ContainerStatuses {
+			s.restarts[cs.Name] = cs.RestartCount
+		}
+	}
+	s.passes++
+
+	passCount := s.gate.ReadinessPassCount
+	if passCount < 1 {
+		passCount = 1
+	}
+	if s.passes < passCount {
+		return false, nil
+	}
+	if s.gate.MinUptime != nil && now.Sub(s.readySince) < s.gate.MinUptime.Duration {
+		return false, nil
+	}
+	return true, nil
+}
+
+// CheckHTTP performs the HTTP check of health gate on node, it passes if the response code is in [200, 400).
+func CheckHTTP(action *v1.HTTPGetAction) error {
+	host := action.Host
+	if len(host) == 0 {
+		host = "127.0.0.1"
+	}
+	scheme := strings.ToLower(string(action.Scheme))
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(host, strconv.Itoa(action.Port.IntValue())),
+		Path:   action.Path,
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	resp, err := healthCheckClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("http check %s returned status %s", u.String(), resp.Status)
+	}
+	return nil
+}
+
+// WaitForHealthGate waits for the upgraded static pod to pass the health gate within the timeout.
+// It returns false if the static pod failed, restarted its containers after it was ready, or didn't
+// pass the health gate in time.
+func WaitForHealthGate(namespace, name, hash string, gate *appsv1alpha1.YurtStaticSetHealthGate, timeout time.Duration) (bool, error) {
+	klog.Infof("WaitForHealthGate namespace is %s, name is %s", namespace, name)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+
+	state := newHealthGateState(gate)
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return false, fmt.Errorf("timeout waiting for static pod %s/%s to pass health gate, %v", namespace, name, lastErr)
+			}
+			return false, fmt.Errorf("timeout waiting for static pod %s/%s to pass health gate", namespace, name)
+		case <-ticker.C:
+			pod, err := GetPodFromYurtHub(namespace, name)
+			if err != nil {
+				klog.V(4).Infof("Temporarily fail to get pod from YurtHub, %v", err)
+				continue
+			}
+			passed, err := state.observe(pod, hash, time.Now())
+			if err != nil {
+				klog.Warningf("Static pod %s/%s failed health gate, %v", namespace, name, err)
+				return false, nil
+			}
+			if !passed {
+				continue
+			}
+			if gate.HTTPGet != nil {
+				if lastErr = CheckHTTP(gate.HTTPGet); lastErr != nil {
+					klog.V(4).Infof("Static pod %s/%s temporarily fails http check, %v", namespace, name, lastErr)
+					continue
+				}
+			}
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func newGatedPod(ready bool, restarts int32) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{StaticPodHashAnnotation: "hash"}},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			ContainerStatuses: []v1.ContainerStatus{{Name: "nginx", RestartCount: restarts}},
+		},
+	}
+}
+
+func TestHealthGateState(t *testing.T) {
+	gate := &appsv1alpha1.YurtStaticSetHealthGate{
+		ReadinessPassCount: 2,
+		MinUptime:          &metav1.Duration{Duration: time.Minute},
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		pods    []*v1.Pod
+		offsets []time.Duration
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "passes after enough checks and uptime",
+			pods:    []*v1.Pod{newGatedPod(true, 0), newGatedPod(true, 0), newGatedPod(true, 0)},
+			offsets: []time.Duration{0, 5 * time.Second, time.Minute},
+			want:    true,
+		},
+		{
+			name:    "uptime is not enough",
+			pods:    []*v1.Pod{newGatedPod(true, 0), newGatedPod(true, 0)},
+			offsets: []time.Duration{0, 5 * time.Second},
+		},
+		{
+			name:    "not ready resets the checks",
+			pods:    []*v1.Pod{newGatedPod(true, 0), newGatedPod(false, 0), newGatedPod(true, 0), newGatedPod(true, 0)},
+			offsets: []time.Duration{0, time.Minute, 2 * time.Minute, 2*time.Minute + 5*time.Second},
+		},
+		{
+			name:    "container restarts after ready",
+			pods:    []*v1.Pod{newGatedPod(true, 0), newGatedPod(true, 1)},
+			offsets: []time.Duration{0, time.Minute},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newHealthGateState(gate)
+			var got bool
+			var err error
+			for i, pod := range tt.pods {
+				if got, err = state.observe(pod, "hash", now.Add(tt.offsets[i])); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect error %v, but got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expect passed %v, but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	if err := CheckHTTP(&v1.HTTPGetAction{Host: host, Port: intstr.FromInt(p), Path: "/healthz"}); err != nil {
+		t.Errorf("expect http check passed, but got %v", err)
+	}
+	if err := CheckHTTP(&v1.HTTPGetAction{Host: host, Port: intstr.FromInt(p), Path: "/readyz"}); err == nil {
+		t.Errorf("expect http check failed")
+	}
+}
+
+func TestEncodeHealthGate(t *testing.T) {
+	gate := &appsv1alpha1.YurtStaticSetHealthGate{ReadinessPassCount: 3, MinUptime: &metav1.Duration{Duration: time.Minute}}
+	data, err := EncodeHealthGate(gate)
+	if err != nil {
+		t.Fatalf("fail to encode health gate, %v", err)
+	}
+	got, err := DecodeHealthGate(data)
+	if err != nil {
+		t.Fatalf("fail to decode health gate, %v", err)
+	}
+	if !reflect.DeepEqual(got, gate) {
+		t.Errorf("expect health gate %v, but got %v", gate, got)
+	}
+
+	if data, _ := EncodeHealthGate(nil); data != "" {
+		t.Errorf("expect empty health gate encoded to empty string, but got %q", data)
+	}
+	if _, err := DecodeHealthGate("{"); err == nil {
+		t.Errorf("expect error of invalid health gate")
+	}
+}
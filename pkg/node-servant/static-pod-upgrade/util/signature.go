@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultManifestPublicKeyPath is the public key on node to verify the signature of static pod manifest,
+	// the manifest is verified before it replaces the running one only if the public key exists.
+	DefaultManifestPublicKeyPath = "/etc/openyurt/pki/static-pod-manifest.pub"
+
+	// ManifestSignatureEnv is the environment variable of upgrade worker which contains the manifest signature.
+	ManifestSignatureEnv = "STATIC_POD_MANIFEST_SIGNATURE"
+)
+
+// ErrInvalidSignature means the manifest is not signed or its signature doesn't match the public key.
+var ErrInvalidSignature = errors.New("invalid signature of static pod manifest")
+
+// LoadPublicKey loads the PEM encoded public key, a nil key is returned if the file doesn't exist.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data is found in public key %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse public key %s, %v", path, err)
+	}
+	return key, nil
+}
+
+// VerifyManifest verifies the base64 encoded detached signature of manifest against the public key.
+// ECDSA (ASN.1 encoded, like the signatures of `cosign sign-blob`) and RSA PKCS #1 v1.5 signatures
+// are over the SHA-256 digest of manifest, and Ed25519 signatures are over the manifest itself.
+func VerifyManifest(key crypto.PublicKey, manifest []byte, signature string) error {
+	if len(signature) == 0 {
+		return fmt.Errorf("%w, signature is empty", ErrInvalidSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("%w, %v", ErrInvalidSignature, err)
+	}
+
+	digest := sha256.Sum256(manifest)
+	var verified bool
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		verified = ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		verified = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		verified = ed25519.Verify(k, manifest, sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	if !verified {
+		return ErrInvalidSignature
+	}
+	return nil
+}
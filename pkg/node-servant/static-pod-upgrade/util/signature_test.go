@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal public key, %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "static-pod-manifest.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatalf("failed to write public key, %v", err)
+	}
+	return path
+}
+
+func TestVerifyManifest(t *testing.T) {
+	manifest := []byte("apiVersion: v1\nkind: Pod\n")
+	digest := sha256.Sum256(manifest)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSig, _ := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaSig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edSig := ed25519.Sign(edKey, manifest)
+
+	testcases := map[string]struct {
+		key       crypto.PublicKey
+		manifest  []byte
+		signature string
+		valid     bool
+	}{
+		"ecdsa": {
+			key:       &ecKey.PublicKey,
+			manifest:  manifest,
+			signature: base64.StdEncoding.EncodeToString(ecSig),
+			valid:     true,
+		},
+		"rsa": {
+			key:       &rsaKey.PublicKey,
+			manifest:  manifest,
+			signature: base64.StdEncoding.EncodeToString(rsaSig),
+			valid:     true,
+		},
+		"ed25519": {
+			key:       edPub,
+			manifest:  manifest,
+			signature: base64.StdEncoding.EncodeToString(edSig),
+			valid:     true,
+		},
+		"tampered manifest": {
+			key:       &ecKey.PublicKey,
+			manifest:  []byte("apiVersion: v1\nkind: Pod\nspec: {}\n"),
+			signature: base64.StdEncoding.EncodeToString(ecSig),
+		},
+		"signed by another key": {
+			key:       edPub,
+			manifest:  manifest,
+			signature: base64.StdEncoding.EncodeToString(ecSig),
+		},
+		"not signed": {
+			key:      &ecKey.PublicKey,
+			manifest: manifest,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			key, err := LoadPublicKey(writePublicKey(t, tc.key))
+			if err != nil {
+				t.Fatalf("failed to load public key, %v", err)
+			}
+			err = VerifyManifest(key, tc.manifest, tc.signature)
+			if tc.valid && err != nil {
+				t.Errorf("expect valid signature, but got %v", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("expect invalid signature, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPublicKeyNotExist(t *testing.T) {
+	key, err := LoadPublicKey(filepath.Join(t.TempDir(), "not-exist.pub"))
+	if err != nil || key != nil {
+		t.Errorf("expect no public key, but got %v, %v", key, err)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+// TemplateValuesEnv is the environment variable of upgrade worker which contains the template values
+// of its node in json.
+const TemplateValuesEnv = "STATIC_POD_TEMPLATE_VALUES"
+
+// placeholderRegexp matches {{NODE_NAME}}, {{NODE_IP}}, {{POOL_NAME}} and {{NODE_LABEL:<key>}},
+// other text in braces is kept as it is.
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*(NODE_NAME|NODE_IP|POOL_NAME|NODE_LABEL:([^{}\s]+))\s*\}\}`)
+
+// TemplateValues are the values of a node used to render the placeholders in static pod manifest.
+type TemplateValues struct {
+	NodeName string            `json:"nodeName,omitempty"`
+	NodeIP   string            `json:"nodeIP,omitempty"`
+	PoolName string            `json:"poolName,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// TemplateValuesFromNode returns the template values of node, the node ip is the first internal ip
+// or the first address if the node has no internal ip.
+func TemplateValuesFromNode(node *v1.Node) *TemplateValues {
+	values := &TemplateValues{
+		NodeName: node.Name,
+		PoolName: node.Labels[apps.NodePoolLabel],
+		Labels:   node.Labels,
+	}
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			values.NodeIP = addr.Address
+			break
+		}
+	}
+	if len(values.NodeIP) == 0 && len(node.Status.Addresses) != 0 {
+		values.NodeIP = node.Status.Addresses[0].Address
+	}
+	return values
+}
+
+// TemplateValuesFromEnv returns the template values in TemplateValuesEnv, nil is returned if it's not set.
+func TemplateValuesFromEnv() (*TemplateValues, error) {
+	data := os.Getenv(TemplateValuesEnv)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	values := &TemplateValues{}
+	if err := json.Unmarshal([]byte(data), values); err != nil {
+		return nil, fmt.Errorf("invalid env %s, %v", TemplateValuesEnv, err)
+	}
+	return values, nil
+}
+
+// HasPlaceholders checks whether the manifest contains any placeholder.
+func HasPlaceholders(manifest string) bool {
+	return placeholderRegexp.MatchString(manifest)
+}
+
+// RenderManifest replaces the placeholders in manifest with the template values, an error is returned
+// if the manifest contains placeholders but no values are given.
+func RenderManifest(manifest string, values *TemplateValues) (string, error) {
+	if !HasPlaceholders(manifest) {
+		return manifest, nil
+	}
+	if values == nil {
+		return "", fmt.Errorf("manifest contains placeholders, but no template values are given")
+	}
+
+	return placeholderRegexp.ReplaceAllStringFunc(manifest, func(placeholder string) string {
+		match := placeholderRegexp.FindStringSubmatch(placeholder)
+		switch match[1] {
+		case "NODE_NAME":
+			return values.NodeName
+		case "NODE_IP":
+			return values.NodeIP
+		case "POOL_NAME":
+			return values.PoolName
+		default:
+			return values.Labels[match[2]]
+		}
+	}), nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+func TestRenderManifest(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "edge-1",
+			Labels: map[string]string{apps.NodePoolLabel: "hangzhou", "topology.kubernetes.io/zone": "zone-a"},
+		},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeHostName, Address: "edge-1"},
+			{Type: v1.NodeInternalIP, Address: "192.168.0.10"},
+		}},
+	}
+	values := TemplateValuesFromNode(node)
+
+	testcases := map[string]struct {
+		manifest string
+		values   *TemplateValues
+		expect   string
+		wantErr  bool
+	}{
+		"no placeholders": {
+			manifest: "- --format={{.ID}}",
+			expect:   "- --format={{.ID}}",
+		},
+		"render node values": {
+			manifest: "- --hostname={{NODE_NAME}}\n- --bind-address={{ NODE_IP }}\n- --pool={{POOL_NAME}}",
+			values:   values,
+			expect:   "- --hostname=edge-1\n- --bind-address=192.168.0.10\n- --pool=hangzhou",
+		},
+		"render node labels": {
+			manifest: "- --zone={{NODE_LABEL:topology.kubernetes.io/zone}}\n- --rack={{NODE_LABEL:rack}}",
+			values:   values,
+			expect:   "- --zone=zone-a\n- --rack=",
+		},
+		"no template values": {
+			manifest: "- --hostname={{NODE_NAME}}",
+			wantErr:  true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got, err := RenderManifest(tc.manifest, tc.values)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("RenderManifest() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.expect {
+				t.Errorf("expect manifest %q, but got %q", tc.expect, got)
+			}
+		})
+	}
+}
@@ -33,6 +33,9 @@ const (
 	UpgradeSuffix string = ".upgrade"
 
 	StaticPodHashAnnotation = "openyurt.io/static-pod-hash"
+
+	// CrashLoopBackOffReason is the waiting reason of containers restarted repeatedly by kubelet
+	CrashLoopBackOffReason = "CrashLoopBackOff"
 )
 
 func WithYamlSuffix(path string) string {
@@ -68,9 +71,9 @@ func CopyFile(src, dst string) error {
 	return nil
 }
 
-// WaitForPodRunning waits static pod to run
-// Success: Static pod annotation `StaticPodHashAnnotation` value equals to function argument hash
-// Failed: Receive PodFailed event
+// WaitForPodRunning waits static pod to run and be ready
+// Success: Static pod annotation `StaticPodHashAnnotation` value equals to function argument hash and pod is ready
+// Failed: Receive PodFailed event or the latest static pod is crash looping
 func WaitForPodRunning(namespace, name, hash string, timeout time.Duration) (bool, error) {
 	klog.Infof("WaitForPodRunning namespace is %s, name is %s", namespace, name)
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -79,19 +82,6 @@ func WaitForPodRunning(namespace, name, hash string, timeout time.Duration) (boo
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
-	checkPod := func(pod *v1.Pod) (hasResult, result bool) {
-		h := pod.Annotations[StaticPodHashAnnotation]
-		if pod.Status.Phase == v1.PodRunning && h == hash {
-			return true, true
-		}
-
-		if pod.Status.Phase == v1.PodFailed {
-			return true, false
-		}
-
-		return false, false
-	}
-
 	for {
 		select {
 		case <-ctx.Done():
@@ -102,7 +92,7 @@ func WaitForPodRunning(namespace, name, hash string, timeout time.Duration) (boo
 				klog.V(4).Infof("Temporarily fail to get pod from YurtHub, %v", err)
 			}
 			if pod != nil {
-				hasResult, result := checkPod(pod)
+				hasResult, result := CheckPodUpgraded(pod, hash)
 				if hasResult {
 					return result, nil
 				}
@@ -110,3 +100,31 @@ func WaitForPodRunning(namespace, name, hash string, timeout time.Duration) (boo
 		}
 	}
 }
+
+// CheckPodUpgraded checks whether the static pod with the latest hash is running and ready,
+// hasResult is false when the static pod is still being upgraded.
+func CheckPodUpgraded(pod *v1.Pod, hash string) (hasResult, result bool) {
+	if pod.Status.Phase == v1.PodFailed {
+		return true, false
+	}
+	if pod.Annotations[StaticPodHashAnnotation] != hash {
+		return false, false
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == CrashLoopBackOffReason {
+			return true, false
+		}
+	}
+
+	if pod.Status.Phase != v1.PodRunning {
+		return false, false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue, cond.Status == v1.ConditionTrue
+		}
+	}
+	// pod reported by kubelet without conditions is regarded as ready once it is running
+	return true, true
+}
@@ -22,6 +22,7 @@ import (
 	"crypto/x509/pkix"
 	"fmt"
 	"net"
+	"net/url"
 
 	certificatesv1 "k8s.io/api/certificates/v1"
 	"k8s.io/client-go/kubernetes"
@@ -34,6 +35,7 @@ import (
 
 type IPGetter func() ([]net.IP, error)
 type DNSGetter func() ([]string, error)
+type URIsGetter func() ([]*url.URL, error)
 
 // CertManagerConfig specifies the attributes of the created CertManager
 type CertManagerConfig struct {
@@ -61,6 +63,14 @@ type CertManagerConfig struct {
 	// IPGetter can get ips at runtime. If no error returned when getting ips,
 	// these ips will be used in the cert instead of the IPs.
 	IPGetter
+	// URIs contain a list of URI SANs of cert, such as SPIFFE identities of this component.
+	// Note:
+	// If URIsGetter is set and it can get uris with no error returned,
+	// URIs will be ignored and what got from URIsGetter will be used.
+	URIs []*url.URL
+	// URIsGetter can get uris at runtime. If no error returned when getting uris,
+	// these uris will be used in the cert instead of the URIs.
+	URIsGetter
 	// SignerName can specified the signer of Kubernetes, which can be one of
 	// 1. "kubernetes.io/kube-apiserver-client"
 	// 2. "kubernetes.io/kube-apiserver-client-kubelet"
@@ -108,7 +118,7 @@ func (f *factory) New(cfg *CertManagerConfig) (certificate.Manager, error) {
 		}
 	}
 
-	ips, dnsNames := cfg.IPs, cfg.DNSNames
+	ips, dnsNames, uris := cfg.IPs, cfg.DNSNames, cfg.URIs
 	getTemplate := func() *x509.CertificateRequest {
 		if cfg.IPGetter != nil {
 			newIPs, err := cfg.IPGetter()
@@ -132,6 +142,15 @@ func (f *factory) New(cfg *CertManagerConfig) (certificate.Manager, error) {
 				return nil
 			}
 		}
+		if cfg.URIsGetter != nil {
+			newURIs, err := cfg.URIsGetter()
+			if err != nil {
+				klog.Errorf("failed to get uris for %s when preparing cr template, %v", cfg.ComponentName, err)
+				return nil
+			}
+			klog.V(4).Infof("cr template of %s uses uris=%v", cfg.ComponentName, newURIs)
+			uris = newURIs
+		}
 		return &x509.CertificateRequest{
 			Subject: pkix.Name{
 				CommonName:   cfg.CommonName,
@@ -139,6 +158,7 @@ func (f *factory) New(cfg *CertManagerConfig) (certificate.Manager, error) {
 			},
 			DNSNames:    dnsNames,
 			IPAddresses: ips,
+			URIs:        uris,
 		}
 	}
 
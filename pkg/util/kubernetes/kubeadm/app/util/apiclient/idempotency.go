@@ -166,6 +166,10 @@ func GetNodePoolInfoWithRetry(cfg *clientcmdapi.Config, name string) (*v1beta1.N
 		return false, nil
 	})
 	if err == nil {
+		// the nodepool doesn't exist
+		if obj == nil {
+			return nil, nil
+		}
 		np := new(v1beta1.NodePool)
 		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), np); err != nil {
 			return nil, err
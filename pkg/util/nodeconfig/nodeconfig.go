@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconfig
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+const (
+	// ConfigMapNamePrefix is the prefix of ConfigMap which holds the rendered node configurations of NodePool.
+	ConfigMapNamePrefix = "nodepool-config-"
+
+	// KubeletExtraArgsKey is the key of kubelet extra args in the rendered ConfigMap.
+	KubeletExtraArgsKey = "kubelet-extra-args"
+	// SysctlKey is the key of sysctl.conf in the rendered ConfigMap.
+	SysctlKey = "sysctl.conf"
+	// ContainerdHostsKey is the key of containerd hosts.toml files in the rendered ConfigMap,
+	// it is a json map from registry host to the content of hosts.toml.
+	ContainerdHostsKey = "containerd-hosts.json"
+
+	dockerHubHost   = "docker.io"
+	dockerHubServer = "https://registry-1.docker.io"
+)
+
+// ConfigMapName returns the name of ConfigMap which holds the rendered node configurations of NodePool.
+func ConfigMapName(pool string) string {
+	return ConfigMapNamePrefix + pool
+}
+
+// Render renders the node configurations into the data of ConfigMap.
+func Render(cfg *appsv1beta1.NodeConfig) (map[string]string, error) {
+	data := map[string]string{
+		KubeletExtraArgsKey: "",
+		SysctlKey:           "",
+		ContainerdHostsKey:  "{}",
+	}
+	if cfg == nil {
+		return data, nil
+	}
+
+	data[KubeletExtraArgsKey] = RenderKubeletExtraArgs(cfg.KubeletArgs)
+	data[SysctlKey] = RenderSysctls(cfg.Sysctls)
+
+	hosts, err := json.Marshal(RenderContainerdHosts(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("could not encode containerd hosts, %w", err)
+	}
+	data[ContainerdHostsKey] = string(hosts)
+	return data, nil
+}
+
+// Hash returns the hash of the rendered data, it is used for checking whether
+// the rendered configurations are changed.
+func Hash(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+// RenderKubeletExtraArgs renders kubelet args into command line flags, like --max-pods=110.
+func RenderKubeletExtraArgs(args map[string]string) string {
+	flags := make([]string, 0, len(args))
+	for k, v := range args {
+		flags = append(flags, fmt.Sprintf("--%s=%s", strings.TrimLeft(k, "-"), v))
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, " ")
+}
+
+// RenderSysctls renders kernel parameters into the format of sysctl.conf.
+func RenderSysctls(sysctls map[string]string) string {
+	lines := make([]string, 0, len(sysctls))
+	for k, v := range sysctls {
+		lines = append(lines, fmt.Sprintf("%s = %s\n", k, v))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "")
+}
+
+// RenderContainerdHosts renders registry settings into hosts.toml of containerd,
+// the key of returned map is the registry host. mirrors of registry are tried in
+// the declared order before falling back to the registry itself.
+func RenderContainerdHosts(cfg *appsv1beta1.NodeConfig) map[string]string {
+	servers := make(map[string]string)
+	entries := make(map[string][]string)
+	for _, mirror := range cfg.RegistryMirrors {
+		host, server := parseRegistry(mirror.Registry)
+		if len(host) == 0 {
+			continue
+		}
+		servers[host] = server
+		for _, endpoint := range mirror.Endpoints {
+			if len(strings.TrimSpace(endpoint)) == 0 {
+				continue
+			}
+			_, address := parseRegistry(endpoint)
+			entries[host] = append(entries[host], renderHostEntry(address, mirrorCapabilities, mirror.SkipVerify))
+		}
+	}
+
+	for _, registry := range cfg.InsecureRegistries {
+		host, server := parseRegistry(registry)
+		if len(host) == 0 {
+			continue
+		}
+		servers[host] = server
+		entries[host] = append(entries[host], renderHostEntry(server, nil, true))
+	}
+
+	hosts := make(map[string]string, len(servers))
+	for host, server := range servers {
+		hosts[host] = fmt.Sprintf("server = %q\n", server) + strings.Join(entries[host], "")
+	}
+	return hosts
+}
+
+// mirrorCapabilities are the operations that mirrors of registry are used for,
+// pushing images is not allowed because mirrors are only used as caches.
+var mirrorCapabilities = []string{"pull", "resolve"}
+
+func renderHostEntry(address string, capabilities []string, skipVerify bool) string {
+	entry := fmt.Sprintf("\n[host.%q]\n", address)
+	if len(capabilities) != 0 {
+		quoted := make([]string, 0, len(capabilities))
+		for _, c := range capabilities {
+			quoted = append(quoted, fmt.Sprintf("%q", c))
+		}
+		entry += fmt.Sprintf("  capabilities = [%s]\n", strings.Join(quoted, ", "))
+	}
+	if skipVerify {
+		entry += "  skip_verify = true\n"
+	}
+	return entry
+}
+
+// parseRegistry returns the host and the server address of registry,
+// https is used if the scheme of registry is not specified.
+func parseRegistry(registry string) (string, string) {
+	registry = strings.TrimSuffix(strings.TrimSpace(registry), "/")
+	if strings.HasPrefix(registry, "http://") || strings.HasPrefix(registry, "https://") {
+		return registry[strings.Index(registry, "://")+3:], registry
+	}
+	if registry == dockerHubHost {
+		// images of docker hub are served by registry-1.docker.io actually
+		return registry, dockerHubServer
+	}
+	return registry, "https://" + registry
+}
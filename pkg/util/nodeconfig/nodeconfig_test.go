@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconfig
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestRender(t *testing.T) {
+	testcases := map[string]struct {
+		cfg  *appsv1beta1.NodeConfig
+		want map[string]string
+	}{
+		"empty node config": {
+			cfg: nil,
+			want: map[string]string{
+				KubeletExtraArgsKey: "",
+				SysctlKey:           "",
+				ContainerdHostsKey:  "{}",
+			},
+		},
+		"node config with all settings": {
+			cfg: &appsv1beta1.NodeConfig{
+				KubeletArgs: map[string]string{
+					"max-pods":      "110",
+					"--node-status": "10s",
+				},
+				Sysctls: map[string]string{
+					"vm.max_map_count":    "262144",
+					"net.ipv4.ip_forward": "1",
+				},
+				InsecureRegistries: []string{"registry.local:5000", "http://10.0.0.1:5000/"},
+			},
+			want: map[string]string{
+				KubeletExtraArgsKey: "--max-pods=110 --node-status=10s",
+				SysctlKey:           "net.ipv4.ip_forward = 1\nvm.max_map_count = 262144\n",
+				ContainerdHostsKey: `{"10.0.0.1:5000":"server = \"http://10.0.0.1:5000\"\n\n[host.\"http://10.0.0.1:5000\"]\n  skip_verify = true\n",` +
+					`"registry.local:5000":"server = \"https://registry.local:5000\"\n\n[host.\"https://registry.local:5000\"]\n  skip_verify = true\n"}`,
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got, err := Render(tc.cfg)
+			if err != nil {
+				t.Errorf("expect no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("expect %#v, but got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	data := map[string]string{
+		KubeletExtraArgsKey: "--max-pods=110",
+		SysctlKey:           "",
+	}
+	if Hash(data) != Hash(map[string]string{SysctlKey: "", KubeletExtraArgsKey: "--max-pods=110"}) {
+		t.Errorf("expect hash is stable for the same data")
+	}
+	if Hash(data) == Hash(map[string]string{KubeletExtraArgsKey: "--max-pods=120", SysctlKey: ""}) {
+		t.Errorf("expect hash is changed for different data")
+	}
+}
+
+func TestRenderContainerdHosts(t *testing.T) {
+	testcases := map[string]struct {
+		cfg  *appsv1beta1.NodeConfig
+		want map[string]string
+	}{
+		"registry mirrors": {
+			cfg: &appsv1beta1.NodeConfig{
+				RegistryMirrors: []appsv1beta1.RegistryMirror{
+					{
+						Registry:   "docker.io",
+						Endpoints:  []string{"http://10.0.0.1:5000", "mirror.local"},
+						SkipVerify: true,
+					},
+					{
+						Registry:  "quay.io",
+						Endpoints: []string{"https://quay-cache.local/"},
+					},
+				},
+			},
+			want: map[string]string{
+				"docker.io": "server = \"https://registry-1.docker.io\"\n" +
+					"\n[host.\"http://10.0.0.1:5000\"]\n  capabilities = [\"pull\", \"resolve\"]\n  skip_verify = true\n" +
+					"\n[host.\"https://mirror.local\"]\n  capabilities = [\"pull\", \"resolve\"]\n  skip_verify = true\n",
+				"quay.io": "server = \"https://quay.io\"\n" +
+					"\n[host.\"https://quay-cache.local\"]\n  capabilities = [\"pull\", \"resolve\"]\n",
+			},
+		},
+		"mirrors of insecure registry": {
+			cfg: &appsv1beta1.NodeConfig{
+				InsecureRegistries: []string{"registry.local:5000"},
+				RegistryMirrors: []appsv1beta1.RegistryMirror{
+					{
+						Registry:  "registry.local:5000",
+						Endpoints: []string{"https://cache.local"},
+					},
+				},
+			},
+			want: map[string]string{
+				"registry.local:5000": "server = \"https://registry.local:5000\"\n" +
+					"\n[host.\"https://cache.local\"]\n  capabilities = [\"pull\", \"resolve\"]\n" +
+					"\n[host.\"https://registry.local:5000\"]\n  skip_verify = true\n",
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got := RenderContainerdHosts(tc.cfg)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("expect %#v, but got %#v", tc.want, got)
+			}
+		})
+	}
+}
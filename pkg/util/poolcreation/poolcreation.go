@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolcreation
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CreationPath is the path of nodepool creation service in yurt-manager
+	CreationPath = "/nodepool-creation"
+
+	maxCreationResponseSize = 64 * 1024
+)
+
+// CreationRequest is the request for creating a nodepool from a template configured in cluster.
+type CreationRequest struct {
+	// Name is the name of nodepool.
+	Name string `json:"name"`
+	// Template is the name of nodepool template.
+	Template string `json:"template"`
+	// NodeName is the name of node which is joining, it's recorded in the nodepool.
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+// CreationResponse is the response of nodepool creation service.
+type CreationResponse struct {
+	// Name is the name of nodepool.
+	Name string `json:"name"`
+	// Created is false if the nodepool exists already.
+	Created bool `json:"created"`
+}
+
+// Create requests the nodepool creation service to create the nodepool from template, the request is
+// authenticated by the bootstrap token. The certificate of service is verified with caFile if it's
+// specified, otherwise with system roots.
+func Create(server, caFile, bootstrapToken string, req *CreationRequest) (*CreationResponse, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caFile) != 0 {
+		caData, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, errors.Errorf("no certificate is found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(server, "/")+CreationPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+bootstrapToken)
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCreationResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nodepool creation is rejected with status code %d, %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result CreationResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrap(err, "could not decode nodepool creation response")
+	}
+	return &result, nil
+}
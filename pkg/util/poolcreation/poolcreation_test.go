@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolcreation
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	testcases := map[string]struct {
+		statusCode int
+		created    bool
+		isErr      bool
+	}{
+		"nodepool is created": {
+			statusCode: http.StatusOK,
+			created:    true,
+		},
+		"nodepool exists": {
+			statusCode: http.StatusOK,
+		},
+		"creation is rejected": {
+			statusCode: http.StatusForbidden,
+			isErr:      true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req CreationRequest
+				if r.URL.Path != CreationPath || r.Method != http.MethodPost {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.Header.Get("Authorization") != "Bearer abcdef.0123456789abcdef" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name != "hangzhou" || req.Template != "edge-site" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(tc.statusCode)
+				json.NewEncoder(w).Encode(CreationResponse{Name: req.Name, Created: tc.created})
+			}))
+			defer srv.Close()
+
+			caFile := filepath.Join(t.TempDir(), "ca.crt")
+			caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+			if err := os.WriteFile(caFile, caData, 0600); err != nil {
+				t.Fatalf("could not write ca file, %v", err)
+			}
+
+			resp, err := Create(srv.URL, caFile, "abcdef.0123456789abcdef", &CreationRequest{Name: "hangzhou", Template: "edge-site", NodeName: "node-a"})
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Name != "hangzhou" || resp.Created != tc.created {
+				t.Errorf("unexpected response %v", resp)
+			}
+		})
+	}
+}
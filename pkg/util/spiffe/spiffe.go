@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package spiffe provides the SPIFFE identities of edge components, which are
+// carried as URI SANs of the X509 certificates(X509-SVID) of edge components.
+//
+// the identities are scoped by node or nodepool:
+//
+//	spiffe://<trust-domain>/node/<node-name>/<component>
+//	spiffe://<trust-domain>/nodepool/<nodepool-name>/<component>
+package spiffe
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// Scheme is the URI scheme of SPIFFE identities.
+	Scheme = "spiffe"
+
+	// SignerName is the signer of X509-SVIDs of edge components, the X509-SVIDs are not signed by
+	// the signers of kubernetes, so that they can not be used as the client certificates of kube-apiserver.
+	SignerName = "openyurt.io/spiffe-svid"
+
+	// NodeScope is the scope of identities bound to a node.
+	NodeScope = "node"
+	// NodePoolScope is the scope of identities shared by the nodes in a nodepool.
+	NodePoolScope = "nodepool"
+
+	// ComponentYurtHub is the component name of yurthub in identities.
+	ComponentYurtHub = "yurthub"
+	// ComponentRavenAgent is the component name of raven agent in identities.
+	ComponentRavenAgent = "raven-agent"
+)
+
+// Components are the edge components that SPIFFE identities can be issued to.
+var Components = []string{ComponentYurtHub, ComponentRavenAgent}
+
+// ID is a parsed SPIFFE identity of an edge component.
+type ID struct {
+	TrustDomain string
+	// Scope is NodeScope or NodePoolScope.
+	Scope string
+	// Name is the name of node or nodepool.
+	Name      string
+	Component string
+}
+
+// URL returns the URI form of the identity.
+func (id *ID) URL() *url.URL {
+	return &url.URL{
+		Scheme: Scheme,
+		Host:   id.TrustDomain,
+		Path:   "/" + strings.Join([]string{id.Scope, id.Name, id.Component}, "/"),
+	}
+}
+
+func (id *ID) String() string {
+	return id.URL().String()
+}
+
+// NodeID returns the identity of component bound to the node.
+func NodeID(trustDomain, nodeName, component string) *url.URL {
+	id := &ID{TrustDomain: trustDomain, Scope: NodeScope, Name: nodeName, Component: component}
+	return id.URL()
+}
+
+// NodePoolID returns the identity of component shared by the nodes in the nodepool.
+func NodePoolID(trustDomain, poolName, component string) *url.URL {
+	id := &ID{TrustDomain: trustDomain, Scope: NodePoolScope, Name: poolName, Component: component}
+	return id.URL()
+}
+
+// IDs returns the identities of component on the node, the nodepool scoped identity is
+// included only when the nodepool is specified.
+func IDs(trustDomain, nodeName, poolName, component string) []*url.URL {
+	ids := []*url.URL{NodeID(trustDomain, nodeName, component)}
+	if len(poolName) != 0 {
+		ids = append(ids, NodePoolID(trustDomain, poolName, component))
+	}
+	return ids
+}
+
+// IsSPIFFE checks if the uri is a SPIFFE identity.
+func IsSPIFFE(u *url.URL) bool {
+	return u != nil && strings.EqualFold(u.Scheme, Scheme)
+}
+
+// Parse parses the SPIFFE identity of edge component from uri.
+func Parse(u *url.URL) (*ID, error) {
+	if !IsSPIFFE(u) {
+		return nil, fmt.Errorf("%q is not a spiffe identity", u)
+	}
+	if u.User != nil || len(u.Port()) != 0 || len(u.RawQuery) != 0 || len(u.Fragment) != 0 {
+		return nil, fmt.Errorf("spiffe identity %q must not contain user info, port, query or fragment", u)
+	}
+	if err := ValidateTrustDomain(u.Host); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(segments) != 3 {
+		return nil, fmt.Errorf("path of spiffe identity %q must be /<scope>/<name>/<component>", u)
+	}
+	id := &ID{TrustDomain: u.Host, Scope: segments[0], Name: segments[1], Component: segments[2]}
+	if id.Scope != NodeScope && id.Scope != NodePoolScope {
+		return nil, fmt.Errorf("scope of spiffe identity %q must be %s or %s", u, NodeScope, NodePoolScope)
+	}
+	if errs := validation.IsDNS1123Subdomain(id.Name); len(errs) != 0 {
+		return nil, fmt.Errorf("name of spiffe identity %q is invalid, %s", u, strings.Join(errs, ", "))
+	}
+	if !isComponent(id.Component) {
+		return nil, fmt.Errorf("component of spiffe identity %q must be one of %v", u, Components)
+	}
+	return id, nil
+}
+
+// ValidateTrustDomain checks the trust domain is a lowercase dns name.
+func ValidateTrustDomain(trustDomain string) error {
+	if errs := validation.IsDNS1123Subdomain(trustDomain); len(errs) != 0 {
+		return fmt.Errorf("trust domain %q is invalid, %s", trustDomain, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func isComponent(component string) bool {
+	for i := range Components {
+		if Components[i] == component {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spiffe
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestIDs(t *testing.T) {
+	ids := IDs("openyurt.io", "node1", "hangzhou", ComponentYurtHub)
+	expect := []string{
+		"spiffe://openyurt.io/node/node1/yurthub",
+		"spiffe://openyurt.io/nodepool/hangzhou/yurthub",
+	}
+	var got []string
+	for _, id := range ids {
+		got = append(got, id.String())
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect ids %v, but got %v", expect, got)
+	}
+
+	if ids := IDs("openyurt.io", "node1", "", ComponentYurtHub); len(ids) != 1 {
+		t.Errorf("expect only node scoped id without nodepool, but got %v", ids)
+	}
+}
+
+func TestParse(t *testing.T) {
+	testcases := map[string]struct {
+		uri    string
+		expect *ID
+	}{
+		"node scoped id": {
+			uri:    "spiffe://openyurt.io/node/node1/yurthub",
+			expect: &ID{TrustDomain: "openyurt.io", Scope: NodeScope, Name: "node1", Component: ComponentYurtHub},
+		},
+		"nodepool scoped id": {
+			uri:    "spiffe://openyurt.io/nodepool/hangzhou/raven-agent",
+			expect: &ID{TrustDomain: "openyurt.io", Scope: NodePoolScope, Name: "hangzhou", Component: ComponentRavenAgent},
+		},
+		"not spiffe": {
+			uri: "https://openyurt.io/node/node1/yurthub",
+		},
+		"unknown scope": {
+			uri: "spiffe://openyurt.io/cluster/node1/yurthub",
+		},
+		"unknown component": {
+			uri: "spiffe://openyurt.io/node/node1/kubelet",
+		},
+		"invalid name": {
+			uri: "spiffe://openyurt.io/node/Node_1/yurthub",
+		},
+		"extra segments": {
+			uri: "spiffe://openyurt.io/node/node1/yurthub/extra",
+		},
+		"port in trust domain": {
+			uri: "spiffe://openyurt.io:443/node/node1/yurthub",
+		},
+		"query": {
+			uri: "spiffe://openyurt.io/node/node1/yurthub?a=b",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(tc.uri)
+			if err != nil {
+				t.Fatalf("could not parse uri, %v", err)
+			}
+			id, err := Parse(u)
+			if tc.expect == nil {
+				if err == nil {
+					t.Errorf("expect error, but got id %v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+			if !reflect.DeepEqual(id, tc.expect) {
+				t.Errorf("expect id %v, but got %v", tc.expect, id)
+			}
+			if id.String() != tc.uri {
+				t.Errorf("expect uri %s, but got %s", tc.uri, id.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	// ExchangePath is the path of token exchange service in yurt-manager
+	ExchangePath = "/token-exchange"
+
+	// IdentityTypeOIDC means the identity is an OIDC id token
+	IdentityTypeOIDC = "oidc"
+	// IdentityTypeInstanceIdentity means the identity is a cloud instance identity document with its signature
+	IdentityTypeInstanceIdentity = "instance-identity"
+
+	// BoundNodeAnnotation is the annotation of exchanged bootstrap token secret, which records the node
+	// that the token is bound to. only the node is allowed to be bootstrapped by the token.
+	BoundNodeAnnotation = "openyurt.io/token-exchange-node"
+
+	maxExchangeResponseSize = 64 * 1024
+)
+
+// ExchangeRequest is the request for exchanging an external identity for a bootstrap token.
+type ExchangeRequest struct {
+	// Type is the type of identity, oidc or instance-identity.
+	Type string `json:"type"`
+	// Token is the OIDC id token, it's required for oidc identity.
+	Token string `json:"token,omitempty"`
+	// Document is the instance identity document, it's required for instance-identity identity.
+	Document string `json:"document,omitempty"`
+	// Signature is the base64 encoded signature of Document, it's required for instance-identity identity.
+	Signature string `json:"signature,omitempty"`
+	// NodeName is the name of node which is joining, it's recorded in the bootstrap token.
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+// ExchangeResponse is the response of token exchange service.
+type ExchangeResponse struct {
+	// Token is the bootstrap token in the format of abcdef.0123456789abcdef
+	Token string `json:"token"`
+	// Expiration is the time when the bootstrap token expires.
+	Expiration time.Time `json:"expiration"`
+}
+
+// Exchange exchanges the external identity for a short-lived bootstrap token from the token exchange
+// service, the certificate of service is verified with caFile if it's specified, otherwise with system roots.
+func Exchange(server, caFile string, req *ExchangeRequest) (*ExchangeResponse, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caFile) != 0 {
+		caData, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, pkgerrors.Errorf("no certificate is found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+	resp, err := client.Post(strings.TrimSuffix(server, "/")+ExchangePath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxExchangeResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange is rejected with status code %d, %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result ExchangeResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, pkgerrors.Wrap(err, "could not decode token exchange response")
+	}
+	if _, err := newBootstrapTokenString(result.Token); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
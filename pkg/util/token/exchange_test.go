@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExchange(t *testing.T) {
+	expiration := time.Date(2023, 8, 1, 10, 15, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		statusCode int
+		token      string
+		isErr      bool
+	}{
+		"token is exchanged": {
+			statusCode: http.StatusOK,
+			token:      "abcdef.0123456789abcdef",
+		},
+		"exchange is rejected": {
+			statusCode: http.StatusUnauthorized,
+			isErr:      true,
+		},
+		"invalid token is responded": {
+			statusCode: http.StatusOK,
+			token:      "invalid-token",
+			isErr:      true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ExchangeRequest
+				if r.URL.Path != ExchangePath || r.Method != http.MethodPost {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type != IdentityTypeOIDC || req.Token != "id-token" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(tc.statusCode)
+				json.NewEncoder(w).Encode(ExchangeResponse{Token: tc.token, Expiration: expiration})
+			}))
+			defer srv.Close()
+
+			caFile := filepath.Join(t.TempDir(), "ca.crt")
+			caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+			if err := os.WriteFile(caFile, caData, 0600); err != nil {
+				t.Fatalf("could not write ca file, %v", err)
+			}
+
+			resp, err := Exchange(srv.URL, caFile, &ExchangeRequest{Type: IdentityTypeOIDC, Token: "id-token", NodeName: "node-a"})
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.Token != tc.token || !resp.Expiration.Equal(expiration) {
+				t.Errorf("unexpected response %v", resp)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -162,6 +163,55 @@ func RetrieveValidatedConfigInfo(client kubernetes.Interface, data *BootstrapDat
 	return secureKubeconfig, nil
 }
 
+// JoinInfo is the information for bootstrapping node which is cached by yurthub in the nodepool,
+// so nodes can be joined from the nodepool when kube-apiserver is unreachable.
+type JoinInfo struct {
+	// ClusterInfo is the cluster-info ConfigMap in kube-public namespace with JWS signatures of bootstrap tokens
+	ClusterInfo *v1.ConfigMap `json:"clusterInfo"`
+	// Version is the version of kube-apiserver
+	Version *version.Info `json:"version,omitempty"`
+}
+
+// ValidateCachedClusterInfo validates the cluster-info ConfigMap which is not retrieved from kube-apiserver
+// directly, like the one in JoinInfo. The JWS signature of token is verified, and the cluster CA is checked
+// against CaCertHashes if specified.
+func ValidateCachedClusterInfo(clusterInfo *v1.ConfigMap, data *BootstrapData) (*clientcmdapi.Config, error) {
+	if clusterInfo == nil {
+		return nil, pkgerrors.Errorf("%s ConfigMap is empty", bootstrapapi.ConfigMapClusterInfo)
+	}
+
+	token, err := newBootstrapTokenString(data.JoinToken)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeyPins := pubkeypin.NewSet()
+	if err = pubKeyPins.Allow(data.CaCertHashes...); err != nil {
+		return nil, err
+	}
+
+	kubeconfigBytes, err := validateClusterInfoToken(clusterInfo, token)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.Load(kubeconfigBytes)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "couldn't parse the kubeconfig file in the %s ConfigMap", bootstrapapi.ConfigMapClusterInfo)
+	}
+
+	if len(config.Clusters) != 1 {
+		return nil, pkgerrors.Errorf("expected the kubeconfig file in the %s ConfigMap to have a single cluster, but it had %d", bootstrapapi.ConfigMapClusterInfo, len(config.Clusters))
+	}
+
+	if !pubKeyPins.Empty() {
+		if _, err := validateClusterCA(config, pubKeyPins); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
 // buildInsecureBootstrapKubeConfig makes a kubeconfig object that connects insecurely to the API Server for bootstrapping purposes
 func buildInsecureBootstrapKubeConfig(endpoint, clustername string) *clientcmdapi.Config {
 	controlPlaneEndpoint := fmt.Sprintf("https://%s", endpoint)
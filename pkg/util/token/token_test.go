@@ -18,6 +18,10 @@ package token
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
 	"testing"
 	"time"
 
@@ -29,8 +33,12 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	fakeclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
 	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
 	tokenjws "k8s.io/cluster-bootstrap/token/jws"
+
+	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
+	"github.com/openyurtio/openyurt/pkg/util/pubkeypin"
 )
 
 func TestRetrieveValidatedConfigInfo(t *testing.T) {
@@ -312,3 +320,85 @@ func createOrUpdateConfigMap(client clientset.Interface, cm *v1.ConfigMap) error
 	}
 	return nil
 }
+
+func TestValidateCachedClusterInfo(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "kubernetes"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	kubeconfig, err := clientcmd.Write(*kubeconfigutil.CreateBasic("https://127.0.0.1:6443", "kubernetes", BootstrapUser, caData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := tokenjws.ComputeDetachedSignature(string(kubeconfig), "123456", "abcdef1234567890")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterInfo := &v1.ConfigMap{
+		Data: map[string]string{
+			bootstrapapi.KubeConfigKey:                    string(kubeconfig),
+			bootstrapapi.JWSSignatureKeyPrefix + "123456": signature,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		clusterInfo *v1.ConfigMap
+		data        BootstrapData
+		expectedErr bool
+	}{
+		{
+			name:        "valid with CA verification",
+			clusterInfo: clusterInfo,
+			data:        BootstrapData{JoinToken: "123456.abcdef1234567890", CaCertHashes: []string{pubkeypin.Hash(caCert)}},
+		},
+		{
+			name:        "valid without CA verification",
+			clusterInfo: clusterInfo,
+			data:        BootstrapData{JoinToken: "123456.abcdef1234567890"},
+		},
+		{
+			name:        "invalid: token secret is wrong",
+			clusterInfo: clusterInfo,
+			data:        BootstrapData{JoinToken: "123456.abcdef1234567891"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid: no signature for token",
+			clusterInfo: clusterInfo,
+			data:        BootstrapData{JoinToken: "654321.abcdef1234567890"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid: CA hash mismatch",
+			clusterInfo: clusterInfo,
+			data: BootstrapData{
+				JoinToken:    "123456.abcdef1234567890",
+				CaCertHashes: []string{"sha256:98be2e6d4d8a89aa308fb15de0c07e2531ce549c68dec1687cdd5c06f0826658"},
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid: cluster info is empty",
+			data:        BootstrapData{JoinToken: "123456.abcdef1234567890"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := ValidateCachedClusterInfo(tc.clusterInfo, &tc.data)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %v, but got %v", tc.expectedErr, err)
+			}
+			if err == nil && len(config.Clusters) != 1 {
+				t.Errorf("expected a single cluster, but got %d", len(config.Clusters))
+			}
+		})
+	}
+}
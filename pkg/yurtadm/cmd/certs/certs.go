@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	util "github.com/openyurtio/openyurt/pkg/yurtadm/util/error"
+)
+
+// NewCmdCerts returns "yurtadm certs" command.
+func NewCmdCerts(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "certs",
+		Short: "Commands related to handling certificates of edge node",
+		Run:   subCmdRun(),
+	}
+
+	cmd.AddCommand(newCmdRenew(out))
+	return cmd
+}
+
+// subCmdRun returns a function that handles a case where a subcommand must be specified
+func subCmdRun() func(c *cobra.Command, args []string) {
+	return func(c *cobra.Command, args []string) {
+		if len(args) > 0 {
+			msg := fmt.Sprintf("invalid subcommand %q", strings.Join(args, " "))
+			util.CheckErr(errors.Errorf("%s\nSee '%s -h' for help and examples", msg, c.CommandPath()))
+		}
+		if err := c.Help(); err != nil {
+			return
+		}
+		util.CheckErr(util.ErrExit)
+	}
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/certificate"
+	"k8s.io/client-go/util/certificate/csr"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/projectinfo"
+	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
+	"github.com/openyurtio/openyurt/pkg/util/token"
+	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
+	hubtoken "github.com/openyurtio/openyurt/pkg/yurthub/certificate/token"
+)
+
+const (
+	ComponentKubelet = "kubelet"
+	ComponentYurtHub = "yurthub"
+
+	kubeletPkiDir        = "/var/lib/kubelet/pki"
+	kubeletClientPrefix  = "kubelet-client"
+	defaultRenewBefore   = 24 * time.Hour
+	defaultApprovalWait  = 15 * time.Minute
+	componentsFlag       = "components"
+	renewBeforeFlag      = "renew-before"
+	approvalTimeoutFlag  = "approval-timeout"
+	forceFlag            = "force"
+	bootstrapClusterName = "kubernetes"
+)
+
+type renewOptions struct {
+	token                    string
+	caCertHashes             []string
+	unsafeSkipCAVerification bool
+	serverAddr               string
+	yurthubServer            string
+	nodeName                 string
+	components               []string
+	renewBefore              time.Duration
+	approvalTimeout          time.Duration
+	force                    bool
+	kubeconfig               string
+	throughYurtHub           bool
+}
+
+// newRenewOptions returns a struct ready for being used for creating cmd certs renew flags.
+func newRenewOptions() *renewOptions {
+	return &renewOptions{
+		caCertHashes:             make([]string, 0),
+		unsafeSkipCAVerification: true,
+		yurthubServer:            yurtconstants.DefaultYurtHubServerAddr,
+		components:               []string{ComponentKubelet, ComponentYurtHub},
+		renewBefore:              defaultRenewBefore,
+		approvalTimeout:          defaultApprovalWait,
+	}
+}
+
+// newCmdRenew returns "yurtadm certs renew" command.
+func newCmdRenew(out io.Writer) *cobra.Command {
+	o := newRenewOptions()
+
+	cmd := &cobra.Command{
+		Use:   "renew",
+		Short: "Renew the client certificates of kubelet and yurthub which expired while node is offline",
+		Long: `Renew the client certificates of kubelet and yurthub without resetting and rejoining node.
+The certificate signing requests are created with the bootstrap token if --token is specified, otherwise
+with the credential in --kubeconfig. The requests which are not approved automatically wait for the
+approval of operator, e.g. kubectl certificate approve <csr>.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if kubeconfig, err := cmd.Flags().GetString("kubeconfig"); err == nil {
+				o.kubeconfig = kubeconfig
+			}
+			if err := o.validate(); err != nil {
+				return err
+			}
+			return o.run(out)
+		},
+	}
+
+	addRenewFlags(cmd.Flags(), o)
+	return cmd
+}
+
+func addRenewFlags(flagSet *flag.FlagSet, o *renewOptions) {
+	flagSet.StringVar(
+		&o.token, yurtconstants.TokenStr, o.token,
+		"Use this bootstrap token to request certificates.",
+	)
+	flagSet.StringSliceVar(
+		&o.caCertHashes, yurtconstants.TokenDiscoveryCAHash, o.caCertHashes,
+		"For token-based discovery, validate that the root CA public key matches this hash (format: \"<type>:<value>\").",
+	)
+	flagSet.BoolVar(
+		&o.unsafeSkipCAVerification, yurtconstants.TokenDiscoverySkipCAHash, o.unsafeSkipCAVerification,
+		"For token-based discovery, allow renewing without --discovery-token-ca-cert-hash pinning.",
+	)
+	flagSet.StringVar(
+		&o.serverAddr, yurtconstants.ServerAddr, o.serverAddr,
+		"The address of Kubernetes kube-apiserver, the format is: \"server1,server2,...\"",
+	)
+	flagSet.StringVar(
+		&o.yurthubServer, yurtconstants.YurtHubServerAddr, o.yurthubServer,
+		"Sets the address for yurthub server addr",
+	)
+	flagSet.StringVar(
+		&o.nodeName, yurtconstants.NodeName, o.nodeName,
+		"Specify the node name. if not specified, hostname will be used.",
+	)
+	flagSet.StringSliceVar(
+		&o.components, componentsFlag, o.components,
+		"The components whose client certificates are renewed, kubelet and yurthub are supported.",
+	)
+	flagSet.DurationVar(
+		&o.renewBefore, renewBeforeFlag, o.renewBefore,
+		"Certificates which expire within this duration are renewed.",
+	)
+	flagSet.DurationVar(
+		&o.approvalTimeout, approvalTimeoutFlag, o.approvalTimeout,
+		"How long to wait for the certificate signing requests to be approved and signed.",
+	)
+	flagSet.BoolVar(
+		&o.force, forceFlag, o.force,
+		"Renew certificates even if they're still valid.",
+	)
+	flagSet.BoolVar(
+		&o.throughYurtHub, yurtconstants.ThroughYurtHub, o.throughYurtHub,
+		"Request certificates through the local yurthub, only the bootstrap token or the bearer token in kubeconfig can be forwarded by yurthub.",
+	)
+}
+
+func (o *renewOptions) validate() error {
+	if len(o.token) == 0 && len(o.kubeconfig) == 0 {
+		return errors.New("either --token or --kubeconfig should be specified to request certificates")
+	}
+	// kube-apiserver address is used by yurthub to request its certificate with the bootstrap token
+	if len(o.token) != 0 && len(o.serverAddr) == 0 && (!o.throughYurtHub || o.hasComponent(ComponentYurtHub)) {
+		return errors.Errorf("--%s should be specified with --token", yurtconstants.ServerAddr)
+	}
+	if len(o.token) != 0 && len(o.caCertHashes) == 0 && !o.unsafeSkipCAVerification {
+		return errors.New("set --discovery-token-unsafe-skip-ca-verification flag as true or pass CACertHashes to continue")
+	}
+	for _, c := range o.components {
+		if c != ComponentKubelet && c != ComponentYurtHub {
+			return errors.Errorf("component %s is not supported, only kubelet and yurthub are supported", c)
+		}
+	}
+	return nil
+}
+
+func (o *renewOptions) hasComponent(component string) bool {
+	for _, c := range o.components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *renewOptions) run(out io.Writer) error {
+	nodeName, err := edgenode.GetHostname(o.nodeName)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range o.components {
+		switch c {
+		case ComponentKubelet:
+			err = o.renewKubelet(out, nodeName)
+		case ComponentYurtHub:
+			err = o.renewYurtHub(out, nodeName)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "fail to renew certificate of %s", c)
+		}
+	}
+	return nil
+}
+
+// renewKubelet requests a new client certificate for kubelet and restarts kubelet to load it.
+func (o *renewOptions) renewKubelet(out io.Writer, nodeName string) error {
+	store, err := certificate.NewFileStore(kubeletClientPrefix, kubeletPkiDir, kubeletPkiDir, "", "")
+	if err != nil {
+		return err
+	}
+	if !o.force && !needRenew(store.CurrentPath(), o.renewBefore, time.Now()) {
+		fmt.Fprintf(out, "certificate of kubelet %s is still valid, skip renewing\n", store.CurrentPath())
+		return nil
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   fmt.Sprintf("system:node:%s", nodeName),
+			Organization: []string{"system:nodes"},
+		},
+	}
+	if err := o.requestCertificate(out, store, template, certificatesv1.KubeAPIServerClientKubeletSignerName); err != nil {
+		return err
+	}
+
+	if output, err := exec.Command("bash", "-c", yurtconstants.RestartKubeletSvc).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "fail to restart kubelet, %s", output)
+	}
+	fmt.Fprintf(out, "certificate of kubelet is renewed to %s\n", store.CurrentPath())
+	return nil
+}
+
+// renewYurtHub renews the client certificate of yurthub. With bootstrap token, yurthub is given a new
+// bootstrap config and requests the certificate by itself. Otherwise the certificate is requested here
+// and yurthub is restarted to load it.
+func (o *renewOptions) renewYurtHub(out io.Writer, nodeName string) error {
+	if !o.force && yurthub.CheckYurthubReadyzOnce(o.yurthubServer) {
+		fmt.Fprintln(out, "certificate of yurthub is still valid, skip renewing")
+		return nil
+	}
+
+	if len(o.token) != 0 {
+		if err := yurthub.SetHubBootstrapConfig(o.firstServer(), o.token, o.caCertHashes); err != nil {
+			return err
+		}
+		if err := yurthub.CheckYurthubReadyz(o.yurthubServer); err != nil {
+			return err
+		}
+		if err := yurthub.CleanHubBootstrapConfig(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "certificate of yurthub is renewed")
+		return nil
+	}
+
+	pkiDir := filepath.Join(yurtconstants.YurtHubWorkdir, "pki")
+	store, err := certificate.NewFileStore(projectinfo.GetHubName(), pkiDir, pkiDir, "", "")
+	if err != nil {
+		return err
+	}
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   fmt.Sprintf("system:node:%s", nodeName),
+			Organization: []string{hubtoken.YurtHubCSROrg, "system:nodes"},
+		},
+	}
+	if err := o.requestCertificate(out, store, template, certificatesv1.KubeAPIServerClientSignerName); err != nil {
+		return err
+	}
+
+	// yurthub static pod is restarted by kubelet after the process exits
+	if output, err := exec.Command("pkill", "-x", projectinfo.GetHubName()).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "fail to restart yurthub, %s", output)
+	}
+	if err := yurthub.CheckYurthubReadyz(o.yurthubServer); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "certificate of yurthub is renewed to %s\n", store.CurrentPath())
+	return nil
+}
+
+// requestCertificate creates a certificate signing request with a new private key, waits for it to be
+// approved and signed, and saves the certificate and key into store.
+func (o *renewOptions) requestCertificate(out io.Writer, store certificate.FileStore, template *x509.CertificateRequest, signerName string) error {
+	client, err := o.csrClient()
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		return err
+	}
+	csrData, err := x509.CreateCertificateRequest(cryptorand.Reader, template, privateKey)
+	if err != nil {
+		return err
+	}
+	keyData, err := keyutil.MarshalPrivateKeyToPEM(privateKey)
+	if err != nil {
+		return err
+	}
+
+	usages := []certificatesv1.KeyUsage{
+		certificatesv1.UsageDigitalSignature,
+		certificatesv1.UsageKeyEncipherment,
+		certificatesv1.UsageClientAuth,
+	}
+	reqName, reqUID, err := csr.RequestCertificate(client, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrData}),
+		"", signerName, nil, usages, privateKey)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "certificate signing request %s is created, approve it by `kubectl certificate approve %s` if it's not approved automatically\n", reqName, reqName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.approvalTimeout)
+	defer cancel()
+	certData, err := csr.WaitForCertificate(ctx, client, reqName, reqUID)
+	if err != nil {
+		return errors.Wrapf(err, "certificate signing request %s is not approved and signed in %s", reqName, o.approvalTimeout)
+	}
+
+	_, err = store.Update(certData, keyData)
+	return err
+}
+
+// csrClient returns the client to create certificate signing requests, which authenticates with
+// the bootstrap token or the credential in kubeconfig.
+func (o *renewOptions) csrClient() (clientset.Interface, error) {
+	if o.throughYurtHub {
+		cfg, err := yurthub.ProxyKubeConfig(o.kubeconfig, o.token, o.yurthubServer)
+		if err != nil {
+			return nil, err
+		}
+		return kubeconfigutil.ToClientSet(cfg)
+	}
+
+	if len(o.token) == 0 {
+		cfg, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		return clientset.NewForConfig(cfg)
+	}
+
+	server := o.firstServer()
+	cfg, err := token.RetrieveValidatedConfigInfo(nil, &token.BootstrapData{
+		ServerAddr:   server,
+		JoinToken:    o.token,
+		CaCertHashes: o.caCertHashes,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't retrieve bootstrap config info")
+	}
+	cluster := kubeconfigutil.GetClusterFromKubeConfig(cfg)
+	return kubeconfigutil.ToClientSet(kubeconfigutil.CreateWithToken(fmt.Sprintf("https://%s", server),
+		bootstrapClusterName, "token-bootstrap-client", cluster.CertificateAuthorityData, o.token))
+}
+
+// firstServer returns the host:port of the first kube-apiserver
+func (o *renewOptions) firstServer() string {
+	server := strings.TrimSpace(strings.Split(o.serverAddr, ",")[0])
+	return strings.TrimPrefix(server, "https://")
+}
+
+// needRenew checks whether the certificate in path doesn't exist, can't be parsed or expires within renewBefore.
+func needRenew(path string, renewBefore time.Duration, now time.Time) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		klog.Infof("fail to read certificate %s, %v", path, err)
+		return true
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return true
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return true
+		}
+		return now.Add(renewBefore).After(cert.NotAfter)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, path string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "system:node:foo"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})...)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNeedRenew(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	expired := filepath.Join(dir, "expired.pem")
+	writeCert(t, expired, now.Add(-time.Hour))
+	expiring := filepath.Join(dir, "expiring.pem")
+	writeCert(t, expiring, now.Add(time.Hour))
+	valid := filepath.Join(dir, "valid.pem")
+	writeCert(t, valid, now.Add(30*24*time.Hour))
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := map[string]struct {
+		path   string
+		expect bool
+	}{
+		"not exist": {path: filepath.Join(dir, "none.pem"), expect: true},
+		"invalid":   {path: invalid, expect: true},
+		"expired":   {path: expired, expect: true},
+		"expiring":  {path: expiring, expect: true},
+		"valid":     {path: valid, expect: false},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := needRenew(tc.path, defaultRenewBefore, now); got != tc.expect {
+				t.Errorf("expect %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testcases := map[string]struct {
+		modify  func(o *renewOptions)
+		wantErr bool
+	}{
+		"no credential": {
+			modify:  func(o *renewOptions) {},
+			wantErr: true,
+		},
+		"kubeconfig": {
+			modify:  func(o *renewOptions) { o.kubeconfig = "/etc/kubernetes/admin.conf" },
+			wantErr: false,
+		},
+		"token without server": {
+			modify:  func(o *renewOptions) { o.token = "abcdef.0123456789abcdef" },
+			wantErr: true,
+		},
+		"token with server": {
+			modify: func(o *renewOptions) {
+				o.token = "abcdef.0123456789abcdef"
+				o.serverAddr = "1.2.3.4:6443"
+			},
+			wantErr: false,
+		},
+		"token through yurthub without server": {
+			modify: func(o *renewOptions) {
+				o.token = "abcdef.0123456789abcdef"
+				o.throughYurtHub = true
+				o.components = []string{ComponentKubelet}
+			},
+			wantErr: false,
+		},
+		"token through yurthub without server for yurthub": {
+			modify: func(o *renewOptions) {
+				o.token = "abcdef.0123456789abcdef"
+				o.throughYurtHub = true
+			},
+			wantErr: true,
+		},
+		"unknown component": {
+			modify: func(o *renewOptions) {
+				o.kubeconfig = "/etc/kubernetes/admin.conf"
+				o.components = []string{"kube-proxy"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			o := newRenewOptions()
+			tc.modify(o)
+			if err := o.validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expect error %v, but got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
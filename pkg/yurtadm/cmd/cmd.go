@@ -26,6 +26,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/certs"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/config"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/docs"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join"
@@ -33,6 +34,7 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/reset"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/staticpods"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/token"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/upgrade"
 )
 
 // NewYurtadmCommand creates a new yurtadm command
@@ -54,6 +56,8 @@ func NewYurtadmCommand() *cobra.Command {
 	cmds.AddCommand(renew.NewCmdRenew(os.Stdin, os.Stdout, os.Stderr))
 	cmds.AddCommand(staticpods.NewCmdStaticPods(os.Stdin, os.Stdout, os.Stderr))
 	cmds.AddCommand(config.NewCmdConfig(os.Stdin, os.Stdout, os.Stderr))
+	cmds.AddCommand(certs.NewCmdCerts(os.Stdin, os.Stdout, os.Stderr))
+	cmds.AddCommand(upgrade.NewCmdUpgrade(os.Stdin, os.Stdout, os.Stderr))
 	klog.InitFlags(nil)
 	// goflag.Parse()
 	flag.CommandLine.AddGoFlagSet(goflag.CommandLine)
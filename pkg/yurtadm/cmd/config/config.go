@@ -24,9 +24,11 @@ import (
 	"github.com/lithammer/dedent"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
 	"github.com/openyurtio/openyurt/pkg/util/templates"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
@@ -41,6 +43,7 @@ func NewCmdConfig(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command
 	}
 
 	cmd.AddCommand(newCmdConfigPrint(out))
+	cmd.AddCommand(newCmdConfigPrintJoinConfigDefaults(out))
 	return cmd
 }
 
@@ -60,6 +63,25 @@ func newCmdConfigPrintJoinDefaults(out io.Writer) *cobra.Command {
 	return newCmdConfigPrintActionDefaults(out, "join", getDefaultNodeConfigBytes)
 }
 
+// newCmdConfigPrintJoinConfigDefaults returns cobra.Command for "yurtadm config print-join-defaults" command
+func newCmdConfigPrintJoinConfigDefaults(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "print-join-defaults",
+		Short: "Print default yurtadm JoinConfiguration, that can be used for 'yurtadm join --join-config'",
+		Long: fmt.Sprintf(dedent.Dedent(`
+			This command prints the default yurtadm JoinConfiguration which covers nodepool, labels, yurthub, raven and runtime
+			settings of 'yurtadm join', it can be edited and used by 'yurtadm join --join-config'.
+
+			Note that the Bootstrap Token is replaced with placeholder value %q.
+		`), constants.PlaceholderToken),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigPrintActionDefaults(out, getDefaultJoinConfigurationBytes)
+		},
+		Args: cobra.NoArgs,
+	}
+	return cmd
+}
+
 func newCmdConfigPrintActionDefaults(out io.Writer, action string, configBytesProc func() (string, error)) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   fmt.Sprintf("%s-defaults", action),
@@ -88,6 +110,14 @@ func runConfigPrintActionDefaults(out io.Writer, configBytesProc func() (string,
 	return nil
 }
 
+func getDefaultJoinConfigurationBytes() (string, error) {
+	data, err := yaml.Marshal(join.DefaultJoinConfiguration())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func getDefaultNodeConfigBytes() (string, error) {
 	KubeadmJoinDiscoveryFilePath := filepath.Join(constants.KubeletWorkdir, constants.KubeadmJoinDiscoveryFileName)
 	ignoreErrors := sets.String{}
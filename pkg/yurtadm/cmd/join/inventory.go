@@ -0,0 +1,338 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	flag "github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/remote"
+)
+
+const (
+	defaultInventoryParallelism = 10
+	remoteYurtadmPath           = "/tmp/yurtadm"
+	remoteJoinConfigPath        = "/tmp/yurtadm-join-config.yaml"
+)
+
+// Inventory is the list of hosts which are joined by ssh.
+type Inventory struct {
+	// Defaults are used for the fields which are not specified in hosts.
+	Defaults InventoryHost `json:"defaults,omitempty"`
+	// Hosts are the machines which will be joined.
+	Hosts []InventoryHost `json:"hosts"`
+}
+
+// InventoryHost describes how to connect to a host and how the host is joined.
+type InventoryHost struct {
+	Address               string `json:"address,omitempty"`
+	Port                  int    `json:"port,omitempty"`
+	User                  string `json:"user,omitempty"`
+	Password              string `json:"password,omitempty"`
+	PrivateKeyFile        string `json:"privateKeyFile,omitempty"`
+	KnownHostsFile        string `json:"knownHostsFile,omitempty"`
+	InsecureIgnoreHostKey bool   `json:"insecureIgnoreHostKey,omitempty"`
+	// YurtadmBinary is the local path of yurtadm which is uploaded to host, the running yurtadm is used if it's not specified.
+	YurtadmBinary string `json:"yurtadmBinary,omitempty"`
+
+	NodeName     string `json:"nodeName,omitempty"`
+	NodePoolName string `json:"nodePoolName,omitempty"`
+	NodeType     string `json:"nodeType,omitempty"`
+	NodeLabels   string `json:"nodeLabels,omitempty"`
+}
+
+// remoteHost is the connection to a host in inventory.
+type remoteHost interface {
+	Run(cmd string, stdout, stderr io.Writer) error
+	Upload(src io.Reader, dst string, mode os.FileMode) error
+	Close() error
+}
+
+var dialHost = func(h *InventoryHost) (remoteHost, error) {
+	return remote.Dial(&remote.Config{
+		Address:               h.Address,
+		Port:                  h.Port,
+		User:                  h.User,
+		Password:              h.Password,
+		PrivateKeyFile:        h.PrivateKeyFile,
+		KnownHostsFile:        h.KnownHostsFile,
+		InsecureIgnoreHostKey: h.InsecureIgnoreHostKey,
+	})
+}
+
+// batchFlags are flags of batch join which are not passed to yurtadm join on hosts.
+var batchFlags = sets.NewString(yurtconstants.Inventory, yurtconstants.InventoryParallelism)
+
+// loadInventory reads inventory file and fills hosts with defaults.
+func loadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var inventory Inventory
+	if err := yaml.UnmarshalStrict(data, &inventory); err != nil {
+		return nil, errors.Wrapf(err, "could not parse inventory %s", path)
+	}
+	if len(inventory.Hosts) == 0 {
+		return nil, errors.Errorf("no host is found in inventory %s", path)
+	}
+
+	addresses := sets.NewString()
+	nodeNames := sets.NewString()
+	for i := range inventory.Hosts {
+		h := &inventory.Hosts[i]
+		h.applyDefaults(&inventory.Defaults)
+		if len(h.Address) == 0 {
+			return nil, errors.Errorf("address of the %dth host is empty in inventory %s", i, path)
+		}
+		if addresses.Has(h.Address) {
+			return nil, errors.Errorf("host %s is duplicated in inventory %s", h.Address, path)
+		}
+		addresses.Insert(h.Address)
+		if len(h.NodeName) != 0 {
+			if nodeNames.Has(h.NodeName) {
+				return nil, errors.Errorf("node name %s is duplicated in inventory %s", h.NodeName, path)
+			}
+			nodeNames.Insert(h.NodeName)
+		}
+	}
+	return &inventory, nil
+}
+
+func (h *InventoryHost) applyDefaults(d *InventoryHost) {
+	setDefault := func(v *string, def string) {
+		if len(*v) == 0 {
+			*v = def
+		}
+	}
+	if h.Port == 0 {
+		h.Port = d.Port
+	}
+	setDefault(&h.User, d.User)
+	setDefault(&h.User, "root")
+	setDefault(&h.Password, d.Password)
+	setDefault(&h.PrivateKeyFile, d.PrivateKeyFile)
+	setDefault(&h.KnownHostsFile, d.KnownHostsFile)
+	h.InsecureIgnoreHostKey = h.InsecureIgnoreHostKey || d.InsecureIgnoreHostKey
+	setDefault(&h.YurtadmBinary, d.YurtadmBinary)
+	setDefault(&h.NodePoolName, d.NodePoolName)
+	setDefault(&h.NodeType, d.NodeType)
+	setDefault(&h.NodeLabels, d.NodeLabels)
+}
+
+// joinCommand builds the yurtadm join command for host, the flags specified in command line are
+// passed through except batch flags, and the node flags of host take precedence over them.
+func joinCommand(args []string, flags *flag.FlagSet, h *InventoryHost) string {
+	values := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		if batchFlags.Has(f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(flag.SliceValue); ok {
+			values[f.Name] = strings.Join(sv.GetSlice(), ",")
+			return
+		}
+		values[f.Name] = f.Value.String()
+	})
+	if _, ok := values[yurtconstants.JoinConfig]; ok {
+		values[yurtconstants.JoinConfig] = remoteJoinConfigPath
+	}
+	for name, value := range map[string]string{
+		yurtconstants.NodeName:     h.NodeName,
+		yurtconstants.NodePoolName: h.NodePoolName,
+		yurtconstants.NodeType:     h.NodeType,
+		yurtconstants.NodeLabels:   h.NodeLabels,
+	} {
+		if len(value) != 0 {
+			values[name] = value
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	words := []string{remoteYurtadmPath, "join"}
+	for _, arg := range args {
+		words = append(words, remote.Quote(arg))
+	}
+	for _, name := range names {
+		words = append(words, remote.Quote(fmt.Sprintf("--%s=%s", name, values[name])))
+	}
+	cmd := strings.Join(words, " ")
+	if h.User != "root" {
+		cmd = "sudo -n " + cmd
+	}
+	return cmd
+}
+
+// runBatchJoin joins the hosts in inventory by ssh in parallel, the output of every host is
+// prefixed with its address, and a summary of results is printed at the end.
+func runBatchJoin(args []string, opt *joinOptions, flags *flag.FlagSet, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("apiServer endpoint is empty")
+	}
+	if opt.inventoryParallelism <= 0 {
+		return errors.Errorf("--%s should be positive", yurtconstants.InventoryParallelism)
+	}
+	inventory, err := loadInventory(opt.inventory)
+	if err != nil {
+		return err
+	}
+
+	var lock sync.Mutex
+	results := make([]error, len(inventory.Hosts))
+	sem := make(chan struct{}, opt.inventoryParallelism)
+	var wg sync.WaitGroup
+	for i := range inventory.Hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			h := &inventory.Hosts[i]
+			w := &prefixWriter{prefix: fmt.Sprintf("[%s] ", h.Address), out: out, lock: &lock}
+			results[i] = joinHost(args, flags, h, w)
+			w.Flush()
+			if results[i] != nil {
+				fmt.Fprintf(w, "join failed: %v\n", results[i])
+			} else {
+				fmt.Fprintf(w, "join succeeded\n")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tNODE\tRESULT")
+	for i := range inventory.Hosts {
+		result := "succeeded"
+		if results[i] != nil {
+			failed++
+			result = fmt.Sprintf("failed: %v", results[i])
+		}
+		nodeName := inventory.Hosts[i].NodeName
+		if len(nodeName) == 0 {
+			nodeName = "<hostname>"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", inventory.Hosts[i].Address, nodeName, result)
+	}
+	tw.Flush()
+
+	if failed != 0 {
+		return errors.Errorf("%d of %d hosts failed to join", failed, len(inventory.Hosts))
+	}
+	return nil
+}
+
+// joinHost uploads yurtadm to host and runs yurtadm join on it, which runs preflight checks,
+// installs binaries and joins the host into cluster.
+func joinHost(args []string, flags *flag.FlagSet, h *InventoryHost, w io.Writer) error {
+	binary := h.YurtadmBinary
+	if len(binary) == 0 {
+		self, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		binary = self
+	}
+
+	fmt.Fprintf(w, "connecting as %s\n", h.User)
+	conn, err := dialHost(h)
+	if err != nil {
+		return errors.Wrap(err, "could not connect")
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(w, "uploading yurtadm from %s\n", binary)
+	f, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := conn.Upload(f, remoteYurtadmPath, 0755); err != nil {
+		return err
+	}
+
+	if jc := flags.Lookup(yurtconstants.JoinConfig); jc != nil && jc.Changed {
+		fmt.Fprintf(w, "uploading join configuration from %s\n", jc.Value.String())
+		cfg, err := os.Open(jc.Value.String())
+		if err != nil {
+			return err
+		}
+		defer cfg.Close()
+		if err := conn.Upload(cfg, remoteJoinConfigPath, 0600); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "running yurtadm join\n")
+	return conn.Run(joinCommand(args, flags, h), w, w)
+}
+
+// prefixWriter writes lines into out with prefix, and lines of different writers
+// sharing the same lock are not interleaved.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	lock   *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			// keep the incomplete line for next write
+			w.buf.Write(line)
+			break
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the incomplete line in buffer.
+func (w *prefixWriter) Flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.buf.Len() != 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf.String())
+		w.buf.Reset()
+	}
+}
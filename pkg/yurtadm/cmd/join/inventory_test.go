@@ -0,0 +1,268 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+func writeInventory(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("could not write inventory, %v", err)
+	}
+	return path
+}
+
+func TestLoadInventory(t *testing.T) {
+	testcases := map[string]struct {
+		content string
+		hosts   []InventoryHost
+		isErr   bool
+	}{
+		"hosts with defaults": {
+			content: `
+defaults:
+  user: ubuntu
+  privateKeyFile: ~/.ssh/id_rsa
+  nodePoolName: hangzhou
+hosts:
+- address: 10.0.0.1
+  nodeName: edge-1
+- address: 10.0.0.2
+  user: root
+  port: 2222
+  nodePoolName: beijing
+`,
+			hosts: []InventoryHost{
+				{Address: "10.0.0.1", User: "ubuntu", PrivateKeyFile: "~/.ssh/id_rsa", NodeName: "edge-1", NodePoolName: "hangzhou"},
+				{Address: "10.0.0.2", Port: 2222, User: "root", PrivateKeyFile: "~/.ssh/id_rsa", NodePoolName: "beijing"},
+			},
+		},
+		"user is root by default": {
+			content: `
+hosts:
+- address: 10.0.0.1
+  password: secret
+`,
+			hosts: []InventoryHost{
+				{Address: "10.0.0.1", User: "root", Password: "secret"},
+			},
+		},
+		"no hosts": {
+			content: `defaults: {}`,
+			isErr:   true,
+		},
+		"unknown field": {
+			content: `
+hosts:
+- address: 10.0.0.1
+  unknown: value
+`,
+			isErr: true,
+		},
+		"empty address": {
+			content: `
+hosts:
+- nodeName: edge-1
+`,
+			isErr: true,
+		},
+		"duplicated address": {
+			content: `
+hosts:
+- address: 10.0.0.1
+- address: 10.0.0.1
+`,
+			isErr: true,
+		},
+		"duplicated node name": {
+			content: `
+hosts:
+- address: 10.0.0.1
+  nodeName: edge-1
+- address: 10.0.0.2
+  nodeName: edge-1
+`,
+			isErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			inventory, err := loadInventory(writeInventory(t, tc.content))
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(inventory.Hosts) != len(tc.hosts) {
+				t.Fatalf("expect %d hosts, but got %d", len(tc.hosts), len(inventory.Hosts))
+			}
+			for i := range tc.hosts {
+				if inventory.Hosts[i] != tc.hosts[i] {
+					t.Errorf("expect host %+v, but got %+v", tc.hosts[i], inventory.Hosts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJoinCommand(t *testing.T) {
+	opt := newJoinOptions()
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	addJoinConfigFlags(fs, opt)
+	if err := fs.Parse([]string{
+		"--token=abcdef.0123456789abcdef",
+		"--discovery-token-ca-cert-hash=sha256:aaa,sha256:bbb",
+		"--nodepool-name=default-pool",
+		"--node-labels=a=b c",
+		"--inventory=hosts.yaml",
+		"--inventory-parallelism=5",
+		"--join-config=/root/join.yaml",
+	}); err != nil {
+		t.Fatalf("could not parse flags, %v", err)
+	}
+
+	testcases := map[string]struct {
+		host   InventoryHost
+		expect string
+	}{
+		"root user": {
+			host: InventoryHost{Address: "10.0.0.1", User: "root", NodeName: "edge-1"},
+			expect: "/tmp/yurtadm join 1.2.3.4:6443 --discovery-token-ca-cert-hash=sha256:aaa,sha256:bbb " +
+				"--join-config=/tmp/yurtadm-join-config.yaml '--node-labels=a=b c' --node-name=edge-1 --nodepool-name=default-pool --token=abcdef.0123456789abcdef",
+		},
+		"non-root user with node pool": {
+			host: InventoryHost{Address: "10.0.0.2", User: "ubuntu", NodePoolName: "hangzhou", NodeLabels: "x=y"},
+			expect: "sudo -n /tmp/yurtadm join 1.2.3.4:6443 --discovery-token-ca-cert-hash=sha256:aaa,sha256:bbb " +
+				"--join-config=/tmp/yurtadm-join-config.yaml --node-labels=x=y --nodepool-name=hangzhou --token=abcdef.0123456789abcdef",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if cmd := joinCommand([]string{"1.2.3.4:6443"}, fs, &tc.host); cmd != tc.expect {
+				t.Errorf("expect command\n%s\nbut got\n%s", tc.expect, cmd)
+			}
+		})
+	}
+}
+
+type fakeRemoteHost struct {
+	address string
+	runErr  error
+
+	lock     *sync.Mutex
+	commands map[string]string
+}
+
+func (h *fakeRemoteHost) Run(cmd string, stdout, stderr io.Writer) error {
+	h.lock.Lock()
+	h.commands[h.address] = cmd
+	h.lock.Unlock()
+	fmt.Fprintf(stdout, "[preflight] Running pre-flight checks\npartial line")
+	return h.runErr
+}
+
+func (h *fakeRemoteHost) Upload(src io.Reader, dst string, mode os.FileMode) error {
+	_, err := io.Copy(io.Discard, src)
+	return err
+}
+
+func (h *fakeRemoteHost) Close() error {
+	return nil
+}
+
+func TestRunBatchJoin(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yurtadm")
+	if err := os.WriteFile(binary, []byte("binary"), 0755); err != nil {
+		t.Fatalf("could not write binary, %v", err)
+	}
+	inventory := writeInventory(t, fmt.Sprintf(`
+defaults:
+  yurtadmBinary: %s
+hosts:
+- address: 10.0.0.1
+  nodeName: edge-1
+- address: 10.0.0.2
+  nodeName: edge-2
+- address: 10.0.0.3
+  nodeName: edge-3
+`, binary))
+
+	var lock sync.Mutex
+	commands := make(map[string]string)
+	oldDialHost := dialHost
+	defer func() { dialHost = oldDialHost }()
+	dialHost = func(h *InventoryHost) (remoteHost, error) {
+		switch h.Address {
+		case "10.0.0.2":
+			return nil, errors.New("connection refused")
+		case "10.0.0.3":
+			return &fakeRemoteHost{address: h.Address, runErr: errors.New("exit status 1"), lock: &lock, commands: commands}, nil
+		}
+		return &fakeRemoteHost{address: h.Address, lock: &lock, commands: commands}, nil
+	}
+
+	opt := newJoinOptions()
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	addJoinConfigFlags(fs, opt)
+	if err := fs.Parse([]string{"--token=abcdef.0123456789abcdef", "--inventory=" + inventory, "--inventory-parallelism=2"}); err != nil {
+		t.Fatalf("could not parse flags, %v", err)
+	}
+
+	var out bytes.Buffer
+	err := runBatchJoin([]string{"1.2.3.4:6443"}, opt, fs, &out)
+	if err == nil || err.Error() != "2 of 3 hosts failed to join" {
+		t.Fatalf("expect 2 hosts failed, but got %v", err)
+	}
+
+	if len(commands) != 2 || !strings.Contains(commands["10.0.0.1"], "--node-name=edge-1") {
+		t.Errorf("unexpected commands %v", commands)
+	}
+	for _, expect := range []string{
+		"[10.0.0.1] [preflight] Running pre-flight checks\n",
+		"[10.0.0.1] partial line\n[10.0.0.1] join succeeded\n",
+		"[10.0.0.2] join failed: could not connect: connection refused\n",
+		"[10.0.0.3] join failed: exit status 1\n",
+	} {
+		if !strings.Contains(out.String(), expect) {
+			t.Errorf("expect output contains %q, but got\n%s", expect, out.String())
+		}
+	}
+	for _, expect := range []string{
+		"10.0.0.1  edge-1  succeeded",
+		"10.0.0.2  edge-2  failed: could not connect: connection refused",
+		"10.0.0.3  edge-3  failed: exit status 1",
+	} {
+		if !strings.Contains(out.String(), expect) {
+			t.Errorf("expect summary contains %q, but got\n%s", expect, out.String())
+		}
+	}
+}
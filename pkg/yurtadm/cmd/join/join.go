@@ -20,28 +20,45 @@ package join
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
 	clientset "k8s.io/client-go/kubernetes"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
 	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
 	"github.com/openyurtio/openyurt/pkg/util/kubernetes/kubeadm/app/util/apiclient"
+	"github.com/openyurtio/openyurt/pkg/util/poolcreation"
+	"github.com/openyurtio/openyurt/pkg/util/token"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joinconfig"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	yurtphases "github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/phases"
 	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
 	yurtadmutil "github.com/openyurtio/openyurt/pkg/yurtadm/util/kubernetes"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/publicip"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
+	hubutil "github.com/openyurtio/openyurt/pkg/yurthub/util"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
 )
 
+const (
+	apiServerCheckTimeout = 10 * time.Second
+)
+
 type joinOptions struct {
 	cfgPath                  string
 	token                    string
@@ -61,6 +78,24 @@ type joinOptions struct {
 	yurthubServer            string
 	reuseCNIBin              bool
 	staticPods               string
+	offlineBundle            string
+	distro                   string
+	gpuPreflight             bool
+	minNvidiaDriverVersion   string
+	publicIP                 string
+	stunServers              []string
+	hubLeaderAddr            string
+	tokenExchangeServer      string
+	tokenExchangeCAFile      string
+	identityTokenFile        string
+	instanceIdentityDocument string
+	instanceIdentitySig      string
+	createNodePoolFrom       string
+	nodePoolCreationServer   string
+	nodePoolCreationCAFile   string
+	inventory                string
+	inventoryParallelism     int
+	joinConfig               string
 }
 
 // newJoinOptions returns a struct ready for being used for creating cmd join flags.
@@ -77,6 +112,10 @@ func newJoinOptions() *joinOptions {
 		kubernetesResourceServer: yurtconstants.DefaultKubernetesResourceServer,
 		yurthubServer:            yurtconstants.DefaultYurtHubServerAddr,
 		reuseCNIBin:              false,
+		stunServers:              []string{publicip.DefaultSTUNServer},
+		distro:                   distro.Kubeadm,
+		minNvidiaDriverVersion:   preflight.DefaultMinNvidiaDriverVersion,
+		inventoryParallelism:     defaultInventoryParallelism,
 	}
 }
 
@@ -95,6 +134,18 @@ func NewCmdJoin(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
 		Use:   "join [api-server-endpoint]",
 		Short: "Run this on any machine you wish to join an existing cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(joinOptions.joinConfig) != 0 {
+				cfg, err := joinconfig.Load(joinOptions.joinConfig)
+				if err != nil {
+					return err
+				}
+				args = applyJoinConfiguration(cfg, joinOptions, cmd.Flags(), args)
+			}
+
+			if len(joinOptions.inventory) != 0 {
+				return runBatchJoin(args, joinOptions, cmd.Flags(), out)
+			}
+
 			o, err := newJoinData(args, joinOptions)
 			if err != nil {
 				return err
@@ -118,6 +169,11 @@ func addJoinConfigFlags(flagSet *flag.FlagSet, joinOptions *joinOptions) {
 	flagSet.StringVar(
 		&joinOptions.cfgPath, yurtconstants.CfgPath, "", "Path to a joinConfiguration file.",
 	)
+	flagSet.StringVar(
+		&joinOptions.joinConfig, yurtconstants.JoinConfig, "",
+		"Path to a yurtadm JoinConfiguration file, flags specified in command line take precedence over the values in it. "+
+			"the default configuration can be printed by 'yurtadm config print-join-defaults'",
+	)
 	flagSet.StringVar(
 		&joinOptions.token, yurtconstants.TokenStr, "",
 		"Use this token for both discovery-token and tls-bootstrap-token when those values are not provided.",
@@ -186,6 +242,82 @@ func addJoinConfigFlags(flagSet *flag.FlagSet, joinOptions *joinOptions) {
 		&joinOptions.staticPods, yurtconstants.StaticPods, joinOptions.staticPods,
 		"Set the specified static pods on this node want to install",
 	)
+	flagSet.StringVar(
+		&joinOptions.offlineBundle, yurtconstants.OfflineBundle, joinOptions.offlineBundle,
+		"Path to the offline bundle(tar.gz) which contains kubelet/kubeadm binaries, CNI plugins, images and yurthub cache, "+
+			"nothing is downloaded from the internet when it's specified",
+	)
+	flagSet.StringVar(
+		&joinOptions.distro, yurtconstants.Distro, joinOptions.distro,
+		"The kubernetes distro of node(kubeadm, k3s, rke2 or auto), k3s and rke2 agents should be installed and joined before, "+
+			"and they are configured to connect to kube-apiserver through yurthub. auto means detecting it from the agent services",
+	)
+	flagSet.BoolVar(
+		&joinOptions.gpuPreflight, yurtconstants.GPUPreflight, joinOptions.gpuPreflight,
+		"Check the nvidia driver, nvidia-container-toolkit and gpu devices before joining a gpu pool, "+
+			"the gpu and accelerator labels are added into node if the check is passed",
+	)
+	flagSet.StringVar(
+		&joinOptions.minNvidiaDriverVersion, yurtconstants.MinNvidiaDriverVersion, joinOptions.minNvidiaDriverVersion,
+		"The minimum nvidia driver version required by the gpu preflight",
+	)
+	flagSet.StringVar(
+		&joinOptions.publicIP, yurtconstants.PublicIP, joinOptions.publicIP,
+		"Sets the public ip of node for raven tunnel endpoint, \"auto\" means detecting it by cloud metadata services and stun servers",
+	)
+	flagSet.StringSliceVar(
+		&joinOptions.stunServers, yurtconstants.STUNServers, joinOptions.stunServers,
+		"The stun servers which are used to detect the public ip of node when --public-ip=auto",
+	)
+	flagSet.StringVar(
+		&joinOptions.hubLeaderAddr, yurtconstants.HubLeaderAddr, joinOptions.hubLeaderAddr,
+		fmt.Sprintf("The address(host or host:port) of join info server(--join-info-server-addr of yurthub) on the hub leader node in the nodepool, "+
+			"port %d is used if it's not specified. the node is prepared with the join info cached by it when kube-apiserver is unreachable, "+
+			"and it's registered if kube-apiserver is reachable after the preparation", hubutil.YurtHubJoinInfoPort),
+	)
+	flagSet.StringVar(
+		&joinOptions.tokenExchangeServer, yurtconstants.TokenExchangeServer, joinOptions.tokenExchangeServer,
+		"The address(https://host:port) of token exchange service in yurt-manager, when --token is not specified, "+
+			"the identity of node is exchanged for a short-lived bootstrap token by it",
+	)
+	flagSet.StringVar(
+		&joinOptions.tokenExchangeCAFile, yurtconstants.TokenExchangeCAFile, joinOptions.tokenExchangeCAFile,
+		"The ca file which is used to verify the certificate of token exchange service, system roots are used if it's not specified",
+	)
+	flagSet.StringVar(
+		&joinOptions.identityTokenFile, yurtconstants.IdentityTokenFile, joinOptions.identityTokenFile,
+		"Path to the file of OIDC id token which is exchanged for a bootstrap token",
+	)
+	flagSet.StringVar(
+		&joinOptions.instanceIdentityDocument, yurtconstants.InstanceIdentityDocument, joinOptions.instanceIdentityDocument,
+		"Path to the file of cloud instance identity document which is exchanged for a bootstrap token, the node should be named after the instance id",
+	)
+	flagSet.StringVar(
+		&joinOptions.instanceIdentitySig, yurtconstants.InstanceIdentitySignature, joinOptions.instanceIdentitySig,
+		"Path to the file of base64 encoded signature of cloud instance identity document",
+	)
+	flagSet.StringVar(
+		&joinOptions.createNodePoolFrom, yurtconstants.CreateNodePoolFrom, joinOptions.createNodePoolFrom,
+		"The nodepool template configured in yurt-manager, the nodepool specified by --nodepool-name is created from it "+
+			"if the nodepool doesn't exist",
+	)
+	flagSet.StringVar(
+		&joinOptions.nodePoolCreationServer, yurtconstants.NodePoolCreationServer, joinOptions.nodePoolCreationServer,
+		"The address(https://host:port) of nodepool creation service in yurt-manager, --token-exchange-server is used if it's not specified",
+	)
+	flagSet.StringVar(
+		&joinOptions.nodePoolCreationCAFile, yurtconstants.NodePoolCreationCAFile, joinOptions.nodePoolCreationCAFile,
+		"The ca file which is used to verify the certificate of nodepool creation service, --token-exchange-ca-file is used if it's not specified",
+	)
+	flagSet.StringVar(
+		&joinOptions.inventory, yurtconstants.Inventory, joinOptions.inventory,
+		"Path to the inventory file(yaml) of hosts, when it's specified, the hosts are joined by ssh in parallel with the other flags, "+
+			"and file paths in the other flags refer to files on the hosts except --join-config which is uploaded to the hosts",
+	)
+	flagSet.IntVar(
+		&joinOptions.inventoryParallelism, yurtconstants.InventoryParallelism, joinOptions.inventoryParallelism,
+		"How many hosts in the inventory are joined at the same time",
+	)
 }
 
 func newJoinerWithJoinData(o *joinData, in io.Reader, out io.Writer, outErr io.Writer) *nodeJoiner {
@@ -201,10 +333,21 @@ func newJoinerWithJoinData(o *joinData, in io.Reader, out io.Writer, outErr io.W
 func (nodeJoiner *nodeJoiner) Run() error {
 	joinData := nodeJoiner.joinData
 
+	if err := yurtphases.RunPreflight(joinData); err != nil {
+		return err
+	}
+
 	if err := yurtphases.RunPrepare(joinData); err != nil {
 		return err
 	}
 
+	// node prepared with the join info from hub leader can only be registered when kube-apiserver is reachable,
+	// kubeadm join waits for kube-apiserver for minutes, so it fails fast here.
+	if joinData.registrationDeferred && !yurtadmutil.IsAPIServerReachable(joinData.ServerAddr(), apiServerCheckTimeout) {
+		return errors.Errorf("node is prepared with the join info from hub leader, but it can't be registered because kube-apiserver %s is unreachable, "+
+			"please run yurtadm join again when kube-apiserver is reachable", joinData.ServerAddr())
+	}
+
 	if err := yurtphases.RunJoinNode(joinData, nodeJoiner.outWriter, nodeJoiner.outErrWriter); err != nil {
 		return err
 	}
@@ -238,6 +381,29 @@ type joinData struct {
 	namespace                string
 	staticPodTemplateList    []string
 	staticPodManifestList    []string
+	offlineBundle            string
+	registrationDeferred     bool
+	distro                   string
+	gpuPreflight             bool
+	minNvidiaDriverVersion   string
+}
+
+// checkWindowsJoin checks the options which are supported by windows worker node, and sets the cri socket
+// to containerd if it's not specified because dockershim is not supported on windows.
+func checkWindowsJoin(opt *joinOptions, nodeDistro string) error {
+	if nodeDistro != distro.Kubeadm {
+		return errors.Errorf("distro(%s) is not supported on windows node, only kubeadm is supported", nodeDistro)
+	}
+	if len(opt.offlineBundle) != 0 {
+		return errors.Errorf("--%s is not supported on windows node", yurtconstants.OfflineBundle)
+	}
+	if opt.gpuPreflight {
+		return errors.Errorf("--%s is not supported on windows node", yurtconstants.GPUPreflight)
+	}
+	if opt.criSocket == yurtconstants.DefaultDockerCRISocket {
+		opt.criSocket = windows.DefaultCRISocket
+	}
+	return nil
 }
 
 // newJoinData returns a new joinData struct to be used for the execution of the kubeadm join workflow.
@@ -257,6 +423,25 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 		apiServerEndpoint = args[0]
 	}
 
+	if len(opt.token) == 0 && len(opt.tokenExchangeServer) != 0 {
+		if err := exchangeToken(opt); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opt.createNodePoolFrom) != 0 {
+		if len(opt.nodePoolName) == 0 {
+			return nil, errors.Errorf("--%s should be specified with --%s", yurtconstants.NodePoolName, yurtconstants.CreateNodePoolFrom)
+		}
+		if len(opt.nodePoolCreationServer) == 0 {
+			opt.nodePoolCreationServer, opt.nodePoolCreationCAFile = opt.tokenExchangeServer, opt.tokenExchangeCAFile
+		}
+		if len(opt.nodePoolCreationServer) == 0 {
+			return nil, errors.Errorf("--%s or --%s should be specified with --%s", yurtconstants.NodePoolCreationServer,
+				yurtconstants.TokenExchangeServer, yurtconstants.CreateNodePoolFrom)
+		}
+	}
+
 	if len(opt.token) == 0 {
 		return nil, errors.New("join token is empty, so unable to bootstrap worker node.")
 	}
@@ -275,6 +460,35 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 		return nil, errors.Errorf("when --discovery-token-ca-cert-hash is not specified, --discovery-token-unsafe-skip-ca-verification should be true")
 	}
 
+	if opt.gpuPreflight && len(opt.minNvidiaDriverVersion) != 0 {
+		if _, err := version.ParseGeneric(opt.minNvidiaDriverVersion); err != nil {
+			return nil, errors.Wrapf(err, "--%s is invalid", yurtconstants.MinNvidiaDriverVersion)
+		}
+	}
+
+	if !distro.IsValid(opt.distro) {
+		return nil, errors.Errorf("distro(%s) is invalid, only \"kubeadm, k3s, rke2 and auto\" are supported", opt.distro)
+	}
+	nodeDistro := opt.distro
+	if nodeDistro == distro.Auto {
+		nodeDistro = distro.Detect("/")
+		klog.Infof("distro %s is detected", nodeDistro)
+	}
+	if distro.GetAgent(nodeDistro) != nil && len(opt.offlineBundle) != 0 {
+		return nil, errors.Errorf("--%s is not supported for %s, binaries are installed by its agent", yurtconstants.OfflineBundle, nodeDistro)
+	}
+	if windows.IsWindows() {
+		if err := checkWindowsJoin(opt, nodeDistro); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opt.offlineBundle) != 0 {
+		if _, err := os.Stat(opt.offlineBundle); err != nil {
+			return nil, errors.Errorf("offline bundle %s is invalid, %v", opt.offlineBundle, err)
+		}
+	}
+
 	ignoreErrors := sets.String{}
 	for i := range opt.ignorePreflightErrors {
 		ignoreErrors.Insert(opt.ignorePreflightErrors[i])
@@ -309,6 +523,10 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 		kubernetesResourceServer: opt.kubernetesResourceServer,
 		reuseCNIBin:              opt.reuseCNIBin,
 		namespace:                opt.namespace,
+		offlineBundle:            opt.offlineBundle,
+		distro:                   nodeDistro,
+		gpuPreflight:             opt.gpuPreflight,
+		minNvidiaDriverVersion:   opt.minNvidiaDriverVersion,
 	}
 
 	// parse node labels
@@ -324,6 +542,26 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 		}
 	}
 
+	// label node with public ip, so it can be elected as tunnel endpoint of raven gateway once registered
+	if len(opt.publicIP) != 0 {
+		ip, err := publicip.NewDetector(opt.stunServers).Resolve(opt.publicIP)
+		if err != nil {
+			return nil, errors.Wrapf(err, "--%s is invalid", yurtconstants.PublicIP)
+		}
+		data.nodeLabels[apps.NodePublicIPLabel] = ip
+		data.nodeLabels[raven.LabelEndpointCandidate] = "true"
+	}
+
+	// bootstrap node with the join info cached by the hub leader in nodepool if kube-apiserver is unreachable
+	if len(opt.hubLeaderAddr) != 0 && !yurtadmutil.IsAPIServerReachable(apiServerEndpoint, apiServerCheckTimeout) {
+		klog.Warningf("kube-apiserver %s is unreachable, bootstrap node from hub leader %s", apiServerEndpoint, opt.hubLeaderAddr)
+		if err := data.bootstrapFromHubLeader(opt); err != nil {
+			return nil, err
+		}
+		klog.Infof("node join data info: %#+v", *data)
+		return data, nil
+	}
+
 	// get tls bootstrap config
 	cfg, err := yurtadmutil.RetrieveBootstrapConfig(data)
 	if err != nil {
@@ -350,6 +588,11 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 	// check whether specified nodePool exists
 	if len(opt.nodePoolName) != 0 {
 		np, err := apiclient.GetNodePoolInfoWithRetry(cfg, opt.nodePoolName)
+		if err == nil && np == nil && len(opt.createNodePoolFrom) != 0 {
+			if np, err = createNodePool(cfg, opt); err != nil {
+				return nil, err
+			}
+		}
 		if err != nil || np == nil {
 			// the specified nodePool not exist, return
 			return nil, errors.Errorf("when --nodepool-name is specified, the specified nodePool should be exist.")
@@ -411,6 +654,93 @@ func newJoinData(args []string, opt *joinOptions) (*joinData, error) {
 	return data, nil
 }
 
+// exchangeToken exchanges the OIDC id token or cloud instance identity document of node for
+// a short-lived bootstrap token from the token exchange service in yurt-manager.
+func exchangeToken(opt *joinOptions) error {
+	req := &token.ExchangeRequest{NodeName: opt.nodeName}
+	switch {
+	case len(opt.identityTokenFile) != 0:
+		idToken, err := os.ReadFile(opt.identityTokenFile)
+		if err != nil {
+			return errors.Wrapf(err, "could not read id token file %s", opt.identityTokenFile)
+		}
+		req.Type = token.IdentityTypeOIDC
+		req.Token = strings.TrimSpace(string(idToken))
+	case len(opt.instanceIdentityDocument) != 0 && len(opt.instanceIdentitySig) != 0:
+		doc, err := os.ReadFile(opt.instanceIdentityDocument)
+		if err != nil {
+			return errors.Wrapf(err, "could not read instance identity document %s", opt.instanceIdentityDocument)
+		}
+		sig, err := os.ReadFile(opt.instanceIdentitySig)
+		if err != nil {
+			return errors.Wrapf(err, "could not read instance identity signature %s", opt.instanceIdentitySig)
+		}
+		req.Type = token.IdentityTypeInstanceIdentity
+		req.Document = string(doc)
+		req.Signature = strings.Join(strings.Fields(string(sig)), "")
+	default:
+		return errors.Errorf("--%s or --%s and --%s should be specified with --%s", yurtconstants.IdentityTokenFile,
+			yurtconstants.InstanceIdentityDocument, yurtconstants.InstanceIdentitySignature, yurtconstants.TokenExchangeServer)
+	}
+
+	resp, err := token.Exchange(opt.tokenExchangeServer, opt.tokenExchangeCAFile, req)
+	if err != nil {
+		return errors.Wrapf(err, "could not exchange %s identity for bootstrap token", req.Type)
+	}
+	klog.Infof("bootstrap token is exchanged from %s, expires at %s", opt.tokenExchangeServer, resp.Expiration.Format(time.RFC3339))
+	opt.token = resp.Token
+	return nil
+}
+
+// createNodePool creates the nodepool from template by the nodepool creation service in yurt-manager, the request
+// is authenticated by the bootstrap token, because the token is not allowed to create nodepools in kube-apiserver.
+func createNodePool(cfg *clientcmdapi.Config, opt *joinOptions) (*v1beta1.NodePool, error) {
+	req := &poolcreation.CreationRequest{
+		Name:     opt.nodePoolName,
+		Template: opt.createNodePoolFrom,
+		NodeName: opt.nodeName,
+	}
+	resp, err := poolcreation.Create(opt.nodePoolCreationServer, opt.nodePoolCreationCAFile, opt.token, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create nodepool %s from template %s", opt.nodePoolName, opt.createNodePoolFrom)
+	}
+	if resp.Created {
+		klog.Infof("nodepool %s is created from template %s", opt.nodePoolName, opt.createNodePoolFrom)
+	}
+	return apiclient.GetNodePoolInfoWithRetry(cfg, opt.nodePoolName)
+}
+
+// bootstrapFromHubLeader fills joinData with the join info cached by the hub leader, the resources
+// which can only be got from kube-apiserver are not supported, and hard-code yurthub manifest is used.
+func (j *joinData) bootstrapFromHubLeader(opt *joinOptions) error {
+	if len(opt.staticPods) != 0 {
+		return errors.Errorf("--%s is not supported when node is bootstrapped from hub leader", yurtconstants.StaticPods)
+	}
+
+	info, err := yurthub.GetJoinInfo(opt.hubLeaderAddr)
+	if err != nil {
+		return errors.Wrapf(err, "could not get join info from hub leader %s", opt.hubLeaderAddr)
+	}
+	cfg, err := yurtadmutil.RetrieveBootstrapConfigFromJoinInfo(j, info)
+	if err != nil {
+		return errors.Wrapf(err, "join info from hub leader %s is invalid", opt.hubLeaderAddr)
+	}
+	if info.Version == nil || len(info.Version.GitVersion) == 0 {
+		return errors.Errorf("kubernetes version is not found in join info from hub leader %s", opt.hubLeaderAddr)
+	}
+
+	j.tlsBootstrapCfg = cfg
+	j.kubernetesVersion = info.Version.GitVersion
+	j.registrationDeferred = true
+	if len(opt.nodePoolName) != 0 {
+		klog.Warningf("nodepool %s is not checked because kube-apiserver is unreachable", opt.nodePoolName)
+		j.nodeLabels[apps.NodePoolLabel] = opt.nodePoolName
+	}
+	j.yurthubManifest = yurtconstants.YurthubStaticPodManifest
+	j.yurthubTemplate = yurtconstants.YurthubTemplate
+	return nil
+}
+
 // CfgPath returns path to a joinConfiguration file.
 func (j *joinData) CfgPath() string {
 	return j.cfgPath
@@ -501,3 +831,29 @@ func (j *joinData) StaticPodTemplateList() []string {
 func (j *joinData) StaticPodManifestList() []string {
 	return j.staticPodManifestList
 }
+
+// OfflineBundle returns the path of offline bundle, it's empty if node is not joined offline.
+func (j *joinData) OfflineBundle() string {
+	return j.offlineBundle
+}
+
+// Distro returns the kubernetes distro of node, like kubeadm, k3s and rke2.
+func (j *joinData) Distro() string {
+	return j.distro
+}
+
+// GPUPreflight returns whether the gpu environment of node is checked before joining.
+func (j *joinData) GPUPreflight() bool {
+	return j.gpuPreflight
+}
+
+// MinNvidiaDriverVersion returns the minimum nvidia driver version required by the gpu preflight.
+func (j *joinData) MinNvidiaDriverVersion() string {
+	return j.minNvidiaDriverVersion
+}
+
+// RegistrationDeferred returns whether node is bootstrapped from the hub leader and registered
+// until kube-apiserver is reachable.
+func (j *joinData) RegistrationDeferred() bool {
+	return j.registrationDeferred
+}
@@ -30,8 +30,12 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/publicip"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
 )
 
 const (
@@ -58,6 +62,10 @@ func TestNewJoinOptions(t *testing.T) {
 				kubernetesResourceServer: yurtconstants.DefaultKubernetesResourceServer,
 				yurthubServer:            yurtconstants.DefaultYurtHubServerAddr,
 				reuseCNIBin:              false,
+				stunServers:              []string{publicip.DefaultSTUNServer},
+				distro:                   distro.Kubeadm,
+				minNvidiaDriverVersion:   preflight.DefaultMinNvidiaDriverVersion,
+				inventoryParallelism:     defaultInventoryParallelism,
 			},
 		},
 	}
@@ -239,6 +247,23 @@ func TestNewJoinData(t *testing.T) {
 	jo2.token = "v22u0b.17490yh3xp8azpr0"
 	jo2.unsafeSkipCAVerification = true
 	jo2.nodePoolName = "nodePool2"
+	jo3 := newJoinOptions()
+	jo3.token = "v22u0b.17490yh3xp8azpr0"
+	jo3.unsafeSkipCAVerification = true
+	jo3.publicIP = "invalid-ip"
+	jo4 := newJoinOptions()
+	jo4.token = "v22u0b.17490yh3xp8azpr0"
+	jo4.unsafeSkipCAVerification = true
+	jo4.distro = "microk8s"
+	jo5 := newJoinOptions()
+	jo5.token = "v22u0b.17490yh3xp8azpr0"
+	jo5.unsafeSkipCAVerification = true
+	jo5.gpuPreflight = true
+	jo5.minNvidiaDriverVersion = "latest"
+	jo6 := newJoinOptions()
+	jo6.token = "v22u0b.17490yh3xp8azpr0"
+	jo6.unsafeSkipCAVerification = true
+	jo6.createNodePoolFrom = "edge-site"
 
 	tests := []struct {
 		name   string
@@ -258,6 +283,30 @@ func TestNewJoinData(t *testing.T) {
 			jo2,
 			nil,
 		},
+		{
+			"invalid public ip",
+			[]string{"localhost:8080"},
+			jo3,
+			nil,
+		},
+		{
+			"invalid distro",
+			[]string{"localhost:8080"},
+			jo4,
+			nil,
+		},
+		{
+			"invalid min nvidia driver version",
+			[]string{"localhost:8080"},
+			jo5,
+			nil,
+		},
+		{
+			"nodepool template without nodepool name",
+			[]string{"localhost:8080"},
+			jo6,
+			nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -276,6 +325,55 @@ func TestNewJoinData(t *testing.T) {
 	}
 }
 
+func TestCheckWindowsJoin(t *testing.T) {
+	tests := []struct {
+		name      string
+		modify    func(o *joinOptions)
+		distro    string
+		criSocket string
+		expectErr bool
+	}{
+		{
+			name:      "docker socket is replaced by containerd",
+			distro:    distro.Kubeadm,
+			criSocket: windows.DefaultCRISocket,
+		},
+		{
+			name:      "specified cri socket is kept",
+			modify:    func(o *joinOptions) { o.criSocket = "npipe:////./pipe/cri-dockerd" },
+			distro:    distro.Kubeadm,
+			criSocket: "npipe:////./pipe/cri-dockerd",
+		},
+		{
+			name:      "k3s is not supported",
+			distro:    distro.K3s,
+			expectErr: true,
+		},
+		{
+			name:      "offline bundle is not supported",
+			modify:    func(o *joinOptions) { o.offlineBundle = "bundle.tar.gz" },
+			distro:    distro.Kubeadm,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newJoinOptions()
+			if tt.modify != nil {
+				tt.modify(o)
+			}
+			err := checkWindowsJoin(o, tt.distro)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("\t%s\texpect error %v, but get %v", failed, tt.expectErr, err)
+			}
+			if err == nil && o.criSocket != tt.criSocket {
+				t.Errorf("\t%s\texpect cri socket %s, but get %s", failed, tt.criSocket, o.criSocket)
+			}
+		})
+	}
+}
+
 func TestServerAddr(t *testing.T) {
 	jd := joinData{
 		apiServerEndpoint: "192.168.1.1",
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joinconfig"
+	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+)
+
+// DefaultJoinConfiguration returns the JoinConfiguration with default values of 'yurtadm join' flags.
+func DefaultJoinConfiguration() *joinconfig.JoinConfiguration {
+	opt := newJoinOptions()
+	return &joinconfig.JoinConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: joinconfig.APIVersion,
+			Kind:       joinconfig.Kind,
+		},
+		Discovery: joinconfig.Discovery{
+			Token:                    yurtconstants.PlaceholderToken,
+			CACertHashes:             opt.caCertHashes,
+			UnsafeSkipCAVerification: &opt.unsafeSkipCAVerification,
+		},
+		Node: joinconfig.Node{
+			Type:                   opt.nodeType,
+			IgnorePreflightErrors:  opt.ignorePreflightErrors,
+			GPUPreflight:           &opt.gpuPreflight,
+			MinNvidiaDriverVersion: opt.minNvidiaDriverVersion,
+		},
+		YurtHub: joinconfig.YurtHub{
+			Image:     opt.yurthubImage,
+			Server:    opt.yurthubServer,
+			Namespace: opt.namespace,
+		},
+		Raven: joinconfig.Raven{
+			STUNServers: opt.stunServers,
+		},
+		Runtime: joinconfig.Runtime{
+			Distro:                   opt.distro,
+			CRISocket:                opt.criSocket,
+			PauseImage:               opt.pauseImage,
+			ReuseCNIBin:              &opt.reuseCNIBin,
+			KubernetesResourceServer: opt.kubernetesResourceServer,
+		},
+	}
+}
+
+// applyJoinConfiguration fills joinOptions with the values in JoinConfiguration, the flags which are
+// specified in command line and the fields which are not set in JoinConfiguration are skipped.
+// the api server endpoint in JoinConfiguration is returned as args if no endpoint is specified in args.
+func applyJoinConfiguration(cfg *joinconfig.JoinConfiguration, opt *joinOptions, flags *flag.FlagSet, args []string) []string {
+	setString := func(name string, v *string, value string) {
+		if len(value) != 0 && !flags.Changed(name) {
+			*v = value
+		}
+	}
+	setSlice := func(name string, v *[]string, value []string) {
+		if value != nil && !flags.Changed(name) {
+			*v = value
+		}
+	}
+	setBool := func(name string, v *bool, value *bool) {
+		if value != nil && !flags.Changed(name) {
+			*v = *value
+		}
+	}
+
+	if len(args) == 0 && len(cfg.Discovery.APIServerEndpoint) != 0 {
+		args = []string{cfg.Discovery.APIServerEndpoint}
+	}
+	setString(yurtconstants.TokenStr, &opt.token, cfg.Discovery.Token)
+	setSlice(yurtconstants.TokenDiscoveryCAHash, &opt.caCertHashes, cfg.Discovery.CACertHashes)
+	setBool(yurtconstants.TokenDiscoverySkipCAHash, &opt.unsafeSkipCAVerification, cfg.Discovery.UnsafeSkipCAVerification)
+	setString(yurtconstants.HubLeaderAddr, &opt.hubLeaderAddr, cfg.Discovery.HubLeaderAddr)
+	if te := cfg.Discovery.TokenExchange; te != nil {
+		setString(yurtconstants.TokenExchangeServer, &opt.tokenExchangeServer, te.Server)
+		setString(yurtconstants.TokenExchangeCAFile, &opt.tokenExchangeCAFile, te.CAFile)
+		setString(yurtconstants.IdentityTokenFile, &opt.identityTokenFile, te.IdentityTokenFile)
+		setString(yurtconstants.InstanceIdentityDocument, &opt.instanceIdentityDocument, te.InstanceIdentityDocument)
+		setString(yurtconstants.InstanceIdentitySignature, &opt.instanceIdentitySig, te.InstanceIdentitySignature)
+	}
+
+	setString(yurtconstants.NodeName, &opt.nodeName, cfg.Node.Name)
+	setString(yurtconstants.NodeType, &opt.nodeType, cfg.Node.Type)
+	setString(yurtconstants.NodePoolName, &opt.nodePoolName, cfg.Node.NodePoolName)
+	if pc := cfg.Node.NodePoolCreation; pc != nil {
+		setString(yurtconstants.CreateNodePoolFrom, &opt.createNodePoolFrom, pc.Template)
+		setString(yurtconstants.NodePoolCreationServer, &opt.nodePoolCreationServer, pc.Server)
+		setString(yurtconstants.NodePoolCreationCAFile, &opt.nodePoolCreationCAFile, pc.CAFile)
+	}
+	setString(yurtconstants.NodeLabels, &opt.nodeLabels, formatLabels(cfg.Node.Labels))
+	setSlice(yurtconstants.IgnorePreflightErrors, &opt.ignorePreflightErrors, cfg.Node.IgnorePreflightErrors)
+	setBool(yurtconstants.GPUPreflight, &opt.gpuPreflight, cfg.Node.GPUPreflight)
+	setString(yurtconstants.MinNvidiaDriverVersion, &opt.minNvidiaDriverVersion, cfg.Node.MinNvidiaDriverVersion)
+
+	setString(yurtconstants.YurtHubImage, &opt.yurthubImage, cfg.YurtHub.Image)
+	setString(yurtconstants.YurtHubServerAddr, &opt.yurthubServer, cfg.YurtHub.Server)
+	setString(yurtconstants.Namespace, &opt.namespace, cfg.YurtHub.Namespace)
+	setString(yurtconstants.Organizations, &opt.organizations, cfg.YurtHub.Organizations)
+
+	setString(yurtconstants.PublicIP, &opt.publicIP, cfg.Raven.PublicIP)
+	setSlice(yurtconstants.STUNServers, &opt.stunServers, cfg.Raven.STUNServers)
+
+	setString(yurtconstants.Distro, &opt.distro, cfg.Runtime.Distro)
+	setString(yurtconstants.NodeCRISocket, &opt.criSocket, cfg.Runtime.CRISocket)
+	setString(yurtconstants.PauseImage, &opt.pauseImage, cfg.Runtime.PauseImage)
+	setBool(yurtconstants.ReuseCNIBin, &opt.reuseCNIBin, cfg.Runtime.ReuseCNIBin)
+	setString(yurtconstants.KubernetesResourceServer, &opt.kubernetesResourceServer, cfg.Runtime.KubernetesResourceServer)
+	setString(yurtconstants.OfflineBundle, &opt.offlineBundle, cfg.Runtime.OfflineBundle)
+	setString(yurtconstants.StaticPods, &opt.staticPods, cfg.Runtime.StaticPods)
+
+	return args
+}
+
+// formatLabels converts labels into the format of --node-labels, like k1=v1,k2=v2
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
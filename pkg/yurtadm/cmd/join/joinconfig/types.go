@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package joinconfig
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the api version of JoinConfiguration
+	APIVersion = "yurtadm.openyurt.io/v1alpha1"
+	// Kind is the kind of JoinConfiguration
+	Kind = "JoinConfiguration"
+)
+
+// JoinConfiguration contains the settings of 'yurtadm join', the fields which are not specified
+// keep the default values of flags, and flags specified in command line take precedence over them.
+type JoinConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Discovery specifies how the node finds and trusts the cluster.
+	Discovery Discovery `json:"discovery,omitempty"`
+	// Node specifies the registration of node.
+	Node Node `json:"node,omitempty"`
+	// YurtHub specifies the settings of yurthub on node.
+	YurtHub YurtHub `json:"yurthub,omitempty"`
+	// Raven specifies the settings of raven tunnel endpoint on node.
+	Raven Raven `json:"raven,omitempty"`
+	// Runtime specifies the container runtime and where the node components come from.
+	Runtime Runtime `json:"runtime,omitempty"`
+}
+
+// Discovery contains the settings for discovering and bootstrapping from the cluster.
+type Discovery struct {
+	// APIServerEndpoint is the address of kube-apiserver, it's used when no endpoint is specified in command line.
+	APIServerEndpoint string `json:"apiServerEndpoint,omitempty"`
+	// Token is the bootstrap token for both discovery and tls bootstrap.
+	Token string `json:"token,omitempty"`
+	// CACertHashes are the hashes of root CA public key for validating cluster info.
+	CACertHashes []string `json:"caCertHashes,omitempty"`
+	// UnsafeSkipCAVerification allows joining without CA cert hash pinning.
+	UnsafeSkipCAVerification *bool `json:"unsafeSkipCAVerification,omitempty"`
+	// HubLeaderAddr is the address of join info server on the hub leader node, which is used when kube-apiserver is unreachable.
+	HubLeaderAddr string `json:"hubLeaderAddr,omitempty"`
+	// TokenExchange specifies how to exchange the identity of node for a bootstrap token.
+	TokenExchange *TokenExchange `json:"tokenExchange,omitempty"`
+}
+
+// TokenExchange contains the settings for exchanging external identity for a bootstrap token.
+type TokenExchange struct {
+	Server                    string `json:"server,omitempty"`
+	CAFile                    string `json:"caFile,omitempty"`
+	IdentityTokenFile         string `json:"identityTokenFile,omitempty"`
+	InstanceIdentityDocument  string `json:"instanceIdentityDocument,omitempty"`
+	InstanceIdentitySignature string `json:"instanceIdentitySignature,omitempty"`
+}
+
+// Node contains the settings for registering node.
+type Node struct {
+	// Name is the node name, hostname is used if it's not specified.
+	Name string `json:"name,omitempty"`
+	// Type is the type of node, edge or cloud.
+	Type string `json:"type,omitempty"`
+	// NodePoolName is the nodepool which node is added into.
+	NodePoolName string `json:"nodePoolName,omitempty"`
+	// NodePoolCreation specifies how to create the nodepool if it doesn't exist.
+	NodePoolCreation *NodePoolCreation `json:"nodePoolCreation,omitempty"`
+	// Labels are added into node.
+	Labels map[string]string `json:"labels,omitempty"`
+	// IgnorePreflightErrors are the checks whose errors are shown as warnings.
+	IgnorePreflightErrors []string `json:"ignorePreflightErrors,omitempty"`
+	// GPUPreflight checks the gpu environment of node before joining, and adds the gpu labels into node if it's passed.
+	GPUPreflight *bool `json:"gpuPreflight,omitempty"`
+	// MinNvidiaDriverVersion is the minimum nvidia driver version required by the gpu preflight.
+	MinNvidiaDriverVersion string `json:"minNvidiaDriverVersion,omitempty"`
+}
+
+// NodePoolCreation contains the settings for creating the nodepool from a template configured in yurt-manager.
+type NodePoolCreation struct {
+	Template string `json:"template,omitempty"`
+	Server   string `json:"server,omitempty"`
+	CAFile   string `json:"caFile,omitempty"`
+}
+
+// YurtHub contains the settings of yurthub.
+type YurtHub struct {
+	Image         string `json:"image,omitempty"`
+	Server        string `json:"server,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Organizations string `json:"organizations,omitempty"`
+}
+
+// Raven contains the settings of raven tunnel endpoint.
+type Raven struct {
+	// PublicIP is the public ip of node, "auto" means detecting it.
+	PublicIP string `json:"publicIP,omitempty"`
+	// STUNServers are used for detecting the public ip of node.
+	STUNServers []string `json:"stunServers,omitempty"`
+}
+
+// Runtime contains the settings of container runtime and node components.
+type Runtime struct {
+	// Distro is the kubernetes distro of node, kubeadm, k3s, rke2 or auto.
+	Distro                   string `json:"distro,omitempty"`
+	CRISocket                string `json:"criSocket,omitempty"`
+	PauseImage               string `json:"pauseImage,omitempty"`
+	ReuseCNIBin              *bool  `json:"reuseCNIBin,omitempty"`
+	KubernetesResourceServer string `json:"kubernetesResourceServer,omitempty"`
+	OfflineBundle            string `json:"offlineBundle,omitempty"`
+	StaticPods               string `json:"staticPods,omitempty"`
+}
+
+// Load reads JoinConfiguration from file, unknown fields are rejected.
+func Load(path string) (*JoinConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg JoinConfiguration
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "could not parse join configuration %s", path)
+	}
+	if cfg.APIVersion != APIVersion || cfg.Kind != Kind {
+		return nil, errors.Errorf("join configuration %s should be %s of %s, but got %s of %s", path, Kind, APIVersion, cfg.Kind, cfg.APIVersion)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package join
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joinconfig"
+	yurtconstants "github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+)
+
+func loadJoinConfiguration(t *testing.T, content string) (*joinconfig.JoinConfiguration, error) {
+	path := filepath.Join(t.TempDir(), "join.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("could not write join configuration, %v", err)
+	}
+	return joinconfig.Load(path)
+}
+
+func TestDefaultJoinConfiguration(t *testing.T) {
+	data, err := yaml.Marshal(DefaultJoinConfiguration())
+	if err != nil {
+		t.Fatalf("could not marshal default join configuration, %v", err)
+	}
+	cfg, err := loadJoinConfiguration(t, string(data))
+	if err != nil {
+		t.Fatalf("could not load default join configuration, %v", err)
+	}
+
+	opt := newJoinOptions()
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	addJoinConfigFlags(fs, opt)
+	applyJoinConfiguration(cfg, opt, fs, nil)
+
+	expect := newJoinOptions()
+	expect.token = yurtconstants.PlaceholderToken
+	if !reflect.DeepEqual(expect, opt) {
+		t.Errorf("expect options %+v, but got %+v", expect, opt)
+	}
+}
+
+func TestApplyJoinConfiguration(t *testing.T) {
+	cfg, err := loadJoinConfiguration(t, `
+apiVersion: yurtadm.openyurt.io/v1alpha1
+kind: JoinConfiguration
+discovery:
+  apiServerEndpoint: 1.2.3.4:6443
+  token: abcdef.0123456789abcdef
+  unsafeSkipCAVerification: true
+  tokenExchange:
+    server: https://yurt-manager:10273
+node:
+  nodePoolName: hangzhou
+  nodePoolCreation:
+    template: edge-site
+  labels:
+    b: "2"
+    a: "1"
+yurthub:
+  image: yurthub:v1.4.0
+raven:
+  publicIP: auto
+runtime:
+  criSocket: /run/containerd/containerd.sock
+  reuseCNIBin: true
+`)
+	if err != nil {
+		t.Fatalf("could not load join configuration, %v", err)
+	}
+
+	opt := newJoinOptions()
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	addJoinConfigFlags(fs, opt)
+	if err := fs.Parse([]string{"--nodepool-name=beijing", "--reuse-cni-bin=false"}); err != nil {
+		t.Fatalf("could not parse flags, %v", err)
+	}
+	args := applyJoinConfiguration(cfg, opt, fs, nil)
+
+	if !reflect.DeepEqual(args, []string{"1.2.3.4:6443"}) {
+		t.Errorf("expect args [1.2.3.4:6443], but got %v", args)
+	}
+	expect := newJoinOptions()
+	expect.token = "abcdef.0123456789abcdef"
+	expect.unsafeSkipCAVerification = true
+	expect.tokenExchangeServer = "https://yurt-manager:10273"
+	expect.nodePoolName = "beijing"
+	expect.createNodePoolFrom = "edge-site"
+	expect.nodeLabels = "a=1,b=2"
+	expect.yurthubImage = "yurthub:v1.4.0"
+	expect.publicIP = "auto"
+	expect.criSocket = "/run/containerd/containerd.sock"
+	expect.reuseCNIBin = false
+	if !reflect.DeepEqual(expect, opt) {
+		t.Errorf("expect options %+v, but got %+v", expect, opt)
+	}
+
+	if args := applyJoinConfiguration(cfg, opt, fs, []string{"5.6.7.8:6443"}); !reflect.DeepEqual(args, []string{"5.6.7.8:6443"}) {
+		t.Errorf("expect args in command line are kept, but got %v", args)
+	}
+}
+
+func TestLoadInvalidJoinConfiguration(t *testing.T) {
+	testcases := map[string]string{
+		"wrong kind": `
+apiVersion: yurtadm.openyurt.io/v1alpha1
+kind: InitConfiguration
+`,
+		"wrong api version": `
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: JoinConfiguration
+`,
+		"unknown field": `
+apiVersion: yurtadm.openyurt.io/v1alpha1
+kind: JoinConfiguration
+node:
+  poolName: hangzhou
+`,
+	}
+
+	for k, content := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if _, err := loadJoinConfiguration(t, content); err == nil {
+				t.Errorf("expect error for invalid join configuration")
+			}
+		})
+	}
+}
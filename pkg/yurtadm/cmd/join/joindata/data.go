@@ -51,4 +51,9 @@ type YurtJoinData interface {
 	Namespace() string
 	StaticPodTemplateList() []string
 	StaticPodManifestList() []string
+	OfflineBundle() string
+	RegistrationDeferred() bool
+	Distro() string
+	GPUPreflight() bool
+	MinNvidiaDriverVersion() string
 }
@@ -25,17 +25,30 @@ import (
 
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/kubernetes"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
 )
 
 // RunJoinNode executes the node join process.
 func RunJoinNode(data joindata.YurtJoinData, out io.Writer, outErr io.Writer) error {
+	// agent has joined the cluster, so it's restarted to load the new config instead of running kubeadm join
+	if agent := distro.GetAgent(data.Distro()); agent != nil {
+		fmt.Fprintf(out, "restarting %s to connect to kube-apiserver through yurthub\n", agent.Service)
+		return kubernetes.RestartService(agent.Service)
+	}
+
 	var kubeadmJoinConfigFilePath string
 	if data.CfgPath() != "" {
 		kubeadmJoinConfigFilePath = data.CfgPath()
 	} else {
 		kubeadmJoinConfigFilePath = filepath.Join(constants.KubeletWorkdir, constants.KubeadmJoinConfigFileName)
 	}
-	kubeadmCmd := exec.Command("kubeadm", "join", fmt.Sprintf("--config=%s", kubeadmJoinConfigFilePath))
+	kubeadm := "kubeadm"
+	if windows.IsWindows() {
+		kubeadm = windows.Binary("kubeadm")
+	}
+	kubeadmCmd := exec.Command(kubeadm, "join", fmt.Sprintf("--config=%s", kubeadmJoinConfigFilePath))
 	kubeadmCmd.Stdout = out
 	kubeadmCmd.Stderr = outErr
 
@@ -20,17 +20,22 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/kubernetes"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
 )
 
 // RunPostCheck executes the node health check and clean process.
 func RunPostCheck(data joindata.YurtJoinData) error {
-	klog.V(1).Infof("check kubelet status.")
-	if err := kubernetes.CheckKubeletStatus(); err != nil {
+	service := "kubelet"
+	if agent := distro.GetAgent(data.Distro()); agent != nil {
+		service = agent.Service
+	}
+	klog.V(1).Infof("check %s status.", service)
+	if err := kubernetes.CheckServiceStatus(service); err != nil {
 		return err
 	}
-	klog.V(1).Infof("kubelet service is active")
+	klog.V(1).Infof("%s service is active", service)
 
 	klog.V(1).Infof("waiting hub agent ready.")
 	if err := yurthub.CheckYurthubHealthz(data.YurtHubServer()); err != nil {
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/node-servant/preflight"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+	hubutil "github.com/openyurtio/openyurt/pkg/yurthub/util"
+)
+
+// kubeletPort is the port of kubelet server
+const kubeletPort = 10250
+
+// RunPreflight checks the connectivity between node and cluster before anything is changed on node,
+// so that the join fails fast instead of leaving a half-joined node. The errors of checks specified
+// by --ignore-preflight-errors are shown as warnings.
+func RunPreflight(data joindata.YurtJoinData) error {
+	checks := preflightChecks(data)
+	if data.RegistrationDeferred() {
+		klog.Warningf("kube-apiserver is unreachable, node will be registered once it's reachable")
+	} else if client, url, err := serverTimeClient(data); err != nil {
+		klog.Warningf("clock skew is not checked, %v", err)
+	} else {
+		checks = append(checks, preflight.ClockSkewCheck{URL: url, Client: client, MaxSkew: preflight.DefaultMaxClockSkew})
+	}
+
+	var nodeName string
+	if node := data.NodeRegistration(); node != nil {
+		nodeName = node.Name
+	}
+	report := preflight.Run(nodeName, checks...)
+	gpuPassed := checkPassed(report, preflight.GPUCheck{}.Name())
+	report.IgnoreErrors(data.IgnorePreflightErrors().List())
+	fmt.Print(report.String())
+	if !report.Ready() {
+		return errors.Errorf("preflight checks failed, fix the errors above or skip them by --ignore-preflight-errors")
+	}
+
+	if data.GPUPreflight() && gpuPassed {
+		if err := addGPULabels(data); err != nil {
+			klog.Warningf("gpu labels are not added into node, %v", err)
+		}
+	}
+	return nil
+}
+
+// checkPassed checks whether the check named name is run and returns no errors
+func checkPassed(report *preflight.Report, name string) bool {
+	for _, result := range report.Results {
+		if result.Name == name {
+			return len(result.Errors) == 0
+		}
+	}
+	return false
+}
+
+// addGPULabels adds the gpu and accelerator labels into node, so the node is selected by gpu workloads
+// and counted in the gpu inventory of nodepool before gpu feature discovery is running on it.
+// The labels specified by --node-labels are kept.
+func addGPULabels(data joindata.YurtJoinData) error {
+	gpus, err := preflight.DetectNvidiaGPUs()
+	if err != nil {
+		return err
+	}
+	nodeLabels := data.NodeLabels()
+	for k, v := range preflight.GPULabels(gpus) {
+		if _, ok := nodeLabels[k]; !ok {
+			nodeLabels[k] = v
+		}
+	}
+	return nil
+}
+
+// preflightChecks returns the checks of kube-apiserver reachability, path MTU, DNS, ports and gpu if it's enabled
+func preflightChecks(data joindata.YurtJoinData) []preflight.Checker {
+	var endpoints, hosts []string
+	for _, endpoint := range strings.Split(data.ServerAddr(), ",") {
+		if endpoint = strings.TrimSpace(endpoint); len(endpoint) != 0 {
+			endpoints = append(endpoints, endpoint)
+			hosts = append(hosts, preflight.HostOf(endpoint))
+		}
+	}
+
+	// nothing is downloaded from the kubernetes resource server if node is joined offline
+	var optionalHosts []string
+	if len(data.OfflineBundle()) == 0 {
+		optionalHosts = append(optionalHosts, preflight.HostOf(data.KubernetesResourceServer()))
+	}
+	checks := []preflight.Checker{
+		preflight.DNSCheck{Hosts: hosts, OptionalHosts: optionalHosts},
+	}
+	if data.GPUPreflight() {
+		checks = append(checks, preflight.GPUCheck{MinDriverVersion: data.MinNvidiaDriverVersion()})
+	}
+	ports := []int{kubeletPort, hubutil.YurtHubProxyPort, hubutil.YurtHubPort}
+	// kubelet of k3s or rke2 is running already, so the agent is checked instead of kubelet port
+	if agent := distro.GetAgent(data.Distro()); agent != nil {
+		checks = append(checks, agentCheck{agent: agent})
+		ports = []int{hubutil.YurtHubProxyPort, hubutil.YurtHubPort}
+	}
+	// kube-apiserver is unreachable when node is bootstrapped from the hub leader
+	if data.RegistrationDeferred() {
+		return append(checks, preflight.PortCheck{Ports: ports})
+	}
+	checks = append(checks, preflight.APIServerCheck{Endpoints: endpoints, MaxLatency: preflight.DefaultMaxAPIServerLatency})
+	if len(endpoints) != 0 {
+		checks = append(checks, preflight.PathMTUCheck{Endpoint: endpoints[0], MinMTU: preflight.DefaultMinPathMTU})
+	}
+	return append(checks, preflight.PortCheck{Ports: ports})
+}
+
+// agentCheck checks whether k3s or rke2 agent is installed and has joined the cluster
+type agentCheck struct {
+	agent *distro.Agent
+}
+
+func (c agentCheck) Name() string {
+	return "Agent"
+}
+
+func (c agentCheck) Check() (warnings, errorList []error) {
+	if err := c.agent.CheckJoined("/"); err != nil {
+		errorList = append(errorList, err)
+	}
+	return nil, errorList
+}
+
+// serverTimeClient returns the client trusting the cluster CA and the url to get the time of kube-apiserver
+func serverTimeClient(data joindata.YurtJoinData) (*http.Client, string, error) {
+	if data.TLSBootstrapCfg() == nil {
+		return nil, "", errors.New("tls bootstrap config is not found")
+	}
+	cfg, err := clientcmd.NewDefaultClientConfig(*data.TLSBootstrapCfg(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return &http.Client{Transport: transport, Timeout: 10 * time.Second}, strings.TrimSuffix(cfg.Host, "/") + "/version", nil
+}
@@ -20,18 +20,32 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	utilsexec "k8s.io/utils/exec"
 
+	"github.com/openyurtio/openyurt/pkg/node-servant/components"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
 	yurtadmutil "github.com/openyurtio/openyurt/pkg/yurtadm/util/kubernetes"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/offline"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/system"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
 )
 
 // RunPrepare executes the node initialization process.
 func RunPrepare(data joindata.YurtJoinData) error {
+	if agent := distro.GetAgent(data.Distro()); agent != nil {
+		return prepareAgent(data, agent)
+	}
+	if windows.IsWindows() {
+		return prepareWindows(data)
+	}
+
 	// cleanup at first
 	staticPodsPath := filepath.Join(constants.KubeletConfigureDir, constants.ManifestsSubDirName)
 	if err := os.RemoveAll(staticPodsPath); err != nil {
@@ -47,13 +61,21 @@ func RunPrepare(data joindata.YurtJoinData) error {
 	if err := system.SetSELinux(); err != nil {
 		return err
 	}
+	reuseCNIBin := data.ReuseCNIBin()
+	if len(data.OfflineBundle()) != 0 {
+		installed, err := installOfflineBundle(data)
+		if err != nil {
+			return err
+		}
+		reuseCNIBin = reuseCNIBin || installed
+	}
 	if err := yurtadmutil.CheckAndInstallKubelet(data.KubernetesResourceServer(), data.KubernetesVersion()); err != nil {
 		return err
 	}
 	if err := yurtadmutil.CheckAndInstallKubeadm(data.KubernetesResourceServer(), data.KubernetesVersion()); err != nil {
 		return err
 	}
-	if err := yurtadmutil.CheckAndInstallKubernetesCni(data.ReuseCNIBin()); err != nil {
+	if err := yurtadmutil.CheckAndInstallKubernetesCni(reuseCNIBin); err != nil {
 		return err
 	}
 	if err := yurtadmutil.SetKubeletService(); err != nil {
@@ -90,3 +112,95 @@ func RunPrepare(data joindata.YurtJoinData) error {
 	}
 	return nil
 }
+
+// prepareAgent configures the k3s or rke2 agent which has joined the cluster to connect to kube-apiserver
+// through yurthub, binaries, CNI plugins and kubelet are managed by the agent itself.
+func prepareAgent(data joindata.YurtJoinData, agent *distro.Agent) error {
+	klog.Infof("convert %s node, yurthub is started by %s", agent.Name, agent.Service)
+	if err := yurtadmutil.SetKubeletConfigForNode(); err != nil {
+		return err
+	}
+	if err := yurthub.SetHubBootstrapConfig(data.ServerAddr(), data.JoinToken(), data.CaCertHashes()); err != nil {
+		return err
+	}
+	if err := yurthub.AddYurthubStaticYaml(data, agent.StaticPodPath()); err != nil {
+		return err
+	}
+	if len(data.StaticPodTemplateList()) != 0 {
+		if err := edgenode.DeployStaticYaml(data.StaticPodManifestList(), data.StaticPodTemplateList(), agent.StaticPodPath()); err != nil {
+			return err
+		}
+	}
+	return yurtadmutil.SetAgentConfig(data, agent)
+}
+
+// prepareWindows prepares the windows worker node, kubelet and yurthub run as windows services instead of
+// systemd service and static pod, and the kernel settings and CNI plugins of linux are not managed.
+func prepareWindows(data joindata.YurtJoinData) error {
+	staticPodsPath := filepath.Join(constants.KubeletConfigureDir, constants.ManifestsSubDirName)
+	if err := os.RemoveAll(staticPodsPath); err != nil {
+		klog.Warningf("remove %s: %v", staticPodsPath, err)
+	}
+
+	if err := windows.CheckAndInstallBinaries(data.KubernetesResourceServer(), data.KubernetesVersion()); err != nil {
+		return err
+	}
+	if err := yurtadmutil.SetWindowsKubeletService(data); err != nil {
+		return err
+	}
+	if err := yurtadmutil.SetKubeletConfigForNode(); err != nil {
+		return err
+	}
+	if err := yurthub.SetHubBootstrapConfig(data.ServerAddr(), data.JoinToken(), data.CaCertHashes()); err != nil {
+		return err
+	}
+	if err := yurthub.AddYurthubWindowsService(data); err != nil {
+		return err
+	}
+	if len(data.StaticPodTemplateList()) != 0 {
+		if err := edgenode.DeployStaticYaml(data.StaticPodManifestList(), data.StaticPodTemplateList(), constants.StaticPodPath); err != nil {
+			return err
+		}
+	}
+	if err := yurtadmutil.SetDiscoveryConfig(data); err != nil {
+		return err
+	}
+	if data.CfgPath() == "" {
+		return yurtadmutil.SetKubeadmJoinConfig(data)
+	}
+	return nil
+}
+
+// installOfflineBundle installs binaries, CNI plugins, images and yurthub cache from the offline bundle,
+// so that nothing is downloaded when node is joined. It returns whether CNI plugins are installed.
+func installOfflineBundle(data joindata.YurtJoinData) (bool, error) {
+	klog.Infof("install offline bundle %s", data.OfflineBundle())
+	defer os.RemoveAll(constants.OfflineBundleDir)
+	bundle, err := offline.Extract(data.OfflineBundle(), constants.OfflineBundleDir)
+	if err != nil {
+		return false, err
+	}
+
+	if err := bundle.InstallBinaries("/usr/bin", "kubelet", "kubeadm"); err != nil {
+		return false, err
+	}
+	cniInstalled, err := bundle.InstallCNI(constants.KubeCniDir)
+	if err != nil {
+		return false, err
+	}
+	if !cniInstalled && !data.ReuseCNIBin() {
+		return false, errors.Errorf("no CNI plugins are found in offline bundle, please add them or specify --%s", constants.ReuseCNIBin)
+	}
+
+	runtime, err := components.NewContainerRuntimeForImage(utilsexec.New(), data.NodeRegistration().CRISocket)
+	if err != nil {
+		return false, err
+	}
+	if err := bundle.LoadImages(runtime); err != nil {
+		return false, err
+	}
+	if err := bundle.SeedYurtHubCache(disk.CacheBaseDir); err != nil {
+		return false, err
+	}
+	return cniInstalled, nil
+}
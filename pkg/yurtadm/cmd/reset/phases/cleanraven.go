@@ -0,0 +1,203 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"os/exec"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ravenDevicePrefix is the name prefix of vxlan and wireguard devices created by raven agent
+	ravenDevicePrefix = "raven"
+	// ravenChainPrefix is the name prefix of iptables chains created by raven agent
+	ravenChainPrefix = "RAVEN"
+	// ravenRouteTable is the route table which raven agent sets the routes to other gateways in
+	ravenRouteTable = "9027"
+)
+
+var iptablesTables = []string{"filter", "nat", "mangle"}
+
+// RunCleanRaven tears down the network devices, routes and iptables chains created by raven agent,
+// the stale tunnels and rules break the networking of node after it rejoins the cluster.
+func RunCleanRaven() error {
+	cleanRavenDevices()
+	cleanRavenRoutes()
+	for _, iptables := range []string{"iptables", "ip6tables"} {
+		cleanRavenChains(iptables)
+	}
+	return nil
+}
+
+func cleanRavenDevices() {
+	out, err := exec.Command("ip", "-o", "link", "show").CombinedOutput()
+	if err != nil {
+		klog.Warningf("List network devices fail: %v, %s", err, out)
+		return
+	}
+	for _, dev := range parseLinkNames(string(out)) {
+		if !strings.HasPrefix(dev, ravenDevicePrefix) {
+			continue
+		}
+		if out, err := exec.Command("ip", "link", "delete", dev).CombinedOutput(); err != nil {
+			klog.Warningf("Delete network device %s fail: %v, %s, please delete it manually.", dev, err, out)
+		}
+	}
+}
+
+func cleanRavenRoutes() {
+	for _, family := range []string{"-4", "-6"} {
+		out, err := exec.Command("ip", family, "rule", "show").CombinedOutput()
+		if err != nil {
+			klog.Warningf("List ip rules fail: %v, %s", err, out)
+			continue
+		}
+		for i := 0; i < countRulesOfTable(string(out), ravenRouteTable); i++ {
+			if out, err := exec.Command("ip", family, "rule", "del", "table", ravenRouteTable).CombinedOutput(); err != nil {
+				klog.Warningf("Delete ip rule of table %s fail: %v, %s, please delete it manually.", ravenRouteTable, err, out)
+				break
+			}
+		}
+		if out, err := exec.Command("ip", family, "route", "flush", "table", ravenRouteTable).CombinedOutput(); err != nil {
+			klog.V(4).Infof("Flush route table %s: %v, %s", ravenRouteTable, err, out)
+		}
+	}
+}
+
+func cleanRavenChains(iptables string) {
+	if _, err := exec.LookPath(iptables); err != nil {
+		return
+	}
+	for _, table := range iptablesTables {
+		out, err := exec.Command(iptables+"-save", "-t", table).CombinedOutput()
+		if err != nil {
+			klog.Warningf("List %s rules of table %s fail: %v, %s", iptables, table, err, out)
+			continue
+		}
+
+		chains, jumps := parseRavenChains(string(out))
+		// the rules which jump to raven chains are deleted first, otherwise the chains can't be deleted
+		for _, rule := range jumps {
+			args := append([]string{"-t", table, "-D"}, rule...)
+			if out, err := exec.Command(iptables, args...).CombinedOutput(); err != nil {
+				klog.Warningf("Delete %s rule %v fail: %v, %s, please delete it manually.", iptables, rule, err, out)
+			}
+		}
+		for _, op := range []string{"-F", "-X"} {
+			for _, chain := range chains {
+				if out, err := exec.Command(iptables, "-t", table, op, chain).CombinedOutput(); err != nil {
+					klog.Warningf("Clean %s chain %s fail: %v, %s, please clean it manually.", iptables, chain, err, out)
+				}
+			}
+		}
+	}
+}
+
+// parseLinkNames returns the device names in output of `ip -o link show`, e.g.
+// "5: raven0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 ..."
+func parseLinkNames(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		name := strings.TrimSpace(fields[1])
+		if i := strings.Index(name, "@"); i >= 0 {
+			name = name[:i]
+		}
+		if len(name) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// countRulesOfTable returns the number of rules which look up the specified table in output of `ip rule show`.
+func countRulesOfTable(output, table string) int {
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if (fields[i] == "lookup" || fields[i] == "table") && fields[i+1] == table {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// parseRavenChains returns the raven chains and the rules in other chains which jump to them
+// in output of iptables-save.
+func parseRavenChains(output string) ([]string, [][]string) {
+	var chains []string
+	var jumps [][]string
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, ":"+ravenChainPrefix):
+			chains = append(chains, strings.Fields(line[1:])[0])
+		case strings.HasPrefix(line, "-A "):
+			rule := splitRule(line[len("-A "):])
+			if len(rule) == 0 || strings.HasPrefix(rule[0], ravenChainPrefix) {
+				continue
+			}
+			for i := 0; i+1 < len(rule); i++ {
+				if (rule[i] == "-j" || rule[i] == "-g") && strings.HasPrefix(rule[i+1], ravenChainPrefix) {
+					jumps = append(jumps, rule)
+					break
+				}
+			}
+		}
+	}
+	return chains, jumps
+}
+
+// splitRule splits the rule in iptables-save into arguments, the quoted argument like comment is kept as a whole.
+func splitRule(rule string) []string {
+	var args []string
+	var current strings.Builder
+	quoted, escaped, inArg := false, false, false
+	for _, r := range rule {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case r == ' ' && !quoted:
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLinkNames(t *testing.T) {
+	output := `1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN mode DEFAULT group default qlen 1000\    link/loopback 00:00:00:00:00:00 brd 00:00:00:00:00:00
+2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP mode DEFAULT group default qlen 1000\    link/ether 00:16:3e:00:00:01 brd ff:ff:ff:ff:ff:ff
+5: raven0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1450 qdisc noqueue state UNKNOWN mode DEFAULT group default \    link/ether 6a:1b:00:00:00:02 brd ff:ff:ff:ff:ff:ff
+6: raven-wg0: <POINTOPOINT,NOARP,UP,LOWER_UP> mtu 1420 qdisc noqueue state UNKNOWN mode DEFAULT group default qlen 1000\    link/none
+7: veth1@if3: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default \    link/ether 8a:00:00:00:00:03 brd ff:ff:ff:ff:ff:ff link-netnsid 0
+`
+	expect := []string{"lo", "eth0", "raven0", "raven-wg0", "veth1"}
+	if got := parseLinkNames(output); !reflect.DeepEqual(expect, got) {
+		t.Errorf("expect %v, but got %v", expect, got)
+	}
+}
+
+func TestCountRulesOfTable(t *testing.T) {
+	output := `0:	from all lookup local
+100:	from all fwmark 0x40000/0x40000 lookup 9027
+101:	from 10.0.0.0/16 lookup 9027
+32766:	from all lookup main
+32767:	from all lookup default
+`
+	if got := countRulesOfTable(output, ravenRouteTable); got != 2 {
+		t.Errorf("expect 2 rules, but got %d", got)
+	}
+}
+
+func TestParseRavenChains(t *testing.T) {
+	output := `# Generated by iptables-save v1.8.4 on Mon Jan  1 00:00:00 2024
+*nat
+:PREROUTING ACCEPT [0:0]
+:POSTROUTING ACCEPT [0:0]
+:RAVEN-NAT-POSTROUTING - [0:0]
+:KUBE-SERVICES - [0:0]
+-A POSTROUTING -m comment --comment "raven nat rules" -j RAVEN-NAT-POSTROUTING
+-A PREROUTING -m comment --comment "kubernetes service portals" -j KUBE-SERVICES
+-A RAVEN-NAT-POSTROUTING -s 10.0.0.0/16 -j RETURN
+COMMIT
+`
+	chains, jumps := parseRavenChains(output)
+	if expect := []string{"RAVEN-NAT-POSTROUTING"}; !reflect.DeepEqual(expect, chains) {
+		t.Errorf("expect chains %v, but got %v", expect, chains)
+	}
+	expectJumps := [][]string{{"POSTROUTING", "-m", "comment", "--comment", "raven nat rules", "-j", "RAVEN-NAT-POSTROUTING"}}
+	if !reflect.DeepEqual(expectJumps, jumps) {
+		t.Errorf("expect jumps %v, but got %v", expectJumps, jumps)
+	}
+}
+
+func TestSplitRule(t *testing.T) {
+	testcases := map[string][]string{
+		`FORWARD -j ACCEPT`: {"FORWARD", "-j", "ACCEPT"},
+		`FORWARD -m comment --comment "a \"quoted\" comment"  -j ACCEPT`: {"FORWARD", "-m", "comment", "--comment", `a "quoted" comment`, "-j", "ACCEPT"},
+		`FORWARD --comment ""`: {"FORWARD", "--comment", ""},
+	}
+	for rule, expect := range testcases {
+		if got := splitRule(rule); !reflect.DeepEqual(expect, got) {
+			t.Errorf("rule %s: expect %q, but got %q", rule, expect, got)
+		}
+	}
+}
@@ -18,24 +18,46 @@ package phases
 
 import (
 	"os"
+	"path/filepath"
 
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
 )
 
+// RunCleanYurtFile removes the files created by yurtadm join and the components on node, including
+// yurthub cache, static pod manifests, generated certificates and cni state.
 func RunCleanYurtFile() error {
 	for _, file := range []string{constants.KubeletWorkdir,
 		constants.YurttunnelAgentWorkdir,
 		constants.YurttunnelServerWorkdir,
 		constants.YurtHubWorkdir,
+		constants.OpenyurtDir,
+		disk.CacheBaseDir,
+		constants.StaticPodPath,
+		constants.DefaultCertificatesDir,
+		constants.CniStateDir,
 		constants.KubeletSvcPath,
 		constants.KubeletServiceFilepath,
+		filepath.Dir(constants.KubeletServiceConfPath),
 		constants.KubeletConfigureDir,
 		constants.SysctlK8sConfig} {
 		if err := os.RemoveAll(file); err != nil {
 			klog.Warningf("Clean file %s fail: %v, please clean it manually.", file, err)
 		}
 	}
+
+	// remove the agent config and yurthub manifest written for k3s or rke2 agent
+	for _, agent := range distro.Agents() {
+		files, _ := filepath.Glob(filepath.Join(agent.StaticPodPath(), constants.YurthubStaticPodManifest+"*.yaml"))
+		files = append(files, agent.ConfigDropInFile())
+		for _, file := range files {
+			if err := os.RemoveAll(file); err != nil {
+				klog.Warningf("Clean file %s fail: %v, please clean it manually.", file, err)
+			}
+		}
+	}
 	return nil
 }
@@ -106,6 +106,10 @@ func (nodeReseter *nodeReseter) Run() error {
 		return err
 	}
 
+	if err := yurtphases.RunCleanRaven(); err != nil {
+		return err
+	}
+
 	if err := yurtphases.RunCleanYurtFile(); err != nil {
 		return err
 	}
@@ -20,14 +20,22 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
+	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
 )
 
+const printJoinCommandFlag = "print-join-command"
+
 func NewCmdToken(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
+	var throughYurtHub bool
+	yurthubServer := constants.DefaultYurtHubServerAddr
 
 	cmd := &cobra.Command{
 		Use:                "token",
@@ -40,9 +48,26 @@ func NewCmdToken(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
 				return err
 			}
 
-			klog.V(2).InfoS("kubeadm command exec", "args", os.Args[1:])
+			kubeadmArgs := stripFlags(os.Args[1:], map[string]bool{constants.ThroughYurtHub: false, constants.YurtHubServerAddr: true})
+			if throughYurtHub {
+				if hasFlag(kubeadmArgs, printJoinCommandFlag) {
+					return errors.Errorf("--%s can't be used with --%s, the join command would point to yurthub", printJoinCommandFlag, constants.ThroughYurtHub)
+				}
+				kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+				if len(kubeconfig) == 0 {
+					kubeconfig = constants.DefaultAdminKubeConfig
+				}
+				proxyKubeConfig, err := writeProxyKubeConfig(kubeconfig, yurthubServer)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(proxyKubeConfig)
+				kubeadmArgs = append(stripFlags(kubeadmArgs, map[string]bool{"kubeconfig": true}), "--kubeconfig="+proxyKubeConfig)
+			}
+
+			klog.V(2).InfoS("kubeadm command exec", "args", kubeadmArgs)
 
-			kubeadmCmd := exec.Command("kubeadm", os.Args[1:]...)
+			kubeadmCmd := exec.Command("kubeadm", kubeadmArgs...)
 			kubeadmCmd.Stdout = out
 			kubeadmCmd.Stderr = outErr
 			if err := kubeadmCmd.Run(); err != nil {
@@ -52,5 +77,71 @@ func NewCmdToken(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&throughYurtHub, constants.ThroughYurtHub, throughYurtHub,
+		"Access kube-apiserver through the local yurthub, only the bearer token in kubeconfig can be forwarded by yurthub.")
+	cmd.Flags().StringVar(&yurthubServer, constants.YurtHubServerAddr, yurthubServer,
+		"Sets the address for yurthub server addr")
 	return cmd
 }
+
+// writeProxyKubeConfig writes the kubeconfig which accesses kube-apiserver through yurthub into a temporary file.
+func writeProxyKubeConfig(kubeconfig, yurthubServer string) (string, error) {
+	cfg, err := yurthub.ProxyKubeConfig(kubeconfig, "", yurthubServer)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "yurtadm-token-kubeconfig-")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	if err := kubeconfigutil.WriteToDisk(f.Name(), cfg); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// stripFlags removes the flags and their values from args. The value of flags tells whether the flag
+// takes a value, which can be in the format of "--flag=value" or "--flag value", while the bool flags
+// can only be in the format of "--flag" or "--flag=value".
+func stripFlags(args []string, flags map[string]bool) []string {
+	var stripped []string
+	for i := 0; i < len(args); i++ {
+		name, hasValue, matched := matchFlag(args[i], flags)
+		if !matched {
+			stripped = append(stripped, args[i])
+			continue
+		}
+		if !hasValue && flags[name] && i+1 < len(args) {
+			i++
+		}
+	}
+	return stripped
+}
+
+// hasFlag checks whether the flag is set in args.
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if _, _, matched := matchFlag(arg, map[string]bool{flag: false}); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFlag returns the name of flag in arg, whether the value is in arg, and whether it's one of flags.
+func matchFlag(arg string, flags map[string]bool) (string, bool, bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return "", false, false
+	}
+	name := strings.TrimLeft(arg, "-")
+	hasValue := false
+	if idx := strings.Index(name, "="); idx >= 0 {
+		name, hasValue = name[:idx], true
+	}
+	if _, ok := flags[name]; !ok {
+		return "", false, false
+	}
+	return name, hasValue, true
+}
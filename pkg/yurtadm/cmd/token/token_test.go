@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripFlags(t *testing.T) {
+	flags := map[string]bool{"through-yurthub": false, "yurthub-server-addr": true, "kubeconfig": true}
+	testcases := map[string]struct {
+		args   []string
+		expect []string
+	}{
+		"no flags to strip": {
+			args:   []string{"token", "list"},
+			expect: []string{"token", "list"},
+		},
+		"bool flag": {
+			args:   []string{"token", "--through-yurthub", "list"},
+			expect: []string{"token", "list"},
+		},
+		"bool flag with value": {
+			args:   []string{"token", "--through-yurthub=true", "list"},
+			expect: []string{"token", "list"},
+		},
+		"flags with values": {
+			args:   []string{"token", "create", "--kubeconfig", "/etc/kubernetes/admin.conf", "--yurthub-server-addr=127.0.0.2", "--ttl", "2h"},
+			expect: []string{"token", "create", "--ttl", "2h"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := stripFlags(tc.args, flags); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestHasFlag(t *testing.T) {
+	if !hasFlag([]string{"token", "create", "--print-join-command"}, printJoinCommandFlag) {
+		t.Errorf("expect --%s is found", printJoinCommandFlag)
+	}
+	if hasFlag([]string{"token", "create", "print-join-command"}, printJoinCommandFlag) {
+		t.Errorf("expect --%s is not found in args", printJoinCommandFlag)
+	}
+}
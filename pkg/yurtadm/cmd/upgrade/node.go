@@ -0,0 +1,415 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/yurthub"
+)
+
+const defaultUpgradeTimeout = 5 * time.Minute
+
+var (
+	// openyurtComponents are the names of OpenYurt images which are run by static pods on node
+	openyurtComponents = sets.NewString("yurthub", "raven-agent", "node-servant", "yurt-iot-dock")
+
+	// imageLine matches the image field of containers in manifest, e.g. `  - image: "openyurt/yurthub:v1.3.0" # comment`
+	imageLine = regexp.MustCompile(`^(\s*(?:-\s+)?image:\s*)(["']?)([^"'\s#]+)(["']?)(\s*(?:#.*)?)$`)
+
+	// the functions to check the upgraded static pods, they're replaced in unit tests
+	checkYurthubReady = func() bool { return yurthub.CheckYurthubReadyzOnce(constants.DefaultYurtHubServerAddr) }
+	listPods          = func() (*v1.PodList, error) {
+		return upgradeutil.GetPodsFromYurtHub(upgradeutil.YurtHubAddress + upgradeutil.YurtHubAPIPath)
+	}
+	verifyInterval = 5 * time.Second
+)
+
+type nodeOptions struct {
+	targetVersion  string
+	distro         string
+	nodeName       string
+	backupDir      string
+	upgradeTimeout time.Duration
+	dryRun         bool
+}
+
+// imageChange is an image in manifest which is upgraded to the target version
+type imageChange struct {
+	from string
+	to   string
+}
+
+// manifestUpgrade is a static pod manifest on node which runs OpenYurt components
+type manifestUpgrade struct {
+	file      string
+	namespace string
+	name      string
+	original  []byte
+	upgraded  []byte
+	changes   []imageChange
+}
+
+// newCmdUpgradeNode returns "yurtadm upgrade node" command.
+func newCmdUpgradeNode(out io.Writer) *cobra.Command {
+	o := &nodeOptions{
+		distro:         distro.Auto,
+		backupDir:      constants.UpgradeBackupDir,
+		upgradeTimeout: defaultUpgradeTimeout,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Upgrade the OpenYurt components on this node to the target version",
+		Long: dedent.Dedent(`
+			This command upgrades the static pods of OpenYurt components on this node, like yurthub and raven agent,
+			to the target version by rewriting the image tags in their manifests. The upgraded static pods must be
+			ready within the upgrade timeout, otherwise the previous manifests are restored.
+
+			It's used by the sites which prefer pulling upgrades on node over the OTA upgrades driven by controllers.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validate(); err != nil {
+				return err
+			}
+			return runUpgradeNode(o, staticPodPath(o.distro), out)
+		},
+	}
+
+	addNodeFlags(cmd.Flags(), o)
+	return cmd
+}
+
+// addNodeFlags adds flags of "yurtadm upgrade node"
+func addNodeFlags(flagSet *flag.FlagSet, o *nodeOptions) {
+	flagSet.StringVar(
+		&o.targetVersion, constants.TargetVersion, o.targetVersion,
+		"The OpenYurt version which the components on this node are upgraded to, e.g. v1.4.0.",
+	)
+	flagSet.StringVar(
+		&o.distro, constants.Distro, o.distro,
+		"The kubernetes distro of node, kubeadm, k3s, rke2 or auto, it decides where the static pod manifests are.",
+	)
+	flagSet.StringVar(
+		&o.nodeName, constants.NodeName, o.nodeName,
+		"The name of this node, the hostname is used if it's not set.",
+	)
+	flagSet.StringVar(
+		&o.backupDir, constants.BackupDir, o.backupDir,
+		"The dir where the previous manifests are backed up.",
+	)
+	flagSet.DurationVar(
+		&o.upgradeTimeout, constants.UpgradeTimeout, o.upgradeTimeout,
+		"How long to wait for the upgraded static pods to be ready before rolling back.",
+	)
+	flagSet.BoolVar(
+		&o.dryRun, constants.DryRun, o.dryRun,
+		"Only print the images which would be upgraded.",
+	)
+}
+
+func (o *nodeOptions) validate() error {
+	if len(o.targetVersion) == 0 {
+		return errors.Errorf("--%s is required", constants.TargetVersion)
+	}
+	if strings.ContainsAny(o.targetVersion, ":@/ ") {
+		return errors.Errorf("--%s %q is not a valid image tag", constants.TargetVersion, o.targetVersion)
+	}
+	if !distro.IsValid(o.distro) {
+		return errors.Errorf("--%s %q is invalid, expect kubeadm, k3s, rke2 or auto", constants.Distro, o.distro)
+	}
+	if o.upgradeTimeout <= 0 {
+		return errors.Errorf("--%s must be positive", constants.UpgradeTimeout)
+	}
+	if len(o.nodeName) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return errors.Wrap(err, "failed to get hostname as node name")
+		}
+		o.nodeName = strings.ToLower(hostname)
+	}
+	return nil
+}
+
+// staticPodPath returns the dir of static pod manifests of distro
+func staticPodPath(name string) string {
+	if name == distro.Auto {
+		name = distro.Detect("/")
+	}
+	if agent := distro.GetAgent(name); agent != nil {
+		return agent.StaticPodPath()
+	}
+	return constants.StaticPodPath
+}
+
+// runUpgradeNode upgrades the static pods in manifestDir, the manifests are restored if the upgraded
+// static pods are not ready in the timeout.
+func runUpgradeNode(o *nodeOptions, manifestDir string, out io.Writer) error {
+	upgrades, err := planUpgrade(manifestDir, o.targetVersion)
+	if err != nil {
+		return err
+	}
+	if len(upgrades) == 0 {
+		fmt.Fprintf(out, "OpenYurt components in %s are already at version %s\n", manifestDir, o.targetVersion)
+		return nil
+	}
+	for _, u := range upgrades {
+		for _, c := range u.changes {
+			fmt.Fprintf(out, "[upgrade] %s: %s -> %s\n", u.file, c.from, c.to)
+		}
+	}
+	if o.dryRun {
+		return nil
+	}
+
+	if err := backupManifests(upgrades, o.backupDir); err != nil {
+		return err
+	}
+	if err := writeManifests(upgrades, func(u *manifestUpgrade) []byte { return u.upgraded }); err != nil {
+		rollback(upgrades)
+		return errors.Wrap(err, "failed to replace manifests, the previous manifests are restored")
+	}
+
+	if err := verifyUpgrade(upgrades, o.nodeName, o.upgradeTimeout); err != nil {
+		rollback(upgrades)
+		fmt.Fprintf(out, "[upgrade] the upgrade failed, the previous manifests are restored from %s\n", o.backupDir)
+		return err
+	}
+	fmt.Fprintf(out, "[upgrade] OpenYurt components on node %s are upgraded to %s\n", o.nodeName, o.targetVersion)
+	return nil
+}
+
+// planUpgrade returns the manifests in dir whose OpenYurt images are not at the target version
+func planUpgrade(dir, version string) ([]*manifestUpgrade, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var upgrades []*manifestUpgrade
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		upgraded, changes := rewriteImages(data, version)
+		if len(changes) == 0 {
+			continue
+		}
+
+		pod := &v1.Pod{}
+		if err := yaml.Unmarshal(data, pod); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse manifest %s", file)
+		}
+		namespace := pod.Namespace
+		if len(namespace) == 0 {
+			namespace = metav1.NamespaceDefault
+		}
+		upgrades = append(upgrades, &manifestUpgrade{
+			file:      file,
+			namespace: namespace,
+			name:      pod.Name,
+			original:  data,
+			upgraded:  upgraded,
+			changes:   changes,
+		})
+	}
+	return upgrades, nil
+}
+
+// rewriteImages sets the tag of OpenYurt images in manifest to version, the other content of manifest is kept as it is.
+func rewriteImages(data []byte, version string) ([]byte, []imageChange) {
+	var changes []imageChange
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		m := imageLine.FindStringSubmatch(line)
+		if m == nil || m[2] != m[4] {
+			continue
+		}
+		image, ok := withTag(m[3], version)
+		if !ok || image == m[3] {
+			continue
+		}
+		lines[i] = m[1] + m[2] + image + m[4] + m[5]
+		changes = append(changes, imageChange{from: m[3], to: image})
+	}
+	return []byte(strings.Join(lines, "\n")), changes
+}
+
+// withTag returns the image with tag, false is returned if image is not an OpenYurt component.
+// The digest of image is dropped, because it pins the previous version.
+func withTag(image, tag string) (string, bool) {
+	repo := image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	if !openyurtComponents.Has(repo[strings.LastIndex(repo, "/")+1:]) {
+		return "", false
+	}
+	return repo + ":" + tag, true
+}
+
+// backupManifests saves the previous manifests in backup dir, so they can be restored manually
+// if yurtadm is interrupted during the upgrade.
+func backupManifests(upgrades []*manifestUpgrade, backupDir string) error {
+	if err := os.MkdirAll(backupDir, constants.DirMode); err != nil {
+		return err
+	}
+	for _, u := range upgrades {
+		if err := os.WriteFile(filepath.Join(backupDir, upgradeutil.WithBackupSuffix(filepath.Base(u.file))), u.original, 0600); err != nil {
+			return errors.Wrapf(err, "failed to back up manifest %s", u.file)
+		}
+	}
+	return nil
+}
+
+func writeManifests(upgrades []*manifestUpgrade, content func(*manifestUpgrade) []byte) error {
+	for _, u := range upgrades {
+		mode := os.FileMode(0600)
+		if info, err := os.Stat(u.file); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.WriteFile(u.file, content(u), mode); err != nil {
+			return errors.Wrapf(err, "failed to write manifest %s", u.file)
+		}
+	}
+	return nil
+}
+
+// rollback restores the previous manifests, kubelet restarts the static pods with them.
+func rollback(upgrades []*manifestUpgrade) {
+	if err := writeManifests(upgrades, func(u *manifestUpgrade) []byte { return u.original }); err != nil {
+		klog.Errorf("failed to restore the previous manifests, %v", err)
+	}
+}
+
+// verifyUpgrade waits for yurthub to be ready and the static pods of upgraded manifests to run the target images.
+// The pods are listed from yurthub, so yurthub is checked first.
+func verifyUpgrade(upgrades []*manifestUpgrade, nodeName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if err := wait.PollImmediate(verifyInterval, timeout, func() (bool, error) {
+		return checkYurthubReady(), nil
+	}); err != nil {
+		return errors.Errorf("yurthub is not ready in %v", timeout)
+	}
+
+	pending := make(map[string]*manifestUpgrade, len(upgrades))
+	for _, u := range upgrades {
+		pending[u.namespace+"/"+u.name+"-"+nodeName] = u
+	}
+	var lastErr error
+	err := wait.PollImmediate(verifyInterval, time.Until(deadline), func() (bool, error) {
+		pods, err := listPods()
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			key := pod.Namespace + "/" + pod.Name
+			u, ok := pending[key]
+			if !ok {
+				continue
+			}
+			ready, err := podUpgraded(pod, u.changes)
+			if err != nil {
+				return false, err
+			}
+			if ready {
+				klog.Infof("static pod %s is upgraded", key)
+				delete(pending, key)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if err != wait.ErrWaitTimeout {
+		return err
+	}
+
+	keys := make([]string, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if lastErr != nil {
+		return errors.Errorf("static pods %s are not ready in %v, %v", strings.Join(keys, ","), timeout, lastErr)
+	}
+	return errors.Errorf("static pods %s are not ready in %v", strings.Join(keys, ","), timeout)
+}
+
+// podUpgraded checks whether pod runs the upgraded images and is ready, an error is returned
+// if any container of pod is crash looping.
+func podUpgraded(pod *v1.Pod, changes []imageChange) (bool, error) {
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == upgradeutil.CrashLoopBackOffReason {
+			return false, errors.Errorf("container %s of pod %s/%s is crash looping", status.Name, pod.Namespace, pod.Name)
+		}
+	}
+
+	images := sets.NewString()
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		images.Insert(c.Image)
+	}
+	for _, c := range changes {
+		if !images.Has(c.to) {
+			return false, nil
+		}
+	}
+
+	if pod.Status.Phase != v1.PodRunning {
+		return false, nil
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
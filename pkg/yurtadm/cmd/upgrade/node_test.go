@@ -0,0 +1,233 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
+)
+
+const yurthubManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: yurt-hub
+  namespace: kube-system
+spec:
+  containers:
+  - image: "registry.cn-hangzhou.aliyuncs.com/openyurt/yurthub:v1.3.0" # pinned by yurtadm join
+    name: yurt-hub
+  - name: sidecar
+    image: busybox:1.36
+`
+
+const ravenManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: raven-agent
+  namespace: kube-system
+spec:
+  containers:
+  - name: raven-agent
+    image: openyurt/raven-agent@sha256:0123456789abcdef
+`
+
+func TestWithTag(t *testing.T) {
+	tests := map[string]struct {
+		image string
+		want  string
+		ok    bool
+	}{
+		"tagged image": {
+			image: "openyurt/yurthub:v1.3.0",
+			want:  "openyurt/yurthub:v1.4.0",
+			ok:    true,
+		},
+		"registry with port": {
+			image: "registry.local:5000/openyurt/node-servant",
+			want:  "registry.local:5000/openyurt/node-servant:v1.4.0",
+			ok:    true,
+		},
+		"image with digest": {
+			image: "openyurt/raven-agent:v1.3.0@sha256:0123456789abcdef",
+			want:  "openyurt/raven-agent:v1.4.0",
+			ok:    true,
+		},
+		"not openyurt component": {
+			image: "registry.local:5000/library/nginx:1.25",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := withTag(tt.image, "v1.4.0")
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("withTag(%s) = %s, %v, want %s, %v", tt.image, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	upgraded, changes := rewriteImages([]byte(yurthubManifest), "v1.4.0")
+	if len(changes) != 1 || changes[0].to != "registry.cn-hangzhou.aliyuncs.com/openyurt/yurthub:v1.4.0" {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	want := strings.Replace(yurthubManifest, "yurthub:v1.3.0", "yurthub:v1.4.0", 1)
+	if string(upgraded) != want {
+		t.Errorf("expect manifest\n%s\nbut got\n%s", want, upgraded)
+	}
+
+	if _, changes := rewriteImages(upgraded, "v1.4.0"); len(changes) != 0 {
+		t.Errorf("expect no changes for the upgraded manifest, but got %v", changes)
+	}
+}
+
+func writeManifestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"yurthub.yaml":     yurthubManifest,
+		"raven-agent.yaml": ravenManifest,
+		"etcd.yaml":        "apiVersion: v1\nkind: Pod\nmetadata:\n  name: etcd\nspec:\n  containers:\n  - image: etcd:3.5.0\n",
+		".hidden.yaml":     yurthubManifest,
+	}
+	for name, content := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	upgrades, err := planUpgrade(writeManifestDir(t), "v1.4.0")
+	if err != nil {
+		t.Fatalf("failed to plan upgrade, %v", err)
+	}
+	if len(upgrades) != 2 {
+		t.Fatalf("expect 2 manifests to upgrade, but got %d", len(upgrades))
+	}
+	for _, u := range upgrades {
+		if u.namespace != "kube-system" || (u.name != "yurt-hub" && u.name != "raven-agent") {
+			t.Errorf("unexpected static pod %s/%s", u.namespace, u.name)
+		}
+	}
+}
+
+func upgradedPod(name, image string, ready bool) v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: name},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "c", Image: image}}},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestRunUpgradeNode(t *testing.T) {
+	oldCheck, oldList, oldInterval := checkYurthubReady, listPods, verifyInterval
+	defer func() {
+		checkYurthubReady, listPods, verifyInterval = oldCheck, oldList, oldInterval
+	}()
+	checkYurthubReady = func() bool { return true }
+	verifyInterval = 10 * time.Millisecond
+
+	tests := map[string]struct {
+		ravenReady   bool
+		wantErr      bool
+		wantUpgraded bool
+	}{
+		"static pods are upgraded": {
+			ravenReady:   true,
+			wantUpgraded: true,
+		},
+		"rolled back when static pod is not ready": {
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			listPods = func() (*v1.PodList, error) {
+				return &v1.PodList{Items: []v1.Pod{
+					upgradedPod("yurt-hub-node1", "registry.cn-hangzhou.aliyuncs.com/openyurt/yurthub:v1.4.0", true),
+					upgradedPod("raven-agent-node1", "openyurt/raven-agent:v1.4.0", tt.ravenReady),
+				}}, nil
+			}
+
+			manifestDir := writeManifestDir(t)
+			o := &nodeOptions{
+				targetVersion:  "v1.4.0",
+				distro:         distro.Kubeadm,
+				nodeName:       "node1",
+				backupDir:      filepath.Join(t.TempDir(), "backup"),
+				upgradeTimeout: 200 * time.Millisecond,
+			}
+			var out bytes.Buffer
+			err := runUpgradeNode(o, manifestDir, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect error %v, but got %v, output:\n%s", tt.wantErr, err, out.String())
+			}
+
+			data, err := os.ReadFile(filepath.Join(manifestDir, "yurthub.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if upgraded := bytes.Contains(data, []byte("yurthub:v1.4.0")); upgraded != tt.wantUpgraded {
+				t.Errorf("expect yurthub manifest upgraded %v, but got\n%s", tt.wantUpgraded, data)
+			}
+			if _, err := os.Stat(filepath.Join(o.backupDir, "yurthub.yaml.bak")); err != nil {
+				t.Errorf("expect the previous manifest is backed up, %v", err)
+			}
+		})
+	}
+}
+
+func TestRunUpgradeNodeDryRun(t *testing.T) {
+	manifestDir := writeManifestDir(t)
+	o := &nodeOptions{targetVersion: "v1.4.0", distro: distro.Kubeadm, nodeName: "node1", dryRun: true}
+
+	var out bytes.Buffer
+	if err := runUpgradeNode(o, manifestDir, &out); err != nil {
+		t.Fatalf("failed to run dry run, %v", err)
+	}
+	if !strings.Contains(out.String(), "openyurt/raven-agent:v1.4.0") {
+		t.Errorf("expect raven agent is upgraded, but got %s", out.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(manifestDir, "raven-agent.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ravenManifest {
+		t.Errorf("expect manifest is not changed in dry run, but got\n%s", data)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util"
+)
+
+// NewCmdUpgrade returns "yurtadm upgrade" command.
+func NewCmdUpgrade(in io.Reader, out io.Writer, outErr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade OpenYurt components",
+		Run:   util.SubCmdRun(),
+	}
+
+	cmd.AddCommand(newCmdUpgradeNode(out))
+	return cmd
+}
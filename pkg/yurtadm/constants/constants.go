@@ -25,9 +25,12 @@ const (
 	YurtHubWorkdir                = "/var/lib/yurthub"
 	YurtHubBootstrapConfig        = "/var/lib/yurthub/bootstrap-hub.conf"
 	OpenyurtDir                   = "/var/lib/openyurt"
+	OfflineBundleDir              = "/var/lib/openyurt/offline-bundle"
+	UpgradeBackupDir              = "/var/lib/openyurt/upgrade-backup"
 	YurttunnelAgentWorkdir        = "/var/lib/yurttunnel-agent"
 	YurttunnelServerWorkdir       = "/var/lib/yurttunnel-server"
 	KubeCniDir                    = "/opt/cni/bin"
+	CniStateDir                   = "/var/lib/cni"
 	KubeCniVersion                = "v0.8.0"
 	KubeletServiceFilepath        = "/etc/systemd/system/kubelet.service"
 	KubeletServiceConfPath        = "/etc/systemd/system/kubelet.service.d/10-kubeadm.conf"
@@ -115,15 +118,66 @@ const (
 	ReuseCNIBin = "reuse-cni-bin"
 	// StaticPods flag set the specified static pods on this node want to install
 	StaticPods = "static-pods"
+	// OfflineBundle flag sets the path of offline bundle which contains binaries, images and yurthub cache for air-gapped join
+	OfflineBundle = "offline-bundle"
+	// PublicIP flag sets the public ip of node, or "auto" to detect it by metadata services and stun servers.
+	PublicIP = "public-ip"
+	// HubLeaderAddr flag sets the address of join info server on the hub leader node in nodepool, which is used
+	// for bootstrapping node when kube-apiserver is unreachable.
+	HubLeaderAddr = "hub-leader-addr"
+	// STUNServers flag sets the stun servers which are used to detect the public ip of node.
+	STUNServers = "stun-servers"
+	// Distro flag sets the kubernetes distro of node, kubeadm, k3s, rke2 or auto.
+	Distro = "distro"
+	// TargetVersion flag sets the OpenYurt version which the components on node are upgraded to.
+	TargetVersion = "target-version"
+	// UpgradeTimeout flag sets how long to wait for the upgraded components to be ready before rolling back.
+	UpgradeTimeout = "upgrade-timeout"
+	// BackupDir flag sets the dir where the manifests are backed up before they're upgraded.
+	BackupDir = "backup-dir"
+	// DryRun flag prints the changes without applying them.
+	DryRun = "dry-run"
+	// GPUPreflight flag enables the checks of gpu environment before joining a gpu pool.
+	GPUPreflight = "gpu-preflight"
+	// MinNvidiaDriverVersion flag sets the minimum nvidia driver version required by the gpu preflight.
+	MinNvidiaDriverVersion = "min-nvidia-driver-version"
+	// CreateNodePoolFrom flag sets the nodepool template which the nodepool is created from if it doesn't exist.
+	CreateNodePoolFrom = "create-nodepool-from"
+	// NodePoolCreationServer flag sets the address of nodepool creation service in yurt-manager.
+	NodePoolCreationServer = "nodepool-creation-server"
+	// NodePoolCreationCAFile flag sets the ca file which is used to verify the certificate of nodepool creation service.
+	NodePoolCreationCAFile = "nodepool-creation-ca-file"
+	// ThroughYurtHub flag proxies the requests to kube-apiserver through the local yurthub.
+	ThroughYurtHub = "through-yurthub"
+	// JoinConfig flag sets the path of yurtadm JoinConfiguration file.
+	JoinConfig = "join-config"
+	// Inventory flag sets the inventory file of hosts which are joined by ssh in parallel.
+	Inventory = "inventory"
+	// InventoryParallelism flag sets how many hosts in inventory are joined at the same time.
+	InventoryParallelism = "inventory-parallelism"
+	// TokenExchangeServer flag sets the address of token exchange service in yurt-manager, which exchanges
+	// external identity of node for a short-lived bootstrap token.
+	TokenExchangeServer = "token-exchange-server"
+	// TokenExchangeCAFile flag sets the ca file which is used to verify the certificate of token exchange service.
+	TokenExchangeCAFile = "token-exchange-ca-file"
+	// IdentityTokenFile flag sets the file of OIDC id token which is exchanged for a bootstrap token.
+	IdentityTokenFile = "identity-token-file"
+	// InstanceIdentityDocument flag sets the file of cloud instance identity document which is exchanged for a bootstrap token.
+	InstanceIdentityDocument = "instance-identity-document"
+	// InstanceIdentitySignature flag sets the file of base64 encoded signature of cloud instance identity document.
+	InstanceIdentitySignature = "instance-identity-signature"
 
 	DefaultServerAddr            = "https://127.0.0.1:6443"
 	ServerHealthzServer          = "127.0.0.1:10267"
 	ServerHealthzURLPath         = "/v1/healthz"
 	ServerReadyzURLPath          = "/v1/readyz"
+	ServerJoinInfoURLPath        = "/v1/join-info"
 	DefaultOpenYurtImageRegistry = "registry.cn-hangzhou.aliyuncs.com/openyurt"
 	Yurthub                      = "yurthub"
 	DefaultOpenYurtVersion       = "latest"
 	DefaultYurtHubServerAddr     = "127.0.0.1"
+	YurtHubProxyPort             = 10261
+	DefaultAdminKubeConfig       = "/etc/kubernetes/admin.conf"
 	DirMode                      = 0755
 	KubeletServiceContent        = `
 [Unit]
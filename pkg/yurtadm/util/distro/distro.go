@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distro
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Kubeadm means node is joined by kubeadm and kubelet runs as a standalone service
+	Kubeadm = "kubeadm"
+	// K3s means kubelet is embedded in k3s agent
+	K3s = "k3s"
+	// RKE2 means kubelet is started by rke2 agent
+	RKE2 = "rke2"
+	// Auto means the distro is detected from the agent services installed on node
+	Auto = "auto"
+
+	// openyurtConfigDropIn is the config file of agent which is written by yurtadm, it's loaded after
+	// the config files of users because files in config.yaml.d are loaded in alphabetical order.
+	openyurtConfigDropIn = "90-openyurt.yaml"
+)
+
+// Agent describes the node agent of a lightweight kubernetes distro, which runs kubelet by itself
+// and is configured by config files instead of kubelet flags.
+type Agent struct {
+	// Name is the name of distro.
+	Name string
+	// Service is the systemd service of agent.
+	Service string
+	// UnitFiles are the possible paths of agent unit file.
+	UnitFiles []string
+	// ConfigDir is the dir of agent config.yaml and config.yaml.d.
+	ConfigDir string
+	// DataDir is the dir of agent data, like certificates, kubeconfig and static pod manifests.
+	DataDir string
+}
+
+var agents = map[string]*Agent{
+	K3s: {
+		Name:      K3s,
+		Service:   "k3s-agent",
+		UnitFiles: []string{"/etc/systemd/system/k3s-agent.service", "/usr/local/lib/systemd/system/k3s-agent.service"},
+		ConfigDir: "/etc/rancher/k3s",
+		DataDir:   "/var/lib/rancher/k3s/agent",
+	},
+	RKE2: {
+		Name:      RKE2,
+		Service:   "rke2-agent",
+		UnitFiles: []string{"/usr/local/lib/systemd/system/rke2-agent.service", "/usr/lib/systemd/system/rke2-agent.service", "/etc/systemd/system/rke2-agent.service"},
+		ConfigDir: "/etc/rancher/rke2",
+		DataDir:   "/var/lib/rancher/rke2/agent",
+	},
+}
+
+// IsValid checks whether name is a supported distro or auto.
+func IsValid(name string) bool {
+	_, ok := agents[name]
+	return ok || name == Kubeadm || name == Auto
+}
+
+// GetAgent returns the agent of distro, nil is returned for kubeadm.
+func GetAgent(name string) *Agent {
+	return agents[name]
+}
+
+// Agents returns the agents of all supported distros.
+func Agents() []*Agent {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]*Agent, 0, len(names))
+	for _, name := range names {
+		list = append(list, agents[name])
+	}
+	return list
+}
+
+// Detect returns the distro whose agent unit file exists under root, kubeadm is returned if no agent is found.
+func Detect(root string) string {
+	for _, agent := range Agents() {
+		for _, unitFile := range agent.UnitFiles {
+			if _, err := os.Stat(filepath.Join(root, unitFile)); err == nil {
+				return agent.Name
+			}
+		}
+	}
+	return Kubeadm
+}
+
+// StaticPodPath returns the dir of static pod manifests which are started by agent.
+func (a *Agent) StaticPodPath() string {
+	return filepath.Join(a.DataDir, "pod-manifests")
+}
+
+// KubeletKubeConfig returns the kubeconfig which is generated by agent for kubelet.
+func (a *Agent) KubeletKubeConfig() string {
+	return filepath.Join(a.DataDir, "kubelet.kubeconfig")
+}
+
+// ClientKubeletCertFile returns the client certificate of kubelet which is issued when agent is joined.
+func (a *Agent) ClientKubeletCertFile() string {
+	return filepath.Join(a.DataDir, "client-kubelet.crt")
+}
+
+// ServerCAFile returns the ca file of kube-apiserver.
+func (a *Agent) ServerCAFile() string {
+	return filepath.Join(a.DataDir, "server-ca.crt")
+}
+
+// ConfigDropInFile returns the agent config file which is written by yurtadm.
+func (a *Agent) ConfigDropInFile() string {
+	return filepath.Join(a.ConfigDir, "config.yaml.d", openyurtConfigDropIn)
+}
+
+// CheckJoined checks whether agent is installed and has joined the cluster, because yurtadm converts
+// the node of agent instead of installing it.
+func (a *Agent) CheckJoined(root string) error {
+	installed := false
+	for _, unitFile := range a.UnitFiles {
+		if _, err := os.Stat(filepath.Join(root, unitFile)); err == nil {
+			installed = true
+			break
+		}
+	}
+	if !installed {
+		return fmt.Errorf("%s is not installed, unit file is not found in %s", a.Service, strings.Join(a.UnitFiles, ","))
+	}
+
+	for _, file := range []string{a.KubeletKubeConfig(), a.ClientKubeletCertFile(), a.ServerCAFile()} {
+		if _, err := os.Stat(filepath.Join(root, file)); err != nil {
+			return fmt.Errorf("%s has not joined the cluster, %s is not found", a.Service, file)
+		}
+	}
+	return nil
+}
+
+// agentConfig is the part of agent config which is managed by yurtadm, the keys end with "+"
+// so that the values are appended to the ones in the other config files instead of replacing them.
+type agentConfig struct {
+	KubeletArg []string `json:"kubelet-arg+,omitempty"`
+	NodeLabel  []string `json:"node-label+,omitempty"`
+}
+
+// ConfigDropIn returns the agent config which makes kubelet connect to kube-apiserver through yurthub
+// with kubeletKubeConfig, and adds nodeLabels into node.
+func (a *Agent) ConfigDropIn(kubeletKubeConfig string, nodeLabels map[string]string) ([]byte, error) {
+	cfg := agentConfig{
+		KubeletArg: []string{fmt.Sprintf("kubeconfig=%s", kubeletKubeConfig)},
+	}
+	for k, v := range nodeLabels {
+		cfg.NodeLabel = append(cfg.NodeLabel, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(cfg.NodeLabel)
+
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("# generated by yurtadm join, kubelet connects to kube-apiserver through yurthub\n"), data...), nil
+}
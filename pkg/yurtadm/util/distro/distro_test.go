@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package distro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func touch(t *testing.T, root string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("could not create dir for %s, %v", path, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("could not write %s, %v", path, err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testcases := map[string]struct {
+		files  []string
+		expect string
+	}{
+		"no agent": {
+			expect: Kubeadm,
+		},
+		"k3s agent": {
+			files:  []string{"/etc/systemd/system/k3s-agent.service"},
+			expect: K3s,
+		},
+		"rke2 agent": {
+			files:  []string{"/usr/lib/systemd/system/rke2-agent.service"},
+			expect: RKE2,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			root := t.TempDir()
+			touch(t, root, tc.files...)
+			if got := Detect(root); got != tc.expect {
+				t.Errorf("expect distro %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	for _, name := range []string{Kubeadm, K3s, RKE2, Auto} {
+		if !IsValid(name) {
+			t.Errorf("expect %s is valid", name)
+		}
+	}
+	if IsValid("microk8s") {
+		t.Errorf("expect microk8s is invalid")
+	}
+	if GetAgent(Kubeadm) != nil {
+		t.Errorf("expect no agent for kubeadm")
+	}
+}
+
+func TestCheckJoined(t *testing.T) {
+	agent := GetAgent(K3s)
+	testcases := map[string]struct {
+		files []string
+		isErr bool
+	}{
+		"agent is not installed": {
+			isErr: true,
+		},
+		"agent has not joined": {
+			files: []string{"/etc/systemd/system/k3s-agent.service"},
+			isErr: true,
+		},
+		"agent has joined": {
+			files: []string{
+				"/etc/systemd/system/k3s-agent.service",
+				"/var/lib/rancher/k3s/agent/kubelet.kubeconfig",
+				"/var/lib/rancher/k3s/agent/client-kubelet.crt",
+				"/var/lib/rancher/k3s/agent/server-ca.crt",
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			root := t.TempDir()
+			touch(t, root, tc.files...)
+			if err := agent.CheckJoined(root); tc.isErr != (err != nil) {
+				t.Errorf("expect error %v, but got %v", tc.isErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigDropIn(t *testing.T) {
+	agent := GetAgent(RKE2)
+	if agent.ConfigDropInFile() != "/etc/rancher/rke2/config.yaml.d/90-openyurt.yaml" {
+		t.Errorf("unexpected config drop-in file %s", agent.ConfigDropInFile())
+	}
+	if agent.StaticPodPath() != "/var/lib/rancher/rke2/agent/pod-manifests" {
+		t.Errorf("unexpected static pod path %s", agent.StaticPodPath())
+	}
+
+	data, err := agent.ConfigDropIn("/etc/kubernetes/kubelet.conf", map[string]string{
+		"openyurt.io/is-edge-worker": "true",
+		"apps.openyurt.io/nodepool":  "hangzhou",
+	})
+	if err != nil {
+		t.Fatalf("could not generate config drop-in, %v", err)
+	}
+	expect := `# generated by yurtadm join, kubelet connects to kube-apiserver through yurthub
+kubelet-arg+:
+- kubeconfig=/etc/kubernetes/kubelet.conf
+node-label+:
+- apps.openyurt.io/nodepool=hangzhou
+- openyurt.io/is-edge-worker=true
+`
+	if string(data) != expect {
+		t.Errorf("expect config drop-in\n%s\nbut got\n%s", expect, string(data))
+	}
+}
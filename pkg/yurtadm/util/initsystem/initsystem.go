@@ -27,4 +27,7 @@ type InitSystem interface {
 
 	// ServiceIsActive ensures the service is running, or attempting to run. (crash looping in the case of kubelet)
 	ServiceIsActive(service string) bool
+
+	// ServiceRestart tries to restart a specific service
+	ServiceRestart(service string) error
 }
@@ -22,6 +22,7 @@ package initsystem
 
 import (
 	"fmt"
+	"time"
 
 	"golang.org/x/sys/windows/svc"
 	"golang.org/x/sys/windows/svc/mgr"
@@ -94,6 +95,35 @@ func (sysd WindowsInitSystem) ServiceIsActive(service string) bool {
 	return status.State == svc.Running
 }
 
+// ServiceRestart tries to restart a specific service
+func (sysd WindowsInitSystem) ServiceRestart(service string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(service)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+	for deadline := time.Now().Add(30 * time.Second); status.State != svc.Stopped; {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for service %s to stop", service)
+		}
+		time.Sleep(time.Second)
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+	return s.Start()
+}
+
 // GetInitSystem returns an InitSystem for the current system, or nil
 // if we cannot detect a supported init system.
 // This indicates we will skip init system checks, not an error.
@@ -22,8 +22,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -48,8 +50,10 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/distro"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/edgenode"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/util/initsystem"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
 )
 
 const (
@@ -290,6 +294,16 @@ func EnableKubeletService() error {
 	return nil
 }
 
+// SetWindowsKubeletService registers kubelet as a windows service, which is started by kubeadm join.
+func SetWindowsKubeletService(data joindata.YurtJoinData) error {
+	klog.Info("Setting kubelet windows service.")
+	nodeReg := data.NodeRegistration()
+	service := windows.KubeletServiceFor(path.Join(constants.KubeletConfigureDir, constants.KubeletKubeConfigFileName),
+		constants.KubeletWorkdir, nodeReg.Name, nodeReg.CRISocket, data.PauseImage(),
+		constructNodeLabels(data.NodeLabels(), nodeReg.WorkingMode, projectinfo.GetEdgeWorkerLabelKey()))
+	return windows.InstallService(service, false)
+}
+
 // SetKubeletUnitConfig configure kubelet startup parameters.
 func SetKubeletUnitConfig() error {
 	kubeletUnitDir := filepath.Dir(constants.KubeletServiceConfPath)
@@ -430,18 +444,59 @@ func SetKubeadmJoinConfig(data joindata.YurtJoinData) error {
 	return nil
 }
 
-// constructNodeLabels make up node labels string
-func constructNodeLabels(nodeLabels map[string]string, workingMode, edgeWorkerLabel string) string {
-	if nodeLabels == nil {
-		nodeLabels = make(map[string]string)
+// SetAgentConfig writes the config of k3s or rke2 agent, so that kubelet started by agent connects to
+// kube-apiserver through yurthub, and node labels are added into node.
+func SetAgentConfig(data joindata.YurtJoinData, agent *distro.Agent) error {
+	nodeReg := data.NodeRegistration()
+	nodeLabels := withEdgeWorkerLabel(data.NodeLabels(), nodeReg.WorkingMode, projectinfo.GetEdgeWorkerLabelKey())
+	content, err := agent.ConfigDropIn(filepath.Join(constants.KubeletConfigureDir, constants.KubeletKubeConfigFileName), nodeLabels)
+	if err != nil {
+		return err
+	}
+
+	dropInFile := agent.ConfigDropInFile()
+	if err := os.MkdirAll(filepath.Dir(dropInFile), constants.DirMode); err != nil {
+		klog.Errorf("Create dir %s fail: %v", filepath.Dir(dropInFile), err)
+		return err
+	}
+	if err := os.WriteFile(dropInFile, content, 0600); err != nil {
+		return err
+	}
+	klog.Infof("%s agent config is written into %s", agent.Name, dropInFile)
+	return nil
+}
+
+// RestartService restarts the service, it's used for making agent load the new config.
+func RestartService(service string) error {
+	initSystem, err := initsystem.GetInitSystem()
+	if err != nil {
+		return err
+	}
+	if err := initSystem.ServiceRestart(service); err != nil {
+		return fmt.Errorf("restart %s service failed, %v", service, err)
+	}
+	return nil
+}
+
+// withEdgeWorkerLabel returns node labels with edge worker label which is set by working mode if it's not specified.
+func withEdgeWorkerLabel(nodeLabels map[string]string, workingMode, edgeWorkerLabel string) map[string]string {
+	labels := make(map[string]string, len(nodeLabels)+1)
+	for k, v := range nodeLabels {
+		labels[k] = v
 	}
-	if _, ok := nodeLabels[edgeWorkerLabel]; !ok {
+	if _, ok := labels[edgeWorkerLabel]; !ok {
 		if workingMode == "cloud" {
-			nodeLabels[edgeWorkerLabel] = "false"
+			labels[edgeWorkerLabel] = "false"
 		} else {
-			nodeLabels[edgeWorkerLabel] = "true"
+			labels[edgeWorkerLabel] = "true"
 		}
 	}
+	return labels
+}
+
+// constructNodeLabels make up node labels string
+func constructNodeLabels(nodeLabels map[string]string, workingMode, edgeWorkerLabel string) string {
+	nodeLabels = withEdgeWorkerLabel(nodeLabels, workingMode, edgeWorkerLabel)
 	var labelsStr string
 	for k, v := range nodeLabels {
 		if len(labelsStr) == 0 {
@@ -455,13 +510,22 @@ func constructNodeLabels(nodeLabels map[string]string, workingMode, edgeWorkerLa
 }
 
 func SetDiscoveryConfig(data joindata.YurtJoinData) error {
-	cfg, err := token.RetrieveValidatedConfigInfo(nil, &token.BootstrapData{
-		ServerAddr:   data.ServerAddr(),
-		JoinToken:    data.JoinToken(),
-		CaCertHashes: data.CaCertHashes(),
-	})
-	if err != nil {
-		return err
+	var cfg *clientcmdapi.Config
+	if data.RegistrationDeferred() {
+		// kube-apiserver is unreachable, so the cluster-info in join info from hub leader is used,
+		// which has been validated when tls bootstrap config is created.
+		cluster := kubeconfigutil.GetClusterFromKubeConfig(data.TLSBootstrapCfg())
+		cfg = kubeconfigutil.CreateBasic(cluster.Server, "kubernetes", "", cluster.CertificateAuthorityData)
+	} else {
+		var err error
+		cfg, err = token.RetrieveValidatedConfigInfo(nil, &token.BootstrapData{
+			ServerAddr:   data.ServerAddr(),
+			JoinToken:    data.JoinToken(),
+			CaCertHashes: data.CaCertHashes(),
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	cluster := kubeconfigutil.GetClusterFromKubeConfig(cfg)
@@ -497,14 +561,60 @@ func RetrieveBootstrapConfig(data joindata.YurtJoinData) (*clientcmdapi.Config,
 	), nil
 }
 
+// RetrieveBootstrapConfigFromJoinInfo creates tls bootstrap config with the cluster-info in join info which is
+// cached by yurthub in nodepool, the cluster-info is validated in the same way as it's got from kube-apiserver.
+func RetrieveBootstrapConfigFromJoinInfo(data joindata.YurtJoinData, info *token.JoinInfo) (*clientcmdapi.Config, error) {
+	cfg, err := token.ValidateCachedClusterInfo(info.ClusterInfo, &token.BootstrapData{
+		ServerAddr:   data.ServerAddr(),
+		JoinToken:    data.JoinToken(),
+		CaCertHashes: data.CaCertHashes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clusterinfo := kubeconfigutil.GetClusterFromKubeConfig(cfg)
+	return kubeconfigutil.CreateWithToken(
+		// If there are multiple master IP addresses, take the first one here
+		fmt.Sprintf("https://%s", strings.Split(data.ServerAddr(), ",")[0]),
+		"kubernetes",
+		TokenUser,
+		clusterinfo.CertificateAuthorityData,
+		data.JoinToken(),
+	), nil
+}
+
+// IsAPIServerReachable checks whether any of kube-apiserver addresses(e.g. 1.2.3.4:6443,1.2.3.5:6443) can be connected.
+func IsAPIServerReachable(serverAddr string, timeout time.Duration) bool {
+	for _, addr := range strings.Split(serverAddr, ",") {
+		addr = strings.TrimPrefix(strings.TrimSpace(addr), "https://")
+		if len(addr) == 0 {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			klog.V(4).Infof("kube-apiserver %s is unreachable, %v", addr, err)
+			continue
+		}
+		conn.Close()
+		return true
+	}
+	return false
+}
+
 // CheckKubeletStatus check if kubelet is healthy.
 func CheckKubeletStatus() error {
+	return CheckServiceStatus("kubelet")
+}
+
+// CheckServiceStatus check if service is active.
+func CheckServiceStatus(service string) error {
 	initSystem, err := initsystem.GetInitSystem()
 	if err != nil {
 		return err
 	}
-	if ok := initSystem.ServiceIsActive("kubelet"); !ok {
-		return fmt.Errorf("kubelet is not active. ")
+	if ok := initSystem.ServiceIsActive(service); !ok {
+		return fmt.Errorf("%s is not active. ", service)
 	}
 	return nil
 }
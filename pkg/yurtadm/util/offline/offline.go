@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline installs the offline bundle for joining nodes at disconnected sites. The bundle is
+// a tar.gz archive with the following layout, and every part of it is optional:
+//
+//	bin/kubelet, bin/kubeadm    binaries installed if they're not found on node
+//	cni/*.tgz                   archives of CNI plugins, extracted to /opt/cni/bin
+//	images/*.tar                image archives loaded into container runtime
+//	yurthub-cache/              preseeded cache of yurthub, so node is autonomous before it talks to cloud
+package offline
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/node-servant/components"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util"
+)
+
+const (
+	BinDir          = "bin"
+	CNIDir          = "cni"
+	ImagesDir       = "images"
+	YurtHubCacheDir = "yurthub-cache"
+)
+
+// Bundle is an extracted offline bundle.
+type Bundle struct {
+	Dir string
+}
+
+// Extract extracts the offline bundle into dest.
+func Extract(bundle, dest string) (*Bundle, error) {
+	f, err := os.Open(bundle)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("offline bundle %s is not a tar.gz archive, %v", bundle, err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		target := filepath.Join(dest, hdr.Name)
+		if target != dest && !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("file %s in offline bundle is out of %s", hdr.Name, dest)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(hdr.Mode)); err != nil {
+				return nil, err
+			}
+		default:
+			klog.Warningf("skip file %s of type %c in offline bundle", hdr.Name, hdr.Typeflag)
+		}
+	}
+	return &Bundle{Dir: dest}, nil
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// InstallBinaries installs the binaries in bundle into binDir if they're not found on node, an error is
+// returned if any of them is neither found on node nor in bundle, because nothing can be downloaded.
+func (b *Bundle) InstallBinaries(binDir string, names ...string) error {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err == nil {
+			klog.Infof("%s already exists, skip installing it from offline bundle", name)
+			continue
+		}
+		src := filepath.Join(b.Dir, BinDir, name)
+		if _, err := os.Stat(src); err != nil {
+			return fmt.Errorf("%s is neither installed on node nor found in offline bundle, %v", name, err)
+		}
+		if err := copyFile(src, filepath.Join(binDir, name), 0755); err != nil {
+			return err
+		}
+		klog.Infof("install %s from offline bundle", name)
+	}
+	return nil
+}
+
+// InstallCNI extracts the CNI plugins archives in bundle into cniDir, false is returned if
+// no CNI plugins are found in bundle.
+func (b *Bundle) InstallCNI(cniDir string) (bool, error) {
+	archives, err := filepath.Glob(filepath.Join(b.Dir, CNIDir, "*.tgz"))
+	if err != nil || len(archives) == 0 {
+		return false, err
+	}
+	if err := os.MkdirAll(cniDir, 0755); err != nil {
+		return false, err
+	}
+	for _, archive := range archives {
+		if err := util.Untar(archive, cniDir); err != nil {
+			return false, fmt.Errorf("fail to extract CNI plugins %s, %v", archive, err)
+		}
+	}
+	return true, nil
+}
+
+// LoadImages loads the image archives in bundle into container runtime.
+func (b *Bundle) LoadImages(runtime components.ContainerRuntimeForImage) error {
+	archives, err := filepath.Glob(filepath.Join(b.Dir, ImagesDir, "*.tar"))
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		if err := runtime.LoadImage(archive); err != nil {
+			return fmt.Errorf("fail to load image archive %s, %v", archive, err)
+		}
+		klog.Infof("load image archive %s from offline bundle", filepath.Base(archive))
+	}
+	return nil
+}
+
+// SeedYurtHubCache copies the preseeded yurthub cache in bundle into cacheDir, existing files are kept.
+func (b *Bundle) SeedYurtHubCache(cacheDir string) error {
+	src := filepath.Join(b.Dir, YurtHubCacheDir)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(cacheDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dest string, mode os.FileMode) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFile(dest, f, mode)
+}
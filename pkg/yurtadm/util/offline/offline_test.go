@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTarGz returns the tar.gz archive of files
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("fail to write tar header, %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("fail to write tar content, %v", err)
+		}
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+type fakeRuntime struct {
+	loaded []string
+}
+
+func (r *fakeRuntime) IsDocker() bool {
+	return false
+}
+
+func (r *fakeRuntime) PullImage(image string) error {
+	return nil
+}
+
+func (r *fakeRuntime) ImageExists(image string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeRuntime) LoadImage(archive string) error {
+	r.loaded = append(r.loaded, filepath.Base(archive))
+	return nil
+}
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	cni := newTarGz(t, map[string]string{"bridge": "bridge"})
+	archive := filepath.Join(dir, "bundle.tar.gz")
+	if err := os.WriteFile(archive, newTarGz(t, map[string]string{
+		"bin/yurtadm-offline-test":   "binary",
+		"cni/cni-plugins.tgz":        string(cni),
+		"images/pause.tar":           "pause",
+		"images/yurthub.tar":         "yurthub",
+		"yurthub-cache/kubelet/pods": "cached",
+		"yurthub-cache/kubelet/svcs": "cached",
+	}), 0644); err != nil {
+		t.Fatalf("fail to write bundle, %v", err)
+	}
+
+	bundle, err := Extract(archive, filepath.Join(dir, "extracted"))
+	if err != nil {
+		t.Fatalf("fail to extract bundle, %v", err)
+	}
+
+	binDir := filepath.Join(dir, "bin")
+	if err := bundle.InstallBinaries(binDir, "yurtadm-offline-test"); err != nil {
+		t.Errorf("fail to install binaries, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "yurtadm-offline-test")); err != nil {
+		t.Errorf("expect binary installed, %v", err)
+	}
+	if err := bundle.InstallBinaries(binDir, "yurtadm-offline-missing"); err == nil {
+		t.Errorf("expect error when binary is not found")
+	}
+
+	cniDir := filepath.Join(dir, "cni")
+	if installed, err := bundle.InstallCNI(cniDir); err != nil || !installed {
+		t.Errorf("expect CNI plugins installed, but got %v, %v", installed, err)
+	}
+	if _, err := os.Stat(filepath.Join(cniDir, "bridge")); err != nil {
+		t.Errorf("expect CNI plugin extracted, %v", err)
+	}
+
+	runtime := &fakeRuntime{}
+	if err := bundle.LoadImages(runtime); err != nil {
+		t.Errorf("fail to load images, %v", err)
+	}
+	if expect := []string{"pause.tar", "yurthub.tar"}; !reflect.DeepEqual(runtime.loaded, expect) {
+		t.Errorf("expect images %v loaded, but got %v", expect, runtime.loaded)
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	if err := os.MkdirAll(filepath.Join(cacheDir, "kubelet"), 0755); err != nil {
+		t.Fatalf("fail to create cache dir, %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "kubelet", "svcs"), []byte("existing"), 0644); err != nil {
+		t.Fatalf("fail to write cache, %v", err)
+	}
+	if err := bundle.SeedYurtHubCache(cacheDir); err != nil {
+		t.Errorf("fail to seed yurthub cache, %v", err)
+	}
+	for file, expect := range map[string]string{"pods": "cached", "svcs": "existing"} {
+		if content, _ := os.ReadFile(filepath.Join(cacheDir, "kubelet", file)); string(content) != expect {
+			t.Errorf("expect cache %s is %q, but got %q", file, expect, content)
+		}
+	}
+}
+
+func TestExtractOutOfDest(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "bundle.tar.gz")
+	if err := os.WriteFile(archive, newTarGz(t, map[string]string{"../evil": "evil"}), 0644); err != nil {
+		t.Fatalf("fail to write bundle, %v", err)
+	}
+	if _, err := Extract(archive, filepath.Join(dir, "extracted")); err == nil {
+		t.Errorf("expect error when file is out of dest")
+	}
+}
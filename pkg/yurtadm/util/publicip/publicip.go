@@ -0,0 +1,288 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publicip
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Auto means the public ip of node is detected by metadata services and stun servers.
+	Auto = "auto"
+
+	DefaultSTUNServer = "stun.l.google.com:19302"
+	defaultTimeout    = 3 * time.Second
+
+	stunBindingRequest     = 0x0001
+	stunBindingSuccess     = 0x0101
+	stunMagicCookie        = 0x2112A442
+	stunHeaderLen          = 20
+	stunAttrMappedAddr     = 0x0001
+	stunAttrXorMappedAddr  = 0x0020
+	stunAddrFamilyIPv4     = 0x01
+	stunAddrFamilyIPv6     = 0x02
+	maxMetadataResponseLen = 256
+)
+
+// MetadataEndpoint is the metadata service of cloud provider which returns the public ip of instance.
+type MetadataEndpoint struct {
+	Provider string
+	URL      string
+	Header   map[string]string
+}
+
+// DefaultMetadataEndpoints are the metadata services of well-known cloud providers.
+var DefaultMetadataEndpoints = []MetadataEndpoint{
+	{
+		Provider: "aws",
+		URL:      "http://169.254.169.254/latest/meta-data/public-ipv4",
+	},
+	{
+		Provider: "alibaba",
+		URL:      "http://100.100.100.200/latest/meta-data/eipv4",
+	},
+	{
+		Provider: "gcp",
+		URL:      "http://169.254.169.254/computeMetadata/v1/instance/network-interfaces/0/access-configs/0/external-ip",
+		Header:   map[string]string{"Metadata-Flavor": "Google"},
+	},
+}
+
+// Detector detects the public ip of node from metadata services first, and then stun servers.
+type Detector struct {
+	MetadataEndpoints []MetadataEndpoint
+	STUNServers       []string
+	Timeout           time.Duration
+}
+
+// NewDetector returns a Detector with the default metadata services and the specified stun servers.
+func NewDetector(stunServers []string) *Detector {
+	return &Detector{
+		MetadataEndpoints: DefaultMetadataEndpoints,
+		STUNServers:       stunServers,
+		Timeout:           defaultTimeout,
+	}
+}
+
+// Resolve returns the public ip specified by value, or detects it when value is Auto.
+func (d *Detector) Resolve(value string) (string, error) {
+	if value != Auto {
+		if net.ParseIP(value) == nil {
+			return "", errors.Errorf("public ip %s is invalid", value)
+		}
+		return value, nil
+	}
+	return d.Detect()
+}
+
+// Detect returns the first public ip found by metadata services or stun servers.
+func (d *Detector) Detect() (string, error) {
+	client := &http.Client{Timeout: d.Timeout}
+	for _, ep := range d.MetadataEndpoints {
+		ip, err := fromMetadata(client, ep)
+		if err != nil {
+			klog.V(4).Infof("could not get public ip from metadata service of %s, %v", ep.Provider, err)
+			continue
+		}
+		klog.Infof("public ip %s is detected from metadata service of %s", ip, ep.Provider)
+		return ip, nil
+	}
+
+	for _, server := range d.STUNServers {
+		ip, err := fromSTUN(server, d.Timeout)
+		if err != nil {
+			klog.V(4).Infof("could not get public ip from stun server %s, %v", server, err)
+			continue
+		}
+		klog.Infof("public ip %s is detected from stun server %s", ip, server)
+		return ip, nil
+	}
+	return "", errors.Errorf("public ip is not detected by %s", d)
+}
+
+func fromMetadata(client *http.Client, ep MetadataEndpoint) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, ep.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range ep.Header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataResponseLen))
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return "", errors.Errorf("response %q is not an ip", body)
+	}
+	return ip.String(), nil
+}
+
+// fromSTUN sends a binding request to the stun server and returns the mapped address in response(RFC 5389).
+func fromSTUN(server string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+
+	req := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(req[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:8], stunMagicCookie)
+	if _, err := rand.Read(req[8:stunHeaderLen]); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write(req); err != nil {
+		return "", err
+	}
+
+	resp := make([]byte, 1024)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return "", err
+	}
+	ip, err := parseSTUNResponse(resp[:n], req[8:stunHeaderLen])
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// parseSTUNResponse returns the address in XOR-MAPPED-ADDRESS or MAPPED-ADDRESS attribute
+// of binding success response with the specified transaction id.
+func parseSTUNResponse(data, transactionID []byte) (net.IP, error) {
+	if len(data) < stunHeaderLen {
+		return nil, errors.New("stun response is too short")
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != stunBindingSuccess {
+		return nil, errors.Errorf("unexpected stun message type 0x%04x", binary.BigEndian.Uint16(data[0:2]))
+	}
+	if binary.BigEndian.Uint32(data[4:8]) != stunMagicCookie || !bytes.Equal(data[8:stunHeaderLen], transactionID) {
+		return nil, errors.New("stun response doesn't match the request")
+	}
+
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if stunHeaderLen+length > len(data) {
+		return nil, errors.New("stun response is truncated")
+	}
+	attrs := data[stunHeaderLen : stunHeaderLen+length]
+
+	var mapped net.IP
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			return nil, errors.New("stun attribute is truncated")
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXorMappedAddr:
+			ip, err := parseSTUNAddress(value, true, data[4:stunHeaderLen])
+			if err != nil {
+				return nil, err
+			}
+			return ip, nil
+		case stunAttrMappedAddr:
+			ip, err := parseSTUNAddress(value, false, nil)
+			if err != nil {
+				return nil, err
+			}
+			mapped = ip
+		}
+
+		// attributes are padded to a multiple of 4 bytes
+		padded := (4 + attrLen + 3) &^ 3
+		if padded > len(attrs) {
+			break
+		}
+		attrs = attrs[padded:]
+	}
+
+	if mapped == nil {
+		return nil, errors.New("no mapped address in stun response")
+	}
+	return mapped, nil
+}
+
+// parseSTUNAddress parses the address attribute, the xor key is magic cookie and transaction id.
+func parseSTUNAddress(value []byte, xor bool, key []byte) (net.IP, error) {
+	if len(value) < 4 {
+		return nil, errors.New("stun address attribute is too short")
+	}
+
+	var size int
+	switch value[1] {
+	case stunAddrFamilyIPv4:
+		size = net.IPv4len
+	case stunAddrFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return nil, errors.Errorf("unknown address family %d", value[1])
+	}
+	if len(value) < 4+size {
+		return nil, errors.New("stun address attribute is too short")
+	}
+
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if xor {
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	return ip, nil
+}
+
+// String returns the description of sources which are used to detect public ip.
+func (d *Detector) String() string {
+	providers := make([]string, 0, len(d.MetadataEndpoints))
+	for _, ep := range d.MetadataEndpoints {
+		providers = append(providers, ep.Provider)
+	}
+	return fmt.Sprintf("metadata services(%s), stun servers(%s)", strings.Join(providers, ","), strings.Join(d.STUNServers, ","))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publicip
+
+import (
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeSTUNServer answers binding requests with the XOR-MAPPED-ADDRESS of mappedIP.
+func fakeSTUNServer(t *testing.T, mappedIP net.IP) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderLen {
+				continue
+			}
+			ip := mappedIP.To4()
+			resp := make([]byte, stunHeaderLen+12)
+			binary.BigEndian.PutUint16(resp[0:2], stunBindingSuccess)
+			binary.BigEndian.PutUint16(resp[2:4], 12)
+			copy(resp[4:stunHeaderLen], buf[4:stunHeaderLen])
+			binary.BigEndian.PutUint16(resp[20:22], stunAttrXorMappedAddr)
+			binary.BigEndian.PutUint16(resp[22:24], 8)
+			resp[25] = stunAddrFamilyIPv4
+			binary.BigEndian.PutUint16(resp[26:28], 12345^uint16(stunMagicCookie>>16))
+			for i := range ip {
+				resp[28+i] = ip[i] ^ buf[4+i]
+			}
+			if _, err := conn.WriteTo(resp, addr); err != nil {
+				return
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestDetect(t *testing.T) {
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("203.0.113.10\n"))
+	}))
+	defer metadata.Close()
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	stunServer := fakeSTUNServer(t, net.ParseIP("198.51.100.20"))
+
+	testcases := map[string]struct {
+		metadata []MetadataEndpoint
+		stun     []string
+		expect   string
+		wantErr  bool
+	}{
+		"from metadata": {
+			metadata: []MetadataEndpoint{
+				{Provider: "foo", URL: notFound.URL},
+				{Provider: "gcp", URL: metadata.URL, Header: map[string]string{"Metadata-Flavor": "Google"}},
+			},
+			stun:   []string{stunServer},
+			expect: "203.0.113.10",
+		},
+		"metadata without header": {
+			metadata: []MetadataEndpoint{{Provider: "gcp", URL: metadata.URL}},
+			stun:     []string{stunServer},
+			expect:   "198.51.100.20",
+		},
+		"from stun": {
+			metadata: []MetadataEndpoint{{Provider: "foo", URL: notFound.URL}},
+			stun:     []string{stunServer},
+			expect:   "198.51.100.20",
+		},
+		"not detected": {
+			metadata: []MetadataEndpoint{{Provider: "foo", URL: notFound.URL}},
+			wantErr:  true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			d := &Detector{MetadataEndpoints: tc.metadata, STUNServers: tc.stun, Timeout: time.Second}
+			ip, err := d.Resolve(Auto)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if ip != tc.expect {
+				t.Errorf("expect ip %s, but got %s", tc.expect, ip)
+			}
+		})
+	}
+}
+
+func TestResolveExplicit(t *testing.T) {
+	d := &Detector{}
+	if ip, err := d.Resolve("203.0.113.1"); err != nil || ip != "203.0.113.1" {
+		t.Errorf("expect 203.0.113.1, but got %s, %v", ip, err)
+	}
+	if _, err := d.Resolve("foo"); err == nil {
+		t.Errorf("expect error for invalid ip")
+	}
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	tid := []byte("abcdefghijkl")
+	header := func(msgType uint16, length int) []byte {
+		b := make([]byte, stunHeaderLen)
+		binary.BigEndian.PutUint16(b[0:2], msgType)
+		binary.BigEndian.PutUint16(b[2:4], uint16(length))
+		binary.BigEndian.PutUint32(b[4:8], stunMagicCookie)
+		copy(b[8:], tid)
+		return b
+	}
+
+	mapped := append(header(stunBindingSuccess, 12), 0, byte(stunAttrMappedAddr), 0, 8, 0, stunAddrFamilyIPv4, 0x30, 0x39, 192, 0, 2, 1)
+	if ip, err := parseSTUNResponse(mapped, tid); err != nil || ip.String() != "192.0.2.1" {
+		t.Errorf("expect 192.0.2.1, but got %v, %v", ip, err)
+	}
+
+	if _, err := parseSTUNResponse(header(stunBindingSuccess, 0), []byte("000000000000")); err == nil {
+		t.Errorf("expect error for mismatched transaction id")
+	}
+	if _, err := parseSTUNResponse(header(stunBindingRequest, 0), tid); err == nil {
+		t.Errorf("expect error for non-response message")
+	}
+	if _, err := parseSTUNResponse(header(stunBindingSuccess, 0), tid); err == nil {
+		t.Errorf("expect error without mapped address")
+	}
+}
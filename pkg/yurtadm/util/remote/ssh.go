@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSSHPort     = 22
+	defaultDialTimeout = 30 * time.Second
+)
+
+// Config is the configuration for connecting to a remote host by ssh.
+type Config struct {
+	// Address is the ip or hostname of remote host.
+	Address string
+	// Port is the ssh port of remote host, 22 is used if it's not specified.
+	Port int
+	// User is the login user.
+	User string
+	// Password is used for password authentication.
+	Password string
+	// PrivateKeyFile is used for public key authentication.
+	PrivateKeyFile string
+	// KnownHostsFile is used for verifying the host key of remote host, ~/.ssh/known_hosts is used if it's not specified.
+	KnownHostsFile string
+	// InsecureIgnoreHostKey means host key of remote host is not verified.
+	InsecureIgnoreHostKey bool
+	// Timeout is the timeout for establishing connection.
+	Timeout time.Duration
+}
+
+// Client runs commands on a remote host by ssh.
+type Client struct {
+	client *ssh.Client
+}
+
+// Dial connects to the remote host specified by cfg.
+func Dial(cfg *Config) (*Client, error) {
+	auths := make([]ssh.AuthMethod, 0, 2)
+	if len(cfg.PrivateKeyFile) != 0 {
+		key, err := os.ReadFile(expandHome(cfg.PrivateKeyFile))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read private key file %s", cfg.PrivateKeyFile)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse private key file %s", cfg.PrivateKeyFile)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if len(cfg.Password) != 0 {
+		auths = append(auths, ssh.Password(cfg.Password))
+	}
+	if len(auths) == 0 {
+		return nil, errors.Errorf("neither private key file nor password is specified for %s", cfg.Address)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !cfg.InsecureIgnoreHostKey {
+		knownHostsFile := cfg.KnownHostsFile
+		if len(knownHostsFile) == 0 {
+			knownHostsFile = "~/.ssh/known_hosts"
+		}
+		callback, err := knownhosts.New(expandHome(knownHostsFile))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load known hosts file %s", knownHostsFile)
+		}
+		hostKeyCallback = callback
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultDialTimeout
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(cfg.Address, strconv.Itoa(port)), &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: client}, nil
+}
+
+// Run runs the command on remote host, and the output of command is written into stdout and stderr.
+func (c *Client) Run(cmd string, stdout, stderr io.Writer) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	return session.Run(cmd)
+}
+
+// Upload writes the content of src into file dst on remote host with the specified mode.
+func (c *Client) Upload(src io.Reader, dst string, mode os.FileMode) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = src
+	var stderr strings.Builder
+	session.Stderr = &stderr
+	cmd := fmt.Sprintf("mkdir -p %s && cat > %s && chmod %o %s", Quote(filepath.Dir(dst)), Quote(dst), mode.Perm(), Quote(dst))
+	if err := session.Run(cmd); err != nil {
+		return errors.Wrapf(err, "could not upload %s, %s", dst, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Close closes the connection to remote host.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// Quote quotes s so that it's passed as a single word to the remote shell.
+func Quote(s string) string {
+	if len(s) == 0 {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,@+%", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
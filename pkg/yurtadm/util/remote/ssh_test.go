@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	testcases := map[string]struct {
+		s      string
+		expect string
+	}{
+		"empty string": {
+			s:      "",
+			expect: "''",
+		},
+		"plain word": {
+			s:      "--token=abcdef.0123456789abcdef",
+			expect: "--token=abcdef.0123456789abcdef",
+		},
+		"string with space": {
+			s:      "--node-labels=a=b c",
+			expect: "'--node-labels=a=b c'",
+		},
+		"string with single quote": {
+			s:      "it's",
+			expect: `'it'"'"'s'`,
+		},
+		"string with shell characters": {
+			s:      "$(reboot);",
+			expect: "'$(reboot);'",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := Quote(tc.s); got != tc.expect {
+				t.Errorf("expect %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestDialWithoutAuth(t *testing.T) {
+	if _, err := Dial(&Config{Address: "127.0.0.1", User: "root"}); err == nil {
+		t.Errorf("expect error when neither private key file nor password is specified")
+	}
+}
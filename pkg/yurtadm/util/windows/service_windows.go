@@ -0,0 +1,112 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windows
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+	"k8s.io/klog/v2"
+)
+
+// InstallService registers the service to start automatically on each boot and to be restarted on failure,
+// the config of service is updated if it exists. The service is (re)started if start is true.
+func InstallService(s *Service, start bool) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	config := mgr.Config{
+		DisplayName: s.DisplayName,
+		Description: s.Description,
+		StartType:   mgr.StartAutomatic,
+	}
+	service, err := m.OpenService(s.Name)
+	if err != nil {
+		klog.Infof("create windows service %s", s.Name)
+		service, err = m.CreateService(s.Name, s.BinaryPath, config, s.Args...)
+		if err != nil {
+			return fmt.Errorf("could not create service %s, %v", s.Name, err)
+		}
+	} else {
+		klog.Infof("update windows service %s", s.Name)
+		current, err := service.Config()
+		if err != nil {
+			service.Close()
+			return err
+		}
+		current.DisplayName = config.DisplayName
+		current.Description = config.Description
+		current.StartType = config.StartType
+		current.BinaryPathName = commandLine(s.BinaryPath, s.Args)
+		if err := service.UpdateConfig(current); err != nil {
+			service.Close()
+			return fmt.Errorf("could not update service %s, %v", s.Name, err)
+		}
+	}
+	defer service.Close()
+
+	recovery := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+	}
+	if err := service.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("could not set recovery actions of service %s, %v", s.Name, err)
+	}
+	if !start {
+		return nil
+	}
+
+	status, err := service.Query()
+	if err != nil {
+		return err
+	}
+	if status.State != svc.Stopped {
+		if status, err = service.Control(svc.Stop); err != nil {
+			return err
+		}
+		for deadline := time.Now().Add(30 * time.Second); status.State != svc.Stopped; {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timeout waiting for service %s to stop", s.Name)
+			}
+			time.Sleep(time.Second)
+			if status, err = service.Query(); err != nil {
+				return err
+			}
+		}
+	}
+	return service.Start()
+}
+
+// commandLine returns the escaped command line of service the same as mgr.CreateService.
+func commandLine(binaryPath string, args []string) string {
+	parts := []string{syscall.EscapeArg(binaryPath)}
+	for _, arg := range args {
+		parts = append(parts, syscall.EscapeArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
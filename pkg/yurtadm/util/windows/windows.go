@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windows
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util"
+)
+
+const (
+	// DefaultCRISocket is the named pipe of containerd, docker is not supported on windows node.
+	DefaultCRISocket = "npipe:////./pipe/containerd-containerd"
+	// BinDir is the dir of kubelet, kubeadm and yurthub binaries on windows node.
+	BinDir = `C:\k`
+	// KubeletService is the name of kubelet windows service.
+	KubeletService = "kubelet"
+	// YurtHubService is the name of yurthub windows service.
+	YurtHubService = "yurthub"
+
+	binaryUrlFormat = "https://%s/release/%s/bin/windows/%s/%s.exe"
+)
+
+// Service describes a windows service which is registered in the service control manager.
+type Service struct {
+	// Name is the name of service.
+	Name string
+	// DisplayName is the name of service which is shown in the service control manager.
+	DisplayName string
+	// Description is the description of service.
+	Description string
+	// BinaryPath is the path of service executable.
+	BinaryPath string
+	// Args are the arguments of service executable.
+	Args []string
+}
+
+// IsWindows checks whether yurtadm is running on windows node.
+func IsWindows() bool {
+	return runtime.GOOS == "windows"
+}
+
+// HostPath converts the linux style path which is shared by linux and windows nodes, like /var/lib/kubelet,
+// to the absolute path on the system drive of windows node, like C:\var\lib\kubelet. kubeadm and kubelet
+// resolve the linux style paths on the system drive too.
+func HostPath(p string) string {
+	drive := os.Getenv("SystemDrive")
+	if len(drive) == 0 {
+		drive = "C:"
+	}
+	return drive + strings.ReplaceAll(path.Clean("/"+p), "/", `\`)
+}
+
+// Binary returns the path of binary in BinDir.
+func Binary(name string) string {
+	return BinDir + `\` + name + ".exe"
+}
+
+// CheckAndInstallBinaries downloads kubelet and kubeadm of the cluster version into BinDir if they don't exist.
+// yurthub is not released as windows binary, so it should be placed into BinDir in advance.
+func CheckAndInstallBinaries(kubernetesResourceServer, clusterVersion string) error {
+	if _, err := os.Stat(Binary("yurthub")); err != nil {
+		return fmt.Errorf("yurthub binary is not found, please place it at %s in advance, %v", Binary("yurthub"), err)
+	}
+	if strings.Contains(clusterVersion, "-") {
+		clusterVersion = strings.Split(clusterVersion, "-")[0]
+	}
+	if err := os.MkdirAll(BinDir, 0755); err != nil {
+		return err
+	}
+	for _, name := range []string{"kubelet", "kubeadm"} {
+		if _, err := os.Stat(Binary(name)); err == nil {
+			klog.Infof("%s already exists, skip install.", Binary(name))
+			continue
+		}
+		packageUrl := BinaryURL(kubernetesResourceServer, clusterVersion, name)
+		klog.V(1).Infof("Download %s from: %s", name, packageUrl)
+		if err := util.DownloadFile(packageUrl, Binary(name), 3); err != nil {
+			return fmt.Errorf("download %s fail: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// BinaryURL returns the url of windows binary in kubernetes resource server.
+func BinaryURL(kubernetesResourceServer, clusterVersion, name string) string {
+	return fmt.Sprintf(binaryUrlFormat, kubernetesResourceServer, clusterVersion, runtime.GOARCH, name)
+}
+
+// KubeletServiceFor returns the kubelet service of node. kubeadm writes kubelet flags into kubeadm-flags.env
+// which can't be loaded by windows service, so the flags are passed as service arguments, and kubelet
+// connects to kube-apiserver through yurthub with kubeletKubeConfig.
+func KubeletServiceFor(kubeletKubeConfig, kubeletDir, nodeName, criSocket, pauseImage, nodeLabels string) *Service {
+	args := []string{
+		"--windows-service",
+		fmt.Sprintf("--config=%s", HostPath(path.Join(kubeletDir, "config.yaml"))),
+		fmt.Sprintf("--kubeconfig=%s", HostPath(kubeletKubeConfig)),
+		fmt.Sprintf("--cert-dir=%s", HostPath(path.Join(kubeletDir, "pki"))),
+		fmt.Sprintf("--hostname-override=%s", nodeName),
+		fmt.Sprintf("--container-runtime-endpoint=%s", criSocket),
+		fmt.Sprintf("--pod-infra-container-image=%s", pauseImage),
+		"--rotate-certificates=false",
+		// cgroups and resolv.conf are not supported on windows
+		"--cgroups-per-qos=false",
+		"--enforce-node-allocatable=",
+		"--resolv-conf=",
+	}
+	if len(nodeLabels) != 0 {
+		args = append(args, fmt.Sprintf("--node-labels=%s", nodeLabels))
+	}
+	return &Service{
+		Name:        KubeletService,
+		DisplayName: "Kubernetes Kubelet",
+		Description: "kubelet: The Kubernetes Node Agent",
+		BinaryPath:  Binary("kubelet"),
+		Args:        args,
+	}
+}
+
+// YurtHubServiceFor returns the yurthub service which runs the same as yurthub static pod on linux node,
+// except that dummy interface and iptables are disabled because they are not supported on windows.
+func YurtHubServiceFor(bindAddr, serverAddrs, nodeName, workDir, bootstrapFile, workingMode, namespace, organizations, nodePoolName string) *Service {
+	args := []string{
+		"--v=2",
+		fmt.Sprintf("--bind-address=%s", bindAddr),
+		fmt.Sprintf("--server-addr=%s", serverAddrs),
+		fmt.Sprintf("--node-name=%s", nodeName),
+		fmt.Sprintf("--root-dir=%s", HostPath(workDir)),
+		fmt.Sprintf("--bootstrap-file=%s", HostPath(bootstrapFile)),
+		fmt.Sprintf("--working-mode=%s", workingMode),
+		fmt.Sprintf("--namespace=%s", namespace),
+		"--enable-dummy-if=false",
+		"--enable-iptables=false",
+	}
+	if len(organizations) != 0 {
+		args = append(args, fmt.Sprintf("--hub-cert-organizations=%s", organizations))
+	}
+	if len(nodePoolName) != 0 {
+		args = append(args, fmt.Sprintf("--nodepool-name=%s", nodePoolName))
+	}
+	return &Service{
+		Name:        YurtHubService,
+		DisplayName: "OpenYurt YurtHub",
+		Description: "yurthub: The node proxy of OpenYurt which connects node to kube-apiserver",
+		BinaryPath:  Binary("yurthub"),
+		Args:        args,
+	}
+}
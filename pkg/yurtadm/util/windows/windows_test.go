@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windows
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHostPath(t *testing.T) {
+	testcases := map[string]struct {
+		drive  string
+		path   string
+		expect string
+	}{
+		"default system drive": {
+			path:   "/var/lib/kubelet",
+			expect: `C:\var\lib\kubelet`,
+		},
+		"specified system drive": {
+			drive:  "D:",
+			path:   "/etc/kubernetes/kubelet.conf",
+			expect: `D:\etc\kubernetes\kubelet.conf`,
+		},
+		"path is cleaned": {
+			path:   "var/lib/yurthub/",
+			expect: `C:\var\lib\yurthub`,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			t.Setenv("SystemDrive", tc.drive)
+			if got := HostPath(tc.path); got != tc.expect {
+				t.Errorf("expect %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestKubeletServiceFor(t *testing.T) {
+	t.Setenv("SystemDrive", "")
+	service := KubeletServiceFor("/etc/kubernetes/kubelet.conf", "/var/lib/kubelet", "win-node", DefaultCRISocket, "pause:3.6", "openyurt.io/is-edge-worker=true")
+	if service.Name != KubeletService || service.BinaryPath != `C:\k\kubelet.exe` {
+		t.Errorf("unexpected kubelet service %s(%s)", service.Name, service.BinaryPath)
+	}
+	expect := []string{
+		"--windows-service",
+		`--config=C:\var\lib\kubelet\config.yaml`,
+		`--kubeconfig=C:\etc\kubernetes\kubelet.conf`,
+		`--cert-dir=C:\var\lib\kubelet\pki`,
+		"--hostname-override=win-node",
+		"--container-runtime-endpoint=npipe:////./pipe/containerd-containerd",
+		"--pod-infra-container-image=pause:3.6",
+		"--rotate-certificates=false",
+		"--cgroups-per-qos=false",
+		"--enforce-node-allocatable=",
+		"--resolv-conf=",
+		"--node-labels=openyurt.io/is-edge-worker=true",
+	}
+	if !reflect.DeepEqual(service.Args, expect) {
+		t.Errorf("expect args %v, but got %v", expect, service.Args)
+	}
+}
+
+func TestYurtHubServiceFor(t *testing.T) {
+	t.Setenv("SystemDrive", "")
+	testcases := map[string]struct {
+		organizations string
+		nodePoolName  string
+		expectExtra   []string
+	}{
+		"without nodepool": {},
+		"with organizations and nodepool": {
+			organizations: "openyurt:tenant:foo",
+			nodePoolName:  "hangzhou",
+			expectExtra:   []string{"--hub-cert-organizations=openyurt:tenant:foo", "--nodepool-name=hangzhou"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			service := YurtHubServiceFor("127.0.0.1", "https://1.2.3.4:6443", "win-node", "/var/lib/yurthub",
+				"/var/lib/yurthub/bootstrap-hub.conf", "edge", "kube-system", tc.organizations, tc.nodePoolName)
+			if service.Name != YurtHubService || service.BinaryPath != `C:\k\yurthub.exe` {
+				t.Errorf("unexpected yurthub service %s(%s)", service.Name, service.BinaryPath)
+			}
+			expect := append([]string{
+				"--v=2",
+				"--bind-address=127.0.0.1",
+				"--server-addr=https://1.2.3.4:6443",
+				"--node-name=win-node",
+				`--root-dir=C:\var\lib\yurthub`,
+				`--bootstrap-file=C:\var\lib\yurthub\bootstrap-hub.conf`,
+				"--working-mode=edge",
+				"--namespace=kube-system",
+				"--enable-dummy-if=false",
+				"--enable-iptables=false",
+			}, tc.expectExtra...)
+			if !reflect.DeepEqual(service.Args, expect) {
+				t.Errorf("expect args %v, but got %v", expect, service.Args)
+			}
+		})
+	}
+}
@@ -19,16 +19,21 @@ package yurthub
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
@@ -37,6 +42,8 @@ import (
 	"github.com/openyurtio/openyurt/pkg/util/token"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/constants"
+	"github.com/openyurtio/openyurt/pkg/yurtadm/util/windows"
+	hubutil "github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
 
 // AddYurthubStaticYaml generate YurtHub static yaml for worker node.
@@ -94,6 +101,26 @@ func AddYurthubStaticYaml(data joindata.YurtJoinData, podManifestPath string) er
 	return nil
 }
 
+// AddYurthubWindowsService registers and starts yurthub as a windows service for windows worker node,
+// which is the same as yurthub static pod on linux node.
+func AddYurthubWindowsService(data joindata.YurtJoinData) error {
+	klog.Info("[join-node] Adding edge hub windows service")
+	serverAddrs := strings.Split(data.ServerAddr(), ",")
+	for i := 0; i < len(serverAddrs); i++ {
+		serverAddrs[i] = fmt.Sprintf("https://%s", serverAddrs[i])
+	}
+
+	nodeReg := data.NodeRegistration()
+	service := windows.YurtHubServiceFor(data.YurtHubServer(), strings.Join(serverAddrs, ","), nodeReg.Name,
+		constants.YurtHubWorkdir, constants.YurtHubBootstrapConfig, nodeReg.WorkingMode, data.Namespace(),
+		nodeReg.Organizations, nodeReg.NodePoolName)
+	if err := windows.InstallService(service, true); err != nil {
+		return err
+	}
+	klog.Info("[join-node] Add hub agent windows service is ok")
+	return nil
+}
+
 func SetHubBootstrapConfig(serverAddr string, joinToken string, caCertHashes []string) error {
 	if cfg, err := token.RetrieveValidatedConfigInfo(nil, &token.BootstrapData{
 		ServerAddr:   serverAddr,
@@ -175,6 +202,30 @@ func CheckYurthubReadyzOnce(yurthubServer string) bool {
 	return string(ok) == "OK"
 }
 
+// GetJoinInfo gets the join info cached by yurthub on the hub leader node of nodepool,
+// the default port of join info server is used if hubLeaderAddr has no port.
+func GetJoinInfo(hubLeaderAddr string) (*token.JoinInfo, error) {
+	if _, _, err := net.SplitHostPort(hubLeaderAddr); err != nil {
+		hubLeaderAddr = net.JoinHostPort(hubLeaderAddr, strconv.Itoa(hubutil.YurtHubJoinInfoPort))
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s%s", hubLeaderAddr, constants.ServerJoinInfoURLPath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not get join info from %s, status code %d", hubLeaderAddr, resp.StatusCode)
+	}
+
+	var info token.JoinInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, errors.Wrapf(err, "could not decode join info from %s", hubLeaderAddr)
+	}
+	return &info, nil
+}
+
 func CleanHubBootstrapConfig() error {
 	if err := os.RemoveAll(constants.YurtHubBootstrapConfig); err != nil {
 		klog.Warningf("Clean file %s fail: %v, please clean it manually.", constants.YurtHubBootstrapConfig, err)
@@ -182,6 +233,52 @@ func CleanHubBootstrapConfig() error {
 	return nil
 }
 
+// ProxyKubeConfig returns a kubeconfig which accesses kube-apiserver through the proxy port of local yurthub,
+// so the requests benefit from the failover of yurthub and kube-apiserver doesn't need to be reachable from
+// the node directly. Only bearer token is forwarded by yurthub, so the token is used if it's specified,
+// otherwise the token of current user in kubeconfig is used.
+// The proxy port of yurthub is served over plain http, so yurthubServer must be a loopback address
+// to prevent the bearer token from being sent over the network.
+func ProxyKubeConfig(kubeconfig, bearerToken, yurthubServer string) (*clientcmdapi.Config, error) {
+	if !isLoopback(yurthubServer) {
+		return nil, errors.Errorf("yurthub server %s is not a loopback address, bearer token can't be sent over plain http", yurthubServer)
+	}
+
+	if len(bearerToken) == 0 {
+		cfg, err := clientcmd.LoadFromFile(kubeconfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load kubeconfig %s", kubeconfig)
+		}
+		authInfo := kubeconfigutil.GetAuthInfoFromKubeConfig(cfg)
+		if authInfo == nil {
+			return nil, errors.Errorf("no user is found for current context in kubeconfig %s", kubeconfig)
+		}
+		bearerToken = authInfo.Token
+		if len(bearerToken) == 0 && len(authInfo.TokenFile) != 0 {
+			data, err := os.ReadFile(authInfo.TokenFile)
+			if err != nil {
+				return nil, err
+			}
+			bearerToken = strings.TrimSpace(string(data))
+		}
+		if len(bearerToken) == 0 {
+			return nil, errors.Errorf("user in kubeconfig %s has no bearer token, client certificate can't be forwarded by yurthub", kubeconfig)
+		}
+	}
+
+	server := fmt.Sprintf("http://%s", net.JoinHostPort(yurthubServer, strconv.Itoa(constants.YurtHubProxyPort)))
+	return kubeconfigutil.CreateWithToken(server, "yurthub", "yurtadm", nil, bearerToken), nil
+}
+
+// isLoopback checks if the host is localhost or a loopback ip address.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // useRealServerAddr check if the server-addr from yurthubTemplate is default value: 127.0.0.1:6443
 // if yes, we should use the real server addr
 func useRealServerAddr(yurthubTemplate string, kubernetesServerAddrs string) (string, error) {
@@ -17,6 +17,8 @@ limitations under the License.
 package yurthub
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,7 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
+	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
 	"github.com/openyurtio/openyurt/pkg/yurtadm/cmd/join/joindata"
 )
 
@@ -315,6 +318,10 @@ func (j *testData) StaticPodManifestList() []string {
 	return nil
 }
 
+func (j *testData) OfflineBundle() string {
+	return ""
+}
+
 func TestAddYurthubStaticYaml(t *testing.T) {
 	xdata := testData{
 		joinNodeData: &joindata.NodeRegistration{
@@ -375,3 +382,109 @@ func TestCheckYurtHubItself(t *testing.T) {
 		})
 	}
 }
+
+func TestProxyKubeConfig(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("could not write token file, %v", err)
+	}
+	writeKubeConfig := func(name string, authInfo *clientcmdapi.AuthInfo) string {
+		cfg := kubeconfigutil.CreateBasic("https://1.2.3.4:6443", "kubernetes", "admin", nil)
+		cfg.AuthInfos["admin"] = authInfo
+		path := filepath.Join(dir, name)
+		if err := kubeconfigutil.WriteToDisk(path, cfg); err != nil {
+			t.Fatalf("could not write kubeconfig, %v", err)
+		}
+		return path
+	}
+
+	tests := map[string]struct {
+		kubeconfig  string
+		bearerToken string
+		server      string
+		wantServer  string
+		wantToken   string
+		wantErr     bool
+	}{
+		"bearer token is specified": {
+			bearerToken: "abcdef.0123456789abcdef",
+			wantToken:   "abcdef.0123456789abcdef",
+		},
+		"yurthub server is localhost": {
+			bearerToken: "abcdef.0123456789abcdef",
+			server:      "localhost",
+			wantServer:  "http://localhost:10261",
+			wantToken:   "abcdef.0123456789abcdef",
+		},
+		"yurthub server is ipv6 loopback": {
+			bearerToken: "abcdef.0123456789abcdef",
+			server:      "::1",
+			wantServer:  "http://[::1]:10261",
+			wantToken:   "abcdef.0123456789abcdef",
+		},
+		"yurthub server is not loopback": {
+			bearerToken: "abcdef.0123456789abcdef",
+			server:      "192.168.0.1",
+			wantErr:     true,
+		},
+		"yurthub server is a hostname": {
+			bearerToken: "abcdef.0123456789abcdef",
+			server:      "edge-node",
+			wantErr:     true,
+		},
+		"token in kubeconfig": {
+			kubeconfig: writeKubeConfig("token.conf", &clientcmdapi.AuthInfo{Token: "kubeconfig-token"}),
+			wantToken:  "kubeconfig-token",
+		},
+		"token file in kubeconfig": {
+			kubeconfig: writeKubeConfig("token-file.conf", &clientcmdapi.AuthInfo{TokenFile: tokenFile}),
+			wantToken:  "file-token",
+		},
+		"client certificate in kubeconfig": {
+			kubeconfig: writeKubeConfig("cert.conf", &clientcmdapi.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}),
+			wantErr:    true,
+		},
+		"kubeconfig is not found": {
+			kubeconfig: filepath.Join(dir, "not-found.conf"),
+			wantErr:    true,
+		},
+	}
+	for k, tt := range tests {
+		t.Run(k, func(t *testing.T) {
+			server, wantServer := tt.server, tt.wantServer
+			if len(server) == 0 {
+				server, wantServer = "127.0.0.1", "http://127.0.0.1:10261"
+			}
+			cfg, err := ProxyKubeConfig(tt.kubeconfig, tt.bearerToken, server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect error %v, but got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := kubeconfigutil.GetClusterFromKubeConfig(cfg).Server; got != wantServer {
+				t.Errorf("expect server %s, but got %s", wantServer, got)
+			}
+			if token := kubeconfigutil.GetAuthInfoFromKubeConfig(cfg).Token; token != tt.wantToken {
+				t.Errorf("expect token %s, but got %s", tt.wantToken, token)
+			}
+		})
+	}
+}
+
+func (j *testData) RegistrationDeferred() bool {
+	return false
+}
+
+func (j *testData) Distro() string {
+	return "kubeadm"
+}
+
+func (j *testData) GPUPreflight() bool {
+	return false
+}
+
+func (j *testData) MinNvidiaDriverVersion() string {
+	return ""
+}
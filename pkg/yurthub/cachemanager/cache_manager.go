@@ -28,6 +28,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -37,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/endpoints/handlers"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
@@ -60,6 +63,7 @@ type CacheManager interface {
 	QueryCache(req *http.Request) (runtime.Object, error)
 	CanCacheFor(req *http.Request) bool
 	DeleteKindFor(gvr schema.GroupVersionResource) error
+	WaitForInFlightWrites(ctx context.Context) error
 }
 
 type cacheManager struct {
@@ -70,6 +74,7 @@ type cacheManager struct {
 	cacheAgents           *CacheAgent
 	listSelectorCollector map[storage.Key]string
 	inMemoryCache         map[string]runtime.Object
+	inFlightWrites        int32
 }
 
 // NewCacheManager creates a new CacheManager
@@ -94,6 +99,9 @@ func NewCacheManager(
 
 // CacheResponse cache response of request into backend storage
 func (cm *cacheManager) CacheResponse(req *http.Request, prc io.ReadCloser, stopCh <-chan struct{}) error {
+	atomic.AddInt32(&cm.inFlightWrites, 1)
+	defer atomic.AddInt32(&cm.inFlightWrites, -1)
+
 	ctx := req.Context()
 	info, _ := apirequest.RequestInfoFrom(ctx)
 	if isWatch(ctx) {
@@ -120,6 +128,19 @@ func (cm *cacheManager) CacheResponse(req *http.Request, prc io.ReadCloser, stop
 	return cm.saveOneObject(ctx, info, buf.Bytes())
 }
 
+// WaitForInFlightWrites blocks until all of response caching are persisted into backend storage,
+// it's used for preventing cache data from being lost when yurthub is shutting down.
+func (cm *cacheManager) WaitForInFlightWrites(ctx context.Context) error {
+	return wait.PollImmediateUntil(100*time.Millisecond, func() (bool, error) {
+		n := atomic.LoadInt32(&cm.inFlightWrites)
+		if n != 0 {
+			klog.V(4).Infof("waiting for %d in-flight cache writes", n)
+			return false, nil
+		}
+		return true, nil
+	}, ctx.Done())
+}
+
 // QueryCache get runtime object from backend storage for request
 func (cm *cacheManager) QueryCache(req *http.Request) (runtime.Object, error) {
 	ctx := req.Context()
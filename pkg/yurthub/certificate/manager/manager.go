@@ -79,6 +79,8 @@ func NewYurtHubCertManager(options *options.YurtHubOptions, remoteServers []*url
 			BootstrapFile:            options.BootstrapFile,
 			CaCertHashes:             options.CACertHashes,
 			YurtHubCertOrganizations: options.YurtHubCertOrganizations,
+			NodePoolName:             options.NodePoolName,
+			SPIFFETrustDomain:        options.SPIFFETrustDomain,
 			RemoteServers:            remoteServers,
 			Client:                   options.ClientForTest,
 		}
@@ -93,6 +95,8 @@ func NewYurtHubCertManager(options *options.YurtHubOptions, remoteServers []*url
 		net.ParseIP(options.HubAgentDummyIfIP),
 		net.ParseIP(options.YurtHubHost),
 		net.ParseIP(options.YurtHubProxyHost),
+		net.ParseIP(options.YurtHubSecondaryHost),
+		net.ParseIP(options.YurtHubSecondaryProxyHost),
 	})
 	serverCertManager, err := hubServerCert.NewHubServerCertificateManager(options.ClientForTest, clientCertManager, options.NodeName, filepath.Join(workDir, "pki"), certIPs)
 	if err != nil {
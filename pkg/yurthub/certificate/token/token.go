@@ -42,6 +42,7 @@ import (
 	certfactory "github.com/openyurtio/openyurt/pkg/util/certmanager/factory"
 	"github.com/openyurtio/openyurt/pkg/util/certmanager/store"
 	kubeconfigutil "github.com/openyurtio/openyurt/pkg/util/kubeconfig"
+	"github.com/openyurtio/openyurt/pkg/util/spiffe"
 	"github.com/openyurtio/openyurt/pkg/util/token"
 	hubCert "github.com/openyurtio/openyurt/pkg/yurthub/certificate"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
@@ -65,6 +66,8 @@ type ClientCertificateManagerConfiguration struct {
 	BootstrapFile            string
 	CaCertHashes             []string
 	YurtHubCertOrganizations []string
+	NodePoolName             string
+	SPIFFETrustDomain        string
 	RemoteServers            []*url.URL
 	Client                   clientset.Interface
 }
@@ -104,7 +107,7 @@ func NewYurtHubClientCertManager(cfg *ClientCertificateManagerConfiguration) (hu
 	if err != nil {
 		return ycm, errors.Wrap(err, "couldn't new client cert store")
 	}
-	ycm.apiServerClientCertManager, err = ycm.newAPIServerClientCertificateManager(ycm.apiServerClientCertStore, cfg.NodeName, cfg.YurtHubCertOrganizations)
+	ycm.apiServerClientCertManager, err = ycm.newAPIServerClientCertificateManager(ycm.apiServerClientCertStore, cfg)
 	if err != nil {
 		return ycm, errors.Wrap(err, "couldn't new apiserver client certificate manager")
 	}
@@ -300,18 +303,33 @@ func (ycm *yurtHubClientCertManager) GetAPIServerClientCert() *tls.Certificate {
 
 // newAPIServerClientCertificateManager create a certificate manager for yurthub component to prepare client certificate
 // that used to proxy requests to remote kube-apiserver.
-func (ycm *yurtHubClientCertManager) newAPIServerClientCertificateManager(fileStore certificate.FileStore, nodeName string, hubCertOrganizations []string) (certificate.Manager, error) {
+func (ycm *yurtHubClientCertManager) newAPIServerClientCertificateManager(fileStore certificate.FileStore, cfg *ClientCertificateManagerConfiguration) (certificate.Manager, error) {
 	orgs := []string{YurtHubCSROrg, user.NodesGroup}
-	for _, v := range hubCertOrganizations {
+	for _, v := range cfg.YurtHubCertOrganizations {
 		if v != YurtHubCSROrg && v != user.NodesGroup {
 			orgs = append(orgs, v)
 		}
 	}
 
+	// SPIFFE identities of yurthub are carried as URI SANs, so edge components and cloud services
+	// can authorize yurthub by its node and nodepool scoped identities in mTLS.
+	var urisGetter certfactory.URIsGetter
+	if len(cfg.SPIFFETrustDomain) != 0 {
+		urisGetter = func() ([]*url.URL, error) {
+			// nodepool scoped identity is only approved for the registered node, so it's not requested
+			// until the node has been bootstrapped and the certificate is rotated by node credential.
+			if ycm.apiServerClientCertManager == nil || ycm.apiServerClientCertManager.Current() == nil {
+				return spiffe.IDs(cfg.SPIFFETrustDomain, cfg.NodeName, "", spiffe.ComponentYurtHub), nil
+			}
+			return spiffe.IDs(cfg.SPIFFETrustDomain, cfg.NodeName, cfg.NodePoolName, spiffe.ComponentYurtHub), nil
+		}
+	}
+
 	return certfactory.NewCertManagerFactoryWithFnAndStore(ycm.generateCertClientFn, fileStore).New(&certfactory.CertManagerConfig{
 		ComponentName: ycm.hubName,
-		CommonName:    fmt.Sprintf("system:node:%s", nodeName),
+		CommonName:    fmt.Sprintf("system:node:%s", cfg.NodeName),
 		Organizations: orgs,
+		URIsGetter:    urisGetter,
 		SignerName:    certificatesv1.KubeAPIServerClientSignerName,
 	})
 }
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/transport"
+)
+
+// FaultType is the type of fault that is injected into the traffic between yurthub and cloud.
+type FaultType string
+
+const (
+	// Unreachable makes all requests to the cloud fail as if the network is down.
+	Unreachable FaultType = "unreachable"
+	// Latency delays every request to the cloud by the specified latency.
+	Latency FaultType = "latency"
+	// PacketLoss makes requests to the cloud fail randomly by the specified loss rate.
+	PacketLoss FaultType = "loss"
+
+	// MaxFaultDuration is the upper limit of the fault duration, so an injected fault
+	// can not be left on the node unintentionally.
+	MaxFaultDuration = 24 * time.Hour
+)
+
+// Fault describes a fault that is injected into the traffic between yurthub and cloud.
+type Fault struct {
+	Type     FaultType     `json:"type"`
+	Latency  time.Duration `json:"latency,omitempty"`
+	LossRate float64       `json:"lossRate,omitempty"`
+	// ExpireTime is the time when the fault will be cleared automatically.
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// Validate checks the fault is supported or not.
+func (f *Fault) Validate() error {
+	switch f.Type {
+	case Unreachable:
+	case Latency:
+		if f.Latency <= 0 {
+			return fmt.Errorf("latency should be positive for %s fault", f.Type)
+		}
+	case PacketLoss:
+		if f.LossRate <= 0 || f.LossRate > 1 {
+			return fmt.Errorf("loss rate should be in (0, 1] for %s fault", f.Type)
+		}
+	default:
+		return fmt.Errorf("fault type %q is not supported", f.Type)
+	}
+	return nil
+}
+
+// Injector holds the fault that is injected currently, and wraps transports
+// for accessing cloud so that requests go through the injected fault.
+type Injector struct {
+	sync.RWMutex
+	fault   *Fault
+	remotes []*url.URL
+	tm      transport.Interface
+}
+
+// NewInjector creates an *Injector for the specified remote servers.
+func NewInjector(remoteServers []*url.URL) *Injector {
+	return &Injector{
+		remotes: remoteServers,
+	}
+}
+
+// Inject sets up a fault which will be cleared automatically after duration.
+func (i *Injector) Inject(fault Fault, duration time.Duration) error {
+	if duration <= 0 || duration > MaxFaultDuration {
+		return fmt.Errorf("fault duration should be in (0, %v]", MaxFaultDuration)
+	}
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+	fault.ExpireTime = time.Now().Add(duration)
+
+	i.Lock()
+	i.fault = &fault
+	tm := i.tm
+	i.Unlock()
+	klog.Warningf("fault %s is injected for accessing cloud, and will be cleared at %s", fault.Type, fault.ExpireTime.Format(time.RFC3339))
+
+	// close the established connections(like long-running watch requests),
+	// so they can go through the injected fault when reconnecting.
+	if tm != nil && fault.Type != Latency {
+		for _, remote := range i.remotes {
+			tm.Close(remote.Host)
+		}
+	}
+	return nil
+}
+
+// Clear removes the injected fault.
+func (i *Injector) Clear() {
+	i.Lock()
+	defer i.Unlock()
+	if i.fault != nil {
+		klog.Infof("fault %s is cleared", i.fault.Type)
+	}
+	i.fault = nil
+}
+
+// Current returns the fault that is injected currently, nil will be returned
+// if no fault is injected or the fault has expired.
+func (i *Injector) Current() *Fault {
+	i.RLock()
+	defer i.RUnlock()
+	if i.fault == nil || time.Now().After(i.fault.ExpireTime) {
+		return nil
+	}
+	f := *i.fault
+	return &f
+}
+
+// WrapTransportManager wraps transports of transport manager with the injector.
+func (i *Injector) WrapTransportManager(tm transport.Interface) transport.Interface {
+	i.Lock()
+	i.tm = tm
+	i.Unlock()
+	return &faultTransportManager{
+		Interface: tm,
+		injector:  i,
+	}
+}
+
+// WrapRoundTripper wraps the round tripper with the injector.
+func (i *Injector) WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return &faultRoundTripper{
+		rt:       rt,
+		injector: i,
+	}
+}
+
+type faultTransportManager struct {
+	transport.Interface
+	injector *Injector
+}
+
+func (ftm *faultTransportManager) CurrentTransport() http.RoundTripper {
+	return ftm.injector.WrapRoundTripper(ftm.Interface.CurrentTransport())
+}
+
+func (ftm *faultTransportManager) BearerTransport() http.RoundTripper {
+	return ftm.injector.WrapRoundTripper(ftm.Interface.BearerTransport())
+}
+
+type faultRoundTripper struct {
+	rt       http.RoundTripper
+	injector *Injector
+}
+
+func (frt *faultRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := frt.injector.Current()
+	if fault == nil {
+		return frt.rt.RoundTrip(req)
+	}
+
+	switch fault.Type {
+	case Unreachable:
+		return nil, fmt.Errorf("dial tcp %s: connect: network is unreachable(injected)", req.URL.Host)
+	case PacketLoss:
+		if rand.Float64() < fault.LossRate {
+			return nil, fmt.Errorf("dial tcp %s: i/o timeout(injected)", req.URL.Host)
+		}
+	case Latency:
+		t := time.NewTimer(fault.Latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	return frt.rt.RoundTrip(req)
+}
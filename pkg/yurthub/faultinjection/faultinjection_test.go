@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestInject(t *testing.T) {
+	testcases := map[string]struct {
+		fault    Fault
+		duration time.Duration
+		isErr    bool
+	}{
+		"unreachable fault": {
+			fault:    Fault{Type: Unreachable},
+			duration: time.Minute,
+		},
+		"latency fault without latency": {
+			fault:    Fault{Type: Latency},
+			duration: time.Minute,
+			isErr:    true,
+		},
+		"loss fault with invalid loss rate": {
+			fault:    Fault{Type: PacketLoss, LossRate: 1.5},
+			duration: time.Minute,
+			isErr:    true,
+		},
+		"unknown fault": {
+			fault:    Fault{Type: "unknown"},
+			duration: time.Minute,
+			isErr:    true,
+		},
+		"duration is too long": {
+			fault:    Fault{Type: Unreachable},
+			duration: 2 * MaxFaultDuration,
+			isErr:    true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			injector := NewInjector(nil)
+			err := injector.Inject(tc.fault, tc.duration)
+			if tc.isErr != (err != nil) {
+				t.Errorf("expect error %v, but got %v", tc.isErr, err)
+			}
+
+			if !tc.isErr && injector.Current() == nil {
+				t.Errorf("expect fault is injected, but got nil")
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	injector := NewInjector(nil)
+	client := &http.Client{Transport: injector.WrapRoundTripper(http.DefaultTransport)}
+
+	if _, err := client.Get(server.URL); err != nil {
+		t.Errorf("expect request succeed without fault, but got %v", err)
+	}
+
+	if err := injector.Inject(Fault{Type: Unreachable}, time.Minute); err != nil {
+		t.Fatalf("could not inject fault, %v", err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expect request failed with unreachable fault, but got nil")
+	}
+
+	if err := injector.Inject(Fault{Type: PacketLoss, LossRate: 1}, time.Minute); err != nil {
+		t.Fatalf("could not inject fault, %v", err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expect request failed with loss fault, but got nil")
+	}
+
+	injector.Clear()
+	if _, err := client.Get(server.URL); err != nil {
+		t.Errorf("expect request succeed after fault cleared, but got %v", err)
+	}
+}
@@ -42,6 +42,16 @@ const (
 	// propagating the hostNetwork configuration to the pods running in it.
 	HostNetworkPropagationFilterName = "hostnetworkpropagation"
 
+	// ExternalTrafficPolicyFilterName filter is used to report the endpoints in the same NodePool as local endpoints
+	// for the service annotated with openyurt.io/external-traffic-policy=nodepool, in order to make the NodePort and
+	// LoadBalancer traffic arriving at a node only be forwarded to the endpoints in the same NodePool by kube-proxy.
+	ExternalTrafficPolicyFilterName = "externaltrafficpolicy"
+
+	// TopologyHintsFilterName filter is used to generate topology hints of endpointslice for the service annotated with
+	// openyurt.io/topology-hints=nodepool, in order to make kube-proxy prefer the endpoints in the same NodePool and
+	// spill the traffic over to the neighbor NodePools when the endpoints in the NodePool are not enough.
+	TopologyHintsFilterName = "topologyhints"
+
 	// SkipDiscardServiceAnnotation is annotation used by LB service.
 	// If end users want to use specified LB service at the edge side,
 	// End users should add annotation["openyurt.io/skip-discard"]="true" for LB service.
@@ -60,5 +70,7 @@ var (
 		InClusterConfigFilterName:        "kubelet",
 		NodePortIsolationFilterName:      "kube-proxy",
 		HostNetworkPropagationFilterName: "kubelet",
+		ExternalTrafficPolicyFilterName:  "kube-proxy",
+		TopologyHintsFilterName:          "kube-proxy",
 	}
 )
@@ -0,0 +1,270 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaltrafficpolicy
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	discoveryV1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
+)
+
+// AnnotationExternalTrafficPolicy specifies the scope of endpoints which the external traffic(NodePort and
+// LoadBalancer traffic) arriving at a node is forwarded to. when the value is nodepool, the external traffic
+// is only forwarded to the endpoints in the same NodePool as the node, so the traffic of external clients
+// never goes across the WAN.
+const (
+	AnnotationExternalTrafficPolicy              = "openyurt.io/external-traffic-policy"
+	AnnotationExternalTrafficPolicyValueNodePool = "nodepool"
+)
+
+// IsPoolLocalExternalTraffic checks whether the external traffic of service should be kept in the NodePool.
+func IsPoolLocalExternalTraffic(svc *v1.Service) bool {
+	if svc.Spec.Type != v1.ServiceTypeNodePort && svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return false
+	}
+	return svc.Annotations[AnnotationExternalTrafficPolicy] == AnnotationExternalTrafficPolicyValueNodePool
+}
+
+// Register registers a filter
+func Register(filters *filter.Filters) {
+	filters.Register(filter.ExternalTrafficPolicyFilterName, func() (filter.ObjectFilter, error) {
+		return NewExternalTrafficPolicyFilter()
+	})
+}
+
+func NewExternalTrafficPolicyFilter() (filter.ObjectFilter, error) {
+	return &externalTrafficPolicyFilter{}, nil
+}
+
+// externalTrafficPolicyFilter keeps the external traffic of service in the NodePool. the service is set
+// with externalTrafficPolicy=Local by yurt-manager, so kube-proxy only forwards the external traffic to the
+// local endpoints, and the endpoints in the same NodePool are reported as the endpoints on the current node
+// by this filter, then kube-proxy takes them as local endpoints. the endpoints in other NodePools are still
+// kept, so the traffic from the cluster ip is not affected.
+type externalTrafficPolicyFilter struct {
+	serviceLister  listers.ServiceLister
+	serviceSynced  cache.InformerSynced
+	nodePoolLister cache.GenericLister
+	nodePoolSynced cache.InformerSynced
+	nodePoolName   string
+	nodeName       string
+	client         kubernetes.Interface
+}
+
+func (etf *externalTrafficPolicyFilter) Name() string {
+	return filter.ExternalTrafficPolicyFilterName
+}
+
+func (etf *externalTrafficPolicyFilter) SupportedResourceAndVerbs() map[string]sets.String {
+	return map[string]sets.String{
+		"endpoints":      sets.NewString("list", "watch"),
+		"endpointslices": sets.NewString("list", "watch"),
+	}
+}
+
+func (etf *externalTrafficPolicyFilter) SetSharedInformerFactory(factory informers.SharedInformerFactory) error {
+	etf.serviceLister = factory.Core().V1().Services().Lister()
+	etf.serviceSynced = factory.Core().V1().Services().Informer().HasSynced
+
+	return nil
+}
+
+func (etf *externalTrafficPolicyFilter) SetNodePoolInformerFactory(dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory) error {
+	gvr := v1beta1.GroupVersion.WithResource("nodepools")
+	etf.nodePoolLister = dynamicInformerFactory.ForResource(gvr).Lister()
+	etf.nodePoolSynced = dynamicInformerFactory.ForResource(gvr).Informer().HasSynced
+
+	return nil
+}
+
+func (etf *externalTrafficPolicyFilter) SetNodeName(nodeName string) error {
+	etf.nodeName = nodeName
+
+	return nil
+}
+
+func (etf *externalTrafficPolicyFilter) SetNodePoolName(poolName string) error {
+	etf.nodePoolName = poolName
+	return nil
+}
+
+func (etf *externalTrafficPolicyFilter) SetKubeClient(client kubernetes.Interface) error {
+	etf.client = client
+	return nil
+}
+
+func (etf *externalTrafficPolicyFilter) Filter(obj runtime.Object, stopCh <-chan struct{}) runtime.Object {
+	if ok := cache.WaitForCacheSync(stopCh, etf.serviceSynced, etf.nodePoolSynced); !ok {
+		return obj
+	}
+
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSliceList:
+		for i := range v.Items {
+			etf.externalTrafficHandler(&v.Items[i])
+		}
+		return v
+	case *discovery.EndpointSliceList:
+		for i := range v.Items {
+			etf.externalTrafficHandler(&v.Items[i])
+		}
+		return v
+	case *v1.EndpointsList:
+		for i := range v.Items {
+			etf.externalTrafficHandler(&v.Items[i])
+		}
+		return v
+	case *v1.Endpoints, *discoveryV1beta1.EndpointSlice, *discovery.EndpointSlice:
+		return etf.externalTrafficHandler(v)
+	default:
+		return obj
+	}
+}
+
+func (etf *externalTrafficPolicyFilter) externalTrafficHandler(obj runtime.Object) runtime.Object {
+	svc := etf.resolveService(obj)
+	if svc == nil || !IsPoolLocalExternalTraffic(svc) || svc.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyTypeLocal {
+		return obj
+	}
+
+	nodes, ok := etf.resolveNodePoolNodes()
+	if !ok {
+		return obj
+	}
+
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		for i := range v.Endpoints {
+			ep := &v.Endpoints[i]
+			if nodes.Has(ep.Topology[v1.LabelHostname]) {
+				ep.Topology[v1.LabelHostname] = etf.nodeName
+				if ep.NodeName != nil {
+					ep.NodeName = &etf.nodeName
+				}
+			}
+		}
+	case *discovery.EndpointSlice:
+		for i := range v.Endpoints {
+			ep := &v.Endpoints[i]
+			if ep.NodeName != nil && nodes.Has(*ep.NodeName) {
+				ep.NodeName = &etf.nodeName
+				if _, ok := ep.DeprecatedTopology[v1.LabelHostname]; ok {
+					ep.DeprecatedTopology[v1.LabelHostname] = etf.nodeName
+				}
+			}
+		}
+	case *v1.Endpoints:
+		for i := range v.Subsets {
+			localizeAddresses(v.Subsets[i].Addresses, etf.nodeName, nodes)
+			localizeAddresses(v.Subsets[i].NotReadyAddresses, etf.nodeName, nodes)
+		}
+	}
+	return obj
+}
+
+// localizeAddresses reports the addresses in the NodePool as the addresses on the current node.
+func localizeAddresses(addresses []v1.EndpointAddress, nodeName string, nodes sets.String) {
+	for i := range addresses {
+		if addresses[i].NodeName != nil && nodes.Has(*addresses[i].NodeName) {
+			addresses[i].NodeName = &nodeName
+		}
+	}
+}
+
+func (etf *externalTrafficPolicyFilter) resolveService(obj runtime.Object) *v1.Service {
+	var svcNamespace, svcName string
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		svcNamespace = v.Namespace
+		svcName = v.Labels[discoveryV1beta1.LabelServiceName]
+	case *discovery.EndpointSlice:
+		svcNamespace = v.Namespace
+		svcName = v.Labels[discovery.LabelServiceName]
+	case *v1.Endpoints:
+		svcNamespace = v.Namespace
+		svcName = v.Name
+	default:
+		return nil
+	}
+
+	svc, err := etf.serviceLister.Services(svcNamespace).Get(svcName)
+	if err != nil {
+		klog.Warningf("externalTrafficPolicyFilter: failed to get service %s/%s, err: %v", svcNamespace, svcName, err)
+		return nil
+	}
+	return svc
+}
+
+// resolveNodePoolNodes returns the nodes in the same NodePool as the current node.
+func (etf *externalTrafficPolicyFilter) resolveNodePoolNodes() (sets.String, bool) {
+	nodePoolName := etf.resolveNodePoolName()
+	if len(nodePoolName) == 0 {
+		klog.Infof("node(%s) is not added into node pool, so skip externalTrafficPolicyFilter", etf.nodeName)
+		return nil, false
+	}
+
+	runtimeObj, err := etf.nodePoolLister.Get(nodePoolName)
+	if err != nil {
+		klog.Warningf("externalTrafficPolicyFilter: failed to get nodepool %s, err: %v", nodePoolName, err)
+		return nil, false
+	}
+	var nodePool *v1beta1.NodePool
+	switch poolObj := runtimeObj.(type) {
+	case *v1beta1.NodePool:
+		nodePool = poolObj
+	case *unstructured.Unstructured:
+		nodePool = new(v1beta1.NodePool)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(poolObj.UnstructuredContent(), nodePool); err != nil {
+			klog.Warningf("object(%#+v) is not a v1beta1.NodePool", poolObj)
+			return nil, false
+		}
+	default:
+		klog.Warningf("object(%#+v) is not a unknown type", poolObj)
+		return nil, false
+	}
+	return sets.NewString(nodePool.Status.Nodes...), true
+}
+
+func (etf *externalTrafficPolicyFilter) resolveNodePoolName() string {
+	if len(etf.nodePoolName) != 0 {
+		return etf.nodePoolName
+	}
+
+	node, err := etf.client.CoreV1().Nodes().Get(context.Background(), etf.nodeName, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("failed to get node(%s) in externalTrafficPolicyFilter filter, %v", etf.nodeName, err)
+		return etf.nodePoolName
+	}
+	etf.nodePoolName = node.Labels[apps.NodePoolLabel]
+	return etf.nodePoolName
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaltrafficpolicy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	discoveryV1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
+)
+
+func newService(svcType corev1.ServiceType, policy corev1.ServiceExternalTrafficPolicyType, annotated bool) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc1",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                  svcType,
+			ExternalTrafficPolicy: policy,
+		},
+	}
+	if annotated {
+		svc.Annotations = map[string]string{
+			AnnotationExternalTrafficPolicy: AnnotationExternalTrafficPolicyValueNodePool,
+		}
+	}
+	return svc
+}
+
+func TestName(t *testing.T) {
+	etf, _ := NewExternalTrafficPolicyFilter()
+	if etf.Name() != filter.ExternalTrafficPolicyFilterName {
+		t.Errorf("expect %s, but got %s", filter.ExternalTrafficPolicyFilterName, etf.Name())
+	}
+}
+
+func TestFilter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	apis.AddToScheme(scheme)
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "apps.openyurt.io", Version: "v1beta1", Resource: "nodepools"}: "NodePoolList",
+	}
+	currentNodeName := "node1"
+	nodeName2 := "node2"
+	nodeName3 := "node3"
+	newNodePool := func() *v1beta1.NodePool {
+		return &v1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "hangzhou",
+			},
+			Status: v1beta1.NodePoolStatus{
+				Nodes: []string{currentNodeName, nodeName2},
+			},
+		}
+	}
+	newEndpointSlice := func(nodes ...string) *discovery.EndpointSlice {
+		eps := &discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "svc1-np7sf",
+				Namespace: "default",
+				Labels: map[string]string{
+					discovery.LabelServiceName: "svc1",
+				},
+			},
+		}
+		for i := range nodes {
+			eps.Endpoints = append(eps.Endpoints, discovery.Endpoint{
+				Addresses: []string{"10.244.1.2"},
+				NodeName:  &nodes[i],
+			})
+		}
+		return eps
+	}
+
+	testcases := map[string]struct {
+		responseObject runtime.Object
+		service        *corev1.Service
+		expectObject   runtime.Object
+	}{
+		"v1.EndpointSlice: endpoints in the nodepool are reported as local endpoints": {
+			responseObject: newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+			service:        newService(corev1.ServiceTypeNodePort, corev1.ServiceExternalTrafficPolicyTypeLocal, true),
+			expectObject:   newEndpointSlice(currentNodeName, currentNodeName, nodeName3),
+		},
+		"v1.EndpointSlice: service is not annotated": {
+			responseObject: newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+			service:        newService(corev1.ServiceTypeNodePort, corev1.ServiceExternalTrafficPolicyTypeLocal, false),
+			expectObject:   newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+		},
+		"v1.EndpointSlice: externalTrafficPolicy of service is Cluster": {
+			responseObject: newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+			service:        newService(corev1.ServiceTypeLoadBalancer, corev1.ServiceExternalTrafficPolicyTypeCluster, true),
+			expectObject:   newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+		},
+		"v1.EndpointSlice: service is not NodePort or LoadBalancer": {
+			responseObject: newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+			service:        newService(corev1.ServiceTypeClusterIP, corev1.ServiceExternalTrafficPolicyTypeLocal, true),
+			expectObject:   newEndpointSlice(currentNodeName, nodeName2, nodeName3),
+		},
+		"v1beta1.EndpointSliceList: endpoints in the nodepool are reported as local endpoints": {
+			responseObject: &discoveryV1beta1.EndpointSliceList{
+				Items: []discoveryV1beta1.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discoveryV1beta1.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discoveryV1beta1.Endpoint{
+							{
+								Addresses: []string{"10.244.1.3"},
+								Topology: map[string]string{
+									corev1.LabelHostname: nodeName2,
+								},
+							},
+							{
+								Addresses: []string{"10.244.1.4"},
+								Topology: map[string]string{
+									corev1.LabelHostname: nodeName3,
+								},
+							},
+						},
+					},
+				},
+			},
+			service: newService(corev1.ServiceTypeLoadBalancer, corev1.ServiceExternalTrafficPolicyTypeLocal, true),
+			expectObject: &discoveryV1beta1.EndpointSliceList{
+				Items: []discoveryV1beta1.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discoveryV1beta1.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discoveryV1beta1.Endpoint{
+							{
+								Addresses: []string{"10.244.1.3"},
+								Topology: map[string]string{
+									corev1.LabelHostname: currentNodeName,
+								},
+							},
+							{
+								Addresses: []string{"10.244.1.4"},
+								Topology: map[string]string{
+									corev1.LabelHostname: nodeName3,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: addresses in the nodepool are reported as local addresses": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.3", NodeName: &nodeName2},
+							{IP: "10.244.1.4", NodeName: &nodeName3},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName2},
+						},
+					},
+				},
+			},
+			service: newService(corev1.ServiceTypeNodePort, corev1.ServiceExternalTrafficPolicyTypeLocal, true),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.3", NodeName: &currentNodeName},
+							{IP: "10.244.1.4", NodeName: &nodeName3},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &currentNodeName},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for k, tt := range testcases {
+		t.Run(k, func(t *testing.T) {
+			kubeClient := k8sfake.NewSimpleClientset(tt.service)
+			factory := informers.NewSharedInformerFactory(kubeClient, 24*time.Hour)
+			serviceInformer := factory.Core().V1().Services()
+			serviceInformer.Informer()
+
+			stopper := make(chan struct{})
+			defer close(stopper)
+			factory.Start(stopper)
+			factory.WaitForCacheSync(stopper)
+
+			gvr := v1beta1.GroupVersion.WithResource("nodepools")
+			yurtClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind, newNodePool())
+			yurtFactory := dynamicinformer.NewDynamicSharedInformerFactory(yurtClient, 24*time.Hour)
+			nodePoolInformer := yurtFactory.ForResource(gvr)
+			nodePoolInformer.Informer()
+
+			stopper2 := make(chan struct{})
+			defer close(stopper2)
+			yurtFactory.Start(stopper2)
+			yurtFactory.WaitForCacheSync(stopper2)
+
+			etf := &externalTrafficPolicyFilter{
+				nodeName:       currentNodeName,
+				nodePoolName:   "hangzhou",
+				serviceLister:  serviceInformer.Lister(),
+				serviceSynced:  serviceInformer.Informer().HasSynced,
+				nodePoolLister: nodePoolInformer.Lister(),
+				nodePoolSynced: nodePoolInformer.Informer().HasSynced,
+				client:         kubeClient,
+			}
+
+			newObj := etf.Filter(tt.responseObject, make(<-chan struct{}))
+			if !reflect.DeepEqual(newObj, tt.expectObject) {
+				t.Errorf("externalTrafficPolicyFilter expect: \n%#+v\nbut got: \n%#+v\n", tt.expectObject, newObj)
+			}
+		})
+	}
+}
@@ -31,10 +31,12 @@ import (
 
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/discardcloudservice"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/externaltrafficpolicy"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/inclusterconfig"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/masterservice"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/nodeportisolation"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/topologyhints"
 )
 
 func TestNew(t *testing.T) {
@@ -82,6 +84,14 @@ func TestInitialize(t *testing.T) {
 			fn:     servicetopology.NewServiceTopologyFilter,
 			result: nil,
 		},
+		"init externaltrafficpolicy filter": {
+			fn:     externaltrafficpolicy.NewExternalTrafficPolicyFilter,
+			result: nil,
+		},
+		"init topologyhints filter": {
+			fn:     topologyhints.NewTopologyHintsFilter,
+			result: nil,
+		},
 		"init errfilter filter": {
 			fn:     NewErrFilter,
 			result: nodeNameErr,
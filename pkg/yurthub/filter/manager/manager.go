@@ -29,12 +29,14 @@ import (
 	"github.com/openyurtio/openyurt/cmd/yurthub/app/options"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/discardcloudservice"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/externaltrafficpolicy"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/hostnetworkpropagation"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/inclusterconfig"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/initializer"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/masterservice"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/nodeportisolation"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/topologyhints"
 	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/serializer"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
@@ -136,4 +138,6 @@ func registerAllFilters(filters *filter.Filters) {
 	inclusterconfig.Register(filters)
 	nodeportisolation.Register(filters)
 	hostnetworkpropagation.Register(filters)
+	externaltrafficpolicy.Register(filters)
+	topologyhints.Register(filters)
 }
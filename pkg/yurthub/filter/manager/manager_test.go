@@ -82,7 +82,7 @@ func TestFindResponseFilter(t *testing.T) {
 			verb:                   "GET",
 			path:                   "/api/v1/services",
 			isFound:                true,
-			names:                  sets.NewString("discardcloudservice", "nodeportisolation"),
+			names:                  sets.NewString("discardcloudservice", "nodeportisolation", "topologyhints"),
 		},
 		"get service topology filter": {
 			enableResourceFilter:   true,
@@ -92,7 +92,7 @@ func TestFindResponseFilter(t *testing.T) {
 			verb:                   "GET",
 			path:                   "/api/v1/endpoints",
 			isFound:                true,
-			names:                  sets.NewString("servicetopology"),
+			names:                  sets.NewString("servicetopology", "externaltrafficpolicy"),
 		},
 		"disable service topology filter": {
 			enableResourceFilter:    true,
@@ -102,7 +102,8 @@ func TestFindResponseFilter(t *testing.T) {
 			userAgent:               "kube-proxy",
 			verb:                    "GET",
 			path:                    "/api/v1/endpoints",
-			isFound:                 false,
+			isFound:                 true,
+			names:                   sets.NewString("externaltrafficpolicy"),
 		},
 		"can't get discard cloud service filter in cloud mode": {
 			enableResourceFilter:   true,
@@ -112,7 +113,7 @@ func TestFindResponseFilter(t *testing.T) {
 			verb:                   "GET",
 			path:                   "/api/v1/services",
 			isFound:                true,
-			names:                  sets.NewString("nodeportisolation"),
+			names:                  sets.NewString("nodeportisolation", "topologyhints"),
 		},
 		"get hostnetwork propagation filter": {
 			enableResourceFilter:   true,
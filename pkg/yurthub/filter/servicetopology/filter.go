@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -38,11 +39,24 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
 )
 
+// AnnotationServiceTopologyKey specifies the topology of service, besides the following values,
+// it can also be any node label key, like topology.kubernetes.io/zone or a site-specific rack label,
+// then only the endpoints on the nodes with the same label value as the current node are kept.
+// The endpoints of headless service are filtered in the same way, so the service is resolved to the pods
+// under the topology by coredns on edge nodes. The identity records(<hostname>.<service>) of the pods outside
+// the topology, like the peers of statefulset, are resolved by the hosts records of raven dns controller.
 const (
 	AnnotationServiceTopologyKey           = "openyurt.io/topologyKeys"
 	AnnotationServiceTopologyValueNode     = "kubernetes.io/hostname"
 	AnnotationServiceTopologyValueZone     = "kubernetes.io/zone"
 	AnnotationServiceTopologyValueNodePool = "openyurt.io/nodepool"
+
+	// AnnotationServiceTopologyFallback specifies the behavior when there's no endpoint or none of the endpoints
+	// is serving under the topology of service. By default, only the endpoints under the topology are returned
+	// even if they are empty or not serving, so the traffic never goes out of the topology. All endpoints in
+	// the cluster are kept in both cases only if the value is cluster.
+	AnnotationServiceTopologyFallback             = "openyurt.io/topology-fallback"
+	AnnotationServiceTopologyFallbackValueCluster = "cluster"
 )
 
 var (
@@ -68,6 +82,7 @@ type serviceTopologyFilter struct {
 	nodePoolName   string
 	nodeName       string
 	client         kubernetes.Interface
+	topologyNodes  *topologyNodes
 }
 
 func (stf *serviceTopologyFilter) Name() string {
@@ -112,6 +127,13 @@ func (stf *serviceTopologyFilter) SetKubeClient(client kubernetes.Interface) err
 	return nil
 }
 
+func (stf *serviceTopologyFilter) resolveTopologyNodes() *topologyNodes {
+	if stf.topologyNodes == nil {
+		stf.topologyNodes = newTopologyNodes(stf.client, stf.nodeName)
+	}
+	return stf.topologyNodes
+}
+
 func (stf *serviceTopologyFilter) resolveNodePoolName() string {
 	if len(stf.nodePoolName) != 0 {
 		return stf.nodePoolName
@@ -136,7 +158,7 @@ func (stf *serviceTopologyFilter) Filter(obj runtime.Object, stopCh <-chan struc
 		// filter endpointSlice before k8s 1.21
 		var items []discoveryV1beta1.EndpointSlice
 		for i := range v.Items {
-			eps := stf.serviceTopologyHandler(&v.Items[i]).(*discoveryV1beta1.EndpointSlice)
+			eps := stf.serviceTopologyHandler(&v.Items[i], stopCh).(*discoveryV1beta1.EndpointSlice)
 			items = append(items, *eps)
 		}
 		v.Items = items
@@ -144,7 +166,7 @@ func (stf *serviceTopologyFilter) Filter(obj runtime.Object, stopCh <-chan struc
 	case *discovery.EndpointSliceList:
 		var items []discovery.EndpointSlice
 		for i := range v.Items {
-			eps := stf.serviceTopologyHandler(&v.Items[i]).(*discovery.EndpointSlice)
+			eps := stf.serviceTopologyHandler(&v.Items[i], stopCh).(*discovery.EndpointSlice)
 			items = append(items, *eps)
 		}
 		v.Items = items
@@ -152,24 +174,53 @@ func (stf *serviceTopologyFilter) Filter(obj runtime.Object, stopCh <-chan struc
 	case *v1.EndpointsList:
 		var items []v1.Endpoints
 		for i := range v.Items {
-			ep := stf.serviceTopologyHandler(&v.Items[i]).(*v1.Endpoints)
+			ep := stf.serviceTopologyHandler(&v.Items[i], stopCh).(*v1.Endpoints)
 			items = append(items, *ep)
 		}
 		v.Items = items
 		return v
 	case *v1.Endpoints, *discoveryV1beta1.EndpointSlice, *discovery.EndpointSlice:
-		return stf.serviceTopologyHandler(v)
+		return stf.serviceTopologyHandler(v, stopCh)
 	default:
 		return obj
 	}
 }
 
-func (stf *serviceTopologyFilter) serviceTopologyHandler(obj runtime.Object) runtime.Object {
-	needHandle, serviceTopologyType := stf.resolveServiceTopologyType(obj)
+func (stf *serviceTopologyFilter) serviceTopologyHandler(obj runtime.Object, stopCh <-chan struct{}) runtime.Object {
+	needHandle, serviceTopologyType, svc := stf.resolveServiceTopologyType(obj)
 	if !needHandle || len(serviceTopologyType) == 0 {
 		return obj
 	}
 
+	original := obj.DeepCopyObject()
+	filtered := stf.topologyHandler(obj, serviceTopologyType, stopCh)
+	if needFallback(svc, original, filtered) {
+		klog.V(4).Infof("no serving endpoints of service %s/%s under topology %s, so keep all endpoints", svc.Namespace, svc.Name, serviceTopologyType)
+		return original
+	}
+	return filtered
+}
+
+// needFallback checks whether all endpoints should be kept instead of the endpoints filtered by topology,
+// which happens only when the service is annotated to fall back to cluster.
+func needFallback(svc *v1.Service, original, filtered runtime.Object) bool {
+	if svc.Annotations[AnnotationServiceTopologyFallback] != AnnotationServiceTopologyFallbackValueCluster {
+		return false
+	}
+
+	// the not ready endpoints are published on purpose, like the peers of statefulset, so they are
+	// filtered by topology unless there's no endpoint under the topology at all.
+	if svc.Spec.PublishNotReadyAddresses {
+		return !hasEndpoints(filtered) && hasEndpoints(original)
+	}
+
+	// all endpoints are kept if none of the endpoints under the topology is serving, like the pods in
+	// the pool are being replaced by rolling update, so the traffic is not dropped while there're
+	// serving endpoints outside the topology.
+	return !hasServingEndpoints(filtered) && hasServingEndpoints(original)
+}
+
+func (stf *serviceTopologyFilter) topologyHandler(obj runtime.Object, serviceTopologyType string, stopCh <-chan struct{}) runtime.Object {
 	switch serviceTopologyType {
 	case AnnotationServiceTopologyValueNode:
 		// close traffic on the same node
@@ -178,11 +229,12 @@ func (stf *serviceTopologyFilter) serviceTopologyHandler(obj runtime.Object) run
 		// close traffic on the same node pool
 		return stf.nodePoolTopologyHandler(obj)
 	default:
-		return obj
+		// close traffic on the nodes with the same topology label value
+		return stf.nodeLabelTopologyHandler(obj, serviceTopologyType, stopCh)
 	}
 }
 
-func (stf *serviceTopologyFilter) resolveServiceTopologyType(obj runtime.Object) (bool, string) {
+func (stf *serviceTopologyFilter) resolveServiceTopologyType(obj runtime.Object) (bool, string, *v1.Service) {
 	var svcNamespace, svcName string
 	switch v := obj.(type) {
 	case *discoveryV1beta1.EndpointSlice:
@@ -195,19 +247,20 @@ func (stf *serviceTopologyFilter) resolveServiceTopologyType(obj runtime.Object)
 		svcNamespace = v.Namespace
 		svcName = v.Name
 	default:
-		return false, ""
+		return false, "", nil
 	}
 
 	svc, err := stf.serviceLister.Services(svcNamespace).Get(svcName)
 	if err != nil {
 		klog.Warningf("serviceTopologyFilterHandler: failed to get service %s/%s, err: %v", svcNamespace, svcName, err)
-		return false, ""
+		return false, "", nil
 	}
 
-	if topologyValueSets.Has(svc.Annotations[AnnotationServiceTopologyKey]) {
-		return true, svc.Annotations[AnnotationServiceTopologyKey]
+	topologyKey := svc.Annotations[AnnotationServiceTopologyKey]
+	if topologyValueSets.Has(topologyKey) || len(validation.IsQualifiedName(topologyKey)) == 0 {
+		return true, topologyKey, svc
 	}
-	return false, ""
+	return false, "", nil
 }
 
 func (stf *serviceTopologyFilter) nodeTopologyHandler(obj runtime.Object) runtime.Object {
@@ -250,22 +303,46 @@ func (stf *serviceTopologyFilter) nodePoolTopologyHandler(obj runtime.Object) ru
 		return obj
 	}
 
+	nodes := nodePool.Status.Nodes
+	if nodes == nil {
+		// no endpoints are kept for the empty pool
+		nodes = []string{}
+	}
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		return reassembleV1beta1EndpointSlice(v, "", nodes)
+	case *discovery.EndpointSlice:
+		return reassembleEndpointSlice(v, "", nodes)
+	case *v1.Endpoints:
+		return reassembleEndpoints(v, "", nodes)
+	default:
+		return obj
+	}
+}
+
+func (stf *serviceTopologyFilter) nodeLabelTopologyHandler(obj runtime.Object, topologyKey string, stopCh <-chan struct{}) runtime.Object {
+	nodes, ok := stf.resolveTopologyNodes().nodesInDomain(topologyKey, stopCh)
+	if !ok {
+		klog.Infof("node(%s) has no topology label %s, so fall into node topology", stf.nodeName, topologyKey)
+		return stf.nodeTopologyHandler(obj)
+	}
+
 	switch v := obj.(type) {
 	case *discoveryV1beta1.EndpointSlice:
-		return reassembleV1beta1EndpointSlice(v, "", nodePool)
+		return reassembleV1beta1EndpointSlice(v, "", nodes)
 	case *discovery.EndpointSlice:
-		return reassembleEndpointSlice(v, "", nodePool)
+		return reassembleEndpointSlice(v, "", nodes)
 	case *v1.Endpoints:
-		return reassembleEndpoints(v, "", nodePool)
+		return reassembleEndpoints(v, "", nodes)
 	default:
 		return obj
 	}
 }
 
-// reassembleV1beta1EndpointSlice will discard endpoints that are not on the same node/nodePool for v1beta1.EndpointSlice
-func reassembleV1beta1EndpointSlice(endpointSlice *discoveryV1beta1.EndpointSlice, nodeName string, nodePool *v1beta1.NodePool) *discoveryV1beta1.EndpointSlice {
-	if len(nodeName) != 0 && nodePool != nil {
-		klog.Warningf("reassembleV1beta1EndpointSlice: nodeName(%s) and nodePool can not be set at the same time", nodeName)
+// reassembleV1beta1EndpointSlice will discard endpoints that are not on the same node/nodePool/topology domain for v1beta1.EndpointSlice
+func reassembleV1beta1EndpointSlice(endpointSlice *discoveryV1beta1.EndpointSlice, nodeName string, nodes []string) *discoveryV1beta1.EndpointSlice {
+	if len(nodeName) != 0 && nodes != nil {
+		klog.Warningf("reassembleV1beta1EndpointSlice: nodeName(%s) and nodes can not be set at the same time", nodeName)
 		return endpointSlice
 	}
 
@@ -277,8 +354,8 @@ func reassembleV1beta1EndpointSlice(endpointSlice *discoveryV1beta1.EndpointSlic
 			}
 		}
 
-		if nodePool != nil {
-			if inSameNodePool(endpointSlice.Endpoints[i].Topology[v1.LabelHostname], nodePool.Status.Nodes) {
+		if nodes != nil {
+			if inNodes(endpointSlice.Endpoints[i].Topology[v1.LabelHostname], nodes) {
 				newEps = append(newEps, endpointSlice.Endpoints[i])
 			}
 		}
@@ -289,10 +366,10 @@ func reassembleV1beta1EndpointSlice(endpointSlice *discoveryV1beta1.EndpointSlic
 	return endpointSlice
 }
 
-// reassembleEndpointSlice will discard endpoints that are not on the same node/nodePool for v1.EndpointSlice
-func reassembleEndpointSlice(endpointSlice *discovery.EndpointSlice, nodeName string, nodePool *v1beta1.NodePool) *discovery.EndpointSlice {
-	if len(nodeName) != 0 && nodePool != nil {
-		klog.Warningf("reassembleEndpointSlice: nodeName(%s) and nodePool can not be set at the same time", nodeName)
+// reassembleEndpointSlice will discard endpoints that are not on the same node/nodePool/topology domain for v1.EndpointSlice
+func reassembleEndpointSlice(endpointSlice *discovery.EndpointSlice, nodeName string, nodes []string) *discovery.EndpointSlice {
+	if len(nodeName) != 0 && nodes != nil {
+		klog.Warningf("reassembleEndpointSlice: nodeName(%s) and nodes can not be set at the same time", nodeName)
 		return endpointSlice
 	}
 
@@ -304,8 +381,8 @@ func reassembleEndpointSlice(endpointSlice *discovery.EndpointSlice, nodeName st
 			}
 		}
 
-		if nodePool != nil {
-			if inSameNodePool(*endpointSlice.Endpoints[i].NodeName, nodePool.Status.Nodes) {
+		if nodes != nil {
+			if inNodes(*endpointSlice.Endpoints[i].NodeName, nodes) {
 				newEps = append(newEps, endpointSlice.Endpoints[i])
 			}
 		}
@@ -316,10 +393,10 @@ func reassembleEndpointSlice(endpointSlice *discovery.EndpointSlice, nodeName st
 	return endpointSlice
 }
 
-// reassembleEndpoints will discard subset that are not on the same node/nodePool for v1.Endpoints
-func reassembleEndpoints(endpoints *v1.Endpoints, nodeName string, nodePool *v1beta1.NodePool) *v1.Endpoints {
-	if len(nodeName) != 0 && nodePool != nil {
-		klog.Warningf("reassembleEndpoints: nodeName(%s) and nodePool can not be set at the same time", nodeName)
+// reassembleEndpoints will discard subset that are not on the same node/nodePool/topology domain for v1.Endpoints
+func reassembleEndpoints(endpoints *v1.Endpoints, nodeName string, nodes []string) *v1.Endpoints {
+	if len(nodeName) != 0 && nodes != nil {
+		klog.Warningf("reassembleEndpoints: nodeName(%s) and nodes can not be set at the same time", nodeName)
 		return endpoints
 	}
 
@@ -330,9 +407,9 @@ func reassembleEndpoints(endpoints *v1.Endpoints, nodeName string, nodePool *v1b
 			endpoints.Subsets[i].NotReadyAddresses = filterValidEndpointsAddr(endpoints.Subsets[i].NotReadyAddresses, nodeName, nil)
 		}
 
-		if nodePool != nil {
-			endpoints.Subsets[i].Addresses = filterValidEndpointsAddr(endpoints.Subsets[i].Addresses, "", nodePool)
-			endpoints.Subsets[i].NotReadyAddresses = filterValidEndpointsAddr(endpoints.Subsets[i].NotReadyAddresses, "", nodePool)
+		if nodes != nil {
+			endpoints.Subsets[i].Addresses = filterValidEndpointsAddr(endpoints.Subsets[i].Addresses, "", nodes)
+			endpoints.Subsets[i].NotReadyAddresses = filterValidEndpointsAddr(endpoints.Subsets[i].NotReadyAddresses, "", nodes)
 		}
 
 		if len(endpoints.Subsets[i].Addresses) != 0 || len(endpoints.Subsets[i].NotReadyAddresses) != 0 {
@@ -345,7 +422,7 @@ func reassembleEndpoints(endpoints *v1.Endpoints, nodeName string, nodePool *v1b
 	return endpoints
 }
 
-func filterValidEndpointsAddr(addresses []v1.EndpointAddress, nodeName string, nodePool *v1beta1.NodePool) []v1.EndpointAddress {
+func filterValidEndpointsAddr(addresses []v1.EndpointAddress, nodeName string, nodes []string) []v1.EndpointAddress {
 	var newEpAddresses []v1.EndpointAddress
 	for i := range addresses {
 		if addresses[i].NodeName == nil {
@@ -360,8 +437,8 @@ func filterValidEndpointsAddr(addresses []v1.EndpointAddress, nodeName string, n
 		}
 
 		// filter address on the same node pool
-		if nodePool != nil {
-			if inSameNodePool(*addresses[i].NodeName, nodePool.Status.Nodes) {
+		if nodes != nil {
+			if inNodes(*addresses[i].NodeName, nodes) {
 				newEpAddresses = append(newEpAddresses, addresses[i])
 			}
 		}
@@ -369,7 +446,55 @@ func filterValidEndpointsAddr(addresses []v1.EndpointAddress, nodeName string, n
 	return newEpAddresses
 }
 
-func inSameNodePool(nodeName string, nodeList []string) bool {
+// hasServingEndpoints checks whether there's any endpoint which can serve traffic, that is the endpoint
+// is ready, or it's still serving while terminating, which is used by kube-proxy if no endpoint is ready.
+func hasServingEndpoints(obj runtime.Object) bool {
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		for i := range v.Endpoints {
+			if isServing(v.Endpoints[i].Conditions.Ready, v.Endpoints[i].Conditions.Serving) {
+				return true
+			}
+		}
+	case *discovery.EndpointSlice:
+		for i := range v.Endpoints {
+			if isServing(v.Endpoints[i].Conditions.Ready, v.Endpoints[i].Conditions.Serving) {
+				return true
+			}
+		}
+	case *v1.Endpoints:
+		for i := range v.Subsets {
+			if len(v.Subsets[i].Addresses) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasEndpoints checks whether there's any endpoint regardless of its conditions.
+func hasEndpoints(obj runtime.Object) bool {
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		return len(v.Endpoints) != 0
+	case *discovery.EndpointSlice:
+		return len(v.Endpoints) != 0
+	case *v1.Endpoints:
+		for i := range v.Subsets {
+			if len(v.Subsets[i].Addresses) != 0 || len(v.Subsets[i].NotReadyAddresses) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isServing returns true if the endpoint is ready or serving, nil ready condition means ready.
+func isServing(ready, serving *bool) bool {
+	return ready == nil || *ready || (serving != nil && *serving)
+}
+
+func inNodes(nodeName string, nodeList []string) bool {
 	for _, n := range nodeList {
 		if nodeName == n {
 			return true
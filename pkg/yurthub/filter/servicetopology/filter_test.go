@@ -40,6 +40,29 @@ import (
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
 )
 
+const testRackLabel = "example.com/rack"
+
+func newRackNode(name, rack string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{testRackLabel: rack},
+		},
+	}
+}
+
+func newTopologyService(name, topologyKey string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationServiceTopologyKey: topologyKey,
+			},
+		},
+	}
+}
+
 func TestName(t *testing.T) {
 	stf, _ := NewServiceTopologyFilter()
 	if stf.Name() != filter.ServiceTopologyFilterName {
@@ -74,6 +97,8 @@ func TestFilter(t *testing.T) {
 	currentNodeName := "node1"
 	nodeName2 := "node2"
 	nodeName3 := "node3"
+	ready, notReady := true, false
+	web0, web1, web2 := "web-0", "web-1", "web-2"
 
 	testcases := map[string]struct {
 		poolName       string
@@ -1084,6 +1109,613 @@ func TestFilter(t *testing.T) {
 				},
 			},
 		},
+		"v1.EndpointSliceList: topologyKeys is a custom node label": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.2"},
+								NodeName:  &currentNodeName,
+							},
+							{
+								Addresses: []string{"10.244.1.3"},
+								NodeName:  &nodeName2,
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName2, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				newTopologyService("svc1", testRackLabel),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.2"},
+								NodeName:  &currentNodeName,
+							},
+							{
+								Addresses: []string{"10.244.1.3"},
+								NodeName:  &nodeName2,
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: topologyKeys is a custom node label": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName},
+							{IP: "10.244.1.3", NodeName: &nodeName2},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName2, "rack-b"),
+				newRackNode(nodeName3, "rack-b"),
+				newTopologyService("svc1", testRackLabel),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: current node has no custom topology label": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.2"},
+								NodeName:  &currentNodeName,
+							},
+							{
+								Addresses: []string{"10.244.1.3"},
+								NodeName:  &nodeName2,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: currentNodeName}},
+				newRackNode(nodeName2, "rack-a"),
+				newTopologyService("svc1", testRackLabel),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.2"},
+								NodeName:  &currentNodeName,
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: no serving endpoints under topology": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &notReady},
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				newTopologyService("svc1", testRackLabel),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &notReady},
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: fall back to cluster when no serving endpoints under topology": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &notReady},
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("svc1", testRackLabel)
+					svc.Annotations[AnnotationServiceTopologyFallback] = AnnotationServiceTopologyFallbackValueCluster
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &notReady},
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: terminating endpoints under topology are still serving": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &ready, Terminating: &ready},
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				newTopologyService("svc1", testRackLabel),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Conditions: discovery.EndpointConditions{Ready: &notReady, Serving: &ready, Terminating: &ready},
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: service publishes not ready addresses": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("svc1", testRackLabel)
+					svc.Spec.PublishNotReadyAddresses = true
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: service publishing not ready addresses falls back to cluster when no endpoints under topology": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.4", NodeName: &nodeName3},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("svc1", testRackLabel)
+					svc.Spec.PublishNotReadyAddresses = true
+					svc.Annotations[AnnotationServiceTopologyFallback] = AnnotationServiceTopologyFallbackValueCluster
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+						NotReadyAddresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.4", NodeName: &nodeName3},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: fall back to cluster when no endpoints under topology": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.3"},
+								NodeName:  &nodeName2,
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName2, "rack-b"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("svc1", testRackLabel)
+					svc.Annotations[AnnotationServiceTopologyFallback] = AnnotationServiceTopologyFallbackValueCluster
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "svc1-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "svc1",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses: []string{"10.244.1.3"},
+								NodeName:  &nodeName2,
+							},
+							{
+								Addresses: []string{"10.244.1.5"},
+								NodeName:  &nodeName3,
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: fall back to cluster when no ready endpoints under topology": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("svc1", testRackLabel)
+					svc.Annotations[AnnotationServiceTopologyFallback] = AnnotationServiceTopologyFallbackValueCluster
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "svc1",
+					Namespace: "default",
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.5", NodeName: &nodeName3},
+						},
+					},
+				},
+			},
+		},
+		"v1.EndpointSliceList: headless service of statefulset": {
+			responseObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "web-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "web",
+								corev1.IsHeadlessService:   "",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Hostname:   &web0,
+								Conditions: discovery.EndpointConditions{Ready: &ready},
+							},
+							{
+								Addresses:  []string{"10.244.1.3"},
+								NodeName:   &nodeName2,
+								Hostname:   &web1,
+								Conditions: discovery.EndpointConditions{Ready: &ready},
+							},
+							{
+								Addresses:  []string{"10.244.1.5"},
+								NodeName:   &nodeName3,
+								Hostname:   &web2,
+								Conditions: discovery.EndpointConditions{Ready: &ready},
+							},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName2, "rack-b"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("web", testRackLabel)
+					svc.Spec.ClusterIP = corev1.ClusterIPNone
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &discovery.EndpointSliceList{
+				Items: []discovery.EndpointSlice{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "web-np7sf",
+							Namespace: "default",
+							Labels: map[string]string{
+								discovery.LabelServiceName: "web",
+								corev1.IsHeadlessService:   "",
+							},
+						},
+						Endpoints: []discovery.Endpoint{
+							{
+								Addresses:  []string{"10.244.1.2"},
+								NodeName:   &currentNodeName,
+								Hostname:   &web0,
+								Conditions: discovery.EndpointConditions{Ready: &ready},
+							},
+						},
+					},
+				},
+			},
+		},
+		"v1.Endpoints: headless service of statefulset": {
+			responseObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web",
+					Namespace: "default",
+					Labels:    map[string]string{corev1.IsHeadlessService: ""},
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName, Hostname: web0},
+							{IP: "10.244.1.3", NodeName: &nodeName2, Hostname: web1},
+							{IP: "10.244.1.5", NodeName: &nodeName3, Hostname: web2},
+						},
+					},
+				},
+			},
+			kubeClient: k8sfake.NewSimpleClientset(
+				newRackNode(currentNodeName, "rack-a"),
+				newRackNode(nodeName2, "rack-b"),
+				newRackNode(nodeName3, "rack-b"),
+				func() *corev1.Service {
+					svc := newTopologyService("web", testRackLabel)
+					svc.Spec.ClusterIP = corev1.ClusterIPNone
+					return svc
+				}(),
+			),
+			yurtClient: fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind),
+			expectObject: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web",
+					Namespace: "default",
+					Labels:    map[string]string{corev1.IsHeadlessService: ""},
+				},
+				Subsets: []corev1.EndpointSubset{
+					{
+						Addresses: []corev1.EndpointAddress{
+							{IP: "10.244.1.2", NodeName: &currentNodeName, Hostname: web0},
+						},
+					},
+				},
+			},
+		},
 		"v1beta1.EndpointSliceList: no service info in endpointslice": {
 			responseObject: &discoveryV1beta1.EndpointSliceList{
 				Items: []discoveryV1beta1.EndpointSlice{
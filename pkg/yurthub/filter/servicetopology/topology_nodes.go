@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicetopology
+
+import (
+	"context"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// topologyDomain is the nodes which have the same value of a topology label as the current node.
+type topologyDomain struct {
+	value  string
+	lister listers.NodeLister
+	synced cache.InformerSynced
+	stopCh chan struct{}
+}
+
+// topologyNodes tracks the nodes in the same topology domains as the current node, the topology domain
+// is defined by a node label key, like topology.kubernetes.io/zone or a site-specific rack label. The
+// nodes of each domain are listed/watched with label selector, so only a few nodes are cached.
+type topologyNodes struct {
+	sync.Mutex
+	client     kubernetes.Interface
+	nodeName   string
+	nodeLabels map[string]string
+	domains    map[string]*topologyDomain
+}
+
+func newTopologyNodes(client kubernetes.Interface, nodeName string) *topologyNodes {
+	return &topologyNodes{
+		client:   client,
+		nodeName: nodeName,
+		domains:  make(map[string]*topologyDomain),
+	}
+}
+
+// resolveNodeLabels gets the labels of the current node, they are cached after got successfully.
+func (tn *topologyNodes) resolveNodeLabels() map[string]string {
+	if tn.nodeLabels != nil {
+		return tn.nodeLabels
+	}
+
+	node, err := tn.client.CoreV1().Nodes().Get(context.Background(), tn.nodeName, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("failed to get node(%s) in serviceTopologyFilter filter, %v", tn.nodeName, err)
+		return nil
+	}
+	tn.nodeLabels = node.Labels
+	if tn.nodeLabels == nil {
+		tn.nodeLabels = map[string]string{}
+	}
+	return tn.nodeLabels
+}
+
+// domainOf returns the domain of topologyKey which the current node belongs to, nil is
+// returned if the current node has no label of topologyKey.
+func (tn *topologyNodes) domainOf(topologyKey string) *topologyDomain {
+	tn.Lock()
+	defer tn.Unlock()
+	value, ok := tn.resolveNodeLabels()[topologyKey]
+	if !ok {
+		return nil
+	}
+
+	if domain, ok := tn.domains[topologyKey]; ok && domain.value == value {
+		return domain
+	} else if ok {
+		close(domain.stopCh)
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{topologyKey: value}).String()
+	factory := informers.NewSharedInformerFactoryWithOptions(tn.client, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+		options.LabelSelector = selector
+	}))
+	nodeInformer := factory.Core().V1().Nodes()
+	domain := &topologyDomain{
+		value:  value,
+		lister: nodeInformer.Lister(),
+		synced: nodeInformer.Informer().HasSynced,
+		stopCh: make(chan struct{}),
+	}
+	factory.Start(domain.stopCh)
+	tn.domains[topologyKey] = domain
+	return domain
+}
+
+// nodesInDomain returns the names of nodes in the same domain of topologyKey as the current node,
+// false is returned if the current node is not in any domain of topologyKey.
+func (tn *topologyNodes) nodesInDomain(topologyKey string, stopCh <-chan struct{}) ([]string, bool) {
+	domain := tn.domainOf(topologyKey)
+	if domain == nil {
+		return nil, false
+	}
+	if ok := cache.WaitForCacheSync(stopCh, domain.synced); !ok {
+		return nil, false
+	}
+
+	nodes, err := domain.lister.List(labels.Everything())
+	if err != nil {
+		klog.Warningf("failed to list nodes of topology %s=%s, %v", topologyKey, domain.value, err)
+		return nil, false
+	}
+	names := make([]string, 0, len(nodes))
+	for i := range nodes {
+		names = append(names, nodes[i].Name)
+	}
+	return names, true
+}
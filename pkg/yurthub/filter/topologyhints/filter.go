@@ -0,0 +1,329 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyhints
+
+import (
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	discoveryV1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
+)
+
+// AnnotationTopologyHints enables the topology hints of service when the value is nodepool, the endpoints in the
+// same NodePool are hinted for the zone of node, so kube-proxy prefers them. AnnotationTopologySpillover specifies
+// the neighbor NodePools with weights(like pool-b=2,pool-c=1) which take the spilled traffic when the serving
+// endpoints in the NodePool are fewer than AnnotationTopologyMinLocalEndpoints(1 by default).
+const (
+	AnnotationTopologyHints              = "openyurt.io/topology-hints"
+	AnnotationTopologyHintsValueNodePool = "nodepool"
+	AnnotationTopologySpillover          = "openyurt.io/topology-spillover"
+	AnnotationTopologyMinLocalEndpoints  = "openyurt.io/topology-min-local-endpoints"
+)
+
+// Register registers a filter
+func Register(filters *filter.Filters) {
+	filters.Register(filter.TopologyHintsFilterName, func() (filter.ObjectFilter, error) {
+		return NewTopologyHintsFilter()
+	})
+}
+
+func NewTopologyHintsFilter() (filter.ObjectFilter, error) {
+	return &topologyHintsFilter{}, nil
+}
+
+// topologyHintsFilter generates the topology hints of EndpointSlices for kube-proxy on the node. the hints are
+// generated for each node, the selected endpoints are hinted for the zone(topology.kubernetes.io/zone) of node
+// and the others are hinted for their own NodePools, and the services are annotated with topology-aware-hints=auto,
+// so kube-proxy only forwards the traffic to the selected endpoints. the endpoints are selected within each
+// EndpointSlice, and kube-proxy falls back to all endpoints if no endpoint is selected.
+type topologyHintsFilter struct {
+	serviceLister  listers.ServiceLister
+	serviceSynced  cache.InformerSynced
+	nodePoolLister cache.GenericLister
+	nodePoolSynced cache.InformerSynced
+	nodePoolName   string
+	nodeName       string
+	client         kubernetes.Interface
+	nodeOnce       sync.Once
+	nodeLister     listers.NodeLister
+	nodeSynced     cache.InformerSynced
+}
+
+func (thf *topologyHintsFilter) Name() string {
+	return filter.TopologyHintsFilterName
+}
+
+func (thf *topologyHintsFilter) SupportedResourceAndVerbs() map[string]sets.String {
+	return map[string]sets.String{
+		"services":       sets.NewString("list", "watch"),
+		"endpointslices": sets.NewString("list", "watch"),
+	}
+}
+
+func (thf *topologyHintsFilter) SetSharedInformerFactory(factory informers.SharedInformerFactory) error {
+	thf.serviceLister = factory.Core().V1().Services().Lister()
+	thf.serviceSynced = factory.Core().V1().Services().Informer().HasSynced
+
+	return nil
+}
+
+func (thf *topologyHintsFilter) SetNodePoolInformerFactory(dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory) error {
+	gvr := v1beta1.GroupVersion.WithResource("nodepools")
+	thf.nodePoolLister = dynamicInformerFactory.ForResource(gvr).Lister()
+	thf.nodePoolSynced = dynamicInformerFactory.ForResource(gvr).Informer().HasSynced
+
+	return nil
+}
+
+func (thf *topologyHintsFilter) SetNodeName(nodeName string) error {
+	thf.nodeName = nodeName
+
+	return nil
+}
+
+func (thf *topologyHintsFilter) SetNodePoolName(poolName string) error {
+	thf.nodePoolName = poolName
+	return nil
+}
+
+func (thf *topologyHintsFilter) SetKubeClient(client kubernetes.Interface) error {
+	thf.client = client
+	return nil
+}
+
+func (thf *topologyHintsFilter) Filter(obj runtime.Object, stopCh <-chan struct{}) runtime.Object {
+	switch v := obj.(type) {
+	case *v1.ServiceList:
+		for i := range v.Items {
+			annotateService(&v.Items[i])
+		}
+		return v
+	case *v1.Service:
+		return annotateService(v)
+	}
+
+	thf.startNodeInformer()
+	if ok := cache.WaitForCacheSync(stopCh, thf.serviceSynced, thf.nodePoolSynced, thf.nodeSynced); !ok {
+		return obj
+	}
+
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSliceList:
+		for i := range v.Items {
+			thf.topologyHintsHandler(&v.Items[i])
+		}
+		return v
+	case *discovery.EndpointSliceList:
+		for i := range v.Items {
+			thf.topologyHintsHandler(&v.Items[i])
+		}
+		return v
+	case *discoveryV1beta1.EndpointSlice, *discovery.EndpointSlice:
+		return thf.topologyHintsHandler(v)
+	default:
+		return obj
+	}
+}
+
+// annotateService makes kube-proxy consume the topology hints of the service.
+func annotateService(svc *v1.Service) *v1.Service {
+	if svc.Annotations[AnnotationTopologyHints] != AnnotationTopologyHintsValueNodePool {
+		return svc
+	}
+	annotations := make(map[string]string, len(svc.Annotations)+1)
+	for k, v := range svc.Annotations {
+		annotations[k] = v
+	}
+	annotations[v1.AnnotationTopologyAwareHints] = "auto"
+	svc.Annotations = annotations
+	return svc
+}
+
+func (thf *topologyHintsFilter) topologyHintsHandler(obj runtime.Object) runtime.Object {
+	var svcNamespace, svcName string
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		svcNamespace = v.Namespace
+		svcName = v.Labels[discoveryV1beta1.LabelServiceName]
+	case *discovery.EndpointSlice:
+		svcNamespace = v.Namespace
+		svcName = v.Labels[discovery.LabelServiceName]
+	default:
+		return obj
+	}
+
+	svc, err := thf.serviceLister.Services(svcNamespace).Get(svcName)
+	if err != nil {
+		klog.Warningf("topologyHintsFilter: failed to get service %s/%s, err: %v", svcNamespace, svcName, err)
+		return obj
+	}
+	cfg, err := parseHintsConfig(svc)
+	if err != nil {
+		klog.Warningf("topologyHintsFilter: skip service %s/%s, %v", svcNamespace, svcName, err)
+		return obj
+	}
+	if cfg == nil {
+		return obj
+	}
+
+	zone, localPool := thf.resolveZoneAndPool()
+	if len(zone) == 0 || len(localPool) == 0 {
+		klog.V(4).Infof("node(%s) has no zone label or is not added into node pool, so skip topologyHintsFilter", thf.nodeName)
+		return obj
+	}
+	nodeToPool, ok := thf.resolveNodeToPool()
+	if !ok {
+		return obj
+	}
+
+	switch v := obj.(type) {
+	case *discoveryV1beta1.EndpointSlice:
+		eps := make([]endpointInfo, len(v.Endpoints))
+		for i := range v.Endpoints {
+			eps[i] = endpointInfo{
+				address: strings.Join(v.Endpoints[i].Addresses, ","),
+				node:    v.Endpoints[i].Topology[v1.LabelHostname],
+				serving: isServing(v.Endpoints[i].Conditions.Serving, v.Endpoints[i].Conditions.Ready),
+			}
+			if v.Endpoints[i].NodeName != nil {
+				eps[i].node = *v.Endpoints[i].NodeName
+			}
+		}
+		selected := selectEndpoints(eps, cfg, localPool, thf.nodeName, nodeToPool)
+		for i := range v.Endpoints {
+			v.Endpoints[i].Hints = &discoveryV1beta1.EndpointHints{
+				ForZones: []discoveryV1beta1.ForZone{{Name: hintZone(selected[i], zone, eps[i].node, nodeToPool)}},
+			}
+		}
+	case *discovery.EndpointSlice:
+		eps := make([]endpointInfo, len(v.Endpoints))
+		for i := range v.Endpoints {
+			eps[i] = endpointInfo{
+				address: strings.Join(v.Endpoints[i].Addresses, ","),
+				serving: isServing(v.Endpoints[i].Conditions.Serving, v.Endpoints[i].Conditions.Ready),
+			}
+			if v.Endpoints[i].NodeName != nil {
+				eps[i].node = *v.Endpoints[i].NodeName
+			}
+		}
+		selected := selectEndpoints(eps, cfg, localPool, thf.nodeName, nodeToPool)
+		for i := range v.Endpoints {
+			v.Endpoints[i].Hints = &discovery.EndpointHints{
+				ForZones: []discovery.ForZone{{Name: hintZone(selected[i], zone, eps[i].node, nodeToPool)}},
+			}
+		}
+	}
+	return obj
+}
+
+// hintZone returns the zone which the endpoint is hinted for, the endpoints which are not selected are hinted
+// for their own NodePools, so they are never consumed by kube-proxy on the node.
+func hintZone(selected bool, zone, node string, nodeToPool map[string]string) string {
+	if selected {
+		return zone
+	}
+	if pool := nodeToPool[node]; len(pool) != 0 && pool != zone {
+		return pool
+	}
+	return node
+}
+
+func isServing(serving, ready *bool) bool {
+	if serving != nil {
+		return *serving
+	}
+	return ready == nil || *ready
+}
+
+// resolveNodeToPool returns the NodePool of each node.
+func (thf *topologyHintsFilter) resolveNodeToPool() (map[string]string, bool) {
+	runtimeObjs, err := thf.nodePoolLister.List(labels.Everything())
+	if err != nil {
+		klog.Warningf("topologyHintsFilter: failed to list nodepools, err: %v", err)
+		return nil, false
+	}
+
+	nodeToPool := make(map[string]string)
+	for _, runtimeObj := range runtimeObjs {
+		var nodePool *v1beta1.NodePool
+		switch poolObj := runtimeObj.(type) {
+		case *v1beta1.NodePool:
+			nodePool = poolObj
+		case *unstructured.Unstructured:
+			nodePool = new(v1beta1.NodePool)
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(poolObj.UnstructuredContent(), nodePool); err != nil {
+				klog.Warningf("object(%#+v) is not a v1beta1.NodePool", poolObj)
+				continue
+			}
+		default:
+			klog.Warningf("object(%#+v) is not a unknown type", poolObj)
+			continue
+		}
+		for _, node := range nodePool.Status.Nodes {
+			nodeToPool[node] = nodePool.Name
+		}
+	}
+	return nodeToPool, true
+}
+
+// startNodeInformer lists/watches the current node with field selector, so the labels of node are read from
+// cache instead of kube-apiserver for each EndpointSlice, and only the current node is cached.
+func (thf *topologyHintsFilter) startNodeInformer() {
+	thf.nodeOnce.Do(func() {
+		factory := informers.NewSharedInformerFactoryWithOptions(thf.client, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", thf.nodeName).String()
+		}))
+		nodeInformer := factory.Core().V1().Nodes()
+		thf.nodeLister = nodeInformer.Lister()
+		thf.nodeSynced = nodeInformer.Informer().HasSynced
+		factory.Start(wait.NeverStop)
+	})
+}
+
+// resolveZoneAndPool returns the zone label and NodePool of the current node.
+func (thf *topologyHintsFilter) resolveZoneAndPool() (string, string) {
+	node, err := thf.nodeLister.Get(thf.nodeName)
+	if err != nil {
+		klog.Warningf("failed to get node(%s) in topologyHintsFilter filter, %v", thf.nodeName, err)
+		return "", thf.nodePoolName
+	}
+
+	poolName := thf.nodePoolName
+	if len(poolName) == 0 {
+		poolName = node.Labels[apps.NodePoolLabel]
+	}
+	return node.Labels[v1.LabelTopologyZone], poolName
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyhints
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter"
+)
+
+func newService(annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "svc1",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+	}
+}
+
+func newNodePool(name string, nodes ...string) *v1beta1.NodePool {
+	return &v1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1beta1.NodePoolStatus{Nodes: nodes},
+	}
+}
+
+func TestName(t *testing.T) {
+	thf, _ := NewTopologyHintsFilter()
+	if thf.Name() != filter.TopologyHintsFilterName {
+		t.Errorf("expect %s, but got %s", filter.TopologyHintsFilterName, thf.Name())
+	}
+}
+
+func TestParseHintsConfig(t *testing.T) {
+	testcases := map[string]struct {
+		annotations map[string]string
+		expect      *hintsConfig
+		expectErr   bool
+	}{
+		"hints are not enabled": {
+			annotations: map[string]string{AnnotationTopologySpillover: "shanghai"},
+		},
+		"default configuration": {
+			annotations: map[string]string{AnnotationTopologyHints: AnnotationTopologyHintsValueNodePool},
+			expect:      &hintsConfig{minLocalEndpoints: 1},
+		},
+		"spillover with weights": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:             AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologySpillover:         "shanghai=3, beijing",
+				AnnotationTopologyMinLocalEndpoints: "2",
+			},
+			expect: &hintsConfig{
+				minLocalEndpoints: 2,
+				spillover:         []spilloverPool{{name: "shanghai", weight: 3}, {name: "beijing", weight: 1}},
+			},
+		},
+		"invalid weight": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:     AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologySpillover: "shanghai=0",
+			},
+			expectErr: true,
+		},
+		"invalid min local endpoints": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:             AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologyMinLocalEndpoints: "foo",
+			},
+			expectErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			cfg, err := parseHintsConfig(newService(tc.annotations))
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.expectErr, err)
+			}
+			if !reflect.DeepEqual(cfg, tc.expect) {
+				t.Errorf("expect %#+v, but got %#+v", tc.expect, cfg)
+			}
+		})
+	}
+}
+
+func TestSpilloverQuotas(t *testing.T) {
+	candidates := map[string][]int{
+		"shanghai": {0, 1, 2, 3},
+		"beijing":  {4},
+	}
+	testcases := map[string]struct {
+		need   int
+		pools  []spilloverPool
+		expect map[string]int
+	}{
+		"distributed by weights": {
+			need:   4,
+			pools:  []spilloverPool{{name: "shanghai", weight: 3}, {name: "beijing", weight: 1}},
+			expect: map[string]int{"shanghai": 3, "beijing": 1},
+		},
+		"quota exceeding the endpoints is given to others": {
+			need:   4,
+			pools:  []spilloverPool{{name: "shanghai", weight: 1}, {name: "beijing", weight: 3}},
+			expect: map[string]int{"shanghai": 3, "beijing": 1},
+		},
+		"not enough endpoints": {
+			need:   10,
+			pools:  []spilloverPool{{name: "shanghai", weight: 1}, {name: "beijing", weight: 1}, {name: "hangzhou", weight: 1}},
+			expect: map[string]int{"shanghai": 4, "beijing": 1},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			quotas := spilloverQuotas(tc.need, tc.pools, candidates)
+			for pool, n := range tc.expect {
+				if quotas[pool] != n {
+					t.Errorf("expect quota of %s is %d, but got %d", pool, n, quotas[pool])
+				}
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	apis.AddToScheme(scheme)
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "apps.openyurt.io", Version: "v1beta1", Resource: "nodepools"}: "NodePoolList",
+	}
+	currentNodeName := "node1"
+	nodes := []string{currentNodeName, "node2", "node3", "node4"}
+	ready, notReady := true, false
+	newEndpointSlice := func(readiness ...bool) *discovery.EndpointSlice {
+		eps := &discovery.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "svc1-np7sf",
+				Namespace: "default",
+				Labels: map[string]string{
+					discovery.LabelServiceName: "svc1",
+				},
+			},
+		}
+		for i := range readiness {
+			eps.Endpoints = append(eps.Endpoints, discovery.Endpoint{
+				Addresses:  []string{fmt.Sprintf("10.244.1.%d", i)},
+				NodeName:   &nodes[i],
+				Conditions: discovery.EndpointConditions{Ready: &readiness[i]},
+			})
+		}
+		return eps
+	}
+	hinted := func(eps *discovery.EndpointSlice, zones ...string) *discovery.EndpointSlice {
+		for i := range zones {
+			eps.Endpoints[i].Hints = &discovery.EndpointHints{ForZones: []discovery.ForZone{{Name: zones[i]}}}
+		}
+		return eps
+	}
+
+	testcases := map[string]struct {
+		annotations    map[string]string
+		responseObject runtime.Object
+		expectObject   runtime.Object
+	}{
+		"endpoints in the nodepool are preferred": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:     AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologySpillover: "shanghai",
+			},
+			responseObject: newEndpointSlice(ready, ready, ready, ready),
+			expectObject:   hinted(newEndpointSlice(ready, ready, ready, ready), "zone-a", "zone-a", "shanghai", "beijing"),
+		},
+		"traffic is spilled over to the neighbor nodepool": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:     AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologySpillover: "shanghai",
+			},
+			responseObject: newEndpointSlice(notReady, notReady, ready, ready),
+			expectObject:   hinted(newEndpointSlice(notReady, notReady, ready, ready), "zone-a", "zone-a", "zone-a", "beijing"),
+		},
+		"local capacity is saturated": {
+			annotations: map[string]string{
+				AnnotationTopologyHints:             AnnotationTopologyHintsValueNodePool,
+				AnnotationTopologySpillover:         "shanghai,beijing",
+				AnnotationTopologyMinLocalEndpoints: "4",
+			},
+			responseObject: newEndpointSlice(ready, ready, ready, ready),
+			expectObject:   hinted(newEndpointSlice(ready, ready, ready, ready), "zone-a", "zone-a", "zone-a", "zone-a"),
+		},
+		"hints are not enabled": {
+			annotations:    map[string]string{AnnotationTopologySpillover: "shanghai"},
+			responseObject: newEndpointSlice(ready, ready, ready, ready),
+			expectObject:   newEndpointSlice(ready, ready, ready, ready),
+		},
+	}
+
+	for k, tt := range testcases {
+		t.Run(k, func(t *testing.T) {
+			kubeClient := k8sfake.NewSimpleClientset(
+				newService(tt.annotations),
+				&corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: currentNodeName,
+						Labels: map[string]string{
+							apps.NodePoolLabel:       "hangzhou",
+							corev1.LabelTopologyZone: "zone-a",
+						},
+					},
+				},
+			)
+			factory := informers.NewSharedInformerFactory(kubeClient, 24*time.Hour)
+			serviceInformer := factory.Core().V1().Services()
+			serviceInformer.Informer()
+
+			stopper := make(chan struct{})
+			defer close(stopper)
+			factory.Start(stopper)
+			factory.WaitForCacheSync(stopper)
+
+			gvr := v1beta1.GroupVersion.WithResource("nodepools")
+			yurtClient := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind,
+				newNodePool("hangzhou", currentNodeName, "node2"),
+				newNodePool("shanghai", "node3"),
+				newNodePool("beijing", "node4"),
+			)
+			yurtFactory := dynamicinformer.NewDynamicSharedInformerFactory(yurtClient, 24*time.Hour)
+			nodePoolInformer := yurtFactory.ForResource(gvr)
+			nodePoolInformer.Informer()
+
+			stopper2 := make(chan struct{})
+			defer close(stopper2)
+			yurtFactory.Start(stopper2)
+			yurtFactory.WaitForCacheSync(stopper2)
+
+			thf := &topologyHintsFilter{
+				nodeName:       currentNodeName,
+				serviceLister:  serviceInformer.Lister(),
+				serviceSynced:  serviceInformer.Informer().HasSynced,
+				nodePoolLister: nodePoolInformer.Lister(),
+				nodePoolSynced: nodePoolInformer.Informer().HasSynced,
+				client:         kubeClient,
+			}
+
+			newObj := thf.Filter(tt.responseObject, make(<-chan struct{}))
+			if !reflect.DeepEqual(newObj, tt.expectObject) {
+				t.Errorf("topologyHintsFilter expect: \n%#+v\nbut got: \n%#+v\n", tt.expectObject, newObj)
+			}
+
+			svc := thf.Filter(newService(tt.annotations), make(<-chan struct{})).(*corev1.Service)
+			enabled := tt.annotations[AnnotationTopologyHints] == AnnotationTopologyHintsValueNodePool
+			if (svc.Annotations[corev1.AnnotationTopologyAwareHints] == "auto") != enabled {
+				t.Errorf("expect topology aware hints of service enabled %v, but got annotations %v", enabled, svc.Annotations)
+			}
+
+			for _, action := range kubeClient.Actions() {
+				if action.GetVerb() == "get" && action.GetResource().Resource == "nodes" {
+					t.Errorf("expect node is read from informer, but got action %#+v", action)
+				}
+			}
+		})
+	}
+}
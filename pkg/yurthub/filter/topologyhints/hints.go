@@ -0,0 +1,186 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologyhints
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// spilloverPool is a neighbor NodePool which takes the traffic spilled over from the local NodePool.
+type spilloverPool struct {
+	name   string
+	weight int
+}
+
+// hintsConfig is the topology hints configuration of service.
+type hintsConfig struct {
+	// minLocalEndpoints is the local capacity of NodePool, the traffic is spilled over to
+	// neighbor NodePools when the serving endpoints in the NodePool are fewer than it.
+	minLocalEndpoints int
+	spillover         []spilloverPool
+}
+
+// parseHintsConfig parses the topology hints configuration from the annotations of service.
+func parseHintsConfig(svc *v1.Service) (*hintsConfig, error) {
+	if svc.Annotations[AnnotationTopologyHints] != AnnotationTopologyHintsValueNodePool {
+		return nil, nil
+	}
+
+	cfg := &hintsConfig{minLocalEndpoints: 1}
+	if v, ok := svc.Annotations[AnnotationTopologyMinLocalEndpoints]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s %q", AnnotationTopologyMinLocalEndpoints, v)
+		}
+		cfg.minLocalEndpoints = n
+	}
+
+	for _, item := range strings.Split(svc.Annotations[AnnotationTopologySpillover], ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		name, weight := item, 1
+		if idx := strings.Index(item, "="); idx >= 0 {
+			name = strings.TrimSpace(item[:idx])
+			w, err := strconv.Atoi(strings.TrimSpace(item[idx+1:]))
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight of nodepool %s in %s %q", name, AnnotationTopologySpillover, svc.Annotations[AnnotationTopologySpillover])
+			}
+			weight = w
+		}
+		if len(name) == 0 {
+			return nil, fmt.Errorf("invalid %s %q", AnnotationTopologySpillover, svc.Annotations[AnnotationTopologySpillover])
+		}
+		cfg.spillover = append(cfg.spillover, spilloverPool{name: name, weight: weight})
+	}
+	return cfg, nil
+}
+
+// endpointInfo is the topology information of an endpoint in EndpointSlice.
+type endpointInfo struct {
+	address string
+	node    string
+	serving bool
+}
+
+// selectEndpoints returns the indexes of endpoints which are hinted for the current node. all endpoints in
+// the local NodePool are selected, and if the serving ones are fewer than minLocalEndpoints, the shortfall is
+// taken from the serving endpoints of spillover NodePools in proportion to their weights. the endpoints of
+// each spillover NodePool are picked from an offset derived from the node name, so the spilled traffic of
+// different nodes is spread over the endpoints.
+func selectEndpoints(eps []endpointInfo, cfg *hintsConfig, localPool, nodeName string, nodeToPool map[string]string) map[int]bool {
+	selected := make(map[int]bool)
+	serving := 0
+	candidates := make(map[string][]int)
+	for i := range eps {
+		pool := nodeToPool[eps[i].node]
+		if len(pool) == 0 {
+			continue
+		}
+		if pool == localPool {
+			selected[i] = true
+			if eps[i].serving {
+				serving++
+			}
+		} else if eps[i].serving {
+			candidates[pool] = append(candidates[pool], i)
+		}
+	}
+
+	need := cfg.minLocalEndpoints - serving
+	if need <= 0 {
+		return selected
+	}
+
+	for pool := range candidates {
+		sort.Slice(candidates[pool], func(i, j int) bool {
+			return eps[candidates[pool][i]].address < eps[candidates[pool][j]].address
+		})
+	}
+	quotas := spilloverQuotas(need, cfg.spillover, candidates)
+	offset := hashOf(nodeName)
+	for _, p := range cfg.spillover {
+		idxs := candidates[p.name]
+		for i := 0; i < quotas[p.name]; i++ {
+			selected[idxs[(offset+i)%len(idxs)]] = true
+		}
+	}
+	return selected
+}
+
+// spilloverQuotas distributes the needed endpoints among spillover NodePools in proportion to their weights
+// by the largest remainder method, and the quota exceeding the endpoints of a NodePool is given to the others.
+func spilloverQuotas(need int, pools []spilloverPool, candidates map[string][]int) map[string]int {
+	quotas := make(map[string]int)
+	for need > 0 {
+		total := 0
+		available := make([]spilloverPool, 0, len(pools))
+		for _, p := range pools {
+			if len(candidates[p.name])-quotas[p.name] > 0 {
+				available = append(available, p)
+				total += p.weight
+			}
+		}
+		if len(available) == 0 {
+			break
+		}
+
+		type remainder struct {
+			name  string
+			value int
+		}
+		assigned := 0
+		remainders := make([]remainder, 0, len(available))
+		for _, p := range available {
+			n := need * p.weight / total
+			if room := len(candidates[p.name]) - quotas[p.name]; n > room {
+				n = room
+			}
+			quotas[p.name] += n
+			assigned += n
+			remainders = append(remainders, remainder{name: p.name, value: need * p.weight % total})
+		}
+		sort.SliceStable(remainders, func(i, j int) bool { return remainders[i].value > remainders[j].value })
+		for _, r := range remainders {
+			if assigned == need {
+				break
+			}
+			if len(candidates[r.name])-quotas[r.name] > 0 {
+				quotas[r.name]++
+				assigned++
+			}
+		}
+		if assigned == 0 {
+			break
+		}
+		need -= assigned
+	}
+	return quotas
+}
+
+func hashOf(s string) int {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return int(h.Sum32() & 0x7fffffff)
+}
@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -54,6 +55,13 @@ type HubMetrics struct {
 	yurtCoordinatorYurthubRoleCollector   *prometheus.GaugeVec
 	yurtCoordinatorHealthyStatusCollector *prometheus.GaugeVec
 	yurtCoordinatorReadyStatusCollector   *prometheus.GaugeVec
+	yurtCoordinatorStorageSizeCollector   *prometheus.GaugeVec
+	yurtCoordinatorLastSyncTimeCollector  *prometheus.GaugeVec
+	yurtCoordinatorConnectedHubsCollector *prometheus.GaugeVec
+	yurtCoordinatorDelegatedLeasesGauge   *prometheus.GaugeVec
+	yurtCoordinatorDelegateLeaseCounter   *prometheus.CounterVec
+	yurtCoordinatorInconsistencyCounter   *prometheus.CounterVec
+	yurtCoordinatorEvictionCounter        *prometheus.CounterVec
 }
 
 func newHubMetrics() *HubMetrics {
@@ -135,6 +143,62 @@ func newHubMetrics() *HubMetrics {
 			Help:      "yurt coordinator ready status 1: ready, 0: notReady",
 		},
 		[]string{})
+	yurtCoordinatorStorageSizeCollector := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_storage_size_bytes",
+			Help:      "size of yurt coordinator storage(unit: byte), it's only reported by leader yurthub",
+		},
+		[]string{})
+	yurtCoordinatorLastSyncTimeCollector := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_last_sync_timestamp_seconds",
+			Help:      "last time when pool-scoped resources in yurt coordinator are confirmed to be synced with cloud(unix timestamp)",
+		},
+		[]string{})
+	yurtCoordinatorConnectedHubsCollector := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_connected_yurthubs",
+			Help:      "number of yurthubs connected to yurt coordinator, it's only reported by leader yurthub",
+		},
+		[]string{})
+	yurtCoordinatorDelegatedLeasesGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_delegated_leases",
+			Help:      "number of node leases delegated to cloud currently, it's only reported by leader yurthub",
+		},
+		[]string{})
+	yurtCoordinatorDelegateLeaseCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_delegate_lease_counter",
+			Help:      "counter of node leases delegated to cloud by leader yurthub. result: success, failure",
+		},
+		[]string{"result"})
+	yurtCoordinatorInconsistencyCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_inconsistent_objects_counter",
+			Help:      "counter of objects in yurt coordinator which are inconsistent with cloud and repaired by leader yurthub. type: stale, outdated, missing",
+		},
+		[]string{"resource", "type"})
+	yurtCoordinatorEvictionCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "yurt_coordinator_evicted_objects_counter",
+			Help:      "counter of objects evicted from yurt coordinator by leader yurthub because the storage exceeds its quota.",
+		},
+		[]string{"resource"})
 	prometheus.MustRegister(serversHealthyCollector)
 	prometheus.MustRegister(inFlightRequestsCollector)
 	prometheus.MustRegister(inFlightRequestsGauge)
@@ -145,6 +209,13 @@ func newHubMetrics() *HubMetrics {
 	prometheus.MustRegister(yurtCoordinatorYurthubRoleCollector)
 	prometheus.MustRegister(yurtCoordinatorHealthyStatusCollector)
 	prometheus.MustRegister(yurtCoordinatorReadyStatusCollector)
+	prometheus.MustRegister(yurtCoordinatorStorageSizeCollector)
+	prometheus.MustRegister(yurtCoordinatorLastSyncTimeCollector)
+	prometheus.MustRegister(yurtCoordinatorConnectedHubsCollector)
+	prometheus.MustRegister(yurtCoordinatorDelegatedLeasesGauge)
+	prometheus.MustRegister(yurtCoordinatorDelegateLeaseCounter)
+	prometheus.MustRegister(yurtCoordinatorInconsistencyCounter)
+	prometheus.MustRegister(yurtCoordinatorEvictionCounter)
 	return &HubMetrics{
 		serversHealthyCollector:               serversHealthyCollector,
 		inFlightRequestsCollector:             inFlightRequestsCollector,
@@ -156,6 +227,13 @@ func newHubMetrics() *HubMetrics {
 		yurtCoordinatorHealthyStatusCollector: yurtCoordinatorHealthyStatusCollector,
 		yurtCoordinatorReadyStatusCollector:   yurtCoordinatorReadyStatusCollector,
 		yurtCoordinatorYurthubRoleCollector:   yurtCoordinatorYurthubRoleCollector,
+		yurtCoordinatorStorageSizeCollector:   yurtCoordinatorStorageSizeCollector,
+		yurtCoordinatorLastSyncTimeCollector:  yurtCoordinatorLastSyncTimeCollector,
+		yurtCoordinatorConnectedHubsCollector: yurtCoordinatorConnectedHubsCollector,
+		yurtCoordinatorDelegatedLeasesGauge:   yurtCoordinatorDelegatedLeasesGauge,
+		yurtCoordinatorDelegateLeaseCounter:   yurtCoordinatorDelegateLeaseCounter,
+		yurtCoordinatorInconsistencyCounter:   yurtCoordinatorInconsistencyCounter,
+		yurtCoordinatorEvictionCounter:        yurtCoordinatorEvictionCounter,
 	}
 }
 
@@ -184,6 +262,38 @@ func (hm *HubMetrics) ObserveYurtCoordinatorHealthyStatus(status int32) {
 	hm.yurtCoordinatorHealthyStatusCollector.WithLabelValues().Set(float64(status))
 }
 
+func (hm *HubMetrics) ObserveYurtCoordinatorStorageSize(size int64) {
+	hm.yurtCoordinatorStorageSizeCollector.WithLabelValues().Set(float64(size))
+}
+
+func (hm *HubMetrics) ObserveYurtCoordinatorLastSyncTime(t time.Time) {
+	hm.yurtCoordinatorLastSyncTimeCollector.WithLabelValues().Set(float64(t.Unix()))
+}
+
+func (hm *HubMetrics) ObserveYurtCoordinatorConnectedYurtHubs(cnt int32) {
+	hm.yurtCoordinatorConnectedHubsCollector.WithLabelValues().Set(float64(cnt))
+}
+
+func (hm *HubMetrics) ObserveYurtCoordinatorDelegatedLeases(cnt int32) {
+	hm.yurtCoordinatorDelegatedLeasesGauge.WithLabelValues().Set(float64(cnt))
+}
+
+func (hm *HubMetrics) IncYurtCoordinatorDelegateLeaseCounter(result string) {
+	hm.yurtCoordinatorDelegateLeaseCounter.WithLabelValues(result).Inc()
+}
+
+func (hm *HubMetrics) AddYurtCoordinatorInconsistentObjects(resource, inconsistencyType string, cnt int) {
+	if cnt > 0 {
+		hm.yurtCoordinatorInconsistencyCounter.WithLabelValues(resource, inconsistencyType).Add(float64(cnt))
+	}
+}
+
+func (hm *HubMetrics) AddYurtCoordinatorEvictedObjects(resource string, cnt int) {
+	if cnt > 0 {
+		hm.yurtCoordinatorEvictionCounter.WithLabelValues(resource).Add(float64(cnt))
+	}
+}
+
 func (hm *HubMetrics) IncInFlightRequests(verb, resource, subresource, client string) {
 	hm.inFlightRequestsCollector.WithLabelValues(verb, resource, subresource, client).Inc()
 	hm.inFlightRequestsGauge.Inc()
@@ -18,6 +18,7 @@ package otaupdate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -28,6 +29,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
 	"github.com/openyurtio/openyurt/pkg/yurthub/cachemanager"
 	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/rest"
 	upgrade "github.com/openyurtio/openyurt/pkg/yurthub/otaupdate/upgrader"
@@ -130,6 +132,10 @@ func UpdatePod(clientset kubernetes.Interface, nodeName string) http.Handler {
 
 		if err := upgrader.Apply(); err != nil {
 			klog.Errorf("Apply update failed, %v", err)
+			if errors.Is(err, upgradeutil.ErrInvalidSignature) {
+				util.WriteErr(w, "Manifest signature verification failed", http.StatusForbidden)
+				return
+			}
 			// Pod update failed with error
 			util.WriteErr(w, "Apply update failed", http.StatusInternalServerError)
 			return
@@ -140,6 +146,73 @@ func UpdatePod(clientset kubernetes.Interface, nodeName string) http.Handler {
 	})
 }
 
+// StagePod stages the upgrade of a specific static pod(namespace/podname), the latest manifest is
+// prepared on the node and the upgrade takes effect only after it is confirmed by ConfirmPod.
+func StagePod(clientset kubernetes.Interface, nodeName string) http.Handler {
+	return staticPodUpgradeHandler(clientset, nodeName, "stage", (*upgrade.StaticPodUpgrader).Stage)
+}
+
+// ConfirmPod confirms the staged upgrade of a specific static pod(namespace/podname).
+func ConfirmPod(clientset kubernetes.Interface, nodeName string) http.Handler {
+	return staticPodUpgradeHandler(clientset, nodeName, "confirm", (*upgrade.StaticPodUpgrader).Confirm)
+}
+
+// CancelPod cancels the staged upgrade of a specific static pod(namespace/podname) before it is confirmed.
+func CancelPod(clientset kubernetes.Interface, nodeName string) http.Handler {
+	return staticPodUpgradeHandler(clientset, nodeName, "cancel", (*upgrade.StaticPodUpgrader).Cancel)
+}
+
+// staticPodUpgradeHandler runs the operation of StaticPodUpgrader for the static pod in request
+func staticPodUpgradeHandler(clientset kubernetes.Interface, nodeName, action string,
+	operate func(*upgrade.StaticPodUpgrader) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := mux.Vars(r)
+		namespace := params["ns"]
+		podName := params["podname"]
+
+		pod, ok := preCheck(clientset, namespace, podName, nodeName)
+		// Pod update is not allowed
+		if !ok {
+			util.WriteErr(w, "Pod is not-updatable", http.StatusForbidden)
+			return
+		}
+		if kind := pod.GetOwnerReferences()[0].Kind; kind != StaticPod {
+			util.WriteErr(w, fmt.Sprintf("Not support %s upgrade of pod type %v", action, kind), http.StatusBadRequest)
+			return
+		}
+
+		ok, staticName, err := upgrade.PreCheck(podName, nodeName, namespace, clientset)
+		if err != nil {
+			klog.Errorf("Static pod pre-check failed, %v", err)
+			util.WriteErr(w, "Static pod pre-check failed", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			util.WriteErr(w, "Configmap for static pod does not exist", http.StatusForbidden)
+			return
+		}
+
+		upgrader := &upgrade.StaticPodUpgrader{Interface: clientset,
+			NamespacedName: types.NamespacedName{Namespace: namespace, Name: podName}, StaticName: staticName}
+		if err := operate(upgrader); err != nil {
+			if errors.Is(err, upgrade.ErrUpgradeNotStaged) {
+				util.WriteErr(w, "Upgrade of pod is not staged", http.StatusConflict)
+				return
+			}
+			if errors.Is(err, upgradeutil.ErrInvalidSignature) {
+				klog.Errorf("Apply %s of upgrade failed, %v", action, err)
+				util.WriteErr(w, "Manifest signature verification failed", http.StatusForbidden)
+				return
+			}
+			klog.Errorf("Apply %s of upgrade failed, %v", action, err)
+			util.WriteErr(w, fmt.Sprintf("Apply %s of upgrade failed", action), http.StatusInternalServerError)
+			return
+		}
+
+		util.WriteJSONResponse(w, []byte(fmt.Sprintf("Apply %s of upgrade for pod %v/%v", action, namespace, podName)))
+	})
+}
+
 // preCheck will check the necessary requirements before apply upgrade
 // 1. target pod has not been deleted yet
 // 2. target pod belongs to current node
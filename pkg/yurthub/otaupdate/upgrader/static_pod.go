@@ -18,11 +18,15 @@ package upgrader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,6 +36,7 @@ import (
 	upgrade "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade"
 	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
 	"github.com/openyurtio/openyurt/pkg/yurthub/otaupdate/util"
+	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
 	spctrlutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
 )
 
@@ -39,6 +44,9 @@ const OTA = "OTA"
 
 var (
 	DefaultUpgradePath = "/tmp/manifests"
+	// DefaultPublicKeyPath is the public key to verify the manifest signature, the manifest is not verified
+	// if the public key doesn't exist
+	DefaultPublicKeyPath = upgradeutil.DefaultManifestPublicKeyPath
 )
 
 type StaticPodUpgrader struct {
@@ -48,19 +56,27 @@ type StaticPodUpgrader struct {
 	StaticName string
 }
 
+// ErrUpgradeNotStaged is returned when confirming or cancelling an upgrade which is not staged.
+var ErrUpgradeNotStaged = errors.New("upgrade of static pod is not staged")
+
+// Apply stages the latest manifest and confirms the upgrade at once.
 func (s *StaticPodUpgrader) Apply() error {
-	cm, err := s.CoreV1().ConfigMaps(s.Namespace).Get(context.TODO(),
-		spctrlutil.WithConfigMapPrefix(s.StaticName), metav1.GetOptions{})
-	if err != nil {
+	if err := s.Stage(); err != nil {
 		return err
 	}
-	var manifest, data string
-	for k, v := range cm.Data {
-		manifest = k
-		data = v
+	return s.Confirm()
+}
+
+// Stage writes the latest manifests into the upgrade path without replacing the running ones,
+// the upgrade takes effect only after it is confirmed.
+func (s *StaticPodUpgrader) Stage() error {
+	cm, manifest, data, err := s.latestManifest()
+	if err != nil {
+		return err
 	}
-	if len(data) == 0 {
-		return fmt.Errorf("empty manifest in configmap %v", spctrlutil.WithConfigMapPrefix(s.StaticName))
+	bundle, err := upgradeutil.DecodeBundle(cm.Annotations[spctrlutil.StaticPodBundleAnnotation])
+	if err != nil {
+		return err
 	}
 
 	// Make sure upgrade dir exist
@@ -70,14 +86,216 @@ func (s *StaticPodUpgrader) Apply() error {
 		}
 	}
 
+	// All manifests of the bundle are verified and rendered before any of them is staged
+	manifests := map[string]string{manifest: data}
+	signatures := map[string]string{manifest: cm.Annotations[spctrlutil.ManifestSignatureAnnotation]}
+	for _, m := range bundle {
+		if len(cm.Data[m.Manifest]) == 0 {
+			return fmt.Errorf("empty bundled manifest %s in configmap %v", m.Manifest, cm.Name)
+		}
+		manifests[m.Manifest] = cm.Data[m.Manifest]
+		signatures[m.Manifest] = m.Signature
+	}
+	for name, data := range manifests {
+		if err := verifyManifest(data, signatures[name]); err != nil {
+			return err
+		}
+		if manifests[name], err = s.renderManifest(data); err != nil {
+			return err
+		}
+	}
+
+	for name, data := range manifests {
+		upgradeManifestPath := filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(name))
+		if err := genUpgradeManifest(upgradeManifestPath, data); err != nil {
+			return err
+		}
+	}
+	klog.V(5).Info("Generate upgrade manifest")
+
+	return s.setUpgradeState(corev1.ConditionTrue, spctrlutil.OTAUpgradeStagedReason, cm.Annotations[spctrlutil.StaticPodHashAnnotation])
+}
+
+// Confirm replaces the running manifests with the staged ones, and kubelet will upgrade the static pods.
+func (s *StaticPodUpgrader) Confirm() error {
+	cm, manifest, _, err := s.latestManifest()
+	if err != nil {
+		return err
+	}
+	bundle, err := upgradeutil.DecodeBundle(cm.Annotations[spctrlutil.StaticPodBundleAnnotation])
+	if err != nil {
+		return err
+	}
+
+	upgradeManifestPaths := []string{filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(manifest))}
+	for _, m := range bundle {
+		upgradeManifestPaths = append(upgradeManifestPaths, filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(m.Manifest)))
+	}
+	for _, path := range upgradeManifestPaths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return ErrUpgradeNotStaged
+		}
+	}
+
+	healthGate, err := upgradeutil.DecodeHealthGate(cm.Annotations[spctrlutil.HealthGateAnnotation])
+	if err != nil {
+		return err
+	}
+
+	hash := cm.Annotations[spctrlutil.StaticPodHashAnnotation]
+	ctrl := upgrade.New(s.Name, s.Namespace, manifest, OTA).
+		WithBundle(bundle, strings.TrimPrefix(s.Name, s.StaticName+"-")).
+		WithVerification(hash, rollbackWindow(cm)).
+		WithHealthGate(healthGate)
+	if err := ctrl.Upgrade(); err != nil {
+		return err
+	}
+	for _, path := range upgradeManifestPaths {
+		if err := os.Remove(path); err != nil {
+			klog.Warningf("Fail to remove staged manifest %s, %v", path, err)
+		}
+	}
+
+	if err := s.setUpgradeState(corev1.ConditionTrue, spctrlutil.OTAUpgradeConfirmedReason, hash); err != nil {
+		return err
+	}
+	if len(hash) != 0 {
+		go s.verifyOrRollback(ctrl, hash)
+	}
+	return nil
+}
+
+// verifyOrRollback waits for the upgraded static pod to be ready and pass the health gate within the
+// rollback window, and reports whether it's verified or the previous manifest is restored.
+func (s *StaticPodUpgrader) verifyOrRollback(ctrl *upgrade.Controller, hash string) {
+	err := ctrl.VerifyOrRollback()
+	if err == nil {
+		klog.Infof("Static pod %s/%s is upgraded to %s", s.Namespace, s.Name, hash)
+		if err := s.setUpgradeState(corev1.ConditionTrue, spctrlutil.OTAUpgradeVerifiedReason, hash); err != nil {
+			klog.Errorf("Fail to report verification of static pod %s/%s, %v", s.Namespace, s.Name, err)
+		}
+		return
+	}
+	if !errors.Is(err, upgrade.ErrRolledBack) {
+		klog.Errorf("Fail to upgrade static pod %s/%s, %v", s.Namespace, s.Name, err)
+		return
+	}
+
+	klog.Warningf("Static pod %s/%s is rolled back, %v", s.Namespace, s.Name, err)
+	if err := s.setUpgradeState(corev1.ConditionTrue, spctrlutil.OTAUpgradeRolledBackReason, hash); err != nil {
+		klog.Errorf("Fail to report rollback of static pod %s/%s, %v", s.Namespace, s.Name, err)
+	}
+}
+
+// rollbackWindow returns the rollback window recorded on the configmap by YurtStaticSet controller
+func rollbackWindow(cm *corev1.ConfigMap) time.Duration {
+	if window, err := time.ParseDuration(cm.Annotations[spctrlutil.RollbackWindowAnnotation]); err == nil && window > 0 {
+		return window
+	}
+	return upgrade.DefaultStaticPodRunningCheckTimeout
+}
+
+// Cancel removes the staged manifests before the upgrade is confirmed.
+func (s *StaticPodUpgrader) Cancel() error {
+	cm, manifest, _, err := s.latestManifest()
+	if err != nil {
+		return err
+	}
+
 	upgradeManifestPath := filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(manifest))
-	if err := genUpgradeManifest(upgradeManifestPath, data); err != nil {
+	if err := os.Remove(upgradeManifestPath); err != nil {
+		if os.IsNotExist(err) {
+			return ErrUpgradeNotStaged
+		}
 		return err
 	}
-	klog.V(5).Info("Generate upgrade manifest")
+	// The staged bundled manifests are removed as well, they may not exist if staging failed halfway
+	bundle, err := upgradeutil.DecodeBundle(cm.Annotations[spctrlutil.StaticPodBundleAnnotation])
+	if err != nil {
+		return err
+	}
+	for _, m := range bundle {
+		path := filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(m.Manifest))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return s.setUpgradeState(corev1.ConditionFalse, spctrlutil.OTAUpgradeCancelledReason, "")
+}
+
+// latestManifest returns the configmap of YurtStaticSet, and the main manifest name and data in it.
+func (s *StaticPodUpgrader) latestManifest() (*corev1.ConfigMap, string, string, error) {
+	cm, err := s.CoreV1().ConfigMaps(s.Namespace).Get(context.TODO(),
+		spctrlutil.WithConfigMapPrefix(s.StaticName), metav1.GetOptions{})
+	if err != nil {
+		return nil, "", "", err
+	}
+	bundle, err := upgradeutil.DecodeBundle(cm.Annotations[spctrlutil.StaticPodBundleAnnotation])
+	if err != nil {
+		return nil, "", "", err
+	}
+	bundled := make(map[string]bool, len(bundle))
+	for _, m := range bundle {
+		bundled[m.Manifest] = true
+	}
+	var manifest, data string
+	for k, v := range cm.Data {
+		if bundled[k] {
+			continue
+		}
+		manifest = k
+		data = v
+	}
+	if len(data) == 0 {
+		return nil, "", "", fmt.Errorf("empty manifest in configmap %v", spctrlutil.WithConfigMapPrefix(s.StaticName))
+	}
+	return cm, manifest, data, nil
+}
+
+// verifyManifest verifies the manifest with its signature in configmap if the public key exists on node.
+func verifyManifest(data, signature string) error {
+	key, err := upgradeutil.LoadPublicKey(DefaultPublicKeyPath)
+	if err != nil || key == nil {
+		return err
+	}
+	return upgradeutil.VerifyManifest(key, []byte(data), signature)
+}
+
+// renderManifest renders the placeholders in manifest with the values of node which runs the static pod.
+func (s *StaticPodUpgrader) renderManifest(data string) (string, error) {
+	if !upgradeutil.HasPlaceholders(data) {
+		return data, nil
+	}
+	pod, err := s.CoreV1().Pods(s.Namespace).Get(context.TODO(), s.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	node, err := s.CoreV1().Nodes().Get(context.TODO(), pod.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return upgradeutil.RenderManifest(data, upgradeutil.TemplateValuesFromNode(node))
+}
 
-	ctrl := upgrade.New(s.Name, s.Namespace, manifest, OTA)
-	return ctrl.Upgrade()
+// setUpgradeState reports the upgrade state by the condition PodOTAUpgrade of static pod,
+// and YurtStaticSet collects it into the status.
+func (s *StaticPodUpgrader) setUpgradeState(status corev1.ConditionStatus, reason, hash string) error {
+	pod, err := s.CoreV1().Pods(s.Namespace).Get(context.TODO(), s.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	cond := &corev1.PodCondition{
+		Type:    spctrlutil.PodOTAUpgrade,
+		Status:  status,
+		Reason:  reason,
+		Message: hash,
+	}
+	if changed := podutil.UpdatePodCondition(&pod.Status, cond); !changed {
+		return nil
+	}
+	_, err = s.CoreV1().Pods(s.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{})
+	return err
 }
 
 func PreCheck(name, nodename, namespace string, c kubernetes.Interface) (bool, string, error) {
@@ -17,6 +17,8 @@ limitations under the License.
 package upgrader
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -73,6 +75,112 @@ spec:
 	})
 }
 
+func TestStaticPodUpgrader_StageAndCancel(t *testing.T) {
+	upgrade.DefaultUpgradePath = t.TempDir()
+	upgrade.DefaultManifestPath = t.TempDir()
+	DefaultUpgradePath = upgrade.DefaultUpgradePath
+	_, _ = os.Create(filepath.Join(upgrade.DefaultManifestPath, upgradeutil.WithYamlSuffix("nginx")))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   metav1.NamespaceDefault,
+			Name:        spctrlutil.WithConfigMapPrefix("nginx"),
+			Annotations: map[string]string{spctrlutil.StaticPodHashAnnotation: "hash1"},
+		},
+		Data: map[string]string{"nginx": "apiVersion: v1\nkind: Pod\n"},
+	}
+	clientset := fake.NewSimpleClientset(util.NewPodWithCondition("nginx-node", "Node", corev1.ConditionTrue), cm)
+	upgrader := StaticPodUpgrader{
+		Interface:      clientset,
+		NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "nginx-node"},
+		StaticName:     "nginx",
+	}
+	expectState := func(state, hash string) {
+		pod, err := clientset.CoreV1().Pods(metav1.NamespaceDefault).Get(context.TODO(), "nginx-node", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Fail to get pod, %v", err)
+		}
+		if s, h := spctrlutil.GetPodOTAUpgradeState(pod); s != state || h != hash {
+			t.Fatalf("Expect upgrade state %q with hash %q, but got %q with %q", state, hash, s, h)
+		}
+	}
+
+	if err := upgrader.Confirm(); !errors.Is(err, ErrUpgradeNotStaged) {
+		t.Fatalf("Expect confirm without staged upgrade fail, but got %v", err)
+	}
+
+	if err := upgrader.Stage(); err != nil {
+		t.Fatalf("Fail to stage upgrade, %v", err)
+	}
+	expectState(spctrlutil.OTAUpgradeStagedReason, "hash1")
+
+	if err := upgrader.Cancel(); err != nil {
+		t.Fatalf("Fail to cancel upgrade, %v", err)
+	}
+	expectState("", "")
+	if err := upgrader.Cancel(); !errors.Is(err, ErrUpgradeNotStaged) {
+		t.Fatalf("Expect cancel without staged upgrade fail, but got %v", err)
+	}
+
+	if err := upgrader.Stage(); err != nil {
+		t.Fatalf("Fail to stage upgrade, %v", err)
+	}
+	if err := upgrader.Confirm(); err != nil {
+		t.Fatalf("Fail to confirm upgrade, %v", err)
+	}
+	expectState(spctrlutil.OTAUpgradeConfirmedReason, "hash1")
+}
+
+func TestStaticPodUpgrader_Bundle(t *testing.T) {
+	upgrade.DefaultUpgradePath = t.TempDir()
+	upgrade.DefaultManifestPath = t.TempDir()
+	DefaultUpgradePath = upgrade.DefaultUpgradePath
+	_, _ = os.Create(filepath.Join(upgrade.DefaultManifestPath, upgradeutil.WithYamlSuffix("nginx")))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      spctrlutil.WithConfigMapPrefix("nginx"),
+			Annotations: map[string]string{
+				spctrlutil.StaticPodHashAnnotation:   "hash1",
+				spctrlutil.StaticPodBundleAnnotation: `[{"manifest":"mirror","name":"nginx-mirror"}]`,
+			},
+		},
+		Data: map[string]string{
+			"nginx":  "apiVersion: v1\nkind: Pod\nmetadata:\n  name: nginx\n",
+			"mirror": "apiVersion: v1\nkind: Pod\nmetadata:\n  name: nginx-mirror\n",
+		},
+	}
+	clientset := fake.NewSimpleClientset(util.NewPodWithCondition("nginx-node", "Node", corev1.ConditionTrue), cm)
+	upgrader := StaticPodUpgrader{
+		Interface:      clientset,
+		NamespacedName: types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "nginx-node"},
+		StaticName:     "nginx",
+	}
+
+	if err := upgrader.Stage(); err != nil {
+		t.Fatalf("Fail to stage upgrade, %v", err)
+	}
+	for _, manifest := range []string{"nginx", "mirror"} {
+		if _, err := os.Stat(filepath.Join(DefaultUpgradePath, upgradeutil.WithUpgradeSuffix(manifest))); err != nil {
+			t.Fatalf("Expect manifest %s staged, %v", manifest, err)
+		}
+	}
+
+	if err := upgrader.Confirm(); err != nil {
+		t.Fatalf("Fail to confirm upgrade, %v", err)
+	}
+	for manifest, data := range cm.Data {
+		content, err := os.ReadFile(filepath.Join(upgrade.DefaultManifestPath, upgradeutil.WithYamlSuffix(manifest)))
+		if err != nil {
+			t.Fatalf("Fail to read manifest %s, %v", manifest, err)
+		}
+		if string(content) != data {
+			t.Errorf("Expect manifest %s upgraded to %q, but got %q", manifest, data, content)
+		}
+	}
+}
+
 func Test_genUpgradeManifest(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, rand.String(10))
@@ -57,6 +57,7 @@ type yurtReverseProxy struct {
 	poolProxy                     http.Handler
 	maxRequestsInFlight           int
 	tenantMgr                     tenant.Interface
+	watchDrainer                  *util.WatchDrainer
 	isCoordinatorReady            func() bool
 	workingMode                   hubutil.WorkingMode
 	enableYurtCoordinator         bool
@@ -70,6 +71,7 @@ func NewYurtReverseProxyHandler(
 	transportMgr transport.Interface,
 	cloudHealthChecker healthchecker.MultipleBackendsHealthChecker,
 	tenantMgr tenant.Interface,
+	watchDrainer *util.WatchDrainer,
 	coordinatorGetter func() yurtcoordinator.Coordinator,
 	coordinatorTransportMgrGetter func() transport.Interface,
 	coordinatorHealthCheckerGetter func() healthchecker.HealthChecker,
@@ -147,6 +149,7 @@ func NewYurtReverseProxyHandler(
 		isCoordinatorReady:            isCoordinatorReady,
 		enableYurtCoordinator:         yurtHubCfg.EnableCoordinator,
 		tenantMgr:                     tenantMgr,
+		watchDrainer:                  watchDrainer,
 		workingMode:                   yurtHubCfg.WorkingMode,
 	}
 
@@ -166,6 +169,9 @@ func (p *yurtReverseProxy) buildHandlerChain(handler http.Handler) http.Handler
 	handler = util.WithRequestTraceFull(handler)
 	handler = util.WithMaxInFlightLimit(handler, p.maxRequestsInFlight)
 	handler = util.WithRequestClientComponent(handler)
+	if p.watchDrainer != nil {
+		handler = p.watchDrainer.WithWatchDrain(handler)
+	}
 
 	if p.enableYurtCoordinator {
 		handler = util.WithIfPoolScopedResource(handler)
@@ -0,0 +1,356 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/util"
+)
+
+const (
+	// drainingRetryAfterSeconds is the retry hint returned to clients
+	// whose requests arrive while yurthub is shutting down.
+	drainingRetryAfterSeconds = 5
+)
+
+var errWatchDrained = errors.New("watch is drained because yurthub is shutting down")
+
+// WatchDrainer keeps track of in-flight watch requests, and closes them
+// gracefully when yurthub is shutting down. A watch stream that is ended
+// normally makes clients(like reflectors of client-go) re-watch from the
+// last resourceVersion they have received instead of relisting all resources.
+type WatchDrainer struct {
+	sync.Mutex
+	draining bool
+	nextID   int
+	watches  map[int]*drainResponseWriter
+	doneCh   chan struct{}
+}
+
+// NewWatchDrainer creates a *WatchDrainer
+func NewWatchDrainer() *WatchDrainer {
+	return &WatchDrainer{
+		watches: make(map[int]*drainResponseWriter),
+	}
+}
+
+// WithWatchDrain registers watch requests into WatchDrainer so they can be closed
+// when yurthub is shutting down, and rejects the incoming resource requests with
+// a retry hint when the drain has been started.
+func (d *WatchDrainer) WithWatchDrain(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info, ok := apirequest.RequestInfoFrom(req.Context())
+		if !ok || !info.IsResourceRequest {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		isWatch := info.Verb == "watch"
+		id, dw, ok := d.register(w, req, isWatch)
+		if !ok {
+			klog.Infof("reject request %s because yurthub is shutting down", util.ReqString(req))
+			writeDrainingErr(w, req)
+			return
+		}
+		if !isWatch {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		defer d.unregister(id)
+		defer func() {
+			// the reverse proxy aborts the handler when the response can't be copied to the drained writer,
+			// the watch response is ended normally instead, so clients will not see a broken stream.
+			if r := recover(); r != nil && (r != http.ErrAbortHandler || !dw.isDrained()) {
+				panic(r)
+			}
+			if dw.isDrained() {
+				klog.Infof("watch request %s is drained", util.ReqString(req))
+				if !dw.wroteHeader {
+					writeDrainingErr(dw.ResponseWriter, req)
+				}
+			}
+		}()
+		handler.ServeHTTP(dw, req.WithContext(dw.ctx))
+	})
+}
+
+// Drain stops accepting new resource requests and closes all in-flight watch requests,
+// then waits until all of watch handlers have exited or the context is done.
+func (d *WatchDrainer) Drain(ctx context.Context) {
+	d.Lock()
+	if d.draining {
+		d.Unlock()
+		return
+	}
+	d.draining = true
+	d.doneCh = make(chan struct{})
+	if len(d.watches) == 0 {
+		close(d.doneCh)
+	}
+	klog.Infof("start to drain %d in-flight watch requests", len(d.watches))
+	for _, dw := range d.watches {
+		dw.drain()
+	}
+	doneCh := d.doneCh
+	d.Unlock()
+
+	select {
+	case <-doneCh:
+		klog.Infof("all of in-flight watch requests have been drained")
+	case <-ctx.Done():
+		d.Lock()
+		klog.Warningf("%d watch requests are not drained before timeout, %v", len(d.watches), ctx.Err())
+		for _, dw := range d.watches {
+			dw.cancel()
+		}
+		d.Unlock()
+	}
+}
+
+func (d *WatchDrainer) register(w http.ResponseWriter, req *http.Request, isWatch bool) (int, *drainResponseWriter, bool) {
+	d.Lock()
+	defer d.Unlock()
+	if d.draining {
+		return 0, nil, false
+	}
+
+	if !isWatch {
+		return 0, nil, true
+	}
+
+	dw := newDrainResponseWriter(w, req)
+	d.nextID++
+	d.watches[d.nextID] = dw
+	return d.nextID, dw, true
+}
+
+func (d *WatchDrainer) unregister(id int) {
+	d.Lock()
+	defer d.Unlock()
+	if dw, ok := d.watches[id]; ok {
+		dw.cancel()
+		delete(d.watches, id)
+	}
+
+	if d.draining && len(d.watches) == 0 {
+		select {
+		case <-d.doneCh:
+		default:
+			close(d.doneCh)
+		}
+	}
+}
+
+func writeDrainingErr(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Retry-After", strconv.Itoa(drainingRetryAfterSeconds))
+	Err(apierrors.NewTooManyRequests("yurthub is shutting down, please try again later.", drainingRetryAfterSeconds), w, req)
+}
+
+// drainResponseWriter wraps the response writer of a watch request. when the watch is drained,
+// the response is ended at the boundary of watch events, so the clients receive complete events
+// and a normally closed stream. a watch which has not responded is rejected with a retry hint.
+type drainResponseWriter struct {
+	http.ResponseWriter
+	sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+	framer      *watchFramer
+	wroteHeader bool
+	draining    bool
+	drained     bool
+}
+
+func newDrainResponseWriter(w http.ResponseWriter, req *http.Request) *drainResponseWriter {
+	ctx, cancel := context.WithCancel(req.Context())
+	return &drainResponseWriter{
+		ResponseWriter: w,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+func (dw *drainResponseWriter) WriteHeader(statusCode int) {
+	dw.Lock()
+	defer dw.Unlock()
+	if dw.drained || dw.wroteHeader {
+		return
+	}
+	dw.wroteHeader = true
+	dw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (dw *drainResponseWriter) Write(p []byte) (int, error) {
+	dw.Lock()
+	defer dw.Unlock()
+	if dw.drained {
+		return 0, errWatchDrained
+	}
+	dw.wroteHeader = true
+	if dw.framer == nil {
+		dw.framer = newWatchFramer(dw.Header().Get("Content-Type"))
+	}
+
+	if !dw.draining {
+		dw.framer.consume(p, false)
+		return dw.ResponseWriter.Write(p)
+	}
+
+	// only the rest of current event is written when the watch is draining
+	n := dw.framer.consume(p, true)
+	written, err := dw.ResponseWriter.Write(p[:n])
+	if dw.framer.atBoundary() {
+		dw.finish()
+	}
+	if err != nil {
+		return written, err
+	}
+	if written < len(p) {
+		return written, errWatchDrained
+	}
+	return written, nil
+}
+
+func (dw *drainResponseWriter) Flush() {
+	if flusher, ok := dw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (dw *drainResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := dw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer is not a http.Hijacker")
+	}
+	return hijacker.Hijack()
+}
+
+func (dw *drainResponseWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
+
+// drain ends the watch immediately if no event is partially written,
+// otherwise the watch is ended after the current event is written.
+func (dw *drainResponseWriter) drain() {
+	dw.Lock()
+	defer dw.Unlock()
+	dw.draining = true
+	if dw.framer == nil || dw.framer.atBoundary() {
+		dw.finish()
+	}
+}
+
+func (dw *drainResponseWriter) finish() {
+	dw.drained = true
+	dw.cancel()
+}
+
+func (dw *drainResponseWriter) isDrained() bool {
+	dw.Lock()
+	defer dw.Unlock()
+	return dw.drained
+}
+
+// watchFramer tracks the boundary of watch events in the response stream, events are
+// delimited by newline in json streams and prefixed by length in protobuf streams.
+type watchFramer struct {
+	lengthPrefixed bool
+	// partial is true when a json event is partially written
+	partial bool
+	// header collects the length prefix of current protobuf frame
+	header []byte
+	// remaining is the unwritten bytes of current protobuf frame
+	remaining int
+}
+
+func newWatchFramer(contentType string) *watchFramer {
+	return &watchFramer{
+		lengthPrefixed: strings.Contains(contentType, "protobuf"),
+	}
+}
+
+func (f *watchFramer) atBoundary() bool {
+	if f.lengthPrefixed {
+		return len(f.header) == 0 && f.remaining == 0
+	}
+	return !f.partial
+}
+
+// consume advances the framer by p, and returns the number of consumed bytes. when stopAtBoundary
+// is true, the consumption is stopped at the first boundary of events.
+func (f *watchFramer) consume(p []byte, stopAtBoundary bool) int {
+	if stopAtBoundary && f.atBoundary() {
+		return 0
+	}
+
+	if !f.lengthPrefixed {
+		if !stopAtBoundary {
+			if len(p) != 0 {
+				f.partial = p[len(p)-1] != '\n'
+			}
+			return len(p)
+		}
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			f.partial = false
+			return i + 1
+		}
+		return len(p)
+	}
+
+	i := 0
+	for i < len(p) {
+		if f.remaining == 0 {
+			take := 4 - len(f.header)
+			if take > len(p)-i {
+				take = len(p) - i
+			}
+			f.header = append(f.header, p[i:i+take]...)
+			i += take
+			if len(f.header) < 4 {
+				break
+			}
+			f.remaining = int(binary.BigEndian.Uint32(f.header))
+			f.header = nil
+		} else {
+			take := f.remaining
+			if take > len(p)-i {
+				take = len(p) - i
+			}
+			f.remaining -= take
+			i += take
+		}
+
+		if stopAtBoundary && f.atBoundary() {
+			break
+		}
+	}
+	return i
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apiserver/pkg/endpoints/filters"
+)
+
+func TestWatchDrainer(t *testing.T) {
+	resolver := newTestRequestInfoResolver()
+	drainer := NewWatchDrainer()
+
+	watchStarted := make(chan struct{})
+	watchExited := make(chan struct{})
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("watch") == "true" {
+			close(watchStarted)
+			<-req.Context().Done()
+			close(watchExited)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler = drainer.WithWatchDrain(handler)
+	handler = filters.WithRequestInfo(handler, resolver)
+
+	watchReq, _ := http.NewRequest("GET", "/api/v1/pods?watch=true", nil)
+	watchReq.RemoteAddr = "127.0.0.1"
+	go handler.ServeHTTP(httptest.NewRecorder(), watchReq)
+	<-watchStarted
+
+	listReq, _ := http.NewRequest("GET", "/api/v1/pods", nil)
+	listReq.RemoteAddr = "127.0.0.1"
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, listReq)
+	if resp.Code != http.StatusOK {
+		t.Errorf("expect status code %d before drain, but got %d", http.StatusOK, resp.Code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drainer.Drain(ctx)
+
+	select {
+	case <-watchExited:
+	default:
+		t.Errorf("expect watch request is closed after drain")
+	}
+
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, listReq)
+	if resp.Code != http.StatusTooManyRequests {
+		t.Errorf("expect status code %d after drain, but got %d", http.StatusTooManyRequests, resp.Code)
+	}
+	if resp.Header().Get("Retry-After") == "" {
+		t.Errorf("expect Retry-After header is set after drain")
+	}
+}
+
+func TestWatchDrainerEndsAtEventBoundary(t *testing.T) {
+	resolver := newTestRequestInfoResolver()
+	drainer := NewWatchDrainer()
+
+	firstHalfWritten := make(chan struct{})
+	drained := make(chan struct{})
+	var writeErr error
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type":"ADDED",`))
+		close(firstHalfWritten)
+		<-drained
+		// the rest of current event is written, and the next event is dropped.
+		if _, writeErr = w.Write([]byte("\"object\":{}}\n{\"type\":\"MODIFIED\"")); writeErr != nil {
+			// the reverse proxy aborts the handler when the response can't be copied.
+			panic(http.ErrAbortHandler)
+		}
+	})
+	handler = drainer.WithWatchDrain(handler)
+	handler = filters.WithRequestInfo(handler, resolver)
+
+	watchReq, _ := http.NewRequest("GET", "/api/v1/pods?watch=true", nil)
+	watchReq.RemoteAddr = "127.0.0.1"
+	resp := httptest.NewRecorder()
+	handlerExited := make(chan struct{})
+	go func() {
+		defer close(handlerExited)
+		handler.ServeHTTP(resp, watchReq)
+	}()
+	<-firstHalfWritten
+
+	go func() {
+		// wait for the drainer to mark the watch as draining before the handler continues.
+		time.Sleep(100 * time.Millisecond)
+		close(drained)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drainer.Drain(ctx)
+	<-handlerExited
+
+	if writeErr != errWatchDrained {
+		t.Errorf("expect write error %v, but got %v", errWatchDrained, writeErr)
+	}
+	if got := resp.Body.String(); got != "{\"type\":\"ADDED\",\"object\":{}}\n" {
+		t.Errorf("expect the watch is ended after the complete event, but got %q", got)
+	}
+	if resp.Code != http.StatusOK {
+		t.Errorf("expect status code %d, but got %d", http.StatusOK, resp.Code)
+	}
+}
+
+func TestWatchFramer(t *testing.T) {
+	frame := func(payload string) []byte {
+		data := make([]byte, 4, 4+len(payload))
+		binary.BigEndian.PutUint32(data, uint32(len(payload)))
+		return append(data, payload...)
+	}
+
+	f := newWatchFramer("application/vnd.kubernetes.protobuf;stream=watch")
+	first, second := frame("event1"), frame("event2")
+	if n := f.consume(first[:2], false); n != 2 || f.atBoundary() {
+		t.Fatalf("expect partial length prefix is consumed, but got %d, boundary %v", n, f.atBoundary())
+	}
+	stream := append(append([]byte{}, first[2:]...), second...)
+	if n := f.consume(stream, true); n != len(first)-2 || !f.atBoundary() {
+		t.Errorf("expect the consumption is stopped at the end of first frame, but got %d, boundary %v", n, f.atBoundary())
+	}
+	if n := f.consume(second, true); n != 0 {
+		t.Errorf("expect nothing is consumed at boundary, but got %d", n)
+	}
+
+	f = newWatchFramer("application/json")
+	if f.consume([]byte(`{"type":"ADDED"}`+"\n"+`{"type"`), false); f.atBoundary() {
+		t.Errorf("expect json event is partially written")
+	}
+	if n := f.consume([]byte(`:"DELETED"}`+"\n{"), true); n != 12 || !f.atBoundary() {
+		t.Errorf("expect the consumption is stopped at newline, but got %d, boundary %v", n, f.atBoundary())
+	}
+}
@@ -185,7 +185,7 @@ func WithIfPoolScopedResource(handler http.Handler) http.Handler {
 		ctx := req.Context()
 		if info, ok := apirequest.RequestInfoFrom(ctx); ok {
 			var ifPoolScopedResource bool
-			if info.IsResourceRequest && resources.IsPoolScopeResources(info) {
+			if info.IsResourceRequest && resources.IsPoolScopeResources(info, req.URL.Query().Get("labelSelector")) {
 				ifPoolScopedResource = true
 			}
 			ctx = util.WithIfPoolScopedResource(ctx, ifPoolScopedResource)
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	yurtutil "github.com/openyurtio/openyurt/pkg/util"
+	"github.com/openyurtio/openyurt/pkg/yurthub/faultinjection"
+)
+
+// getFaultHandler returns a http handler that reports the fault injected currently.
+func getFaultHandler(injector *faultinjection.Injector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault := injector.Current()
+		if fault == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "no fault is injected")
+			return
+		}
+
+		data, err := json.Marshal(fault)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "could not encode fault, %v", err)
+			return
+		}
+		w.Header().Set(yurtutil.HttpHeaderContentType, yurtutil.HttpContentTypeJson)
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+}
+
+// injectFaultHandler returns a http handler that injects a fault for accessing cloud,
+// the fault is specified by query parameters, for example:
+// /v1/debug/faults?type=unreachable&duration=10m
+// /v1/debug/faults?type=latency&latency=500ms&duration=10m
+// /v1/debug/faults?type=loss&lossRate=0.3&duration=10m
+func injectFaultHandler(injector *faultinjection.Injector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		duration, err := time.ParseDuration(query.Get("duration"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "could not parse duration, %v", err)
+			return
+		}
+
+		fault := faultinjection.Fault{
+			Type: faultinjection.FaultType(query.Get("type")),
+		}
+		if latency := query.Get("latency"); len(latency) != 0 {
+			if fault.Latency, err = time.ParseDuration(latency); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "could not parse latency, %v", err)
+				return
+			}
+		}
+		if lossRate := query.Get("lossRate"); len(lossRate) != 0 {
+			if fault.LossRate, err = strconv.ParseFloat(lossRate, 64); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "could not parse loss rate, %v", err)
+				return
+			}
+		}
+
+		if err := injector.Inject(fault, duration); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "could not inject fault, %v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "inject %s fault for %v successfully", fault.Type, duration)
+	})
+}
+
+// clearFaultHandler returns a http handler that clears the injected fault.
+func clearFaultHandler(injector *faultinjection.Injector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		injector.Clear()
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "clear fault successfully")
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+	"github.com/openyurtio/openyurt/pkg/yurthub/cachemanager"
+	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/rest"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+)
+
+const (
+	joinInfoPath          = "/v1/join-info"
+	joinInfoRefreshPeriod = 10 * time.Minute
+)
+
+var joinInfoKey = storage.ClusterInfoKey{
+	ClusterInfoType: storage.JoinInfo,
+	UrlPath:         joinInfoPath,
+}
+
+// anonymousClientGetter returns a func which creates client for the healthy kube-apiserver, the client
+// is anonymous because cluster-info ConfigMap is only readable by anonymous user in kubeadm clusters.
+func anonymousClientGetter(restMgr *rest.RestConfigManager) func() kubernetes.Interface {
+	return func() kubernetes.Interface {
+		restCfg := restMgr.GetRestConfig(true)
+		if restCfg == nil {
+			return nil
+		}
+		client, err := kubernetes.NewForConfig(restclient.AnonymousClientConfig(restCfg))
+		if err != nil {
+			klog.Errorf("could not create anonymous client, %v", err)
+			return nil
+		}
+		return client
+	}
+}
+
+// cacheJoinInfo gets cluster-info ConfigMap and version from kube-apiserver, and saves them into local cache.
+func cacheJoinInfo(client kubernetes.Interface, sw cachemanager.StorageWrapper) ([]byte, error) {
+	clusterInfo, err := client.CoreV1().ConfigMaps(metav1.NamespacePublic).Get(context.TODO(), bootstrapapi.ConfigMapClusterInfo, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&token.JoinInfo{ClusterInfo: clusterInfo, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	if err := sw.SaveClusterInfo(joinInfoKey, data); err != nil {
+		klog.Errorf("could not cache join info, %v", err)
+	}
+	return data, nil
+}
+
+// refreshJoinInfo keeps join info in local cache up to date, so nodes in the nodepool can
+// be joined from this yurthub even if kube-apiserver becomes unreachable.
+func refreshJoinInfo(clientGetter func() kubernetes.Interface, sw cachemanager.StorageWrapper) {
+	client := clientGetter()
+	if client == nil {
+		return
+	}
+	if _, err := cacheJoinInfo(client, sw); err != nil {
+		klog.Errorf("could not refresh join info, %v", err)
+	}
+}
+
+// joinInfoHandler returns the information for bootstrapping node, it's got from kube-apiserver
+// if it's healthy, otherwise from local cache.
+func joinInfoHandler(clientGetter func() kubernetes.Interface, sw cachemanager.StorageWrapper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if client := clientGetter(); client != nil {
+			data, err := cacheJoinInfo(client, sw)
+			if err == nil {
+				w.WriteHeader(http.StatusOK)
+				writeRawJSON(data, w)
+				return
+			}
+			klog.Errorf("could not get join info from kube-apiserver, %v, so get it from local cache", err)
+		}
+
+		data, err := sw.GetClusterInfo(joinInfoKey)
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusOK)
+			writeRawJSON(data, w)
+		case err == storage.ErrStorageNotFound:
+			w.WriteHeader(http.StatusNotFound)
+			writeErrResponse(joinInfoPath, err, w)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			writeErrResponse(joinInfoPath, err, w)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+	"github.com/openyurtio/openyurt/pkg/yurthub/cachemanager"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
+)
+
+func TestJoinInfoHandler(t *testing.T) {
+	dStorage, err := disk.NewDiskStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("disk initialize error: %v", err)
+	}
+	sw := cachemanager.NewStorageWrapper(dStorage)
+
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrapapi.ConfigMapClusterInfo,
+			Namespace: metav1.NamespacePublic,
+		},
+		Data: map[string]string{
+			bootstrapapi.KubeConfigKey:                    "kubeconfig",
+			bootstrapapi.JWSSignatureKeyPrefix + "abcdef": "signature",
+		},
+	})
+	healthy := func() kubernetes.Interface { return client }
+	unhealthy := func() kubernetes.Interface { return nil }
+
+	testcases := []struct {
+		name         string
+		clientGetter func() kubernetes.Interface
+		code         int
+	}{
+		{name: "no cache when kube-apiserver is unhealthy", clientGetter: unhealthy, code: http.StatusNotFound},
+		{name: "get from kube-apiserver", clientGetter: healthy, code: http.StatusOK},
+		{name: "get from cache when kube-apiserver is unhealthy", clientGetter: unhealthy, code: http.StatusOK},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, joinInfoPath, nil)
+			resp := httptest.NewRecorder()
+			joinInfoHandler(tc.clientGetter, sw).ServeHTTP(resp, req)
+			if resp.Code != tc.code {
+				t.Fatalf("expect status code %d, but got %d", tc.code, resp.Code)
+			}
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var info token.JoinInfo
+			if err := json.Unmarshal(resp.Body.Bytes(), &info); err != nil {
+				t.Fatalf("could not decode join info, %v", err)
+			}
+			if info.ClusterInfo == nil || info.ClusterInfo.Data[bootstrapapi.KubeConfigKey] != "kubeconfig" {
+				t.Errorf("expect cluster-info in join info, but got %v", info.ClusterInfo)
+			}
+			if info.Version == nil {
+				t.Errorf("expect version in join info")
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/klog/v2"
 
@@ -51,16 +52,43 @@ func RunYurtHubServers(cfg *config.YurtHubConfiguration,
 		}
 	}
 
+	if cfg.YurtHubSecondaryServerServing != nil {
+		if err := cfg.YurtHubSecondaryServerServing.Serve(hubServerHandler, 0, stopCh); err != nil {
+			return err
+		}
+	}
+
 	// start yurthub proxy servers for forwarding requests to cloud kube-apiserver
 	if cfg.WorkingMode == util.WorkingModeEdge {
 		proxyHandler = wrapNonResourceHandler(proxyHandler, cfg, rest)
 	}
+
+	// keep join info in local cache and serve it on the dedicated address, so nodes can be joined
+	// from the nodepool when kube-apiserver is unreachable. the address is only configured on edge
+	// nodes with yurt coordinator enabled.
+	if cfg.YurtHubJoinInfoServerServing != nil {
+		go wait.Until(func() {
+			refreshJoinInfo(anonymousClientGetter(rest), cfg.StorageWrapper)
+		}, joinInfoRefreshPeriod, stopCh)
+
+		joinInfoServerHandler := mux.NewRouter()
+		joinInfoServerHandler.Handle(joinInfoPath, joinInfoHandler(anonymousClientGetter(rest), cfg.StorageWrapper)).Methods("GET")
+		if err := cfg.YurtHubJoinInfoServerServing.Serve(joinInfoServerHandler, 0, stopCh); err != nil {
+			return err
+		}
+	}
 	if cfg.YurtHubProxyServerServing != nil {
 		if err := cfg.YurtHubProxyServerServing.Serve(proxyHandler, 0, stopCh); err != nil {
 			return err
 		}
 	}
 
+	if cfg.YurtHubSecondaryProxyServing != nil {
+		if err := cfg.YurtHubSecondaryProxyServing.Serve(proxyHandler, 0, stopCh); err != nil {
+			return err
+		}
+	}
+
 	if cfg.YurtHubDummyProxyServerServing != nil {
 		if err := cfg.YurtHubDummyProxyServerServing.Serve(proxyHandler, 0, stopCh); err != nil {
 			return err
@@ -73,6 +101,12 @@ func RunYurtHubServers(cfg *config.YurtHubConfiguration,
 		}
 	}
 
+	if cfg.YurtHubSecondarySecureServing != nil {
+		if _, err := cfg.YurtHubSecondarySecureServing.Serve(proxyHandler, 0, stopCh); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -90,6 +124,13 @@ func registerHandlers(c *mux.Router, cfg *config.YurtHubConfiguration, rest *res
 		profile.Install(c)
 	}
 
+	// register handlers for fault injection, which is only used for testing
+	if cfg.FaultInjector != nil {
+		c.Handle("/v1/debug/faults", getFaultHandler(cfg.FaultInjector)).Methods("GET")
+		c.Handle("/v1/debug/faults", injectFaultHandler(cfg.FaultInjector)).Methods("POST", "PUT")
+		c.Handle("/v1/debug/faults", clearFaultHandler(cfg.FaultInjector)).Methods("DELETE")
+	}
+
 	// register handler for metrics
 	c.Handle("/metrics", promhttp.Handler())
 
@@ -101,6 +142,12 @@ func registerHandlers(c *mux.Router, cfg *config.YurtHubConfiguration, rest *res
 	}
 	c.Handle("/openyurt.io/v1/namespaces/{ns}/pods/{podname}/upgrade",
 		ota.HealthyCheck(rest, cfg.NodeName, ota.UpdatePod)).Methods("POST")
+	c.Handle("/openyurt.io/v1/namespaces/{ns}/pods/{podname}/upgrade/stage",
+		ota.HealthyCheck(rest, cfg.NodeName, ota.StagePod)).Methods("POST")
+	c.Handle("/openyurt.io/v1/namespaces/{ns}/pods/{podname}/upgrade/stage",
+		ota.HealthyCheck(rest, cfg.NodeName, ota.CancelPod)).Methods("DELETE")
+	c.Handle("/openyurt.io/v1/namespaces/{ns}/pods/{podname}/upgrade/confirm",
+		ota.HealthyCheck(rest, cfg.NodeName, ota.ConfirmPod)).Methods("POST")
 }
 
 // healthz returns ok for healthz request
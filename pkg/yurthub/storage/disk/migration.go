@@ -0,0 +1,279 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/util/fs"
+)
+
+const (
+	// FormatVersionLegacy is the cache format in which resources are stored at
+	// /<Component>/<Resource>/
+	FormatVersionLegacy = 1
+	// FormatVersionGVR is the cache format in which resources are stored at
+	// /<Component>/<Resource.Version.Group>/
+	FormatVersionGVR = 2
+	// CurrentFormatVersion is the cache format used by this version of yurthub.
+	CurrentFormatVersion = FormatVersionGVR
+
+	// formatVersionKey is the path of file which records the format version of cache
+	formatVersionKey = "_internal/cache/format-version"
+)
+
+// migrateFunc migrates the cache from the specified format version to the next one.
+type migrateFunc func(ds *diskStorage) error
+
+// migrations holds the migration path of cache format, the key is the format version
+// that migration starts from. New migrations should be added here when the storage
+// layout is changed, so the cache can be kept across yurthub upgrades.
+var migrations = map[int]migrateFunc{
+	FormatVersionLegacy: migrateLegacyToGVR,
+}
+
+// migrate detects the format version of cache and migrates the cache to the
+// current format version step by step. The format version of cache is returned,
+// if the migration fails, the cache will be kept as it is in the returned version.
+func (ds *diskStorage) migrate() (int, error) {
+	version, err := ds.formatVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	if version > CurrentFormatVersion {
+		klog.Warningf("cache format version %d is newer than %d supported by current yurthub, skip migration", version, CurrentFormatVersion)
+		return version, nil
+	}
+
+	for version < CurrentFormatVersion {
+		fn, ok := migrations[version]
+		if !ok {
+			return version, fmt.Errorf("no migration path for cache format version %d", version)
+		}
+
+		klog.Infof("start to migrate cache format from version %d to %d", version, version+1)
+		if err := fn(ds); err != nil {
+			return version, fmt.Errorf("could not migrate cache format from version %d, %v", version, err)
+		}
+		version++
+
+		if err := ds.setFormatVersion(version); err != nil {
+			return version, err
+		}
+		klog.Infof("cache format has been migrated to version %d", version)
+	}
+
+	return version, nil
+}
+
+// formatVersion returns the format version recorded in cache. If there's no format
+// version recorded, it will be detected from the layout of cache, and the cache
+// without any component resources will be regarded as current format version.
+func (ds *diskStorage) formatVersion() (int, error) {
+	path := filepath.Join(ds.baseDir, formatVersionKey)
+	if fs.IfExists(path) {
+		content, err := ds.fsOperator.Read(path)
+		if err != nil {
+			return 0, fmt.Errorf("could not read cache format version from %s, %v", path, err)
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil {
+			return 0, fmt.Errorf("could not parse cache format version %q, %v", string(content), err)
+		}
+		return version, nil
+	}
+
+	enhancement, err := ifEnhancement(ds.baseDir, *ds.fsOperator)
+	if err != nil {
+		return 0, err
+	}
+
+	version := FormatVersionLegacy
+	if enhancement {
+		version = CurrentFormatVersion
+	}
+	if err := ds.setFormatVersion(version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (ds *diskStorage) setFormatVersion(version int) error {
+	path := filepath.Join(ds.baseDir, formatVersionKey)
+	content := []byte(strconv.Itoa(version))
+	if err := ds.fsOperator.Write(path, content); err == fs.ErrNotExists {
+		err = ds.fsOperator.CreateFile(path, content)
+		if err != nil {
+			return fmt.Errorf("could not create cache format version file %s, %v", path, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("could not update cache format version file %s, %v", path, err)
+	}
+	return nil
+}
+
+// dirRename records a dir renamed by migration, so it can be rolled back.
+type dirRename struct {
+	from string
+	to   string
+}
+
+// renameDir renames dir in cache, it can be replaced in unit tests.
+var renameDir = func(ds *diskStorage, from, to string) error {
+	return ds.fsOperator.Rename(from, to)
+}
+
+// migrateLegacyToGVR renames dir of each resource from <Resource> to <Resource.Version.Group>,
+// the group and version are resolved from apiVersion of objects cached under the dir.
+// the migration is planned before any dir is changed, and the renamed dirs are rolled back if
+// the migration fails partway, so the cache is never left in a mix of legacy and gvr format.
+func migrateLegacyToGVR(ds *diskStorage) error {
+	renames, removes, err := planLegacyToGVR(ds)
+	if err != nil {
+		return err
+	}
+
+	done := make([]dirRename, 0, len(renames))
+	for _, r := range renames {
+		klog.Infof("migrate legacy cache dir %s to %s", r.from, r.to)
+		if err := renameDir(ds, r.from, r.to); err != nil {
+			rollbackRenames(ds, done)
+			return fmt.Errorf("failed to rename dir %s to %s, %v", r.from, r.to, err)
+		}
+		done = append(done, r)
+	}
+
+	// the legacy dirs which are empty or have been migrated are removed at last.
+	for _, dir := range removes {
+		klog.Infof("remove legacy cache dir %s", dir)
+		if err := ds.fsOperator.DeleteDir(dir); err != nil {
+			rollbackRenames(ds, done)
+			return fmt.Errorf("failed to delete dir %s, %v", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// planLegacyToGVR resolves the gvr dir of each legacy resource dir without changing the cache, and returns
+// the dirs to be renamed and the legacy dirs to be removed because they are empty or have been migrated.
+func planLegacyToGVR(ds *diskStorage) ([]dirRename, []string, error) {
+	compDirs, err := ds.fsOperator.List(ds.baseDir, fs.ListModeDirs, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list dirs under %s, %v", ds.baseDir, err)
+	}
+
+	var renames []dirRename
+	var removes []string
+	for _, compDir := range compDirs {
+		if _, dirName := filepath.Split(compDir); dirName == "_internal" {
+			continue
+		}
+
+		resDirs, err := ds.fsOperator.List(compDir, fs.ListModeDirs, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list dirs under %s, %v", compDir, err)
+		}
+
+		for _, resDir := range resDirs {
+			_, resource := filepath.Split(resDir)
+			if len(strings.Split(resource, ".")) != 1 {
+				continue
+			}
+
+			gv, err := resolveGroupVersion(ds.fsOperator, resDir)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if gv == nil {
+				// nothing is cached for this resource, so remove it directly.
+				removes = append(removes, resDir)
+				continue
+			}
+
+			group := gv.Group
+			if group == "" {
+				group = "core"
+			}
+			newResDir := filepath.Join(compDir, strings.Join([]string{resource, gv.Version, group}, "."))
+			if fs.IfExists(newResDir) {
+				klog.Warningf("cache dir %s already exists, legacy cache dir %s will be removed", newResDir, resDir)
+				removes = append(removes, resDir)
+				continue
+			}
+			renames = append(renames, dirRename{from: resDir, to: newResDir})
+		}
+	}
+	return renames, removes, nil
+}
+
+func rollbackRenames(ds *diskStorage, done []dirRename) {
+	for i := len(done) - 1; i >= 0; i-- {
+		if err := renameDir(ds, done[i].to, done[i].from); err != nil {
+			klog.Errorf("could not roll back cache dir %s to %s, %v", done[i].to, done[i].from, err)
+		}
+	}
+}
+
+// resolveGroupVersion returns the group version of objects cached under resDir,
+// nil will be returned if there's no object under resDir.
+func resolveGroupVersion(fsOperator *fs.FileSystemOperator, resDir string) (*schema.GroupVersion, error) {
+	files, err := fsOperator.List(resDir, fs.ListModeFiles, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files under %s, %v", resDir, err)
+	}
+
+	cached := 0
+	for _, file := range files {
+		if isTmpFile(file) {
+			continue
+		}
+		cached++
+
+		content, err := fsOperator.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s, %v", file, err)
+		}
+
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(content, &typeMeta); err != nil || len(typeMeta.APIVersion) == 0 {
+			klog.Warningf("could not resolve apiVersion from cached file %s, %v", file, err)
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse apiVersion %s in file %s, %v", typeMeta.APIVersion, file, err)
+		}
+		return &gv, nil
+	}
+
+	if cached != 0 {
+		return nil, fmt.Errorf("could not resolve group version for %d cached files under %s", cached, resDir)
+	}
+	return nil, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/util/fs"
+)
+
+func TestMigrate(t *testing.T) {
+	cases := []struct {
+		description     string
+		existingFile    map[string][]byte
+		expectedFile    map[string][]byte
+		expectedVersion int
+	}{
+		{
+			description: "should migrate legacy cache to gvr format",
+			existingFile: map[string][]byte{
+				"/kubelet/pods/default/nginx":          []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+				"/kubelet/leases/kube-node-lease/node": []byte(`{"apiVersion":"coordination.k8s.io/v1","kind":"Lease"}`),
+				"/kube-proxy/services/default/nginx":   []byte(`{"apiVersion":"v1","kind":"Service"}`),
+			},
+			expectedFile: map[string][]byte{
+				"/kubelet/pods.v1.core/default/nginx":                         []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+				"/kubelet/leases.v1.coordination.k8s.io/kube-node-lease/node": []byte(`{"apiVersion":"coordination.k8s.io/v1","kind":"Lease"}`),
+				"/kube-proxy/services.v1.core/default/nginx":                  []byte(`{"apiVersion":"v1","kind":"Service"}`),
+				formatVersionKey: []byte(strconv.Itoa(FormatVersionGVR)),
+			},
+			expectedVersion: FormatVersionGVR,
+		},
+		{
+			description: "should keep legacy cache if group version can not be resolved",
+			existingFile: map[string][]byte{
+				"/kubelet/pods/default/nginx": []byte("nginx-pod"),
+			},
+			expectedFile: map[string][]byte{
+				"/kubelet/pods/default/nginx": []byte("nginx-pod"),
+				formatVersionKey:              []byte(strconv.Itoa(FormatVersionLegacy)),
+			},
+			expectedVersion: FormatVersionLegacy,
+		},
+		{
+			description: "should keep all of legacy cache if group version of a resource can not be resolved",
+			existingFile: map[string][]byte{
+				"/kubelet/pods/default/nginx":        []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+				"/kubelet/services/default/nginx":    []byte("nginx-svc"),
+				"/kube-proxy/services/default/nginx": []byte(`{"apiVersion":"v1","kind":"Service"}`),
+			},
+			expectedFile: map[string][]byte{
+				"/kubelet/pods/default/nginx":        []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+				"/kubelet/services/default/nginx":    []byte("nginx-svc"),
+				"/kube-proxy/services/default/nginx": []byte(`{"apiVersion":"v1","kind":"Service"}`),
+				formatVersionKey:                     []byte(strconv.Itoa(FormatVersionLegacy)),
+			},
+			expectedVersion: FormatVersionLegacy,
+		},
+		{
+			description: "should record current format version for gvr format cache",
+			existingFile: map[string][]byte{
+				"/kubelet/pods.v1.core/default/nginx": []byte("nginx-pod"),
+			},
+			expectedFile: map[string][]byte{
+				"/kubelet/pods.v1.core/default/nginx": []byte("nginx-pod"),
+				formatVersionKey:                      []byte(strconv.Itoa(CurrentFormatVersion)),
+			},
+			expectedVersion: CurrentFormatVersion,
+		},
+	}
+
+	for _, c := range cases {
+		baseDir := filepath.Join(diskStorageTestBaseDir, "migration")
+		t.Run(c.description, func(t *testing.T) {
+			os.RemoveAll(baseDir)
+			defer os.RemoveAll(baseDir)
+			ds := &diskStorage{
+				baseDir:    baseDir,
+				fsOperator: &fs.FileSystemOperator{},
+			}
+			ds.fsOperator.CreateDir(baseDir)
+
+			for f, b := range c.existingFile {
+				path := filepath.Join(baseDir, f)
+				if err := ds.fsOperator.CreateFile(path, b); err != nil {
+					t.Errorf("failed to create file %s, %v", path, err)
+				}
+			}
+
+			version, _ := ds.migrate()
+			if version != c.expectedVersion {
+				t.Errorf("unexpected format version, want: %d, got: %d", c.expectedVersion, version)
+			}
+
+			for f, b := range c.expectedFile {
+				path := filepath.Join(baseDir, f)
+				content, err := ds.fsOperator.Read(path)
+				if err != nil {
+					t.Errorf("failed to read file %s, %v", path, err)
+					continue
+				}
+				if string(content) != string(b) {
+					t.Errorf("unexpected content of file %s, want: %s, got: %s", path, string(b), string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateRollback(t *testing.T) {
+	baseDir := filepath.Join(diskStorageTestBaseDir, "migration-rollback")
+	os.RemoveAll(baseDir)
+	defer os.RemoveAll(baseDir)
+	ds := &diskStorage{
+		baseDir:    baseDir,
+		fsOperator: &fs.FileSystemOperator{},
+	}
+	ds.fsOperator.CreateDir(baseDir)
+
+	existingFile := map[string][]byte{
+		"/kubelet/pods/default/nginx":        []byte(`{"apiVersion":"v1","kind":"Pod"}`),
+		"/kube-proxy/services/default/nginx": []byte(`{"apiVersion":"v1","kind":"Service"}`),
+	}
+	for f, b := range existingFile {
+		path := filepath.Join(baseDir, f)
+		if err := ds.fsOperator.CreateFile(path, b); err != nil {
+			t.Fatalf("failed to create file %s, %v", path, err)
+		}
+	}
+
+	// the rename of the second resource dir fails.
+	renames := 0
+	originalRenameDir := renameDir
+	defer func() { renameDir = originalRenameDir }()
+	renameDir = func(ds *diskStorage, from, to string) error {
+		renames++
+		if renames == 2 {
+			return errors.New("rename failed")
+		}
+		return originalRenameDir(ds, from, to)
+	}
+
+	version, err := ds.migrate()
+	if err == nil || version != FormatVersionLegacy {
+		t.Fatalf("expect migration fails in legacy format version, but got version %d, %v", version, err)
+	}
+
+	for f, b := range existingFile {
+		path := filepath.Join(baseDir, f)
+		content, err := ds.fsOperator.Read(path)
+		if err != nil {
+			t.Errorf("expect legacy cache %s is rolled back, %v", path, err)
+			continue
+		}
+		if string(content) != string(b) {
+			t.Errorf("unexpected content of file %s, want: %s, got: %s", path, string(b), string(content))
+		}
+	}
+	for _, dir := range []string{"/kubelet/pods.v1.core", "/kube-proxy/services.v1.core"} {
+		if fs.IfExists(filepath.Join(baseDir, dir)) {
+			t.Errorf("expect gvr cache dir %s is rolled back", dir)
+		}
+	}
+}
@@ -77,16 +77,7 @@ func NewDiskStorage(dir string) (storage.Store, error) {
 		fsOperator:       fsOperator,
 	}
 
-	enhancementMode, err := ifEnhancement(ds.baseDir, *ds.fsOperator)
-	if err != nil {
-		return nil, fmt.Errorf("cannot detect running mode of disk storage, %v", err)
-	}
-	ds.enhancementMode = enhancementMode
-	if ds.enhancementMode {
-		klog.Info("yurthub disk storage will run in enhancement mode")
-	}
-
-	err = ds.Recover()
+	err := ds.Recover()
 	if err != nil {
 		// we should ensure that there no tmp file last when local storage start to work.
 		// Otherwise, it means the baseDir cannot serve as local storage dir, because there're some subpath
@@ -94,6 +85,21 @@ func NewDiskStorage(dir string) (storage.Store, error) {
 		// So, we'd better return error to avoid unknown problems.
 		return nil, fmt.Errorf("could not recover local storage, %v, and skip the error", err)
 	}
+
+	// migrate the cache to current format version, so the cache can be kept when storage layout is changed.
+	// if the migration fails, disk storage will keep working on the cache in the old format.
+	version, err := ds.migrate()
+	if err != nil {
+		klog.Errorf("could not migrate cache to format version %d, %v", CurrentFormatVersion, err)
+		if version == 0 {
+			return nil, fmt.Errorf("cannot detect format version of disk storage, %v", err)
+		}
+	}
+	ds.enhancementMode = version >= FormatVersionGVR
+	if ds.enhancementMode {
+		klog.Info("yurthub disk storage will run in enhancement mode")
+	}
+
 	return ds, nil
 }
 
@@ -424,7 +430,7 @@ func (ds *diskStorage) DeleteComponentResources(component string) error {
 func (ds *diskStorage) SaveClusterInfo(key storage.ClusterInfoKey, content []byte) error {
 	var path string
 	switch key.ClusterInfoType {
-	case storage.APIsInfo, storage.Version:
+	case storage.APIsInfo, storage.Version, storage.JoinInfo:
 		path = filepath.Join(ds.baseDir, string(key.ClusterInfoType))
 	case storage.APIResourcesInfo:
 		translatedURLPath := strings.ReplaceAll(key.UrlPath, "/", "_")
@@ -449,7 +455,7 @@ func (ds *diskStorage) SaveClusterInfo(key storage.ClusterInfoKey, content []byt
 func (ds *diskStorage) GetClusterInfo(key storage.ClusterInfoKey) ([]byte, error) {
 	var path string
 	switch key.ClusterInfoType {
-	case storage.APIsInfo, storage.Version:
+	case storage.APIsInfo, storage.Version, storage.JoinInfo:
 		path = filepath.Join(ds.baseDir, string(key.ClusterInfoType))
 	case storage.APIResourcesInfo:
 		translatedURLPath := strings.ReplaceAll(key.UrlPath, "/", "_")
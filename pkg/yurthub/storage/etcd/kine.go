@@ -0,0 +1,288 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/utils"
+)
+
+const (
+	// kineMaxRetries is the max times to retry when a key is modified by others during put
+	kineMaxRetries = 3
+)
+
+// kineStorage is the storage of yurt-coordinator which uses kine as backend. Kine only supports
+// the transactions which create, update or delete a single key by comparing its mod revision as
+// kube-apiserver does, so keys are operated one by one instead of in one transaction. The object
+// key is written after its mirror rv key, and the stale mirror rv key will be corrected at the
+// next write of object.
+type kineStorage struct {
+	*etcdStorage
+}
+
+func (s *kineStorage) Create(key storage.Key, content []byte) error {
+	if err := utils.ValidateKV(key, content, storageKey{}); err != nil {
+		return err
+	}
+
+	keyStr := key.Key()
+	originRv, err := getRvOfObject(content)
+	if err != nil {
+		return fmt.Errorf("failed to get rv from content when creating %s, %v", keyStr, err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	if kv, err := s.get(ctx, keyStr); err != nil {
+		return err
+	} else if kv != nil {
+		return storage.ErrKeyExists
+	}
+	if err := s.put(ctx, s.mirrorPath(keyStr, rvType), []byte(fixLenRvString(originRv))); err != nil {
+		return err
+	}
+	if created, err := s.create(ctx, keyStr, content); err != nil {
+		return err
+	} else if !created {
+		return storage.ErrKeyExists
+	}
+
+	storageKey := key.(storageKey)
+	s.localComponentKeyCache.AddKey(storageKey.component(), storageKey)
+	return nil
+}
+
+func (s *kineStorage) Delete(key storage.Key) error {
+	if err := utils.ValidateKey(key, storageKey{}); err != nil {
+		return err
+	}
+
+	keyStr := key.Key()
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	if err := s.delete(ctx, keyStr); err != nil {
+		return err
+	}
+	if err := s.delete(ctx, s.mirrorPath(keyStr, rvType)); err != nil {
+		return err
+	}
+
+	storageKey := key.(storageKey)
+	s.localComponentKeyCache.DeleteKey(storageKey.component(), storageKey)
+	return nil
+}
+
+func (s *kineStorage) Update(key storage.Key, content []byte, rv uint64) ([]byte, error) {
+	if err := utils.ValidateKV(key, content, storageKey{}); err != nil {
+		return nil, err
+	}
+
+	keyStr := key.Key()
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	kv, err := s.get(ctx, keyStr)
+	if err != nil {
+		return nil, err
+	} else if kv == nil {
+		return nil, storage.ErrStorageNotFound
+	}
+	if fresher, err := s.isFresher(ctx, keyStr, fixLenRvUint64(rv)); err != nil {
+		return nil, err
+	} else if !fresher {
+		return kv.Value, storage.ErrUpdateConflict
+	}
+
+	if err := s.put(ctx, s.mirrorPath(keyStr, rvType), []byte(fixLenRvUint64(rv))); err != nil {
+		return nil, err
+	}
+	if updated, current, err := s.update(ctx, keyStr, content, kv.ModRevision); err != nil {
+		return nil, err
+	} else if !updated {
+		if current == nil {
+			return nil, storage.ErrStorageNotFound
+		}
+		return current.Value, storage.ErrUpdateConflict
+	}
+
+	return content, nil
+}
+
+func (s *kineStorage) ReplaceComponentList(component string, gvr schema.GroupVersionResource, namespace string, contents map[storage.Key][]byte) error {
+	addedOrUpdated, deleted, err := s.diffComponentList(component, gvr, namespace, contents)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	for k := range addedOrUpdated {
+		rv, err := getRvOfObject(contents[k])
+		if err != nil {
+			klog.Errorf("failed to process %s in list object, %v", k.Key(), err)
+			continue
+		}
+		// only create the key or update it with a fresher object
+		if fresher, err := s.isFresher(ctx, k.Key(), fixLenRvString(rv)); err != nil {
+			return err
+		} else if !fresher {
+			continue
+		}
+		if err := s.put(ctx, s.mirrorPath(k.Key(), rvType), []byte(fixLenRvString(rv))); err != nil {
+			return err
+		}
+		if err := s.put(ctx, k.Key(), contents[k]); err != nil {
+			return err
+		}
+	}
+	for k := range deleted {
+		if err := s.delete(ctx, k.Key()); err != nil {
+			return err
+		}
+		if err := s.delete(ctx, s.mirrorPath(k.Key(), rvType)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *kineStorage) DeleteComponentResources(component string) error {
+	if component == "" {
+		return storage.ErrEmptyComponent
+	}
+	keyCache, loaded := s.localComponentKeyCache.LoadAndDelete(component)
+	if !loaded || keyCache.m == nil {
+		// no need to delete
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	for _, keySet := range keyCache.m {
+		for k := range keySet {
+			if err := s.delete(ctx, k.Key()); err != nil {
+				return err
+			}
+			if err := s.delete(ctx, s.mirrorPath(k.Key(), rvType)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isFresher checks whether rv is fresher than the one recorded in mirror rv key of key,
+// it's true if the key does not exist.
+func (s *kineStorage) isFresher(ctx context.Context, key, rv string) (bool, error) {
+	kv, err := s.get(ctx, key)
+	if err != nil || kv == nil {
+		return err == nil, err
+	}
+
+	mirror, err := s.get(ctx, s.mirrorPath(key, rvType))
+	if err != nil || mirror == nil {
+		return err == nil, err
+	}
+	return string(mirror.Value) < rv, nil
+}
+
+func (s *kineStorage) get(ctx context.Context, key string) (*mvccpb.KeyValue, error) {
+	getResp, err := s.client.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(getResp.Kvs) == 0 {
+		return nil, nil
+	}
+	return getResp.Kvs[0], nil
+}
+
+// create creates the key if it does not exist.
+func (s *kineStorage) create(ctx context.Context, key string, value []byte) (bool, error) {
+	txnResp, err := s.client.Txn(ctx).If(
+		notFound(key),
+	).Then(
+		clientv3.OpPut(key, string(value)),
+	).Commit()
+	if err != nil {
+		return false, err
+	}
+	return txnResp.Succeeded, nil
+}
+
+// update updates the key if it's not modified since modRevision, the current key-value
+// is returned if it's modified.
+func (s *kineStorage) update(ctx context.Context, key string, value []byte, modRevision int64) (bool, *mvccpb.KeyValue, error) {
+	txnResp, err := s.client.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(key), "=", modRevision),
+	).Then(
+		clientv3.OpPut(key, string(value)),
+	).Else(
+		clientv3.OpGet(key),
+	).Commit()
+	if err != nil {
+		return false, nil, err
+	}
+	if txnResp.Succeeded {
+		return true, nil, nil
+	}
+
+	getResp := (*clientv3.GetResponse)(txnResp.Responses[0].GetResponseRange())
+	if len(getResp.Kvs) == 0 {
+		return false, nil, nil
+	}
+	return false, getResp.Kvs[0], nil
+}
+
+// put creates or updates the key no matter what its current value is.
+func (s *kineStorage) put(ctx context.Context, key string, value []byte) error {
+	for i := 0; i < kineMaxRetries; i++ {
+		kv, err := s.get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		var done bool
+		if kv == nil {
+			done, err = s.create(ctx, key, value)
+		} else {
+			done, _, err = s.update(ctx, key, value, kv.ModRevision)
+		}
+		if err != nil || done {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to put %s, it's modified by others", key)
+}
+
+// delete deletes the key no matter what its current value is.
+func (s *kineStorage) delete(ctx context.Context, key string) error {
+	_, err := s.client.Txn(ctx).If().Then(
+		clientv3.OpGet(key),
+		clientv3.OpDelete(key),
+	).Commit()
+	return err
+}
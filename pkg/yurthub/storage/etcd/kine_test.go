@@ -0,0 +1,261 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/util/fs"
+)
+
+// fakeKineKV is an in-memory KV which only accepts the transactions supported by kine.
+type fakeKineKV struct {
+	clientv3.KV
+	rev int64
+	kvs map[string]*mvccpb.KeyValue
+}
+
+func (kv *fakeKineKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp := &clientv3.GetResponse{}
+	if v, ok := kv.kvs[key]; ok {
+		resp.Kvs = []*mvccpb.KeyValue{v}
+	}
+	return resp, nil
+}
+
+func (kv *fakeKineKV) Txn(ctx context.Context) clientv3.Txn {
+	return &fakeKineTxn{kv: kv}
+}
+
+type fakeKineTxn struct {
+	kv      *fakeKineKV
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (txn *fakeKineTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	txn.cmps = append(txn.cmps, cs...)
+	return txn
+}
+
+func (txn *fakeKineTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	txn.thenOps = append(txn.thenOps, ops...)
+	return txn
+}
+
+func (txn *fakeKineTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	txn.elseOps = append(txn.elseOps, ops...)
+	return txn
+}
+
+func (txn *fakeKineTxn) Commit() (*clientv3.TxnResponse, error) {
+	// delete: If().Then(OpGet(key), OpDelete(key))
+	if len(txn.cmps) == 0 {
+		if len(txn.thenOps) != 2 || len(txn.elseOps) != 0 || !txn.thenOps[0].IsGet() || !txn.thenOps[1].IsDelete() ||
+			string(txn.thenOps[0].KeyBytes()) != string(txn.thenOps[1].KeyBytes()) || len(txn.thenOps[1].RangeBytes()) != 0 {
+			return nil, fmt.Errorf("unsupported delete txn")
+		}
+		delete(txn.kv.kvs, string(txn.thenOps[1].KeyBytes()))
+		return &clientv3.TxnResponse{Succeeded: true}, nil
+	}
+
+	// create: If(ModRevision(key) = 0).Then(OpPut(key))
+	// update: If(ModRevision(key) = rev).Then(OpPut(key)).Else(OpGet(key))
+	if len(txn.cmps) != 1 || txn.cmps[0].Target != etcdserverpb.Compare_MOD || txn.cmps[0].Result != etcdserverpb.Compare_EQUAL {
+		return nil, fmt.Errorf("unsupported compare in txn")
+	}
+	key := string(txn.cmps[0].KeyBytes())
+	rev := txn.cmps[0].TargetUnion.(*etcdserverpb.Compare_ModRevision).ModRevision
+	if len(txn.thenOps) != 1 || !txn.thenOps[0].IsPut() || string(txn.thenOps[0].KeyBytes()) != key {
+		return nil, fmt.Errorf("unsupported then ops in txn")
+	}
+	if rev == 0 && len(txn.elseOps) != 0 {
+		return nil, fmt.Errorf("unsupported else ops in create txn")
+	}
+	if rev != 0 && (len(txn.elseOps) != 1 || !txn.elseOps[0].IsGet() || string(txn.elseOps[0].KeyBytes()) != key) {
+		return nil, fmt.Errorf("unsupported else ops in update txn")
+	}
+
+	var current int64
+	if v, ok := txn.kv.kvs[key]; ok {
+		current = v.ModRevision
+	}
+	if current != rev {
+		resp := &clientv3.TxnResponse{Succeeded: false}
+		if rev != 0 {
+			rangeResp := &etcdserverpb.RangeResponse{}
+			if v, ok := txn.kv.kvs[key]; ok {
+				rangeResp.Kvs = []*mvccpb.KeyValue{v}
+			}
+			resp.Responses = []*etcdserverpb.ResponseOp{{Response: &etcdserverpb.ResponseOp_ResponseRange{ResponseRange: rangeResp}}}
+		}
+		return resp, nil
+	}
+	txn.kv.rev++
+	txn.kv.kvs[key] = &mvccpb.KeyValue{Key: []byte(key), Value: txn.thenOps[0].ValueBytes(), ModRevision: txn.kv.rev}
+	return &clientv3.TxnResponse{Succeeded: true}, nil
+}
+
+var _ = Describe("Test KineStorage", func() {
+	var kineStore *kineStorage
+	var kv *fakeKineKV
+	var key1 storage.Key
+	var podObj *v1.Pod
+	var podJson []byte
+	BeforeEach(func() {
+		kv = &fakeKineKV{kvs: map[string]*mvccpb.KeyValue{}}
+		s := &etcdStorage{
+			ctx:    context.Background(),
+			prefix: "/registry",
+			client: &clientv3.Client{KV: kv},
+			mirrorPrefixMap: map[pathType]string{
+				rvType: "/mirror/rv",
+			},
+		}
+		s.localComponentKeyCache = &componentKeyCache{
+			ctx:        context.Background(),
+			filePath:   filepath.Join(keyCacheDir, uuid.New().String()),
+			cache:      map[string]keyCache{},
+			fsOperator: fs.FileSystemOperator{},
+			keyFunc:    s.KeyFunc,
+		}
+		kineStore = &kineStorage{etcdStorage: s}
+
+		var err error
+		key1, err = kineStore.KeyFunc(storage.KeyBuildInfo{
+			Component: "kubelet",
+			Resources: "pods",
+			Version:   "v1",
+			Namespace: "default",
+			Name:      "pod1",
+		})
+		Expect(err).To(BeNil())
+		podObj = &v1.Pod{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Pod",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod1",
+				Namespace:       "default",
+				ResourceVersion: "890",
+			},
+		}
+		podJson, err = json.Marshal(podObj)
+		Expect(err).To(BeNil())
+	})
+
+	Context("Test Create", func() {
+		It("should return ErrKeyExists if key already exists", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			Expect(kineStore.Create(key1, podJson)).To(Equal(storage.ErrKeyExists))
+		})
+		It("should create key and its mirror rv key", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			buf, err := kineStore.Get(key1)
+			Expect(err).To(BeNil())
+			Expect(buf).To(Equal(podJson))
+			Expect(kv.kvs[kineStore.mirrorPath(key1.Key(), rvType)].Value).To(Equal([]byte(fixLenRvString(podObj.ResourceVersion))))
+		})
+	})
+
+	Context("Test Update", func() {
+		It("should return ErrStorageNotFound if key does not exist", func() {
+			_, err := kineStore.Update(key1, podJson, 891)
+			Expect(err).To(Equal(storage.ErrStorageNotFound))
+		})
+		It("should return ErrUpdateConflict and the stored object if rv is not fresher", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			podObj.ResourceVersion = "889"
+			oldPodJson, err := json.Marshal(podObj)
+			Expect(err).To(BeNil())
+			buf, err := kineStore.Update(key1, oldPodJson, 889)
+			Expect(err).To(Equal(storage.ErrUpdateConflict))
+			Expect(buf).To(Equal(podJson))
+		})
+		It("should update key and its mirror rv key if rv is fresher", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			podObj.ResourceVersion = "891"
+			newPodJson, err := json.Marshal(podObj)
+			Expect(err).To(BeNil())
+			buf, err := kineStore.Update(key1, newPodJson, 891)
+			Expect(err).To(BeNil())
+			Expect(buf).To(Equal(newPodJson))
+			Expect(kv.kvs[kineStore.mirrorPath(key1.Key(), rvType)].Value).To(Equal([]byte(fixLenRvUint64(891))))
+		})
+	})
+
+	Context("Test Delete", func() {
+		It("should delete key and its mirror rv key", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			Expect(kineStore.Delete(key1)).To(BeNil())
+			Expect(kv.kvs).To(BeEmpty())
+		})
+		It("should not return error if key does not exist", func() {
+			Expect(kineStore.Delete(key1)).To(BeNil())
+		})
+	})
+
+	Context("Test ReplaceComponentList", func() {
+		It("should replace the keys of component and delete the keys not in list", func() {
+			gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			key2, err := kineStore.KeyFunc(storage.KeyBuildInfo{
+				Component: "kubelet",
+				Resources: "pods",
+				Version:   "v1",
+				Namespace: "default",
+				Name:      "pod2",
+			})
+			Expect(err).To(BeNil())
+			podObj.Name, podObj.ResourceVersion = "pod2", "900"
+			pod2Json, err := json.Marshal(podObj)
+			Expect(err).To(BeNil())
+
+			Expect(kineStore.ReplaceComponentList("kubelet", gvr, "default", map[storage.Key][]byte{key1: podJson})).To(BeNil())
+			Expect(kineStore.ReplaceComponentList("kubelet", gvr, "default", map[storage.Key][]byte{key2: pod2Json})).To(BeNil())
+			_, err = kineStore.Get(key1)
+			Expect(err).To(Equal(storage.ErrStorageNotFound))
+			buf, err := kineStore.Get(key2)
+			Expect(err).To(BeNil())
+			Expect(buf).To(Equal(pod2Json))
+		})
+	})
+
+	Context("Test DeleteComponentResources", func() {
+		It("should delete all keys of component", func() {
+			Expect(kineStore.Create(key1, podJson)).To(BeNil())
+			Expect(kineStore.DeleteComponentResources("kubelet")).To(BeNil())
+			Expect(kv.kvs).To(BeEmpty())
+		})
+	})
+})
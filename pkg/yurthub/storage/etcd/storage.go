@@ -47,6 +47,12 @@ const (
 	defaultMaxReceiveSize         = 100 * 1024 * 1024
 	defaultComponentCacheFileName = "component-key-cache"
 	defaultRvLen                  = 32
+
+	// BackendEtcd is the default storage backend of yurt-coordinator.
+	BackendEtcd = "etcd"
+	// BackendKine is the storage backend of yurt-coordinator which uses kine with SQLite
+	// as a lighter substitute of etcd.
+	BackendKine = "kine"
 )
 
 type pathType string
@@ -63,6 +69,8 @@ type EtcdStorageConfig struct {
 	CaFile        string
 	LocalCacheDir string
 	UnSecure      bool
+	// Backend is the storage backend behind the etcd endpoints, etcd or kine.
+	Backend string
 }
 
 // TODO: consider how to recover the work if it was interrupted because of restart, in
@@ -150,6 +158,9 @@ func NewStorage(ctx context.Context, cfg *EtcdStorageConfig) (storage.Store, err
 
 	go s.clientLifeCycleManagement()
 
+	if cfg.Backend == BackendKine {
+		return &kineStorage{etcdStorage: s}, nil
+	}
 	return s, nil
 }
 
@@ -366,39 +377,11 @@ func (s *etcdStorage) ListResourceKeysOfComponent(component string, gvr schema.G
 }
 
 func (s *etcdStorage) ReplaceComponentList(component string, gvr schema.GroupVersionResource, namespace string, contents map[storage.Key][]byte) error {
-	if component == "" {
-		return storage.ErrEmptyComponent
-	}
-	rootKey, err := s.KeyFunc(storage.KeyBuildInfo{
-		Component: component,
-		Resources: gvr.Resource,
-		Group:     gvr.Group,
-		Version:   gvr.Version,
-		Namespace: namespace,
-	})
+	addedOrUpdated, deleted, err := s.diffComponentList(component, gvr, namespace, contents)
 	if err != nil {
 		return err
 	}
 
-	newKeySet := storageKeySet{}
-	for k := range contents {
-		storageKey, ok := k.(storageKey)
-		if !ok {
-			return storage.ErrUnrecognizedKey
-		}
-		if !strings.HasPrefix(k.Key(), rootKey.Key()) {
-			return storage.ErrInvalidContent
-		}
-		newKeySet[storageKey] = struct{}{}
-	}
-
-	var addedOrUpdated, deleted storageKeySet
-	oldKeySet, loaded := s.localComponentKeyCache.LoadOrStore(component, gvr, newKeySet)
-	addedOrUpdated = newKeySet.Difference(storageKeySet{})
-	if loaded {
-		deleted = oldKeySet.Difference(newKeySet)
-	}
-
 	ops := []clientv3.Op{}
 	for k := range addedOrUpdated {
 		rv, err := getRvOfObject(contents[k])
@@ -450,6 +433,44 @@ func (s *etcdStorage) ReplaceComponentList(component string, gvr schema.GroupVer
 	return nil
 }
 
+// diffComponentList records the new keys of component list in local cache, and returns the keys
+// need to be added or updated and the keys need to be deleted.
+func (s *etcdStorage) diffComponentList(component string, gvr schema.GroupVersionResource, namespace string, contents map[storage.Key][]byte) (storageKeySet, storageKeySet, error) {
+	if component == "" {
+		return nil, nil, storage.ErrEmptyComponent
+	}
+	rootKey, err := s.KeyFunc(storage.KeyBuildInfo{
+		Component: component,
+		Resources: gvr.Resource,
+		Group:     gvr.Group,
+		Version:   gvr.Version,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newKeySet := storageKeySet{}
+	for k := range contents {
+		storageKey, ok := k.(storageKey)
+		if !ok {
+			return nil, nil, storage.ErrUnrecognizedKey
+		}
+		if !strings.HasPrefix(k.Key(), rootKey.Key()) {
+			return nil, nil, storage.ErrInvalidContent
+		}
+		newKeySet[storageKey] = struct{}{}
+	}
+
+	var addedOrUpdated, deleted storageKeySet
+	oldKeySet, loaded := s.localComponentKeyCache.LoadOrStore(component, gvr, newKeySet)
+	addedOrUpdated = newKeySet.Difference(storageKeySet{})
+	if loaded {
+		deleted = oldKeySet.Difference(newKeySet)
+	}
+	return addedOrUpdated, deleted, nil
+}
+
 func (s *etcdStorage) DeleteComponentResources(component string) error {
 	if component == "" {
 		return storage.ErrEmptyComponent
@@ -479,6 +500,39 @@ func (s *etcdStorage) DeleteComponentResources(component string) error {
 	return nil
 }
 
+// DBSize returns the size of backend database of the storage in bytes.
+func (s *etcdStorage) DBSize() (int64, error) {
+	if len(s.clientConfig.Endpoints) == 0 {
+		return 0, fmt.Errorf("no endpoint of storage")
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	statusResp, err := s.client.Status(ctx, s.clientConfig.Endpoints[0])
+	if err != nil {
+		return 0, err
+	}
+	return statusResp.DbSize, nil
+}
+
+// DBSizeInUse returns the size of backend database of the storage which is logically in use in bytes,
+// the space of deleted objects is reclaimed after the storage is compacted. The physical size is
+// returned if the storage doesn't report the size in use, like kine.
+func (s *etcdStorage) DBSizeInUse() (int64, error) {
+	if len(s.clientConfig.Endpoints) == 0 {
+		return 0, fmt.Errorf("no endpoint of storage")
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, defaultTimeout)
+	defer cancel()
+	statusResp, err := s.client.Status(ctx, s.clientConfig.Endpoints[0])
+	if err != nil {
+		return 0, err
+	}
+	if statusResp.DbSizeInUse == 0 {
+		return statusResp.DbSize, nil
+	}
+	return statusResp.DbSizeInUse, nil
+}
+
 func fixLenRvUint64(rv uint64) string {
 	return fmt.Sprintf("%0*d", defaultRvLen, rv)
 }
@@ -29,6 +29,7 @@ const (
 	Version          ClusterInfoType = "version"
 	APIsInfo         ClusterInfoType = "apis"
 	APIResourcesInfo ClusterInfoType = "api-resources"
+	JoinInfo         ClusterInfoType = "join-info"
 	Unknown          ClusterInfoType = "unknown"
 )
 
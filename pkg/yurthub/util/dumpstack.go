@@ -22,14 +22,17 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
-	"syscall"
 
 	"k8s.io/klog/v2"
 )
 
 func SetupDumpStackTrap(logDir string, stopCh <-chan struct{}) {
+	// signal.Notify relays all signals if no signal is specified
+	if len(dumpStackSignals) == 0 {
+		return
+	}
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGUSR1)
+	signal.Notify(c, dumpStackSignals...)
 
 	go func() {
 		for {
@@ -78,6 +78,7 @@ const (
 	YurtHubProxyPort       = 10261
 	YurtHubPort            = 10267
 	YurtHubProxySecurePort = 10268
+	YurtHubJoinInfoPort    = 10269
 )
 
 var (
@@ -0,0 +1,281 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/yurthub/healthchecker"
+	"github.com/openyurtio/openyurt/pkg/yurthub/metrics"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+)
+
+const (
+	defaultConsistencyCheckPeriod  = 10 * time.Second
+	defaultConsistencyResyncPeriod = 30 * time.Minute
+
+	// inconsistencyStale means the object in yurt-coordinator has been deleted in cloud.
+	inconsistencyStale = "stale"
+	// inconsistencyOutdated means the object in yurt-coordinator is older than the one in cloud.
+	inconsistencyOutdated = "outdated"
+	// inconsistencyMissing means the object in cloud is not in yurt-coordinator.
+	inconsistencyMissing = "missing"
+)
+
+var (
+	podGVR  = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	nodeGVR = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+)
+
+// consistencyChecker is used by the leader yurthub to compare the nodes and pods of the pool uploaded
+// into yurt-coordinator with the ones in cloud, and repair the drift. The objects are uploaded from the
+// local cache of yurthubs, so they may be deleted or changed in cloud while the pool is disconnected
+// from cloud. It checks when it's started, every time the cloud becomes healthy again and periodically.
+type consistencyChecker struct {
+	ctx          context.Context
+	nodePoolName string
+	period       time.Duration
+	resyncPeriod time.Duration
+	// cloudClient is a dynamic client of cloud APIServer which is proxied by yurthub.
+	cloudClient        dynamic.Interface
+	cloudHealthChecker healthchecker.MultipleBackendsHealthChecker
+	getEtcdStore       func() storage.Store
+	isRunning          bool
+	cancel             func()
+}
+
+func (c *consistencyChecker) EnsureStart() {
+	if !c.isRunning {
+		ctx, cancel := context.WithCancel(c.ctx)
+		go c.run(ctx)
+		c.cancel = cancel
+		c.isRunning = true
+	}
+}
+
+func (c *consistencyChecker) EnsureStop() {
+	if c.isRunning {
+		c.cancel()
+		c.cancel = nil
+		c.isRunning = false
+	}
+}
+
+func (c *consistencyChecker) run(ctx context.Context) {
+	var lastCheckTime time.Time
+	// needCheck is true at the beginning and after the cloud is unhealthy
+	needCheck := true
+	wait.Until(func() {
+		if !c.cloudHealthChecker.IsHealthy() {
+			needCheck = true
+			return
+		}
+		if !needCheck && time.Since(lastCheckTime) < c.resyncPeriod {
+			return
+		}
+
+		if err := c.check(ctx); err != nil {
+			klog.Errorf("could not check consistency of yurt-coordinator, %v", err)
+			return
+		}
+		needCheck = false
+		lastCheckTime = time.Now()
+	}, c.period, ctx.Done())
+}
+
+// check compares nodes and pods of the pool in yurt-coordinator with cloud, and repairs the
+// stale, outdated and missing objects in yurt-coordinator.
+func (c *consistencyChecker) check(ctx context.Context) error {
+	if len(c.nodePoolName) == 0 {
+		return nil
+	}
+	etcdStore := c.getEtcdStore()
+	if etcdStore == nil {
+		return fmt.Errorf("got empty etcd storage")
+	}
+
+	nodes, err := c.cloudClient.Resource(nodeGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{apps.NodePoolLabel: c.nodePoolName}.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("could not list nodes of pool %s from cloud, %v", c.nodePoolName, err)
+	}
+	nodeNames := make(map[string]struct{}, len(nodes.Items))
+	for i := range nodes.Items {
+		nodeNames[nodes.Items[i].GetName()] = struct{}{}
+	}
+
+	pods := make([]unstructured.Unstructured, 0)
+	for nodeName := range nodeNames {
+		podList, err := c.cloudClient.Resource(podGVR).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not list pods of node %s from cloud, %v", nodeName, err)
+		}
+		for i := range podList.Items {
+			if podNodeName, _, _ := unstructured.NestedString(podList.Items[i].Object, "spec", "nodeName"); podNodeName == nodeName {
+				pods = append(pods, podList.Items[i])
+			}
+		}
+	}
+
+	if err := c.repair(etcdStore, nodeGVR, "Node", nodes.Items); err != nil {
+		return err
+	}
+	return c.repair(etcdStore, podGVR, "Pod", pods)
+}
+
+// repair makes the objects of gvr in yurt-coordinator consistent with the objects in cloud.
+func (c *consistencyChecker) repair(etcdStore storage.Store, gvr schema.GroupVersionResource, kind string, cloudObjs []unstructured.Unstructured) error {
+	rootKey, err := etcdStore.KeyFunc(storage.KeyBuildInfo{
+		Component: "kubelet",
+		Group:     gvr.Group,
+		Version:   gvr.Version,
+		Resources: gvr.Resource,
+	})
+	if err != nil {
+		return fmt.Errorf("could not get root key of %s, %v", gvr.String(), err)
+	}
+	contents, err := etcdStore.List(rootKey)
+	if err != nil && err != storage.ErrStorageNotFound {
+		return fmt.Errorf("could not list %s from yurt-coordinator, %v", gvr.String(), err)
+	}
+
+	coordinatorObjs := make(map[string]*unstructured.Unstructured, len(contents))
+	for _, content := range contents {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(content); err != nil {
+			klog.Errorf("could not decode %s in yurt-coordinator, %v", gvr.String(), err)
+			continue
+		}
+		// the root key is also the prefix of other resources, like podtemplates
+		if obj.GetKind() != kind {
+			continue
+		}
+		coordinatorObjs[objectKey(obj)] = obj
+	}
+
+	counts := map[string]int{}
+	for i := range cloudObjs {
+		cloudObj := &cloudObjs[i]
+		key, err := c.keyOf(etcdStore, gvr, cloudObj)
+		if err != nil {
+			return err
+		}
+		content, err := json.Marshal(cloudObj)
+		if err != nil {
+			return fmt.Errorf("could not encode %s, %v", objectKey(cloudObj), err)
+		}
+
+		coordinatorObj, ok := coordinatorObjs[objectKey(cloudObj)]
+		delete(coordinatorObjs, objectKey(cloudObj))
+		switch {
+		case !ok:
+			if err := etcdStore.Create(key, content); err != nil && err != storage.ErrKeyExists {
+				return fmt.Errorf("could not create %s %s in yurt-coordinator, %v", gvr.Resource, objectKey(cloudObj), err)
+			}
+			counts[inconsistencyMissing]++
+		case coordinatorObj.GetUID() != cloudObj.GetUID():
+			// the object is recreated in cloud
+			if err := etcdStore.Delete(key); err != nil {
+				return fmt.Errorf("could not delete %s %s in yurt-coordinator, %v", gvr.Resource, objectKey(cloudObj), err)
+			}
+			if err := etcdStore.Create(key, content); err != nil && err != storage.ErrKeyExists {
+				return fmt.Errorf("could not create %s %s in yurt-coordinator, %v", gvr.Resource, objectKey(cloudObj), err)
+			}
+			counts[inconsistencyStale]++
+		case isNewerResourceVersion(cloudObj.GetResourceVersion(), coordinatorObj.GetResourceVersion()):
+			rv, _ := strconv.ParseUint(cloudObj.GetResourceVersion(), 10, 64)
+			if _, err := etcdStore.Update(key, content, rv); err != nil && err != storage.ErrUpdateConflict {
+				return fmt.Errorf("could not update %s %s in yurt-coordinator, %v", gvr.Resource, objectKey(cloudObj), err)
+			}
+			counts[inconsistencyOutdated]++
+		}
+	}
+
+	// the remaining objects are deleted in cloud or not in the pool any more
+	for _, obj := range coordinatorObjs {
+		key, err := c.keyOf(etcdStore, gvr, obj)
+		if err != nil {
+			return err
+		}
+		if err := etcdStore.Delete(key); err != nil {
+			return fmt.Errorf("could not delete %s %s in yurt-coordinator, %v", gvr.Resource, objectKey(obj), err)
+		}
+		counts[inconsistencyStale]++
+	}
+
+	for inconsistency, cnt := range counts {
+		metrics.Metrics.AddYurtCoordinatorInconsistentObjects(gvr.Resource, inconsistency, cnt)
+	}
+	if len(counts) != 0 {
+		klog.Infof("repaired %s in yurt-coordinator, stale: %d, outdated: %d, missing: %d",
+			gvr.Resource, counts[inconsistencyStale], counts[inconsistencyOutdated], counts[inconsistencyMissing])
+	}
+	return nil
+}
+
+func (c *consistencyChecker) keyOf(etcdStore storage.Store, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) (storage.Key, error) {
+	key, err := etcdStore.KeyFunc(storage.KeyBuildInfo{
+		Component: "kubelet",
+		Group:     gvr.Group,
+		Version:   gvr.Version,
+		Resources: gvr.Resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get key of %s %s, %v", gvr.Resource, objectKey(obj), err)
+	}
+	return key, nil
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	if len(obj.GetNamespace()) == 0 {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// isNewerResourceVersion returns true if rv is newer than the other one, the resource versions
+// which are not integers are considered as not newer.
+func isNewerResourceVersion(rv, other string) bool {
+	rvInt, err := strconv.ParseUint(rv, 10, 64)
+	if err != nil {
+		return false
+	}
+	otherInt, err := strconv.ParseUint(other, 10, 64)
+	if err != nil {
+		return true
+	}
+	return rvInt > otherInt
+}
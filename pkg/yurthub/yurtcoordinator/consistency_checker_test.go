@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
+)
+
+func newTestPod(name, nodeName, uid, rv string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(uid), ResourceVersion: rv},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func storeObject(t *testing.T, store storage.Store, resource string, obj metav1.Object) {
+	key, err := store.KeyFunc(storage.KeyBuildInfo{
+		Component: "kubelet",
+		Version:   "v1",
+		Resources: resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	})
+	if err != nil {
+		t.Fatalf("could not get key, %v", err)
+	}
+	content, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("could not encode object, %v", err)
+	}
+	if err := store.Create(key, content); err != nil {
+		t.Fatalf("could not create object, %v", err)
+	}
+}
+
+func getStoredPod(t *testing.T, store storage.Store, name string) *corev1.Pod {
+	key, err := store.KeyFunc(storage.KeyBuildInfo{
+		Component: "kubelet",
+		Version:   "v1",
+		Resources: "pods",
+		Namespace: "default",
+		Name:      name,
+	})
+	if err != nil {
+		t.Fatalf("could not get key, %v", err)
+	}
+	content, err := store.Get(key)
+	if err == storage.ErrStorageNotFound {
+		return nil
+	} else if err != nil {
+		t.Fatalf("could not get pod %s, %v", name, err)
+	}
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(content, pod); err != nil {
+		t.Fatalf("could not decode pod %s, %v", name, err)
+	}
+	return pod
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	store, err := disk.NewDiskStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create storage, %v", err)
+	}
+	node := &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a", UID: "node-a", ResourceVersion: "10", Labels: map[string]string{apps.NodePoolLabel: "hangzhou"}},
+	}
+	storeObject(t, store, "nodes", node)
+	// outdated pod
+	storeObject(t, store, "pods", newTestPod("pod-a", "node-a", "pod-a", "10"))
+	// pod deleted in cloud
+	storeObject(t, store, "pods", newTestPod("pod-b", "node-a", "pod-b", "10"))
+	// pod recreated in cloud
+	storeObject(t, store, "pods", newTestPod("pod-c", "node-a", "pod-c", "10"))
+	// pod up to date
+	storeObject(t, store, "pods", newTestPod("pod-e", "node-a", "pod-e", "10"))
+
+	cloudObjs := []runtime.Object{
+		node,
+		newTestPod("pod-a", "node-a", "pod-a", "20"),
+		newTestPod("pod-c", "node-a", "pod-c-new", "20"),
+		newTestPod("pod-d", "node-a", "pod-d", "20"),
+		newTestPod("pod-e", "node-a", "pod-e", "10"),
+		// pod of node out of the pool
+		newTestPod("pod-f", "node-f", "pod-f", "20"),
+	}
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("could not add scheme, %v", err)
+	}
+	checker := &consistencyChecker{
+		ctx:          context.Background(),
+		nodePoolName: "hangzhou",
+		cloudClient:  dynamicfake.NewSimpleDynamicClient(scheme, cloudObjs...),
+		getEtcdStore: func() storage.Store { return store },
+	}
+	if err := checker.check(context.TODO()); err != nil {
+		t.Fatalf("could not check consistency, %v", err)
+	}
+
+	expected := map[string]types.UID{
+		"pod-a": "pod-a",
+		"pod-c": "pod-c-new",
+		"pod-d": "pod-d",
+		"pod-e": "pod-e",
+	}
+	for _, name := range []string{"pod-a", "pod-b", "pod-c", "pod-d", "pod-e", "pod-f"} {
+		pod := getStoredPod(t, store, name)
+		uid, ok := expected[name]
+		if !ok {
+			if pod != nil {
+				t.Errorf("expect pod %s is deleted from yurt-coordinator", name)
+			}
+			continue
+		}
+		if pod == nil || pod.UID != uid {
+			t.Errorf("expect pod %s with uid %s in yurt-coordinator, but got %v", name, uid, pod)
+			continue
+		}
+		if name != "pod-e" && pod.ResourceVersion != "20" {
+			t.Errorf("expect pod %s is updated, but got rv %s", name, pod.ResourceVersion)
+		}
+	}
+}
@@ -29,5 +29,7 @@ var (
 
 const (
 	DefaultPoolScopedUserAgent      = "leader-yurthub"
+	StatusReporterUserAgent         = "yurt-coordinator-status-reporter"
+	ConsistencyCheckerUserAgent     = "yurt-coordinator-consistency-checker"
 	YurtCoordinatorClientSecretName = "yurt-coordinator-yurthub-certs"
 )
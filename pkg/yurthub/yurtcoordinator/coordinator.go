@@ -28,6 +28,7 @@ import (
 	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -42,6 +43,8 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/cmd/yurthub/app/config"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurthub/cachemanager"
 	"github.com/openyurtio/openyurt/pkg/yurthub/healthchecker"
 	"github.com/openyurtio/openyurt/pkg/yurthub/kubernetes/meta"
@@ -96,6 +99,8 @@ type coordinator struct {
 	serializerMgr     *serializer.SerializerManager
 	restConfigMgr     *yurtrest.RestConfigManager
 	etcdStorageCfg    *etcd.EtcdStorageConfig
+	nodePoolName      string
+	nodePoolLister    cache.GenericLister
 	poolCacheManager  cachemanager.CacheManager
 	diskStorage       storage.Store
 	etcdStorage       storage.Store
@@ -122,6 +127,14 @@ type coordinator struct {
 	// node lease contains DelegateHeartBeat label, it will triger the eventhandler which will
 	// use cloud client to send it to cloud APIServer.
 	delegateNodeLeaseManager *coordinatorLeaseInformerManager
+	// leaseDelegator sends the delegated node leases to cloud APIServer with jitter and rate limit.
+	leaseDelegator *leaseDelegator
+	// statusReporter reports the status of yurt-coordinator to metrics and cloud for the leader yurthub.
+	statusReporter *statusReporter
+	// consistencyChecker repairs the drift of objects uploaded into yurt-coordinator from cloud for the leader yurthub.
+	consistencyChecker *consistencyChecker
+	// storageQuotaEnforcer evicts objects from yurt-coordinator by priority when its storage exceeds the quota.
+	storageQuotaEnforcer *storageQuotaEnforcer
 }
 
 func NewCoordinator(
@@ -156,6 +169,7 @@ func NewCoordinator(
 		cloudCAFilePath:    cfg.CertManager.GetCaFile(),
 		cloudHealthChecker: cloudHealthChecker,
 		etcdStorageCfg:     etcdStorageCfg,
+		nodePoolName:       cfg.NodePoolName,
 		restConfigMgr:      restMgr,
 		certMgr:            certMgr,
 		informerFactory:    cfg.SharedFactory,
@@ -192,7 +206,7 @@ func NewCoordinator(
 	}
 
 	// init pool scope resources
-	resources.InitPoolScopeResourcesManger(proxiedClient, cfg.SharedFactory)
+	resources.InitPoolScopeResourcesManger(proxiedClient, cfg.SharedFactory, cfg.NodePoolName)
 
 	dynamicClient, err := buildDynamicClientWithUserAgent(fmt.Sprintf("http://%s", cfg.YurtHubProxyServerAddr), constants.DefaultPoolScopedUserAgent)
 	if err != nil {
@@ -207,8 +221,52 @@ func NewCoordinator(
 		getEtcdStore:      coordinator.getEtcdStore,
 	}
 
+	// the storage backend of yurt-coordinator is specified by the label of NodePool
+	coordinator.nodePoolLister = cfg.NodePoolInformerFactory.ForResource(v1beta1.GroupVersion.WithResource("nodepools")).Lister()
+	cfg.NodePoolInformerFactory.Start(ctx.Done())
+
+	statusClient, err := buildDynamicClientWithUserAgent(fmt.Sprintf("http://%s", cfg.YurtHubProxyServerAddr), constants.StatusReporterUserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for status reporter, %v", err)
+	}
+	coordinator.statusReporter = &statusReporter{
+		ctx:               ctx,
+		nodeName:          cfg.NodeName,
+		nodePoolName:      cfg.NodePoolName,
+		period:            defaultStatusReportPeriod,
+		coordinatorClient: coordinatorClient,
+		cloudClient:       statusClient,
+		getEtcdStore:      coordinator.getEtcdStore,
+		getStorageBackend: coordinator.getStorageBackend,
+		getNodePool:       coordinator.getNodePool,
+		isPoolCacheSynced: coordinator.poolCacheSynced,
+	}
+
+	consistencyClient, err := buildDynamicClientWithUserAgent(fmt.Sprintf("http://%s", cfg.YurtHubProxyServerAddr), constants.ConsistencyCheckerUserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for consistency checker, %v", err)
+	}
+	coordinator.consistencyChecker = &consistencyChecker{
+		ctx:                ctx,
+		nodePoolName:       cfg.NodePoolName,
+		period:             defaultConsistencyCheckPeriod,
+		resyncPeriod:       defaultConsistencyResyncPeriod,
+		cloudClient:        consistencyClient,
+		cloudHealthChecker: cloudHealthChecker,
+		getEtcdStore:       coordinator.getEtcdStore,
+	}
+
+	coordinator.storageQuotaEnforcer = &storageQuotaEnforcer{
+		ctx:          ctx,
+		quota:        cfg.CoordinatorStorageQuota,
+		period:       defaultStorageQuotaCheckPeriod,
+		cooldown:     defaultStorageEvictionCooldown,
+		getEtcdStore: coordinator.getEtcdStore,
+	}
+
 	coordinator.poolCacheSyncedDetector = poolCacheSyncedDetector
 	coordinator.delegateNodeLeaseManager = delegateNodeLeaseManager
+	coordinator.leaseDelegator = newLeaseDelegator(ctx, cfg.DelegateLeaseJitter, cfg.DelegateLeaseQPS, cfg.DelegateLeaseBurst, delegateNodeLease)
 	coordinator.poolCacheSyncManager = poolScopedCacheSyncManager
 
 	return coordinator, nil
@@ -231,7 +289,11 @@ func (coordinator *coordinator) Run() {
 		case <-coordinator.ctx.Done():
 			coordinator.poolCacheSyncManager.EnsureStop()
 			coordinator.delegateNodeLeaseManager.EnsureStop()
+			coordinator.leaseDelegator.EnsureStop()
 			coordinator.poolCacheSyncedDetector.EnsureStop()
+			coordinator.statusReporter.EnsureStop()
+			coordinator.consistencyChecker.EnsureStop()
+			coordinator.storageQuotaEnforcer.EnsureStop()
 			klog.Info("exit normally in coordinator loop.")
 			return
 		case electorStatus, ok := <-coordinator.hubElector.StatusChan():
@@ -274,7 +336,11 @@ func (coordinator *coordinator) Run() {
 			case PendingHub:
 				coordinator.poolCacheSyncManager.EnsureStop()
 				coordinator.delegateNodeLeaseManager.EnsureStop()
+				coordinator.leaseDelegator.EnsureStop()
 				coordinator.poolCacheSyncedDetector.EnsureStop()
+				coordinator.statusReporter.EnsureStop()
+				coordinator.consistencyChecker.EnsureStop()
+				coordinator.storageQuotaEnforcer.EnsureStop()
 				needUploadLocalCache = true
 				needCancelEtcdStorage = true
 				isPoolCacheSynced = false
@@ -304,19 +370,21 @@ func (coordinator *coordinator) Run() {
 					continue
 				}
 				klog.Infof("coordinator newCloudLeaseClient success.")
+				coordinator.leaseDelegator.EnsureStart(nodeLeaseProxyClient)
 				coordinator.delegateNodeLeaseManager.EnsureStartWithHandler(cache.FilteringResourceEventHandler{
 					FilterFunc: ifDelegateHeartBeat,
 					Handler: cache.ResourceEventHandlerFuncs{
-						AddFunc: func(obj interface{}) {
-							coordinator.delegateNodeLease(nodeLeaseProxyClient, obj)
-						},
+						AddFunc: coordinator.leaseDelegator.Enqueue,
 						UpdateFunc: func(_, newObj interface{}) {
-							coordinator.delegateNodeLease(nodeLeaseProxyClient, newObj)
+							coordinator.leaseDelegator.Enqueue(newObj)
 						},
 					},
 				})
 
 				coordinator.poolCacheSyncedDetector.EnsureStart()
+				coordinator.statusReporter.EnsureStart()
+				coordinator.consistencyChecker.EnsureStart()
+				coordinator.storageQuotaEnforcer.EnsureStart()
 
 				if coordinator.needUploadLocalCache {
 					if err := coordinator.uploadLocalCache(etcdStorage); err != nil {
@@ -335,6 +403,10 @@ func (coordinator *coordinator) Run() {
 
 				coordinator.poolCacheSyncManager.EnsureStop()
 				coordinator.delegateNodeLeaseManager.EnsureStop()
+				coordinator.leaseDelegator.EnsureStop()
+				coordinator.statusReporter.EnsureStop()
+				coordinator.consistencyChecker.EnsureStop()
+				coordinator.storageQuotaEnforcer.EnsureStop()
 				coordinator.poolCacheSyncedDetector.EnsureStart()
 
 				if coordinator.needUploadLocalCache {
@@ -397,7 +469,9 @@ func (coordinator *coordinator) IsHealthy() (cachemanager.CacheManager, bool) {
 
 func (coordinator *coordinator) buildPoolCacheStore() (cachemanager.CacheManager, storage.Store, func(), error) {
 	ctx, cancel := context.WithCancel(coordinator.ctx)
-	etcdStore, err := etcd.NewStorage(ctx, coordinator.etcdStorageCfg)
+	etcdStorageCfg := *coordinator.etcdStorageCfg
+	etcdStorageCfg.Backend = coordinator.getStorageBackend()
+	etcdStore, err := etcd.NewStorage(ctx, &etcdStorageCfg)
 	if err != nil {
 		cancel()
 		return nil, nil, nil, fmt.Errorf("failed to create etcd storage, %v", err)
@@ -411,6 +485,37 @@ func (coordinator *coordinator) buildPoolCacheStore() (cachemanager.CacheManager
 	return poolCacheManager, etcdStore, cancel, nil
 }
 
+// getStorageBackend returns the storage backend of yurt-coordinator specified by the label of NodePool,
+// etcd is used if the label is not set or the NodePool can not be got.
+func (coordinator *coordinator) getStorageBackend() string {
+	if len(coordinator.nodePoolName) == 0 {
+		return etcd.BackendEtcd
+	}
+	nodePool, err := coordinator.getNodePool()
+	if err != nil {
+		klog.Warningf("could not get nodepool %s for storage backend of yurt-coordinator, %v", coordinator.nodePoolName, err)
+		return etcd.BackendEtcd
+	}
+	if nodePool.GetLabels()[apps.NodePoolCoordinatorStorageLabel] == etcd.BackendKine {
+		return etcd.BackendKine
+	}
+	return etcd.BackendEtcd
+}
+
+func (coordinator *coordinator) getNodePool() (metav1.Object, error) {
+	obj, err := coordinator.nodePoolLister.Get(coordinator.nodePoolName)
+	if err != nil {
+		return nil, err
+	}
+	return apimeta.Accessor(obj)
+}
+
+func (coordinator *coordinator) poolCacheSynced() bool {
+	coordinator.Lock()
+	defer coordinator.Unlock()
+	return coordinator.isPoolCacheSynced
+}
+
 func (coordinator *coordinator) getEtcdStore() storage.Store {
 	return coordinator.etcdStorage
 }
@@ -449,15 +554,14 @@ func (coordinator *coordinator) uploadLocalCache(etcdStore storage.Store) error
 	return nil
 }
 
-func (coordinator *coordinator) delegateNodeLease(cloudLeaseClient coordclientset.LeaseInterface, obj interface{}) {
-	newLease := obj.(*coordinationv1.Lease)
+func delegateNodeLease(ctx context.Context, cloudLeaseClient coordclientset.LeaseInterface, newLease *coordinationv1.Lease) {
 	for i := 0; i < leaseDelegateRetryTimes; i++ {
 		// ResourceVersions of lease objects in yurt-coordinator always have different rv
 		// from what of cloud lease. So we should get cloud lease first and then update
 		// it with lease from yurt-coordinator.
-		cloudLease, err := cloudLeaseClient.Get(coordinator.ctx, newLease.Name, metav1.GetOptions{})
+		cloudLease, err := cloudLeaseClient.Get(ctx, newLease.Name, metav1.GetOptions{})
 		if apierrors.IsNotFound(err) {
-			if _, err := cloudLeaseClient.Create(coordinator.ctx, cloudLease, metav1.CreateOptions{}); err != nil {
+			if _, err := cloudLeaseClient.Create(ctx, cloudLease, metav1.CreateOptions{}); err != nil {
 				klog.Errorf("failed to create lease %s at cloud, %v", newLease.Name, err)
 				continue
 			}
@@ -465,14 +569,16 @@ func (coordinator *coordinator) delegateNodeLease(cloudLeaseClient coordclientse
 
 		cloudLease.Annotations = newLease.Annotations
 		cloudLease.Spec.RenewTime = newLease.Spec.RenewTime
-		if updatedLease, err := cloudLeaseClient.Update(coordinator.ctx, cloudLease, metav1.UpdateOptions{}); err != nil {
+		if updatedLease, err := cloudLeaseClient.Update(ctx, cloudLease, metav1.UpdateOptions{}); err != nil {
 			klog.Errorf("failed to update lease %s at cloud, %v", newLease.Name, err)
 			continue
 		} else {
 			klog.V(2).Infof("delegate node lease for %s", updatedLease.Name)
 		}
-		break
+		metrics.Metrics.IncYurtCoordinatorDelegateLeaseCounter("success")
+		return
 	}
+	metrics.Metrics.IncYurtCoordinatorDelegateLeaseCounter("failure")
 }
 
 // poolScopedCacheSyncManager will continuously sync pool-scoped resources from cloud to yurt-coordinator.
@@ -511,14 +617,25 @@ func (p *poolScopedCacheSyncManager) EnsureStart() error {
 
 		ctx, cancel := context.WithCancel(p.ctx)
 		hasInformersSynced := []cache.InformerSynced{}
-		dynamicInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(p.dynamicClient, 0, metav1.NamespaceAll, nil)
+		// the resources with the same label selector share an informer factory.
+		dynamicInformerFactories := make(map[string]dynamicinformer.DynamicSharedInformerFactory)
 		for _, gvr := range resources.GetPoolScopeResources() {
-			klog.Infof("coordinator informer with resources gvr %+v registered", gvr)
-			informer := dynamicInformerFactory.ForResource(gvr)
+			selector := resources.GetPoolScopeResourceLabelSelector(gvr)
+			factory, ok := dynamicInformerFactories[selector]
+			if !ok {
+				factory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(p.dynamicClient, 0, metav1.NamespaceAll, func(opts *metav1.ListOptions) {
+					opts.LabelSelector = selector
+				})
+				dynamicInformerFactories[selector] = factory
+			}
+			klog.Infof("coordinator informer with resources gvr %+v and label selector %q registered", gvr, selector)
+			informer := factory.ForResource(gvr)
 			hasInformersSynced = append(hasInformersSynced, informer.Informer().HasSynced)
 		}
 
-		dynamicInformerFactory.Start(ctx.Done())
+		for _, factory := range dynamicInformerFactories {
+			factory.Start(ctx.Done())
+		}
 		go p.holdInformerSync(ctx, hasInformersSynced)
 		p.cancel = cancel
 		p.isRunning = true
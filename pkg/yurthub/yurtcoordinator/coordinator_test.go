@@ -24,10 +24,16 @@ import (
 
 	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/pointer"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/etcd"
 )
 
 var leaseGVR = schema.GroupVersionResource{
@@ -237,3 +243,52 @@ func TestIfInformerSyncLease(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStorageBackend(t *testing.T) {
+	cases := []struct {
+		Description  string
+		NodePoolName string
+		Labels       map[string]string
+		Expect       string
+	}{
+		{
+			Description:  "return kine if it is specified by nodepool label",
+			NodePoolName: "hangzhou",
+			Labels:       map[string]string{apps.NodePoolCoordinatorStorageLabel: etcd.BackendKine},
+			Expect:       etcd.BackendKine,
+		},
+		{
+			Description:  "return etcd if nodepool label is not set",
+			NodePoolName: "hangzhou",
+			Expect:       etcd.BackendEtcd,
+		},
+		{
+			Description:  "return etcd if nodepool does not exist",
+			NodePoolName: "beijing",
+			Expect:       etcd.BackendEtcd,
+		},
+		{
+			Description: "return etcd if node is not in nodepool",
+			Expect:      etcd.BackendEtcd,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			nodePool := &unstructured.Unstructured{}
+			nodePool.SetName("hangzhou")
+			nodePool.SetLabels(c.Labels)
+			if err := indexer.Add(nodePool); err != nil {
+				t.Fatalf("could not add nodepool, %v", err)
+			}
+			coordinator := &coordinator{
+				nodePoolName:   c.NodePoolName,
+				nodePoolLister: cache.NewGenericLister(indexer, v1beta1.GroupVersion.WithResource("nodepools").GroupResource()),
+			}
+			if got := coordinator.getStorageBackend(); got != c.Expect {
+				t.Errorf("unexpected storage backend for %s, want: %v, got: %v", c.Description, c.Expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// candidateLeasePrefix is the name prefix of leases which are used by yurthubs
+	// to announce that they are healthy candidates of leader election.
+	candidateLeasePrefix = "yurthub-candidate-"
+	// labelCandidate is the label of candidate leases, only the candidate leases
+	// are watched by the yurthubs instead of all leases in the namespace.
+	labelCandidate = "openyurt.io/hub-candidate"
+
+	annotationCandidateLoad      = "openyurt.io/hub-candidate-load"
+	annotationCandidateUptime    = "openyurt.io/hub-candidate-uptime"
+	annotationCandidateRecentLed = "openyurt.io/hub-candidate-recently-led"
+)
+
+// candidate is a yurthub which is healthy and takes part in the leader election.
+type candidate struct {
+	name   string
+	load   float64
+	uptime time.Duration
+	// recentlyLed is true if the yurthub has been leader within the last leader term,
+	// the other candidates are preferred so the leadership rotates among all candidates.
+	recentlyLed bool
+}
+
+// less returns true if c is preferred to be leader over the other candidate.
+func (c candidate) less(other candidate) bool {
+	if c.recentlyLed != other.recentlyLed {
+		return !c.recentlyLed
+	}
+	if stable, otherStable := c.uptime >= minStableUptime, other.uptime >= minStableUptime; stable != otherStable {
+		return stable
+	}
+	if c.load != other.load {
+		return c.load < other.load
+	}
+	return c.name < other.name
+}
+
+var candidateSelector = labels.SelectorFromSet(labels.Set{labelCandidate: "true"})
+
+// candidateRegistry registers the yurthub as a candidate in yurt-coordinator with a lease,
+// and lists the candidates whose leases are renewed recently. the candidate leases are
+// watched by an informer, so listing candidates doesn't send requests to yurt-coordinator.
+type candidateRegistry struct {
+	client        kubernetes.Interface
+	namespace     string
+	nodeName      string
+	leaseDuration time.Duration
+	now           func() time.Time
+
+	factory     informers.SharedInformerFactory
+	leaseLister coordinationlisters.LeaseNamespaceLister
+	leaseSynced cache.InformerSynced
+}
+
+func newCandidateRegistry(client kubernetes.Interface, namespace, nodeName string, leaseDuration time.Duration) *candidateRegistry {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = candidateSelector.String()
+		}))
+	leaseInformer := factory.Coordination().V1().Leases()
+	return &candidateRegistry{
+		client:        client,
+		namespace:     namespace,
+		nodeName:      nodeName,
+		leaseDuration: leaseDuration,
+		now:           time.Now,
+		factory:       factory,
+		leaseLister:   leaseInformer.Lister().Leases(namespace),
+		leaseSynced:   leaseInformer.Informer().HasSynced,
+	}
+}
+
+// start starts watching the candidate leases until stopCh is closed.
+func (r *candidateRegistry) start(stopCh <-chan struct{}) {
+	r.factory.Start(stopCh)
+}
+
+// register creates or renews the candidate lease of yurthub with its current load and uptime.
+func (r *candidateRegistry) register(ctx context.Context, recentlyLed bool) error {
+	annotations := map[string]string{
+		annotationCandidateRecentLed: strconv.FormatBool(recentlyLed),
+	}
+	if load, err := loadAverage(); err != nil {
+		klog.Warningf("could not get load average, %v", err)
+	} else {
+		annotations[annotationCandidateLoad] = strconv.FormatFloat(load, 'f', 2, 64)
+	}
+	if uptime, err := systemUptime(); err != nil {
+		klog.Warningf("could not get system uptime, %v", err)
+	} else {
+		annotations[annotationCandidateUptime] = strconv.FormatInt(int64(uptime.Seconds()), 10)
+	}
+
+	name := candidateLeasePrefix + r.nodeName
+	candidateLabels := map[string]string{labelCandidate: "true"}
+	durationSeconds := int32(r.leaseDuration.Seconds())
+	renewTime := metav1.NewMicroTime(r.now())
+	leases := r.client.CoordinationV1().Leases(r.namespace)
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   r.namespace,
+				Labels:      candidateLabels,
+				Annotations: annotations,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &r.nodeName,
+				LeaseDurationSeconds: &durationSeconds,
+				RenewTime:            &renewTime,
+			},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	lease = lease.DeepCopy()
+	lease.Labels = candidateLabels
+	lease.Annotations = annotations
+	lease.Spec.HolderIdentity = &r.nodeName
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &renewTime
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// unregister deletes the candidate lease, so the yurthub will not be regarded as a candidate.
+func (r *candidateRegistry) unregister(ctx context.Context) error {
+	err := r.client.CoordinationV1().Leases(r.namespace).Delete(ctx, candidateLeasePrefix+r.nodeName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// list returns the candidates whose leases are renewed within the lease duration,
+// sorted from the most preferred one.
+func (r *candidateRegistry) list() ([]candidate, error) {
+	if !r.leaseSynced() {
+		return nil, fmt.Errorf("candidate leases are not synced")
+	}
+	leases, err := r.leaseLister.List(candidateSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	candidates := make([]candidate, 0, len(leases))
+	for _, lease := range leases {
+		if !strings.HasPrefix(lease.Name, candidateLeasePrefix) {
+			continue
+		}
+		duration := r.leaseDuration
+		if lease.Spec.LeaseDurationSeconds != nil {
+			duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+		}
+		if lease.Spec.RenewTime == nil || now.Sub(lease.Spec.RenewTime.Time) > duration {
+			continue
+		}
+
+		c := candidate{name: strings.TrimPrefix(lease.Name, candidateLeasePrefix)}
+		if load, err := strconv.ParseFloat(lease.Annotations[annotationCandidateLoad], 64); err == nil {
+			c.load = load
+		} else {
+			// the candidate with unknown load is least preferred
+			c.load = maxLoadPenalty
+		}
+		if seconds, err := strconv.ParseInt(lease.Annotations[annotationCandidateUptime], 10, 64); err == nil {
+			c.uptime = time.Duration(seconds) * time.Second
+		}
+		c.recentlyLed, _ = strconv.ParseBool(lease.Annotations[annotationCandidateRecentLed])
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].less(candidates[j])
+	})
+	return candidates, nil
+}
+
+// candidateRank returns the rank of the named candidate, 0 means the most preferred one.
+// -1 is returned if the candidate is not found.
+func candidateRank(candidates []candidate, name string) int {
+	for i := range candidates {
+		if candidates[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
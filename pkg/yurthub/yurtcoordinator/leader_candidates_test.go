@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestCandidateRegistry(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset()
+	loads := map[string]float64{"foo": 0.5, "bar": 0.1, "baz": 0.1}
+	uptimes := map[string]time.Duration{"foo": time.Hour, "bar": time.Minute, "baz": time.Hour}
+	defer func(load func() (float64, error), uptime func() (time.Duration, error)) {
+		loadAverage, systemUptime = load, uptime
+	}(loadAverage, systemUptime)
+
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		name := name
+		loadAverage = func() (float64, error) { return loads[name], nil }
+		systemUptime = func() (time.Duration, error) { return uptimes[name], nil }
+		r := newCandidateRegistry(client, "kube-system", name, time.Minute)
+		r.now = func() time.Time { return now }
+		if err := r.register(context.Background(), false); err != nil {
+			t.Fatalf("could not register candidate %s, %v", name, err)
+		}
+		// renew the lease
+		if err := r.register(context.Background(), name == "qux"); err != nil {
+			t.Fatalf("could not renew candidate %s, %v", name, err)
+		}
+	}
+
+	// the lease of an expired candidate and the other leases are ignored
+	expired := metav1.NewMicroTime(now.Add(-2 * time.Minute))
+	for name, leaseLabels := range map[string]map[string]string{
+		candidateLeasePrefix + "quux": {labelCandidate: "true"},
+		"yurthub":                     nil,
+	} {
+		if _, err := client.CoordinationV1().Leases("kube-system").Create(context.Background(), &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: leaseLabels},
+			Spec:       coordinationv1.LeaseSpec{RenewTime: &expired},
+		}, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("could not create lease %s, %v", name, err)
+		}
+	}
+
+	r := newCandidateRegistry(client, "kube-system", "foo", time.Minute)
+	r.now = func() time.Time { return now }
+	if _, err := r.list(); err == nil {
+		t.Errorf("expect error before candidate leases are synced")
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	r.start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, r.leaseSynced) {
+		t.Fatalf("could not sync candidate leases")
+	}
+	candidates, err := r.list()
+	if err != nil {
+		t.Fatalf("could not list candidates, %v", err)
+	}
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		names = append(names, c.name)
+	}
+	// baz is idle and stable, foo is stable, bar is started recently and qux is leader recently.
+	if expect := []string{"baz", "foo", "bar", "qux"}; !reflect.DeepEqual(names, expect) {
+		t.Errorf("expect candidates %v, but got %v", expect, names)
+	}
+	if rank := candidateRank(candidates, "foo"); rank != 1 {
+		t.Errorf("expect rank of foo is 1, but got %d", rank)
+	}
+	if rank := candidateRank(candidates, "quux"); rank != -1 {
+		t.Errorf("expect rank of quux is -1, but got %d", rank)
+	}
+
+	if err := r.unregister(context.Background()); err != nil {
+		t.Fatalf("could not unregister candidate, %v", err)
+	}
+	if err := r.unregister(context.Background()); err != nil {
+		t.Errorf("expect no error when unregistering twice, but got %v", err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		candidates, err := r.list()
+		return err == nil && candidateRank(candidates, "foo") == -1, nil
+	}); err != nil {
+		t.Errorf("expect foo is not a candidate after unregistering")
+	}
+}
+
+func TestOtherCandidates(t *testing.T) {
+	testcases := map[string]struct {
+		rank              int
+		healthyCandidates int
+		expect            int
+	}{
+		"candidates are unknown": {
+			rank:   -1,
+			expect: 0,
+		},
+		"the only candidate": {
+			rank:              0,
+			healthyCandidates: 1,
+			expect:            0,
+		},
+		"not a candidate": {
+			rank:              -1,
+			healthyCandidates: 2,
+			expect:            2,
+		},
+		"other candidates": {
+			rank:              1,
+			healthyCandidates: 3,
+			expect:            2,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			he := &HubElector{}
+			he.setCandidates(tc.rank, tc.healthyCandidates)
+			if n := he.otherCandidates(); n != tc.expect {
+				t.Errorf("expect %d other candidates, but got %d", tc.expect, n)
+			}
+		})
+	}
+}
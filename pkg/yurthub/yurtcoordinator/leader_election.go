@@ -18,14 +18,20 @@ package yurtcoordinator
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/cmd/yurthub/app/config"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurthub/healthchecker"
 )
 
@@ -36,13 +42,43 @@ const (
 	PendingHub
 )
 
+// candidateLeaseFactor is the ratio of the candidate lease duration to the lease
+// duration of leader election, the candidate lease is renewed every lease duration.
+const candidateLeaseFactor = 3
+
 type HubElector struct {
 	coordinatorClient           kubernetes.Interface
 	coordinatorHealthChecker    healthchecker.HealthChecker
 	cloudAPIServerHealthChecker healthchecker.MultipleBackendsHealthChecker
 	electorStatus               chan int32
 	le                          *leaderelection.LeaderElector
-	inElecting                  bool
+	// inElecting is guarded by lock, because it's reset in the callback of leader elector.
+	inElecting bool
+
+	nodeName string
+	// proxiedClient is used for recording the leadership on the node object,
+	// so the leader of the pool can be shown in the NodePool status.
+	proxiedClient kubernetes.Interface
+	// leaderTerm is the duration that the leader holds the leadership before
+	// yielding it, leadership rotation is disabled if it's 0.
+	leaderTerm time.Duration
+	// yieldPeriod is the duration that the previous leader stays out of the election,
+	// so the other candidates can acquire the leadership.
+	yieldPeriod  time.Duration
+	yieldUntil   time.Time
+	lock         sync.Mutex
+	leadingSince time.Time
+	leadingUntil time.Time
+
+	rotationLock *rotationLock
+	// candidates registers the yurthub as a healthy candidate in yurt-coordinator, the
+	// candidates are ranked for deciding the acquisition delay of each of them.
+	candidates        *candidateRegistry
+	candidateRenew    time.Duration
+	lastRegisterTime  time.Time
+	registered        bool
+	rank              int
+	healthyCandidates int
 }
 
 func NewHubElector(
@@ -56,6 +92,14 @@ func NewHubElector(
 		coordinatorHealthChecker:    coordinatorHealthChecker,
 		cloudAPIServerHealthChecker: cloudAPIServerHealthyChecker,
 		electorStatus:               make(chan int32, 1),
+		nodeName:                    cfg.NodeName,
+		proxiedClient:               cfg.ProxiedClient,
+		leaderTerm:                  cfg.HubLeaderTerm,
+		yieldPeriod:                 yieldPeriod(cfg.LeaderElection.RetryPeriod.Duration, cfg.LeaderElection.LeaseDuration.Duration),
+		candidates: newCandidateRegistry(coordinatorClient, cfg.LeaderElection.ResourceNamespace, cfg.NodeName,
+			candidateLeaseFactor*cfg.LeaderElection.LeaseDuration.Duration),
+		candidateRenew: cfg.LeaderElection.LeaseDuration.Duration,
+		rank:           -1,
 	}
 
 	rl, err := resourcelock.New(cfg.LeaderElection.ResourceLock,
@@ -68,20 +112,36 @@ func NewHubElector(
 		return nil, err
 	}
 
+	// the candidates with lower load and longer uptime are preferred to acquire the leadership,
+	// they are ranked among the healthy candidates if the candidates are known.
+	he.rotationLock = newRotationLock(rl, cfg.LeaderElection.LeaseDuration.Duration, func() time.Duration {
+		if rank := he.candidateRank(); rank >= 0 {
+			return rankDelay(rank, cfg.LeaderElection.RetryPeriod.Duration, cfg.LeaderElection.LeaseDuration.Duration)
+		}
+		return acquireDelay(cfg.LeaderElection.RetryPeriod.Duration, cfg.LeaderElection.LeaseDuration.Duration)
+	})
+
+	he.candidates.start(stopCh)
+
 	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
-		Lock:          rl,
-		LeaseDuration: cfg.LeaderElection.LeaseDuration.Duration,
-		RenewDeadline: cfg.LeaderElection.RenewDeadline.Duration,
-		RetryPeriod:   cfg.LeaderElection.RetryPeriod.Duration,
+		Lock:            he.rotationLock,
+		LeaseDuration:   cfg.LeaderElection.LeaseDuration.Duration,
+		RenewDeadline:   cfg.LeaderElection.RenewDeadline.Duration,
+		RetryPeriod:     cfg.LeaderElection.RetryPeriod.Duration,
+		ReleaseOnCancel: true,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
 				klog.Infof("yurthub of %s became leader", cfg.NodeName)
+				he.setLeadingSince(time.Now())
 				he.electorStatus <- LeaderHub
+				he.recordLeadership(true)
 			},
 			OnStoppedLeading: func() {
 				klog.Infof("yurthub of %s is no more a leader", cfg.NodeName)
+				he.setLeadingSince(time.Time{})
 				he.electorStatus <- FollowerHub
-				he.inElecting = false
+				he.setInElecting(false)
+				go he.recordLeadership(false)
 			},
 		},
 	})
@@ -108,33 +168,51 @@ func (he *HubElector) Run(stopCh <-chan struct{}) {
 
 			if cancel != nil {
 				cancel()
-				he.inElecting = false
+				he.setInElecting(false)
 			}
 			return
 		case <-intervalTicker.C:
 			if !he.coordinatorHealthChecker.IsHealthy() {
-				if he.inElecting && cancel != nil {
+				he.setCandidates(-1, 0)
+				if he.isInElecting() && cancel != nil {
 					cancel()
-					he.inElecting = false
+					he.setInElecting(false)
 					he.electorStatus <- PendingHub
 				}
 				break
 			}
 
 			if !he.cloudAPIServerHealthChecker.IsHealthy() {
-				if he.inElecting && cancel != nil {
+				he.unregisterCandidate()
+				if he.isInElecting() && cancel != nil {
 					cancel()
-					he.inElecting = false
+					he.setInElecting(false)
 					he.electorStatus <- FollowerHub
 				}
 				break
 			}
 
-			if !he.inElecting {
+			he.refreshCandidates()
+			if he.isInElecting() && he.termExpired(time.Now()) {
+				if he.otherCandidates() == 0 {
+					klog.V(4).Infof("yurthub of %s keeps the leadership, because there's no other healthy candidate", he.nodeName)
+				} else {
+					klog.Infof("yurthub of %s has been leader for %v, and yields the leadership", he.nodeName, he.leaderTerm)
+					cancel()
+					he.setInElecting(false)
+					he.yieldUntil = time.Now().Add(he.yieldPeriod)
+					break
+				}
+			}
+
+			if !he.isInElecting() {
+				if time.Now().Before(he.yieldUntil) {
+					break
+				}
 				he.electorStatus <- FollowerHub
 				ctx, cancel = context.WithCancel(context.TODO())
 				go he.le.Run(ctx)
-				he.inElecting = true
+				he.setInElecting(true)
 			}
 		}
 	}
@@ -143,3 +221,148 @@ func (he *HubElector) Run(stopCh <-chan struct{}) {
 func (he *HubElector) StatusChan() chan int32 {
 	return he.electorStatus
 }
+
+func (he *HubElector) setInElecting(inElecting bool) {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	he.inElecting = inElecting
+}
+
+func (he *HubElector) isInElecting() bool {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	return he.inElecting
+}
+
+func (he *HubElector) setLeadingSince(t time.Time) {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	if t.IsZero() && !he.leadingSince.IsZero() {
+		he.leadingUntil = time.Now()
+	}
+	he.leadingSince = t
+}
+
+// recentlyLed checks whether the yurthub is leader or has been leader within the last leader term.
+func (he *HubElector) recentlyLed(now time.Time) bool {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	if !he.leadingSince.IsZero() {
+		return true
+	}
+	return !he.leadingUntil.IsZero() && now.Sub(he.leadingUntil) < he.leaderTerm
+}
+
+func (he *HubElector) setCandidates(rank, healthyCandidates int) {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	he.rank = rank
+	he.healthyCandidates = healthyCandidates
+}
+
+// candidateRank returns the rank of yurthub among the healthy candidates, -1 means it's unknown.
+func (he *HubElector) candidateRank() int {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	return he.rank
+}
+
+// otherCandidates returns the number of healthy candidates except the yurthub itself.
+func (he *HubElector) otherCandidates() int {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	if he.rank >= 0 {
+		return he.healthyCandidates - 1
+	}
+	return he.healthyCandidates
+}
+
+// refreshCandidates renews the candidate lease of yurthub and ranks it among the healthy candidates.
+func (he *HubElector) refreshCandidates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	if !he.registered || now.Sub(he.lastRegisterTime) >= he.candidateRenew {
+		if err := he.candidates.register(ctx, he.recentlyLed(now)); err != nil {
+			klog.Errorf("could not register yurthub of %s as candidate of leader election, %v", he.nodeName, err)
+		} else {
+			he.registered = true
+			he.lastRegisterTime = now
+		}
+	}
+
+	candidates, err := he.candidates.list()
+	if err != nil {
+		klog.Errorf("could not list candidates of leader election, %v", err)
+		he.setCandidates(-1, 0)
+		return
+	}
+	he.setCandidates(candidateRank(candidates, he.nodeName), len(candidates))
+}
+
+// unregisterCandidate removes the yurthub from the candidates, because it can not
+// delegate heartbeats to cloud as a leader.
+func (he *HubElector) unregisterCandidate() {
+	he.setCandidates(-1, 0)
+	if !he.registered {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := he.candidates.unregister(ctx); err != nil {
+		klog.Errorf("could not unregister yurthub of %s from candidates of leader election, %v", he.nodeName, err)
+		return
+	}
+	he.registered = false
+}
+
+// termExpired checks whether the leader has held the leadership longer than the leader term.
+func (he *HubElector) termExpired(now time.Time) bool {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	if he.leaderTerm <= 0 || he.leadingSince.IsZero() {
+		return false
+	}
+	return now.Sub(he.leadingSince) >= he.leaderTerm
+}
+
+// recordLeadership adds or removes the hub leader annotation on the node, the annotation
+// is used by nodepool controller for showing the hub leader in the NodePool status.
+func (he *HubElector) recordLeadership(leading bool) {
+	if he.proxiedClient == nil {
+		return
+	}
+
+	since, previous, reason := "null", "null", "null"
+	if leading {
+		since = fmt.Sprintf("%q", time.Now().UTC().Format(time.RFC3339))
+		if previousHolder, handoverReason := he.handover(); len(previousHolder) != 0 {
+			previous = fmt.Sprintf("%q", previousHolder)
+			reason = fmt.Sprintf("%q", handoverReason)
+		}
+	}
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%s,%q:%s,%q:%s}}}`,
+		apps.AnnotationHubLeaderSince, since,
+		apps.AnnotationHubLeaderPrevious, previous,
+		apps.AnnotationHubLeaderHandover, reason)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := he.proxiedClient.CoreV1().Nodes().Patch(ctx, he.nodeName, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		klog.Errorf("could not record hub leadership(%v) on node %s, %v", leading, he.nodeName, err)
+	}
+}
+
+func (he *HubElector) handover() (string, appsv1beta1.HubLeaderHandoverReason) {
+	if he.rotationLock == nil {
+		return "", ""
+	}
+	return he.rotationLock.Handover()
+}
+
+// yieldPeriod returns the duration that the previous leader stays out of the election,
+// it should be longer than the acquisition delay of any candidate.
+func yieldPeriod(retryPeriod, leaseDuration time.Duration) time.Duration {
+	return time.Duration(maxLoadPenalty*float64(retryPeriod)) + 2*leaseDuration
+}
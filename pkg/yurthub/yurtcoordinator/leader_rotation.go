@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+const (
+	// maxLoadPenalty is the upper limit of the normalized load used for delaying acquisition.
+	maxLoadPenalty = 4.0
+	// minStableUptime is the uptime that node is regarded as stable, the nodes
+	// which are started recently are less preferred to be leader.
+	minStableUptime = 10 * time.Minute
+	// rankDelayFactor is the number of retry periods that a candidate waits longer
+	// than the candidate ranked just before it.
+	rankDelayFactor = 2
+)
+
+var (
+	// loadAverage returns the 1-minute load average normalized by the number of cpus.
+	loadAverage = func() (float64, error) {
+		content, err := os.ReadFile("/proc/loadavg")
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(string(content))
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("invalid content of /proc/loadavg: %q", string(content))
+		}
+		load, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, err
+		}
+		return load / float64(runtime.NumCPU()), nil
+	}
+
+	// systemUptime returns the time elapsed since the node is started.
+	systemUptime = func() (time.Duration, error) {
+		content, err := os.ReadFile("/proc/uptime")
+		if err != nil {
+			return 0, err
+		}
+		fields := strings.Fields(string(content))
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("invalid content of /proc/uptime: %q", string(content))
+		}
+		seconds, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+)
+
+// acquireDelay returns how long the candidate should wait before acquiring the leadership
+// after it becomes available. The candidates with higher load or shorter uptime wait longer,
+// so the leadership is more likely to be acquired by the idle and stable nodes.
+func acquireDelay(retryPeriod, leaseDuration time.Duration) time.Duration {
+	var delay time.Duration
+	if load, err := loadAverage(); err != nil {
+		klog.Warningf("could not get load average, %v", err)
+	} else {
+		if load > maxLoadPenalty {
+			load = maxLoadPenalty
+		}
+		delay += time.Duration(load * float64(retryPeriod))
+	}
+
+	if uptime, err := systemUptime(); err != nil {
+		klog.Warningf("could not get system uptime, %v", err)
+	} else if uptime < minStableUptime {
+		delay += leaseDuration
+	}
+	return delay
+}
+
+// rankDelay returns the acquisition delay of the candidate with rank among the healthy candidates,
+// the candidates are ranked by candidate.less, and the delay is not longer than the one of acquireDelay.
+func rankDelay(rank int, retryPeriod, leaseDuration time.Duration) time.Duration {
+	delay := time.Duration(rank*rankDelayFactor) * retryPeriod
+	if maxDelay := time.Duration(maxLoadPenalty*float64(retryPeriod)) + leaseDuration; delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// rotationLock wraps the resource lock of leader election, it defers the acquisition
+// of leadership for a period computed by delayFunc after the leadership becomes available,
+// so the leadership will not be always acquired by the fastest candidate.
+type rotationLock struct {
+	resourcelock.Interface
+	leaseDuration time.Duration
+	delayFunc     func() time.Duration
+	now           func() time.Time
+
+	sync.Mutex
+	observedRawRecord []byte
+	observedTime      time.Time
+	observedHolder    string
+	availableSince    time.Time
+	// lastHolder is the latest holder of leadership, and released is true if the
+	// leadership has been released by it.
+	lastHolder string
+	released   bool
+	// previousHolder and handoverReason describe how the leadership is handed over
+	// to this candidate at the latest acquisition.
+	previousHolder string
+	handoverReason appsv1beta1.HubLeaderHandoverReason
+}
+
+func newRotationLock(lock resourcelock.Interface, leaseDuration time.Duration, delayFunc func() time.Duration) *rotationLock {
+	return &rotationLock{
+		Interface:     lock,
+		leaseDuration: leaseDuration,
+		delayFunc:     delayFunc,
+		now:           time.Now,
+	}
+}
+
+// Get records the holder of leadership and the time when the leadership becomes available,
+// that is the leadership is released by the previous leader or the lease of it is expired.
+// like leader elector, the lease is regarded as expired when it is not renewed for
+// leaseDuration since it's observed, so the clock skew between nodes doesn't matter.
+func (rl *rotationLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	record, raw, err := rl.Interface.Get(ctx)
+
+	rl.Lock()
+	defer rl.Unlock()
+	now := rl.now()
+	if err != nil {
+		if apierrors.IsNotFound(err) && rl.availableSince.IsZero() {
+			rl.availableSince = now
+		}
+		return record, raw, err
+	}
+
+	if !bytes.Equal(raw, rl.observedRawRecord) {
+		rl.observedRawRecord = raw
+		rl.observedTime = now
+	}
+	rl.observedHolder = record.HolderIdentity
+	if len(record.HolderIdentity) != 0 {
+		rl.lastHolder = record.HolderIdentity
+		rl.released = false
+	} else if len(rl.lastHolder) != 0 {
+		rl.released = true
+	}
+	available := len(record.HolderIdentity) == 0 || rl.observedTime.Add(rl.leaseDuration).Before(now)
+	if !available {
+		rl.availableSince = time.Time{}
+	} else if rl.availableSince.IsZero() {
+		rl.availableSince = now
+	}
+	return record, raw, nil
+}
+
+// Create is called when there's no lock object, the delay is also applied for it.
+func (rl *rotationLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if _, err := rl.checkAcquire(); err != nil {
+		return err
+	}
+	if err := rl.Interface.Create(ctx, ler); err != nil {
+		return err
+	}
+	rl.recordHandover()
+	return nil
+}
+
+// Update is used for acquiring, renewing and releasing leadership, only the acquisition
+// of leadership held by others previously is deferred.
+func (rl *rotationLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	var acquiring bool
+	if ler.HolderIdentity == rl.Identity() {
+		var err error
+		if acquiring, err = rl.checkAcquire(); err != nil {
+			return err
+		}
+	}
+	if err := rl.Interface.Update(ctx, ler); err != nil {
+		return err
+	}
+	if acquiring {
+		rl.recordHandover()
+	}
+	return nil
+}
+
+// Handover returns the previous holder and the reason of handover at the latest acquisition
+// of leadership, the previous holder is empty if it's unknown.
+func (rl *rotationLock) Handover() (string, appsv1beta1.HubLeaderHandoverReason) {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.previousHolder, rl.handoverReason
+}
+
+func (rl *rotationLock) recordHandover() {
+	rl.Lock()
+	defer rl.Unlock()
+	rl.previousHolder = rl.lastHolder
+	switch {
+	case len(rl.lastHolder) == 0:
+		rl.handoverReason = ""
+	case rl.released:
+		rl.handoverReason = appsv1beta1.HubLeaderHandoverReleased
+	default:
+		rl.handoverReason = appsv1beta1.HubLeaderHandoverExpired
+	}
+}
+
+// checkAcquire returns true if the leadership is going to be acquired from others,
+// and an error if the acquisition should be deferred.
+func (rl *rotationLock) checkAcquire() (bool, error) {
+	rl.Lock()
+	defer rl.Unlock()
+	if rl.observedHolder == rl.Identity() {
+		// renew the leadership
+		return false, nil
+	}
+
+	now := rl.now()
+	since := rl.availableSince
+	if since.IsZero() {
+		since = now
+	}
+	if delay := rl.delayFunc(); now.Before(since.Add(delay)) {
+		return false, fmt.Errorf("%s defers acquiring leadership for %v", rl.Identity(), delay)
+	}
+	return true, nil
+}
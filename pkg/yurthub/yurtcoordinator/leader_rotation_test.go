@@ -0,0 +1,239 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+type fakeLock struct {
+	identity string
+	record   resourcelock.LeaderElectionRecord
+}
+
+func (fl *fakeLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	raw, _ := json.Marshal(fl.record)
+	record := fl.record
+	return &record, raw, nil
+}
+
+func (fl *fakeLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	fl.record = ler
+	return nil
+}
+
+func (fl *fakeLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	fl.record = ler
+	return nil
+}
+
+func (fl *fakeLock) RecordEvent(string) {}
+
+func (fl *fakeLock) Identity() string {
+	return fl.identity
+}
+
+func (fl *fakeLock) Describe() string {
+	return "fake-lock"
+}
+
+func TestRotationLock(t *testing.T) {
+	now := time.Now()
+	testcases := map[string]struct {
+		holder  string
+		delay   time.Duration
+		elapsed time.Duration
+		isErr   bool
+	}{
+		"renew leadership": {
+			holder: "foo",
+			delay:  time.Minute,
+		},
+		"acquire released leadership without delay": {
+			holder: "",
+		},
+		"defer acquiring released leadership": {
+			holder: "",
+			delay:  time.Minute,
+			isErr:  true,
+		},
+		"acquire released leadership after delay": {
+			holder:  "",
+			delay:   time.Minute,
+			elapsed: 2 * time.Minute,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			fl := &fakeLock{
+				identity: "foo",
+				record: resourcelock.LeaderElectionRecord{
+					HolderIdentity: tc.holder,
+					RenewTime:      metav1.NewTime(now),
+				},
+			}
+			rl := newRotationLock(fl, 15*time.Second, func() time.Duration {
+				return tc.delay
+			})
+			rl.now = func() time.Time { return now }
+			if _, _, err := rl.Get(context.Background()); err != nil {
+				t.Fatalf("could not get record, %v", err)
+			}
+
+			rl.now = func() time.Time { return now.Add(tc.elapsed) }
+			err := rl.Update(context.Background(), resourcelock.LeaderElectionRecord{HolderIdentity: "foo"})
+			if tc.isErr != (err != nil) {
+				t.Errorf("expect error %v, but got %v", tc.isErr, err)
+			}
+		})
+	}
+}
+
+func TestTermExpired(t *testing.T) {
+	now := time.Now()
+	testcases := map[string]struct {
+		term         time.Duration
+		leadingSince time.Time
+		expired      bool
+	}{
+		"rotation is disabled": {
+			term:         0,
+			leadingSince: now.Add(-time.Hour),
+		},
+		"not leader": {
+			term: time.Minute,
+		},
+		"term is not expired": {
+			term:         time.Hour,
+			leadingSince: now.Add(-time.Minute),
+		},
+		"term is expired": {
+			term:         time.Hour,
+			leadingSince: now.Add(-2 * time.Hour),
+			expired:      true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			he := &HubElector{
+				leaderTerm:   tc.term,
+				leadingSince: tc.leadingSince,
+			}
+			if expired := he.termExpired(now); expired != tc.expired {
+				t.Errorf("expect expired %v, but got %v", tc.expired, expired)
+			}
+		})
+	}
+}
+
+func TestRotationLockHandover(t *testing.T) {
+	now := time.Now()
+	testcases := map[string]struct {
+		records        []resourcelock.LeaderElectionRecord
+		elapsed        time.Duration
+		expectPrevious string
+		expectReason   appsv1beta1.HubLeaderHandoverReason
+	}{
+		"no previous leader": {
+			records: []resourcelock.LeaderElectionRecord{{}},
+		},
+		"leadership is released": {
+			records:        []resourcelock.LeaderElectionRecord{{HolderIdentity: "bar"}, {}},
+			expectPrevious: "bar",
+			expectReason:   appsv1beta1.HubLeaderHandoverReleased,
+		},
+		"leadership is expired": {
+			records:        []resourcelock.LeaderElectionRecord{{HolderIdentity: "bar"}},
+			elapsed:        time.Minute,
+			expectPrevious: "bar",
+			expectReason:   appsv1beta1.HubLeaderHandoverExpired,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			fl := &fakeLock{identity: "foo"}
+			rl := newRotationLock(fl, 15*time.Second, func() time.Duration { return 0 })
+			rl.now = func() time.Time { return now }
+			for _, record := range tc.records {
+				fl.record = record
+				if _, _, err := rl.Get(context.Background()); err != nil {
+					t.Fatalf("could not get record, %v", err)
+				}
+			}
+
+			rl.now = func() time.Time { return now.Add(tc.elapsed) }
+			if _, _, err := rl.Get(context.Background()); err != nil {
+				t.Fatalf("could not get record, %v", err)
+			}
+			if err := rl.Update(context.Background(), resourcelock.LeaderElectionRecord{HolderIdentity: "foo"}); err != nil {
+				t.Fatalf("could not acquire leadership, %v", err)
+			}
+			// renewing leadership doesn't change the handover
+			if _, _, err := rl.Get(context.Background()); err != nil {
+				t.Fatalf("could not get record, %v", err)
+			}
+			if err := rl.Update(context.Background(), resourcelock.LeaderElectionRecord{HolderIdentity: "foo"}); err != nil {
+				t.Fatalf("could not renew leadership, %v", err)
+			}
+
+			previous, reason := rl.Handover()
+			if previous != tc.expectPrevious || reason != tc.expectReason {
+				t.Errorf("expect handover from %q(%s), but got %q(%s)", tc.expectPrevious, tc.expectReason, previous, reason)
+			}
+		})
+	}
+}
+
+func TestRankDelay(t *testing.T) {
+	retryPeriod, leaseDuration := 2*time.Second, 15*time.Second
+	testcases := map[string]struct {
+		rank   int
+		expect time.Duration
+	}{
+		"the most preferred candidate": {
+			rank:   0,
+			expect: 0,
+		},
+		"the second candidate": {
+			rank:   1,
+			expect: 4 * time.Second,
+		},
+		"the delay is limited": {
+			rank:   100,
+			expect: 23 * time.Second,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if delay := rankDelay(tc.rank, retryPeriod, leaseDuration); delay != tc.expect {
+				t.Errorf("expect delay %v, but got %v", tc.expect, delay)
+			}
+		})
+	}
+}
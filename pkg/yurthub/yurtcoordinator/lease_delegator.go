@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	coordclientset "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const (
+	leaseDelegateWorkers = 5
+)
+
+type delegateFunc func(ctx context.Context, cloudLeaseClient coordclientset.LeaseInterface, lease *coordinationv1.Lease)
+
+// leaseDelegator delegates the node leases in yurt-coordinator to cloud APIServer for the leader yurthub.
+// Each lease is sent after a random delay within jitter and under the rate limit, so the leases renewed
+// by the nodes of pool at the same time don't arrive at cloud APIServer in a burst. If a lease is renewed
+// again before it's sent, only the latest one is sent.
+type leaseDelegator struct {
+	sync.Mutex
+	ctx      context.Context
+	jitter   time.Duration
+	qps      float32
+	burst    int
+	delegate delegateFunc
+
+	isRunning bool
+	cancel    func()
+	queue     workqueue.DelayingInterface
+	leases    map[string]*coordinationv1.Lease
+}
+
+func newLeaseDelegator(ctx context.Context, jitter time.Duration, qps float32, burst int, delegate delegateFunc) *leaseDelegator {
+	return &leaseDelegator{
+		ctx:      ctx,
+		jitter:   jitter,
+		qps:      qps,
+		burst:    burst,
+		delegate: delegate,
+		leases:   make(map[string]*coordinationv1.Lease),
+	}
+}
+
+// EnsureStart starts the workers which delegate leases with cloudLeaseClient.
+func (d *leaseDelegator) EnsureStart(cloudLeaseClient coordclientset.LeaseInterface) {
+	d.Lock()
+	defer d.Unlock()
+	if d.isRunning {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+	queue := workqueue.NewNamedDelayingQueue("delegate-lease")
+	var limiter flowcontrol.RateLimiter
+	if d.qps > 0 {
+		limiter = flowcontrol.NewTokenBucketRateLimiter(d.qps, d.burst)
+	}
+	for i := 0; i < leaseDelegateWorkers; i++ {
+		go func() {
+			for d.processNextLease(ctx, queue, limiter, cloudLeaseClient) {
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+		if limiter != nil {
+			limiter.Stop()
+		}
+	}()
+
+	d.queue = queue
+	d.cancel = cancel
+	d.isRunning = true
+}
+
+// EnsureStop stops the workers, the leases which are not sent yet are dropped.
+func (d *leaseDelegator) EnsureStop() {
+	d.Lock()
+	defer d.Unlock()
+	if !d.isRunning {
+		return
+	}
+	d.cancel()
+	d.queue = nil
+	d.leases = make(map[string]*coordinationv1.Lease)
+	d.isRunning = false
+}
+
+// Enqueue adds the lease to be delegated after a random delay within jitter.
+func (d *leaseDelegator) Enqueue(obj interface{}) {
+	lease, ok := obj.(*coordinationv1.Lease)
+	if !ok {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	if !d.isRunning {
+		return
+	}
+	d.leases[lease.Name] = lease
+	var delay time.Duration
+	if d.jitter > 0 {
+		delay = time.Duration(rand.Int63n(int64(d.jitter)))
+	}
+	d.queue.AddAfter(lease.Name, delay)
+}
+
+func (d *leaseDelegator) processNextLease(ctx context.Context, queue workqueue.DelayingInterface, limiter flowcontrol.RateLimiter, cloudLeaseClient coordclientset.LeaseInterface) bool {
+	key, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+	defer queue.Done(key)
+	if ctx.Err() != nil {
+		// the delegator is stopped, drop the remaining leases in queue.
+		return false
+	}
+
+	d.Lock()
+	lease := d.leases[key.(string)]
+	delete(d.leases, key.(string))
+	d.Unlock()
+	if lease == nil {
+		return true
+	}
+
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			klog.V(4).Infof("stop delegating node lease %s, %v", lease.Name, err)
+			return false
+		}
+	}
+	d.delegate(ctx, cloudLeaseClient, lease)
+	return true
+}
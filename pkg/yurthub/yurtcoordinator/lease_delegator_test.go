@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coordclientset "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+type delegatedLeases struct {
+	sync.Mutex
+	leases []*coordinationv1.Lease
+	times  []time.Time
+}
+
+func (d *delegatedLeases) delegate(_ context.Context, _ coordclientset.LeaseInterface, lease *coordinationv1.Lease) {
+	d.Lock()
+	defer d.Unlock()
+	d.leases = append(d.leases, lease)
+	d.times = append(d.times, time.Now())
+}
+
+func (d *delegatedLeases) len() int {
+	d.Lock()
+	defer d.Unlock()
+	return len(d.leases)
+}
+
+func newLease(name, rv string) *coordinationv1.Lease {
+	return &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-node-lease", ResourceVersion: rv}}
+}
+
+func TestLeaseDelegatorCoalesce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	delegated := &delegatedLeases{}
+	d := newLeaseDelegator(ctx, 200*time.Millisecond, 0, 0, delegated.delegate)
+	d.EnsureStart(nil)
+	defer d.EnsureStop()
+
+	d.Enqueue(newLease("node-a", "1"))
+	d.Enqueue(newLease("node-a", "2"))
+	if err := wait.PollImmediate(10*time.Millisecond, 2*time.Second, func() (bool, error) {
+		return delegated.len() == 1, nil
+	}); err != nil {
+		t.Fatalf("lease is not delegated, %v", err)
+	}
+	// make sure the lease is not delegated twice
+	time.Sleep(300 * time.Millisecond)
+	if delegated.len() != 1 || delegated.leases[0].ResourceVersion != "2" {
+		t.Errorf("expect only the latest lease is delegated, but got %d leases", delegated.len())
+	}
+}
+
+func TestLeaseDelegatorRateLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	delegated := &delegatedLeases{}
+	d := newLeaseDelegator(ctx, 0, 10, 1, delegated.delegate)
+	d.EnsureStart(nil)
+	defer d.EnsureStop()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		d.Enqueue(newLease(fmt.Sprintf("node-%d", i), "1"))
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return delegated.len() == 5, nil
+	}); err != nil {
+		t.Fatalf("leases are not delegated, %v", err)
+	}
+	// the first lease is sent immediately, and the others are sent at 10 qps.
+	if elapsed := delegated.times[4].Sub(start); elapsed < 350*time.Millisecond {
+		t.Errorf("expect leases are delegated under rate limit, but all of them are sent in %v", elapsed)
+	}
+}
+
+func TestLeaseDelegatorStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	delegated := &delegatedLeases{}
+	d := newLeaseDelegator(ctx, 200*time.Millisecond, 0, 0, delegated.delegate)
+
+	// leases are ignored before the delegator is started.
+	d.Enqueue(newLease("node-a", "1"))
+	d.EnsureStart(nil)
+	d.Enqueue(newLease("node-b", "1"))
+	d.EnsureStop()
+
+	time.Sleep(300 * time.Millisecond)
+	if delegated.len() != 0 {
+		t.Errorf("expect no lease is delegated after delegator is stopped, but got %d", delegated.len())
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/informers"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	"github.com/openyurtio/openyurt/pkg/yurthub/util"
 )
 
@@ -39,14 +41,17 @@ type PoolScopeResourcesManger struct {
 	validPoolScopedResourcesLock sync.RWMutex
 	k8sClient                    kubernetes.Interface
 	hasSynced                    func() bool
+	// nodePoolName is used to select the configmaps of nodepool.
+	nodePoolName string
 }
 
 var poolScopeResourcesManger *PoolScopeResourcesManger
 
-func InitPoolScopeResourcesManger(client kubernetes.Interface, factory informers.SharedInformerFactory) *PoolScopeResourcesManger {
+func InitPoolScopeResourcesManger(client kubernetes.Interface, factory informers.SharedInformerFactory, nodePoolName string) *PoolScopeResourcesManger {
 	poolScopeResourcesManger = &PoolScopeResourcesManger{
 		k8sClient:                client,
 		validPoolScopedResources: make(map[string]*verifiablePoolScopeResource),
+		nodePoolName:             nodePoolName,
 	}
 	configmapInformer := factory.Core().V1().ConfigMaps().Informer()
 	configmapInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -65,16 +70,41 @@ func WaitUntilPoolScopeResourcesSync(ctx context.Context) {
 	cache.WaitForCacheSync(ctx.Done(), poolScopeResourcesManger.hasSynced)
 }
 
-func IsPoolScopeResources(info *apirequest.RequestInfo) bool {
+// IsPoolScopeResources checks whether the request is for pool scope resources. If the pool scope resource
+// is limited by a label selector, like the configmaps of nodepool, labelSelector of the request should
+// contain all requirements of it.
+func IsPoolScopeResources(info *apirequest.RequestInfo, labelSelector string) bool {
 	if info == nil || poolScopeResourcesManger == nil {
 		return false
 	}
-	_, ok := poolScopeResourcesManger.validPoolScopedResources[schema.GroupVersionResource{
+
+	poolScopeResourcesManger.validPoolScopedResourcesLock.RLock()
+	defer poolScopeResourcesManger.validPoolScopedResourcesLock.RUnlock()
+	resource, ok := poolScopeResourcesManger.validPoolScopedResources[schema.GroupVersionResource{
 		Group:    info.APIGroup,
 		Version:  info.APIVersion,
 		Resource: info.Resource,
 	}.String()]
-	return ok
+	if !ok {
+		return false
+	}
+	return resource.MatchLabelSelector(labelSelector)
+}
+
+// GetPoolScopeResourceLabelSelector returns the label selector of pool scope resource, empty
+// selector is returned if all objects of the resource are pool scoped.
+func GetPoolScopeResourceLabelSelector(gvr schema.GroupVersionResource) string {
+	if poolScopeResourcesManger == nil {
+		return ""
+	}
+
+	poolScopeResourcesManger.validPoolScopedResourcesLock.RLock()
+	defer poolScopeResourcesManger.validPoolScopedResourcesLock.RUnlock()
+	resource, ok := poolScopeResourcesManger.validPoolScopedResources[gvr.String()]
+	if !ok || resource.labelSelector == nil {
+		return ""
+	}
+	return resource.labelSelector.String()
 }
 
 func GetPoolScopeResources() []schema.GroupVersionResource {
@@ -167,7 +197,7 @@ func (m *PoolScopeResourcesManger) getGroupVersionVerifyFunction(client kubernet
 }
 
 func (m *PoolScopeResourcesManger) getInitPoolScopeResources() []*verifiablePoolScopeResource {
-	return []*verifiablePoolScopeResource{
+	resources := []*verifiablePoolScopeResource{
 		newVerifiablePoolScopeResource(
 			schema.GroupVersionResource{Group: "", Version: "v1", Resource: "endpoints"},
 			m.getGroupVersionVerifyFunction(m.k8sClient)),
@@ -177,5 +207,17 @@ func (m *PoolScopeResourcesManger) getInitPoolScopeResources() []*verifiablePool
 		newVerifiablePoolScopeResource(
 			schema.GroupVersionResource{Group: "discovery.k8s.io", Version: "v1beta1", Resource: "endpointslices"},
 			m.getGroupVersionVerifyFunction(m.k8sClient)),
+		newVerifiablePoolScopeResource(
+			schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"},
+			m.getGroupVersionVerifyFunction(m.k8sClient)),
+	}
+	if len(m.nodePoolName) != 0 {
+		// only the configmaps of nodepool, like the ones rendered from config templates
+		// of YurtAppSet, are shared in the nodepool.
+		resources = append(resources, newVerifiablePoolScopeResource(
+			schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"},
+			m.getGroupVersionVerifyFunction(m.k8sClient)).
+			WithLabelSelector(labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: m.nodePoolName})))
 	}
+	return resources
 }
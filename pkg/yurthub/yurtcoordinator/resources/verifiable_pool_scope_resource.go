@@ -16,11 +16,16 @@ limitations under the License.
 
 package resources
 
-import "k8s.io/apimachinery/pkg/runtime/schema"
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
 
 type verifiablePoolScopeResource struct {
 	schema.GroupVersionResource
 	checkFunction func(gvr schema.GroupVersionResource) (bool, string)
+	// labelSelector selects the pool scoped objects of resource, all objects are pool scoped if it's nil.
+	labelSelector labels.Selector
 }
 
 func newVerifiablePoolScopeResource(gvr schema.GroupVersionResource,
@@ -34,3 +39,37 @@ func newVerifiablePoolScopeResource(gvr schema.GroupVersionResource,
 func (v *verifiablePoolScopeResource) Verify() (bool, string) {
 	return v.checkFunction(v.GroupVersionResource)
 }
+
+// WithLabelSelector limits the pool scoped objects of resource to the ones selected by selector.
+func (v *verifiablePoolScopeResource) WithLabelSelector(selector labels.Selector) *verifiablePoolScopeResource {
+	v.labelSelector = selector
+	return v
+}
+
+// MatchLabelSelector checks whether the objects listed with labelSelector are all pool scoped,
+// which means labelSelector contains all requirements of the selector of resource.
+func (v *verifiablePoolScopeResource) MatchLabelSelector(labelSelector string) bool {
+	if v.labelSelector == nil || v.labelSelector.Empty() {
+		return true
+	}
+
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return false
+	}
+	requirements, _ := selector.Requirements()
+	expected, _ := v.labelSelector.Requirements()
+	for _, e := range expected {
+		found := false
+		for _, r := range requirements {
+			if r.String() == e.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
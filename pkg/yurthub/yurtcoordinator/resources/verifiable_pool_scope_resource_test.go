@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+func TestMatchLabelSelector(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	testcases := map[string]struct {
+		selector      labels.Selector
+		labelSelector string
+		expected      bool
+	}{
+		"all objects are pool scoped": {
+			labelSelector: "",
+			expected:      true,
+		},
+		"request without label selector": {
+			selector:      labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: "hangzhou"}),
+			labelSelector: "",
+			expected:      false,
+		},
+		"request with the label selector of pool": {
+			selector:      labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: "hangzhou"}),
+			labelSelector: apps.PoolNameLabelKey + "=hangzhou",
+			expected:      true,
+		},
+		"request with more requirements": {
+			selector:      labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: "hangzhou"}),
+			labelSelector: "app=foo," + apps.PoolNameLabelKey + "=hangzhou",
+			expected:      true,
+		},
+		"request with the label selector of other pool": {
+			selector:      labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: "hangzhou"}),
+			labelSelector: apps.PoolNameLabelKey + "=shanghai",
+			expected:      false,
+		},
+		"request with invalid label selector": {
+			selector:      labels.SelectorFromSet(labels.Set{apps.PoolNameLabelKey: "hangzhou"}),
+			labelSelector: "a=b=c",
+			expected:      false,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			resource := newVerifiablePoolScopeResource(gvr, nil).WithLabelSelector(tc.selector)
+			if got := resource.MatchLabelSelector(tc.labelSelector); got != tc.expected {
+				t.Errorf("expect match %v, but got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/metrics"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+)
+
+const (
+	defaultStatusReportPeriod = 30 * time.Second
+	// defaultNodeLeaseDuration is used to check whether the node lease is renewed recently
+	// if the lease duration is not set.
+	defaultNodeLeaseDuration = 40 * time.Second
+)
+
+var yurtCoordinatorGVR = appsv1alpha1.GroupVersion.WithResource("yurtcoordinators")
+
+// storageSizer is implemented by the storage which can report its size, like etcd storage.
+type storageSizer interface {
+	DBSize() (int64, error)
+}
+
+// statusReporter is used by the leader yurthub to report the status of yurt-coordinator periodically.
+// The status is exposed as metrics of yurthub, and recorded in the YurtCoordinator with the same name
+// as NodePool in cloud, so the degraded yurt-coordinators can be found from cloud.
+type statusReporter struct {
+	ctx          context.Context
+	nodeName     string
+	nodePoolName string
+	period       time.Duration
+	// coordinatorClient is a client of APIServer in yurt-coordinator.
+	coordinatorClient kubernetes.Interface
+	// cloudClient is a dynamic client of cloud APIServer which is proxied by yurthub.
+	cloudClient       dynamic.Interface
+	getEtcdStore      func() storage.Store
+	getStorageBackend func() string
+	getNodePool       func() (metav1.Object, error)
+	isPoolCacheSynced func() bool
+	isRunning         bool
+	cancel            func()
+}
+
+func (r *statusReporter) EnsureStart() {
+	if !r.isRunning {
+		ctx, cancel := context.WithCancel(r.ctx)
+		go wait.Until(func() {
+			status := r.collect(ctx)
+			if err := r.report(ctx, status); err != nil {
+				klog.Errorf("could not report status of yurt-coordinator, %v", err)
+			}
+		}, r.period, ctx.Done())
+		r.cancel = cancel
+		r.isRunning = true
+	}
+}
+
+func (r *statusReporter) EnsureStop() {
+	if r.isRunning {
+		r.cancel()
+		r.cancel = nil
+		r.isRunning = false
+	}
+}
+
+// collect collects the current status of yurt-coordinator and records it into metrics.
+func (r *statusReporter) collect(ctx context.Context) *appsv1alpha1.YurtCoordinatorStatus {
+	now := metav1.Now()
+	status := &appsv1alpha1.YurtCoordinatorStatus{
+		LeaderHub:      r.nodeName,
+		StorageBackend: r.getStorageBackend(),
+		LastUpdateTime: &now,
+	}
+
+	if sizer, ok := r.getEtcdStore().(storageSizer); ok {
+		if size, err := sizer.DBSize(); err != nil {
+			klog.Warningf("could not get storage size of yurt-coordinator, %v", err)
+		} else {
+			status.StorageSize = size
+			metrics.Metrics.ObserveYurtCoordinatorStorageSize(size)
+		}
+	}
+
+	informerLease, err := r.coordinatorClient.CoordinationV1().Leases(namespaceInformerLease).Get(ctx, nameInformerLease, metav1.GetOptions{})
+	if err != nil {
+		klog.Warningf("could not get informer sync lease from yurt-coordinator, %v", err)
+	} else if informerLease.Spec.RenewTime != nil {
+		status.LastSyncTime = &metav1.Time{Time: informerLease.Spec.RenewTime.Time}
+		metrics.Metrics.ObserveYurtCoordinatorLastSyncTime(informerLease.Spec.RenewTime.Time)
+	}
+
+	leases, err := r.coordinatorClient.CoordinationV1().Leases(corev1.NamespaceNodeLease).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("could not list node leases from yurt-coordinator, %v", err)
+	} else {
+		for i := range leases.Items {
+			lease := &leases.Items[i]
+			duration := defaultNodeLeaseDuration
+			if lease.Spec.LeaseDurationSeconds != nil {
+				duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+			}
+			if lease.Spec.RenewTime != nil && now.Sub(lease.Spec.RenewTime.Time) <= duration {
+				status.ConnectedYurtHubs++
+			}
+			if ifDelegateHeartBeat(lease) {
+				status.DelegatedLeases++
+			}
+		}
+		metrics.Metrics.ObserveYurtCoordinatorConnectedYurtHubs(status.ConnectedYurtHubs)
+		metrics.Metrics.ObserveYurtCoordinatorDelegatedLeases(status.DelegatedLeases)
+	}
+
+	condition := appsv1alpha1.YurtCoordinatorCondition{
+		Type:               appsv1alpha1.YurtCoordinatorReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             "PoolCacheSynced",
+		Message:            "pool-scoped resources in yurt-coordinator are synced with cloud",
+	}
+	if !r.isPoolCacheSynced() {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "PoolCacheNotSynced"
+		condition.Message = "pool-scoped resources in yurt-coordinator are not synced with cloud"
+	}
+	status.Conditions = []appsv1alpha1.YurtCoordinatorCondition{condition}
+	return status
+}
+
+// report records the status into the YurtCoordinator of NodePool in cloud, the YurtCoordinator
+// is created with NodePool as owner if it does not exist.
+func (r *statusReporter) report(ctx context.Context, status *appsv1alpha1.YurtCoordinatorStatus) error {
+	if len(r.nodePoolName) == 0 {
+		return nil
+	}
+
+	obj, err := r.cloudClient.Resource(yurtCoordinatorGVR).Get(ctx, r.nodePoolName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		obj, err = r.createYurtCoordinator(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	yc := &appsv1alpha1.YurtCoordinator{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), yc); err != nil {
+		return fmt.Errorf("could not convert YurtCoordinator %s, %v", r.nodePoolName, err)
+	}
+	// keep the transition time if condition status is not changed
+	for i := range status.Conditions {
+		for _, old := range yc.Status.Conditions {
+			if old.Type == status.Conditions[i].Type && old.Status == status.Conditions[i].Status {
+				status.Conditions[i].LastTransitionTime = old.LastTransitionTime
+			}
+		}
+	}
+	yc.Status = *status
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(yc)
+	if err != nil {
+		return fmt.Errorf("could not convert YurtCoordinator %s, %v", r.nodePoolName, err)
+	}
+	_, err = r.cloudClient.Resource(yurtCoordinatorGVR).UpdateStatus(ctx, &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{})
+	return err
+}
+
+func (r *statusReporter) createYurtCoordinator(ctx context.Context) (*unstructured.Unstructured, error) {
+	yc := &appsv1alpha1.YurtCoordinator{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1alpha1.GroupVersion.String(),
+			Kind:       "YurtCoordinator",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.nodePoolName,
+		},
+	}
+	// the YurtCoordinator is garbage collected with NodePool
+	if nodePool, err := r.getNodePool(); err != nil {
+		klog.Warningf("could not get nodepool %s as owner of YurtCoordinator, %v", r.nodePoolName, err)
+	} else {
+		yc.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: v1beta1.GroupVersion.String(),
+			Kind:       "NodePool",
+			Name:       nodePool.GetName(),
+			UID:        nodePool.GetUID(),
+		}}
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(yc)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert YurtCoordinator %s, %v", r.nodePoolName, err)
+	}
+	return r.cloudClient.Resource(yurtCoordinatorGVR).Create(ctx, &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+}
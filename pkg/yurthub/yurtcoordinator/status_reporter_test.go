@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/healthchecker"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/etcd"
+)
+
+func newNodeLease(name string, renewTime time.Time, delegated bool) *coordinationv1.Lease {
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: corev1.NamespaceNodeLease},
+		Spec: coordinationv1.LeaseSpec{
+			LeaseDurationSeconds: pointer.Int32Ptr(40),
+			RenewTime:            &metav1.MicroTime{Time: renewTime},
+		},
+	}
+	if delegated {
+		lease.Annotations = map[string]string{healthchecker.DelegateHeartBeat: "true"}
+	}
+	return lease
+}
+
+func TestStatusReporter(t *testing.T) {
+	now := time.Now()
+	coordinatorClient := fake.NewSimpleClientset(
+		newNodeLease("node-a", now, false),
+		newNodeLease("node-b", now.Add(-10*time.Second), true),
+		newNodeLease("node-c", now.Add(-2*time.Minute), true),
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: nameInformerLease, Namespace: namespaceInformerLease},
+			Spec:       coordinationv1.LeaseSpec{RenewTime: &metav1.MicroTime{Time: now}},
+		},
+	)
+	scheme := runtime.NewScheme()
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("could not add scheme, %v", err)
+	}
+	cloudClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		yurtCoordinatorGVR: "YurtCoordinatorList",
+	})
+	nodePool := &metav1.ObjectMeta{Name: "hangzhou", UID: types.UID("pool-uid")}
+	synced := true
+	r := &statusReporter{
+		ctx:               context.Background(),
+		nodeName:          "node-a",
+		nodePoolName:      "hangzhou",
+		coordinatorClient: coordinatorClient,
+		cloudClient:       cloudClient,
+		getEtcdStore:      func() storage.Store { return nil },
+		getStorageBackend: func() string { return etcd.BackendKine },
+		getNodePool:       func() (metav1.Object, error) { return nodePool, nil },
+		isPoolCacheSynced: func() bool { return synced },
+	}
+
+	status := r.collect(context.TODO())
+	if status.ConnectedYurtHubs != 2 || status.DelegatedLeases != 2 {
+		t.Errorf("expect 2 connected yurthubs and 2 delegated leases, but got %d and %d", status.ConnectedYurtHubs, status.DelegatedLeases)
+	}
+	if status.LastSyncTime == nil || !status.LastSyncTime.Time.Equal(now) {
+		t.Errorf("expect last sync time %v, but got %v", now, status.LastSyncTime)
+	}
+	if status.LeaderHub != "node-a" || status.StorageBackend != etcd.BackendKine {
+		t.Errorf("unexpected leader hub %s or storage backend %s", status.LeaderHub, status.StorageBackend)
+	}
+	if err := r.report(context.TODO(), status); err != nil {
+		t.Fatalf("could not report status, %v", err)
+	}
+	yc := getYurtCoordinator(t, r)
+	if len(yc.OwnerReferences) != 1 || yc.OwnerReferences[0].UID != nodePool.UID {
+		t.Errorf("expect YurtCoordinator is owned by nodepool, but got %v", yc.OwnerReferences)
+	}
+	if len(yc.Status.Conditions) != 1 || yc.Status.Conditions[0].Status != corev1.ConditionTrue {
+		t.Errorf("expect YurtCoordinator is ready, but got %v", yc.Status.Conditions)
+	}
+	transitionTime := yc.Status.Conditions[0].LastTransitionTime
+
+	// the transition time is kept if the condition is not changed
+	if err := r.report(context.TODO(), r.collect(context.TODO())); err != nil {
+		t.Fatalf("could not report status, %v", err)
+	}
+	if yc = getYurtCoordinator(t, r); !yc.Status.Conditions[0].LastTransitionTime.Equal(&transitionTime) {
+		t.Errorf("expect transition time %v, but got %v", transitionTime, yc.Status.Conditions[0].LastTransitionTime)
+	}
+
+	synced = false
+	if err := r.report(context.TODO(), r.collect(context.TODO())); err != nil {
+		t.Fatalf("could not report status, %v", err)
+	}
+	if yc = getYurtCoordinator(t, r); yc.Status.Conditions[0].Status != corev1.ConditionFalse {
+		t.Errorf("expect YurtCoordinator is not ready, but got %v", yc.Status.Conditions)
+	}
+}
+
+func getYurtCoordinator(t *testing.T, r *statusReporter) *appsv1alpha1.YurtCoordinator {
+	obj, err := r.cloudClient.Resource(yurtCoordinatorGVR).Get(context.TODO(), r.nodePoolName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get YurtCoordinator, %v", err)
+	}
+	yc := &appsv1alpha1.YurtCoordinator{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), yc); err != nil {
+		t.Fatalf("could not convert YurtCoordinator, %v", err)
+	}
+	return yc
+}
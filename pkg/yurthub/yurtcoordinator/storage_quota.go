@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/metrics"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/yurtcoordinator/constants"
+)
+
+const (
+	defaultStorageQuotaCheckPeriod = time.Minute
+	// defaultStorageEvictionCooldown should be longer than the compaction interval of kube-apiserver
+	// in yurt-coordinator(5m by default), so the space of evicted objects has been reclaimed when
+	// the quota is checked again.
+	defaultStorageEvictionCooldown = 10 * time.Minute
+	// storageEvictionTarget is the ratio of quota that the storage usage is reduced to by eviction.
+	storageEvictionTarget = 0.8
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// evictableResource is the resource in yurt-coordinator which can be evicted when the storage exceeds
+// its quota. The objects of them can be got from cloud or the local cache of yurthubs again. The other
+// resources, like leases, services, endpoints and endpointslices, are never evicted, because they are
+// necessary for delegating heartbeats and the service discovery in the pool.
+type evictableResource struct {
+	gvr  schema.GroupVersionResource
+	kind string
+	// component is the component who caches the objects into yurt-coordinator.
+	component string
+	// priority returns the eviction priority of object, the objects with lower priority are evicted first.
+	priority func(obj *unstructured.Unstructured) int
+}
+
+var evictableResources = []evictableResource{
+	{
+		gvr:       podGVR,
+		kind:      "Pod",
+		component: "kubelet",
+		priority: func(obj *unstructured.Unstructured) int {
+			// the completed pods are useless for the pool
+			if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase == "Succeeded" || phase == "Failed" {
+				return 0
+			}
+			return 2
+		},
+	},
+	{
+		gvr:       configMapGVR,
+		kind:      "ConfigMap",
+		component: constants.DefaultPoolScopedUserAgent,
+		priority:  func(_ *unstructured.Unstructured) int { return 1 },
+	},
+	{
+		gvr:       nodeGVR,
+		kind:      "Node",
+		component: "kubelet",
+		priority:  func(_ *unstructured.Unstructured) int { return 3 },
+	},
+}
+
+// storageUsage is implemented by the storage which can report the size in use, like etcd storage.
+type storageUsage interface {
+	DBSizeInUse() (int64, error)
+}
+
+type evictionCandidate struct {
+	key      storage.Key
+	resource string
+	priority int
+	size     int64
+}
+
+// storageQuotaEnforcer is used by the leader yurthub to keep the storage usage of yurt-coordinator under
+// the quota, so the disk of the node running yurt-coordinator is not filled up. When the usage exceeds the
+// quota, the objects are evicted by priority until the usage is reduced to storageEvictionTarget of quota,
+// and the bulky objects are evicted first for the objects with the same priority.
+type storageQuotaEnforcer struct {
+	ctx          context.Context
+	quota        int64
+	period       time.Duration
+	cooldown     time.Duration
+	getEtcdStore func() storage.Store
+	lastEviction time.Time
+	isRunning    bool
+	cancel       func()
+}
+
+func (e *storageQuotaEnforcer) EnsureStart() {
+	if e.quota <= 0 {
+		return
+	}
+	if !e.isRunning {
+		ctx, cancel := context.WithCancel(e.ctx)
+		go wait.Until(func() {
+			if time.Since(e.lastEviction) < e.cooldown {
+				return
+			}
+			evicted, err := e.enforce()
+			if err != nil {
+				klog.Errorf("could not enforce storage quota of yurt-coordinator, %v", err)
+			}
+			if evicted {
+				e.lastEviction = time.Now()
+			}
+		}, e.period, ctx.Done())
+		e.cancel = cancel
+		e.isRunning = true
+	}
+}
+
+func (e *storageQuotaEnforcer) EnsureStop() {
+	if e.isRunning {
+		e.cancel()
+		e.cancel = nil
+		e.isRunning = false
+	}
+}
+
+// enforce evicts objects from yurt-coordinator if the storage usage exceeds the quota,
+// and returns true if any object is evicted.
+func (e *storageQuotaEnforcer) enforce() (bool, error) {
+	etcdStore := e.getEtcdStore()
+	if etcdStore == nil {
+		return false, fmt.Errorf("got empty etcd storage")
+	}
+	usage, ok := etcdStore.(storageUsage)
+	if !ok {
+		return false, nil
+	}
+	size, err := usage.DBSizeInUse()
+	if err != nil {
+		return false, fmt.Errorf("could not get storage size in use, %v", err)
+	}
+	if size <= e.quota {
+		return false, nil
+	}
+
+	candidates, err := listEvictionCandidates(etcdStore)
+	if err != nil {
+		return false, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].size > candidates[j].size
+	})
+
+	toFree := size - int64(float64(e.quota)*storageEvictionTarget)
+	var freed int64
+	counts := map[string]int{}
+	for _, c := range candidates {
+		if freed >= toFree {
+			break
+		}
+		if err := etcdStore.Delete(c.key); err != nil {
+			klog.Errorf("could not evict %s from yurt-coordinator, %v", c.key.Key(), err)
+			continue
+		}
+		freed += c.size
+		counts[c.resource]++
+	}
+
+	for resource, cnt := range counts {
+		metrics.Metrics.AddYurtCoordinatorEvictedObjects(resource, cnt)
+	}
+	klog.Infof("yurt-coordinator storage size %d exceeds quota %d, evicted %v objects of %d bytes", size, e.quota, counts, freed)
+	if freed < toFree {
+		klog.Warningf("yurt-coordinator storage is still over quota after evicting all evictable objects")
+	}
+	return len(counts) != 0, nil
+}
+
+// listEvictionCandidates lists the objects of evictable resources in yurt-coordinator.
+func listEvictionCandidates(etcdStore storage.Store) ([]evictionCandidate, error) {
+	candidates := make([]evictionCandidate, 0)
+	for _, r := range evictableResources {
+		rootKey, err := etcdStore.KeyFunc(storage.KeyBuildInfo{
+			Component: r.component,
+			Group:     r.gvr.Group,
+			Version:   r.gvr.Version,
+			Resources: r.gvr.Resource,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get root key of %s, %v", r.gvr.String(), err)
+		}
+		contents, err := etcdStore.List(rootKey)
+		if err == storage.ErrStorageNotFound {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("could not list %s from yurt-coordinator, %v", r.gvr.String(), err)
+		}
+
+		for _, content := range contents {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(content); err != nil {
+				klog.Errorf("could not decode %s in yurt-coordinator, %v", r.gvr.String(), err)
+				continue
+			}
+			// the root key is also the prefix of other resources, like podtemplates
+			if obj.GetKind() != r.kind {
+				continue
+			}
+			key, err := etcdStore.KeyFunc(storage.KeyBuildInfo{
+				Component: r.component,
+				Group:     r.gvr.Group,
+				Version:   r.gvr.Version,
+				Resources: r.gvr.Resource,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("could not get key of %s %s, %v", r.gvr.Resource, objectKey(obj), err)
+			}
+			candidates = append(candidates, evictionCandidate{
+				key:      key,
+				resource: r.gvr.Resource,
+				priority: r.priority(obj),
+				size:     int64(len(content)),
+			})
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtcoordinator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage"
+	"github.com/openyurtio/openyurt/pkg/yurthub/storage/disk"
+	"github.com/openyurtio/openyurt/pkg/yurthub/yurtcoordinator/constants"
+)
+
+type sizedStore struct {
+	storage.Store
+	size int64
+}
+
+func (s *sizedStore) DBSizeInUse() (int64, error) {
+	return s.size, nil
+}
+
+func storeComponentObject(t *testing.T, store storage.Store, component, resource string, obj metav1.Object) (storage.Key, int64) {
+	key, err := store.KeyFunc(storage.KeyBuildInfo{
+		Component: component,
+		Version:   "v1",
+		Resources: resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	})
+	if err != nil {
+		t.Fatalf("could not get key, %v", err)
+	}
+	content, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("could not encode object, %v", err)
+	}
+	if err := store.Create(key, content); err != nil {
+		t.Fatalf("could not create object, %v", err)
+	}
+	return key, int64(len(content))
+}
+
+func TestStorageQuotaEnforce(t *testing.T) {
+	diskStore, err := disk.NewDiskStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create storage, %v", err)
+	}
+
+	completedPod := newTestPod("pod-completed", "node-a", "pod-completed", "10")
+	completedPod.Status.Phase = corev1.PodSucceeded
+	completedPodKey, completedPodSize := storeComponentObject(t, diskStore, "kubelet", "pods", completedPod)
+	runningPod := newTestPod("pod-running", "node-a", "pod-running", "10")
+	runningPod.Status.Phase = corev1.PodRunning
+	runningPodKey, _ := storeComponentObject(t, diskStore, "kubelet", "pods", runningPod)
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"},
+		Data:       map[string]string{"data": strings.Repeat("x", 1024)},
+	}
+	configMapKey, configMapSize := storeComponentObject(t, diskStore, constants.DefaultPoolScopedUserAgent, "configmaps", configMap)
+	node := &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+	}
+	nodeKey, _ := storeComponentObject(t, diskStore, "kubelet", "nodes", node)
+
+	// only the completed pod and configmap need to be evicted for reducing the usage to 80% of quota.
+	var quota int64 = 1000
+	store := &sizedStore{Store: diskStore, size: 800 + completedPodSize + configMapSize - 1}
+	enforcer := &storageQuotaEnforcer{
+		quota:        quota,
+		getEtcdStore: func() storage.Store { return store },
+	}
+	evicted, err := enforcer.enforce()
+	if err != nil {
+		t.Fatalf("could not enforce quota, %v", err)
+	}
+	if !evicted {
+		t.Errorf("expect objects are evicted")
+	}
+	for key, expectExist := range map[storage.Key]bool{
+		completedPodKey: false,
+		configMapKey:    false,
+		runningPodKey:   true,
+		nodeKey:         true,
+	} {
+		_, err := diskStore.Get(key)
+		if exist := err == nil; exist != expectExist {
+			t.Errorf("expect %s exists %v, but got %v", key.Key(), expectExist, exist)
+		}
+	}
+
+	// nothing is evicted under quota
+	store.size = quota
+	if evicted, err := enforcer.enforce(); err != nil || evicted {
+		t.Errorf("expect nothing is evicted under quota, but got evicted %v, err %v", evicted, err)
+	}
+}
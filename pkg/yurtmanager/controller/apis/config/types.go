@@ -19,13 +19,17 @@ package config
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	csrapproverconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
 	nodepoolconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool/config"
 	platformadminconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/platformadmin/config"
+	gatewaycertificateconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaycertificate/config"
 	gatewaypickupconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaypickup/config"
 	yurtappdaemonconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappdaemon/config"
 	yurtappoverriderconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappoverrider/config"
 	yurtappsetconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/config"
 	yurtstaticsetconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/config"
+	poolcreationconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/poolcreation/config"
+	tokenexchangeconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/tokenexchange/config"
 )
 
 // YurtManagerConfiguration contains elements describing yurt-manager.
@@ -52,6 +56,18 @@ type YurtManagerConfiguration struct {
 
 	// YurtAppOverriderControllerConfiguration holds configuration for YurtAppOverriderController related features.
 	YurtAppOverriderController yurtappoverriderconfig.YurtAppOverriderControllerConfiguration
+
+	// TokenExchange holds configuration for the service which exchanges external identities for bootstrap tokens.
+	TokenExchange tokenexchangeconfig.TokenExchangeConfiguration
+
+	// NodePoolCreation holds configuration for the service which creates nodepools from templates for joining nodes.
+	NodePoolCreation poolcreationconfig.NodePoolCreationConfiguration
+
+	// CsrApproverControllerConfiguration holds configuration for CsrApproverController related features.
+	CsrApproverController csrapproverconfig.CsrApproverControllerConfiguration
+
+	// GatewayCertificateControllerConfiguration holds configuration for GatewayCertificateController related features.
+	GatewayCertificateController gatewaycertificateconfig.GatewayCertificateControllerConfiguration
 }
 
 type GenericConfiguration struct {
@@ -29,14 +29,19 @@ import (
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/daemonpodupdater"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/externaltrafficpolicy"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/loadbalancerset"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodemigration"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/platformadmin"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/dns"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaycertificate"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewayinternalservice"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaypickup"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaypublicservice"
 	servicetopologyendpoints "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/servicetopology/endpoints"
 	servicetopologyendpointslice "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/servicetopology/endpointslice"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/servicevariant"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappdaemon"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappoverrider"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset"
@@ -78,6 +83,10 @@ func NewControllerInitializers() map[string]InitFunc {
 	register(names.DelegateLeaseController, delegatelease.Add)
 	register(names.PodBindingController, podbinding.Add)
 	register(names.NodePoolController, nodepool.Add)
+	register(names.NodeMigrationController, nodemigration.Add)
+	register(names.LoadBalancerSetController, loadbalancerset.Add)
+	register(names.ExternalTrafficPolicyController, externaltrafficpolicy.Add)
+	register(names.ServiceVariantController, servicevariant.Add)
 	register(names.YurtCoordinatorCertController, yurtcoordinatorcert.Add)
 	register(names.ServiceTopologyEndpointsController, servicetopologyendpoints.Add)
 	register(names.ServiceTopologyEndpointSliceController, servicetopologyendpointslice.Add)
@@ -90,6 +99,7 @@ func NewControllerInitializers() map[string]InitFunc {
 	register(names.GatewayDNSController, dns.Add)
 	register(names.GatewayInternalServiceController, gatewayinternalservice.Add)
 	register(names.GatewayPublicServiceController, gatewaypublicservice.Add)
+	register(names.GatewayCertificateController, gatewaycertificate.Add)
 
 	return controllers
 }
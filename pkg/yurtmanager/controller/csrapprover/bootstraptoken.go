@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+// verifyBoundNode verifies the csr requested by a bootstrap token which is exchanged for an external identity
+// is requested for the node which the token is bound to, the reason is returned if it's not.
+func (r *ReconcileCsrApprover) verifyBoundNode(ctx context.Context, csr *certificatesv1.CertificateSigningRequest) (string, error) {
+	if !strings.HasPrefix(csr.Spec.Username, bootstrapUserPrefix) {
+		return "", nil
+	}
+
+	tokenID := strings.TrimPrefix(csr.Spec.Username, bootstrapUserPrefix)
+	var secret corev1.Secret
+	key := types.NamespacedName{Namespace: metav1.NamespaceSystem, Name: bootstraputil.BootstrapTokenSecretName(tokenID)}
+	if err := r.apiReader.Get(ctx, key, &secret); apierrors.IsNotFound(err) {
+		return fmt.Sprintf("bootstrap token %s is not found", tokenID), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	bound, ok := secret.Annotations[token.BoundNodeAnnotation]
+	if !ok {
+		return "", nil
+	}
+	x509cr, err := parseCSR(csr)
+	if err != nil {
+		return err.Error(), nil
+	}
+	if nodeName, _ := requestingNode(csr, x509cr); nodeName != bound {
+		return fmt.Sprintf("bootstrap token %s is bound to node %s", tokenID, bound), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"context"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+func newBootstrapTokenSecret(tokenID, boundNode string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceSystem,
+			Name:      bootstraputil.BootstrapTokenSecretName(tokenID),
+		},
+	}
+	if len(boundNode) != 0 {
+		secret.Annotations = map[string]string{token.BoundNodeAnnotation: boundNode}
+	}
+	return secret
+}
+
+func TestVerifyBoundNode(t *testing.T) {
+	newCSR := func(username, commonName string) *certificatesv1.CertificateSigningRequest {
+		return &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "csr-1"},
+			Spec: certificatesv1.CertificateSigningRequestSpec{
+				Username: username,
+				Request:  newCSRData(commonName, []string{user.NodesGroup}, []string{}, nil),
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		csr    *certificatesv1.CertificateSigningRequest
+		secret *corev1.Secret
+		valid  bool
+	}{
+		"requested by node": {
+			csr:   newCSR("system:node:node1", "system:node:node1"),
+			valid: true,
+		},
+		"requested by bootstrap token which is not exchanged": {
+			csr:    newCSR("system:bootstrap:abcdef", "system:node:node1"),
+			secret: newBootstrapTokenSecret("abcdef", ""),
+			valid:  true,
+		},
+		"requested by bootstrap token for the bound node": {
+			csr:    newCSR("system:bootstrap:abcdef", "system:node:node1"),
+			secret: newBootstrapTokenSecret("abcdef", "node1"),
+			valid:  true,
+		},
+		"requested by bootstrap token for another node": {
+			csr:    newCSR("system:bootstrap:abcdef", "system:node:node2"),
+			secret: newBootstrapTokenSecret("abcdef", "node1"),
+		},
+		"bootstrap token is not found": {
+			csr: newCSR("system:bootstrap:abcdef", "system:node:node1"),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var objs []client.Object
+			if tc.secret != nil {
+				objs = append(objs, tc.secret)
+			}
+			r := &ReconcileCsrApprover{apiReader: fakeclient.NewClientBuilder().WithObjects(objs...).Build()}
+			reason, err := r.verifyBoundNode(context.Background(), tc.csr)
+			if err != nil {
+				t.Fatalf("could not verify bound node, %v", err)
+			}
+			if valid := len(reason) == 0; valid != tc.valid {
+				t.Errorf("expect valid %v, but got reason %q", tc.valid, reason)
+			}
+		})
+	}
+}
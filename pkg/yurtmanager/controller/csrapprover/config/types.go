@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the License);
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an AS IS BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// ReservedSignerPrefix is the prefix of signers built in kubernetes, the csrs of these signers
+// are never approved by the approval rules of additional signers.
+const ReservedSignerPrefix = "kubernetes.io/"
+
+// CsrApproverControllerConfiguration contains elements describing CsrApproverController.
+type CsrApproverControllerConfiguration struct {
+	// SignerNames are the additional signers whose csrs requested by nodes for their own identities
+	// (system:node:<node-name> in organization system:nodes) are auto approved.
+	SignerNames []string
+	// RulesConfigMap is the name of ConfigMap in the working namespace, which holds the approval
+	// rules of additional signers in the data key "rules".
+	RulesConfigMap string
+	// NodeApprovalsPerHour is the max number of csrs auto approved for a requester in an hour, the csrs of
+	// bootstrap tokens are limited by the token. no limit if it's zero.
+	NodeApprovalsPerHour int
+	// ClusterApprovalsPerHour is the max number of csrs auto approved in the cluster in an hour, no limit if it's zero.
+	ClusterApprovalsPerHour int
+	// SPIFFETrustDomain is the trust domain of SPIFFE identities which are registered for edge components,
+	// the csrs with SPIFFE identities are not approved if it's empty.
+	SPIFFETrustDomain string
+}
+
+// CSRApprovalRule describes the csrs of an additional signer which are auto approved, all of
+// the specified predicates should be satisfied by the csr.
+type CSRApprovalRule struct {
+	// SignerName is the signer of csrs which are recognized by the rule.
+	SignerName string `json:"signerName"`
+	// CommonNamePrefix is the required prefix of common name in the certificate request, it's required
+	// unless RequestingNodeOnly is true, so the rule can't approve certificates for any subject.
+	CommonNamePrefix string `json:"commonNamePrefix,omitempty"`
+	// RequestingNodeOnly requires the common name in the certificate request to be the identity of the
+	// requesting node(system:node:<node-name>), so a node can't request certificates for other identities.
+	RequestingNodeOnly bool `json:"requestingNodeOnly,omitempty"`
+	// Organizations are the allowed organizations in the certificate request, at least one organization
+	// is required and all of them should be in the list if it's specified, and no organization is allowed
+	// in the certificate request if it's empty.
+	Organizations []string `json:"organizations,omitempty"`
+	// Usages are the allowed key usages of the csr, digital signature, key encipherment,
+	// client auth and server auth are allowed if it's empty.
+	Usages []string `json:"usages,omitempty"`
+	// RequesterGroups are the groups of which the requester of csr should be a member of at least one,
+	// only the csrs requested by nodes(system:nodes) are approved if it's empty.
+	RequesterGroups []string `json:"requesterGroups,omitempty"`
+}
@@ -23,6 +23,7 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"time"
 
 	certificatesv1 "k8s.io/api/certificates/v1"
 	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
@@ -33,7 +34,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -46,6 +50,7 @@ import (
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
 	"github.com/openyurtio/openyurt/pkg/yurthub/certificate/token"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
 	yurtcoorrdinatorCert "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtcoordinator/cert"
 	"github.com/openyurtio/openyurt/pkg/yurttunnel/constants"
 )
@@ -91,6 +96,10 @@ var (
 			recognize:  isYurtCoordinatorClientCert,
 			successMsg: "Auto approving yurtcoordinator-apiserver client certificate",
 		},
+		{
+			recognize:  isSPIFFESVIDCert,
+			successMsg: "Auto approving spiffe identity certificate",
+		},
 	}
 )
 
@@ -101,10 +110,34 @@ type csrRecognizer struct {
 
 // Add creates a new CsrApprover Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
-	r := &ReconcileCsrApprover{}
+func Add(c *appconfig.CompletedConfig, mgr manager.Manager) error {
+	cfg := c.ComponentConfig.CsrApproverController
+	r := &ReconcileCsrApprover{
+		cfg:       cfg,
+		namespace: c.ComponentConfig.Generic.WorkingNamespace,
+		apiReader: mgr.GetAPIReader(),
+		limiter:   newApprovalLimiter(cfg.NodeApprovalsPerHour, cfg.ClusterApprovalsPerHour),
+		recorder:  mgr.GetEventRecorderFor(names.CsrApproverController),
+	}
+	if len(cfg.RulesConfigMap) != 0 {
+		kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		factory := newRulesInformerFactory(kubeClient, r.namespace, cfg.RulesConfigMap)
+		r.rulesLister = factory.Core().V1().ConfigMaps().Lister()
+		r.rulesSynced = factory.Core().V1().ConfigMaps().Informer().HasSynced
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			factory.Start(ctx.Done())
+			<-ctx.Done()
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
 	// Create a new controller
-	c, err := controller.New(names.CsrApproverController, mgr, controller.Options{
+	ctrl, err := controller.New(names.CsrApproverController, mgr, controller.Options{
 		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
 	})
 	if err != nil {
@@ -113,9 +146,9 @@ func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
 
 	// Watch for csr changes
 	if r.csrV1Supported {
-		return c.Watch(&source.Kind{Type: &certificatesv1.CertificateSigningRequest{}}, &handler.EnqueueRequestForObject{})
+		return ctrl.Watch(&source.Kind{Type: &certificatesv1.CertificateSigningRequest{}}, &handler.EnqueueRequestForObject{})
 	} else {
-		return c.Watch(&source.Kind{Type: &certificatesv1beta1.CertificateSigningRequest{}}, &handler.EnqueueRequestForObject{})
+		return ctrl.Watch(&source.Kind{Type: &certificatesv1beta1.CertificateSigningRequest{}}, &handler.EnqueueRequestForObject{})
 	}
 }
 
@@ -126,6 +159,15 @@ type ReconcileCsrApprover struct {
 	client.Client
 	csrV1Supported    bool
 	csrApproverClient kubernetes.Interface
+	cfg               config.CsrApproverControllerConfiguration
+	namespace         string
+	// apiReader reads bootstrap tokens without cache.
+	apiReader client.Reader
+	// rulesLister lists the rules ConfigMap from an informer which only caches the rules ConfigMap.
+	rulesLister corelisters.ConfigMapLister
+	rulesSynced cache.InformerSynced
+	limiter     *approvalLimiter
+	recorder    record.EventRecorder
 }
 
 func (r *ReconcileCsrApprover) InjectClient(c client.Client) error {
@@ -157,7 +199,10 @@ func (r *ReconcileCsrApprover) InjectConfig(cfg *rest.Config) error {
 
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests/approval,verbs=update
-// +kubebuilder:rbac:groups=certificates.k8s.io,resourceNames=kubernetes.io/kube-apiserver-client;kubernetes.io/kubelet-serving,resources=signers,verbs=approve
+// +kubebuilder:rbac:groups=certificates.k8s.io,resourceNames=kubernetes.io/kube-apiserver-client;kubernetes.io/kubelet-serving;openyurt.io/spiffe-svid,resources=signers,verbs=approve
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a CertificateSigningRequest object and makes changes based on the state read
 // and what is in the CertificateSigningRequest.Spec
@@ -194,11 +239,53 @@ func (r *ReconcileCsrApprover) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	ok, successMsg := isYurtCSR(v1Instance)
+	if !ok {
+		// approve the csrs of additional signers by approval rules
+		rules, err := r.approvalRules()
+		if err != nil {
+			klog.Errorf("failed to get approval rules, %v", err)
+			return reconcile.Result{}, err
+		}
+		ok, successMsg = matchApprovalRules(v1Instance, rules)
+	}
 	if !ok {
 		klog.Infof("csr(%s) is not %s", v1Instance.GetName(), yurtCsr)
 		return reconcile.Result{}, nil
 	}
 
+	// the spiffe identities are approved only when they are registered for the requesting node
+	reason, err := r.verifySPIFFEIDs(ctx, v1Instance)
+	if err != nil {
+		klog.Errorf("failed to verify spiffe identities of csr(%s), %v", v1Instance.GetName(), err)
+		return reconcile.Result{}, err
+	} else if len(reason) != 0 {
+		r.recorder.Eventf(v1Instance, corev1.EventTypeWarning, "InvalidSPIFFEID",
+			"Auto approval of csr requested by %s is skipped, %s", requesterOf(v1Instance), reason)
+		klog.Warningf("csr(%s) is not approved, %s", v1Instance.GetName(), reason)
+		return reconcile.Result{}, nil
+	}
+
+	// the bootstrap tokens exchanged for external identities are only allowed to bootstrap the bound nodes
+	reason, err = r.verifyBoundNode(ctx, v1Instance)
+	if err != nil {
+		klog.Errorf("failed to verify bootstrap token of csr(%s), %v", v1Instance.GetName(), err)
+		return reconcile.Result{}, err
+	} else if len(reason) != 0 {
+		r.recorder.Eventf(v1Instance, corev1.EventTypeWarning, "BootstrapTokenNodeMismatch",
+			"Auto approval of csr requested by %s is skipped, %s", requesterOf(v1Instance), reason)
+		klog.Warningf("csr(%s) is not approved, %s", v1Instance.GetName(), reason)
+		return reconcile.Result{}, nil
+	}
+
+	// limit the approvals for each requester and the whole cluster
+	release, delay := r.limiter.reserve(requesterOf(v1Instance))
+	if release == nil {
+		r.recorder.Eventf(v1Instance, corev1.EventTypeWarning, "ApprovalRateLimited",
+			"Auto approval of csr requested by %s is rate limited, retry after %s", requesterOf(v1Instance), delay.Round(time.Second))
+		klog.Warningf("approval of csr(%s) requested by %s is rate limited, retry after %s", v1Instance.GetName(), requesterOf(v1Instance), delay)
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+
 	// approve the openyurt related csr
 	v1Instance.Status.Conditions = append(v1Instance.Status.Conditions,
 		certificatesv1.CertificateSigningRequestCondition{
@@ -211,13 +298,49 @@ func (r *ReconcileCsrApprover) Reconcile(ctx context.Context, request reconcile.
 	// Update CertificateSigningRequests
 	err = r.updateApproval(ctx, v1Instance)
 	if err != nil {
+		release()
 		klog.Errorf("failed to approve %s(%s), %v", yurtCsr, v1Instance.GetName(), err)
 		return reconcile.Result{}, err
 	}
-	klog.Infof("successfully approve %s(%s)", yurtCsr, v1Instance.GetName())
+	r.recordApproval(v1Instance, successMsg)
 	return reconcile.Result{}, nil
 }
 
+// recordApproval records what is approved and why by an event of the csr and a structured log.
+func (r *ReconcileCsrApprover) recordApproval(csr *certificatesv1.CertificateSigningRequest, reason string) {
+	var commonName string
+	var organizations, dnsNames, ipAddresses, uris []string
+	if x509cr, err := parseCSR(csr); err == nil {
+		commonName = x509cr.Subject.CommonName
+		organizations = x509cr.Subject.Organization
+		dnsNames = x509cr.DNSNames
+		for _, ip := range x509cr.IPAddresses {
+			ipAddresses = append(ipAddresses, ip.String())
+		}
+		for _, u := range x509cr.URIs {
+			uris = append(uris, u.String())
+		}
+	}
+	usages := usagesToSet(csr.Spec.Usages).List()
+
+	r.recorder.Eventf(csr, corev1.EventTypeNormal, "AutoApproved",
+		"%s, requester: %s, signer: %s, common name: %s, organizations: %v, dns names: %v, ip addresses: %v, uris: %v, usages: %v",
+		reason, requesterOf(csr), csr.Spec.SignerName, commonName, organizations, dnsNames, ipAddresses, uris, usages)
+	klog.InfoS("successfully approve csr", "csr", csr.GetName(), "reason", reason,
+		"requester", requesterOf(csr), "groups", csr.Spec.Groups, "signer", csr.Spec.SignerName,
+		"commonName", commonName, "organizations", organizations, "dnsNames", dnsNames, "ipAddresses", ipAddresses, "uris", uris, "usages", usages)
+}
+
+// requesterOf returns the requester of csr, which is the key of approval rate limits. the csrs requested by
+// a bootstrap token are limited by the token(system:bootstrap:<token-id>) instead of the node claimed in csr,
+// so a leaked token can't bypass the limit by rotating the node names.
+func requesterOf(csr *certificatesv1.CertificateSigningRequest) string {
+	if len(csr.Spec.Username) == 0 {
+		return "unknown"
+	}
+	return csr.Spec.Username
+}
+
 // updateApproval is used for adding approval info into csr resource
 func (r *ReconcileCsrApprover) updateApproval(ctx context.Context, csr *certificatesv1.CertificateSigningRequest) (err error) {
 	if r.csrV1Supported {
@@ -233,12 +356,7 @@ func (r *ReconcileCsrApprover) updateApproval(ctx context.Context, csr *certific
 // return success message for specified recognizers.
 func isYurtCSR(csr *certificatesv1.CertificateSigningRequest) (bool, string) {
 	var successMsg string
-	pemBytes := csr.Spec.Request
-	block, _ := pem.Decode(pemBytes)
-	if block == nil || block.Type != "CERTIFICATE REQUEST" {
-		return false, successMsg
-	}
-	x509cr, err := x509.ParseCertificateRequest(block.Bytes)
+	x509cr, err := parseCSR(csr)
 	if err != nil {
 		return false, successMsg
 	}
@@ -253,6 +371,15 @@ func isYurtCSR(csr *certificatesv1.CertificateSigningRequest) (bool, string) {
 	return false, successMsg
 }
 
+// parseCSR extracts the x509 certificate request from csr.
+func parseCSR(csr *certificatesv1.CertificateSigningRequest) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("pem block type must be CERTIFICATE REQUEST")
+	}
+	return x509.ParseCertificateRequest(block.Bytes)
+}
+
 // checkCertApprovalCondition checks if the given csr's status is
 // approved or denied
 func checkCertApprovalCondition(status *certificatesv1.CertificateSigningRequestStatus) (approved bool, denied bool) {
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// approvalLimiter limits the auto approvals of csrs for each requester and the whole cluster by token buckets,
+// so a compromised node can't mint unlimited certificates. the buckets hold the approvals of an hour, and are
+// refilled continuously, a limit of zero means the approvals are not limited, and the limiter is nil when
+// neither of the limits is specified.
+type approvalLimiter struct {
+	sync.Mutex
+	requesterLimit rate.Limit
+	requesterBurst int
+	requesters     map[string]*rate.Limiter
+	cluster        *rate.Limiter
+	now            func() time.Time
+}
+
+func newApprovalLimiter(requesterApprovalsPerHour, clusterApprovalsPerHour int) *approvalLimiter {
+	if requesterApprovalsPerHour <= 0 && clusterApprovalsPerHour <= 0 {
+		return nil
+	}
+	l := &approvalLimiter{
+		requesterLimit: perHour(requesterApprovalsPerHour),
+		requesterBurst: requesterApprovalsPerHour,
+		requesters:     make(map[string]*rate.Limiter),
+		now:            time.Now,
+	}
+	if clusterApprovalsPerHour > 0 {
+		l.cluster = rate.NewLimiter(perHour(clusterApprovalsPerHour), clusterApprovalsPerHour)
+	}
+	return l
+}
+
+func perHour(n int) rate.Limit {
+	return rate.Limit(float64(n) / time.Hour.Seconds())
+}
+
+// reserve reserves an approval for the requester. the release func is returned for giving back the approval
+// when the csr isn't approved finally, and the delay before the next approval is returned when it's limited.
+func (l *approvalLimiter) reserve(requester string) (func(), time.Duration) {
+	if l == nil {
+		return func() {}, 0
+	}
+	l.Lock()
+	defer l.Unlock()
+	now := l.now()
+	l.cleanup(now)
+
+	var reservations []*rate.Reservation
+	release := func() {
+		for _, res := range reservations {
+			res.CancelAt(now)
+		}
+	}
+
+	if l.requesterBurst > 0 {
+		limiter, ok := l.requesters[requester]
+		if !ok {
+			limiter = rate.NewLimiter(l.requesterLimit, l.requesterBurst)
+			l.requesters[requester] = limiter
+		}
+		res := limiter.ReserveN(now, 1)
+		reservations = append(reservations, res)
+		if delay := res.DelayFrom(now); delay > 0 {
+			release()
+			return nil, delay
+		}
+	}
+
+	if l.cluster != nil {
+		res := l.cluster.ReserveN(now, 1)
+		reservations = append(reservations, res)
+		if delay := res.DelayFrom(now); delay > 0 {
+			release()
+			return nil, delay
+		}
+	}
+	return release, 0
+}
+
+// cleanup removes the limiters of requesters which are refilled, because they are
+// the same as new limiters, so the limiters of gone requesters are not leaked.
+func (l *approvalLimiter) cleanup(now time.Time) {
+	for requester, limiter := range l.requesters {
+		if limiter.TokensAt(now) >= float64(l.requesterBurst) {
+			delete(l.requesters, requester)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/certificate/token"
+)
+
+func TestApprovalLimiter(t *testing.T) {
+	now := time.Now()
+	l := newApprovalLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	reserve := func(requester string) bool {
+		release, _ := l.reserve(requester)
+		return release != nil
+	}
+
+	if !reserve("node1") || !reserve("node1") {
+		t.Fatalf("expect the first two approvals of node1 are allowed")
+	}
+	if reserve("node1") {
+		t.Errorf("expect the third approval of node1 is limited by requester limit")
+	}
+	if !reserve("node2") {
+		t.Fatalf("expect the first approval of node2 is allowed")
+	}
+	if release, delay := l.reserve("node3"); release != nil || delay <= 0 {
+		t.Errorf("expect the approval of node3 is limited by cluster limit, but got delay %s", delay)
+	}
+
+	// the approvals are refilled after an hour, and the refilled limiters are cleaned up.
+	now = now.Add(time.Hour)
+	release, _ := l.reserve("node1")
+	if release == nil {
+		t.Fatalf("expect the approval of node1 is allowed after an hour")
+	}
+	release()
+	if _, ok := l.requesters["node2"]; ok {
+		t.Errorf("expect the refilled limiter of node2 is cleaned up")
+	}
+
+	unlimited := newApprovalLimiter(0, 0)
+	if unlimited != nil {
+		t.Errorf("expect no limiter when limits are not specified")
+	}
+	for i := 0; i < 100; i++ {
+		if release, _ := unlimited.reserve("node1"); release == nil {
+			t.Fatalf("expect approvals are not limited")
+		}
+	}
+}
+
+func newRateLimitCSR(name, username, nodeName string) *certificatesv1.CertificateSigningRequest {
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			SignerName: certificatesv1.KubeAPIServerClientSignerName,
+			Username:   username,
+			Groups:     []string{user.NodesGroup},
+			Usages: []certificatesv1.KeyUsage{
+				certificatesv1.UsageDigitalSignature,
+				certificatesv1.UsageKeyEncipherment,
+				certificatesv1.UsageClientAuth,
+			},
+			Request: newCSRData("system:node:"+nodeName, []string{token.YurtHubCSROrg, user.NodesGroup}, []string{}, nil),
+		},
+	}
+}
+
+func TestReconcileWithRateLimit(t *testing.T) {
+	csr1, csr2 := newRateLimitCSR("csr-1", "system:node:node1", "node1"), newRateLimitCSR("csr-2", "system:node:node1", "node1")
+	// the nodes joining with the same bootstrap token are limited by the token, whatever nodes are claimed
+	csr3, csr4 := newRateLimitCSR("csr-3", "system:bootstrap:abcdef", "node3"), newRateLimitCSR("csr-4", "system:bootstrap:abcdef", "node4")
+	recorder := record.NewFakeRecorder(10)
+	c := fakeclient.NewClientBuilder().WithObjects(csr1, csr2, csr3, csr4, newBootstrapTokenSecret("abcdef", "")).Build()
+	r := &ReconcileCsrApprover{
+		Client:            c,
+		apiReader:         c,
+		csrV1Supported:    true,
+		csrApproverClient: fake.NewSimpleClientset(csr1, csr2, csr3, csr4),
+		limiter:           newApprovalLimiter(1, 0),
+		recorder:          recorder,
+	}
+
+	res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "csr-1"}})
+	if err != nil || res.RequeueAfter != 0 {
+		t.Fatalf("expect csr-1 is approved, but got %v, %v", res, err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "AutoApproved") || !strings.Contains(event, "requester: system:node:node1") {
+		t.Errorf("expect an AutoApproved event with requester, but got %s", event)
+	}
+	approved, err := r.csrApproverClient.CertificatesV1().CertificateSigningRequests().Get(context.Background(), "csr-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get csr, %v", err)
+	}
+	if ok, _ := checkCertApprovalCondition(&approved.Status); !ok {
+		t.Errorf("expect csr-1 is approved")
+	}
+
+	res, err = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "csr-2"}})
+	if err != nil || res.RequeueAfter <= 0 {
+		t.Fatalf("expect csr-2 is rate limited, but got %v, %v", res, err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ApprovalRateLimited") {
+		t.Errorf("expect an ApprovalRateLimited event, but got %s", event)
+	}
+
+	res, err = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "csr-3"}})
+	if err != nil || res.RequeueAfter != 0 {
+		t.Fatalf("expect csr-3 of joining node is approved, but got %v, %v", res, err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "AutoApproved") {
+		t.Errorf("expect an AutoApproved event, but got %s", event)
+	}
+	res, err = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "csr-4"}})
+	if err != nil || res.RequeueAfter <= 0 {
+		t.Fatalf("expect csr-4 requested by the same bootstrap token is rate limited, but got %v, %v", res, err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ApprovalRateLimited") {
+		t.Errorf("expect an ApprovalRateLimited event, but got %s", event)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
+)
+
+// RulesConfigMapKey is the data key of approval rules in the rules ConfigMap.
+const RulesConfigMapKey = "rules"
+
+var defaultRuleUsages = sets.NewString(
+	string(certificatesv1.UsageDigitalSignature),
+	string(certificatesv1.UsageKeyEncipherment),
+	string(certificatesv1.UsageClientAuth),
+	string(certificatesv1.UsageServerAuth))
+
+// newRulesInformerFactory creates an informer factory which only caches the rules ConfigMap, so the ConfigMap
+// is not read from kube-apiserver for each csr and the other ConfigMaps in the cluster are not cached.
+func newRulesInformerFactory(client kubernetes.Interface, namespace, name string) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+}
+
+// approvalRules returns the approval rules of additional signers, the csrs of signers specified by flags
+// are approved only for the identity of requesting node, and the rules in ConfigMap are appended.
+func (r *ReconcileCsrApprover) approvalRules() ([]config.CSRApprovalRule, error) {
+	rules := make([]config.CSRApprovalRule, 0, len(r.cfg.SignerNames))
+	for _, signer := range r.cfg.SignerNames {
+		rule := config.CSRApprovalRule{
+			SignerName:         signer,
+			RequestingNodeOnly: true,
+			Organizations:      []string{user.NodesGroup},
+		}
+		if err := validateApprovalRule(&rule); err != nil {
+			klog.Warningf("approval rule of signer %s is ignored, %v", signer, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(r.cfg.RulesConfigMap) == 0 {
+		return rules, nil
+	}
+
+	if !r.rulesSynced() {
+		return nil, fmt.Errorf("configmap %s/%s of approval rules is not synced", r.namespace, r.cfg.RulesConfigMap)
+	}
+	cm, err := r.rulesLister.ConfigMaps(r.namespace).Get(r.cfg.RulesConfigMap)
+	if apierrors.IsNotFound(err) {
+		return rules, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var cmRules []config.CSRApprovalRule
+	if err := yaml.Unmarshal([]byte(cm.Data[RulesConfigMapKey]), &cmRules); err != nil {
+		return nil, fmt.Errorf("could not parse approval rules in configmap %s/%s, %v", cm.Namespace, cm.Name, err)
+	}
+	for i := range cmRules {
+		if err := validateApprovalRule(&cmRules[i]); err != nil {
+			klog.Warningf("approval rule %d in configmap %s/%s is ignored, %v", i, cm.Namespace, cm.Name, err)
+			continue
+		}
+		rules = append(rules, cmRules[i])
+	}
+	return rules, nil
+}
+
+// validateApprovalRule checks the signer of the rule is not built in kubernetes and
+// the subject of certificates approved by the rule is restricted.
+func validateApprovalRule(rule *config.CSRApprovalRule) error {
+	if len(rule.SignerName) == 0 {
+		return fmt.Errorf("signerName is required")
+	}
+	if strings.HasPrefix(rule.SignerName, config.ReservedSignerPrefix) {
+		return fmt.Errorf("signer %s of kubernetes is not allowed", rule.SignerName)
+	}
+	if len(rule.CommonNamePrefix) == 0 && !rule.RequestingNodeOnly {
+		return fmt.Errorf("commonNamePrefix or requestingNodeOnly is required for signer %s", rule.SignerName)
+	}
+	return nil
+}
+
+// matchApprovalRules checks if given csr is recognized by one of the approval rules and
+// return success message for the matched rule.
+func matchApprovalRules(csr *certificatesv1.CertificateSigningRequest, rules []config.CSRApprovalRule) (bool, string) {
+	if len(rules) == 0 {
+		return false, ""
+	}
+	x509cr, err := parseCSR(csr)
+	if err != nil {
+		return false, ""
+	}
+
+	for i := range rules {
+		if matchApprovalRule(csr, x509cr, &rules[i]) {
+			return true, fmt.Sprintf("Auto approving %s certificate by approval rule", rules[i].SignerName)
+		}
+	}
+	return false, ""
+}
+
+func matchApprovalRule(csr *certificatesv1.CertificateSigningRequest, x509cr *x509.CertificateRequest, rule *config.CSRApprovalRule) bool {
+	if len(rule.SignerName) == 0 || csr.Spec.SignerName != rule.SignerName {
+		return false
+	}
+
+	if !strings.HasPrefix(x509cr.Subject.CommonName, rule.CommonNamePrefix) {
+		return false
+	}
+
+	if rule.RequestingNodeOnly {
+		if !strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) || x509cr.Subject.CommonName != csr.Spec.Username {
+			klog.V(4).Infof("common name %s of csr(%s) is not the identity of requester %s", x509cr.Subject.CommonName, csr.Name, csr.Spec.Username)
+			return false
+		}
+	}
+
+	if len(rule.Organizations) != 0 {
+		if len(x509cr.Subject.Organization) == 0 || !sets.NewString(rule.Organizations...).HasAll(x509cr.Subject.Organization...) {
+			return false
+		}
+	} else if len(x509cr.Subject.Organization) != 0 {
+		return false
+	}
+
+	allowedUsages := defaultRuleUsages
+	if len(rule.Usages) != 0 {
+		allowedUsages = sets.NewString(rule.Usages...)
+	}
+	usages := usagesToSet(csr.Spec.Usages)
+	if usages.Len() == 0 || !allowedUsages.IsSuperset(usages) {
+		return false
+	}
+
+	requesterGroups := rule.RequesterGroups
+	if len(requesterGroups) == 0 {
+		requesterGroups = []string{user.NodesGroup}
+	}
+	if !sets.NewString(csr.Spec.Groups...).HasAny(requesterGroups...) {
+		klog.V(4).Infof("requester %s of csr(%s) is not a member of %v", csr.Spec.Username, csr.Name, requesterGroups)
+		return false
+	}
+
+	return true
+}
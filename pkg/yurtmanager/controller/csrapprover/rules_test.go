@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
+)
+
+const telemetrySigner = "example.com/gpu-telemetry"
+
+func newRuleCSR(signer, commonName string, organizations, groups []string, usages ...certificatesv1.KeyUsage) *certificatesv1.CertificateSigningRequest {
+	return &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "csr-1"},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			SignerName: signer,
+			Username:   "system:node:node1",
+			Groups:     groups,
+			Usages:     usages,
+			Request:    newCSRData(commonName, organizations, []string{}, nil),
+		},
+	}
+}
+
+func TestMatchApprovalRules(t *testing.T) {
+	clientUsages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageClientAuth}
+	rule := config.CSRApprovalRule{
+		SignerName:       telemetrySigner,
+		CommonNamePrefix: "gpu-telemetry:",
+		Organizations:    []string{"gpu-telemetry"},
+		Usages:           []string{string(certificatesv1.UsageDigitalSignature), string(certificatesv1.UsageKeyEncipherment), string(certificatesv1.UsageClientAuth)},
+		RequesterGroups:  []string{"system:serviceaccounts:gpu"},
+	}
+	nodeRule := config.CSRApprovalRule{
+		SignerName:         telemetrySigner,
+		RequestingNodeOnly: true,
+		Organizations:      []string{user.NodesGroup},
+	}
+
+	tests := []struct {
+		desc  string
+		csr   *certificatesv1.CertificateSigningRequest
+		rules []config.CSRApprovalRule
+		exp   bool
+	}{
+		{
+			desc:  "no rules",
+			csr:   newRuleCSR(telemetrySigner, "gpu-telemetry:node1", nil, []string{user.NodesGroup}, clientUsages...),
+			rules: nil,
+			exp:   false,
+		},
+		{
+			desc:  "signer specified by flag is requested by node for its identity",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node1", []string{user.NodesGroup}, []string{user.NodesGroup}, clientUsages...),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   true,
+		},
+		{
+			desc:  "signer specified by flag is requested by node for identity of another node",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node2", []string{user.NodesGroup}, []string{user.NodesGroup}, clientUsages...),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   false,
+		},
+		{
+			desc:  "signer specified by flag is requested by node for another subject",
+			csr:   newRuleCSR(telemetrySigner, "gpu-telemetry:node1", nil, []string{user.NodesGroup}, clientUsages...),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   false,
+		},
+		{
+			desc:  "signer specified by flag is requested by node for privileged organization",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node1", []string{"system:masters"}, []string{user.NodesGroup}, clientUsages...),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   false,
+		},
+		{
+			desc:  "signer specified by flag is not requested by node",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node1", []string{user.NodesGroup}, []string{"system:authenticated"}, clientUsages...),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   false,
+		},
+		{
+			desc:  "signer specified by flag with unexpected usage",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node1", []string{user.NodesGroup}, []string{user.NodesGroup}, certificatesv1.UsageCertSign),
+			rules: []config.CSRApprovalRule{nodeRule},
+			exp:   false,
+		},
+		{
+			desc:  "organization is not allowed by rule",
+			csr:   newRuleCSR(telemetrySigner, "gpu-telemetry:node1", []string{"system:masters"}, []string{"system:serviceaccounts:gpu"}, clientUsages...),
+			rules: []config.CSRApprovalRule{{SignerName: telemetrySigner, CommonNamePrefix: "gpu-telemetry:", RequesterGroups: []string{"system:serviceaccounts:gpu"}}},
+			exp:   false,
+		},
+		{
+			desc:  "all predicates of rule are satisfied",
+			csr:   newRuleCSR(telemetrySigner, "gpu-telemetry:node1", []string{"gpu-telemetry"}, []string{"system:serviceaccounts:gpu"}, clientUsages...),
+			rules: []config.CSRApprovalRule{rule},
+			exp:   true,
+		},
+		{
+			desc:  "different signer",
+			csr:   newRuleCSR("example.com/other", "gpu-telemetry:node1", []string{"gpu-telemetry"}, []string{"system:serviceaccounts:gpu"}, clientUsages...),
+			rules: []config.CSRApprovalRule{rule},
+			exp:   false,
+		},
+		{
+			desc:  "unexpected common name",
+			csr:   newRuleCSR(telemetrySigner, "system:node:node1", []string{"gpu-telemetry"}, []string{"system:serviceaccounts:gpu"}, clientUsages...),
+			rules: []config.CSRApprovalRule{rule},
+			exp:   false,
+		},
+		{
+			desc:  "unexpected organization",
+			csr:   newRuleCSR(telemetrySigner, "gpu-telemetry:node1", []string{"gpu-telemetry", "system:masters"}, []string{"system:serviceaccounts:gpu"}, clientUsages...),
+			rules: []config.CSRApprovalRule{rule},
+			exp:   false,
+		},
+		{
+			desc: "unexpected usage",
+			csr: newRuleCSR(telemetrySigner, "gpu-telemetry:node1", []string{"gpu-telemetry"}, []string{"system:serviceaccounts:gpu"},
+				certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageServerAuth),
+			rules: []config.CSRApprovalRule{rule},
+			exp:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			act, _ := matchApprovalRules(tt.csr, tt.rules)
+			if act != tt.exp {
+				t.Errorf("the value we want is %v, but the actual value is %v", tt.exp, act)
+			}
+		})
+	}
+}
+
+func TestApprovalRules(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "csr-approval-rules"},
+		Data: map[string]string{
+			RulesConfigMapKey: `
+- signerName: example.com/gpu-telemetry
+  commonNamePrefix: "gpu-telemetry:"
+  requesterGroups:
+  - system:serviceaccounts:gpu
+- signerName: example.com/any-subject
+- signerName: kubernetes.io/kube-apiserver-client
+  commonNamePrefix: "system:"
+`,
+		},
+	}); err != nil {
+		t.Fatalf("could not add configmap, %v", err)
+	}
+
+	r := &ReconcileCsrApprover{
+		rulesLister: corelisters.NewConfigMapLister(indexer),
+		rulesSynced: func() bool { return true },
+		cfg: config.CsrApproverControllerConfiguration{
+			SignerNames:    []string{"example.com/node-agent", certificatesv1.KubeAPIServerClientKubeletSignerName},
+			RulesConfigMap: "csr-approval-rules",
+		},
+		namespace: "kube-system",
+	}
+	rules, err := r.approvalRules()
+	if err != nil {
+		t.Fatalf("could not get approval rules, %v", err)
+	}
+	// the rule without restriction of subject and the rules of kubernetes signers are ignored
+	if len(rules) != 2 || rules[0].SignerName != "example.com/node-agent" || !rules[0].RequestingNodeOnly ||
+		rules[1].SignerName != telemetrySigner || rules[1].CommonNamePrefix != "gpu-telemetry:" || len(rules[1].RequesterGroups) != 1 {
+		t.Errorf("unexpected approval rules %#+v", rules)
+	}
+
+	r.cfg.RulesConfigMap = "not-found"
+	rules, err = r.approvalRules()
+	if err != nil || len(rules) != 1 {
+		t.Errorf("expect only the rules of flags when configmap is not found, but got %#+v, %v", rules, err)
+	}
+
+	r.rulesSynced = func() bool { return false }
+	if _, err = r.approvalRules(); err == nil {
+		t.Errorf("expect an error when configmap is not synced")
+	}
+}
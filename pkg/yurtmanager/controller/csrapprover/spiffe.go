@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/util/spiffe"
+)
+
+const (
+	nodeUserPrefix      = "system:node:"
+	bootstrapUserPrefix = "system:bootstrap:"
+)
+
+var svidAllowedUsages = sets.NewString(
+	string(certificatesv1.UsageDigitalSignature),
+	string(certificatesv1.UsageKeyEncipherment),
+	string(certificatesv1.UsageClientAuth),
+	string(certificatesv1.UsageServerAuth))
+
+// isSPIFFESVIDCert is used to recognize csr of X509-SVID for edge components, such as raven agent.
+// the identity is carried as the URI SANs and common name, and the identities are verified against
+// the requesting node by verifySPIFFEIDs before approval. X509-SVIDs are requested with the dedicated
+// signer of OpenYurt, so they are not credentials of kube-apiserver.
+func isSPIFFESVIDCert(csr *certificatesv1.CertificateSigningRequest, x509cr *x509.CertificateRequest) bool {
+	if csr.Spec.SignerName != spiffe.SignerName {
+		return false
+	}
+
+	if len(x509cr.URIs) == 0 || x509cr.Subject.CommonName != x509cr.URIs[0].String() {
+		return false
+	}
+
+	if len(x509cr.Subject.Organization) != 0 || len(x509cr.DNSNames) != 0 || len(x509cr.IPAddresses) != 0 || len(x509cr.EmailAddresses) != 0 {
+		return false
+	}
+
+	for _, u := range x509cr.URIs {
+		if !spiffe.IsSPIFFE(u) {
+			return false
+		}
+	}
+
+	usages := usagesToSet(csr.Spec.Usages)
+	if !usages.Has(string(certificatesv1.UsageClientAuth)) || !svidAllowedUsages.IsSuperset(usages) {
+		return false
+	}
+
+	return true
+}
+
+// verifySPIFFEIDs verifies the SPIFFE identities in csr are registered for the node which requests the csr,
+// the node scoped identities should be bound to the node and the nodepool scoped identities should be bound
+// to the nodepool which the node belongs to. the nodepool membership is read from the status of nodepools
+// which is maintained by nodepool controller, so the nodepool scoped identities are not approved for the
+// node which has not been registered. the reason is returned if the identities are not registered for the node.
+func (r *ReconcileCsrApprover) verifySPIFFEIDs(ctx context.Context, csr *certificatesv1.CertificateSigningRequest) (string, error) {
+	x509cr, err := parseCSR(csr)
+	if err != nil {
+		return err.Error(), nil
+	}
+
+	var ids []*spiffe.ID
+	for _, u := range x509cr.URIs {
+		if !spiffe.IsSPIFFE(u) {
+			continue
+		}
+		id, err := spiffe.Parse(u)
+		if err != nil {
+			return err.Error(), nil
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+
+	if len(r.cfg.SPIFFETrustDomain) == 0 {
+		return "spiffe identities are not enabled", nil
+	}
+
+	nodeName, bootstrap := requestingNode(csr, x509cr)
+	if len(nodeName) == 0 {
+		return fmt.Sprintf("requester %s is not a node", requesterOf(csr)), nil
+	}
+
+	var pools sets.String
+	for _, id := range ids {
+		if id.TrustDomain != r.cfg.SPIFFETrustDomain {
+			return fmt.Sprintf("trust domain of spiffe identity %s is not %s", id, r.cfg.SPIFFETrustDomain), nil
+		}
+
+		switch id.Scope {
+		case spiffe.NodeScope:
+			if id.Name != nodeName {
+				return fmt.Sprintf("spiffe identity %s is not registered for node %s", id, nodeName), nil
+			}
+		case spiffe.NodePoolScope:
+			if bootstrap {
+				return fmt.Sprintf("spiffe identity %s is not registered for node %s which is not registered", id, nodeName), nil
+			}
+			if pools == nil {
+				if pools, err = r.nodePoolsOf(ctx, nodeName); err != nil {
+					return "", err
+				}
+			}
+			if !pools.Has(id.Name) {
+				return fmt.Sprintf("spiffe identity %s is not registered for node %s which is not a member of nodepool %s", id, nodeName, id.Name), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// nodePoolsOf returns the nodepools whose status contains the node.
+func (r *ReconcileCsrApprover) nodePoolsOf(ctx context.Context, nodeName string) (sets.String, error) {
+	var poolList appsv1beta1.NodePoolList
+	if err := r.List(ctx, &poolList); err != nil {
+		return nil, err
+	}
+
+	pools := sets.NewString()
+	for i := range poolList.Items {
+		for _, name := range poolList.Items[i].Status.Nodes {
+			if name == nodeName {
+				pools.Insert(poolList.Items[i].Name)
+				break
+			}
+		}
+	}
+	return pools, nil
+}
+
+// requestingNode returns the node which requests the csr, and whether the csr is requested by
+// a bootstrap token for the node which is joining.
+func requestingNode(csr *certificatesv1.CertificateSigningRequest, x509cr *x509.CertificateRequest) (string, bool) {
+	if strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) {
+		return strings.TrimPrefix(csr.Spec.Username, nodeUserPrefix), false
+	}
+
+	if strings.HasPrefix(csr.Spec.Username, bootstrapUserPrefix) && strings.HasPrefix(x509cr.Subject.CommonName, nodeUserPrefix) {
+		return strings.TrimPrefix(x509cr.Subject.CommonName, nodeUserPrefix), true
+	}
+	return "", false
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csrapprover
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"strings"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/util/spiffe"
+	"github.com/openyurtio/openyurt/pkg/yurthub/certificate/token"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/csrapprover/config"
+)
+
+func newSVIDCSRData(t *testing.T, commonName string, organizations []string, uris []*url.URL) []byte {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		t.Fatalf("could not create private key, %v", err)
+	}
+	csr, err := cert.MakeCSRFromTemplate(privateKey, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: commonName, Organization: organizations},
+		URIs:    uris,
+	})
+	if err != nil {
+		t.Fatalf("could not make csr, %v", err)
+	}
+	return csr
+}
+
+func TestReconcileWithSPIFFEIDs(t *testing.T) {
+	clientUsages := []certificatesv1.KeyUsage{
+		certificatesv1.UsageDigitalSignature,
+		certificatesv1.UsageKeyEncipherment,
+		certificatesv1.UsageClientAuth,
+	}
+	hubCSR := func(t *testing.T, username string, uris []*url.URL) certificatesv1.CertificateSigningRequestSpec {
+		return certificatesv1.CertificateSigningRequestSpec{
+			SignerName: certificatesv1.KubeAPIServerClientSignerName,
+			Username:   username,
+			Groups:     []string{user.NodesGroup},
+			Usages:     clientUsages,
+			Request:    newSVIDCSRData(t, "system:node:node1", []string{token.YurtHubCSROrg, user.NodesGroup}, uris),
+		}
+	}
+	svidCSR := func(t *testing.T, username string, id *url.URL) certificatesv1.CertificateSigningRequestSpec {
+		return certificatesv1.CertificateSigningRequestSpec{
+			SignerName: spiffe.SignerName,
+			Username:   username,
+			Groups:     []string{user.NodesGroup},
+			Usages:     clientUsages,
+			Request:    newSVIDCSRData(t, id.String(), nil, []*url.URL{id}),
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{apps.NodePoolLabel: "shanghai"},
+		},
+	}
+	pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Status:     appsv1beta1.NodePoolStatus{Nodes: []string{"node1"}},
+	}
+
+	testcases := map[string]struct {
+		spec         func(t *testing.T) certificatesv1.CertificateSigningRequestSpec
+		trustDomain  string
+		node         *corev1.Node
+		approved     bool
+		unrecognized bool
+	}{
+		"yurthub cert with node and nodepool identities": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:node:node1", spiffe.IDs("openyurt.io", "node1", "hangzhou", spiffe.ComponentYurtHub))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+			approved:    true,
+		},
+		"yurthub cert with identity of another nodepool": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:node:node1", spiffe.IDs("openyurt.io", "node1", "beijing", spiffe.ComponentYurtHub))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+		},
+		"yurthub cert of joining node": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:bootstrap:abcdef", spiffe.IDs("openyurt.io", "node1", "", spiffe.ComponentYurtHub))
+			},
+			trustDomain: "openyurt.io",
+			approved:    true,
+		},
+		"yurthub cert of joining node with nodepool identity": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:bootstrap:abcdef", spiffe.IDs("openyurt.io", "node1", "hangzhou", spiffe.ComponentYurtHub))
+			},
+			trustDomain: "openyurt.io",
+		},
+		"yurthub cert with nodepool identity of node label": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:node:node1", spiffe.IDs("openyurt.io", "node1", "shanghai", spiffe.ComponentYurtHub))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+		},
+		"yurthub cert with identities when spiffe is disabled": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return hubCSR(t, "system:node:node1", spiffe.IDs("openyurt.io", "node1", "", spiffe.ComponentYurtHub))
+			},
+			node: node,
+		},
+		"raven agent svid of the requesting node": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return svidCSR(t, "system:node:node1", spiffe.NodeID("openyurt.io", "node1", spiffe.ComponentRavenAgent))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+			approved:    true,
+		},
+		"raven agent svid of another node": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return svidCSR(t, "system:node:node1", spiffe.NodeID("openyurt.io", "node2", spiffe.ComponentRavenAgent))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+		},
+		"raven agent svid of the nodepool": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return svidCSR(t, "system:node:node1", spiffe.NodePoolID("openyurt.io", "hangzhou", spiffe.ComponentRavenAgent))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+			approved:    true,
+		},
+		"raven agent svid signed by kube-apiserver-client signer": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				spec := svidCSR(t, "system:node:node1", spiffe.NodeID("openyurt.io", "node1", spiffe.ComponentRavenAgent))
+				spec.SignerName = certificatesv1.KubeAPIServerClientSignerName
+				return spec
+			},
+			trustDomain:  "openyurt.io",
+			node:         node,
+			unrecognized: true,
+		},
+		"raven agent svid of another trust domain": {
+			spec: func(t *testing.T) certificatesv1.CertificateSigningRequestSpec {
+				return svidCSR(t, "system:node:node1", spiffe.NodePoolID("example.com", "hangzhou", spiffe.ComponentRavenAgent))
+			},
+			trustDomain: "openyurt.io",
+			node:        node,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			csr := &certificatesv1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "csr-1"},
+				Spec:       tc.spec(t),
+			}
+			objs := []client.Object{csr, pool, newBootstrapTokenSecret("abcdef", "")}
+			if tc.node != nil {
+				objs = append(objs, tc.node)
+			}
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("could not add client-go scheme, %v", err)
+			}
+			apis.AddToScheme(scheme)
+			recorder := record.NewFakeRecorder(10)
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := &ReconcileCsrApprover{
+				Client:            c,
+				apiReader:         c,
+				csrV1Supported:    true,
+				csrApproverClient: fake.NewSimpleClientset(csr),
+				cfg:               config.CsrApproverControllerConfiguration{SPIFFETrustDomain: tc.trustDomain},
+				recorder:          recorder,
+			}
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: csr.Name}}); err != nil {
+				t.Fatalf("could not reconcile, %v", err)
+			}
+			got, err := r.csrApproverClient.CertificatesV1().CertificateSigningRequests().Get(context.Background(), csr.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("could not get csr, %v", err)
+			}
+			if approved, _ := checkCertApprovalCondition(&got.Status); approved != tc.approved {
+				t.Errorf("expect approved %v, but got %v", tc.approved, approved)
+			}
+			if !tc.approved && !tc.unrecognized {
+				if event := <-recorder.Events; !strings.Contains(event, "InvalidSPIFFEID") {
+					t.Errorf("expect an InvalidSPIFFEID event, but got %s", event)
+				}
+			}
+		})
+	}
+}
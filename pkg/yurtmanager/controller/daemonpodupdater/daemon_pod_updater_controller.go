@@ -21,6 +21,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -50,8 +51,11 @@ import (
 
 	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	k8sutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/daemonpodupdater/kubernetes"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/maintenance"
 	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/progress"
 )
 
 func init() {
@@ -78,6 +82,11 @@ const (
 	// For more details, see https://github.com/openyurtio/openyurt/pull/921.
 	AutoUpdate            = "Auto"
 	AdvancedRollingUpdate = "AdvancedRollingUpdate"
+	// PoolOrderedUpdate set DaemonSet to update NodePools one by one in the order specified by
+	// "apps.openyurt.io/nodepool-update-order". Pods in a NodePool are updated like AdvancedRollingUpdate,
+	// and the next NodePool is not started until all pods on ready nodes of the previous ones are
+	// updated and available. Nodes which are not in the specified NodePools are updated at last.
+	PoolOrderedUpdate = "PoolOrdered"
 
 	// PodNeedUpgrade indicates whether the pod is able to upgrade.
 	PodNeedUpgrade corev1.PodConditionType = "PodNeedUpgrade"
@@ -88,6 +97,28 @@ const (
 	MaxUnavailableAnnotation = "apps.openyurt.io/max-unavailable"
 	DefaultMaxUnavailable    = "10%"
 
+	// MaxUnavailablePerNodePoolAnnotation is the annotation key added to DaemonSet to indicate the max unavailable
+	// pods number in each NodePool, it works together with "apps.openyurt.io/max-unavailable". A percentage is
+	// scaled by the number of nodes in the NodePool and rounded up, and at least one pod in each NodePool can be
+	// unavailable. Nodes that don't belong to any NodePool are only limited by the cluster-wide max unavailable.
+	MaxUnavailablePerNodePoolAnnotation = "apps.openyurt.io/max-unavailable-per-nodepool"
+
+	// NodePoolUpdateOrderAnnotation is the annotation key added to DaemonSet to specify the comma separated
+	// NodePools in the order of update. It's used with "apps.openyurt.io/update-strategy=PoolOrdered".
+	NodePoolUpdateOrderAnnotation = "apps.openyurt.io/nodepool-update-order"
+
+	// PartitionAnnotation is the annotation key added to DaemonSet to indicate the number of nodes whose pods are
+	// kept at the old revision, it's used with "apps.openyurt.io/update-strategy=AdvancedRollingUpdate" or
+	// "PoolOrdered". It can be an absolute number or a percentage of nodes, and a percentage is rounded up.
+	// Negative values are invalid.
+	PartitionAnnotation = "apps.openyurt.io/partition"
+
+	// PartitionPerNodePoolAnnotation is the annotation key added to DaemonSet to indicate the number of nodes in
+	// each NodePool whose pods are kept at the old revision, it works together with "apps.openyurt.io/partition".
+	// A percentage is scaled by the number of nodes in the NodePool and rounded up. Nodes that don't belong to
+	// any NodePool are only limited by the cluster-wide partition.
+	PartitionPerNodePoolAnnotation = "apps.openyurt.io/partition-per-nodepool"
+
 	// BurstReplicas is a rate limiter for booting pods on a lot of pods.
 	// The value of 250 is chosen b/c values that are too high can cause registry DoS issues.
 	BurstReplicas = 250
@@ -218,6 +249,7 @@ func (r *ReconcileDaemonpodupdater) Reconcile(_ context.Context, request reconci
 		klog.Errorf("Fail to get DaemonSet %v, %v", request.NamespacedName, err)
 		if apierrors.IsNotFound(err) {
 			r.expectations.DeleteExpectations(request.NamespacedName.String())
+			progress.Forget(progress.KindDaemonSet, request.Namespace, request.Name)
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
@@ -240,24 +272,48 @@ func (r *ReconcileDaemonpodupdater) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 
+	// Pods are only updated on the nodes in maintenance windows
+	windows, err := maintenance.WindowsFromAnnotations(instance.Annotations)
+	if err != nil {
+		klog.Errorf(Format("Fail to get maintenance windows of DaemonSet %v: %v", request.NamespacedName, err))
+		return reconcile.Result{}, nil
+	}
+	checker, err := maintenance.NewChecker(r.Client, windows, time.Now())
+	if err != nil {
+		klog.Errorf(Format("Fail to parse maintenance windows of DaemonSet %v: %v", request.NamespacedName, err))
+		return reconcile.Result{}, nil
+	}
+
 	switch v {
 	case OTAUpdate:
-		if err := r.otaUpdate(instance); err != nil {
+		if err := r.otaUpdate(instance, checker); err != nil {
 			klog.Errorf(Format("Fail to OTA update DaemonSet %v pod: %v", request.NamespacedName, err))
 			return reconcile.Result{}, err
 		}
 
 	case AutoUpdate, AdvancedRollingUpdate:
-		if err := r.advancedRollingUpdate(instance); err != nil {
+		if err := r.advancedRollingUpdate(instance, checker, nil); err != nil {
 			klog.Errorf(Format("Fail to advanced rolling update DaemonSet %v pod: %v", request.NamespacedName, err))
 			return reconcile.Result{}, err
 		}
+
+	case PoolOrderedUpdate:
+		if err := r.poolOrderedUpdate(instance, checker); err != nil {
+			klog.Errorf(Format("Fail to pool ordered update DaemonSet %v pod: %v", request.NamespacedName, err))
+			return reconcile.Result{}, err
+		}
 	default:
 		klog.Errorf(Format("Unknown update type for DaemonSet %v pod: %v", request.NamespacedName, v))
 		return reconcile.Result{}, fmt.Errorf("unknown update type %v", v)
 	}
 
-	return reconcile.Result{}, nil
+	if err := r.recordProgress(instance); err != nil {
+		klog.Errorf(Format("Fail to record update progress of DaemonSet %v: %v", request.NamespacedName, err))
+		return reconcile.Result{}, err
+	}
+
+	// Requeue when the maintenance window of a waiting node starts
+	return reconcile.Result{RequeueAfter: checker.RequeueAfter()}, nil
 }
 
 func (r *ReconcileDaemonpodupdater) deletePod(evt event.DeleteEvent, _ workqueue.RateLimitingInterface) {
@@ -295,13 +351,24 @@ func (r *ReconcileDaemonpodupdater) deletePod(evt event.DeleteEvent, _ workqueue
 // otaUpdate compare every pod to its owner DaemonSet to check if pod is updatable
 // If pod is in line with the latest DaemonSet spec, set pod condition "PodNeedUpgrade" to "false"
 // while not, set pod condition "PodNeedUpgrade" to "true"
-func (r *ReconcileDaemonpodupdater) otaUpdate(ds *appsv1.DaemonSet) error {
+// Pods on the nodes out of maintenance windows are not updatable, their condition is set to "false"
+func (r *ReconcileDaemonpodupdater) otaUpdate(ds *appsv1.DaemonSet, checker *maintenance.Checker) error {
 	pods, err := GetDaemonsetPods(r.Client, ds)
 	if err != nil {
 		return err
 	}
 
 	for _, pod := range pods {
+		allowed, err := checker.Allowed(pod.Spec.NodeName)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			if err := updatePodUpgradeCondition(r.Client, pod, corev1.ConditionFalse); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := SetPodUpgradeCondition(r.Client, ds, pod); err != nil {
 			return err
 		}
@@ -310,8 +377,9 @@ func (r *ReconcileDaemonpodupdater) otaUpdate(ds *appsv1.DaemonSet) error {
 }
 
 // advancedRollingUpdate identifies the set of old pods to delete within the constraints imposed by the max-unavailable number.
-// Just ignore and do not calculate not-ready nodes.
-func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet) error {
+// Just ignore and do not calculate not-ready nodes. Old pods on the nodes out of maintenance windows are not deleted,
+// neither are the ones on the nodes rejected by nodeFilter if it's not nil.
+func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet, checker *maintenance.Checker, nodeFilter func(nodeName string) bool) error {
 	nodeToDaemonPods, err := r.getNodesToDaemonPods(ds)
 	if err != nil {
 		return fmt.Errorf("couldn't get node to daemon pod mapping for daemon set %q: %v", ds.Name, err)
@@ -323,9 +391,24 @@ func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet)
 		return fmt.Errorf("couldn't get maxUnavailable number for daemon set %q: %v", ds.Name, err)
 	}
 
+	// Calculate maxUnavailable of each NodePool if it's specified by user
+	nodeToPool, poolMaxUnavailable, err := r.poolMaxUnavailableCounts(ds, nodeToDaemonPods)
+	if err != nil {
+		return fmt.Errorf("couldn't get maxUnavailable number of nodepools for daemon set %q: %v", ds.Name, err)
+	}
+
+	// Calculate how many nodes are allowed to start updating under the partitions if they're specified by user
+	budget, err := r.partitionBudgetOf(ds, nodeToDaemonPods)
+	if err != nil {
+		return fmt.Errorf("couldn't get partition for daemon set %q: %v", ds.Name, err)
+	}
+
 	var numUnavailable int
 	var allowedReplacementPods []string
 	var candidatePodsToDelete []string
+	var candidateNodes []string
+	var candidatePools []string
+	poolUnavailable := make(map[string]int)
 
 	for nodeName, pods := range nodeToDaemonPods {
 		// Check if node is ready, ignore not-ready node
@@ -338,26 +421,43 @@ func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet)
 			continue
 		}
 
+		pool := nodeToPool[nodeName]
 		newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods)
 		if !ok {
 			// Let the manage loop clean up this node, and treat it as an unavailable node
 			klog.V(3).Infof("DaemonSet %s/%s has excess pods on node %s, skipping to allow the core loop to process", ds.Namespace, ds.Name, nodeName)
 			numUnavailable++
+			poolUnavailable[pool]++
 			continue
 		}
 		switch {
 		case oldPod == nil && newPod == nil, oldPod != nil && newPod != nil:
 			// The manage loop will handle creating or deleting the appropriate pod, consider this unavailable
 			numUnavailable++
+			poolUnavailable[pool]++
 		case newPod != nil:
 			// This pod is up-to-date, check its availability
 			if !podutil.IsPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: time.Now()}) {
 				// An unavailable new pod is counted against maxUnavailable
 				numUnavailable++
+				poolUnavailable[pool]++
 			}
 		default:
-			// This pod is old, it is an update candidate
+			// This pod is old, it is an update candidate if the node is in maintenance windows
+			allowed, err := checker.Allowed(nodeName)
+			if err != nil {
+				return fmt.Errorf("couldn't check maintenance windows of node %q, %v", nodeName, err)
+			}
 			switch {
+			case !allowed:
+				klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is out of date, but node is out of maintenance windows", ds.Namespace, ds.Name, oldPod.Name, nodeName)
+				continue
+			case nodeFilter != nil && !nodeFilter(nodeName):
+				klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is out of date, but node is not allowed to update yet", ds.Namespace, ds.Name, oldPod.Name, nodeName)
+				continue
+			case !budget.allowed(nodeName):
+				klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is out of date, but it's kept by partition", ds.Namespace, ds.Name, oldPod.Name, nodeName)
+				continue
 			case !podutil.IsPodAvailable(oldPod, ds.Spec.MinReadySeconds, metav1.Time{Time: time.Now()}):
 				// The old pod isn't available, so it needs to be replaced
 				klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is out of date and not available, allowing replacement", ds.Namespace, ds.Name, oldPod.Name, nodeName)
@@ -366,6 +466,9 @@ func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet)
 					allowedReplacementPods = make([]string, 0, len(nodeToDaemonPods))
 				}
 				allowedReplacementPods = append(allowedReplacementPods, oldPod.Name)
+				budget.take(nodeName)
+				// The unavailable old pod still takes the unavailable budget of its NodePool
+				poolUnavailable[pool]++
 			case numUnavailable >= maxUnavailable:
 				// No point considering any other candidates
 				continue
@@ -376,23 +479,140 @@ func (r *ReconcileDaemonpodupdater) advancedRollingUpdate(ds *appsv1.DaemonSet)
 					candidatePodsToDelete = make([]string, 0, maxUnavailable)
 				}
 				candidatePodsToDelete = append(candidatePodsToDelete, oldPod.Name)
+				candidateNodes = append(candidateNodes, nodeName)
+				candidatePools = append(candidatePools, pool)
 			}
 		}
 	}
 	// Use any of the candidates we can, including the allowedReplacemnntPods
 	klog.V(5).Infof("DaemonSet %s/%s allowing %d replacements, up to %d unavailable, %d are unavailable, %d candidates", ds.Namespace, ds.Name, len(allowedReplacementPods), maxUnavailable, numUnavailable, len(candidatePodsToDelete))
 	remainingUnavailable := maxUnavailable - numUnavailable
-	if remainingUnavailable < 0 {
-		remainingUnavailable = 0
-	}
-	if max := len(candidatePodsToDelete); remainingUnavailable > max {
-		remainingUnavailable = max
+	oldPodsToDelete := allowedReplacementPods
+	for i, name := range candidatePodsToDelete {
+		if remainingUnavailable <= 0 {
+			break
+		}
+		// Skip the candidate if its NodePool has no unavailable budget left, or it's kept by partition
+		max, limited := poolMaxUnavailable[candidatePools[i]]
+		if limited && poolUnavailable[candidatePools[i]] >= max {
+			continue
+		}
+		if !budget.allowed(candidateNodes[i]) {
+			continue
+		}
+		if limited {
+			poolUnavailable[candidatePools[i]]++
+		}
+		budget.take(candidateNodes[i])
+		oldPodsToDelete = append(oldPodsToDelete, name)
+		remainingUnavailable--
 	}
-	oldPodsToDelete := append(allowedReplacementPods, candidatePodsToDelete[:remainingUnavailable]...)
 
 	return r.syncPodsOnNodes(ds, oldPodsToDelete)
 }
 
+// poolOrderedUpdate updates pods like advancedRollingUpdate, but only on the nodes of the NodePools whose
+// turn has come. The NodePools in the order are updated one by one, and the nodes of other NodePools or
+// without NodePool are updated at last. A NodePool is finished when the pods on all its ready nodes are
+// updated and available, which gates the update of the next one.
+func (r *ReconcileDaemonpodupdater) poolOrderedUpdate(ds *appsv1.DaemonSet, checker *maintenance.Checker) error {
+	nodeToDaemonPods, err := r.getNodesToDaemonPods(ds)
+	if err != nil {
+		return fmt.Errorf("couldn't get node to daemon pod mapping for daemon set %q: %v", ds.Name, err)
+	}
+	nodeToPool, err := r.getNodesToPools(nodeToDaemonPods)
+	if err != nil {
+		return fmt.Errorf("couldn't get nodepools of nodes for daemon set %q: %v", ds.Name, err)
+	}
+
+	// Old pods kept by the partition of NodePool don't block the update of the next NodePool
+	_, poolPartitions, err := r.partitions(ds, nodeToDaemonPods, nodeToPool)
+	if err != nil {
+		return fmt.Errorf("couldn't get partition for daemon set %q: %v", ds.Name, err)
+	}
+
+	order := nodePoolUpdateOrder(ds)
+	// stage of the node is the index of its NodePool in order, or len(order) if its NodePool is not in order
+	stageOf := func(nodeName string) int {
+		for i, pool := range order {
+			if nodeToPool[nodeName] == pool {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	// the current stage is the first one which has nodes not finished
+	current := len(order)
+	stageOldPods := make(map[int]int)
+	for nodeName, pods := range nodeToDaemonPods {
+		stage := stageOf(nodeName)
+		if stage >= current {
+			continue
+		}
+		ready, err := NodeReadyByName(r.Client, nodeName)
+		if err != nil {
+			return fmt.Errorf("couldn't check node %q ready status, %v", nodeName, err)
+		}
+		if !ready {
+			continue
+		}
+		newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods)
+		if ok && oldPod != nil && newPod == nil {
+			stageOldPods[stage]++
+			continue
+		}
+		if !ok || oldPod != nil || newPod == nil ||
+			!podutil.IsPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: time.Now()}) {
+			current = stage
+		}
+	}
+	for stage, num := range stageOldPods {
+		if stage >= current {
+			continue
+		}
+		if partition, ok := poolPartitions[order[stage]]; !ok || num > partition {
+			current = stage
+		}
+	}
+
+	if current < len(order) {
+		klog.V(4).Infof("DaemonSet %s/%s is updating nodepool %s", ds.Namespace, ds.Name, order[current])
+	} else {
+		klog.V(4).Infof("DaemonSet %s/%s is updating nodes out of nodepools %v", ds.Namespace, ds.Name, order)
+	}
+	return r.advancedRollingUpdate(ds, checker, func(nodeName string) bool {
+		return stageOf(nodeName) <= current
+	})
+}
+
+// recordProgress exports the number of nodes in each update state of DaemonSet, and emits an event
+// for each updated pod which failed or is crash looping
+func (r *ReconcileDaemonpodupdater) recordProgress(ds *appsv1.DaemonSet) error {
+	pods, err := GetDaemonsetPods(r.Client, ds)
+	if err != nil {
+		return err
+	}
+
+	var p progress.Progress
+	for _, pod := range pods {
+		switch {
+		case IsDaemonsetPodLatest(ds, pod) && isPodFailed(pod):
+			p.Failed++
+			r.recorder.Eventf(ds, corev1.EventTypeWarning, "DaemonPodUpdateFailed",
+				"updated pod %s on node %s failed or is crash looping", pod.Name, pod.Spec.NodeName)
+		case IsDaemonsetPodLatest(ds, pod):
+			p.Upgraded++
+		case pod.DeletionTimestamp != nil || IsPodUpdatable(pod):
+			p.Approved++
+		default:
+			p.Pending++
+		}
+	}
+	progress.Record(progress.KindDaemonSet, ds.Namespace, ds.Name, p)
+	return nil
+}
+
 // getNodesToDaemonPods returns a map from nodes to daemon pods (corresponding to ds) created for the nodes.
 func (r *ReconcileDaemonpodupdater) getNodesToDaemonPods(ds *appsv1.DaemonSet) (map[string][]*corev1.Pod, error) {
 	// Ignore adopt/orphan pod, just deal with pods in podLister
@@ -484,6 +704,191 @@ func (r *ReconcileDaemonpodupdater) maxUnavailableCounts(ds *appsv1.DaemonSet, n
 	return maxUnavailable, nil
 }
 
+// poolMaxUnavailableCounts returns the NodePool of each node and the number of allowed unavailable pods
+// in each NodePool, nothing is returned if max unavailable per NodePool is not specified.
+func (r *ReconcileDaemonpodupdater) poolMaxUnavailableCounts(ds *appsv1.DaemonSet, nodeToDaemonPods map[string][]*corev1.Pod) (map[string]string, map[string]int, error) {
+	v, ok := ds.Annotations[MaxUnavailablePerNodePoolAnnotation]
+	if !ok {
+		return nil, nil, nil
+	}
+	intstrv := intstrutil.Parse(v)
+
+	nodeToPool, err := r.getNodesToPools(nodeToDaemonPods)
+	if err != nil {
+		return nil, nil, err
+	}
+	poolNodes := make(map[string]int)
+	for _, pool := range nodeToPool {
+		poolNodes[pool]++
+	}
+
+	poolMaxUnavailable := make(map[string]int, len(poolNodes))
+	for pool, num := range poolNodes {
+		maxUnavailable, err := intstrutil.GetScaledValueFromIntOrPercent(&intstrv, num, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for MaxUnavailablePerNodePool: %v", err)
+		}
+		if maxUnavailable < 1 {
+			maxUnavailable = 1
+		}
+		poolMaxUnavailable[pool] = maxUnavailable
+	}
+
+	klog.V(5).Infof("DaemonSet %s/%s, maxUnavailable of nodepools: %v", ds.Namespace, ds.Name, poolMaxUnavailable)
+	return nodeToPool, poolMaxUnavailable, nil
+}
+
+// partitions returns the number of nodes whose pods are kept at the old revision and the number in each NodePool.
+// A returned partition of -1 means it's not specified, and nil NodePool partitions mean partition per NodePool is not
+// specified. nodeToPool is fetched if it's nil and needed.
+func (r *ReconcileDaemonpodupdater) partitions(ds *appsv1.DaemonSet, nodeToDaemonPods map[string][]*corev1.Pod,
+	nodeToPool map[string]string) (int, map[string]int, error) {
+	partition := -1
+	if v, ok := ds.Annotations[PartitionAnnotation]; ok {
+		num, err := parsePartition(v, len(nodeToDaemonPods))
+		if err != nil {
+			return -1, nil, fmt.Errorf("invalid value for Partition: %v", err)
+		}
+		partition = num
+	}
+
+	v, ok := ds.Annotations[PartitionPerNodePoolAnnotation]
+	if !ok {
+		return partition, nil, nil
+	}
+	if nodeToPool == nil {
+		var err error
+		if nodeToPool, err = r.getNodesToPools(nodeToDaemonPods); err != nil {
+			return -1, nil, err
+		}
+	}
+	poolNodes := make(map[string]int)
+	for _, pool := range nodeToPool {
+		poolNodes[pool]++
+	}
+
+	poolPartitions := make(map[string]int, len(poolNodes))
+	for pool, num := range poolNodes {
+		p, err := parsePartition(v, num)
+		if err != nil {
+			return -1, nil, fmt.Errorf("invalid value for PartitionPerNodePool: %v", err)
+		}
+		poolPartitions[pool] = p
+	}
+
+	klog.V(5).Infof("DaemonSet %s/%s, partition: %d, partition of nodepools: %v", ds.Namespace, ds.Name, partition, poolPartitions)
+	return partition, poolPartitions, nil
+}
+
+// parsePartition scales the partition of absolute number or percentage to the number of nodes,
+// negative partitions are rejected instead of being treated as not specified.
+func parsePartition(v string, total int) (int, error) {
+	intstrv := intstrutil.Parse(v)
+	num, err := intstrutil.GetScaledValueFromIntOrPercent(&intstrv, total, true)
+	if err != nil {
+		return -1, err
+	}
+	if num < 0 || (intstrv.Type == intstrutil.String && strings.HasPrefix(intstrv.StrVal, "-")) {
+		return -1, fmt.Errorf("partition %q must not be negative", v)
+	}
+	return num, nil
+}
+
+// partitionBudgetOf returns how many nodes are allowed to start updating under the partitions, a nil budget
+// is returned if no partition is specified. Nodes which are not running an old pod are counted as updated.
+func (r *ReconcileDaemonpodupdater) partitionBudgetOf(ds *appsv1.DaemonSet, nodeToDaemonPods map[string][]*corev1.Pod) (*partitionBudget, error) {
+	_, ok1 := ds.Annotations[PartitionAnnotation]
+	_, ok2 := ds.Annotations[PartitionPerNodePoolAnnotation]
+	if !ok1 && !ok2 {
+		return nil, nil
+	}
+
+	var nodeToPool map[string]string
+	if ok2 {
+		var err error
+		if nodeToPool, err = r.getNodesToPools(nodeToDaemonPods); err != nil {
+			return nil, err
+		}
+	}
+	partition, poolPartitions, err := r.partitions(ds, nodeToDaemonPods, nodeToPool)
+	if err != nil {
+		return nil, err
+	}
+
+	// The budget is the number of nodes beyond the partition minus the updated ones
+	budget := &partitionBudget{limited: partition >= 0, remaining: len(nodeToDaemonPods) - partition, nodeToPool: nodeToPool}
+	if poolPartitions != nil {
+		budget.poolRemaining = make(map[string]int, len(poolPartitions))
+		for _, pool := range nodeToPool {
+			budget.poolRemaining[pool]++
+		}
+		for pool, p := range poolPartitions {
+			budget.poolRemaining[pool] -= p
+		}
+	}
+	for nodeName, pods := range nodeToDaemonPods {
+		if newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods); ok && oldPod != nil && newPod == nil {
+			continue
+		}
+		budget.take(nodeName)
+	}
+	return budget, nil
+}
+
+// partitionBudget is the number of nodes which are allowed to start updating under the partitions.
+type partitionBudget struct {
+	// limited is false if the update is not limited by the cluster-wide partition
+	limited       bool
+	remaining     int
+	nodeToPool    map[string]string
+	poolRemaining map[string]int
+}
+
+// allowed checks whether the pod on node is allowed to be updated, all nodes are allowed by a nil budget.
+func (b *partitionBudget) allowed(nodeName string) bool {
+	if b == nil {
+		return true
+	}
+	if b.limited && b.remaining <= 0 {
+		return false
+	}
+	if pool, ok := b.nodeToPool[nodeName]; ok && b.poolRemaining != nil && b.poolRemaining[pool] <= 0 {
+		return false
+	}
+	return true
+}
+
+// take consumes the budget of node.
+func (b *partitionBudget) take(nodeName string) {
+	if b == nil {
+		return
+	}
+	if b.limited {
+		b.remaining--
+	}
+	if pool, ok := b.nodeToPool[nodeName]; ok && b.poolRemaining != nil {
+		b.poolRemaining[pool]--
+	}
+}
+
+// getNodesToPools returns a map from nodes to the NodePools they belong to, nodes without NodePool are not included.
+func (r *ReconcileDaemonpodupdater) getNodesToPools(nodeToDaemonPods map[string][]*corev1.Pod) (map[string]string, error) {
+	nodeToPool := make(map[string]string, len(nodeToDaemonPods))
+	for nodeName := range nodeToDaemonPods {
+		node := &corev1.Node{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pool := node.Labels[apps.NodePoolLabel]; len(pool) != 0 {
+			nodeToPool[nodeName] = pool
+		}
+	}
+	return nodeToPool, nil
+}
+
 // resolveControllerRef returns the controller referenced by a ControllerRef,
 // or nil if the ControllerRef could not be resolved to a matching controller
 // of the correct Kind.
@@ -21,20 +21,27 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	k8sutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/daemonpodupdater/kubernetes"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/maintenance"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/progress"
 )
 
 const (
@@ -374,3 +381,335 @@ func TestController_maxUnavailableCounts(t *testing.T) {
 		})
 	}
 }
+
+func TestAdvancedRollingUpdatePerNodePool(t *testing.T) {
+	tests := []struct {
+		name            string
+		poolUnavailable string
+		notReadyPods    sets.String
+		wantDeletePods  map[string]int
+	}{
+		{
+			name:            "one pod of each nodepool",
+			poolUnavailable: "1",
+			wantDeletePods:  map[string]int{"hangzhou": 1, "beijing": 1, "": 2},
+		},
+		{
+			name:            "percent of nodepool is rounded up",
+			poolUnavailable: "50%",
+			wantDeletePods:  map[string]int{"hangzhou": 1, "beijing": 1, "": 2},
+		},
+		{
+			name:            "unavailable old pod takes the budget of its nodepool",
+			poolUnavailable: "1",
+			notReadyPods:    sets.NewString("node-hangzhou-1"),
+			wantDeletePods:  map[string]int{"hangzhou": 1, "beijing": 1, "": 2},
+		},
+		{
+			name:            "all pods of nodepool",
+			poolUnavailable: "100%",
+			wantDeletePods:  map[string]int{"hangzhou": 2, "beijing": 2, "": 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := newDaemonSet("ds", "foo/bar:v1")
+			setOnDelete(ds)
+			setAutoUpdateAnnotation(ds)
+			setMaxUnavailableAnnotation(ds, "100%")
+			metav1.SetMetaDataAnnotation(&ds.ObjectMeta, MaxUnavailablePerNodePoolAnnotation, test.poolUnavailable)
+
+			var objs []client.Object
+			podToPool := make(map[string]string)
+			for _, node := range []struct{ name, pool string }{
+				{"node-hangzhou-1", "hangzhou"}, {"node-hangzhou-2", "hangzhou"},
+				{"node-beijing-1", "beijing"}, {"node-beijing-2", "beijing"},
+				{"node-1", ""}, {"node-2", ""},
+			} {
+				n := newNode(node.name, true)
+				if len(node.pool) != 0 {
+					n.Labels = map[string]string{apps.NodePoolLabel: node.pool}
+				}
+				pod := newPod("pod-"+node.name, node.name, simpleDaemonSetLabel, ds)
+				if test.notReadyPods.Has(node.name) {
+					pod.Status.Conditions[0].Status = corev1.ConditionFalse
+				}
+				podToPool[pod.Name] = node.pool
+				objs = append(objs, n, pod)
+			}
+			ds.Spec.Template.Spec.Containers[0].Image = "foo/bar:v2"
+
+			podControl := &k8sutil.FakePodControl{}
+			r := &ReconcileDaemonpodupdater{
+				Client:       fakeclient.NewClientBuilder().WithObjects(ds).WithObjects(objs...).Build(),
+				expectations: k8sutil.NewControllerExpectations(),
+				podControl:   podControl,
+			}
+			checker, _ := maintenance.NewChecker(r.Client, nil, time.Now())
+			if err := r.advancedRollingUpdate(ds, checker, nil); err != nil {
+				t.Fatalf("failed to advanced rolling update, %v", err)
+			}
+
+			gotDeletePods := make(map[string]int)
+			for _, name := range podControl.DeletePodName {
+				gotDeletePods[podToPool[name]]++
+			}
+			assert.Equal(t, test.wantDeletePods, gotDeletePods)
+			if test.notReadyPods.Len() != 0 {
+				for _, name := range podControl.DeletePodName {
+					if podToPool[name] == "hangzhou" {
+						assert.Contains(t, name, "pod-node-hangzhou-1")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAdvancedRollingUpdatePartition(t *testing.T) {
+	tests := []struct {
+		name          string
+		partition     string
+		poolPartition string
+		updatedNodes  sets.String
+		wantDeleted   int
+		wantPoolMax   map[string]int
+		wantErr       bool
+	}{
+		{
+			name:        "absolute partition",
+			partition:   "2",
+			wantDeleted: 4,
+		},
+		{
+			name:        "percent partition is rounded up",
+			partition:   "40%",
+			wantDeleted: 3,
+		},
+		{
+			name:         "updated nodes take the budget",
+			partition:    "3",
+			updatedNodes: sets.NewString("node-1"),
+			wantDeleted:  2,
+		},
+		{
+			name:        "all nodes are kept",
+			partition:   "100%",
+			wantDeleted: 0,
+		},
+		{
+			name:          "partition of each nodepool",
+			poolPartition: "1",
+			wantDeleted:   4,
+			wantPoolMax:   map[string]int{"hangzhou": 1, "beijing": 1, "": 2},
+		},
+		{
+			name:          "partition of nodepool works with cluster-wide partition",
+			partition:     "4",
+			poolPartition: "50%",
+			wantDeleted:   2,
+			wantPoolMax:   map[string]int{"hangzhou": 1, "beijing": 1, "": 2},
+		},
+		{
+			name:      "negative partition is rejected",
+			partition: "-1",
+			wantErr:   true,
+		},
+		{
+			name:          "negative partition of nodepool is rejected",
+			poolPartition: "-50%",
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := newDaemonSet("ds", "foo/bar:v1")
+			setOnDelete(ds)
+			setAutoUpdateAnnotation(ds)
+			setMaxUnavailableAnnotation(ds, "100%")
+			if len(test.partition) != 0 {
+				metav1.SetMetaDataAnnotation(&ds.ObjectMeta, PartitionAnnotation, test.partition)
+			}
+			if len(test.poolPartition) != 0 {
+				metav1.SetMetaDataAnnotation(&ds.ObjectMeta, PartitionPerNodePoolAnnotation, test.poolPartition)
+			}
+			newDS := ds.DeepCopy()
+			newDS.Spec.Template.Spec.Containers[0].Image = "foo/bar:v2"
+
+			var objs []client.Object
+			podToPool := make(map[string]string)
+			for _, node := range []struct{ name, pool string }{
+				{"node-hangzhou-1", "hangzhou"}, {"node-hangzhou-2", "hangzhou"},
+				{"node-beijing-1", "beijing"}, {"node-beijing-2", "beijing"},
+				{"node-1", ""}, {"node-2", ""},
+			} {
+				n := newNode(node.name, true)
+				if len(node.pool) != 0 {
+					n.Labels = map[string]string{apps.NodePoolLabel: node.pool}
+				}
+				podDS := ds
+				if test.updatedNodes.Has(node.name) {
+					podDS = newDS
+				}
+				pod := newPod("pod-"+node.name, node.name, simpleDaemonSetLabel, podDS)
+				podToPool[pod.Name] = node.pool
+				objs = append(objs, n, pod)
+			}
+
+			podControl := &k8sutil.FakePodControl{}
+			r := &ReconcileDaemonpodupdater{
+				Client:       fakeclient.NewClientBuilder().WithObjects(newDS).WithObjects(objs...).Build(),
+				expectations: k8sutil.NewControllerExpectations(),
+				podControl:   podControl,
+			}
+			checker, _ := maintenance.NewChecker(r.Client, nil, time.Now())
+			if err := r.advancedRollingUpdate(newDS, checker, nil); (err != nil) != test.wantErr {
+				t.Fatalf("expect error %v, but got %v", test.wantErr, err)
+			}
+
+			assert.Len(t, podControl.DeletePodName, test.wantDeleted)
+			gotDeletePods := make(map[string]int)
+			for _, name := range podControl.DeletePodName {
+				gotDeletePods[podToPool[name]]++
+			}
+			for pool, max := range test.wantPoolMax {
+				assert.LessOrEqual(t, gotDeletePods[pool], max, "deleted pods of nodepool %q", pool)
+			}
+		})
+	}
+}
+
+func TestPoolOrderedUpdate(t *testing.T) {
+	tests := []struct {
+		name              string
+		poolPartition     string
+		updatedNodes      sets.String
+		notReadyNodes     sets.String
+		unavailablePods   sets.String
+		wantDeletePoolPod map[string]int
+	}{
+		{
+			name:              "update the first nodepool",
+			wantDeletePoolPod: map[string]int{"hangzhou": 2},
+		},
+		{
+			name:              "update the next nodepool after the previous one finished",
+			updatedNodes:      sets.NewString("node-hangzhou-1", "node-hangzhou-2"),
+			wantDeletePoolPod: map[string]int{"beijing": 2},
+		},
+		{
+			name:              "wait for updated pods of the nodepool to be available",
+			updatedNodes:      sets.NewString("node-hangzhou-1", "node-hangzhou-2"),
+			unavailablePods:   sets.NewString("node-hangzhou-2"),
+			wantDeletePoolPod: map[string]int{},
+		},
+		{
+			name:              "ignore not-ready nodes of the nodepool",
+			updatedNodes:      sets.NewString("node-hangzhou-1"),
+			notReadyNodes:     sets.NewString("node-hangzhou-2"),
+			wantDeletePoolPod: map[string]int{"beijing": 2},
+		},
+		{
+			name:              "old pods kept by partition of nodepool don't block the next nodepool",
+			poolPartition:     "50%",
+			updatedNodes:      sets.NewString("node-hangzhou-1"),
+			wantDeletePoolPod: map[string]int{"beijing": 1},
+		},
+		{
+			name:              "update nodes out of the nodepools at last",
+			updatedNodes:      sets.NewString("node-hangzhou-1", "node-hangzhou-2", "node-beijing-1", "node-beijing-2"),
+			wantDeletePoolPod: map[string]int{"": 1, "shanghai": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := newDaemonSet("ds", "foo/bar:v1")
+			setOnDelete(ds)
+			metav1.SetMetaDataAnnotation(&ds.ObjectMeta, UpdateAnnotation, PoolOrderedUpdate)
+			metav1.SetMetaDataAnnotation(&ds.ObjectMeta, NodePoolUpdateOrderAnnotation, "hangzhou, beijing")
+			setMaxUnavailableAnnotation(ds, "100%")
+			if len(test.poolPartition) != 0 {
+				metav1.SetMetaDataAnnotation(&ds.ObjectMeta, PartitionPerNodePoolAnnotation, test.poolPartition)
+			}
+			newDS := ds.DeepCopy()
+			newDS.Spec.Template.Spec.Containers[0].Image = "foo/bar:v2"
+
+			var objs []client.Object
+			podToPool := make(map[string]string)
+			for _, node := range []struct{ name, pool string }{
+				{"node-hangzhou-1", "hangzhou"}, {"node-hangzhou-2", "hangzhou"},
+				{"node-beijing-1", "beijing"}, {"node-beijing-2", "beijing"},
+				{"node-shanghai-1", "shanghai"}, {"node-1", ""},
+			} {
+				n := newNode(node.name, !test.notReadyNodes.Has(node.name))
+				if len(node.pool) != 0 {
+					n.Labels = map[string]string{apps.NodePoolLabel: node.pool}
+				}
+				podDS := ds
+				if test.updatedNodes.Has(node.name) {
+					podDS = newDS
+				}
+				pod := newPod("pod-"+node.name, node.name, simpleDaemonSetLabel, podDS)
+				if test.unavailablePods.Has(node.name) {
+					pod.Status.Conditions[0].Status = corev1.ConditionFalse
+				}
+				podToPool[pod.Name] = node.pool
+				objs = append(objs, n, pod)
+			}
+
+			podControl := &k8sutil.FakePodControl{}
+			r := &ReconcileDaemonpodupdater{
+				Client:       fakeclient.NewClientBuilder().WithObjects(newDS).WithObjects(objs...).Build(),
+				expectations: k8sutil.NewControllerExpectations(),
+				podControl:   podControl,
+			}
+			checker, _ := maintenance.NewChecker(r.Client, nil, time.Now())
+			if err := r.poolOrderedUpdate(newDS, checker); err != nil {
+				t.Fatalf("failed to pool ordered update, %v", err)
+			}
+
+			gotDeletePods := make(map[string]int)
+			for _, name := range podControl.DeletePodName {
+				gotDeletePods[podToPool[name]]++
+			}
+			assert.Equal(t, test.wantDeletePoolPod, gotDeletePods)
+		})
+	}
+}
+
+func TestRecordProgress(t *testing.T) {
+	ds := newDaemonSet("ds-progress", "foo/bar:v1")
+	pending := newPod("pod-pending", "node-1", simpleDaemonSetLabel, ds)
+	approved := newPod("pod-approved", "node-2", simpleDaemonSetLabel, ds)
+	approved.Status.Conditions = append(approved.Status.Conditions, corev1.PodCondition{Type: PodNeedUpgrade, Status: corev1.ConditionTrue})
+
+	ds.Spec.Template.Spec.Containers[0].Image = "foo/bar:v2"
+	upgraded := newPod("pod-upgraded", "node-3", simpleDaemonSetLabel, ds)
+	failed := newPod("pod-failed", "node-4", simpleDaemonSetLabel, ds)
+	failed.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileDaemonpodupdater{
+		Client:   fakeclient.NewClientBuilder().WithObjects(ds, pending, approved, upgraded, failed).Build(),
+		recorder: recorder,
+	}
+	if err := r.recordProgress(ds); err != nil {
+		t.Fatalf("failed to record progress, %v", err)
+	}
+
+	for state, want := range map[string]float64{
+		progress.StatePending:  1,
+		progress.StateApproved: 1,
+		progress.StateUpgraded: 1,
+		progress.StateFailed:   1,
+	} {
+		got := testutil.ToFloat64(progress.UpgradeNodes.WithLabelValues(progress.KindDaemonSet, ds.Namespace, ds.Name, state))
+		assert.Equal(t, want, got, "nodes in state %s", state)
+	}
+	assert.Len(t, recorder.Events, 1)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -123,6 +124,11 @@ func SetPodUpgradeCondition(c client.Client, ds *appsv1.DaemonSet, pod *corev1.P
 		status = corev1.ConditionTrue
 	}
 
+	return updatePodUpgradeCondition(c, pod, status)
+}
+
+// updatePodUpgradeCondition sets pod condition "PodNeedUpgrade" to the given status
+func updatePodUpgradeCondition(c client.Client, pod *corev1.Pod, status corev1.ConditionStatus) error {
 	cond := &corev1.PodCondition{
 		Type:   PodNeedUpgrade,
 		Status: status,
@@ -132,23 +138,34 @@ func SetPodUpgradeCondition(c client.Client, ds *appsv1.DaemonSet, pod *corev1.P
 		if err := c.Status().Update(context.TODO(), pod, &client.UpdateOptions{}); err != nil {
 			return err
 		}
-		klog.Infof("set pod %q condition PodNeedUpgrade to %v", pod.Name, !isUpdatable)
+		klog.Infof("set pod %q condition PodNeedUpgrade to %v", pod.Name, status == corev1.ConditionTrue)
 	}
 
 	return nil
 }
 
 // checkPrerequisites checks that daemonset meets two conditions
-// 1. annotation "apps.openyurt.io/update-strategy"="AdvancedRollingUpdate" or "OTA"
+// 1. annotation "apps.openyurt.io/update-strategy"="AdvancedRollingUpdate", "OTA" or "PoolOrdered"
 // 2. update strategy is "OnDelete"
 func checkPrerequisites(ds *appsv1.DaemonSet) bool {
 	v, ok := ds.Annotations[UpdateAnnotation]
-	if !ok || (v != AutoUpdate && v != OTAUpdate && v != AdvancedRollingUpdate) {
+	if !ok || (v != AutoUpdate && v != OTAUpdate && v != AdvancedRollingUpdate && v != PoolOrderedUpdate) {
 		return false
 	}
 	return ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType
 }
 
+// nodePoolUpdateOrder returns the NodePools in the order of update specified by NodePoolUpdateOrderAnnotation
+func nodePoolUpdateOrder(ds *appsv1.DaemonSet) []string {
+	var order []string
+	for _, pool := range strings.Split(ds.Annotations[NodePoolUpdateOrderAnnotation], ",") {
+		if pool = strings.TrimSpace(pool); len(pool) != 0 {
+			order = append(order, pool)
+		}
+	}
+	return order
+}
+
 // CloneAndAddLabel clones the given map and returns a new map with the given key and value added.
 // Returns the given map, if labelKey is empty.
 func CloneAndAddLabel(labels map[string]string, labelKey, labelValue string) map[string]string {
@@ -229,6 +246,19 @@ func GetTargetNodeName(pod *corev1.Pod) (string, error) {
 	return "", fmt.Errorf("no node name found for pod %s/%s", pod.Namespace, pod.Name)
 }
 
+// isPodFailed returns true if the pod failed or any of its containers is crash looping.
+func isPodFailed(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
+
 // IsPodUpdatable returns true if a pod is updatable; false otherwise.
 func IsPodUpdatable(pod *corev1.Pod) bool {
 	return IsPodUpgradeConditionTrue(pod.Status)
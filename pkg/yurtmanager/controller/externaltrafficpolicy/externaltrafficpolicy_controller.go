@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaltrafficpolicy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/externaltrafficpolicy"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "external-traffic-policy-workers", concurrentReconciles, "Max concurrent workers for external-traffic-policy-controller.")
+}
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = corev1.SchemeGroupVersion.WithKind("Service")
+)
+
+func Format(format string, args ...interface{}) string {
+	s := fmt.Sprintf(format, args...)
+	return fmt.Sprintf("%s: %s", names.ExternalTrafficPolicyController, s)
+}
+
+// ReconcileExternalTrafficPolicy sets externalTrafficPolicy=Local for the NodePort and LoadBalancer Services
+// annotated with openyurt.io/external-traffic-policy=nodepool, so kube-proxy only forwards the external traffic
+// to local endpoints, and the endpoints in the same NodePool are reported as local endpoints to kube-proxy by
+// the externaltrafficpolicy filter of yurthub. the health check node port is also allocated for LoadBalancer
+// Services, so the load balancer only sends traffic to the nodes which have endpoints in their NodePool.
+type ReconcileExternalTrafficPolicy struct {
+	client.Client
+	recorder record.EventRecorder
+}
+
+var _ reconcile.Reconciler = &ReconcileExternalTrafficPolicy{}
+
+// Add creates a new ExternalTrafficPolicy Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
+	klog.Infof(Format("external-traffic-policy-controller add controller %s", controllerKind.String()))
+	r := &ReconcileExternalTrafficPolicy{
+		Client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(names.ExternalTrafficPolicyController),
+	}
+
+	c, err := controller.New(names.ExternalTrafficPolicyController, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return needLocalPolicy(evt.Object)
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return needLocalPolicy(evt.ObjectNew)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return needLocalPolicy(evt.Object)
+		},
+	})
+}
+
+// needLocalPolicy checks whether the externalTrafficPolicy of service should be changed into Local.
+func needLocalPolicy(obj client.Object) bool {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		return false
+	}
+	return externaltrafficpolicy.IsPoolLocalExternalTraffic(svc) &&
+		svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal
+}
+
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;update;patch
+
+// Reconcile sets externalTrafficPolicy=Local for the Service whose external traffic should be kept in NodePool.
+// the externalTrafficPolicy is not reverted when the annotation is removed, because the original policy is unknown.
+func (r *ReconcileExternalTrafficPolicy) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var svc corev1.Service
+	if err := r.Get(ctx, req.NamespacedName, &svc); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if svc.DeletionTimestamp != nil || !needLocalPolicy(&svc) {
+		return reconcile.Result{}, nil
+	}
+
+	patch := client.MergeFrom(svc.DeepCopy())
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	if err := r.Patch(ctx, &svc, patch); err != nil {
+		klog.Errorf(Format("could not set externalTrafficPolicy of service %s/%s, %v", svc.Namespace, svc.Name, err))
+		return reconcile.Result{}, err
+	}
+	r.recorder.Eventf(&svc, corev1.EventTypeNormal, "ExternalTrafficPolicyUpdated",
+		"externalTrafficPolicy is set to Local, so the external traffic is only forwarded to the endpoints in the same NodePool")
+	klog.Infof(Format("externalTrafficPolicy of service %s/%s is set to Local", svc.Namespace, svc.Name))
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externaltrafficpolicy
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/externaltrafficpolicy"
+)
+
+func TestReconcile(t *testing.T) {
+	testcases := map[string]struct {
+		svcType      corev1.ServiceType
+		annotations  map[string]string
+		expectPolicy corev1.ServiceExternalTrafficPolicyType
+	}{
+		"NodePort service is annotated": {
+			svcType: corev1.ServiceTypeNodePort,
+			annotations: map[string]string{
+				externaltrafficpolicy.AnnotationExternalTrafficPolicy: externaltrafficpolicy.AnnotationExternalTrafficPolicyValueNodePool,
+			},
+			expectPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		},
+		"LoadBalancer service is annotated": {
+			svcType: corev1.ServiceTypeLoadBalancer,
+			annotations: map[string]string{
+				externaltrafficpolicy.AnnotationExternalTrafficPolicy: externaltrafficpolicy.AnnotationExternalTrafficPolicyValueNodePool,
+			},
+			expectPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		},
+		"service is not annotated": {
+			svcType:      corev1.ServiceTypeNodePort,
+			expectPolicy: corev1.ServiceExternalTrafficPolicyTypeCluster,
+		},
+		"ClusterIP service is annotated": {
+			svcType: corev1.ServiceTypeClusterIP,
+			annotations: map[string]string{
+				externaltrafficpolicy.AnnotationExternalTrafficPolicy: externaltrafficpolicy.AnnotationExternalTrafficPolicyValueNodePool,
+			},
+			expectPolicy: "",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "svc1",
+					Namespace:   "default",
+					Annotations: tc.annotations,
+				},
+				Spec: corev1.ServiceSpec{Type: tc.svcType},
+			}
+			if tc.svcType != corev1.ServiceTypeClusterIP {
+				svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+			}
+			c := fakeclient.NewClientBuilder().WithObjects(svc).Build()
+			r := &ReconcileExternalTrafficPolicy{
+				Client:   c,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: "svc1"}
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("could not reconcile, %v", err)
+			}
+
+			var got corev1.Service
+			if err := c.Get(context.Background(), key, &got); err != nil {
+				t.Fatalf("could not get service, %v", err)
+			}
+			if got.Spec.ExternalTrafficPolicy != tc.expectPolicy {
+				t.Errorf("expect externalTrafficPolicy %q, but got %q", tc.expectPolicy, got.Spec.ExternalTrafficPolicy)
+			}
+		})
+	}
+}
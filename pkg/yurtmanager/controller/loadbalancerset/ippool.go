@@ -0,0 +1,380 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancerset
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// maxScannedAddresses is the max number of addresses checked for allocating an address.
+const maxScannedAddresses = 1 << 16
+
+// addressRange is a range of continuous addresses, both start and end are included.
+type addressRange struct {
+	start *big.Int
+	end   *big.Int
+	ipv4  bool
+}
+
+// parseAddressRange parses a CIDR(like 192.168.10.0/28), a range(like 192.168.10.100-192.168.10.120)
+// or a single address. The network and broadcast addresses of IPv4 CIDR are excluded.
+func parseAddressRange(s string) (addressRange, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		ip, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return addressRange{}, err
+		}
+		ipv4 := ip.To4() != nil
+		ones, bits := ipNet.Mask.Size()
+		start := ipToInt(ipNet.IP)
+		end := new(big.Int).Add(start, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)), big.NewInt(1)))
+		if ipv4 && bits-ones >= 2 {
+			start.Add(start, big.NewInt(1))
+			end.Sub(end, big.NewInt(1))
+		}
+		return addressRange{start: start, end: end, ipv4: ipv4}, nil
+	}
+
+	parts := strings.SplitN(s, "-", 2)
+	first := net.ParseIP(strings.TrimSpace(parts[0]))
+	last := first
+	if len(parts) == 2 {
+		last = net.ParseIP(strings.TrimSpace(parts[1]))
+	}
+	if first == nil || last == nil {
+		return addressRange{}, fmt.Errorf("invalid address range %q", s)
+	}
+	if (first.To4() != nil) != (last.To4() != nil) {
+		return addressRange{}, fmt.Errorf("mixed ip families in address range %q", s)
+	}
+	r := addressRange{start: ipToInt(first), end: ipToInt(last), ipv4: first.To4() != nil}
+	if r.start.Cmp(r.end) > 0 {
+		return addressRange{}, fmt.Errorf("invalid address range %q, the start is greater than the end", s)
+	}
+	return r, nil
+}
+
+// size returns the number of addresses in the range, it's capped at math.MaxInt64.
+func (r addressRange) size() int64 {
+	n := new(big.Int).Sub(r.end, r.start)
+	n.Add(n, big.NewInt(1))
+	if !n.IsInt64() {
+		return math.MaxInt64
+	}
+	return n.Int64()
+}
+
+func (r addressRange) contains(ip net.IP) bool {
+	if (ip.To4() != nil) != r.ipv4 {
+		return false
+	}
+	i := ipToInt(ip)
+	return i.Cmp(r.start) >= 0 && i.Cmp(r.end) <= 0
+}
+
+func (r addressRange) overlaps(other addressRange) bool {
+	return r.ipv4 == other.ipv4 && r.start.Cmp(other.end) <= 0 && other.start.Cmp(r.end) <= 0
+}
+
+func ipToInt(ip net.IP) *big.Int {
+	if v4 := ip.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+func intToIP(i *big.Int, ipv4 bool) net.IP {
+	size := net.IPv6len
+	if ipv4 {
+		size = net.IPv4len
+	}
+	b := i.Bytes()
+	ip := make(net.IP, size)
+	copy(ip[size-len(b):], b)
+	return ip
+}
+
+// ipPool is an EdgeIPPool with the parsed address ranges.
+type ipPool struct {
+	pool   *appsv1alpha1.EdgeIPPool
+	ranges []addressRange
+	// invalid records the addresses of spec which can not be parsed.
+	invalid []string
+	// conflicts records the messages of conflicts with other EdgeIPPools or Services.
+	conflicts []string
+}
+
+func newIPPool(pool *appsv1alpha1.EdgeIPPool) *ipPool {
+	p := &ipPool{pool: pool}
+	for _, s := range pool.Spec.Addresses {
+		r, err := parseAddressRange(s)
+		if err != nil {
+			p.invalid = append(p.invalid, s)
+			continue
+		}
+		p.ranges = append(p.ranges, r)
+	}
+	return p
+}
+
+func (p *ipPool) contains(ip net.IP) bool {
+	for _, r := range p.ranges {
+		if r.contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ipPool) capacity() int64 {
+	var n int64
+	for _, r := range p.ranges {
+		if size := r.size(); n > math.MaxInt64-size {
+			n = math.MaxInt64
+		} else {
+			n += size
+		}
+	}
+	return n
+}
+
+// ipAllocator allocates addresses of the EdgeIPPools in a NodePool to LoadBalancer Services. The allocation
+// is deterministic: the allocated addresses are kept for Services, and the lowest free address of the pools
+// sorted by name is allocated to the Services sorted by namespace/name. The addresses overlapped with other
+// EdgeIPPools or used by Services statically are conflicted, and they are never allocated.
+type ipAllocator struct {
+	pools  []*ipPool
+	others []*ipPool
+	// allocated records the address allocated to the Service.
+	allocated map[string]string
+	// used records the addresses which are allocated or used by Services statically.
+	used map[string]string
+	// exhausted records the Services which can not get an address.
+	exhausted []string
+}
+
+// newIPAllocator creates an allocator for the NodePool with the EdgeIPPools of all NodePools, statics is the
+// addresses used by Services statically in the NodePool, and requests is the Services requesting an address.
+func newIPAllocator(nodePool string, pools []appsv1alpha1.EdgeIPPool, statics map[string]string, requests []string) *ipAllocator {
+	a := &ipAllocator{
+		allocated: make(map[string]string),
+		used:      make(map[string]string),
+	}
+	for i := range pools {
+		if pools[i].DeletionTimestamp != nil {
+			continue
+		}
+		p := newIPPool(&pools[i])
+		if pools[i].Spec.NodePool == nodePool {
+			a.pools = append(a.pools, p)
+		} else {
+			a.others = append(a.others, p)
+		}
+	}
+	sort.Slice(a.pools, func(i, j int) bool {
+		return a.pools[i].pool.Name < a.pools[j].pool.Name
+	})
+	a.detectOverlaps()
+
+	requested := make(map[string]bool, len(requests))
+	for _, svc := range requests {
+		requested[svc] = true
+	}
+	for ip, svc := range statics {
+		a.used[ip] = svc
+	}
+
+	// keep the allocated addresses which are still valid
+	for _, p := range a.pools {
+		for _, allocation := range p.pool.Status.Allocations {
+			ip := net.ParseIP(allocation.IP)
+			if !requested[allocation.Service] || ip == nil || !p.contains(ip) {
+				continue
+			}
+			if _, ok := a.allocated[allocation.Service]; ok {
+				continue
+			}
+			if owner, ok := a.used[ip.String()]; ok {
+				p.conflicts = append(p.conflicts, fmt.Sprintf("address %s allocated to %s is also used by %s", ip.String(), allocation.Service, owner))
+			}
+			a.allocated[allocation.Service] = ip.String()
+			a.used[ip.String()] = allocation.Service
+		}
+	}
+	for ip, svc := range statics {
+		for _, p := range a.pools {
+			if p.contains(net.ParseIP(ip)) && a.used[ip] == svc {
+				p.conflicts = append(p.conflicts, fmt.Sprintf("address %s is used by %s statically", ip, svc))
+			}
+		}
+	}
+
+	sorted := append([]string(nil), requests...)
+	sort.Strings(sorted)
+	for _, svc := range sorted {
+		if _, ok := a.allocated[svc]; ok {
+			continue
+		}
+		ip := a.nextFree()
+		if ip == nil {
+			a.exhausted = append(a.exhausted, svc)
+			continue
+		}
+		a.allocated[svc] = ip.String()
+		a.used[ip.String()] = svc
+	}
+	return a
+}
+
+// detectOverlaps records the conflicts of pools whose addresses overlap with other EdgeIPPools.
+func (a *ipAllocator) detectOverlaps() {
+	for i, p := range a.pools {
+		for j, other := range append(append([]*ipPool(nil), a.pools...), a.others...) {
+			if i == j {
+				continue
+			}
+			if overlapped(p, other) {
+				p.conflicts = append(p.conflicts, fmt.Sprintf("addresses overlap with EdgeIPPool %s", other.pool.Name))
+			}
+		}
+	}
+}
+
+func overlapped(p, other *ipPool) bool {
+	for _, r := range p.ranges {
+		for _, o := range other.ranges {
+			if r.overlaps(o) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextFree returns the lowest free address which is not used and not overlapped with other pools,
+// at most maxScannedAddresses addresses are checked, so a huge IPv6 range doesn't block the allocation.
+func (a *ipAllocator) nextFree() net.IP {
+	scanned := 0
+	for i, p := range a.pools {
+		for _, r := range p.ranges {
+			for cur := new(big.Int).Set(r.start); cur.Cmp(r.end) <= 0; cur.Add(cur, big.NewInt(1)) {
+				if scanned++; scanned > maxScannedAddresses {
+					return nil
+				}
+				ip := intToIP(cur, r.ipv4)
+				if _, ok := a.used[ip.String()]; ok || a.overlappedWithOthers(i, ip) {
+					continue
+				}
+				return ip
+			}
+		}
+	}
+	return nil
+}
+
+func (a *ipAllocator) overlappedWithOthers(index int, ip net.IP) bool {
+	for i, p := range a.pools {
+		if i != index && p.contains(ip) {
+			return true
+		}
+	}
+	for _, p := range a.others {
+		if p.contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// poolStatus returns the desired status of the EdgeIPPool.
+func (a *ipAllocator) poolStatus(p *ipPool) appsv1alpha1.EdgeIPPoolStatus {
+	status := appsv1alpha1.EdgeIPPoolStatus{
+		Capacity: p.capacity(),
+	}
+	for svc, ip := range a.allocated {
+		if p.contains(net.ParseIP(ip)) && !a.ownedByPrevious(p, ip) {
+			status.Allocations = append(status.Allocations, appsv1alpha1.EdgeIPAllocation{IP: ip, Service: svc})
+		}
+	}
+	sort.Slice(status.Allocations, func(i, j int) bool {
+		return status.Allocations[i].Service < status.Allocations[j].Service
+	})
+	status.Allocated = int64(len(status.Allocations))
+
+	exhausted := newCondition(appsv1alpha1.EdgeIPPoolExhausted, corev1.ConditionFalse, "AddressesAvailable", "")
+	if len(a.exhausted) != 0 {
+		exhausted = newCondition(appsv1alpha1.EdgeIPPoolExhausted, corev1.ConditionTrue, "NoFreeAddress",
+			fmt.Sprintf("no free address for services %s", strings.Join(a.exhausted, ", ")))
+	}
+	conflicted := newCondition(appsv1alpha1.EdgeIPPoolConflicted, corev1.ConditionFalse, "NoConflict", "")
+	if len(p.invalid) != 0 {
+		p.conflicts = append(p.conflicts, fmt.Sprintf("invalid addresses %s are ignored", strings.Join(p.invalid, ", ")))
+	}
+	if len(p.conflicts) != 0 {
+		sort.Strings(p.conflicts)
+		conflicted = newCondition(appsv1alpha1.EdgeIPPoolConflicted, corev1.ConditionTrue, "AddressConflict", strings.Join(p.conflicts, "; "))
+	}
+	status.Conditions = mergeConditions(p.pool.Status.Conditions, exhausted, conflicted)
+	return status
+}
+
+// ownedByPrevious checks whether the address is contained by a pool sorted before p,
+// so the allocation of overlapped addresses is only recorded in one pool.
+func (a *ipAllocator) ownedByPrevious(p *ipPool, ip string) bool {
+	for _, prev := range a.pools {
+		if prev == p {
+			return false
+		}
+		if prev.contains(net.ParseIP(ip)) {
+			return true
+		}
+	}
+	return false
+}
+
+func newCondition(condType appsv1alpha1.EdgeIPPoolConditionType, status corev1.ConditionStatus, reason, message string) appsv1alpha1.EdgeIPPoolCondition {
+	return appsv1alpha1.EdgeIPPoolCondition{
+		Type:    condType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// mergeConditions keeps the last transition time of conditions whose status is not changed.
+func mergeConditions(old []appsv1alpha1.EdgeIPPoolCondition, conditions ...appsv1alpha1.EdgeIPPoolCondition) []appsv1alpha1.EdgeIPPoolCondition {
+	for i := range conditions {
+		conditions[i].LastTransitionTime = metav1.Now()
+		for j := range old {
+			if old[j].Type == conditions[i].Type && old[j].Status == conditions[i].Status {
+				conditions[i].LastTransitionTime = old[j].LastTransitionTime
+			}
+		}
+	}
+	return conditions
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancerset
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func TestParseAddressRange(t *testing.T) {
+	testcases := map[string]struct {
+		address    string
+		expectSize int64
+		expectErr  bool
+	}{
+		"ipv4 cidr": {
+			address:    "192.168.10.0/29",
+			expectSize: 6,
+		},
+		"ipv4 /31 cidr": {
+			address:    "192.168.10.0/31",
+			expectSize: 2,
+		},
+		"ipv4 range": {
+			address:    "192.168.10.100-192.168.10.120",
+			expectSize: 21,
+		},
+		"single address": {
+			address:    "fd00::1",
+			expectSize: 1,
+		},
+		"reversed range": {
+			address:   "192.168.10.120-192.168.10.100",
+			expectErr: true,
+		},
+		"mixed ip families": {
+			address:   "192.168.10.100-fd00::1",
+			expectErr: true,
+		},
+		"invalid address": {
+			address:   "foo",
+			expectErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			r, err := parseAddressRange(tc.address)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.expectErr, err)
+			}
+			if err == nil && r.size() != tc.expectSize {
+				t.Errorf("expect size %d, but got %d", tc.expectSize, r.size())
+			}
+		})
+	}
+}
+
+func newEdgeIPPool(name, nodePool string, addresses []string, allocations ...appsv1alpha1.EdgeIPAllocation) appsv1alpha1.EdgeIPPool {
+	return appsv1alpha1.EdgeIPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: appsv1alpha1.EdgeIPPoolSpec{
+			NodePool:  nodePool,
+			Addresses: addresses,
+		},
+		Status: appsv1alpha1.EdgeIPPoolStatus{
+			Allocations: allocations,
+		},
+	}
+}
+
+func conditionStatus(status appsv1alpha1.EdgeIPPoolStatus, condType appsv1alpha1.EdgeIPPoolConditionType) corev1.ConditionStatus {
+	for _, c := range status.Conditions {
+		if c.Type == condType {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+func TestIPAllocator(t *testing.T) {
+	testcases := map[string]struct {
+		pools            []appsv1alpha1.EdgeIPPool
+		statics          map[string]string
+		requests         []string
+		expectAllocated  map[string]string
+		expectExhausted  []string
+		expectConflicted bool
+	}{
+		"allocate the lowest free addresses": {
+			pools: []appsv1alpha1.EdgeIPPool{
+				newEdgeIPPool("b", "hangzhou", []string{"192.168.20.1"}),
+				newEdgeIPPool("a", "hangzhou", []string{"192.168.10.1-192.168.10.2"}),
+			},
+			requests: []string{"default/web", "default/api", "default/db"},
+			expectAllocated: map[string]string{
+				"default/api": "192.168.10.1",
+				"default/db":  "192.168.10.2",
+				"default/web": "192.168.20.1",
+			},
+		},
+		"keep the allocated addresses and release the unused ones": {
+			pools: []appsv1alpha1.EdgeIPPool{
+				newEdgeIPPool("a", "hangzhou", []string{"192.168.10.1-192.168.10.2"},
+					appsv1alpha1.EdgeIPAllocation{IP: "192.168.10.2", Service: "default/web"},
+					appsv1alpha1.EdgeIPAllocation{IP: "192.168.10.1", Service: "default/deleted"}),
+			},
+			requests: []string{"default/web", "default/api"},
+			expectAllocated: map[string]string{
+				"default/api": "192.168.10.1",
+				"default/web": "192.168.10.2",
+			},
+		},
+		"pool is exhausted": {
+			pools: []appsv1alpha1.EdgeIPPool{
+				newEdgeIPPool("a", "hangzhou", []string{"192.168.10.1"}),
+			},
+			requests: []string{"default/web", "default/api"},
+			expectAllocated: map[string]string{
+				"default/api": "192.168.10.1",
+			},
+			expectExhausted: []string{"default/web"},
+		},
+		"conflicted addresses are not allocated": {
+			pools: []appsv1alpha1.EdgeIPPool{
+				newEdgeIPPool("a", "hangzhou", []string{"192.168.10.1-192.168.10.3"}),
+				newEdgeIPPool("c", "shanghai", []string{"192.168.10.2"}),
+			},
+			statics:  map[string]string{"192.168.10.1": "default/static"},
+			requests: []string{"default/web"},
+			expectAllocated: map[string]string{
+				"default/web": "192.168.10.3",
+			},
+			expectConflicted: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			a := newIPAllocator("hangzhou", tc.pools, tc.statics, tc.requests)
+			if !reflect.DeepEqual(a.allocated, tc.expectAllocated) {
+				t.Errorf("expect allocated %v, but got %v", tc.expectAllocated, a.allocated)
+			}
+			if !reflect.DeepEqual(a.exhausted, tc.expectExhausted) {
+				t.Errorf("expect exhausted %v, but got %v", tc.expectExhausted, a.exhausted)
+			}
+
+			var allocated int64
+			for _, p := range a.pools {
+				status := a.poolStatus(p)
+				allocated += status.Allocated
+				if exhausted := conditionStatus(status, appsv1alpha1.EdgeIPPoolExhausted) == corev1.ConditionTrue; exhausted != (len(tc.expectExhausted) != 0) {
+					t.Errorf("expect exhausted condition %v of pool %s, but got %v", len(tc.expectExhausted) != 0, p.pool.Name, exhausted)
+				}
+				if conflicted := conditionStatus(status, appsv1alpha1.EdgeIPPoolConflicted) == corev1.ConditionTrue; conflicted != tc.expectConflicted {
+					t.Errorf("expect conflicted condition %v of pool %s, but got %v", tc.expectConflicted, p.pool.Name, conflicted)
+				}
+			}
+			if allocated != int64(len(tc.expectAllocated)) {
+				t.Errorf("expect %d addresses are allocated in status, but got %d", len(tc.expectAllocated), allocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancerset
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "load-balancer-set-workers", concurrentReconciles, "Max concurrent workers for load-balancer-set-controller.")
+}
+
+const (
+	// KeepalivedConfigKey is the key of keepalived configuration in the keepalived ConfigMap of NodePool.
+	KeepalivedConfigKey = "keepalived.conf"
+
+	keepalivedConfigMapPrefix = "keepalived-"
+	keepalivedNamespace       = "kube-system"
+	defaultVRRPInterface      = "eth0"
+	maxVirtualRouterID        = 255
+)
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = appsv1beta1.SchemeGroupVersion.WithKind("NodePool")
+)
+
+func Format(format string, args ...interface{}) string {
+	s := fmt.Sprintf(format, args...)
+	return fmt.Sprintf("%s: %s", names.LoadBalancerSetController, s)
+}
+
+// KeepalivedConfigMapName returns the name of keepalived ConfigMap of the NodePool.
+func KeepalivedConfigMapName(poolName string) string {
+	return keepalivedConfigMapPrefix + poolName
+}
+
+// ReconcileLoadBalancerSet provides on-site virtual ips for LoadBalancer Services where no cloud load balancer
+// exists. The virtual ips of each NodePool are rendered into the keepalived configuration of the NodePool, which
+// is mounted by the keepalived daemons on the nodes of NodePool, so the virtual ip floats among the nodes by VRRP
+// and fails over automatically. And the virtual ips are recorded into the status of Service, so kube-proxy on the
+// node holding the virtual ip forwards the traffic to the endpoints of Service.
+type ReconcileLoadBalancerSet struct {
+	client.Client
+	recorder record.EventRecorder
+}
+
+var _ reconcile.Reconciler = &ReconcileLoadBalancerSet{}
+
+// Add creates a new LoadBalancerSet Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
+	klog.Infof(Format("load-balancer-set-controller add controller %s", controllerKind.String()))
+	r := &ReconcileLoadBalancerSet{
+		Client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(names.LoadBalancerSetController),
+	}
+
+	c, err := controller.New(names.LoadBalancerSetController, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &appsv1beta1.NodePool{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return evt.ObjectOld.GetAnnotations()[apps.AnnotationVRRPInterface] != evt.ObjectNew.GetAnnotations()[apps.AnnotationVRRPInterface]
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			// the keepalived ConfigMap is owned by NodePool, so it's deleted by garbage collector.
+			return false
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// both the NodePools of old and new Service are enqueued when Service is updated,
+	// so the virtual ips are removed from the NodePools which are not specified any more.
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		svc, ok := obj.(*corev1.Service)
+		if !ok {
+			return nil
+		}
+		vips, dynamicPools, _ := parseVips(svc)
+		requests := make([]reconcile.Request, 0, len(vips)+len(dynamicPools))
+		for pool := range vips {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: pool}})
+		}
+		for _, pool := range dynamicPools {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: pool}})
+		}
+		return requests
+	}))
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &appsv1alpha1.EdgeIPPool{}}, enqueueNodePoolsForEdgeIPPool)
+}
+
+// enqueueNodePoolsForEdgeIPPool enqueues the NodePools of both old and new EdgeIPPool when EdgeIPPool is updated,
+// so the allocations are released from the NodePool which the EdgeIPPool is moved out of.
+var enqueueNodePoolsForEdgeIPPool = handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+	pool, ok := obj.(*appsv1alpha1.EdgeIPPool)
+	if !ok || len(pool.Spec.NodePool) == 0 {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pool.Spec.NodePool}}}
+})
+
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=edgeippools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=edgeippools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// Reconcile allocates the virtual ips from the EdgeIPPools of NodePool for Services, renders the keepalived
+// configuration of NodePool with the virtual ips of Services in the NodePool, and records the virtual ips
+// into the status of these Services.
+func (r *ReconcileLoadBalancerSet) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var nodePool appsv1beta1.NodePool
+	if err := r.Get(ctx, req.NamespacedName, &nodePool); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if nodePool.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	var svcList corev1.ServiceList
+	if err := r.List(ctx, &svcList); err != nil {
+		return reconcile.Result{}, err
+	}
+	var poolList appsv1beta1.NodePoolList
+	if err := r.List(ctx, &poolList); err != nil {
+		return reconcile.Result{}, err
+	}
+	pools := make(map[string]bool, len(poolList.Items))
+	for i := range poolList.Items {
+		pools[poolList.Items[i].Name] = true
+	}
+	var ipPoolList appsv1alpha1.EdgeIPPoolList
+	if err := r.List(ctx, &ipPoolList); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// collect the static virtual ips and the requests of allocation in the NodePool
+	services := make([]*corev1.Service, 0)
+	staticVips := make(map[string]map[string]string)
+	statics := make(map[string]string)
+	requests := make([]string, 0)
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || svc.DeletionTimestamp != nil {
+			continue
+		}
+		vips, dynamicPools, err := parseVips(svc)
+		if err != nil {
+			r.recorder.Eventf(svc, corev1.EventTypeWarning, "InvalidVirtualIP", "%v", err)
+		}
+		key := serviceKey(svc)
+		staticVips[key] = vips
+		if vip, ok := vips[nodePool.Name]; ok {
+			statics[vip] = key
+			services = append(services, svc)
+		} else if contains(dynamicPools, nodePool.Name) {
+			requests = append(requests, key)
+			services = append(services, svc)
+		}
+	}
+
+	allocator := newIPAllocator(nodePool.Name, ipPoolList.Items, statics, requests)
+	for _, p := range allocator.pools {
+		if err := r.updateIPPoolStatus(ctx, p.pool, allocator.poolStatus(p)); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// the virtual ips allocated in all NodePools, the allocations of other NodePools are recorded in the status
+	// of their EdgeIPPools, and the allocations of the NodePool are the latest ones of allocator.
+	dynamicVips := make(map[string]map[string]string)
+	for i := range ipPoolList.Items {
+		ipPool := &ipPoolList.Items[i]
+		if ipPool.Spec.NodePool == nodePool.Name {
+			continue
+		}
+		for _, allocation := range ipPool.Status.Allocations {
+			if dynamicVips[allocation.Service] == nil {
+				dynamicVips[allocation.Service] = make(map[string]string)
+			}
+			dynamicVips[allocation.Service][ipPool.Spec.NodePool] = allocation.IP
+		}
+	}
+	for key, ip := range allocator.allocated {
+		if dynamicVips[key] == nil {
+			dynamicVips[key] = make(map[string]string)
+		}
+		dynamicVips[key][nodePool.Name] = ip
+	}
+
+	instances := make(map[string][]string)
+	for _, svc := range services {
+		key := serviceKey(svc)
+		vip, ok := staticVips[key][nodePool.Name]
+		if !ok {
+			vip, ok = allocator.allocated[key]
+		}
+		if !ok {
+			r.recorder.Eventf(svc, corev1.EventTypeWarning, "EdgeIPPoolExhausted", "no free address in the EdgeIPPools of NodePool %s", nodePool.Name)
+		} else {
+			instances[vip] = append(instances[vip], key)
+		}
+
+		ips := make([]string, 0)
+		for _, vips := range []map[string]string{staticVips[key], dynamicVips[key]} {
+			for pool, ip := range vips {
+				if pools[pool] {
+					ips = append(ips, ip)
+				}
+			}
+		}
+		if err := r.updateServiceStatus(ctx, svc, ips); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	iface := nodePool.Annotations[apps.AnnotationVRRPInterface]
+	if len(iface) == 0 {
+		iface = defaultVRRPInterface
+	}
+	return reconcile.Result{}, r.syncKeepalivedConfig(ctx, &nodePool, renderKeepalivedConfig(nodePool.Name, iface, instances))
+}
+
+// updateServiceStatus records the virtual ips into the load balancer status of Service.
+func (r *ReconcileLoadBalancerSet) updateServiceStatus(ctx context.Context, svc *corev1.Service, ips []string) error {
+	sort.Strings(ips)
+	ingress := make([]corev1.LoadBalancerIngress, 0, len(ips))
+	for i := range ips {
+		if i > 0 && ips[i] == ips[i-1] {
+			continue
+		}
+		ingress = append(ingress, corev1.LoadBalancerIngress{IP: ips[i]})
+	}
+	if reflect.DeepEqual(svc.Status.LoadBalancer.Ingress, ingress) {
+		return nil
+	}
+
+	svc = svc.DeepCopy()
+	svc.Status.LoadBalancer.Ingress = ingress
+	if err := r.Status().Update(ctx, svc); err != nil {
+		klog.Errorf(Format("could not update status of service %s/%s, %v", svc.Namespace, svc.Name, err))
+		return err
+	}
+	klog.Infof(Format("virtual ips %v are assigned to service %s/%s", ips, svc.Namespace, svc.Name))
+	return nil
+}
+
+// updateIPPoolStatus updates the status of EdgeIPPool if it's changed.
+func (r *ReconcileLoadBalancerSet) updateIPPoolStatus(ctx context.Context, ipPool *appsv1alpha1.EdgeIPPool, status appsv1alpha1.EdgeIPPoolStatus) error {
+	if reflect.DeepEqual(ipPool.Status, status) {
+		return nil
+	}
+	ipPool = ipPool.DeepCopy()
+	ipPool.Status = status
+	if err := r.Status().Update(ctx, ipPool); err != nil {
+		klog.Errorf(Format("could not update status of edgeippool %s, %v", ipPool.Name, err))
+		return err
+	}
+	return nil
+}
+
+// syncKeepalivedConfig creates or updates the keepalived ConfigMap of NodePool.
+func (r *ReconcileLoadBalancerSet) syncKeepalivedConfig(ctx context.Context, nodePool *appsv1beta1.NodePool, config string) error {
+	var cm corev1.ConfigMap
+	err := r.Get(ctx, types.NamespacedName{Namespace: keepalivedNamespace, Name: KeepalivedConfigMapName(nodePool.Name)}, &cm)
+	if apierrors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      KeepalivedConfigMapName(nodePool.Name),
+				Namespace: keepalivedNamespace,
+				Labels: map[string]string{
+					apps.KeepalivedConfigLabel: nodePool.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(nodePool, controllerKind)},
+			},
+			Data: map[string]string{
+				KeepalivedConfigKey: config,
+			},
+		}
+		return r.Create(ctx, &cm)
+	} else if err != nil {
+		return err
+	}
+
+	if cm.Data[KeepalivedConfigKey] == config {
+		return nil
+	}
+	cm.Data = map[string]string{
+		KeepalivedConfigKey: config,
+	}
+	klog.Infof(Format("keepalived configuration of nodepool %s is updated", nodePool.Name))
+	return r.Update(ctx, &cm)
+}
+
+// parseVips parses the virtual ips of Service, the key of returned map is the name of NodePool, and the
+// NodePools without address are returned as the NodePools which allocate an address from their EdgeIPPools.
+// the invalid items are skipped and reported by the returned error.
+func parseVips(svc *corev1.Service) (map[string]string, []string, error) {
+	vips := make(map[string]string)
+	dynamicPools := make([]string, 0)
+	value, ok := svc.Annotations[apps.AnnotationVRRPVips]
+	if !ok {
+		return vips, dynamicPools, nil
+	}
+
+	invalid := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) == 1 {
+			dynamicPools = append(dynamicPools, parts[0])
+			continue
+		}
+		if len(parts[0]) == 0 || net.ParseIP(parts[1]) == nil {
+			invalid = append(invalid, item)
+			continue
+		}
+		vips[parts[0]] = net.ParseIP(parts[1]).String()
+	}
+	if len(invalid) != 0 {
+		return vips, dynamicPools, fmt.Errorf("invalid virtual ips %v in annotation %s of service %s/%s", invalid, apps.AnnotationVRRPVips, svc.Namespace, svc.Name)
+	}
+	return vips, dynamicPools, nil
+}
+
+func serviceKey(svc *corev1.Service) string {
+	return fmt.Sprintf("%s/%s", svc.Namespace, svc.Name)
+}
+
+func contains(items []string, item string) bool {
+	for i := range items {
+		if items[i] == item {
+			return true
+		}
+	}
+	return false
+}
+
+// assignVirtualRouterIDs assigns a VRRP virtual router id for each virtual ip. The id is derived from the
+// hash of virtual ip, so it's stable when the other virtual ips are added or removed, and the next free id
+// is used if the id has been assigned to another virtual ip.
+func assignVirtualRouterIDs(vips []string) map[string]int {
+	ids := make(map[string]int, len(vips))
+	used := make(map[int]bool, len(vips))
+	for _, vip := range vips {
+		h := fnv.New32a()
+		h.Write([]byte(vip))
+		id := int(h.Sum32()%maxVirtualRouterID) + 1
+		for i := 0; i < maxVirtualRouterID && used[id]; i++ {
+			id = id%maxVirtualRouterID + 1
+		}
+		if used[id] {
+			klog.Warningf(Format("no free virtual router id for virtual ip %s", vip))
+			continue
+		}
+		used[id] = true
+		ids[vip] = id
+	}
+	return ids
+}
+
+// renderKeepalivedConfig renders the keepalived configuration with a VRRP instance for each virtual ip.
+// all nodes start as BACKUP with the same priority and nopreempt, so the virtual ip doesn't move back
+// and forth when a failed node recovers.
+func renderKeepalivedConfig(poolName, iface string, instances map[string][]string) string {
+	vips := make([]string, 0, len(instances))
+	for vip := range instances {
+		vips = append(vips, vip)
+	}
+	sort.Strings(vips)
+	ids := assignVirtualRouterIDs(vips)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "global_defs {\n    router_id openyurt-%s\n    vrrp_skip_check_adv_addr\n}\n", poolName)
+	for _, vip := range vips {
+		id, ok := ids[vip]
+		if !ok {
+			continue
+		}
+		services := instances[vip]
+		sort.Strings(services)
+		fmt.Fprintf(&b, "\n# services: %s\n", strings.Join(services, ", "))
+		fmt.Fprintf(&b, "vrrp_instance VI_%d {\n", id)
+		fmt.Fprintf(&b, "    state BACKUP\n    interface %s\n    virtual_router_id %d\n    priority 100\n    advert_int 1\n    nopreempt\n", iface, id)
+		fmt.Fprintf(&b, "    virtual_ipaddress {\n        %s\n    }\n}\n", vip)
+	}
+	return b.String()
+}
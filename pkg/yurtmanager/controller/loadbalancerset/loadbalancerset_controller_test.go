@@ -0,0 +1,244 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancerset
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func newService(name string, svcType corev1.ServiceType, vips string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{apps.AnnotationVRRPVips: vips},
+		},
+		Spec: corev1.ServiceSpec{Type: svcType},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	hangzhou := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "hangzhou",
+			Annotations: map[string]string{apps.AnnotationVRRPInterface: "ens33"},
+		},
+	}
+	shanghai := &appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "shanghai"}}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		hangzhou,
+		shanghai,
+		newService("web", corev1.ServiceTypeLoadBalancer, "hangzhou=192.168.10.100,shanghai=192.168.20.100,beijing=192.168.30.100"),
+		newService("api", corev1.ServiceTypeLoadBalancer, "hangzhou=192.168.10.100, shanghai=invalid"),
+		newService("internal", corev1.ServiceTypeClusterIP, "hangzhou=192.168.10.101"),
+	).Build()
+	r := &ReconcileLoadBalancerSet{
+		Client:   c,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "hangzhou"}}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	var cm corev1.ConfigMap
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: keepalivedNamespace, Name: KeepalivedConfigMapName("hangzhou")}, &cm); err != nil {
+		t.Fatalf("could not get keepalived configmap, %v", err)
+	}
+	if cm.Labels[apps.KeepalivedConfigLabel] != "hangzhou" || len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != "hangzhou" {
+		t.Errorf("keepalived configmap is not labeled or owned by nodepool, %#+v", cm.ObjectMeta)
+	}
+	config := cm.Data[KeepalivedConfigKey]
+	for _, expect := range []string{"# services: default/api, default/web", "interface ens33", "192.168.10.100", "nopreempt"} {
+		if !strings.Contains(config, expect) {
+			t.Errorf("expect %q in keepalived configuration, but got\n%s", expect, config)
+		}
+	}
+	if strings.Contains(config, "192.168.10.101") || strings.Count(config, "vrrp_instance") != 1 {
+		t.Errorf("expect only one vrrp instance in keepalived configuration, but got\n%s", config)
+	}
+
+	for name, expect := range map[string][]corev1.LoadBalancerIngress{
+		// the vip of pool beijing is ignored because the pool doesn't exist
+		"web":      {{IP: "192.168.10.100"}, {IP: "192.168.20.100"}},
+		"api":      {{IP: "192.168.10.100"}},
+		"internal": nil,
+	} {
+		var svc corev1.Service
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, &svc); err != nil {
+			t.Fatalf("could not get service %s, %v", name, err)
+		}
+		if len(expect) == 0 && len(svc.Status.LoadBalancer.Ingress) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(svc.Status.LoadBalancer.Ingress, expect) {
+			t.Errorf("expect ingress of service %s is %v, but got %v", name, expect, svc.Status.LoadBalancer.Ingress)
+		}
+	}
+
+	// the configuration is updated after the vip is removed from pool
+	var web corev1.Service
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "web"}, &web); err != nil {
+		t.Fatalf("could not get service, %v", err)
+	}
+	web.Annotations[apps.AnnotationVRRPVips] = "shanghai=192.168.20.100"
+	if err := c.Update(context.Background(), &web); err != nil {
+		t.Fatalf("could not update service, %v", err)
+	}
+	var api corev1.Service
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "api"}, &api); err != nil {
+		t.Fatalf("could not get service, %v", err)
+	}
+	if err := c.Delete(context.Background(), &api); err != nil {
+		t.Fatalf("could not delete service, %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "hangzhou"}}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: keepalivedNamespace, Name: KeepalivedConfigMapName("hangzhou")}, &cm); err != nil {
+		t.Fatalf("could not get keepalived configmap, %v", err)
+	}
+	if strings.Contains(cm.Data[KeepalivedConfigKey], "vrrp_instance") {
+		t.Errorf("expect no vrrp instance in keepalived configuration, but got\n%s", cm.Data[KeepalivedConfigKey])
+	}
+}
+
+func TestReconcileWithEdgeIPPool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	pool := newEdgeIPPool("hangzhou-pool", "hangzhou", []string{"192.168.10.100-192.168.10.101"})
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"}},
+		&pool,
+		newService("web", corev1.ServiceTypeLoadBalancer, "hangzhou"),
+		newService("api", corev1.ServiceTypeLoadBalancer, "hangzhou=192.168.10.100"),
+		newService("db", corev1.ServiceTypeLoadBalancer, "hangzhou"),
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileLoadBalancerSet{
+		Client:   c,
+		recorder: recorder,
+	}
+
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "hangzhou"}}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	// 192.168.10.100 is used by service api statically, so only one address can be allocated
+	// and the services are served in order of namespace/name
+	for name, expect := range map[string][]corev1.LoadBalancerIngress{
+		"db":  {{IP: "192.168.10.101"}},
+		"api": {{IP: "192.168.10.100"}},
+		"web": nil,
+	} {
+		var svc corev1.Service
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, &svc); err != nil {
+			t.Fatalf("could not get service %s, %v", name, err)
+		}
+		if len(expect) == 0 && len(svc.Status.LoadBalancer.Ingress) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(svc.Status.LoadBalancer.Ingress, expect) {
+			t.Errorf("expect ingress of service %s is %v, but got %v", name, expect, svc.Status.LoadBalancer.Ingress)
+		}
+	}
+
+	var got appsv1alpha1.EdgeIPPool
+	if err := c.Get(context.Background(), types.NamespacedName{Name: pool.Name}, &got); err != nil {
+		t.Fatalf("could not get edge ip pool, %v", err)
+	}
+	if got.Status.Capacity != 2 || got.Status.Allocated != 1 {
+		t.Errorf("expect capacity 2 and allocated 1, but got %d and %d", got.Status.Capacity, got.Status.Allocated)
+	}
+	if conditionStatus(got.Status, appsv1alpha1.EdgeIPPoolExhausted) != corev1.ConditionTrue {
+		t.Errorf("expect pool is exhausted, but got %v", got.Status.Conditions)
+	}
+	if conditionStatus(got.Status, appsv1alpha1.EdgeIPPoolConflicted) != corev1.ConditionTrue {
+		t.Errorf("expect pool is conflicted, but got %v", got.Status.Conditions)
+	}
+	if len(recorder.Events) == 0 {
+		t.Errorf("expect an event for the exhausted pool")
+	}
+}
+
+func TestEnqueueNodePoolsForEdgeIPPool(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+	oldPool := newEdgeIPPool("pool-a", "hangzhou", []string{"192.168.0.100-192.168.0.101"})
+	newPool := oldPool.DeepCopy()
+	newPool.Spec.NodePool = "shanghai"
+
+	enqueueNodePoolsForEdgeIPPool.Update(event.UpdateEvent{ObjectOld: &oldPool, ObjectNew: newPool}, queue)
+	got := make([]string, 0, queue.Len())
+	for queue.Len() > 0 {
+		item, _ := queue.Get()
+		got = append(got, item.(reconcile.Request).Name)
+		queue.Done(item)
+	}
+	sort.Strings(got)
+	if expect := []string{"hangzhou", "shanghai"}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect nodepools %v are enqueued, but got %v", expect, got)
+	}
+}
+
+func TestAssignVirtualRouterIDs(t *testing.T) {
+	vips := []string{"192.168.10.100", "192.168.10.101", "192.168.10.102"}
+	ids := assignVirtualRouterIDs(vips)
+	used := make(map[int]bool)
+	for _, vip := range vips {
+		id, ok := ids[vip]
+		if !ok || id < 1 || id > maxVirtualRouterID || used[id] {
+			t.Errorf("invalid virtual router id %d for %s", id, vip)
+		}
+		used[id] = true
+	}
+
+	// the id is stable when other virtual ips are removed
+	if id := assignVirtualRouterIDs(vips[2:])[vips[2]]; id != ids[vips[2]] {
+		t.Errorf("expect virtual router id of %s is %d, but got %d", vips[2], ids[vips[2]], id)
+	}
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemigration
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	migrationutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/migration"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "node-migration-workers", concurrentReconciles, "Max concurrent workers for node-migration-controller.")
+}
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = corev1.SchemeGroupVersion.WithKind("Node")
+
+	// requeueInterval is the interval for checking the progress of migration.
+	requeueInterval = 5 * time.Second
+	// gatewayTimeout is the max duration of waiting for raven gateway reconfiguration.
+	gatewayTimeout = 5 * time.Minute
+	// drainTimeout is the max duration of draining pool-bound pods, the migration is aborted
+	// if the pods can not be evicted in time, e.g. they are protected by PodDisruptionBudget.
+	drainTimeout = 10 * time.Minute
+)
+
+func Format(format string, args ...interface{}) string {
+	s := fmt.Sprintf(format, args...)
+	return fmt.Sprintf("%s: %s", names.NodeMigrationController, s)
+}
+
+// ReconcileNodeMigration moves nodes into another NodePool safely. Nodes are cordoned and the pool-bound
+// pods(like pods of YurtAppSet) are evicted before the nodepool label is changed, and nodes are uncordoned
+// after the raven gateway of the target NodePool takes over the node.
+type ReconcileNodeMigration struct {
+	client.Client
+	kubeClient kubernetes.Interface
+	recorder   record.EventRecorder
+}
+
+var _ reconcile.Reconciler = &ReconcileNodeMigration{}
+
+// Add creates a new NodeMigration Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
+	klog.Infof(Format("node-migration-controller add controller %s", controllerKind.String()))
+	r := &ReconcileNodeMigration{
+		recorder: mgr.GetEventRecorderFor(names.NodeMigrationController),
+	}
+
+	c, err := controller.New(names.NodeMigrationController, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	inMigration := func(obj client.Object) bool {
+		annotations := obj.GetAnnotations()
+		_, migrateTo := annotations[apps.AnnotationMigrateTo]
+		_, migrating := annotations[apps.AnnotationMigrationStatus]
+		return migrateTo || migrating
+	}
+	return c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return inMigration(evt.Object)
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return inMigration(evt.ObjectNew)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+	})
+}
+
+func (r *ReconcileNodeMigration) InjectClient(c client.Client) error {
+	r.Client = c
+	return nil
+}
+
+func (r *ReconcileNodeMigration) InjectConfig(cfg *rest.Config) error {
+	c, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Errorf(Format("could not create kube client, %v", err))
+		return err
+	}
+	r.kubeClient = c
+	return nil
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=raven.openyurt.io,resources=gateways,verbs=get;list;watch
+
+// Reconcile drives the migration of node step by step, and the progress is recorded
+// in the migration status annotation, so the migration can be resumed after restart.
+func (r *ReconcileNodeMigration) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var node corev1.Node
+	if err := r.Get(ctx, req.NamespacedName, &node); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status, err := migrationutil.GetStatus(&node)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	targetPool := node.Annotations[apps.AnnotationMigrateTo]
+
+	if status == nil {
+		if len(targetPool) == 0 {
+			return reconcile.Result{}, nil
+		}
+		return r.startMigration(ctx, &node, targetPool)
+	}
+
+	// the migration is canceled before the node is moved into target pool
+	if targetPool != status.TargetPool && status.Phase != migrationutil.PhaseWaitingGateway {
+		klog.Infof(Format("migration of node %s from %s to %s is canceled", node.Name, status.SourcePool, status.TargetPool))
+		r.recorder.Eventf(&node, corev1.EventTypeNormal, "MigrationCanceled", "migration into NodePool %s is canceled", status.TargetPool)
+		return reconcile.Result{}, r.finishMigration(ctx, &node, status)
+	}
+
+	switch status.Phase {
+	case migrationutil.PhaseDraining:
+		return r.drainNode(ctx, &node, status)
+	case migrationutil.PhaseRelabeling:
+		return r.relabelNode(ctx, &node, status)
+	case migrationutil.PhaseWaitingGateway:
+		return r.waitGateway(ctx, &node, status)
+	default:
+		klog.Warningf(Format("unknown migration phase %s of node %s", status.Phase, node.Name))
+		return reconcile.Result{}, r.finishMigration(ctx, &node, status)
+	}
+}
+
+// startMigration cordons the node and starts draining pool-bound pods on the node.
+func (r *ReconcileNodeMigration) startMigration(ctx context.Context, node *corev1.Node, targetPool string) (reconcile.Result, error) {
+	sourcePool := node.Labels[apps.NodePoolLabel]
+	if sourcePool == targetPool {
+		delete(node.Annotations, apps.AnnotationMigrateTo)
+		return reconcile.Result{}, r.Update(ctx, node)
+	}
+
+	var np appsv1beta1.NodePool
+	if err := r.Get(ctx, types.NamespacedName{Name: targetPool}, &np); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.recorder.Eventf(node, corev1.EventTypeWarning, "MigrationFailed", "target NodePool %s is not found", targetPool)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	status := &migrationutil.Status{
+		Phase:          migrationutil.PhaseDraining,
+		SourcePool:     sourcePool,
+		TargetPool:     targetPool,
+		Cordoned:       !node.Spec.Unschedulable,
+		PhaseStartTime: metav1.Now(),
+	}
+	node.Spec.Unschedulable = true
+	if err := r.updateStatus(ctx, node, status); err != nil {
+		return reconcile.Result{}, err
+	}
+	klog.Infof(Format("node %s is cordoned for migrating from NodePool %s to %s", node.Name, sourcePool, targetPool))
+	r.recorder.Eventf(node, corev1.EventTypeNormal, "MigrationStarted", "start to migrate from NodePool %s to %s", sourcePool, targetPool)
+	return reconcile.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// drainNode evicts the pool-bound pods on the node, pods that are not bound to the pool
+// are kept on the node because they don't depend on the pool. the migration is aborted
+// and the node is uncordoned if the pods are not drained in drainTimeout.
+func (r *ReconcileNodeMigration) drainNode(ctx context.Context, node *corev1.Node, status *migrationutil.Status) (reconcile.Result, error) {
+	pods, err := r.poolBoundPods(ctx, node.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if len(pods) == 0 {
+		status.Phase = migrationutil.PhaseRelabeling
+		status.PhaseStartTime = metav1.Now()
+		return reconcile.Result{}, r.updateStatus(ctx, node, status)
+	}
+
+	if time.Since(status.PhaseStartTime.Time) >= drainTimeout {
+		klog.Warningf(Format("pool-bound pods on node %s are not drained in %v, migration into NodePool %s is aborted", node.Name, drainTimeout, status.TargetPool))
+		r.recorder.Eventf(node, corev1.EventTypeWarning, "MigrationFailed", "pool-bound pods are not drained in %v, migration into NodePool %s is aborted", drainTimeout, status.TargetPool)
+		return reconcile.Result{}, r.finishMigration(ctx, node, status)
+	}
+
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil {
+			continue
+		}
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pods[i].Name,
+				Namespace: pods[i].Namespace,
+			},
+		}
+		if err := r.kubeClient.PolicyV1().Evictions(pods[i].Namespace).Evict(ctx, eviction); err != nil && !apierrors.IsNotFound(err) {
+			// the eviction may be rejected by PodDisruptionBudget, so retry it later
+			klog.Warningf(Format("could not evict pod %s/%s on node %s, %v", pods[i].Namespace, pods[i].Name, node.Name, err))
+			continue
+		}
+		klog.Infof(Format("pod %s/%s is evicted from node %s for migration", pods[i].Namespace, pods[i].Name, node.Name))
+	}
+	return reconcile.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// relabelNode moves the node into target pool, the labels of previous pool will be
+// removed by nodepool controller.
+func (r *ReconcileNodeMigration) relabelNode(ctx context.Context, node *corev1.Node, status *migrationutil.Status) (reconcile.Result, error) {
+	if node.Labels == nil {
+		node.Labels = make(map[string]string)
+	}
+	node.Labels[apps.NodePoolLabel] = status.TargetPool
+	// type and hostnetwork labels will be added by node webhook according to the target pool
+	delete(node.Labels, apps.NodePoolTypeLabel)
+	delete(node.Labels, apps.NodePoolHostNetworkLabel)
+
+	status.Phase = migrationutil.PhaseWaitingGateway
+	status.PhaseStartTime = metav1.Now()
+	if err := r.updateStatus(ctx, node, status); err != nil {
+		return reconcile.Result{}, err
+	}
+	klog.Infof(Format("node %s is moved into NodePool %s", node.Name, status.TargetPool))
+	return reconcile.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// waitGateway waits for the raven gateway of target pool to take over the node,
+// the node will be uncordoned even if the gateway is not ready after timeout.
+func (r *ReconcileNodeMigration) waitGateway(ctx context.Context, node *corev1.Node, status *migrationutil.Status) (reconcile.Result, error) {
+	ready, err := r.isGatewayReady(ctx, node, status.TargetPool)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !ready {
+		if time.Since(status.PhaseStartTime.Time) < gatewayTimeout {
+			return reconcile.Result{RequeueAfter: requeueInterval}, nil
+		}
+		klog.Warningf(Format("raven gateway doesn't take over node %s in %v", node.Name, gatewayTimeout))
+		r.recorder.Eventf(node, corev1.EventTypeWarning, "GatewayNotReady", "raven gateway doesn't take over the node in %v", gatewayTimeout)
+	}
+
+	if err := r.finishMigration(ctx, node, status); err != nil {
+		return reconcile.Result{}, err
+	}
+	klog.Infof(Format("node %s is migrated from NodePool %s to %s", node.Name, status.SourcePool, status.TargetPool))
+	r.recorder.Eventf(node, corev1.EventTypeNormal, "MigrationCompleted", "node is migrated from NodePool %s to %s", status.SourcePool, status.TargetPool)
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileNodeMigration) isGatewayReady(ctx context.Context, node *corev1.Node, poolName string) (bool, error) {
+	var np appsv1beta1.NodePool
+	if err := r.Get(ctx, types.NamespacedName{Name: poolName}, &np); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if len(np.Spec.Gateway) == 0 {
+		return true, nil
+	}
+	if node.Labels[raven.LabelCurrentGateway] != np.Spec.Gateway {
+		return false, nil
+	}
+
+	var gw ravenv1beta1.Gateway
+	if err := r.Get(ctx, types.NamespacedName{Name: np.Spec.Gateway}, &gw); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	for _, n := range gw.Status.Nodes {
+		if n.NodeName == node.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// finishMigration uncordons the node if it's cordoned by migration, and removes the migration annotations.
+func (r *ReconcileNodeMigration) finishMigration(ctx context.Context, node *corev1.Node, status *migrationutil.Status) error {
+	if status.Cordoned {
+		node.Spec.Unschedulable = false
+	}
+	delete(node.Annotations, apps.AnnotationMigrateTo)
+	delete(node.Annotations, apps.AnnotationMigrationStatus)
+	return r.Update(ctx, node)
+}
+
+func (r *ReconcileNodeMigration) updateStatus(ctx context.Context, node *corev1.Node, status *migrationutil.Status) error {
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[apps.AnnotationMigrationStatus] = string(raw)
+	return r.Update(ctx, node)
+}
+
+// poolBoundPods returns the pods on the node that are bound to the nodepool,
+// like pods of YurtAppSet and YurtAppDaemon.
+func (r *ReconcileNodeMigration) poolBoundPods(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	req, err := labels.NewRequirement(apps.PoolNameLabelKey, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*req)}); err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for i := range podList.Items {
+		if podList.Items[i].Spec.NodeName == nodeName {
+			pods = append(pods, podList.Items[i])
+		}
+	}
+	return pods, nil
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemigration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	migrationutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/migration"
+)
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	testcases := map[string]struct {
+		unschedulable bool
+		migrateTo     string
+		expectPhases  []migrationutil.Phase
+		expectPool    string
+		expectCordon  bool
+	}{
+		"migrate node into another pool": {
+			migrateTo:    "shanghai",
+			expectPhases: []migrationutil.Phase{migrationutil.PhaseDraining, migrationutil.PhaseRelabeling, migrationutil.PhaseWaitingGateway, ""},
+			expectPool:   "shanghai",
+		},
+		"migrate unschedulable node into another pool": {
+			unschedulable: true,
+			migrateTo:     "shanghai",
+			expectPhases:  []migrationutil.Phase{migrationutil.PhaseDraining, migrationutil.PhaseRelabeling, migrationutil.PhaseWaitingGateway, ""},
+			expectPool:    "shanghai",
+			expectCordon:  true,
+		},
+		"target pool is not found": {
+			migrateTo:    "beijing",
+			expectPhases: []migrationutil.Phase{""},
+			expectPool:   "hangzhou",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node1",
+					Labels: map[string]string{
+						apps.NodePoolLabel: "hangzhou",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationMigrateTo: tc.migrateTo,
+					},
+				},
+				Spec: corev1.NodeSpec{
+					Unschedulable: tc.unschedulable,
+				},
+			}
+			pool := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "shanghai"},
+				Spec:       appsv1beta1.NodePoolSpec{Type: appsv1beta1.Edge},
+			}
+			r := &ReconcileNodeMigration{
+				Client:   fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(node, pool).Build(),
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "node1"}}
+			var current corev1.Node
+			for _, phase := range tc.expectPhases {
+				if _, err := r.Reconcile(context.TODO(), req); err != nil {
+					t.Fatalf("could not reconcile node, %v", err)
+				}
+				if err := r.Get(context.TODO(), req.NamespacedName, &current); err != nil {
+					t.Fatalf("could not get node, %v", err)
+				}
+				status, err := migrationutil.GetStatus(&current)
+				if err != nil {
+					t.Fatalf("could not get migration status, %v", err)
+				}
+
+				var currentPhase migrationutil.Phase
+				if status != nil {
+					currentPhase = status.Phase
+					if !current.Spec.Unschedulable {
+						t.Errorf("expect node is cordoned in phase %s", currentPhase)
+					}
+				}
+				if currentPhase != phase {
+					t.Fatalf("expect migration phase %q, but got %q", phase, currentPhase)
+				}
+			}
+
+			if current.Labels[apps.NodePoolLabel] != tc.expectPool {
+				t.Errorf("expect node in pool %s, but got %s", tc.expectPool, current.Labels[apps.NodePoolLabel])
+			}
+			if current.Spec.Unschedulable != tc.expectCordon {
+				t.Errorf("expect node unschedulable %v, but got %v", tc.expectCordon, current.Spec.Unschedulable)
+			}
+			if len(tc.expectPhases) > 1 {
+				if _, ok := current.Annotations[apps.AnnotationMigrateTo]; ok {
+					t.Errorf("expect migrate-to annotation is removed after migration")
+				}
+			}
+		})
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{apps.NodePoolLabel: "hangzhou"},
+			Annotations: map[string]string{
+				apps.AnnotationMigrateTo: "shanghai",
+			},
+		},
+	}
+	if err := updateStatusAnnotation(node, &migrationutil.Status{
+		Phase:          migrationutil.PhaseDraining,
+		SourcePool:     "hangzhou",
+		TargetPool:     "shanghai",
+		Cordoned:       true,
+		PhaseStartTime: metav1.NewTime(time.Now().Add(-drainTimeout)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	node.Spec.Unschedulable = true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "default",
+			Labels:    map[string]string{apps.PoolNameLabelKey: "hangzhou"},
+		},
+		Spec: corev1.PodSpec{NodeName: "node1"},
+	}
+	r := &ReconcileNodeMigration{
+		Client:   fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(node, pod).Build(),
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "node1"}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("could not reconcile node, %v", err)
+	}
+	var current corev1.Node
+	if err := r.Get(context.TODO(), req.NamespacedName, &current); err != nil {
+		t.Fatalf("could not get node, %v", err)
+	}
+	if current.Labels[apps.NodePoolLabel] != "hangzhou" || current.Spec.Unschedulable {
+		t.Errorf("expect node is uncordoned in the source pool, but got pool %s, unschedulable %v", current.Labels[apps.NodePoolLabel], current.Spec.Unschedulable)
+	}
+	if _, ok := current.Annotations[apps.AnnotationMigrationStatus]; ok {
+		t.Errorf("expect migration is aborted")
+	}
+	if _, ok := current.Annotations[apps.AnnotationMigrateTo]; ok {
+		t.Errorf("expect migrate-to annotation is removed")
+	}
+}
+
+func updateStatusAnnotation(node *corev1.Node, status *migrationutil.Status) error {
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	node.Annotations[apps.AnnotationMigrationStatus] = string(raw)
+	return nil
+}
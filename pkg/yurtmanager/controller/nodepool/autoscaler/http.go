@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+const (
+	// ActionIncreaseSize is the action for adding nodes into nodepool.
+	ActionIncreaseSize = "IncreaseSize"
+	// ActionDeleteNodes is the action for removing nodes from nodepool.
+	ActionDeleteNodes = "DeleteNodes"
+
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// ScaleRequest is the request body that is posted to the external provisioner.
+type ScaleRequest struct {
+	Action   string   `json:"action"`
+	NodePool string   `json:"nodePool"`
+	Delta    int32    `json:"delta,omitempty"`
+	Nodes    []string `json:"nodes,omitempty"`
+}
+
+// httpProvider is the reference implementation of Provider, it posts scaling
+// requests to the external provisioner in json format, so the adapters for
+// different clouds can be implemented out of tree.
+type httpProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPProvider creates a Provider which posts scaling requests to the endpoint.
+func NewHTTPProvider(endpoint string) Provider {
+	return &httpProvider{
+		endpoint: endpoint,
+		client: &http.Client{
+			Timeout: defaultRequestTimeout,
+		},
+	}
+}
+
+func (hp *httpProvider) Name() string {
+	return "http"
+}
+
+func (hp *httpProvider) IncreaseSize(ctx context.Context, nodePool *appsv1beta1.NodePool, delta int32) error {
+	if delta <= 0 {
+		return fmt.Errorf("delta %d should be positive", delta)
+	}
+	return hp.send(ctx, &ScaleRequest{
+		Action:   ActionIncreaseSize,
+		NodePool: nodePool.Name,
+		Delta:    delta,
+	})
+}
+
+func (hp *httpProvider) DeleteNodes(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []string) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return hp.send(ctx, &ScaleRequest{
+		Action:   ActionDeleteNodes,
+		NodePool: nodePool.Name,
+		Nodes:    nodes,
+	})
+}
+
+func (hp *httpProvider) send(ctx context.Context, sr *ScaleRequest) error {
+	body, err := json.Marshal(sr)
+	if err != nil {
+		return fmt.Errorf("could not encode scale request, %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hp.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create scale request, %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hp.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send %s request for nodepool %s, %w", sr.Action, sr.NodePool, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s request for nodepool %s is rejected with status code %d, %s", sr.Action, sr.NodePool, resp.StatusCode, string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "hangzhou",
+		},
+	}
+
+	testcases := map[string]struct {
+		statusCode int
+		scale      func(p Provider) error
+		wantReq    *ScaleRequest
+		isErr      bool
+	}{
+		"increase size": {
+			statusCode: http.StatusOK,
+			scale: func(p Provider) error {
+				return p.IncreaseSize(context.TODO(), pool, 2)
+			},
+			wantReq: &ScaleRequest{Action: ActionIncreaseSize, NodePool: "hangzhou", Delta: 2},
+		},
+		"delete nodes": {
+			statusCode: http.StatusAccepted,
+			scale: func(p Provider) error {
+				return p.DeleteNodes(context.TODO(), pool, []string{"node1", "node2"})
+			},
+			wantReq: &ScaleRequest{Action: ActionDeleteNodes, NodePool: "hangzhou", Nodes: []string{"node1", "node2"}},
+		},
+		"request is rejected": {
+			statusCode: http.StatusInternalServerError,
+			scale: func(p Provider) error {
+				return p.IncreaseSize(context.TODO(), pool, 1)
+			},
+			wantReq: &ScaleRequest{Action: ActionIncreaseSize, NodePool: "hangzhou", Delta: 1},
+			isErr:   true,
+		},
+		"invalid delta": {
+			scale: func(p Provider) error {
+				return p.IncreaseSize(context.TODO(), pool, 0)
+			},
+			isErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var gotReq *ScaleRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotReq = &ScaleRequest{}
+				if err := json.NewDecoder(r.Body).Decode(gotReq); err != nil {
+					t.Errorf("could not decode scale request, %v", err)
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			err := tc.scale(NewHTTPProvider(server.URL))
+			if tc.isErr != (err != nil) {
+				t.Errorf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if !reflect.DeepEqual(tc.wantReq, gotReq) {
+				t.Errorf("expect request %#v, but got %#v", tc.wantReq, gotReq)
+			}
+		})
+	}
+}
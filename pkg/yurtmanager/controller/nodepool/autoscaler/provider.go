@@ -0,0 +1,36 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// Provider is the hook which is used by nodepool controller to add or remove nodes of
+// a nodepool through an external provisioner, like cloud auto scaling groups or
+// apis of GPU clouds.
+type Provider interface {
+	// Name returns the name of the provider.
+	Name() string
+	// IncreaseSize requests the provider to add delta nodes into the nodepool.
+	IncreaseSize(ctx context.Context, nodePool *appsv1beta1.NodePool, delta int32) error
+	// DeleteNodes requests the provider to remove the specified nodes from the nodepool,
+	// the nodes have been cordoned and drained when they are requested to be removed.
+	DeleteNodes(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []string) error
+}
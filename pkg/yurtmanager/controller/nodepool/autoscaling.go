@@ -0,0 +1,384 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+const (
+	// pendingPodPoolIndex indexes the pods which are not scheduled by the nodepool in their nodeSelector.
+	pendingPodPoolIndex = "spec.nodeSelector.pendingPool"
+	// podNodeIndex indexes the pods by their nodes, so the pods on the draining nodes can be listed.
+	podNodeIndex = "spec.nodeName.autoscaling"
+	// scaleUpTimeout is how long the nodes requested from provider are expected to join the nodepool,
+	// the nodes which have not joined after the timeout are not counted as in-flight any more.
+	scaleUpTimeout = 10 * time.Minute
+	// drainTimeout is the max duration of draining nodes before they are removed, the scale down is
+	// aborted if the pods can not be evicted in time, e.g. they are protected by PodDisruptionBudget.
+	drainTimeout = 10 * time.Minute
+	// drainInterval is the interval for checking the progress of draining nodes.
+	drainInterval = 5 * time.Second
+)
+
+// autoscaleNodePool adds or removes nodes of the nodepool through the autoscaling provider
+// based on spec.desiredSize and the pending pods which can not be scheduled into the nodepool.
+// pending pods trigger adding nodes only when spec.maxSize is specified, and the nodes are
+// cordoned and drained before they are removed. the state of scaling is recorded in the status
+// of nodepool, so the interval for checking the nodepool again and whether the status is updated
+// are returned.
+func (r *ReconcileNodePool) autoscaleNodePool(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node) (time.Duration, bool, error) {
+	if r.provider == nil || nodePool.Spec.DesiredSize == nil {
+		return 0, false, nil
+	}
+	if nodePool.Status.Autoscaling == nil {
+		nodePool.Status.Autoscaling = &appsv1beta1.NodePoolAutoscalingStatus{}
+	}
+	status := nodePool.Status.Autoscaling
+
+	if len(status.DrainingNodes) != 0 {
+		return r.scaleDown(ctx, nodePool, nodes)
+	}
+
+	cooldown := r.cfg.AutoscalingCooldown.Duration
+	if status.LastScaleTime != nil {
+		if elapsed := r.clock.Since(status.LastScaleTime.Time); elapsed < cooldown {
+			return cooldown - elapsed, false, nil
+		}
+	}
+
+	pendingPods, err := r.countPendingPods(ctx, nodePool.Name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	current := int32(len(nodes))
+	// the nodes which have been requested but not joined are counted in, so they are not requested again.
+	inflight, updated := r.inflightNodeNum(status, current)
+	if inflight > 0 {
+		// the pending pods are waiting for the in-flight nodes.
+		pendingPods = 0
+	}
+	desired := desiredNodeNum(nodePool, current+inflight, pendingPods)
+	switch {
+	case desired > current+inflight:
+		delta := desired - current - inflight
+		klog.Infof(Format("NodePool %s will be scaled up from %d to %d nodes by provider %s, pending pods: %d, in-flight nodes: %d", nodePool.Name, current, desired, r.provider.Name(), pendingPods, inflight))
+		if err := r.provider.IncreaseSize(ctx, nodePool, delta); err != nil {
+			r.recordEvent(nodePool, corev1.EventTypeWarning, "ScaleUpFailed", "could not add %d nodes, %v", delta, err)
+			return 0, updated, err
+		}
+		now := metav1.NewTime(r.clock.Now())
+		deadline := metav1.NewTime(now.Add(scaleUpTimeout))
+		status.ScaleUpTarget = &desired
+		status.ScaleUpDeadline = &deadline
+		status.LastScaleTime = &now
+		r.recordEvent(nodePool, corev1.EventTypeNormal, "ScaleUp", "request to add %d nodes", delta)
+		return cooldown, true, nil
+	case desired < current:
+		toRemove := selectNodesToRemove(nodes, current-desired)
+		klog.Infof(Format("NodePool %s will be scaled down from %d to %d nodes by provider %s, nodes %v will be drained and removed", nodePool.Name, current, desired, r.provider.Name(), toRemove))
+		cordoned, err := r.cordonNodes(ctx, nodes, toRemove)
+		if err != nil {
+			r.recordEvent(nodePool, corev1.EventTypeWarning, "ScaleDownFailed", "could not cordon nodes %v, %v", toRemove, err)
+			return 0, updated, err
+		}
+		now := metav1.NewTime(r.clock.Now())
+		status.CordonedNodes = cordoned
+		status.DrainingNodes = toRemove
+		status.DrainStartTime = &now
+		r.recordEvent(nodePool, corev1.EventTypeNormal, "ScaleDownStarted", "start to drain nodes %v", toRemove)
+		return drainInterval, true, nil
+	default:
+		return cooldown, updated, nil
+	}
+}
+
+// scaleDown evicts the pods on the draining nodes, and the nodes are removed through the provider after all
+// pods are evicted. the evictions respect PodDisruptionBudgets, and the scale down is aborted and the nodes
+// cordoned by autoscaling are uncordoned if the nodes are not drained in drainTimeout or not needed to be removed.
+func (r *ReconcileNodePool) scaleDown(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node) (time.Duration, bool, error) {
+	status := nodePool.Status.Autoscaling
+	cooldown := r.cfg.AutoscalingCooldown.Duration
+	if *nodePool.Spec.DesiredSize >= int32(len(nodes)) {
+		klog.Infof(Format("scale down of NodePool %s is aborted, because the desired size %d is not less than %d nodes", nodePool.Name, *nodePool.Spec.DesiredSize, len(nodes)))
+		r.recordEvent(nodePool, corev1.EventTypeNormal, "ScaleDownAborted", "nodes %v are not removed, because the desired size is not less than %d nodes", status.DrainingNodes, len(nodes))
+		return cooldown, true, r.abortScaleDown(ctx, nodePool)
+	}
+
+	if status.DrainStartTime != nil && r.clock.Since(status.DrainStartTime.Time) >= drainTimeout {
+		klog.Warningf(Format("nodes %v of NodePool %s are not drained in %v, scale down is aborted", status.DrainingNodes, nodePool.Name, drainTimeout))
+		r.recordEvent(nodePool, corev1.EventTypeWarning, "ScaleDownFailed", "nodes %v are not drained in %v", status.DrainingNodes, drainTimeout)
+		return cooldown, true, r.abortScaleDown(ctx, nodePool)
+	}
+
+	remaining := 0
+	for _, nodeName := range status.DrainingNodes {
+		pods, err := r.podsToEvict(ctx, nodeName)
+		if err != nil {
+			return 0, false, err
+		}
+		remaining += len(pods)
+		for i := range pods {
+			if pods[i].DeletionTimestamp != nil {
+				continue
+			}
+			eviction := &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pods[i].Name,
+					Namespace: pods[i].Namespace,
+				},
+			}
+			if err := r.kubeClient.PolicyV1().Evictions(pods[i].Namespace).Evict(ctx, eviction); err != nil && !apierrors.IsNotFound(err) {
+				// the eviction may be rejected by PodDisruptionBudget, so retry it later
+				klog.Warningf(Format("could not evict pod %s/%s on node %s, %v", pods[i].Namespace, pods[i].Name, nodeName, err))
+				continue
+			}
+			klog.Infof(Format("pod %s/%s is evicted from node %s for scaling down NodePool %s", pods[i].Namespace, pods[i].Name, nodeName, nodePool.Name))
+		}
+	}
+	if remaining != 0 {
+		return drainInterval, false, nil
+	}
+
+	if err := r.provider.DeleteNodes(ctx, nodePool, status.DrainingNodes); err != nil {
+		r.recordEvent(nodePool, corev1.EventTypeWarning, "ScaleDownFailed", "could not remove nodes %v, %v", status.DrainingNodes, err)
+		return 0, false, err
+	}
+	r.recordEvent(nodePool, corev1.EventTypeNormal, "ScaleDown", "request to remove nodes %v", status.DrainingNodes)
+
+	now := metav1.NewTime(r.clock.Now())
+	status.LastScaleTime = &now
+	status.DrainingNodes = nil
+	status.CordonedNodes = nil
+	status.DrainStartTime = nil
+	return cooldown, true, nil
+}
+
+// abortScaleDown uncordons the nodes cordoned by autoscaling, and the nodepool is
+// not scaled again until the cooldown elapses.
+func (r *ReconcileNodePool) abortScaleDown(ctx context.Context, nodePool *appsv1beta1.NodePool) error {
+	status := nodePool.Status.Autoscaling
+	for _, nodeName := range status.CordonedNodes {
+		var node corev1.Node
+		if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !node.Spec.Unschedulable {
+			continue
+		}
+		node.Spec.Unschedulable = false
+		if err := r.Update(ctx, &node); err != nil {
+			return err
+		}
+		klog.Infof(Format("node %s is uncordoned for aborting scale down of NodePool %s", nodeName, nodePool.Name))
+	}
+
+	now := metav1.NewTime(r.clock.Now())
+	status.LastScaleTime = &now
+	status.DrainingNodes = nil
+	status.CordonedNodes = nil
+	status.DrainStartTime = nil
+	return nil
+}
+
+// cordonNodes marks the nodes to be removed as unschedulable, and the nodes which are cordoned by
+// autoscaling are returned. the cordoned nodes are uncordoned if any of the nodes can not be cordoned.
+func (r *ReconcileNodePool) cordonNodes(ctx context.Context, nodes []corev1.Node, toRemove []string) ([]string, error) {
+	names := sets.NewString(toRemove...)
+	var cordoned []*corev1.Node
+	for i := range nodes {
+		if !names.Has(nodes[i].Name) || nodes[i].Spec.Unschedulable {
+			continue
+		}
+		node := nodes[i].DeepCopy()
+		node.Spec.Unschedulable = true
+		if err := r.Update(ctx, node); err != nil {
+			for _, n := range cordoned {
+				n.Spec.Unschedulable = false
+				if err := r.Update(ctx, n); err != nil {
+					klog.Errorf(Format("could not uncordon node %s, %v", n.Name, err))
+				}
+			}
+			return nil, err
+		}
+		cordoned = append(cordoned, node)
+		klog.Infof(Format("node %s is cordoned for scaling down", node.Name))
+	}
+
+	cordonedNames := make([]string, 0, len(cordoned))
+	for _, n := range cordoned {
+		cordonedNames = append(cordonedNames, n.Name)
+	}
+	return cordonedNames, nil
+}
+
+// podsToEvict returns the pods on the node which should be evicted before the node is removed,
+// the pods of DaemonSets, static pods and the terminated pods are not evicted.
+func (r *ReconcileNodePool) podsToEvict(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.MatchingFields{podNodeIndex: nodeName}); err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+			continue
+		}
+		pods = append(pods, *pod)
+	}
+	return pods, nil
+}
+
+// indexPodNode returns the node of pod.
+func indexPodNode(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || len(pod.Spec.NodeName) == 0 {
+		return []string{}
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+func (r *ReconcileNodePool) recordEvent(nodePool *appsv1beta1.NodePool, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.recorder != nil {
+		r.recorder.Eventf(nodePool, eventType, reason, messageFmt, args...)
+	}
+}
+
+// inflightNodeNum returns the number of nodes which have been requested from provider but not joined
+// the nodepool, the record is cleaned up when the nodes joined or timeout, and whether the status is
+// updated is returned.
+func (r *ReconcileNodePool) inflightNodeNum(status *appsv1beta1.NodePoolAutoscalingStatus, current int32) (int32, bool) {
+	if status.ScaleUpTarget == nil {
+		return 0, false
+	}
+	if current >= *status.ScaleUpTarget || status.ScaleUpDeadline == nil || r.clock.Now().After(status.ScaleUpDeadline.Time) {
+		status.ScaleUpTarget = nil
+		status.ScaleUpDeadline = nil
+		return 0, true
+	}
+	return *status.ScaleUpTarget - current, false
+}
+
+// countPendingPods counts the pods which are pinned to the nodepool by nodeSelector
+// but can not be scheduled.
+func (r *ReconcileNodePool) countPendingPods(ctx context.Context, pool string) (int32, error) {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.MatchingFields{pendingPodPoolIndex: pool}); err != nil {
+		return 0, err
+	}
+
+	var pending int32
+	for i := range podList.Items {
+		if podList.Items[i].Spec.NodeSelector[apps.NodePoolLabel] == pool && isPodUnschedulable(&podList.Items[i]) {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// indexPendingPodPool returns the nodepool of pod which is not scheduled and pinned to the nodepool.
+func indexPendingPodPool(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || len(pod.Spec.NodeName) != 0 || len(pod.Spec.NodeSelector[apps.NodePoolLabel]) == 0 {
+		return []string{}
+	}
+	return []string{pod.Spec.NodeSelector[apps.NodePoolLabel]}
+}
+
+// isPodUnschedulable checks if the pod is pending because there's no node fits it.
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodPending || len(pod.Spec.NodeName) != 0 {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// desiredNodeNum computes the number of nodes expected in the nodepool. nodes are added
+// one by one for pending pods until spec.maxSize is reached, so pending pods don't trigger
+// adding nodes if spec.maxSize is not specified, and nodes will not be removed when there
+// are pending pods.
+func desiredNodeNum(nodePool *appsv1beta1.NodePool, current, pendingPods int32) int32 {
+	desired := *nodePool.Spec.DesiredSize
+	if pendingPods == 0 {
+		return desired
+	}
+
+	if desired < current {
+		desired = current
+	}
+	if nodePool.Spec.MaxSize != nil && desired == current && current < *nodePool.Spec.MaxSize {
+		desired = current + 1
+	}
+	return desired
+}
+
+// selectNodesToRemove selects count nodes which will be removed from the nodepool,
+// unready nodes are preferred, then the newer nodes.
+func selectNodesToRemove(nodes []corev1.Node, count int32) []string {
+	candidates := make([]corev1.Node, len(nodes))
+	copy(candidates, nodes)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		iReady, jReady := isNodeReady(candidates[i]), isNodeReady(candidates[j])
+		if iReady != jReady {
+			return !iReady
+		}
+		if !candidates[i].CreationTimestamp.Equal(&candidates[j].CreationTimestamp) {
+			return candidates[j].CreationTimestamp.Before(&candidates[i].CreationTimestamp)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	if int(count) > len(candidates) {
+		count = int32(len(candidates))
+	}
+	names := make([]string, 0, count)
+	for i := 0; i < int(count); i++ {
+		names = append(names, candidates[i].Name)
+	}
+	return names
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	poolconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool/config"
+)
+
+type fakeProvider struct {
+	increased int32
+	deleted   []string
+}
+
+func (fp *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (fp *fakeProvider) IncreaseSize(ctx context.Context, nodePool *appsv1beta1.NodePool, delta int32) error {
+	fp.increased += delta
+	return nil
+}
+
+func (fp *fakeProvider) DeleteNodes(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []string) error {
+	fp.deleted = append(fp.deleted, nodes...)
+	return nil
+}
+
+func TestDesiredNodeNum(t *testing.T) {
+	testcases := map[string]struct {
+		desiredSize *int32
+		maxSize     *int32
+		current     int32
+		pendingPods int32
+		want        int32
+	}{
+		"no pending pods": {
+			desiredSize: pointer.Int32(3),
+			current:     5,
+			want:        3,
+		},
+		"pending pods without max size": {
+			desiredSize: pointer.Int32(3),
+			maxSize:     nil,
+			current:     3,
+			pendingPods: 2,
+			want:        3,
+		},
+		"pending pods with max size": {
+			desiredSize: pointer.Int32(3),
+			maxSize:     pointer.Int32(5),
+			current:     3,
+			pendingPods: 2,
+			want:        4,
+		},
+		"pending pods when max size is reached": {
+			desiredSize: pointer.Int32(3),
+			maxSize:     pointer.Int32(5),
+			current:     5,
+			pendingPods: 2,
+			want:        5,
+		},
+		"pending pods when desired size is not reached": {
+			desiredSize: pointer.Int32(3),
+			maxSize:     pointer.Int32(5),
+			current:     1,
+			pendingPods: 2,
+			want:        3,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			pool := &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					DesiredSize: tc.desiredSize,
+					MaxSize:     tc.maxSize,
+				},
+			}
+			if got := desiredNodeNum(pool, tc.current, tc.pendingPods); got != tc.want {
+				t.Errorf("Expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectNodesToRemove(t *testing.T) {
+	now := time.Now()
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1", CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Hour))},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node2", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node3", CreationTimestamp: metav1.NewTime(now.Add(-4 * time.Hour))},
+		},
+	}
+
+	testcases := map[string]struct {
+		count int32
+		want  []string
+	}{
+		"remove one node": {
+			count: 1,
+			want:  []string{"node3"},
+		},
+		"remove two nodes": {
+			count: 2,
+			want:  []string{"node3", "node2"},
+		},
+		"remove more nodes than the pool has": {
+			count: 5,
+			want:  []string{"node3", "node2", "node1"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := selectNodesToRemove(nodes, tc.count); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAutoscaleNodePool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	pendingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{apps.NodePoolLabel: "hangzhou"},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+			},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pendingPod).Build()
+
+	pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Spec: appsv1beta1.NodePoolSpec{
+			DesiredSize: pointer.Int32(1),
+			MaxSize:     pointer.Int32(3),
+		},
+	}
+	nodes := []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}
+
+	provider := &fakeProvider{}
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	r := &ReconcileNodePool{
+		Client: c,
+		cfg: poolconfig.NodePoolControllerConfiguration{
+			AutoscalingCooldown: metav1.Duration{Duration: time.Minute},
+		},
+		provider: provider,
+		clock:    fakeClock,
+	}
+
+	requeueAfter, updated, err := r.autoscaleNodePool(context.TODO(), pool, nodes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.increased != 1 || requeueAfter != time.Minute || !updated {
+		t.Errorf("Expected 1 node is added and requeue after 1m, got %d and %v", provider.increased, requeueAfter)
+	}
+	if status := pool.Status.Autoscaling; status.LastScaleTime == nil || status.ScaleUpTarget == nil || *status.ScaleUpTarget != 2 {
+		t.Errorf("Expected the scale up is recorded in status, got %#+v", status)
+	}
+
+	// scaling is skipped in cooldown
+	if _, _, err := r.autoscaleNodePool(context.TODO(), pool, nodes); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.increased != 1 {
+		t.Errorf("Expected no more nodes are added in cooldown, got %d", provider.increased)
+	}
+
+	// the requested node is in-flight after cooldown, so it's not requested again even if
+	// the reconciler is restarted, because the state of scaling is recorded in status.
+	fakeClock.Step(2 * time.Minute)
+	r = &ReconcileNodePool{Client: c, cfg: r.cfg, provider: provider, clock: fakeClock}
+	if _, _, err := r.autoscaleNodePool(context.TODO(), pool, nodes); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.increased != 1 {
+		t.Errorf("Expected in-flight node is not requested again, got %d", provider.increased)
+	}
+
+	// one more node is requested when the in-flight node joined and pods are still pending.
+	nodes = append(nodes, corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2"}})
+	if _, _, err := r.autoscaleNodePool(context.TODO(), pool, nodes); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.increased != 2 {
+		t.Errorf("Expected one more node is added after in-flight node joined, got %d", provider.increased)
+	}
+}
+
+func TestAutoscaleNodePoolScaleDown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	now := time.Now()
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}},
+	}
+	pods := []client.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node2"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "daemon1",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "ds", UID: "ds", Controller: pointer.Bool(true)}},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node2"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+
+	testcases := map[string]struct {
+		evictionErr error
+		elapsed     time.Duration
+		deleted     []string
+		cordoned    bool
+	}{
+		"nodes are removed after drained": {
+			deleted: []string{"node2"},
+		},
+		"scale down is aborted when pods can not be evicted in time": {
+			evictionErr: apierrors.NewTooManyRequests("disruption budget", 10),
+			elapsed:     drainTimeout,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			objs := []client.Object{nodes[0].DeepCopy(), nodes[1].DeepCopy()}
+			for i := range pods {
+				objs = append(objs, pods[i].DeepCopyObject().(client.Object))
+			}
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			var nodeList corev1.NodeList
+			if err := c.List(context.TODO(), &nodeList); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			kubeClient := fake.NewSimpleClientset()
+			kubeClient.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				if tc.evictionErr != nil {
+					return true, nil, tc.evictionErr
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policyv1.Eviction)
+				pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: eviction.Name, Namespace: eviction.Namespace}}
+				return true, nil, c.Delete(context.TODO(), pod)
+			})
+
+			pool := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+				Spec:       appsv1beta1.NodePoolSpec{DesiredSize: pointer.Int32(1)},
+			}
+			provider := &fakeProvider{}
+			fakeClock := testingclock.NewFakeClock(now)
+			r := &ReconcileNodePool{
+				Client:     c,
+				kubeClient: kubeClient,
+				cfg: poolconfig.NodePoolControllerConfiguration{
+					AutoscalingCooldown: metav1.Duration{Duration: time.Minute},
+				},
+				provider: provider,
+				clock:    fakeClock,
+			}
+
+			// the newer node is cordoned and drained before it's removed
+			if _, _, err := r.autoscaleNodePool(context.TODO(), pool, nodeList.Items); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var node corev1.Node
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: "node2"}, &node); err != nil || !node.Spec.Unschedulable {
+				t.Fatalf("Expected node2 is cordoned, got %v, %v", node.Spec.Unschedulable, err)
+			}
+			if !reflect.DeepEqual(pool.Status.Autoscaling.DrainingNodes, []string{"node2"}) || len(provider.deleted) != 0 {
+				t.Fatalf("Expected node2 is draining and not removed, got %#+v, %v", pool.Status.Autoscaling, provider.deleted)
+			}
+
+			for i := 0; i < 3 && len(pool.Status.Autoscaling.DrainingNodes) != 0; i++ {
+				fakeClock.Step(tc.elapsed)
+				if _, _, err := r.autoscaleNodePool(context.TODO(), pool, nodeList.Items); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if !reflect.DeepEqual(provider.deleted, tc.deleted) {
+				t.Errorf("Expected nodes %v are removed, got %v", tc.deleted, provider.deleted)
+			}
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: "node2"}, &node); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tc.deleted == nil && node.Spec.Unschedulable {
+				t.Errorf("Expected node2 is uncordoned when scale down is aborted")
+			}
+			if status := pool.Status.Autoscaling; len(status.DrainingNodes) != 0 || status.LastScaleTime == nil {
+				t.Errorf("Expected scale down is finished, got %#+v", status)
+			}
+		})
+	}
+}
+
+func TestIndexPendingPodPool(t *testing.T) {
+	testcases := map[string]struct {
+		pod  *corev1.Pod
+		want []string
+	}{
+		"pending pod pinned to nodepool": {
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{NodeSelector: map[string]string{apps.NodePoolLabel: "hangzhou"}},
+			},
+			want: []string{"hangzhou"},
+		},
+		"scheduled pod pinned to nodepool": {
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{NodeName: "node1", NodeSelector: map[string]string{apps.NodePoolLabel: "hangzhou"}},
+			},
+			want: []string{},
+		},
+		"pending pod not pinned to nodepool": {
+			pod:  &corev1.Pod{},
+			want: []string{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := indexPendingPodPool(tc.pod); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -16,7 +16,27 @@ limitations under the License.
 
 package config
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // NodePoolControllerConfiguration contains elements describing NodePoolController.
 type NodePoolControllerConfiguration struct {
 	EnableSyncNodePoolConfigurations bool
+
+	// AutoscalingProviderEndpoint is the address of external provisioner which adds
+	// or removes nodes for nodepools, autoscaling of nodepool is disabled if it's empty.
+	AutoscalingProviderEndpoint string
+	// AutoscalingCooldown is the minimum interval between two scaling requests for
+	// the same nodepool.
+	AutoscalingCooldown metav1.Duration
+
+	// NodeServantImage is the image of node-servant which is used for applying
+	// node configurations of nodepool on nodes, node configurations are only
+	// rendered into ConfigMaps if it's empty.
+	NodeServantImage string
+
+	// EnableDisruptionBudget enables creating and maintaining PodDisruptionBudgets
+	// for the workloads selected by spec.disruptionBudget of nodepools.
+	EnableDisruptionBudget bool
 }
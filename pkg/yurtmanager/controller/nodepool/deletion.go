@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// deletionRetryInterval is the interval for checking whether the deleting nodepool is empty.
+const deletionRetryInterval = 30 * time.Second
+
+// deleteNodePool handles the nodes of deleting nodepool according to the deletion policy,
+// and the finalizer is removed only when there's no node left in the nodepool.
+func (r *ReconcileNodePool) deleteNodePool(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(nodePool, appsv1beta1.NodePoolFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := nodePool.Spec.DeletionPolicy
+	switch policy {
+	case appsv1beta1.Orphan, appsv1beta1.Evacuate:
+		for i := range nodes {
+			if err := orphanNode(&nodes[i]); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Update(ctx, &nodes[i]); err != nil {
+				klog.Errorf(Format("could not detach node %s from NodePool %s, %v", nodes[i].Name, nodePool.Name, err))
+				return ctrl.Result{}, err
+			}
+			klog.Infof(Format("node %s is detached from deleting NodePool %s", nodes[i].Name, nodePool.Name))
+		}
+
+		if policy == appsv1beta1.Evacuate {
+			if err := r.evacuatePods(ctx, nodePool); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	default:
+		if len(nodes) != 0 {
+			klog.Infof(Format("NodePool %s can not be deleted until %d nodes leave it", nodePool.Name, len(nodes)))
+			r.recorder.Eventf(nodePool, corev1.EventTypeWarning, "DeletionBlocked",
+				"NodePool can not be deleted until %d nodes leave it with deletion policy %s", len(nodes), appsv1beta1.ForbidIfNonEmpty)
+			return ctrl.Result{RequeueAfter: deletionRetryInterval}, nil
+		}
+	}
+
+	controllerutil.RemoveFinalizer(nodePool, appsv1beta1.NodePoolFinalizer)
+	return ctrl.Result{}, r.Update(ctx, nodePool)
+}
+
+// orphanNode removes the nodepool labels and the attributes synced from nodepool on the node.
+func orphanNode(node *corev1.Node) error {
+	oldNpra, err := decodePoolAttrs(node)
+	if err != nil {
+		return err
+	}
+
+	// attributes modified on the node directly are kept
+	conciliateLabels(node, oldNpra.Labels, nil, appsv1beta1.NodeWins)
+	conciliateAnnotations(node, oldNpra.Annotations, nil, appsv1beta1.NodeWins)
+	conciliateTaints(node, oldNpra.Taints, nil, appsv1beta1.NodeWins)
+	delete(node.Annotations, apps.AnnotationPrevAttrs)
+	delete(node.Labels, apps.NodePoolLabel)
+	delete(node.Labels, apps.NodePoolTypeLabel)
+	delete(node.Labels, apps.NodePoolHostNetworkLabel)
+	return nil
+}
+
+// evacuatePods deletes the scheduled pods which are bound to the deleting nodepool, like pods
+// of YurtAppSet. nodes are detached from nodepool before, so the pods will not be scheduled
+// to the same nodes again.
+func (r *ReconcileNodePool) evacuatePods(ctx context.Context, nodePool *appsv1beta1.NodePool) error {
+	var podList corev1.PodList
+	if err := r.List(ctx, &podList, client.MatchingLabels{apps.PoolNameLabelKey: nodePool.Name}); err != nil {
+		return err
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if len(pod.Spec.NodeName) == 0 || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf(Format("could not evacuate pod %s/%s of NodePool %s, %v", pod.Namespace, pod.Name, nodePool.Name, err))
+			return err
+		}
+		klog.Infof(Format("pod %s/%s is evacuated from deleting NodePool %s", pod.Namespace, pod.Name, nodePool.Name))
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestDeleteNodePool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	testcases := map[string]struct {
+		policy          appsv1beta1.DeletionPolicy
+		expectFinalizer bool
+		expectPoolLabel bool
+		expectPods      int
+	}{
+		"forbid deleting nonempty pool": {
+			policy:          appsv1beta1.ForbidIfNonEmpty,
+			expectFinalizer: true,
+			expectPoolLabel: true,
+			expectPods:      1,
+		},
+		"forbid deleting nonempty pool by default": {
+			expectFinalizer: true,
+			expectPoolLabel: true,
+			expectPods:      1,
+		},
+		"orphan nodes of pool": {
+			policy:     appsv1beta1.Orphan,
+			expectPods: 1,
+		},
+		"evacuate nodes of pool": {
+			policy:     appsv1beta1.Evacuate,
+			expectPods: 0,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			now := metav1.Now()
+			pool := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "hangzhou",
+					Finalizers:        []string{appsv1beta1.NodePoolFinalizer},
+					DeletionTimestamp: &now,
+				},
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:           appsv1beta1.Edge,
+					DeletionPolicy: tc.policy,
+				},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node1",
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "hangzhou",
+						apps.NodePoolTypeLabel: "edge",
+						"region":               "hangzhou",
+						"app":                  "edge",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationPrevAttrs: "{\"labels\":{\"region\":\"hangzhou\"}}",
+					},
+				},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "nginx",
+					Namespace: "default",
+					Labels: map[string]string{
+						apps.PoolNameLabelKey: "hangzhou",
+					},
+				},
+				Spec: corev1.PodSpec{NodeName: "node1"},
+			}
+
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pool, node, pod).Build()
+			r := &ReconcileNodePool{
+				Client:   c,
+				recorder: record.NewFakeRecorder(10),
+			}
+			ctx := context.TODO()
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "hangzhou"}}); err != nil {
+				t.Fatalf("could not reconcile NodePool, %v", err)
+			}
+
+			var currentPool appsv1beta1.NodePool
+			err := c.Get(ctx, types.NamespacedName{Name: "hangzhou"}, &currentPool)
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Fatalf("could not get NodePool, %v", err)
+			}
+			if hasFinalizer := err == nil && len(currentPool.Finalizers) != 0; hasFinalizer != tc.expectFinalizer {
+				t.Errorf("expect finalizer %v, but got %v", tc.expectFinalizer, hasFinalizer)
+			}
+
+			var currentNode corev1.Node
+			if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &currentNode); err != nil {
+				t.Fatalf("could not get node, %v", err)
+			}
+			if _, ok := currentNode.Labels[apps.NodePoolLabel]; ok != tc.expectPoolLabel {
+				t.Errorf("expect pool label %v, but got %v", tc.expectPoolLabel, ok)
+			}
+			if !tc.expectPoolLabel {
+				if _, ok := currentNode.Labels["region"]; ok {
+					t.Errorf("expect label synced from pool is removed, but got %v", currentNode.Labels)
+				}
+				if currentNode.Labels["app"] != "edge" {
+					t.Errorf("expect label of node is kept, but got %v", currentNode.Labels)
+				}
+			}
+
+			var podList corev1.PodList
+			if err := c.List(ctx, &podList, client.InNamespace("default")); err != nil {
+				t.Fatalf("could not list pods, %v", err)
+			}
+			if len(podList.Items) != tc.expectPods {
+				t.Errorf("expect %d pods, but got %d", tc.expectPods, len(podList.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// disruptionBudgetNamePrefix is the prefix of PodDisruptionBudgets created for nodepool.
+const disruptionBudgetNamePrefix = "nodepool-"
+
+// conciliateDisruptionBudget makes sure there's a PodDisruptionBudget in every namespace that
+// has the selected pods of nodepool, and removes the PodDisruptionBudgets that are not needed.
+// pods are regarded as in the nodepool when they are labeled with apps.openyurt.io/pool-name,
+// like the pods of YurtAppSet and YurtAppDaemon.
+func (r *ReconcileNodePool) conciliateDisruptionBudget(ctx context.Context, nodePool *appsv1beta1.NodePool) error {
+	var pdbList policyv1.PodDisruptionBudgetList
+	if err := r.List(ctx, &pdbList, client.MatchingLabels{apps.NodePoolLabel: nodePool.Name}); err != nil {
+		return err
+	}
+
+	desired := make(map[string]*policyv1.PodDisruptionBudget)
+	if nodePool.Spec.DisruptionBudget != nil {
+		selector := poolPodSelector(nodePool)
+		podSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return err
+		}
+
+		var podList corev1.PodList
+		if err := r.List(ctx, &podList, client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+			return err
+		}
+		for i := range podList.Items {
+			ns := podList.Items[i].Namespace
+			if _, ok := desired[ns]; !ok {
+				desired[ns] = newDisruptionBudget(nodePool, ns, selector)
+			}
+		}
+	}
+
+	for i := range pdbList.Items {
+		pdb := &pdbList.Items[i]
+		want, ok := desired[pdb.Namespace]
+		if !ok || pdb.Name != want.Name {
+			if err := r.Delete(ctx, pdb); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			klog.Infof(Format("PodDisruptionBudget %s/%s of NodePool %s is deleted", pdb.Namespace, pdb.Name, nodePool.Name))
+			continue
+		}
+
+		delete(desired, pdb.Namespace)
+		if reflect.DeepEqual(pdb.Spec.Selector, want.Spec.Selector) && reflect.DeepEqual(pdb.Spec.MaxUnavailable, want.Spec.MaxUnavailable) {
+			continue
+		}
+		pdb.Spec.Selector = want.Spec.Selector
+		pdb.Spec.MaxUnavailable = want.Spec.MaxUnavailable
+		if err := r.Update(ctx, pdb); err != nil {
+			return err
+		}
+		klog.Infof(Format("PodDisruptionBudget %s/%s of NodePool %s is updated", pdb.Namespace, pdb.Name, nodePool.Name))
+	}
+
+	for _, pdb := range desired {
+		if err := r.Create(ctx, pdb); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		klog.Infof(Format("PodDisruptionBudget %s/%s of NodePool %s is created", pdb.Namespace, pdb.Name, nodePool.Name))
+	}
+	return nil
+}
+
+// poolPodSelector returns the selector of pods that are covered by the disruption budget of nodepool.
+func poolPodSelector(nodePool *appsv1beta1.NodePool) *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{}
+	if nodePool.Spec.DisruptionBudget.Selector != nil {
+		selector = nodePool.Spec.DisruptionBudget.Selector.DeepCopy()
+	}
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = make(map[string]string)
+	}
+	selector.MatchLabels[apps.PoolNameLabelKey] = nodePool.Name
+	return selector
+}
+
+func newDisruptionBudget(nodePool *appsv1beta1.NodePool, namespace string, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	if nodePool.Spec.DisruptionBudget.MaxUnavailable != nil {
+		maxUnavailable = *nodePool.Spec.DisruptionBudget.MaxUnavailable
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      disruptionBudgetNamePrefix + nodePool.Name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				apps.NodePoolLabel: nodePool.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(nodePool, appsv1beta1.GroupVersion.WithKind("NodePool")),
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       selector.DeepCopy(),
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
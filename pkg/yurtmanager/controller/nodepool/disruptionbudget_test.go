@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestConciliateDisruptionBudget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	newPod := func(namespace, name, pool string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app":                 "nginx",
+					apps.PoolNameLabelKey: pool,
+				},
+			},
+		}
+	}
+	stalePDB := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      disruptionBudgetNamePrefix + "hangzhou",
+			Namespace: "stale",
+			Labels: map[string]string{
+				apps.NodePoolLabel: "hangzhou",
+			},
+		},
+	}
+	maxUnavailable := intstr.FromString("50%")
+
+	testcases := map[string]struct {
+		disruptionBudget *appsv1beta1.NodePoolDisruptionBudget
+		expectPDBs       map[string]intstr.IntOrString
+	}{
+		"disruption budget is not specified": {
+			disruptionBudget: nil,
+			expectPDBs:       map[string]intstr.IntOrString{},
+		},
+		"create PodDisruptionBudgets for selected pods": {
+			disruptionBudget: &appsv1beta1.NodePoolDisruptionBudget{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "nginx"},
+				},
+			},
+			expectPDBs: map[string]intstr.IntOrString{
+				"default": intstr.FromInt(1),
+				"kube-ns": intstr.FromInt(1),
+			},
+		},
+		"create PodDisruptionBudgets with max unavailable": {
+			disruptionBudget: &appsv1beta1.NodePoolDisruptionBudget{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "nginx"},
+				},
+				MaxUnavailable: &maxUnavailable,
+			},
+			expectPDBs: map[string]intstr.IntOrString{
+				"default": maxUnavailable,
+				"kube-ns": maxUnavailable,
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newPod("default", "pod1", "hangzhou"),
+				newPod("default", "pod2", "hangzhou"),
+				newPod("kube-ns", "pod3", "hangzhou"),
+				newPod("other", "pod4", "beijing"),
+				stalePDB.DeepCopy(),
+			).Build()
+			r := &ReconcileNodePool{Client: c}
+			pool := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "hangzhou", UID: "hangzhou-uid"},
+				Spec: appsv1beta1.NodePoolSpec{
+					DisruptionBudget: tc.disruptionBudget,
+				},
+			}
+
+			if err := r.conciliateDisruptionBudget(context.TODO(), pool); err != nil {
+				t.Fatalf("could not conciliate disruption budget, %v", err)
+			}
+
+			var pdbList policyv1.PodDisruptionBudgetList
+			if err := c.List(context.TODO(), &pdbList, client.MatchingLabels{apps.NodePoolLabel: "hangzhou"}); err != nil {
+				t.Fatalf("could not list PodDisruptionBudgets, %v", err)
+			}
+			if len(pdbList.Items) != len(tc.expectPDBs) {
+				t.Errorf("expect %d PodDisruptionBudgets, but got %d", len(tc.expectPDBs), len(pdbList.Items))
+			}
+			for _, pdb := range pdbList.Items {
+				expect, ok := tc.expectPDBs[pdb.Namespace]
+				if !ok {
+					t.Errorf("unexpected PodDisruptionBudget %s/%s", pdb.Namespace, pdb.Name)
+					continue
+				}
+				if pdb.Spec.MaxUnavailable == nil || *pdb.Spec.MaxUnavailable != expect {
+					t.Errorf("expect max unavailable %v, but got %v", expect, pdb.Spec.MaxUnavailable)
+				}
+				if pdb.Spec.Selector.MatchLabels[apps.PoolNameLabelKey] != "hangzhou" {
+					t.Errorf("expect PodDisruptionBudget only selects pods of pool hangzhou, but got %v", pdb.Spec.Selector)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+// conciliateGatewayEndpoints adds the nodes with public ip in nodepool as the tunnel endpoints
+// of gateway if spec.gatewayEndpoints is enabled. the endpoints are not removed when nodes leave
+// the nodepool, because the gateway only elects active endpoints from its own nodes.
+func (r *ReconcileNodePool) conciliateGatewayEndpoints(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node) error {
+	if len(nodePool.Spec.Gateway) == 0 || nodePool.Spec.GatewayEndpoints == nil || !*nodePool.Spec.GatewayEndpoints {
+		return nil
+	}
+
+	var gw ravenv1beta1.Gateway
+	if err := r.Get(ctx, types.NamespacedName{Name: nodePool.Spec.Gateway}, &gw); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	endpoints := make(map[string]int, len(gw.Spec.Endpoints))
+	for i, ep := range gw.Spec.Endpoints {
+		if ep.Type == ravenv1beta1.Tunnel {
+			endpoints[ep.NodeName] = i
+		}
+	}
+
+	updated := false
+	for i := range nodes {
+		publicIP := nodes[i].Labels[apps.NodePublicIPLabel]
+		if net.ParseIP(publicIP) == nil {
+			continue
+		}
+
+		if idx, ok := endpoints[nodes[i].Name]; ok {
+			if gw.Spec.Endpoints[idx].PublicIP != publicIP {
+				gw.Spec.Endpoints[idx].PublicIP = publicIP
+				updated = true
+			}
+			continue
+		}
+		gw.Spec.Endpoints = append(gw.Spec.Endpoints, ravenv1beta1.Endpoint{
+			NodeName: nodes[i].Name,
+			Type:     ravenv1beta1.Tunnel,
+			Port:     ravenv1beta1.DefaultTunnelServerExposedPort,
+			PublicIP: publicIP,
+		})
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+	klog.Infof(Format("update tunnel endpoints of Gateway %s for NodePool %s", gw.Name, nodePool.Name))
+	return r.Update(ctx, &gw)
+}
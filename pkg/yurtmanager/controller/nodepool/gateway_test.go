@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+func TestConciliateGatewayEndpoints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	enabled, disabled := true, false
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{apps.NodePublicIPLabel: "1.1.1.1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{apps.NodePublicIPLabel: "2.2.2.2"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node3"}},
+	}
+
+	testcases := map[string]struct {
+		gatewayEndpoints *bool
+		wanted           []ravenv1beta1.Endpoint
+	}{
+		"gateway endpoints are enabled": {
+			gatewayEndpoints: &enabled,
+			wanted: []ravenv1beta1.Endpoint{
+				{NodeName: "node1", Type: ravenv1beta1.Tunnel, Port: 4500, PublicIP: "1.1.1.1"},
+				{NodeName: "node0", Type: ravenv1beta1.Proxy, Port: 10262},
+				{NodeName: "node2", Type: ravenv1beta1.Tunnel, Port: ravenv1beta1.DefaultTunnelServerExposedPort, PublicIP: "2.2.2.2"},
+			},
+		},
+		"gateway endpoints are disabled": {
+			gatewayEndpoints: &disabled,
+			wanted: []ravenv1beta1.Endpoint{
+				{NodeName: "node1", Type: ravenv1beta1.Tunnel, Port: 4500, PublicIP: "10.0.0.1"},
+				{NodeName: "node0", Type: ravenv1beta1.Proxy, Port: 10262},
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			gw := &ravenv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw-satellite"},
+				Spec: ravenv1beta1.GatewaySpec{
+					Endpoints: []ravenv1beta1.Endpoint{
+						{NodeName: "node1", Type: ravenv1beta1.Tunnel, Port: 4500, PublicIP: "10.0.0.1"},
+						{NodeName: "node0", Type: ravenv1beta1.Proxy, Port: 10262},
+					},
+				},
+			}
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(gw).Build()
+			r := &ReconcileNodePool{Client: c}
+			pool := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "satellite"},
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:             appsv1beta1.Satellite,
+					Gateway:          "gw-satellite",
+					GatewayEndpoints: tc.gatewayEndpoints,
+				},
+			}
+
+			if err := r.conciliateGatewayEndpoints(context.TODO(), pool, nodes); err != nil {
+				t.Fatalf("could not conciliate gateway endpoints, %v", err)
+			}
+
+			var got ravenv1beta1.Gateway
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: "gw-satellite"}, &got); err != nil {
+				t.Fatalf("could not get gateway, %v", err)
+			}
+			if !reflect.DeepEqual(got.Spec.Endpoints, tc.wanted) {
+				t.Errorf("expect endpoints %v, but got %v", tc.wanted, got.Spec.Endpoints)
+			}
+		})
+	}
+}
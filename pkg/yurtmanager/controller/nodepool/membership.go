@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	nodeutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/node"
+)
+
+const (
+	// membershipWindow is the duration of window for the membership statistics of nodepool.
+	membershipWindow = time.Hour
+	// flappingThreshold is the number of readiness changes in the window, the nodepool
+	// is regarded as unstable when nodes change readiness more than it.
+	flappingThreshold = 3
+
+	NodeJoinedEvent   = "NodeJoined"
+	NodeLeftEvent     = "NodeLeft"
+	NodeReadyEvent    = "NodeReady"
+	NodeNotReadyEvent = "NodeNotReady"
+)
+
+// conciliateMembership records events for nodes joining, leaving and changing readiness in the nodepool,
+// and updates the membership statistics and conditions of nodepool. it should be called before
+// the node list in nodepool status is updated.
+func (r *ReconcileNodePool) conciliateMembership(nodes []corev1.Node, nodePool *appsv1beta1.NodePool) (needUpdate bool) {
+	now := metav1.NewTime(r.clock.Now())
+	membership := nodePool.Status.Membership
+	if membership == nil {
+		membership = &appsv1beta1.NodePoolMembership{
+			WindowStart:      now,
+			LastObservedTime: now,
+		}
+		nodePool.Status.Membership = membership
+		needUpdate = true
+	} else if now.Sub(membership.WindowStart.Time) >= membershipWindow {
+		*membership = appsv1beta1.NodePoolMembership{
+			WindowStart:      now,
+			LastObservedTime: membership.LastObservedTime,
+		}
+		needUpdate = true
+	}
+
+	previous := make(map[string]struct{}, len(nodePool.Status.Nodes))
+	for _, name := range nodePool.Status.Nodes {
+		previous[name] = struct{}{}
+	}
+
+	lastObserved := membership.LastObservedTime
+	changed := false
+	for i := range nodes {
+		node := &nodes[i]
+		if _, ok := previous[node.Name]; !ok {
+			membership.Joined++
+			changed = true
+			r.recorder.Eventf(nodePool, corev1.EventTypeNormal, NodeJoinedEvent, "node %s joined the NodePool", node.Name)
+			continue
+		}
+		delete(previous, node.Name)
+
+		// the readiness of node is changed since last observation
+		_, cond := nodeutil.GetNodeCondition(&node.Status, corev1.NodeReady)
+		if cond == nil || !cond.LastTransitionTime.After(lastObserved.Time) {
+			continue
+		}
+		membership.ReadinessChanges++
+		changed = true
+		if cond.LastTransitionTime.After(now.Time) {
+			// use the latest transition time to avoid counting the change twice on clock skew
+			now = cond.LastTransitionTime
+		}
+		if cond.Status == corev1.ConditionTrue {
+			r.recorder.Eventf(nodePool, corev1.EventTypeNormal, NodeReadyEvent, "node %s in the NodePool became ready", node.Name)
+		} else {
+			r.recorder.Eventf(nodePool, corev1.EventTypeWarning, NodeNotReadyEvent, "node %s in the NodePool became not ready", node.Name)
+		}
+	}
+
+	for name := range previous {
+		membership.Left++
+		changed = true
+		r.recorder.Eventf(nodePool, corev1.EventTypeNormal, NodeLeftEvent, "node %s left the NodePool", name)
+	}
+
+	// the observed time is only updated on changes, so the nodepool status is not updated on every reconciliation
+	if changed {
+		membership.LastObservedTime = now
+		needUpdate = true
+	}
+
+	if conciliateNodePoolConditions(nodes, nodePool, now) {
+		needUpdate = true
+	}
+	return needUpdate
+}
+
+// conciliateNodePoolConditions updates the NodesReady and Stable conditions of nodepool.
+func conciliateNodePoolConditions(nodes []corev1.Node, nodePool *appsv1beta1.NodePool, now metav1.Time) (needUpdate bool) {
+	var notReady int
+	for i := range nodes {
+		if !isNodeReady(nodes[i]) {
+			notReady++
+		}
+	}
+
+	readyCond := newNodePoolCondition(appsv1beta1.NodePoolNodesReady, corev1.ConditionTrue, "AllNodesReady", "", now)
+	if len(nodes) == 0 {
+		readyCond = newNodePoolCondition(appsv1beta1.NodePoolNodesReady, corev1.ConditionFalse, "NoNodes", "there is no node in the NodePool", now)
+	} else if notReady != 0 {
+		readyCond = newNodePoolCondition(appsv1beta1.NodePoolNodesReady, corev1.ConditionFalse, "NodesNotReady",
+			fmt.Sprintf("%d of %d nodes are not ready", notReady, len(nodes)), now)
+	}
+	if setNodePoolCondition(&nodePool.Status, readyCond) {
+		needUpdate = true
+	}
+
+	membership := nodePool.Status.Membership
+	stableCond := newNodePoolCondition(appsv1beta1.NodePoolStable, corev1.ConditionTrue, "Stable", "", now)
+	if membership.ReadinessChanges >= flappingThreshold {
+		stableCond = newNodePoolCondition(appsv1beta1.NodePoolStable, corev1.ConditionFalse, "ReadinessFlapping",
+			fmt.Sprintf("nodes changed readiness %d times since %s", membership.ReadinessChanges, membership.WindowStart.Format(time.RFC3339)), now)
+	}
+	if setNodePoolCondition(&nodePool.Status, stableCond) {
+		needUpdate = true
+	}
+	return needUpdate
+}
+
+// membershipRequeueAfter returns the duration after which the membership window of nodepool expires.
+func (r *ReconcileNodePool) membershipRequeueAfter(nodePool *appsv1beta1.NodePool) time.Duration {
+	if nodePool.Status.Membership == nil {
+		return 0
+	}
+	after := membershipWindow - r.clock.Since(nodePool.Status.Membership.WindowStart.Time)
+	if after <= 0 {
+		return time.Second
+	}
+	return after
+}
+
+func newNodePoolCondition(condType appsv1beta1.NodePoolConditionType, status corev1.ConditionStatus, reason, message string, now metav1.Time) *appsv1beta1.NodePoolCondition {
+	return &appsv1beta1.NodePoolCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// setNodePoolCondition updates the nodepool status to include the provided condition, true is
+// returned if the condition is changed.
+func setNodePoolCondition(status *appsv1beta1.NodePoolStatus, condition *appsv1beta1.NodePoolCondition) bool {
+	for i := range status.Conditions {
+		current := &status.Conditions[i]
+		if current.Type != condition.Type {
+			continue
+		}
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return false
+		}
+		if current.Status == condition.Status {
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+		status.Conditions[i] = *condition
+		return true
+	}
+	status.Conditions = append(status.Conditions, *condition)
+	return true
+}
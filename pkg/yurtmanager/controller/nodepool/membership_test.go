@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestConciliateMembership(t *testing.T) {
+	now := time.Date(2023, 1, 1, 1, 0, 0, 0, time.Local)
+	lastObserved := metav1.NewTime(now.Add(-10 * time.Minute))
+	newNode := func(name string, status corev1.ConditionStatus, transition time.Time) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             status,
+						LastTransitionTime: metav1.NewTime(transition),
+					},
+				},
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		nodes            []corev1.Node
+		poolNodes        []string
+		membership       *appsv1beta1.NodePoolMembership
+		wantedMembership appsv1beta1.NodePoolMembership
+		wantedStable     corev1.ConditionStatus
+		wantedEvents     int
+	}{
+		"nodes join and leave the pool": {
+			nodes: []corev1.Node{
+				newNode("node1", corev1.ConditionTrue, now.Add(-time.Hour)),
+				newNode("node3", corev1.ConditionTrue, now.Add(-time.Hour)),
+			},
+			poolNodes: []string{"node1", "node2"},
+			membership: &appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now.Add(-30 * time.Minute)),
+				LastObservedTime: lastObserved,
+			},
+			wantedMembership: appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now.Add(-30 * time.Minute)),
+				LastObservedTime: metav1.NewTime(now),
+				Joined:           1,
+				Left:             1,
+			},
+			wantedStable: corev1.ConditionTrue,
+			wantedEvents: 2,
+		},
+		"readiness of nodes is flapping": {
+			nodes: []corev1.Node{
+				newNode("node1", corev1.ConditionFalse, now.Add(-time.Minute)),
+				newNode("node2", corev1.ConditionTrue, now.Add(-time.Hour)),
+			},
+			poolNodes: []string{"node1", "node2"},
+			membership: &appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now.Add(-30 * time.Minute)),
+				LastObservedTime: lastObserved,
+				ReadinessChanges: 2,
+			},
+			wantedMembership: appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now.Add(-30 * time.Minute)),
+				LastObservedTime: metav1.NewTime(now),
+				ReadinessChanges: 3,
+			},
+			wantedStable: corev1.ConditionFalse,
+			wantedEvents: 1,
+		},
+		"statistics window expires": {
+			nodes: []corev1.Node{
+				newNode("node1", corev1.ConditionTrue, now.Add(-time.Hour)),
+			},
+			poolNodes: []string{"node1"},
+			membership: &appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now.Add(-2 * time.Hour)),
+				LastObservedTime: lastObserved,
+				Joined:           5,
+				ReadinessChanges: 10,
+			},
+			wantedMembership: appsv1beta1.NodePoolMembership{
+				WindowStart:      metav1.NewTime(now),
+				LastObservedTime: lastObserved,
+			},
+			wantedStable: corev1.ConditionTrue,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &ReconcileNodePool{
+				recorder: recorder,
+				clock:    testingclock.NewFakeClock(now),
+			}
+			np := &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+				Status: appsv1beta1.NodePoolStatus{
+					Nodes:      tc.poolNodes,
+					Membership: tc.membership,
+				},
+			}
+
+			if !r.conciliateMembership(tc.nodes, np) {
+				t.Errorf("expect nodepool status is updated")
+			}
+			if *np.Status.Membership != tc.wantedMembership {
+				t.Errorf("expect membership %#+v, but got %#+v", tc.wantedMembership, *np.Status.Membership)
+			}
+			for _, cond := range np.Status.Conditions {
+				if cond.Type == appsv1beta1.NodePoolStable && cond.Status != tc.wantedStable {
+					t.Errorf("expect Stable condition %s, but got %s", tc.wantedStable, cond.Status)
+				}
+			}
+			if len(recorder.Events) != tc.wantedEvents {
+				t.Errorf("expect %d events, but got %d", tc.wantedEvents, len(recorder.Events))
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	nodeservant "github.com/openyurtio/openyurt/pkg/node-servant"
+	"github.com/openyurtio/openyurt/pkg/util/nodeconfig"
+)
+
+const (
+	// annotationNodeConfigHash records the hash of rendered node configurations in ConfigMap
+	annotationNodeConfigHash = "nodepool.openyurt.io/node-config-hash"
+	nodeConfigHashLength     = 10
+)
+
+// conciliateNodeConfig renders the node configurations of nodepool into ConfigMap, and starts
+// node-servant jobs to apply the configurations on nodes of the nodepool if node-servant image
+// is specified. if node configurations are removed from nodepool, the empty configurations
+// will be rendered so the configurations applied on nodes can be cleaned up.
+func (r *ReconcileNodePool) conciliateNodeConfig(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node) error {
+	var cm corev1.ConfigMap
+	key := types.NamespacedName{Namespace: r.namespace, Name: nodeconfig.ConfigMapName(nodePool.Name)}
+	err := r.Get(ctx, key, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+	if !exist && nodePool.Spec.NodeConfig == nil {
+		return nil
+	}
+
+	data, err := nodeconfig.Render(nodePool.Spec.NodeConfig)
+	if err != nil {
+		return err
+	}
+	hash := nodeconfig.Hash(data)
+
+	if !exist {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels: map[string]string{
+					apps.NodePoolLabel: nodePool.Name,
+				},
+				Annotations: map[string]string{
+					annotationNodeConfigHash: hash,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(nodePool, appsv1beta1.GroupVersion.WithKind("NodePool")),
+				},
+			},
+			Data: data,
+		}
+		if err := r.Create(ctx, &cm); err != nil {
+			return err
+		}
+		klog.Infof(Format("node config ConfigMap %s is created for NodePool %s", key, nodePool.Name))
+	} else if !reflect.DeepEqual(cm.Data, data) || cm.Annotations[annotationNodeConfigHash] != hash {
+		cm.Data = data
+		if cm.Annotations == nil {
+			cm.Annotations = make(map[string]string)
+		}
+		cm.Annotations[annotationNodeConfigHash] = hash
+		if err := r.Update(ctx, &cm); err != nil {
+			return err
+		}
+		klog.Infof(Format("node config ConfigMap %s is updated for NodePool %s", key, nodePool.Name))
+	}
+
+	if len(r.cfg.NodeServantImage) == 0 {
+		return nil
+	}
+	return r.applyNodeConfig(ctx, nodePool, nodes, key.Name, hash[:nodeConfigHashLength])
+}
+
+// applyNodeConfig makes sure there's a node-servant job for applying the current node configurations
+// on each node of the nodepool, and the jobs for the previous configurations are removed.
+func (r *ReconcileNodePool) applyNodeConfig(ctx context.Context, nodePool *appsv1beta1.NodePool, nodes []corev1.Node, cmName, hash string) error {
+	var jobList batchv1.JobList
+	if err := r.List(ctx, &jobList, client.InNamespace(r.namespace), client.MatchingLabels{apps.NodePoolLabel: nodePool.Name}); err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{})
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if !strings.HasPrefix(job.Name, nodeservant.ConfigNodeJobNameBase) {
+			continue
+		}
+		if strings.HasSuffix(job.Name, "-"+hash) {
+			existing[job.Name] = struct{}{}
+			continue
+		}
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for i := range nodes {
+		job, err := nodeservant.RenderNodeServantJob("config-node", map[string]string{
+			"node_servant_image": r.cfg.NodeServantImage,
+			"configmap_name":     cmName,
+			"namespace":          r.namespace,
+			"jobNameSuffix":      hash,
+		}, nodes[i].Name)
+		if err != nil {
+			return err
+		}
+		if _, ok := existing[job.Name]; ok {
+			continue
+		}
+
+		if job.Labels == nil {
+			job.Labels = make(map[string]string)
+		}
+		job.Labels[apps.NodePoolLabel] = nodePool.Name
+		if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		klog.Infof(Format("node-servant job %s is created for applying node config on node %s", job.Name, nodes[i].Name))
+	}
+	return nil
+}
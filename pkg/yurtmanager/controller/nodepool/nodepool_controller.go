@@ -21,14 +21,22 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -36,6 +44,8 @@ import (
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool/autoscaler"
 	poolconfig "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/nodepool/config"
 )
 
@@ -55,6 +65,14 @@ type ReconcileNodePool struct {
 	mapper   meta.RESTMapper
 	recorder record.EventRecorder
 	cfg      poolconfig.NodePoolControllerConfiguration
+	// namespace is where ConfigMaps and node-servant jobs for node configurations are created.
+	namespace string
+	// provider is used for adding or removing nodes of nodepool, and
+	// autoscaling is disabled when it's nil.
+	provider autoscaler.Provider
+	// kubeClient is used for evicting pods from the nodes which are removed by autoscaling.
+	kubeClient kubernetes.Interface
+	clock      clock.Clock
 }
 
 func (r *ReconcileNodePool) InjectClient(c client.Client) error {
@@ -62,6 +80,16 @@ func (r *ReconcileNodePool) InjectClient(c client.Client) error {
 	return nil
 }
 
+func (r *ReconcileNodePool) InjectConfig(cfg *rest.Config) error {
+	c, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Errorf(Format("could not create kube client, %v", err))
+		return err
+	}
+	r.kubeClient = c
+	return nil
+}
+
 func (r *ReconcileNodePool) InjectMapper(mapper meta.RESTMapper) error {
 	r.mapper = mapper
 	return nil
@@ -74,8 +102,14 @@ var _ reconcile.Reconciler = &ReconcileNodePool{}
 func Add(c *config.CompletedConfig, mgr manager.Manager) error {
 	klog.Infof("nodepool-controller add controller %s", controllerResource.String())
 	r := &ReconcileNodePool{
-		cfg:      c.ComponentConfig.NodePoolController,
-		recorder: mgr.GetEventRecorderFor(names.NodePoolController),
+		cfg:       c.ComponentConfig.NodePoolController,
+		recorder:  mgr.GetEventRecorderFor(names.NodePoolController),
+		namespace: c.ComponentConfig.Generic.WorkingNamespace,
+		clock:     clock.RealClock{},
+	}
+	if len(r.cfg.AutoscalingProviderEndpoint) != 0 {
+		r.provider = autoscaler.NewHTTPProvider(r.cfg.AutoscalingProviderEndpoint)
+		klog.Infof(Format("nodepool autoscaling is enabled with provider endpoint %s", r.cfg.AutoscalingProviderEndpoint))
 	}
 
 	// Create a new controller
@@ -106,6 +140,68 @@ func Add(c *config.CompletedConfig, mgr manager.Manager) error {
 		return err
 	}
 
+	// Watch for changes to ConfigMaps of node configurations
+	err = ctrl.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &appsv1beta1.NodePool{},
+		IsController: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to raven Gateways, so the reachability between pools can be
+	// updated when the tunnels are changed
+	if _, err := r.mapper.KindFor(ravenv1beta1.SchemeGroupVersion.WithResource("gateways")); err == nil {
+		err = ctrl.Watch(&source.Kind{Type: &ravenv1beta1.Gateway{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueAllNodePools))
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.cfg.EnableDisruptionBudget {
+		// Watch for changes to PodDisruptionBudgets of nodepool
+		err = ctrl.Watch(&source.Kind{Type: &policyv1.PodDisruptionBudget{}}, &handler.EnqueueRequestForOwner{
+			OwnerType:    &appsv1beta1.NodePool{},
+			IsController: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Watch for creation and deletion of pods in nodepool, so PodDisruptionBudgets
+		// can be created in the new namespaces of selected pods.
+		err = ctrl.Watch(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(
+			func(obj client.Object) []reconcile.Request {
+				poolName := obj.GetLabels()[apps.PoolNameLabelKey]
+				if len(poolName) == 0 {
+					return nil
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: poolName}}}
+			}), predicate.Funcs{
+			UpdateFunc: func(event.UpdateEvent) bool {
+				return false
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.provider != nil {
+		// pending pods of nodepool are listed by index when autoscaling, instead of listing all pods.
+		err = mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Pod{}, pendingPodPoolIndex, indexPendingPodPool)
+		if err != nil {
+			klog.Errorf(Format("failed to register field indexers for nodepool autoscaling, %v", err))
+			return err
+		}
+		// pods on the draining nodes are listed by index when scaling down.
+		err = mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Pod{}, podNodeIndex, indexPodNode)
+		if err != nil {
+			klog.Errorf(Format("failed to register field indexers for nodepool autoscaling, %v", err))
+			return err
+		}
+	}
+
 	return nil
 
 }
@@ -119,6 +215,12 @@ type NodePoolRelatedAttributes struct {
 // +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=core,resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=raven.openyurt.io,resources=gateways,verbs=get;list;watch;update
 
 // Reconcile reads that state of the cluster for a NodePool object and makes changes based on the state read
 // and what is in the NodePool.Spec
@@ -143,6 +245,19 @@ func (r *ReconcileNodePool) Reconcile(ctx context.Context, req reconcile.Request
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// handle nodes of the deleting node pool according to the deletion policy
+	if nodePool.DeletionTimestamp != nil {
+		return r.deleteNodePool(ctx, &nodePool, currentNodeList.Items)
+	}
+
+	if !controllerutil.ContainsFinalizer(&nodePool, appsv1beta1.NodePoolFinalizer) {
+		controllerutil.AddFinalizer(&nodePool, appsv1beta1.NodePoolFinalizer)
+		if err := r.Update(ctx, &nodePool); err != nil {
+			klog.Errorf(Format("could not add finalizer for NodePool %s, %v", nodePool.Name, err))
+			return ctrl.Result{}, err
+		}
+	}
+
 	var (
 		readyNode    int32
 		notReadyNode int32
@@ -174,13 +289,66 @@ func (r *ReconcileNodePool) Reconcile(ctx context.Context, req reconcile.Request
 		}
 	}
 
+	// render node configurations of the node pool and apply them on nodes
+	if err := r.conciliateNodeConfig(ctx, &nodePool, currentNodeList.Items); err != nil {
+		klog.Errorf(Format("could not conciliate node config of NodePool %s, %v", nodePool.Name, err))
+		return ctrl.Result{}, err
+	}
+
+	// register nodes with public ip as the tunnel endpoints of gateway
+	if err := r.conciliateGatewayEndpoints(ctx, &nodePool, currentNodeList.Items); err != nil {
+		klog.Errorf(Format("could not conciliate gateway endpoints of NodePool %s, %v", nodePool.Name, err))
+		return ctrl.Result{}, err
+	}
+
+	// maintain PodDisruptionBudgets for the selected workloads in the node pool
+	if r.cfg.EnableDisruptionBudget {
+		if err := r.conciliateDisruptionBudget(ctx, &nodePool); err != nil {
+			klog.Errorf(Format("could not conciliate PodDisruptionBudgets of NodePool %s, %v", nodePool.Name, err))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// add or remove nodes of the node pool if autoscaling is enabled
+	requeueAfter, scaled, err := r.autoscaleNodePool(ctx, &nodePool, currentNodeList.Items)
+	if err != nil {
+		klog.Errorf(Format("could not autoscale NodePool %s, %v", nodePool.Name, err))
+		return ctrl.Result{}, err
+	}
+
+	// record membership changes before the node list in status is updated
+	needUpdate := r.conciliateMembership(currentNodeList.Items, &nodePool)
+	if scaled {
+		needUpdate = true
+	}
+	if after := r.membershipRequeueAfter(&nodePool); after != 0 && (requeueAfter == 0 || after < requeueAfter) {
+		requeueAfter = after
+	}
+
 	// always update the node pool status if necessary
-	needUpdate := conciliateNodePoolStatus(readyNode, notReadyNode, nodes, &nodePool)
+	if conciliateNodePoolStatus(readyNode, notReadyNode, nodes, &nodePool) {
+		needUpdate = true
+	}
+	if conciliateNodePoolResources(currentNodeList.Items, &nodePool) {
+		needUpdate = true
+	}
+	if conciliateGPUInventory(currentNodeList.Items, &nodePool) {
+		needUpdate = true
+	}
+	if conciliateHubLeader(currentNodeList.Items, &nodePool) {
+		needUpdate = true
+	}
+	if updated, err := r.conciliateReachability(ctx, currentNodeList.Items, &nodePool); err != nil {
+		klog.Errorf(Format("could not conciliate reachability of NodePool %s, %v", nodePool.Name, err))
+		return ctrl.Result{}, err
+	} else if updated {
+		needUpdate = true
+	}
 	if needUpdate {
 		klog.V(5).Infof("nodepool(%s): (%#+v) will be updated", nodePool.Name, nodePool)
-		return ctrl.Result{}, r.Status().Update(ctx, &nodePool)
+		return ctrl.Result{RequeueAfter: requeueAfter}, r.Status().Update(ctx, &nodePool)
 	} else {
 		klog.V(5).Infof("nodepool(%#+v) don't need to be updated, ready=%d, notReady=%d, nodes=%v", nodePool, readyNode, notReadyNode, nodes)
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
@@ -20,12 +20,15 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -147,6 +150,7 @@ func TestReconcile(t *testing.T) {
 	apis.AddToScheme(scheme)
 
 	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pools...).WithObjects(nodes...).Build()
+	now := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local))
 	testcases := map[string]struct {
 		EnableSyncNodePoolConfigurations bool
 		pool                             string
@@ -170,6 +174,30 @@ func TestReconcile(t *testing.T) {
 					ReadyNodeNum:   1,
 					UnreadyNodeNum: 1,
 					Nodes:          []string{"node1", "node2"},
+					NodeCounts: appsv1beta1.NodePoolNodeCounts{
+						Ready:   1,
+						Unknown: 1,
+					},
+					Membership: &appsv1beta1.NodePoolMembership{
+						WindowStart:      now,
+						LastObservedTime: now,
+						Joined:           2,
+					},
+					Conditions: []appsv1beta1.NodePoolCondition{
+						{
+							Type:               appsv1beta1.NodePoolNodesReady,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: now,
+							Reason:             "NodesNotReady",
+							Message:            "1 of 2 nodes are not ready",
+						},
+						{
+							Type:               appsv1beta1.NodePoolStable,
+							Status:             corev1.ConditionTrue,
+							LastTransitionTime: now,
+							Reason:             "Stable",
+						},
+					},
 				},
 			},
 		},
@@ -190,6 +218,30 @@ func TestReconcile(t *testing.T) {
 					ReadyNodeNum:   1,
 					UnreadyNodeNum: 1,
 					Nodes:          []string{"node3", "node4"},
+					NodeCounts: appsv1beta1.NodePoolNodeCounts{
+						Ready:   1,
+						Unknown: 1,
+					},
+					Membership: &appsv1beta1.NodePoolMembership{
+						WindowStart:      now,
+						LastObservedTime: now,
+						Joined:           2,
+					},
+					Conditions: []appsv1beta1.NodePoolCondition{
+						{
+							Type:               appsv1beta1.NodePoolNodesReady,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: now,
+							Reason:             "NodesNotReady",
+							Message:            "1 of 2 nodes are not ready",
+						},
+						{
+							Type:               appsv1beta1.NodePoolStable,
+							Status:             corev1.ConditionTrue,
+							LastTransitionTime: now,
+							Reason:             "Stable",
+						},
+					},
 				},
 			},
 			wantedNodes: []corev1.Node{
@@ -242,6 +294,26 @@ func TestReconcile(t *testing.T) {
 				Status: appsv1beta1.NodePoolStatus{
 					ReadyNodeNum:   0,
 					UnreadyNodeNum: 0,
+					Membership: &appsv1beta1.NodePoolMembership{
+						WindowStart:      now,
+						LastObservedTime: now,
+						Joined:           0,
+					},
+					Conditions: []appsv1beta1.NodePoolCondition{
+						{
+							Type:               appsv1beta1.NodePoolNodesReady,
+							Status:             corev1.ConditionFalse,
+							LastTransitionTime: now,
+							Reason:             "NoNodes",
+							Message:            "there is no node in the NodePool",
+						},
+						{
+							Type:               appsv1beta1.NodePoolStable,
+							Status:             corev1.ConditionTrue,
+							LastTransitionTime: now,
+							Reason:             "Stable",
+						},
+					},
 				},
 			},
 		},
@@ -251,7 +323,9 @@ func TestReconcile(t *testing.T) {
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
 			r := &ReconcileNodePool{
-				Client: c,
+				Client:   c,
+				recorder: record.NewFakeRecorder(10),
+				clock:    testingclock.NewFakeClock(now.Time),
 				cfg: poolconfig.NodePoolControllerConfiguration{
 					EnableSyncNodePoolConfigurations: tc.EnableSyncNodePoolConfigurations,
 				},
@@ -80,11 +80,21 @@ func (e *EnqueueNodePoolForNode) Update(evt event.UpdateEvent,
 		klog.V(4).Infof(Format("node(%s) is added into pool(%s)", newNode.Name, newNp))
 		addNodePoolToWorkQueue(newNp, q)
 		return
+	} else if len(newNp) == 0 {
+		// node is detached from the pool
+		klog.V(4).Infof(Format("node(%s) is removed from pool(%s)", newNode.Name, oldNp))
+		addNodePoolToWorkQueue(oldNp, q)
+		return
 	} else if oldNp != newNp {
-		klog.Warningf("It is not allowed to change the NodePoolLabel of node, but pool of node(%s) is changed from %s to %s", newNode.Name, oldNp, newNp)
-		// emit a warning event
-		e.Recorder.Event(newNode.DeepCopy(), corev1.EventTypeWarning, apps.NodePoolChangedEvent,
-			fmt.Sprintf("It is not allowed to change the NodePoolLabel of node, but nodepool of node(%s) is changed from %s to %s", newNode.Name, oldNp, newNp))
+		if _, migrating := oldNode.Annotations[apps.AnnotationMigrationStatus]; !migrating {
+			klog.Warningf("It is not allowed to change the NodePoolLabel of node, but pool of node(%s) is changed from %s to %s", newNode.Name, oldNp, newNp)
+			// emit a warning event
+			e.Recorder.Event(newNode.DeepCopy(), corev1.EventTypeWarning, apps.NodePoolChangedEvent,
+				fmt.Sprintf("It is not allowed to change the NodePoolLabel of node, but nodepool of node(%s) is changed from %s to %s", newNode.Name, oldNp, newNp))
+		}
+		// both pools are enqueued for updating the membership of pools
+		addNodePoolToWorkQueue(oldNp, q)
+		addNodePoolToWorkQueue(newNp, q)
 		return
 	}
 
@@ -96,6 +106,13 @@ func (e *EnqueueNodePoolForNode) Update(evt event.UpdateEvent,
 		return
 	}
 
+	// check public ip of node, it's used for the tunnel endpoints of gateway
+	if newNode.Labels[apps.NodePublicIPLabel] != oldNode.Labels[apps.NodePublicIPLabel] {
+		klog.V(4).Infof(Format("Node public ip has been changed, will enqueue pool(%s) for node(%s)", newNp, newNode.GetName()))
+		addNodePoolToWorkQueue(newNp, q)
+		return
+	}
+
 	// check node's labels, annotations or taints are updated or not
 	if e.EnableSyncNodePoolConfigurations {
 		if !reflect.DeepEqual(newNode.Labels, oldNode.Labels) ||
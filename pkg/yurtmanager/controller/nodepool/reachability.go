@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+const (
+	ReachabilitySameGateway          = "SameGateway"
+	ReachabilityTunnelEstablished    = "TunnelEstablished"
+	ReachabilityNoGateway            = "NoGateway"
+	ReachabilityPeerNoGateway        = "PeerNoGateway"
+	ReachabilityGatewayUnhealthy     = "GatewayUnhealthy"
+	ReachabilityPeerGatewayUnhealthy = "PeerGatewayUnhealthy"
+	ReachabilityBothUnderNAT         = "BothUnderNAT"
+)
+
+// gatewayState is the tunnel health of a raven gateway.
+type gatewayState struct {
+	// healthy means the gateway has an active tunnel endpoint on a ready node.
+	healthy bool
+	// public means one of the healthy tunnel endpoints is not under NAT,
+	// so tunnels can be established from gateways under NAT.
+	public bool
+}
+
+// conciliateReachability updates the reachability from the nodepool to the other pools in status.
+// pools are connected through the raven gateways which their nodes belong to, and two pools can
+// reach each other if they share a gateway, or a tunnel can be established between their gateways.
+func (r *ReconcileNodePool) conciliateReachability(ctx context.Context, nodes []corev1.Node, nodePool *appsv1beta1.NodePool) (bool, error) {
+	var gatewayList ravenv1beta1.GatewayList
+	if err := r.List(ctx, &gatewayList); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var reachability []appsv1beta1.NodePoolReachability
+	if len(gatewayList.Items) != 0 {
+		var poolList appsv1beta1.NodePoolList
+		if err := r.List(ctx, &poolList); err != nil {
+			return false, err
+		}
+
+		gatewayOfNode := make(map[string]string)
+		states := make(map[string]gatewayState, len(gatewayList.Items))
+		for i := range gatewayList.Items {
+			gw := &gatewayList.Items[i]
+			for _, n := range gw.Status.Nodes {
+				gatewayOfNode[n.NodeName] = gw.Name
+			}
+			state, err := r.gatewayState(ctx, gw)
+			if err != nil {
+				return false, err
+			}
+			states[gw.Name] = state
+		}
+
+		names := make([]string, 0, len(nodes))
+		for i := range nodes {
+			names = append(names, nodes[i].Name)
+		}
+		src := poolGateways(names, gatewayOfNode)
+		for i := range poolList.Items {
+			peer := &poolList.Items[i]
+			if peer.Name == nodePool.Name {
+				continue
+			}
+			reachable, reason := poolReachability(src, poolGateways(peer.Status.Nodes, gatewayOfNode), states)
+			reachability = append(reachability, appsv1beta1.NodePoolReachability{
+				NodePool:  peer.Name,
+				Reachable: reachable,
+				Reason:    reason,
+			})
+		}
+		sort.Slice(reachability, func(i, j int) bool {
+			return reachability[i].NodePool < reachability[j].NodePool
+		})
+	}
+
+	if reflect.DeepEqual(reachability, nodePool.Status.Reachability) {
+		return false, nil
+	}
+	nodePool.Status.Reachability = reachability
+	return true, nil
+}
+
+// gatewayState checks the active tunnel endpoints of gateway.
+func (r *ReconcileNodePool) gatewayState(ctx context.Context, gw *ravenv1beta1.Gateway) (gatewayState, error) {
+	var state gatewayState
+	for _, ep := range gw.Status.ActiveEndpoints {
+		if ep == nil || ep.Type != ravenv1beta1.Tunnel {
+			continue
+		}
+		var node corev1.Node
+		if err := r.Get(ctx, types.NamespacedName{Name: ep.NodeName}, &node); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return state, err
+			}
+			continue
+		}
+		if !isNodeReady(node) {
+			continue
+		}
+		state.healthy = true
+		if !ep.UnderNAT {
+			state.public = true
+		}
+	}
+	return state, nil
+}
+
+// poolGateways returns the set of gateways that the nodes belong to.
+func poolGateways(nodes []string, gatewayOfNode map[string]string) map[string]struct{} {
+	gateways := make(map[string]struct{})
+	for _, name := range nodes {
+		if gw, ok := gatewayOfNode[name]; ok {
+			gateways[gw] = struct{}{}
+		}
+	}
+	return gateways
+}
+
+// poolReachability checks whether the pool with dst gateways can be reached from the pool with src gateways.
+func poolReachability(src, dst map[string]struct{}, states map[string]gatewayState) (bool, string) {
+	if len(src) == 0 {
+		return false, ReachabilityNoGateway
+	}
+	if len(dst) == 0 {
+		return false, ReachabilityPeerNoGateway
+	}
+	for gw := range src {
+		if _, ok := dst[gw]; ok {
+			return true, ReachabilitySameGateway
+		}
+	}
+
+	var srcHealthy, dstHealthy, srcPublic, dstPublic bool
+	for gw := range src {
+		srcHealthy = srcHealthy || states[gw].healthy
+		srcPublic = srcPublic || states[gw].public
+	}
+	for gw := range dst {
+		dstHealthy = dstHealthy || states[gw].healthy
+		dstPublic = dstPublic || states[gw].public
+	}
+	switch {
+	case !srcHealthy:
+		return false, ReachabilityGatewayUnhealthy
+	case !dstHealthy:
+		return false, ReachabilityPeerGatewayUnhealthy
+	case !srcPublic && !dstPublic:
+		return false, ReachabilityBothUnderNAT
+	}
+	return true, ReachabilityTunnelEstablished
+}
+
+// enqueueAllNodePools enqueues all nodepools, because the reachability between pools is changed
+// when the gateways are changed.
+func (r *ReconcileNodePool) enqueueAllNodePools(obj client.Object) []reconcile.Request {
+	var poolList appsv1beta1.NodePoolList
+	if err := r.List(context.TODO(), &poolList); err != nil {
+		klog.Errorf(Format("could not list NodePools for gateway %s, %v", obj.GetName(), err))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(poolList.Items))
+	for i := range poolList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: poolList.Items[i].Name}})
+	}
+	return requests
+}
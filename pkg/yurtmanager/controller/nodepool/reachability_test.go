@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+func TestConciliateReachability(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	newNode := func(name string, ready bool) *corev1.Node {
+		status := corev1.ConditionTrue
+		if !ready {
+			status = corev1.ConditionFalse
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			},
+		}
+	}
+	newPool := func(name string, nodes ...string) *appsv1beta1.NodePool {
+		return &appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     appsv1beta1.NodePoolStatus{Nodes: nodes},
+		}
+	}
+	newGateway := func(name string, underNAT bool, nodes ...string) *ravenv1beta1.Gateway {
+		gw := &ravenv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: ravenv1beta1.GatewayStatus{
+				ActiveEndpoints: []*ravenv1beta1.Endpoint{{NodeName: nodes[0], Type: ravenv1beta1.Tunnel, UnderNAT: underNAT}},
+			},
+		}
+		for _, n := range nodes {
+			gw.Status.Nodes = append(gw.Status.Nodes, ravenv1beta1.NodeInfo{NodeName: n})
+		}
+		return gw
+	}
+
+	testcases := map[string]struct {
+		objs   []client.Object
+		wanted []appsv1beta1.NodePoolReachability
+	}{
+		"no gateway in cluster": {
+			objs: []client.Object{newPool("beijing", "node2")},
+		},
+		"pools share a gateway": {
+			objs: []client.Object{
+				newPool("beijing", "node2"),
+				newGateway("gw-hangzhou", true, "node1", "node2"),
+			},
+			wanted: []appsv1beta1.NodePoolReachability{
+				{NodePool: "beijing", Reachable: true, Reason: ReachabilitySameGateway},
+			},
+		},
+		"tunnel between gateways": {
+			objs: []client.Object{
+				newPool("beijing", "node2"),
+				newPool("shanghai", "node3"),
+				newGateway("gw-hangzhou", true, "node1"),
+				newGateway("gw-beijing", false, "node2"),
+			},
+			wanted: []appsv1beta1.NodePoolReachability{
+				{NodePool: "beijing", Reachable: true, Reason: ReachabilityTunnelEstablished},
+				{NodePool: "shanghai", Reachable: false, Reason: ReachabilityPeerNoGateway},
+			},
+		},
+		"gateways are both under nat": {
+			objs: []client.Object{
+				newPool("beijing", "node2"),
+				newGateway("gw-hangzhou", true, "node1"),
+				newGateway("gw-beijing", true, "node2"),
+			},
+			wanted: []appsv1beta1.NodePoolReachability{
+				{NodePool: "beijing", Reachable: false, Reason: ReachabilityBothUnderNAT},
+			},
+		},
+		"gateway endpoint is not ready": {
+			objs: []client.Object{
+				newPool("beijing", "node4"),
+				newGateway("gw-hangzhou", false, "node1"),
+				newGateway("gw-beijing", false, "node4"),
+			},
+			wanted: []appsv1beta1.NodePoolReachability{
+				{NodePool: "beijing", Reachable: false, Reason: ReachabilityPeerGatewayUnhealthy},
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			pool := newPool("hangzhou", "node1")
+			objs := append([]client.Object{pool, newNode("node1", true), newNode("node2", true), newNode("node3", true), newNode("node4", false)}, tc.objs...)
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := &ReconcileNodePool{Client: c}
+
+			_, err := r.conciliateReachability(context.TODO(), []corev1.Node{*newNode("node1", true)}, pool)
+			if err != nil {
+				t.Fatalf("could not conciliate reachability, %v", err)
+			}
+			if !reflect.DeepEqual(pool.Status.Reachability, tc.wanted) {
+				t.Errorf("expect reachability %v, but got %v", tc.wanted, pool.Status.Reachability)
+			}
+		})
+	}
+}
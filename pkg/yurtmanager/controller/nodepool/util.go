@@ -20,11 +20,18 @@ import (
 	"encoding/json"
 	"reflect"
 	"sort"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
 	nodeutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/node"
 )
 
@@ -33,7 +40,7 @@ import (
 func conciliateNode(node *corev1.Node, nodePool *appsv1beta1.NodePool) (bool, error) {
 	// update node attr
 	newNpra := &NodePoolRelatedAttributes{
-		Labels:      nodePool.Spec.Labels,
+		Labels:      poolLabels(nodePool),
 		Annotations: nodePool.Spec.Annotations,
 		Taints:      nodePool.Spec.Taints,
 	}
@@ -43,67 +50,181 @@ func conciliateNode(node *corev1.Node, nodePool *appsv1beta1.NodePool) (bool, er
 		return false, err
 	}
 
-	if !areNodePoolRelatedAttributesEqual(oldNpra, newNpra) {
-		//klog.Infof("oldNpra: %#+v, \n newNpra: %#+v", oldNpra, newNpra)
-		conciliateLabels(node, oldNpra.Labels, newNpra.Labels)
-		conciliateAnnotations(node, oldNpra.Annotations, newNpra.Annotations)
-		conciliateTaints(node, oldNpra.Taints, newNpra.Taints)
+	// the attributes are conciliated only when the attributes of nodepool are changed under the default
+	// policy, so the attributes modified on the node directly are kept until the nodepool is changed. the
+	// explicitly specified policy is applied on every conciliation, so the modified attributes are conciliated too.
+	original := node.DeepCopy()
+	policy := nodePool.Spec.ConflictPolicy
+	changed := !areNodePoolRelatedAttributesEqual(oldNpra, newNpra)
+	if changed || len(policy.Labels) != 0 {
+		conciliateLabels(node, oldNpra.Labels, newNpra.Labels, policy.Labels)
+	}
+	if changed || len(policy.Annotations) != 0 {
+		conciliateAnnotations(node, oldNpra.Annotations, newNpra.Annotations, policy.Annotations)
+	}
+	if changed || len(policy.Taints) != 0 {
+		conciliateTaints(node, oldNpra.Taints, newNpra.Taints, policy.Taints)
+	}
+
+	if changed {
 		if err := encodePoolAttrs(node, newNpra); err != nil {
 			return false, err
 		}
 		return true, nil
 	}
 
-	return false, nil
+	return !apiequality.Semantic.DeepEqual(original.Labels, node.Labels) ||
+		!apiequality.Semantic.DeepEqual(original.Annotations, node.Annotations) ||
+		!apiequality.Semantic.DeepEqual(original.Spec.Taints, node.Spec.Taints), nil
 }
 
-// conciliateLabels will update the node's label that related to the nodepool
-func conciliateLabels(node *corev1.Node, oldLabels, newLabels map[string]string) {
-	// 1. remove labels from the node if they have been removed from the
-	// node pool
-	for oldK := range oldLabels {
-		if _, exist := newLabels[oldK]; !exist {
-			// label has been removed from the nodepool
-			delete(node.Labels, oldK)
+// poolLabels returns the labels that should be added to nodes of the nodepool,
+// including the raven gateway label and topology labels if they are specified.
+func poolLabels(nodePool *appsv1beta1.NodePool) map[string]string {
+	topology := nodePool.Spec.Topology
+	if len(nodePool.Spec.Gateway) == 0 && topology == nil {
+		return nodePool.Spec.Labels
+	}
+
+	labels := make(map[string]string, len(nodePool.Spec.Labels)+4)
+	for k, v := range nodePool.Spec.Labels {
+		labels[k] = v
+	}
+	if len(nodePool.Spec.Gateway) != 0 {
+		labels[raven.LabelCurrentGateway] = nodePool.Spec.Gateway
+	}
+	if topology != nil {
+		if len(topology.Region) != 0 {
+			labels[corev1.LabelTopologyRegion] = topology.Region
+		}
+		if len(topology.Zone) != 0 {
+			labels[corev1.LabelTopologyZone] = topology.Zone
+		}
+		if len(topology.Site) != 0 {
+			labels[apps.LabelTopologySite] = topology.Site
 		}
 	}
+	return labels
+}
 
-	// 2. update the node labels based on the latest node pool labels
-	node.Labels = mergeMap(node.Labels, newLabels)
+// conciliateLabels will update the node's label that related to the nodepool
+func conciliateLabels(node *corev1.Node, oldLabels, newLabels map[string]string, policy appsv1beta1.ConflictPolicy) {
+	node.Labels = conciliateMap(node.Labels, oldLabels, newLabels, policy)
 }
 
 // conciliateLabels will update the node's annotation that related to the nodepool
-func conciliateAnnotations(node *corev1.Node, oldAnnos, newAnnos map[string]string) {
-	// 1. remove annotations from the node if they have been removed from the
+func conciliateAnnotations(node *corev1.Node, oldAnnos, newAnnos map[string]string, policy appsv1beta1.ConflictPolicy) {
+	node.Annotations = conciliateMap(node.Annotations, oldAnnos, newAnnos, policy)
+}
+
+// conciliateMap updates the node's attributes(labels or annotations) based on the
+// previous and the latest attributes of nodepool, and the conflict policy is used
+// for resolving the conflict with the attributes modified on the node directly.
+func conciliateMap(nodeAttrs, oldAttrs, newAttrs map[string]string, policy appsv1beta1.ConflictPolicy) map[string]string {
+	// 1. remove attributes from the node if they have been removed from the
 	// node pool
-	for oldK := range oldAnnos {
-		if _, exist := newAnnos[oldK]; !exist {
-			delete(node.Annotations, oldK)
+	for oldK, oldV := range oldAttrs {
+		if _, exist := newAttrs[oldK]; exist {
+			continue
+		}
+		// attributes modified on the node directly are kept unless pool wins
+		if v, exist := nodeAttrs[oldK]; exist && (isPoolWins(policy) || v == oldV) {
+			delete(nodeAttrs, oldK)
 		}
 	}
 
-	// 2. update the node annotations based on the latest node pool labels
-	node.Annotations = mergeMap(node.Annotations, newAnnos)
+	// 2. update the node attributes based on the latest node pool attributes
+	if isPoolWins(policy) {
+		return mergeMap(nodeAttrs, newAttrs)
+	}
+
+	if nodeAttrs == nil {
+		nodeAttrs = make(map[string]string)
+	}
+	for k, v := range newAttrs {
+		nodeV, exist := nodeAttrs[k]
+		oldV, applied := oldAttrs[k]
+		switch policy {
+		case appsv1beta1.NodeWins:
+			// only the attribute that is not modified on the node can be updated
+			if exist && !(applied && nodeV == oldV) {
+				continue
+			}
+		case appsv1beta1.Merge:
+			// only the attribute that is changed in the nodepool will be synced
+			if applied && oldV == v {
+				continue
+			}
+		}
+		nodeAttrs[k] = v
+	}
+	return nodeAttrs
 }
 
 // conciliateLabels will update the node's taint that related to the nodepool
-func conciliateTaints(node *corev1.Node, oldTaints, newTaints []corev1.Taint) {
+func conciliateTaints(node *corev1.Node, oldTaints, newTaints []corev1.Taint, policy appsv1beta1.ConflictPolicy) {
+	if isPoolWins(policy) {
+		// 1. remove taints that have been removed from the node pool
+		for _, oldTaint := range oldTaints {
+			if _, exist := containTaint(oldTaint, newTaints); exist {
+				continue
+			}
+			if _, exist := containTaint(oldTaint, node.Spec.Taints); exist {
+				node.Spec.Taints = removeTaint(oldTaint, node.Spec.Taints)
+			}
+		}
+
+		// 2. add or overwrite node taints based on the latest node pool taints
+		for _, nt := range newTaints {
+			if i, exist := containTaint(nt, node.Spec.Taints); exist {
+				node.Spec.Taints[i] = nt
+			} else {
+				node.Spec.Taints = append(node.Spec.Taints, nt)
+			}
+		}
+		return
+	}
 
-	// 1. remove old taints from the node
+	// 1. remove taints that have been removed from the node pool, except
+	// the taints modified on the node directly
 	for _, oldTaint := range oldTaints {
-		if _, exist := containTaint(oldTaint, node.Spec.Taints); exist {
+		if _, exist := containTaint(oldTaint, newTaints); exist {
+			continue
+		}
+		if i, exist := containTaint(oldTaint, node.Spec.Taints); exist && node.Spec.Taints[i].Value == oldTaint.Value {
 			node.Spec.Taints = removeTaint(oldTaint, node.Spec.Taints)
 		}
 	}
 
-	// 2. add new node taints based on the latest node pool taints
+	// 2. add or update node taints based on the conflict policy
 	for _, nt := range newTaints {
-		if _, exist := containTaint(nt, node.Spec.Taints); !exist {
+		i, exist := containTaint(nt, node.Spec.Taints)
+		j, applied := containTaint(nt, oldTaints)
+		switch policy {
+		case appsv1beta1.NodeWins:
+			if exist && !(applied && node.Spec.Taints[i].Value == oldTaints[j].Value) {
+				continue
+			}
+		case appsv1beta1.Merge:
+			if applied && oldTaints[j].Value == nt.Value {
+				continue
+			}
+		}
+
+		if exist {
+			node.Spec.Taints[i] = nt
+		} else {
 			node.Spec.Taints = append(node.Spec.Taints, nt)
 		}
 	}
 }
 
+// isPoolWins checks if the attributes of nodepool should overwrite the ones on
+// the node, pool-wins is the default policy.
+func isPoolWins(policy appsv1beta1.ConflictPolicy) bool {
+	return len(policy) == 0 || policy == appsv1beta1.PoolWins
+}
+
 // conciliateNodePoolStatus will update the nodepool status if necessary
 func conciliateNodePoolStatus(
 	readyNode,
@@ -132,6 +253,219 @@ func conciliateNodePoolStatus(
 	return needUpdate
 }
 
+// conciliateNodePoolResources will update the breakdown of node counts and
+// the aggregated resources of nodepool status if necessary
+func conciliateNodePoolResources(nodes []corev1.Node, nodePool *appsv1beta1.NodePool) (needUpdate bool) {
+	var counts appsv1beta1.NodePoolNodeCounts
+	capacity := corev1.ResourceList{}
+	allocatable := corev1.ResourceList{}
+	for i := range nodes {
+		_, nc := nodeutil.GetNodeCondition(&nodes[i].Status, corev1.NodeReady)
+		switch {
+		case nc != nil && nc.Status == corev1.ConditionTrue:
+			counts.Ready++
+		case nc != nil && nc.Status == corev1.ConditionFalse:
+			counts.NotReady++
+		default:
+			counts.Unknown++
+		}
+		if nodes[i].Spec.Unschedulable {
+			counts.Cordoned++
+		}
+
+		addResourceList(capacity, nodes[i].Status.Capacity)
+		addResourceList(allocatable, nodes[i].Status.Allocatable)
+	}
+
+	if counts != nodePool.Status.NodeCounts {
+		nodePool.Status.NodeCounts = counts
+		needUpdate = true
+	}
+
+	if !areResourceListsEqual(capacity, nodePool.Status.Capacity) {
+		nodePool.Status.Capacity = capacity
+		needUpdate = true
+	}
+
+	if !areResourceListsEqual(allocatable, nodePool.Status.Allocatable) {
+		nodePool.Status.Allocatable = allocatable
+		needUpdate = true
+	}
+
+	return needUpdate
+}
+
+const (
+	// gpuProductLabel is the label added by gpu feature discovery, the value is the model of gpu.
+	gpuProductLabel = "nvidia.com/gpu.product"
+	// unknownGPUModel is used for nodes with gpus but without the gpu product label.
+	unknownGPUModel = "unknown"
+)
+
+// conciliateGPUInventory will update the gpu inventory of nodepool status if necessary
+func conciliateGPUInventory(nodes []corev1.Node, nodePool *appsv1beta1.NodePool) (needUpdate bool) {
+	inventory := &appsv1beta1.GPUInventory{
+		Capacity:    corev1.ResourceList{},
+		Allocatable: corev1.ResourceList{},
+	}
+	models := make(map[string]*appsv1beta1.GPUModelInventory)
+	for i := range nodes {
+		var count int64
+		for name, quantity := range nodes[i].Status.Capacity {
+			if !isGPUResource(name) {
+				continue
+			}
+			addQuantity(inventory.Capacity, name, quantity)
+			if strings.HasSuffix(string(name), "/gpu") {
+				count += quantity.Value()
+			}
+		}
+		for name, quantity := range nodes[i].Status.Allocatable {
+			if isGPUResource(name) {
+				addQuantity(inventory.Allocatable, name, quantity)
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		model := nodes[i].Labels[gpuProductLabel]
+		if len(model) == 0 {
+			model = unknownGPUModel
+		}
+		if _, ok := models[model]; !ok {
+			models[model] = &appsv1beta1.GPUModelInventory{Model: model}
+		}
+		models[model].Nodes++
+		models[model].Count += count
+	}
+
+	for _, m := range models {
+		inventory.Models = append(inventory.Models, *m)
+	}
+	sort.Slice(inventory.Models, func(i, j int) bool {
+		return inventory.Models[i].Model < inventory.Models[j].Model
+	})
+
+	if len(inventory.Capacity) == 0 && len(inventory.Allocatable) == 0 {
+		inventory = nil
+	}
+	if isGPUInventoryEqual(inventory, nodePool.Status.GPUInventory) {
+		return false
+	}
+	nodePool.Status.GPUInventory = inventory
+	return true
+}
+
+// isGPUResource checks if the resource is gpu, including the whole gpus(like nvidia.com/gpu)
+// and MIG devices(like nvidia.com/mig-1g.5gb).
+func isGPUResource(name corev1.ResourceName) bool {
+	return strings.HasSuffix(string(name), "/gpu") || strings.Contains(string(name), "/mig-")
+}
+
+func addQuantity(total corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	if q, ok := total[name]; ok {
+		q.Add(quantity)
+		total[name] = q
+	} else {
+		total[name] = quantity.DeepCopy()
+	}
+}
+
+func isGPUInventoryEqual(a, b *appsv1beta1.GPUInventory) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return areResourceListsEqual(a.Capacity, b.Capacity) &&
+		areResourceListsEqual(a.Allocatable, b.Allocatable) &&
+		reflect.DeepEqual(a.Models, b.Models)
+}
+
+// maxHubLeaderHistory is the max number of elections recorded in nodepool status
+const maxHubLeaderHistory = 10
+
+// conciliateHubLeader will update the hub leader and the election history of nodepool status
+// if necessary, the leader is the ready node with the latest hub leader annotation, and how the
+// leadership is handed over to it is recorded in the history too.
+func conciliateHubLeader(nodes []corev1.Node, nodePool *appsv1beta1.NodePool) (needUpdate bool) {
+	var (
+		leader      string
+		leaderNode  *corev1.Node
+		electedTime time.Time
+	)
+	for i := range nodes {
+		since, ok := nodes[i].Annotations[apps.AnnotationHubLeaderSince]
+		if !ok || !isNodeReady(nodes[i]) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			klog.Warningf(Format("invalid hub leader annotation %q of node %s, %v", since, nodes[i].Name, err))
+			continue
+		}
+		if len(leader) == 0 || t.After(electedTime) {
+			leader = nodes[i].Name
+			leaderNode = &nodes[i]
+			electedTime = t
+		}
+	}
+
+	if leader != nodePool.Status.HubLeader {
+		nodePool.Status.HubLeader = leader
+		needUpdate = true
+	}
+	if len(leader) == 0 {
+		return needUpdate
+	}
+
+	history := nodePool.Status.HubLeaderHistory
+	if len(history) != 0 && history[0].NodeName == leader && history[0].ElectedTime.Time.Equal(electedTime) {
+		return needUpdate
+	}
+	record := appsv1beta1.HubLeaderRecord{
+		NodeName:         leader,
+		ElectedTime:      metav1.NewTime(electedTime),
+		PreviousNodeName: leaderNode.Annotations[apps.AnnotationHubLeaderPrevious],
+		Reason:           appsv1beta1.HubLeaderHandoverReason(leaderNode.Annotations[apps.AnnotationHubLeaderHandover]),
+	}
+	history = append([]appsv1beta1.HubLeaderRecord{record}, history...)
+	if len(history) > maxHubLeaderHistory {
+		history = history[:maxHubLeaderHistory]
+	}
+	nodePool.Status.HubLeaderHistory = history
+	return true
+}
+
+// isAggregatedResource checks if the resource should be aggregated into nodepool
+// status, only cpu, memory and gpu(like nvidia.com/gpu) are aggregated.
+func isAggregatedResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU || name == corev1.ResourceMemory || strings.HasSuffix(string(name), "/gpu")
+}
+
+// addResourceList adds the aggregated resources in `delta` into `total`
+func addResourceList(total, delta corev1.ResourceList) {
+	for name, quantity := range delta {
+		if !isAggregatedResource(name) {
+			continue
+		}
+		addQuantity(total, name, quantity)
+	}
+}
+
+// areResourceListsEqual checks if the quantities of all resources in a and b are equal
+func areResourceListsEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, qa := range a {
+		qb, ok := b[name]
+		if !ok || qa.Cmp(qb) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // containTaint checks if `taint` is in `taints`, if yes it will return
 // the index of the taint and true, otherwise, it will return 0 and false.
 // N.B. the uniqueness of the taint is based on both key and effect pair
@@ -20,12 +20,15 @@ import (
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
 )
 
 func TestConcilateNode(t *testing.T) {
@@ -352,6 +355,73 @@ func TestConcilateNode(t *testing.T) {
 			},
 			updated: true,
 		},
+		"pool attributes modified on the node directly with pool-wins policy": {
+			mockNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"label1":     "value1",
+						"poollabel1": "modified",
+					},
+				},
+			},
+			initNpra: &NodePoolRelatedAttributes{
+				Labels: map[string]string{
+					"poollabel1": "value1",
+				},
+			},
+			pool: appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Labels: map[string]string{
+						"poollabel1": "value1",
+					},
+					ConflictPolicy: appsv1beta1.NodePoolConflictPolicy{
+						Labels: appsv1beta1.PoolWins,
+					},
+				},
+			},
+			wantedNodeExcludeAttribute: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"label1":     "value1",
+						"poollabel1": "value1",
+					},
+					Annotations: map[string]string{},
+				},
+			},
+			updated: true,
+		},
+		"pool attributes modified on the node directly with default policy": {
+			mockNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"label1":     "value1",
+						"poollabel1": "modified",
+					},
+				},
+			},
+			initNpra: &NodePoolRelatedAttributes{
+				Labels: map[string]string{
+					"poollabel1": "value1",
+				},
+			},
+			pool: appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Labels: map[string]string{
+						"poollabel1": "value1",
+					},
+				},
+			},
+			wantedNodeExcludeAttribute: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"label1":     "value1",
+						"poollabel1": "modified",
+					},
+					Annotations: map[string]string{},
+				},
+			},
+			updated: false,
+		},
 		"node and pool has no pool attributes": {
 			mockNode: corev1.Node{
 				ObjectMeta: metav1.ObjectMeta{
@@ -440,6 +510,58 @@ func TestConcilateNode(t *testing.T) {
 	}
 }
 
+func TestPoolLabels(t *testing.T) {
+	testcases := map[string]struct {
+		pool *appsv1beta1.NodePool
+		want map[string]string
+	}{
+		"pool without gateway": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Labels: map[string]string{"label1": "value1"},
+				},
+			},
+			want: map[string]string{"label1": "value1"},
+		},
+		"pool with gateway": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Labels:  map[string]string{"label1": "value1"},
+					Gateway: "gw-hangzhou",
+				},
+			},
+			want: map[string]string{"label1": "value1", raven.LabelCurrentGateway: "gw-hangzhou"},
+		},
+		"pool with topology": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Labels: map[string]string{"label1": "value1"},
+					Topology: &appsv1beta1.NodePoolTopology{
+						Region: "cn-east",
+						Site:   "hangzhou-factory",
+					},
+				},
+			},
+			want: map[string]string{
+				"label1":                   "value1",
+				corev1.LabelTopologyRegion: "cn-east",
+				apps.LabelTopologySite:     "hangzhou-factory",
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := poolLabels(tc.pool); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+			if _, ok := tc.pool.Spec.Labels[raven.LabelCurrentGateway]; ok {
+				t.Errorf("Expected labels of nodepool spec are not changed")
+			}
+		})
+	}
+}
+
 func TestConciliateLabels(t *testing.T) {
 	mockNode := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
@@ -464,7 +586,7 @@ func TestConciliateLabels(t *testing.T) {
 		"label4": "value4",
 	}
 
-	conciliateLabels(mockNode, oldLabels, newLabels)
+	conciliateLabels(mockNode, oldLabels, newLabels, appsv1beta1.PoolWins)
 	if !reflect.DeepEqual(wantNodeLabels, mockNode.Labels) {
 		t.Errorf("Expected %v, got %v", wantNodeLabels, mockNode.Labels)
 	}
@@ -494,7 +616,7 @@ func TestConciliateAnnotations(t *testing.T) {
 		"anno4": "value4",
 	}
 
-	conciliateAnnotations(mockNode, oldAnnos, newAnnos)
+	conciliateAnnotations(mockNode, oldAnnos, newAnnos, appsv1beta1.PoolWins)
 	if !reflect.DeepEqual(wantNodeAnnos, mockNode.Annotations) {
 		t.Errorf("Expected %v, got %v", wantNodeAnnos, mockNode.Annotations)
 	}
@@ -545,13 +667,124 @@ func TestConciliateTaints(t *testing.T) {
 			Effect: corev1.TaintEffectPreferNoSchedule,
 		},
 	}
-	conciliateTaints(mockNode, oldTaints, newTaints)
+	conciliateTaints(mockNode, oldTaints, newTaints, appsv1beta1.PoolWins)
 
 	if !reflect.DeepEqual(wantNodeTaints, mockNode.Spec.Taints) {
 		t.Errorf("Expected %v, got %v", wantNodeTaints, mockNode.Spec.Taints)
 	}
 }
 
+func TestConciliateMapWithConflictPolicy(t *testing.T) {
+	testcases := map[string]struct {
+		policy    appsv1beta1.ConflictPolicy
+		nodeAttrs map[string]string
+		oldAttrs  map[string]string
+		newAttrs  map[string]string
+		want      map[string]string
+	}{
+		"default policy overwrites attributes modified on node": {
+			nodeAttrs: map[string]string{"k1": "node", "k2": "pool", "k3": "node"},
+			oldAttrs:  map[string]string{"k1": "pool", "k2": "pool", "k3": "pool"},
+			newAttrs:  map[string]string{"k1": "pool", "k2": "pool2"},
+			want:      map[string]string{"k1": "pool", "k2": "pool2"},
+		},
+		"pool-wins overwrites attributes modified on node": {
+			policy:    appsv1beta1.PoolWins,
+			nodeAttrs: map[string]string{"k1": "node", "k2": "pool"},
+			oldAttrs:  map[string]string{"k1": "pool", "k2": "pool"},
+			newAttrs:  map[string]string{"k1": "pool", "k2": "pool2"},
+			want:      map[string]string{"k1": "pool", "k2": "pool2"},
+		},
+		"node-wins keeps attributes modified on node": {
+			policy:    appsv1beta1.NodeWins,
+			nodeAttrs: map[string]string{"k1": "node", "k2": "pool", "k3": "node", "k4": "node"},
+			oldAttrs:  map[string]string{"k1": "pool", "k2": "pool", "k3": "pool"},
+			newAttrs:  map[string]string{"k1": "pool2", "k2": "pool2", "k4": "pool", "k5": "pool"},
+			want:      map[string]string{"k1": "node", "k2": "pool2", "k3": "node", "k4": "node", "k5": "pool"},
+		},
+		"merge only syncs attributes changed in nodepool": {
+			policy:    appsv1beta1.Merge,
+			nodeAttrs: map[string]string{"k1": "node", "k2": "node", "k3": "node", "k4": "pool"},
+			oldAttrs:  map[string]string{"k1": "pool", "k2": "pool", "k3": "pool", "k4": "pool"},
+			newAttrs:  map[string]string{"k1": "pool", "k2": "pool2", "k5": "pool"},
+			want:      map[string]string{"k1": "node", "k2": "pool2", "k3": "node", "k5": "pool"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got := conciliateMap(tc.nodeAttrs, tc.oldAttrs, tc.newAttrs, tc.policy)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConciliateTaintsWithConflictPolicy(t *testing.T) {
+	testcases := map[string]struct {
+		policy     appsv1beta1.ConflictPolicy
+		nodeTaints []corev1.Taint
+		oldTaints  []corev1.Taint
+		newTaints  []corev1.Taint
+		want       []corev1.Taint
+	}{
+		"node-wins keeps taints modified on node": {
+			policy: appsv1beta1.NodeWins,
+			nodeTaints: []corev1.Taint{
+				{Key: "key1", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+			},
+			oldTaints: []corev1.Taint{
+				{Key: "key1", Value: "pool", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "pool", Effect: corev1.TaintEffectNoSchedule},
+			},
+			newTaints: []corev1.Taint{
+				{Key: "key1", Value: "pool2", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key3", Value: "pool", Effect: corev1.TaintEffectNoExecute},
+			},
+			want: []corev1.Taint{
+				{Key: "key1", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key3", Value: "pool", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		"merge only syncs taints changed in nodepool": {
+			policy: appsv1beta1.Merge,
+			nodeTaints: []corev1.Taint{
+				{Key: "key1", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+			},
+			oldTaints: []corev1.Taint{
+				{Key: "key1", Value: "pool", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "pool", Effect: corev1.TaintEffectNoSchedule},
+			},
+			newTaints: []corev1.Taint{
+				{Key: "key1", Value: "pool", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "pool2", Effect: corev1.TaintEffectNoSchedule},
+			},
+			want: []corev1.Taint{
+				{Key: "key1", Value: "node", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "key2", Value: "pool2", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			node := &corev1.Node{
+				Spec: corev1.NodeSpec{
+					Taints: tc.nodeTaints,
+				},
+			}
+			conciliateTaints(node, tc.oldTaints, tc.newTaints, tc.policy)
+			if !reflect.DeepEqual(tc.want, node.Spec.Taints) {
+				t.Errorf("Expected %v, got %v", tc.want, node.Spec.Taints)
+			}
+		})
+	}
+}
+
 func TestConciliateNodePoolStatus(t *testing.T) {
 	testcases := map[string]struct {
 		readyNodes    int32
@@ -625,6 +858,326 @@ func TestConciliateNodePoolStatus(t *testing.T) {
 	}
 }
 
+func TestConciliateNodePoolResources(t *testing.T) {
+	nodes := []corev1.Node{
+		{
+			Spec: corev1.NodeSpec{
+				Unschedulable: true,
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:   corev1.NodeReady,
+						Status: corev1.ConditionTrue,
+					},
+				},
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+					"nvidia.com/gpu":      resource.MustParse("1"),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("3800m"),
+					corev1.ResourceMemory: resource.MustParse("7Gi"),
+					"nvidia.com/gpu":      resource.MustParse("1"),
+				},
+			},
+		},
+		{
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:   corev1.NodeReady,
+						Status: corev1.ConditionFalse,
+					},
+				},
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1800m"),
+					corev1.ResourceMemory: resource.MustParse("3Gi"),
+				},
+			},
+		},
+		{
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:   corev1.NodeReady,
+						Status: corev1.ConditionUnknown,
+					},
+				},
+			},
+		},
+		{},
+	}
+
+	wantedStatus := appsv1beta1.NodePoolStatus{
+		NodeCounts: appsv1beta1.NodePoolNodeCounts{
+			Ready:    1,
+			NotReady: 1,
+			Unknown:  2,
+			Cordoned: 1,
+		},
+		Capacity: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("6"),
+			corev1.ResourceMemory: resource.MustParse("12Gi"),
+			"nvidia.com/gpu":      resource.MustParse("1"),
+		},
+		Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("5600m"),
+			corev1.ResourceMemory: resource.MustParse("10Gi"),
+			"nvidia.com/gpu":      resource.MustParse("1"),
+		},
+	}
+
+	pool := &appsv1beta1.NodePool{}
+	if !conciliateNodePoolResources(nodes, pool) {
+		t.Errorf("Expected status is updated, but not")
+	}
+	if pool.Status.NodeCounts != wantedStatus.NodeCounts {
+		t.Errorf("Expected %v, got %v", wantedStatus.NodeCounts, pool.Status.NodeCounts)
+	}
+	if !areResourceListsEqual(pool.Status.Capacity, wantedStatus.Capacity) {
+		t.Errorf("Expected %v, got %v", wantedStatus.Capacity, pool.Status.Capacity)
+	}
+	if !areResourceListsEqual(pool.Status.Allocatable, wantedStatus.Allocatable) {
+		t.Errorf("Expected %v, got %v", wantedStatus.Allocatable, pool.Status.Allocatable)
+	}
+
+	if conciliateNodePoolResources(nodes, pool) {
+		t.Errorf("Expected status is not updated, but updated")
+	}
+}
+
+func TestConciliateGPUInventory(t *testing.T) {
+	gpuNode := func(model string, gpus, mig string) corev1.Node {
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{},
+			},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("8"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("8"),
+				},
+			},
+		}
+		if len(model) != 0 {
+			node.Labels[gpuProductLabel] = model
+		}
+		if len(gpus) != 0 {
+			node.Status.Capacity["nvidia.com/gpu"] = resource.MustParse(gpus)
+			node.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse(gpus)
+		}
+		if len(mig) != 0 {
+			node.Status.Capacity["nvidia.com/mig-1g.5gb"] = resource.MustParse(mig)
+			node.Status.Allocatable["nvidia.com/mig-1g.5gb"] = resource.MustParse(mig)
+		}
+		return node
+	}
+
+	testcases := map[string]struct {
+		nodes        []corev1.Node
+		inventory    *appsv1beta1.GPUInventory
+		needUpdate   bool
+		expectModels []appsv1beta1.GPUModelInventory
+		expectGPUs   int64
+		expectMIGs   int64
+	}{
+		"no gpu in the pool": {
+			nodes:      []corev1.Node{gpuNode("", "", "")},
+			needUpdate: false,
+		},
+		"aggregate gpus by model": {
+			nodes: []corev1.Node{
+				gpuNode("NVIDIA-A100", "8", "7"),
+				gpuNode("NVIDIA-A100", "4", ""),
+				gpuNode("NVIDIA-T4", "1", ""),
+				gpuNode("", "2", ""),
+				gpuNode("", "", ""),
+			},
+			needUpdate: true,
+			expectModels: []appsv1beta1.GPUModelInventory{
+				{Model: "NVIDIA-A100", Nodes: 2, Count: 12},
+				{Model: "NVIDIA-T4", Nodes: 1, Count: 1},
+				{Model: unknownGPUModel, Nodes: 1, Count: 2},
+			},
+			expectGPUs: 15,
+			expectMIGs: 7,
+		},
+		"gpus are removed from the pool": {
+			nodes: []corev1.Node{gpuNode("", "", "")},
+			inventory: &appsv1beta1.GPUInventory{
+				Capacity: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+			},
+			needUpdate: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			np := &appsv1beta1.NodePool{
+				Status: appsv1beta1.NodePoolStatus{GPUInventory: tc.inventory},
+			}
+			if needUpdate := conciliateGPUInventory(tc.nodes, np); needUpdate != tc.needUpdate {
+				t.Errorf("expect needUpdate %v, but got %v", tc.needUpdate, needUpdate)
+			}
+
+			inventory := np.Status.GPUInventory
+			if tc.expectModels == nil {
+				if inventory != nil {
+					t.Errorf("expect no gpu inventory, but got %#v", inventory)
+				}
+				return
+			}
+			if inventory == nil {
+				t.Fatalf("expect gpu inventory, but got nil")
+			}
+			if !reflect.DeepEqual(tc.expectModels, inventory.Models) {
+				t.Errorf("expect models %#v, but got %#v", tc.expectModels, inventory.Models)
+			}
+			gpus := inventory.Capacity["nvidia.com/gpu"]
+			if gpus.Value() != tc.expectGPUs {
+				t.Errorf("expect %d gpus, but got %d", tc.expectGPUs, gpus.Value())
+			}
+			migs := inventory.Allocatable["nvidia.com/mig-1g.5gb"]
+			if migs.Value() != tc.expectMIGs {
+				t.Errorf("expect %d MIG devices, but got %d", tc.expectMIGs, migs.Value())
+			}
+			if _, ok := inventory.Capacity[corev1.ResourceCPU]; ok {
+				t.Errorf("expect cpu is not in gpu inventory")
+			}
+		})
+	}
+}
+
+func TestConciliateHubLeader(t *testing.T) {
+	readyNode := func(name, since string) corev1.Node {
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:   corev1.NodeReady,
+						Status: corev1.ConditionTrue,
+					},
+				},
+			},
+		}
+		if len(since) != 0 {
+			node.Annotations[apps.AnnotationHubLeaderSince] = since
+		}
+		return node
+	}
+	t1, _ := time.Parse(time.RFC3339, "2023-08-01T10:00:00Z")
+	t2, _ := time.Parse(time.RFC3339, "2023-08-01T11:00:00Z")
+
+	testcases := map[string]struct {
+		nodes        []corev1.Node
+		status       appsv1beta1.NodePoolStatus
+		needUpdate   bool
+		expectStatus appsv1beta1.NodePoolStatus
+	}{
+		"no hub leader": {
+			nodes:        []corev1.Node{readyNode("foo", "")},
+			needUpdate:   false,
+			expectStatus: appsv1beta1.NodePoolStatus{},
+		},
+		"new hub leader is elected": {
+			nodes: []corev1.Node{readyNode("foo", "2023-08-01T10:00:00Z"), readyNode("bar", "2023-08-01T11:00:00Z")},
+			status: appsv1beta1.NodePoolStatus{
+				HubLeader: "foo",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+			needUpdate: true,
+			expectStatus: appsv1beta1.NodePoolStatus{
+				HubLeader: "bar",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "bar", ElectedTime: metav1.NewTime(t2)},
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+		},
+		"hub leader is handed over": {
+			nodes: func() []corev1.Node {
+				node := readyNode("bar", "2023-08-01T11:00:00Z")
+				node.Annotations[apps.AnnotationHubLeaderPrevious] = "foo"
+				node.Annotations[apps.AnnotationHubLeaderHandover] = string(appsv1beta1.HubLeaderHandoverReleased)
+				return []corev1.Node{readyNode("foo", ""), node}
+			}(),
+			status: appsv1beta1.NodePoolStatus{
+				HubLeader: "foo",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+			needUpdate: true,
+			expectStatus: appsv1beta1.NodePoolStatus{
+				HubLeader: "bar",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "bar", ElectedTime: metav1.NewTime(t2), PreviousNodeName: "foo", Reason: appsv1beta1.HubLeaderHandoverReleased},
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+		},
+		"hub leader is not changed": {
+			nodes: []corev1.Node{readyNode("foo", "2023-08-01T10:00:00Z")},
+			status: appsv1beta1.NodePoolStatus{
+				HubLeader: "foo",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+			needUpdate: false,
+			expectStatus: appsv1beta1.NodePoolStatus{
+				HubLeader: "foo",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+		},
+		"hub leader is gone": {
+			nodes: []corev1.Node{readyNode("bar", "")},
+			status: appsv1beta1.NodePoolStatus{
+				HubLeader: "foo",
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+			needUpdate: true,
+			expectStatus: appsv1beta1.NodePoolStatus{
+				HubLeaderHistory: []appsv1beta1.HubLeaderRecord{
+					{NodeName: "foo", ElectedTime: metav1.NewTime(t1)},
+				},
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			np := &appsv1beta1.NodePool{Status: tc.status}
+			needUpdate := conciliateHubLeader(tc.nodes, np)
+			if needUpdate != tc.needUpdate {
+				t.Errorf("expect needUpdate %v, but got %v", tc.needUpdate, needUpdate)
+			}
+			if !reflect.DeepEqual(tc.expectStatus, np.Status) {
+				t.Errorf("expect status %#v, but got %#v", tc.expectStatus, np.Status)
+			}
+		})
+	}
+}
+
 func TestContainTaint(t *testing.T) {
 	mockTaints := []corev1.Taint{
 		{
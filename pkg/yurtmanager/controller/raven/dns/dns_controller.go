@@ -29,11 +29,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -41,6 +43,8 @@ import (
 
 	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
 	common "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
 )
@@ -83,18 +87,22 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// Watch for changes to service
-	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &EnqueueRequestForServiceEvent{}, predicate.NewPredicateFuncs(
-		func(obj client.Object) bool {
-			svc, ok := obj.(*corev1.Service)
-			if !ok {
-				return false
-			}
-			if svc.Spec.Type != corev1.ServiceTypeClusterIP {
-				return false
-			}
-			return svc.Namespace == utils.WorkingNamespace && svc.Name == utils.GatewayProxyInternalService
-		}))
+	// Watch for changes to service, the old service is also checked when updated,
+	// so the dns records are removed after the split horizon annotation is removed.
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &EnqueueRequestForServiceEvent{}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isDNSService(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isDNSService(e.ObjectOld) || isDNSService(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isDNSService(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isDNSService(e.Object)
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -104,6 +112,26 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to endpoints of headless services, the identity records of pods behind
+	// the headless services with topology are recorded.
+	err = c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, &EnqueueRequestForEndpointsEvent{}, predicate.NewPredicateFuncs(
+		func(object client.Object) bool {
+			_, ok := object.GetLabels()[corev1.IsHeadlessService]
+			return ok
+		}))
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to raven-cfg, which configures the proxy and ttl of dns records
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &EnqueueRequestForRavenConfigEvent{}, predicate.NewPredicateFuncs(
+		func(object client.Object) bool {
+			return object.GetNamespace() == utils.WorkingNamespace && object.GetName() == utils.RavenGlobalConfig
+		}))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -149,7 +177,34 @@ func (r *ReconcileDns) Reconcile(ctx context.Context, req reconcile.Request) (re
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: 2 * time.Second}, fmt.Errorf("failed to list node, error %s", err.Error())
 	}
-	cm.Data[utils.ProxyNodesKey] = buildDNSRecords(nodeList, enableProxy, proxyAddress)
+	records := buildDNSRecords(nodeList, enableProxy, proxyAddress)
+
+	//4. update split horizon dns record of services
+	svcList := new(corev1.ServiceList)
+	err = r.Client.List(ctx, svcList, &client.ListOptions{})
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: 2 * time.Second}, fmt.Errorf("failed to list service, error %s", err.Error())
+	}
+	cm.Data[utils.CloudServicesKey], cm.Data[utils.EdgeServicesKey] = buildServiceDNSRecords(svcList, enableProxy, proxyAddress)
+
+	//5. update identity dns records of pods behind headless services with topology, they are served
+	// by the same hosts plugin as the records of nodes.
+	endpointsList := new(corev1.EndpointsList)
+	err = r.Client.List(ctx, endpointsList, client.HasLabels{corev1.IsHeadlessService})
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: 2 * time.Second}, fmt.Errorf("failed to list endpoints, error %s", err.Error())
+	}
+	if identities := buildIdentityDNSRecords(svcList, endpointsList); len(identities) != 0 {
+		if len(records) != 0 {
+			records += "\n"
+		}
+		records += identities
+	}
+	cm.Data[utils.ProxyNodesKey] = records
+
+	//6. update the ttl of dns records and negative responses
+	recordTTL, negativeTTL := utils.GetDNSTTL(ctx, r.Client)
+	cm.Data[utils.DNSServerConfigKey] = buildDNSServerConfig(recordTTL, negativeTTL)
 	err = r.updateDNS(cm)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: 2 * time.Second}, fmt.Errorf("failed to update configmap %s/%s, error %s",
@@ -188,7 +243,10 @@ func (r *ReconcileDns) buildRavenDNSConfigMap() error {
 			Namespace: utils.WorkingNamespace,
 		},
 		Data: map[string]string{
-			utils.ProxyNodesKey: "",
+			utils.ProxyNodesKey:      "",
+			utils.CloudServicesKey:   "",
+			utils.EdgeServicesKey:    "",
+			utils.DNSServerConfigKey: buildDNSServerConfig(utils.DefaultDNSRecordTTL, utils.DefaultDNSNegativeTTL),
 		},
 	}
 	err := r.Client.Create(context.TODO(), cm, &client.CreateOptions{})
@@ -238,6 +296,103 @@ func buildDNSRecords(nodeList *corev1.NodeList, needProxy bool, proxyIp string)
 	return strings.Join(dns, "\n")
 }
 
+// buildServiceDNSRecords builds the split horizon dns records of services for cloud and edge. The cloud
+// clients access the service in edge through raven proxy, so the service name is resolved to the proxy
+// address, and the edge clients access the service by its cluster ip, the traffic is served by the
+// endpoints in the same pool. No cloud record is built if the proxy is unavailable, so the service is
+// resolved by cluster dns as usual.
+func buildServiceDNSRecords(svcList *corev1.ServiceList, needProxy bool, proxyIp string) (string, string) {
+	cloud := make([]string, 0)
+	edge := make([]string, 0)
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		if !isSplitHorizonService(svc) || svc.DeletionTimestamp != nil {
+			continue
+		}
+		domain := fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, utils.ClusterDomain)
+		edge = append(edge, fmt.Sprintf("%s\t%s", svc.Spec.ClusterIP, domain))
+		if needProxy && proxyIp != "" {
+			cloud = append(cloud, fmt.Sprintf("%s\t%s", proxyIp, domain))
+		}
+	}
+	sort.Strings(cloud)
+	sort.Strings(edge)
+	return strings.Join(cloud, "\n"), strings.Join(edge, "\n")
+}
+
+// buildIdentityDNSRecords builds the identity dns records(<hostname>.<service>.<namespace>.svc) of the pods
+// behind the headless services with topology, like the pods of statefulset. the endpoints of these services
+// are filtered by topology for the coredns on edge nodes, so the service is resolved to the pods under the
+// topology, and the identity records of the pods outside the topology are resolved by these records.
+func buildIdentityDNSRecords(svcList *corev1.ServiceList, endpointsList *corev1.EndpointsList) string {
+	services := sets.NewString()
+	for i := range svcList.Items {
+		if isTopologyHeadlessService(&svcList.Items[i]) {
+			services.Insert(svcList.Items[i].Namespace + "/" + svcList.Items[i].Name)
+		}
+	}
+
+	dns := make([]string, 0)
+	for i := range endpointsList.Items {
+		ep := &endpointsList.Items[i]
+		if !services.Has(ep.Namespace + "/" + ep.Name) {
+			continue
+		}
+		for _, subset := range ep.Subsets {
+			for _, addr := range subset.Addresses {
+				if len(addr.Hostname) == 0 {
+					continue
+				}
+				dns = append(dns, fmt.Sprintf("%s\t%s.%s.%s.svc.%s", addr.IP, addr.Hostname, ep.Name, ep.Namespace, utils.ClusterDomain))
+			}
+		}
+	}
+	sort.Strings(dns)
+	return strings.Join(dns, "\n")
+}
+
+// buildDNSServerConfig renders the coredns configuration for the records in edge-tunnel-nodes, which is mounted
+// at /etc/edge and imported into the server block by `import /etc/edge/tunnel-nodes.server` instead of a static
+// hosts plugin, so the records are served with the ttl from raven-cfg. it also replaces the cache plugin of the
+// server block, because the not found responses of node names are cached for the negative ttl by coredns.
+func buildDNSServerConfig(recordTTL, negativeTTL int) string {
+	return fmt.Sprintf(`hosts /etc/edge/%s {
+    ttl %d
+    reload 1s
+    fallthrough
+}
+cache {
+    success 9984 %d
+    denial 9984 %d
+}
+`, utils.ProxyNodesKey, recordTTL, recordTTL, negativeTTL)
+}
+
+// isDNSService checks whether the service is recorded in dns, that is the internal service of raven
+// proxy, the service annotated with split horizon dns or the headless service with topology.
+func isDNSService(obj client.Object) bool {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		return false
+	}
+	if svc.Namespace == utils.WorkingNamespace && svc.Name == utils.GatewayProxyInternalService {
+		return svc.Spec.Type == corev1.ServiceTypeClusterIP
+	}
+	return isSplitHorizonService(svc) || isTopologyHeadlessService(svc)
+}
+
+func isSplitHorizonService(svc *corev1.Service) bool {
+	if strings.ToLower(svc.Annotations[raven.AnnotationSplitHorizonDNS]) != "true" {
+		return false
+	}
+	return svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone
+}
+
+// isTopologyHeadlessService checks whether the endpoints of headless service are filtered by topology on edge nodes.
+func isTopologyHeadlessService(svc *corev1.Service) bool {
+	return svc.Spec.ClusterIP == corev1.ClusterIPNone && len(svc.Annotations[servicetopology.AnnotationServiceTopologyKey]) != 0
+}
+
 func getHostIP(node *corev1.Node) (string, error) {
 	// get InternalIPs first and then ExternalIPs
 	var internalIP, externalIP net.IP
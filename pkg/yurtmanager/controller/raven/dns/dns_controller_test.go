@@ -32,6 +32,7 @@ import (
 
 	"github.com/openyurtio/openyurt/pkg/apis/raven"
 	ravenv1v1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
 )
 
@@ -165,3 +166,144 @@ func TestReconcileDns_Reconcile(t *testing.T) {
 		assert.Equal(t, err, nil)
 	})
 }
+
+func TestReconcileDns_DNSTTL(t *testing.T) {
+	testcases := map[string]struct {
+		data        map[string]string
+		recordTTL   string
+		negativeTTL string
+	}{
+		"default ttl": {
+			recordTTL:   "ttl 30",
+			negativeTTL: "denial 9984 5",
+		},
+		"configured ttl": {
+			data:        map[string]string{utils.RavenDNSRecordTTL: "10", utils.RavenDNSNegativeTTL: "2"},
+			recordTTL:   "ttl 10",
+			negativeTTL: "denial 9984 2",
+		},
+		"invalid ttl": {
+			data:        map[string]string{utils.RavenDNSRecordTTL: "-1", utils.RavenDNSNegativeTTL: "foo"},
+			recordTTL:   "ttl 30",
+			negativeTTL: "denial 9984 5",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			r := mockReconciler()
+			if tc.data != nil {
+				err := r.Client.Create(context.Background(), &v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: utils.WorkingNamespace, Name: utils.RavenGlobalConfig},
+					Data:       tc.data,
+				})
+				assert.NoError(t, err)
+			}
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: utils.WorkingNamespace, Name: utils.RavenProxyNodesConfig}})
+			assert.NoError(t, err)
+
+			var cm v1.ConfigMap
+			err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: utils.WorkingNamespace, Name: utils.RavenProxyNodesConfig}, &cm)
+			assert.NoError(t, err)
+			assert.Contains(t, cm.Data[utils.DNSServerConfigKey], tc.recordTTL)
+			assert.Contains(t, cm.Data[utils.DNSServerConfigKey], tc.negativeTTL)
+		})
+	}
+}
+
+func TestBuildServiceDNSRecords(t *testing.T) {
+	svcList := &v1.ServiceList{
+		Items: []v1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "web",
+					Namespace:   "default",
+					Annotations: map[string]string{raven.AnnotationSplitHorizonDNS: "true"},
+				},
+				Spec: v1.ServiceSpec{ClusterIP: "10.96.0.10"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "headless",
+					Namespace:   "default",
+					Annotations: map[string]string{raven.AnnotationSplitHorizonDNS: "true"},
+				},
+				Spec: v1.ServiceSpec{ClusterIP: v1.ClusterIPNone},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "normal",
+					Namespace: "default",
+				},
+				Spec: v1.ServiceSpec{ClusterIP: "10.96.0.11"},
+			},
+		},
+	}
+
+	t.Run("proxy is enabled", func(t *testing.T) {
+		cloud, edge := buildServiceDNSRecords(svcList, true, ProxyIP)
+		assert.Equal(t, ProxyIP+"\tweb.default.svc.cluster.local", cloud)
+		assert.Equal(t, "10.96.0.10\tweb.default.svc.cluster.local", edge)
+	})
+
+	t.Run("proxy is disabled", func(t *testing.T) {
+		cloud, edge := buildServiceDNSRecords(svcList, false, "")
+		assert.Equal(t, "", cloud)
+		assert.Equal(t, "10.96.0.10\tweb.default.svc.cluster.local", edge)
+	})
+}
+
+func TestBuildIdentityDNSRecords(t *testing.T) {
+	headless := func(name string, annotations map[string]string) v1.Service {
+		return v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       v1.ServiceSpec{ClusterIP: v1.ClusterIPNone},
+		}
+	}
+	endpoints := func(name string, addresses ...v1.EndpointAddress) v1.Endpoints {
+		return v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{v1.IsHeadlessService: ""}},
+			Subsets:    []v1.EndpointSubset{{Addresses: addresses}},
+		}
+	}
+	topology := map[string]string{servicetopology.AnnotationServiceTopologyKey: servicetopology.AnnotationServiceTopologyValueNodePool}
+	svcList := &v1.ServiceList{
+		Items: []v1.Service{
+			headless("web", topology),
+			headless("db", nil),
+			headless("pods", topology),
+		},
+	}
+	// the pods of statefulset web are spread among nodepools and listed out of order, the pods of db are not filtered by
+	// topology and the pods behind service pods have no hostname.
+	endpointsList := &v1.EndpointsList{
+		Items: []v1.Endpoints{
+			endpoints("web",
+				v1.EndpointAddress{IP: "10.244.1.3", Hostname: "web-1"},
+				v1.EndpointAddress{IP: "10.244.1.2", Hostname: "web-0"}),
+			endpoints("db", v1.EndpointAddress{IP: "10.244.1.4", Hostname: "db-0"}),
+			endpoints("pods", v1.EndpointAddress{IP: "10.244.1.5"}),
+		},
+	}
+
+	expect := "10.244.1.2\tweb-0.web.default.svc.cluster.local\n10.244.1.3\tweb-1.web.default.svc.cluster.local"
+	assert.Equal(t, expect, buildIdentityDNSRecords(svcList, endpointsList))
+
+	t.Run("identity records are served with node records", func(t *testing.T) {
+		r := mockReconciler()
+		for i := range svcList.Items {
+			assert.NoError(t, r.Client.Create(context.Background(), &svcList.Items[i]))
+		}
+		for i := range endpointsList.Items {
+			assert.NoError(t, r.Client.Create(context.Background(), &endpointsList.Items[i]))
+		}
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: utils.WorkingNamespace, Name: utils.RavenProxyNodesConfig}})
+		assert.NoError(t, err)
+
+		var cm v1.ConfigMap
+		err = r.Client.Get(context.Background(), types.NamespacedName{Namespace: utils.WorkingNamespace, Name: utils.RavenProxyNodesConfig}, &cm)
+		assert.NoError(t, err)
+		assert.Contains(t, cm.Data[utils.ProxyNodesKey], Node1Address+"\t"+Node1Name+"\n")
+		assert.Contains(t, cm.Data[utils.ProxyNodesKey], expect)
+	})
+}
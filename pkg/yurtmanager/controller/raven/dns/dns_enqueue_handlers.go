@@ -17,6 +17,8 @@ limitations under the License.
 package dns
 
 import (
+	"reflect"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
@@ -53,7 +55,8 @@ func (h *EnqueueRequestForServiceEvent) Update(e event.UpdateEvent, q workqueue.
 		klog.Error(Format("fail to assert runtime Object to v1.Service"))
 		return
 	}
-	if newSvc.Spec.ClusterIP != oldSvc.Spec.ClusterIP {
+	if newSvc.Spec.ClusterIP != oldSvc.Spec.ClusterIP || isSplitHorizonService(newSvc) != isSplitHorizonService(oldSvc) ||
+		isTopologyHeadlessService(newSvc) != isTopologyHeadlessService(oldSvc) {
 		klog.V(2).Infof(Format("enqueue configmap %s/%s due to service update event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
 		utils.AddDNSConfigmapToWorkQueue(q)
 	}
@@ -87,7 +90,20 @@ func (h *EnqueueRequestForNodeEvent) Create(e event.CreateEvent, q workqueue.Rat
 }
 
 func (h *EnqueueRequestForNodeEvent) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	return
+	newNode, ok := e.ObjectNew.(*corev1.Node)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Node"))
+		return
+	}
+	oldNode, ok := e.ObjectOld.(*corev1.Node)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Node"))
+		return
+	}
+	if !reflect.DeepEqual(oldNode.Status.Addresses, newNode.Status.Addresses) {
+		klog.V(2).Infof(Format("enqueue configmap %s/%s due to node address update event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+		utils.AddDNSConfigmapToWorkQueue(q)
+	}
 }
 
 func (h *EnqueueRequestForNodeEvent) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
@@ -103,3 +119,72 @@ func (h *EnqueueRequestForNodeEvent) Delete(e event.DeleteEvent, q workqueue.Rat
 func (h *EnqueueRequestForNodeEvent) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
 
 }
+
+type EnqueueRequestForEndpointsEvent struct{}
+
+func (h *EnqueueRequestForEndpointsEvent) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	klog.V(2).Infof(Format("enqueue configmap %s/%s due to endpoints create event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+	utils.AddDNSConfigmapToWorkQueue(q)
+}
+
+func (h *EnqueueRequestForEndpointsEvent) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newEp, ok := e.ObjectNew.(*corev1.Endpoints)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Endpoints"))
+		return
+	}
+	oldEp, ok := e.ObjectOld.(*corev1.Endpoints)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Endpoints"))
+		return
+	}
+	if !reflect.DeepEqual(oldEp.Subsets, newEp.Subsets) {
+		klog.V(2).Infof(Format("enqueue configmap %s/%s due to endpoints update event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+		utils.AddDNSConfigmapToWorkQueue(q)
+	}
+}
+
+func (h *EnqueueRequestForEndpointsEvent) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	klog.V(2).Infof(Format("enqueue configmap %s/%s due to endpoints delete event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+	utils.AddDNSConfigmapToWorkQueue(q)
+}
+
+func (h *EnqueueRequestForEndpointsEvent) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	return
+}
+
+type EnqueueRequestForRavenConfigEvent struct{}
+
+func (h *EnqueueRequestForRavenConfigEvent) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	klog.V(2).Infof(Format("enqueue configmap %s/%s due to raven config create event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+	utils.AddDNSConfigmapToWorkQueue(q)
+}
+
+func (h *EnqueueRequestForRavenConfigEvent) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newCm, ok := e.ObjectNew.(*corev1.ConfigMap)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.ConfigMap"))
+		return
+	}
+	oldCm, ok := e.ObjectOld.(*corev1.ConfigMap)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.ConfigMap"))
+		return
+	}
+	for _, key := range []string{utils.RavenEnableProxy, utils.RavenDNSRecordTTL, utils.RavenDNSNegativeTTL} {
+		if oldCm.Data[key] != newCm.Data[key] {
+			klog.V(2).Infof(Format("enqueue configmap %s/%s due to raven config update event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+			utils.AddDNSConfigmapToWorkQueue(q)
+			return
+		}
+	}
+}
+
+func (h *EnqueueRequestForRavenConfigEvent) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	klog.V(2).Infof(Format("enqueue configmap %s/%s due to raven config delete event", utils.WorkingNamespace, utils.RavenProxyNodesConfig))
+	utils.AddDNSConfigmapToWorkQueue(q)
+}
+
+func (h *EnqueueRequestForRavenConfigEvent) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	return
+}
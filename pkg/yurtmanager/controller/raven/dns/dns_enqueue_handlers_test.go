@@ -25,6 +25,8 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
 )
 
@@ -89,6 +91,46 @@ func TestEnqueueRequestFoServiceEvent(t *testing.T) {
 		t.Errorf("failed to update service, expected %d, but get %d", 1, queue.Len())
 	}
 	clearQueue(queue)
+
+	annotatedSvc := svc.DeepCopy()
+	annotatedSvc.Annotations = map[string]string{raven.AnnotationSplitHorizonDNS: "true"}
+	h.Update(event.UpdateEvent{ObjectOld: svc, ObjectNew: annotatedSvc}, queue)
+	if !assert.Equal(t, 1, queue.Len()) {
+		t.Errorf("failed to update service, expected %d, but get %d", 1, queue.Len())
+	}
+	clearQueue(queue)
+
+	headlessSvc := svc.DeepCopy()
+	headlessSvc.Spec.ClusterIP = corev1.ClusterIPNone
+	topologySvc := headlessSvc.DeepCopy()
+	topologySvc.Annotations = map[string]string{servicetopology.AnnotationServiceTopologyKey: servicetopology.AnnotationServiceTopologyValueNodePool}
+	h.Update(event.UpdateEvent{ObjectOld: headlessSvc, ObjectNew: topologySvc}, queue)
+	if !assert.Equal(t, 1, queue.Len()) {
+		t.Errorf("failed to update service, expected %d, but get %d", 1, queue.Len())
+	}
+	clearQueue(queue)
+}
+
+func TestEnqueueRequestForEndpointsEvent(t *testing.T) {
+	h := &EnqueueRequestForEndpointsEvent{}
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: []corev1.EndpointAddress{{IP: "10.244.1.2", Hostname: "web-0"}}},
+		},
+	}
+
+	h.Update(event.UpdateEvent{ObjectOld: ep, ObjectNew: ep.DeepCopy()}, queue)
+	if !assert.Equal(t, 0, queue.Len()) {
+		t.Errorf("failed to update endpoints, expected %d, but get %d", 0, queue.Len())
+	}
+	updatedEp := ep.DeepCopy()
+	updatedEp.Subsets[0].Addresses[0].IP = "10.244.1.3"
+	h.Update(event.UpdateEvent{ObjectOld: ep, ObjectNew: updatedEp}, queue)
+	if !assert.Equal(t, 1, queue.Len()) {
+		t.Errorf("failed to update endpoints, expected %d, but get %d", 1, queue.Len())
+	}
 }
 
 func TestEnqueueRequestForNodeEvent(t *testing.T) {
@@ -107,6 +149,18 @@ func TestEnqueueRequestForNodeEvent(t *testing.T) {
 	}
 	clearQueue(queue)
 
+	h.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: node.DeepCopy()}, queue)
+	if !assert.Equal(t, 0, queue.Len()) {
+		t.Errorf("failed to update node, expected %d, but get %d", 0, queue.Len())
+	}
+	updatedNode := node.DeepCopy()
+	updatedNode.Status.Addresses[0].Address = Node2Address
+	h.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: updatedNode}, queue)
+	if !assert.Equal(t, 1, queue.Len()) {
+		t.Errorf("failed to update node, expected %d, but get %d", 1, queue.Len())
+	}
+	clearQueue(queue)
+
 	time := metav1.Now()
 	deletedNode := node.DeepCopy()
 	deletedNode.DeletionTimestamp = &time
@@ -0,0 +1,28 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// GatewayCertificateControllerConfiguration contains elements describing GatewayCertificateController.
+type GatewayCertificateControllerConfiguration struct {
+	// IssuerName is the name of cert-manager issuer which signs the certificates of raven proxy,
+	// the certificates are not sourced from cert-manager if it's empty.
+	IssuerName string
+	// IssuerKind is the kind of cert-manager issuer, Issuer or ClusterIssuer.
+	IssuerKind string
+	// IssuerGroup is the api group of cert-manager issuer.
+	IssuerGroup string
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycertificate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	common "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaycertificate/config"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
+)
+
+const (
+	serverCertificatePrefix = "raven-proxy-server-"
+	clientCertificatePrefix = "raven-proxy-client-"
+)
+
+var (
+	// CertificateGVK is the cert-manager Certificate, it's accessed as unstructured object,
+	// so yurt-manager doesn't depend on the api of cert-manager.
+	CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+)
+
+func Format(format string, args ...interface{}) string {
+	s := fmt.Sprintf(format, args...)
+	return fmt.Sprintf("%s: %s", names.GatewayCertificateController, s)
+}
+
+// ServerCertificateName returns the name of cert-manager Certificate and Secret for the proxy server of gateway.
+func ServerCertificateName(gwName string) string {
+	return serverCertificatePrefix + gwName
+}
+
+// ClientCertificateName returns the name of cert-manager Certificate and Secret for the proxy client of gateway.
+func ClientCertificateName(gwName string) string {
+	return clientCertificatePrefix + gwName
+}
+
+// Add creates a new GatewayCertificate Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(c *appconfig.CompletedConfig, mgr manager.Manager) error {
+	cfg := c.ComponentConfig.GatewayCertificateController
+	if len(cfg.IssuerName) == 0 {
+		klog.Infof(Format("cert-manager issuer is not specified, the built-in certificates are used by raven"))
+		return nil
+	}
+	if _, err := mgr.GetRESTMapper().RESTMapping(CertificateGVK.GroupKind(), CertificateGVK.Version); err != nil {
+		klog.Infof(Format("resource %s doesn't exist", CertificateGVK.String()))
+		return err
+	}
+
+	r := &ReconcileGatewayCertificate{
+		Client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		recorder:      mgr.GetEventRecorderFor(names.GatewayCertificateController),
+		Configuration: cfg,
+		namespace:     utils.WorkingNamespace,
+	}
+	ctrl, err := controller.New(names.GatewayCertificateController, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: common.ConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.Watch(&source.Kind{Type: &ravenv1beta1.Gateway{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	return ctrl.Watch(&source.Kind{Type: certificate}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &ravenv1beta1.Gateway{},
+	})
+}
+
+var _ reconcile.Reconciler = &ReconcileGatewayCertificate{}
+
+// ReconcileGatewayCertificate sources the server and client certificates of raven proxy in each gateway from the
+// cert-manager issuer instead of the built-in self-signed flow. the cert-manager Certificates are created in the
+// working namespace of raven and owned by gateway, and the secrets of certificates are recorded in the annotations
+// of gateway once the certificates are ready, so raven agents can pick them up.
+type ReconcileGatewayCertificate struct {
+	client.Client
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	Configuration config.GatewayCertificateControllerConfiguration
+	namespace     string
+}
+
+// certificateSpec is the subset of cert-manager Certificate spec which is managed by yurt-manager.
+type certificateSpec struct {
+	SecretName     string          `json:"secretName"`
+	SecretTemplate *secretTemplate `json:"secretTemplate,omitempty"`
+	CommonName     string          `json:"commonName,omitempty"`
+	DNSNames       []string        `json:"dnsNames,omitempty"`
+	IPAddresses    []string        `json:"ipAddresses,omitempty"`
+	Usages         []string        `json:"usages,omitempty"`
+	IssuerRef      issuerReference `json:"issuerRef"`
+}
+
+type secretTemplate struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type issuerReference struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+//+kubebuilder:rbac:groups=raven.openyurt.io,resources=gateways,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile ensures the cert-manager Certificates of gateway, and records the secrets of ready certificates in gateway.
+func (r *ReconcileGatewayCertificate) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var gw ravenv1beta1.Gateway
+	if err := r.Get(ctx, req.NamespacedName, &gw); err != nil {
+		// the certificates are owned by gateway, so they are deleted by garbage collector.
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if gw.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	serverReady, err := r.syncCertificate(ctx, &gw, ServerCertificateName(gw.Name), r.serverCertificateSpec(&gw))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	clientReady, err := r.syncCertificate(ctx, &gw, ClientCertificateName(gw.Name), r.clientCertificateSpec(&gw))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	annotations := map[string]string{
+		raven.AnnotationServerCertificateSecret: "",
+		raven.AnnotationClientCertificateSecret: "",
+	}
+	if serverReady {
+		annotations[raven.AnnotationServerCertificateSecret] = r.namespace + "/" + ServerCertificateName(gw.Name)
+	}
+	if clientReady {
+		annotations[raven.AnnotationClientCertificateSecret] = r.namespace + "/" + ClientCertificateName(gw.Name)
+	}
+	return reconcile.Result{}, r.updateAnnotations(ctx, &gw, annotations)
+}
+
+// syncCertificate creates or updates the cert-manager Certificate, and returns whether the certificate is ready.
+func (r *ReconcileGatewayCertificate) syncCertificate(ctx context.Context, gw *ravenv1beta1.Gateway, name string, spec *certificateSpec) (bool, error) {
+	desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+	if err != nil {
+		return false, err
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	err = r.Get(ctx, client.ObjectKey{Namespace: r.namespace, Name: name}, certificate)
+	if apierrs.IsNotFound(err) {
+		certificate.SetNamespace(r.namespace)
+		certificate.SetName(name)
+		certificate.SetLabels(map[string]string{raven.LabelCurrentGateway: gw.Name})
+		certificate.Object["spec"] = desired
+		if err := controllerutil.SetControllerReference(gw, certificate, r.scheme); err != nil {
+			return false, err
+		}
+		if err := r.Create(ctx, certificate); err != nil {
+			klog.Errorf(Format("could not create certificate %s/%s, %v", r.namespace, name, err))
+			return false, err
+		}
+		r.recorder.Eventf(gw, corev1.EventTypeNormal, "CertificateCreated", "certificate %s/%s is requested from %s %s",
+			r.namespace, name, spec.IssuerRef.Kind, spec.IssuerRef.Name)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var current certificateSpec
+	existingSpec, _, _ := unstructured.NestedMap(certificate.Object, "spec")
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(existingSpec, &current); err != nil || !reflect.DeepEqual(&current, spec) {
+		// the fields which are not managed by yurt-manager are kept
+		if existingSpec == nil {
+			existingSpec = make(map[string]interface{})
+		}
+		for k, v := range desired {
+			existingSpec[k] = v
+		}
+		for _, k := range []string{"secretTemplate", "dnsNames", "ipAddresses"} {
+			if _, ok := desired[k]; !ok {
+				delete(existingSpec, k)
+			}
+		}
+		certificate.Object["spec"] = existingSpec
+		if err := r.Update(ctx, certificate); err != nil {
+			klog.Errorf(Format("could not update certificate %s/%s, %v", r.namespace, name, err))
+			return false, err
+		}
+		klog.Infof(Format("certificate %s/%s is updated", r.namespace, name))
+	}
+	return isCertificateReady(certificate), nil
+}
+
+// serverCertificateSpec returns the certificate of proxy server, which is valid for the public ips of gateway
+// endpoints, the private ips of gateway nodes and the internal proxy service.
+func (r *ReconcileGatewayCertificate) serverCertificateSpec(gw *ravenv1beta1.Gateway) *certificateSpec {
+	ips := sets.NewString()
+	for _, ep := range gw.Spec.Endpoints {
+		if net.ParseIP(ep.PublicIP) != nil {
+			ips.Insert(ep.PublicIP)
+		}
+	}
+	for _, node := range gw.Status.Nodes {
+		if net.ParseIP(node.PrivateIP) != nil {
+			ips.Insert(node.PrivateIP)
+		}
+	}
+	svc := utils.GatewayProxyInternalService
+	spec := r.certificateSpec(gw, ServerCertificateName(gw.Name), fmt.Sprintf("raven-proxy-server:%s", gw.Name),
+		"digital signature", "key encipherment", "server auth")
+	spec.DNSNames = []string{
+		svc,
+		fmt.Sprintf("%s.%s", svc, r.namespace),
+		fmt.Sprintf("%s.%s.svc", svc, r.namespace),
+		fmt.Sprintf("%s.%s.svc.%s", svc, r.namespace, utils.ClusterDomain),
+	}
+	if ips.Len() != 0 {
+		spec.IPAddresses = ips.List()
+	}
+	return spec
+}
+
+// clientCertificateSpec returns the certificate of proxy client, which is used to connect with the proxy server.
+func (r *ReconcileGatewayCertificate) clientCertificateSpec(gw *ravenv1beta1.Gateway) *certificateSpec {
+	return r.certificateSpec(gw, ClientCertificateName(gw.Name), fmt.Sprintf("raven-proxy-client:%s", gw.Name),
+		"digital signature", "key encipherment", "client auth")
+}
+
+func (r *ReconcileGatewayCertificate) certificateSpec(gw *ravenv1beta1.Gateway, name, commonName string, usages ...string) *certificateSpec {
+	return &certificateSpec{
+		SecretName: name,
+		SecretTemplate: &secretTemplate{
+			Labels: map[string]string{raven.LabelCurrentGateway: gw.Name},
+		},
+		CommonName: commonName,
+		Usages:     usages,
+		IssuerRef: issuerReference{
+			Name:  r.Configuration.IssuerName,
+			Kind:  r.Configuration.IssuerKind,
+			Group: r.Configuration.IssuerGroup,
+		},
+	}
+}
+
+// updateAnnotations sets the annotations of gateway, and the annotations with empty value are removed.
+func (r *ReconcileGatewayCertificate) updateAnnotations(ctx context.Context, gw *ravenv1beta1.Gateway, annotations map[string]string) error {
+	changed := false
+	for k, v := range annotations {
+		if gw.Annotations[k] != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(gw.DeepCopy())
+	if gw.Annotations == nil {
+		gw.Annotations = make(map[string]string)
+	}
+	for k, v := range annotations {
+		if len(v) == 0 {
+			delete(gw.Annotations, k)
+		} else {
+			gw.Annotations[k] = v
+		}
+	}
+	if err := r.Patch(ctx, gw, patch); err != nil {
+		klog.Errorf(Format("could not update certificate annotations of gateway %s, %v", gw.Name, err))
+		return err
+	}
+	r.recorder.Eventf(gw, corev1.EventTypeNormal, "CertificateSecretsUpdated", "server certificate secret: %q, client certificate secret: %q",
+		gw.Annotations[raven.AnnotationServerCertificateSecret], gw.Annotations[raven.AnnotationClientCertificateSecret])
+	return nil
+}
+
+// isCertificateReady checks the Ready condition of cert-manager Certificate.
+func isCertificateReady(certificate *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Ready" {
+			return condition["status"] == string(corev1.ConditionTrue)
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycertificate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaycertificate/config"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
+)
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	apis.AddToScheme(scheme)
+	scheme.AddKnownTypeWithName(CertificateGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(CertificateGVK.GroupVersion().WithKind("CertificateList"), &unstructured.UnstructuredList{})
+
+	gw := &ravenv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw-hangzhou", UID: "gw-uid"},
+		Spec: ravenv1beta1.GatewaySpec{
+			Endpoints: []ravenv1beta1.Endpoint{
+				{NodeName: "node1", Type: ravenv1beta1.Proxy, PublicIP: "47.1.1.1"},
+				{NodeName: "node2", Type: ravenv1beta1.Tunnel, PublicIP: "47.1.1.2"},
+			},
+		},
+		Status: ravenv1beta1.GatewayStatus{
+			Nodes: []ravenv1beta1.NodeInfo{{NodeName: "node1", PrivateIP: "192.168.0.1"}},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(gw).Build()
+	r := &ReconcileGatewayCertificate{
+		Client:   c,
+		scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+		Configuration: config.GatewayCertificateControllerConfiguration{
+			IssuerName:  "corp-pki",
+			IssuerKind:  "ClusterIssuer",
+			IssuerGroup: "cert-manager.io",
+		},
+		namespace: utils.WorkingNamespace,
+	}
+
+	ctx := context.Background()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: gw.Name}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	getCertificate := func(name string) *unstructured.Unstructured {
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(CertificateGVK)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: utils.WorkingNamespace, Name: name}, certificate); err != nil {
+			t.Fatalf("could not get certificate %s, %v", name, err)
+		}
+		return certificate
+	}
+
+	server := getCertificate(ServerCertificateName(gw.Name))
+	ips, _, _ := unstructured.NestedStringSlice(server.Object, "spec", "ipAddresses")
+	if expect := []string{"192.168.0.1", "47.1.1.1", "47.1.1.2"}; !reflect.DeepEqual(ips, expect) {
+		t.Errorf("expect ip addresses %v, but got %v", expect, ips)
+	}
+	issuer, _, _ := unstructured.NestedString(server.Object, "spec", "issuerRef", "name")
+	if issuer != "corp-pki" {
+		t.Errorf("expect issuer corp-pki, but got %s", issuer)
+	}
+	usages, _, _ := unstructured.NestedStringSlice(getCertificate(ClientCertificateName(gw.Name)).Object, "spec", "usages")
+	if expect := []string{"digital signature", "key encipherment", "client auth"}; !reflect.DeepEqual(usages, expect) {
+		t.Errorf("expect usages %v, but got %v", expect, usages)
+	}
+
+	var got ravenv1beta1.Gateway
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("could not get gateway, %v", err)
+	}
+	if _, ok := got.Annotations[raven.AnnotationServerCertificateSecret]; ok {
+		t.Errorf("expect no certificate secret before the certificate is ready")
+	}
+
+	// the secret is recorded in gateway when the certificate is ready, and the
+	// certificate is updated when the public ip of gateway is changed.
+	server.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+	}
+	if err := c.Update(ctx, server); err != nil {
+		t.Fatalf("could not update certificate, %v", err)
+	}
+	got.Spec.Endpoints = got.Spec.Endpoints[:1]
+	if err := c.Update(ctx, &got); err != nil {
+		t.Fatalf("could not update gateway, %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("could not get gateway, %v", err)
+	}
+	if secret := got.Annotations[raven.AnnotationServerCertificateSecret]; secret != utils.WorkingNamespace+"/"+ServerCertificateName(gw.Name) {
+		t.Errorf("expect server certificate secret is recorded, but got %q", secret)
+	}
+	if _, ok := got.Annotations[raven.AnnotationClientCertificateSecret]; ok {
+		t.Errorf("expect no client certificate secret before the certificate is ready")
+	}
+	ips, _, _ = unstructured.NestedStringSlice(getCertificate(ServerCertificateName(gw.Name)).Object, "spec", "ipAddresses")
+	if expect := []string{"192.168.0.1", "47.1.1.1"}; !reflect.DeepEqual(ips, expect) {
+		t.Errorf("expect ip addresses %v, but got %v", expect, ips)
+	}
+}
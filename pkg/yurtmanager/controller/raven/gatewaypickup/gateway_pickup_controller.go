@@ -118,6 +118,18 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to Services pinned to Gateway
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &EnqueueGatewayForService{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Endpoints of Services pinned to Gateway
+	err = c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, &EnqueueGatewayForEndpoints{client: mgr.GetClient()})
+	if err != nil {
+		return err
+	}
+
 	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &EnqueueGatewayForRavenConfig{client: mgr.GetClient()}, predicate.NewPredicateFuncs(
 		func(object client.Object) bool {
 			cm, ok := object.(*corev1.ConfigMap)
@@ -200,6 +212,13 @@ func (r *ReconcileGateway) Reconcile(ctx context.Context, req reconcile.Request)
 	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeName < nodes[j].NodeName })
 	klog.V(4).Info(Format("managed node info list, nodes: %v", nodes))
 	gw.Status.Nodes = nodes
+	// 3. get the services whose cross-pool traffic is pinned to the Gateway
+	pinnedServices, err := r.getPinnedServices(ctx, &gw)
+	if err != nil {
+		klog.ErrorS(err, "unable to get pinned services")
+		return reconcile.Result{}, err
+	}
+	gw.Status.PinnedServices = pinnedServices
 	err = r.Status().Update(ctx, &gw)
 	if err != nil {
 		if apierrs.IsConflict(err) {
@@ -351,6 +370,47 @@ func (r *ReconcileGateway) getPodCIDRs(ctx context.Context, node corev1.Node) ([
 	return append(podCIDRs, node.Spec.PodCIDR), nil
 }
 
+// getPinnedServices returns the services annotated with the name of gateway, the ready endpoint addresses
+// of service are routed through the tunnel of gateway by raven agents.
+func (r *ReconcileGateway) getPinnedServices(ctx context.Context, gw *ravenv1beta1.Gateway) ([]ravenv1beta1.PinnedService, error) {
+	var svcList corev1.ServiceList
+	if err := r.List(ctx, &svcList); err != nil {
+		return nil, fmt.Errorf("unable to list services: %s", err)
+	}
+
+	pinnedServices := make([]ravenv1beta1.PinnedService, 0)
+	for _, svc := range svcList.Items {
+		if svc.Annotations[raven.AnnotationPinnedGateway] != gw.Name {
+			continue
+		}
+		var eps corev1.Endpoints
+		if err := r.Get(ctx, client.ObjectKey{Namespace: svc.Namespace, Name: svc.Name}, &eps); err != nil && !apierrs.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to get endpoints %s/%s: %s", svc.Namespace, svc.Name, err)
+		}
+		addresses := make([]string, 0)
+		seen := make(map[string]struct{})
+		for _, subset := range eps.Subsets {
+			for _, addr := range subset.Addresses {
+				if _, ok := seen[addr.IP]; ok {
+					continue
+				}
+				seen[addr.IP] = struct{}{}
+				addresses = append(addresses, addr.IP)
+			}
+		}
+		sort.Strings(addresses)
+		pinnedServices = append(pinnedServices, ravenv1beta1.PinnedService{
+			Service:   fmt.Sprintf("%s/%s", svc.Namespace, svc.Name),
+			Addresses: addresses,
+		})
+	}
+	if len(pinnedServices) == 0 {
+		return nil, nil
+	}
+	sort.Slice(pinnedServices, func(i, j int) bool { return pinnedServices[i].Service < pinnedServices[j].Service })
+	return pinnedServices, nil
+}
+
 func getActiveEndpointsInfo(eps []*ravenv1beta1.Endpoint) (map[string][]string, int) {
 	infos := make(map[string][]string)
 	infos[ActiveEndpointsName] = make([]string, 0)
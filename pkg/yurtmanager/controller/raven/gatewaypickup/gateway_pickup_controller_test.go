@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/openyurtio/openyurt/pkg/apis/raven"
 	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/gatewaypickup/config"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/raven/utils"
@@ -465,3 +466,66 @@ func TestReconcileGateway_getPodCIDRs(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileGateway_getPinnedServices(t *testing.T) {
+	newService := func(name, gwName string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{raven.AnnotationPinnedGateway: gwName},
+			},
+		}
+	}
+	eps := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.1.3"}, {IP: "10.0.0.2"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.4"}},
+			},
+			{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}},
+			},
+		},
+	}
+	mockReconciler := &ReconcileGateway{
+		Configration: config.GatewayPickupControllerConfiguration{},
+		Client: fake.NewClientBuilder().WithObjects(
+			newService("web", "gw-hangzhou"),
+			newService("api", "gw-hangzhou"),
+			newService("db", "gw-shanghai"),
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "default"}},
+			eps,
+		).Build(),
+	}
+	var tt = []struct {
+		name   string
+		gwName string
+		expect []ravenv1beta1.PinnedService
+	}{
+		{
+			name:   "services are pinned to gateway",
+			gwName: "gw-hangzhou",
+			expect: []ravenv1beta1.PinnedService{
+				{Service: "default/api", Addresses: []string{}},
+				{Service: "default/web", Addresses: []string{"10.0.0.2", "10.0.1.3"}},
+			},
+		},
+		{
+			name:   "no service is pinned to gateway",
+			gwName: "gw-beijing",
+			expect: nil,
+		},
+	}
+	for _, v := range tt {
+		t.Run(v.name, func(t *testing.T) {
+			a := assert.New(t)
+			gw := &ravenv1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: v.gwName}}
+			pinnedServices, err := mockReconciler.getPinnedServices(context.Background(), gw)
+			if a.NoError(err) {
+				a.Equal(v.expect, pinnedServices)
+			}
+		})
+	}
+}
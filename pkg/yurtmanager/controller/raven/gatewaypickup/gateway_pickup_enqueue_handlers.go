@@ -18,8 +18,10 @@ package gatewaypickup
 
 import (
 	"context"
+	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -102,6 +104,112 @@ func (e *EnqueueGatewayForNode) Delete(evt event.DeleteEvent, q workqueue.RateLi
 func (e *EnqueueGatewayForNode) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
 }
 
+type EnqueueGatewayForService struct{}
+
+// Create implements EventHandler
+func (e *EnqueueGatewayForService) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	svc, ok := evt.Object.(*corev1.Service)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Service"))
+		return
+	}
+	utils.AddGatewayToWorkQueue(svc.Annotations[raven.AnnotationPinnedGateway], q)
+}
+
+// Update implements EventHandler
+func (e *EnqueueGatewayForService) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newSvc, ok := evt.ObjectNew.(*corev1.Service)
+	if !ok {
+		klog.Errorf(Format("fail to assert runtime Object(%s) to v1.Service",
+			evt.ObjectNew.GetName()))
+		return
+	}
+	oldSvc, ok := evt.ObjectOld.(*corev1.Service)
+	if !ok {
+		klog.Errorf(Format("fail to assert runtime Object(%s) to v1.Service",
+			evt.ObjectOld.GetName()))
+		return
+	}
+
+	oldGwName := oldSvc.Annotations[raven.AnnotationPinnedGateway]
+	newGwName := newSvc.Annotations[raven.AnnotationPinnedGateway]
+	if oldGwName != newGwName {
+		klog.V(5).Infof(Format("will enqueue gateway as pinned gateway of service(%s/%s) has been changed",
+			newSvc.GetNamespace(), newSvc.GetName()))
+		utils.AddGatewayToWorkQueue(oldGwName, q)
+		utils.AddGatewayToWorkQueue(newGwName, q)
+	}
+}
+
+// Delete implements EventHandler
+func (e *EnqueueGatewayForService) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	svc, ok := evt.Object.(*corev1.Service)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Service"))
+		return
+	}
+	utils.AddGatewayToWorkQueue(svc.Annotations[raven.AnnotationPinnedGateway], q)
+}
+
+// Generic implements EventHandler
+func (e *EnqueueGatewayForService) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+type EnqueueGatewayForEndpoints struct {
+	client client.Client
+}
+
+// Create implements EventHandler
+func (e *EnqueueGatewayForEndpoints) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueuePinnedGateway(evt.Object, q)
+}
+
+// Update implements EventHandler
+func (e *EnqueueGatewayForEndpoints) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newEps, ok := evt.ObjectNew.(*corev1.Endpoints)
+	if !ok {
+		klog.Errorf(Format("fail to assert runtime Object(%s) to v1.Endpoints",
+			evt.ObjectNew.GetName()))
+		return
+	}
+	oldEps, ok := evt.ObjectOld.(*corev1.Endpoints)
+	if !ok {
+		klog.Errorf(Format("fail to assert runtime Object(%s) to v1.Endpoints",
+			evt.ObjectOld.GetName()))
+		return
+	}
+	if reflect.DeepEqual(oldEps.Subsets, newEps.Subsets) {
+		return
+	}
+	e.enqueuePinnedGateway(newEps, q)
+}
+
+// Delete implements EventHandler
+func (e *EnqueueGatewayForEndpoints) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueuePinnedGateway(evt.Object, q)
+}
+
+// Generic implements EventHandler
+func (e *EnqueueGatewayForEndpoints) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+}
+
+// enqueuePinnedGateway enqueues the gateway which the service of endpoints is pinned to.
+func (e *EnqueueGatewayForEndpoints) enqueuePinnedGateway(obj client.Object, q workqueue.RateLimitingInterface) {
+	eps, ok := obj.(*corev1.Endpoints)
+	if !ok {
+		klog.Error(Format("fail to assert runtime Object to v1.Endpoints"))
+		return
+	}
+	var svc corev1.Service
+	if err := e.client.Get(context.TODO(), client.ObjectKey{Namespace: eps.Namespace, Name: eps.Name}, &svc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Error(Format("failed to get service %s/%s, error %s", eps.Namespace, eps.Name, err.Error()))
+		}
+		return
+	}
+	utils.AddGatewayToWorkQueue(svc.Annotations[raven.AnnotationPinnedGateway], q)
+}
+
 type EnqueueGatewayForRavenConfig struct {
 	client client.Client
 }
@@ -46,6 +46,9 @@ const (
 
 	RavenProxyNodesConfig      = "edge-tunnel-nodes"
 	ProxyNodesKey              = "tunnel-nodes"
+	CloudServicesKey           = "cloud-services"
+	EdgeServicesKey            = "edge-services"
+	ClusterDomain              = "cluster.local"
 	RavenAgentConfig           = "raven-agent-config"
 	ProxyServerSecurePortKey   = "proxy-internal-secure-addr"
 	ProxyServerInsecurePortKey = "proxy-internal-insecure-addr"
@@ -53,6 +56,15 @@ const (
 	VPNServerExposedPortKey    = "tunnel-bind-addr"
 	RavenEnableProxy           = "enable-l7-proxy"
 	RavenEnableTunnel          = "enable-l3-tunnel"
+	RavenDNSRecordTTL          = "dns-record-ttl"
+	RavenDNSNegativeTTL        = "dns-negative-ttl"
+	DNSServerConfigKey         = "tunnel-nodes.server"
+
+	// DefaultDNSRecordTTL and DefaultDNSNegativeTTL are the ttl(in seconds) of dns records and negative responses
+	// for the records in edge-tunnel-nodes, they are kept short so node ip changes are propagated quickly.
+	DefaultDNSRecordTTL   = 30
+	DefaultDNSNegativeTTL = 5
+	MaxDNSTTL             = 3600
 )
 
 // GetNodeInternalIP returns internal ip of the given `node`.
@@ -94,6 +106,37 @@ func CheckServer(ctx context.Context, client client.Client) (enableProxy, enable
 	return enableProxy, enableTunnel
 }
 
+// GetDNSTTL returns the ttl of dns records and negative responses configured in raven-cfg, and the
+// default values are used when they are not configured or invalid.
+func GetDNSTTL(ctx context.Context, client client.Client) (recordTTL, negativeTTL int) {
+	recordTTL, negativeTTL = DefaultDNSRecordTTL, DefaultDNSNegativeTTL
+	var cm corev1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{Namespace: WorkingNamespace, Name: RavenGlobalConfig}, &cm)
+	if err != nil {
+		return recordTTL, negativeTTL
+	}
+	if ttl, ok := parseDNSTTL(cm.Data, RavenDNSRecordTTL); ok {
+		recordTTL = ttl
+	}
+	if ttl, ok := parseDNSTTL(cm.Data, RavenDNSNegativeTTL); ok {
+		negativeTTL = ttl
+	}
+	return recordTTL, negativeTTL
+}
+
+func parseDNSTTL(data map[string]string, key string) (int, bool) {
+	val, ok := data[key]
+	if !ok {
+		return 0, false
+	}
+	ttl, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || ttl < 1 || ttl > MaxDNSTTL {
+		klog.Warningf("invalid %s %q in %s/%s, it should be an integer in [1, %d]", key, val, WorkingNamespace, RavenGlobalConfig, MaxDNSTTL)
+		return 0, false
+	}
+	return ttl, true
+}
+
 func AddNodePoolToWorkQueue(npName string, q workqueue.RateLimitingInterface) {
 	if npName != "" {
 		q.Add(reconcile.Request{
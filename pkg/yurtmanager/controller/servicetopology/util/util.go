@@ -19,14 +19,34 @@ package util
 import (
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/externaltrafficpolicy"
 	"github.com/openyurtio/openyurt/pkg/yurthub/filter/servicetopology"
+	"github.com/openyurtio/openyurt/pkg/yurthub/filter/topologyhints"
 )
 
 func ServiceTopologyTypeChanged(oldSvc, newSvc *corev1.Service) bool {
 	oldType := oldSvc.Annotations[servicetopology.AnnotationServiceTopologyKey]
 	newType := newSvc.Annotations[servicetopology.AnnotationServiceTopologyKey]
-	if oldType == newType {
-		return false
+	if oldType != newType {
+		return true
 	}
-	return true
+	oldFallback := oldSvc.Annotations[servicetopology.AnnotationServiceTopologyFallback]
+	newFallback := newSvc.Annotations[servicetopology.AnnotationServiceTopologyFallback]
+	if oldFallback != newFallback {
+		return true
+	}
+	// the endpoints are also filtered by externaltrafficpolicy filter in yurthub
+	if externaltrafficpolicy.IsPoolLocalExternalTraffic(oldSvc) != externaltrafficpolicy.IsPoolLocalExternalTraffic(newSvc) {
+		return true
+	}
+	if oldSvc.Spec.ExternalTrafficPolicy != newSvc.Spec.ExternalTrafficPolicy {
+		return true
+	}
+	// the topology hints of endpointslices are generated by topologyhints filter in yurthub
+	for _, key := range []string{topologyhints.AnnotationTopologyHints, topologyhints.AnnotationTopologySpillover, topologyhints.AnnotationTopologyMinLocalEndpoints} {
+		if oldSvc.Annotations[key] != newSvc.Annotations[key] {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,445 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicevariant
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func init() {
+	flag.IntVar(&concurrentReconciles, "service-variant-workers", concurrentReconciles, "Max concurrent workers for service-variant-controller.")
+}
+
+const (
+	allNodePools     = "*"
+	maxServiceName   = 63
+	variantHashChars = 8
+)
+
+var (
+	concurrentReconciles = 3
+	controllerKind       = corev1.SchemeGroupVersion.WithKind("Service")
+)
+
+func Format(format string, args ...interface{}) string {
+	s := fmt.Sprintf(format, args...)
+	return fmt.Sprintf("%s: %s", names.ServiceVariantController, s)
+}
+
+// VariantName returns the name of variant Service of the parent Service in the NodePool. the name is
+// truncated with a hash suffix when it exceeds the length limit of Service name.
+func VariantName(parent, pool string) string {
+	name := strings.ToLower(strings.ReplaceAll(parent+"-"+pool, ".", "-"))
+	if len(name) <= maxServiceName {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(parent + "/" + pool))
+	suffix := fmt.Sprintf("%08x", h.Sum32())[:variantHashChars]
+	return strings.TrimRight(name[:maxServiceName-variantHashChars-1], "-") + "-" + suffix
+}
+
+// ReconcileServiceVariant materializes a variant Service for each NodePool specified by the annotation
+// service.openyurt.io/pool-variants of parent Service. the variant Service has no selector and owns a
+// distinct cluster ip, and its Endpoints only keep the addresses of parent Endpoints on the nodes of the
+// NodePool, so the legacy clients which can't rely on the servicetopology filter of yurthub, like the clients
+// configured with a static cluster ip, can access the endpoints in NodePool by the variant Service.
+// the variant Services are owned by the parent Service, and kept in sync with the ports of parent Service.
+type ReconcileServiceVariant struct {
+	client.Client
+	recorder record.EventRecorder
+}
+
+var _ reconcile.Reconciler = &ReconcileServiceVariant{}
+
+// Add creates a new ServiceVariant Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(_ *appconfig.CompletedConfig, mgr manager.Manager) error {
+	klog.Infof(Format("service-variant-controller add controller %s", controllerKind.String()))
+	r := &ReconcileServiceVariant{
+		Client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(names.ServiceVariantController),
+	}
+
+	c, err := controller.New(names.ServiceVariantController, mgr, controller.Options{
+		Reconciler: r, MaxConcurrentReconciles: concurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// both the old and new Service are mapped when Service is updated, so the variants
+	// are removed when the annotation is removed from parent Service.
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		if _, ok := obj.GetAnnotations()[apps.AnnotationServiceVariantPools]; ok {
+			return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+		}
+		if parent := obj.GetLabels()[apps.ServiceVariantParentLabel]; len(parent) != 0 {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: parent}}}
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		if parent := obj.GetLabels()[apps.ServiceVariantParentLabel]; len(parent) != 0 {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: parent}}}
+		}
+		var svc corev1.Service
+		if err := r.Get(context.TODO(), client.ObjectKeyFromObject(obj), &svc); err != nil {
+			return nil
+		}
+		if _, ok := svc.Annotations[apps.AnnotationServiceVariantPools]; !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+	}))
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &appsv1beta1.NodePool{}}, handler.EnqueueRequestsFromMapFunc(r.mapNodePoolToServices), predicate.Funcs{
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			oldPool, ok := evt.ObjectOld.(*appsv1beta1.NodePool)
+			if !ok {
+				return false
+			}
+			newPool, ok := evt.ObjectNew.(*appsv1beta1.NodePool)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(oldPool.Status.Nodes, newPool.Status.Nodes)
+		},
+	})
+}
+
+// mapNodePoolToServices enqueues the parent Services which have a variant in the NodePool.
+func (r *ReconcileServiceVariant) mapNodePoolToServices(obj client.Object) []reconcile.Request {
+	var svcList corev1.ServiceList
+	if err := r.List(context.TODO(), &svcList); err != nil {
+		klog.Errorf(Format("could not list services, %v", err))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range svcList.Items {
+		pools, ok := parseVariantPools(&svcList.Items[i])
+		if !ok {
+			continue
+		}
+		if pools.Has(allNodePools) || pools.Has(obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&svcList.Items[i])})
+		}
+	}
+	return requests
+}
+
+// parseVariantPools returns the NodePools specified by the annotation of parent Service.
+func parseVariantPools(svc *corev1.Service) (sets.String, bool) {
+	value, ok := svc.Annotations[apps.AnnotationServiceVariantPools]
+	if !ok {
+		return nil, false
+	}
+	pools := sets.NewString()
+	for _, pool := range strings.Split(value, ",") {
+		if pool = strings.TrimSpace(pool); len(pool) != 0 {
+			pools.Insert(pool)
+		}
+	}
+	return pools, true
+}
+
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates or updates the variant Services and Endpoints of parent Service in the specified NodePools,
+// and deletes the variants in the NodePools which are not specified any more.
+func (r *ReconcileServiceVariant) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var svc corev1.Service
+	if err := r.Get(ctx, req.NamespacedName, &svc); err != nil {
+		// the variants are owned by parent Service, so they are deleted by garbage collector.
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if svc.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	nodePools, err := r.desiredNodePools(ctx, &svc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var variantList corev1.ServiceList
+	if err := r.List(ctx, &variantList, client.InNamespace(svc.Namespace),
+		client.MatchingLabels{apps.ServiceVariantParentLabel: svc.Name}); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range variantList.Items {
+		variant := &variantList.Items[i]
+		if !metav1.IsControlledBy(variant, &svc) {
+			continue
+		}
+		if _, ok := nodePools[variant.Labels[apps.PoolNameLabelKey]]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, variant); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf(Format("could not delete variant service %s/%s, %v", variant.Namespace, variant.Name, err))
+			return reconcile.Result{}, err
+		}
+		klog.Infof(Format("variant service %s/%s is deleted", variant.Namespace, variant.Name))
+	}
+	if len(nodePools) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	var endpoints corev1.Endpoints
+	if err := r.Get(ctx, req.NamespacedName, &endpoints); err != nil && !apierrors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	poolNames := make([]string, 0, len(nodePools))
+	for name := range nodePools {
+		poolNames = append(poolNames, name)
+	}
+	sort.Strings(poolNames)
+	for _, name := range poolNames {
+		variant, err := r.syncVariantService(ctx, &svc, name)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if variant == nil {
+			continue
+		}
+		if err := r.syncVariantEndpoints(ctx, variant, &endpoints, nodePools[name]); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// desiredNodePools returns the existing NodePools specified by the annotation of parent Service.
+func (r *ReconcileServiceVariant) desiredNodePools(ctx context.Context, svc *corev1.Service) (map[string]*appsv1beta1.NodePool, error) {
+	pools, ok := parseVariantPools(svc)
+	if !ok || pools.Len() == 0 {
+		return nil, nil
+	}
+
+	var poolList appsv1beta1.NodePoolList
+	if err := r.List(ctx, &poolList); err != nil {
+		return nil, err
+	}
+	nodePools := make(map[string]*appsv1beta1.NodePool)
+	for i := range poolList.Items {
+		if pools.Has(allNodePools) || pools.Has(poolList.Items[i].Name) {
+			nodePools[poolList.Items[i].Name] = &poolList.Items[i]
+		}
+	}
+	return nodePools, nil
+}
+
+// syncVariantService creates or updates the variant Service of parent Service in the NodePool, and nil
+// is returned when the name of variant Service is occupied by a Service not controlled by parent Service.
+func (r *ReconcileServiceVariant) syncVariantService(ctx context.Context, parent *corev1.Service, pool string) (*corev1.Service, error) {
+	variant := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: parent.Namespace,
+			Name:      VariantName(parent.Name, pool),
+		},
+	}
+	err := r.Get(ctx, client.ObjectKeyFromObject(variant), variant)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		variant.Labels = variantLabels(parent.Name, pool)
+		variant.Spec = variantSpec(parent)
+		if err := controllerutil.SetControllerReference(parent, variant, r.Scheme()); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, variant); err != nil {
+			klog.Errorf(Format("could not create variant service %s/%s, %v", variant.Namespace, variant.Name, err))
+			return nil, err
+		}
+		r.recorder.Eventf(parent, corev1.EventTypeNormal, "VariantCreated", "variant service %s is created for nodepool %s", variant.Name, pool)
+		klog.Infof(Format("variant service %s/%s is created for nodepool %s", variant.Namespace, variant.Name, pool))
+		return variant, nil
+	}
+
+	if !metav1.IsControlledBy(variant, parent) {
+		r.recorder.Eventf(parent, corev1.EventTypeWarning, "VariantConflict",
+			"service %s already exists and is not a variant of this service, skip nodepool %s", variant.Name, pool)
+		return nil, nil
+	}
+
+	desired := variantSpec(parent)
+	labels := variantLabels(parent.Name, pool)
+	if reflect.DeepEqual(variant.Spec.Ports, desired.Ports) &&
+		variant.Spec.SessionAffinity == desired.SessionAffinity &&
+		reflect.DeepEqual(variant.Spec.SessionAffinityConfig, desired.SessionAffinityConfig) &&
+		variant.Spec.PublishNotReadyAddresses == desired.PublishNotReadyAddresses &&
+		hasLabels(variant.Labels, labels) {
+		return variant, nil
+	}
+
+	// the cluster ip of variant Service is immutable, so only the mutable fields are updated.
+	patch := client.MergeFrom(variant.DeepCopy())
+	variant.Spec.Ports = desired.Ports
+	variant.Spec.SessionAffinity = desired.SessionAffinity
+	variant.Spec.SessionAffinityConfig = desired.SessionAffinityConfig
+	variant.Spec.PublishNotReadyAddresses = desired.PublishNotReadyAddresses
+	if variant.Labels == nil {
+		variant.Labels = make(map[string]string)
+	}
+	for k, v := range labels {
+		variant.Labels[k] = v
+	}
+	if err := r.Patch(ctx, variant, patch); err != nil {
+		klog.Errorf(Format("could not update variant service %s/%s, %v", variant.Namespace, variant.Name, err))
+		return nil, err
+	}
+	klog.Infof(Format("variant service %s/%s is updated", variant.Namespace, variant.Name))
+	return variant, nil
+}
+
+// syncVariantEndpoints creates or updates the Endpoints of variant Service, which only keeps the addresses
+// of parent Endpoints on the nodes of NodePool.
+func (r *ReconcileServiceVariant) syncVariantEndpoints(ctx context.Context, variant *corev1.Service, parent *corev1.Endpoints, nodePool *appsv1beta1.NodePool) error {
+	nodes := sets.NewString(nodePool.Status.Nodes...)
+	var subsets []corev1.EndpointSubset
+	for _, subset := range parent.Subsets {
+		filtered := corev1.EndpointSubset{
+			Addresses:         filterAddresses(subset.Addresses, nodes),
+			NotReadyAddresses: filterAddresses(subset.NotReadyAddresses, nodes),
+			Ports:             subset.Ports,
+		}
+		if len(filtered.Addresses) != 0 || len(filtered.NotReadyAddresses) != 0 {
+			subsets = append(subsets, filtered)
+		}
+	}
+
+	endpoints := &corev1.Endpoints{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(variant), endpoints)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if apierrors.IsNotFound(err) {
+		endpoints = &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: variant.Namespace,
+				Name:      variant.Name,
+				Labels:    variantLabels(variant.Labels[apps.ServiceVariantParentLabel], nodePool.Name),
+			},
+			Subsets: subsets,
+		}
+		if err := controllerutil.SetControllerReference(variant, endpoints, r.Scheme()); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, endpoints); err != nil {
+			klog.Errorf(Format("could not create endpoints of variant service %s/%s, %v", variant.Namespace, variant.Name, err))
+			return err
+		}
+		return nil
+	}
+
+	if reflect.DeepEqual(endpoints.Subsets, subsets) {
+		return nil
+	}
+	endpoints.Subsets = subsets
+	if err := r.Update(ctx, endpoints); err != nil {
+		klog.Errorf(Format("could not update endpoints of variant service %s/%s, %v", variant.Namespace, variant.Name, err))
+		return err
+	}
+	return nil
+}
+
+// variantSpec returns the spec of variant Service without selector, the node ports are not
+// allocated for variant Service because it's only accessed in the cluster.
+func variantSpec(parent *corev1.Service) corev1.ServiceSpec {
+	spec := corev1.ServiceSpec{
+		Type:                     corev1.ServiceTypeClusterIP,
+		SessionAffinity:          parent.Spec.SessionAffinity,
+		SessionAffinityConfig:    parent.Spec.SessionAffinityConfig,
+		PublishNotReadyAddresses: parent.Spec.PublishNotReadyAddresses,
+	}
+	if parent.Spec.ClusterIP == corev1.ClusterIPNone {
+		spec.ClusterIP = corev1.ClusterIPNone
+	}
+	for _, port := range parent.Spec.Ports {
+		port.NodePort = 0
+		spec.Ports = append(spec.Ports, port)
+	}
+	return spec
+}
+
+func variantLabels(parent, pool string) map[string]string {
+	return map[string]string{
+		apps.ServiceVariantParentLabel: parent,
+		apps.PoolNameLabelKey:          pool,
+	}
+}
+
+func hasLabels(labels, expected map[string]string) bool {
+	for k, v := range expected {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func filterAddresses(addresses []corev1.EndpointAddress, nodes sets.String) []corev1.EndpointAddress {
+	var filtered []corev1.EndpointAddress
+	for _, addr := range addresses {
+		if addr.NodeName != nil && nodes.Has(*addr.NodeName) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
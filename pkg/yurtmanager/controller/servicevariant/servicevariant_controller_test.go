@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicevariant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestVariantName(t *testing.T) {
+	testcases := map[string]struct {
+		parent string
+		pool   string
+		expect string
+	}{
+		"short name": {
+			parent: "web",
+			pool:   "hangzhou",
+			expect: "web-hangzhou",
+		},
+		"pool name with dots": {
+			parent: "web",
+			pool:   "pool.hangzhou",
+			expect: "web-pool-hangzhou",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := VariantName(tc.parent, tc.pool); got != tc.expect {
+				t.Errorf("expect variant name %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+
+	long := VariantName(strings.Repeat("a", 60), "hangzhou")
+	if len(long) > maxServiceName {
+		t.Errorf("expect variant name is not longer than %d, but got %s", maxServiceName, long)
+	}
+	if long == VariantName(strings.Repeat("a", 60), "shanghai") {
+		t.Errorf("expect different variant names for different nodepools, but got %s", long)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	apis.AddToScheme(scheme)
+
+	nodeName := func(name string) *string { return &name }
+	parent := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         "web-uid",
+			Annotations: map[string]string{apps.AnnotationServiceVariantPools: "hangzhou, shanghai"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeNodePort,
+			ClusterIP: "10.96.0.10",
+			Selector:  map[string]string{"app": "web"},
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 80, NodePort: 30080}},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		parent,
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.244.1.1", NodeName: nodeName("node1")},
+					{IP: "10.244.2.1", NodeName: nodeName("node2")},
+				},
+				NotReadyAddresses: []corev1.EndpointAddress{
+					{IP: "10.244.1.2", NodeName: nodeName("node1")},
+				},
+				Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}},
+			}},
+		},
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+			Status:     appsv1beta1.NodePoolStatus{Nodes: []string{"node1"}},
+		},
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "shanghai"},
+			Status:     appsv1beta1.NodePoolStatus{Nodes: []string{"node3"}},
+		},
+	).Build()
+	r := &ReconcileServiceVariant{
+		Client:   c,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	var variant corev1.Service
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-hangzhou"}, &variant); err != nil {
+		t.Fatalf("could not get variant service, %v", err)
+	}
+	if variant.Spec.Type != corev1.ServiceTypeClusterIP || len(variant.Spec.Selector) != 0 || variant.Spec.Ports[0].NodePort != 0 {
+		t.Errorf("expect a selectorless ClusterIP variant service, but got %#+v", variant.Spec)
+	}
+	if !metav1.IsControlledBy(&variant, parent) {
+		t.Errorf("expect variant service is controlled by parent service")
+	}
+
+	var endpoints corev1.Endpoints
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-hangzhou"}, &endpoints); err != nil {
+		t.Fatalf("could not get endpoints of variant service, %v", err)
+	}
+	if len(endpoints.Subsets) != 1 || len(endpoints.Subsets[0].Addresses) != 1 || endpoints.Subsets[0].Addresses[0].IP != "10.244.1.1" ||
+		len(endpoints.Subsets[0].NotReadyAddresses) != 1 || endpoints.Subsets[0].Ports[0].Port != 8080 {
+		t.Errorf("expect only the addresses in nodepool hangzhou, but got %#+v", endpoints.Subsets)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-shanghai"}, &endpoints); err != nil {
+		t.Fatalf("could not get endpoints of variant service, %v", err)
+	}
+	if len(endpoints.Subsets) != 0 {
+		t.Errorf("expect no addresses in nodepool shanghai, but got %#+v", endpoints.Subsets)
+	}
+
+	// the ports of variant are kept in sync with parent service, and the variant
+	// is deleted when the nodepool is removed from the annotation.
+	var latest corev1.Service
+	if err := c.Get(ctx, key, &latest); err != nil {
+		t.Fatalf("could not get service, %v", err)
+	}
+	latest.Annotations[apps.AnnotationServiceVariantPools] = "hangzhou"
+	latest.Spec.Ports[0].Port = 8000
+	if err := c.Update(ctx, &latest); err != nil {
+		t.Fatalf("could not update service, %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-hangzhou"}, &variant); err != nil {
+		t.Fatalf("could not get variant service, %v", err)
+	}
+	if variant.Spec.Ports[0].Port != 8000 {
+		t.Errorf("expect port of variant service is 8000, but got %d", variant.Spec.Ports[0].Port)
+	}
+	err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-shanghai"}, &variant)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expect variant service in nodepool shanghai is deleted, but got %v", err)
+	}
+}
+
+func TestReconcileWithConflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	apis.AddToScheme(scheme)
+
+	existing := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-hangzhou", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 443}}},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{apps.AnnotationServiceVariantPools: "*"},
+			},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+		},
+		existing,
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"}},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileServiceVariant{
+		Client:   c,
+		recorder: recorder,
+	}
+
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}}); err != nil {
+		t.Fatalf("could not reconcile, %v", err)
+	}
+
+	var got corev1.Service
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(existing), &got); err != nil {
+		t.Fatalf("could not get service, %v", err)
+	}
+	if got.Spec.Ports[0].Port != 443 {
+		t.Errorf("expect service not controlled by parent is untouched, but got %#+v", got.Spec)
+	}
+	if len(recorder.Events) != 1 || !strings.Contains(<-recorder.Events, "VariantConflict") {
+		t.Errorf("expect a VariantConflict event")
+	}
+}
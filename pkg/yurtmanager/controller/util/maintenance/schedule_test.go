@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	testcases := map[string]struct {
+		spec    string
+		wantErr bool
+	}{
+		"every minute":           {spec: "* * * * *"},
+		"lists, ranges and step": {spec: "*/15 1-3,22 1 1-12/2 0,6"},
+		"sunday as 7":            {spec: "0 2 * * 7"},
+		"missing fields":         {spec: "0 2 * *", wantErr: true},
+		"minute out of range":    {spec: "60 2 * * *", wantErr: true},
+		"reversed range":         {spec: "0 5-2 * * *", wantErr: true},
+		"invalid step":           {spec: "*/0 * * * *", wantErr: true},
+		"invalid value":          {spec: "0 2 * * sat", wantErr: true},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if _, err := ParseSchedule(tc.spec); (err != nil) != tc.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2023-06-14 is Wednesday
+	now := time.Date(2023, 6, 14, 10, 30, 20, 0, time.UTC)
+	testcases := map[string]struct {
+		spec   string
+		expect time.Time
+	}{
+		"next minute": {
+			spec:   "* * * * *",
+			expect: time.Date(2023, 6, 14, 10, 31, 0, 0, time.UTC),
+		},
+		"later today": {
+			spec:   "0 22 * * *",
+			expect: time.Date(2023, 6, 14, 22, 0, 0, 0, time.UTC),
+		},
+		"tomorrow": {
+			spec:   "0 2 * * *",
+			expect: time.Date(2023, 6, 15, 2, 0, 0, 0, time.UTC),
+		},
+		"weekend": {
+			spec:   "30 1 * * 6,0",
+			expect: time.Date(2023, 6, 17, 1, 30, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			spec:   "0 0 20 * 5",
+			expect: time.Date(2023, 6, 16, 0, 0, 0, 0, time.UTC),
+		},
+		"next year": {
+			spec:   "0 0 1 1 *",
+			expect: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"never": {
+			spec: "0 0 30 2 *",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec)
+			if err != nil {
+				t.Fatalf("failed to parse schedule, %v", err)
+			}
+			if got := s.Next(now); !got.Equal(tc.expect) {
+				t.Errorf("expect next time %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	// embed the time zone database, so time zones of NodePools can be loaded in minimal images
+	_ "time/tzdata"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// WindowsAnnotation is the annotation key added to workloads like DaemonSet to specify the maintenance
+// windows in json, like `[{"schedule":"0 2 * * *","duration":"2h"}]`.
+const WindowsAnnotation = "apps.openyurt.io/maintenance-windows"
+
+type window struct {
+	schedule *Schedule
+	duration time.Duration
+}
+
+// ValidateWindows checks the schedules and durations of the maintenance windows.
+func ValidateWindows(windows []appsv1alpha1.MaintenanceWindow) error {
+	_, err := parseWindows(windows)
+	return err
+}
+
+func parseWindows(windows []appsv1alpha1.MaintenanceWindow) ([]window, error) {
+	parsed := make([]window, 0, len(windows))
+	for _, w := range windows {
+		schedule, err := ParseSchedule(w.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if w.Duration.Duration <= 0 {
+			return nil, fmt.Errorf("duration of maintenance window %q must be positive", w.Schedule)
+		}
+		parsed = append(parsed, window{schedule: schedule, duration: w.Duration.Duration})
+	}
+	return parsed, nil
+}
+
+// WindowsFromAnnotations returns the maintenance windows specified by the annotation WindowsAnnotation.
+func WindowsFromAnnotations(annotations map[string]string) ([]appsv1alpha1.MaintenanceWindow, error) {
+	v, ok := annotations[WindowsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var windows []appsv1alpha1.MaintenanceWindow
+	if err := json.Unmarshal([]byte(v), &windows); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s, %v", WindowsAnnotation, err)
+	}
+	return windows, nil
+}
+
+// inWindows checks whether now is in one of the windows, and returns how long to wait until the next
+// window starts if it's not. A zero duration is returned if no window starts in the future.
+func inWindows(windows []window, now time.Time) (bool, time.Duration) {
+	var wait time.Duration
+	for _, w := range windows {
+		// the window contains now if it starts in (now-duration, now]
+		if start := w.schedule.Next(now.Add(-w.duration)); !start.IsZero() && !start.After(now) {
+			return true, 0
+		}
+		if next := w.schedule.Next(now); !next.IsZero() && (wait == 0 || next.Sub(now) < wait) {
+			wait = next.Sub(now)
+		}
+	}
+	return false, wait
+}
+
+// Checker checks whether nodes are in the maintenance windows, the windows are evaluated in the time zone
+// of the NodePool that each node belongs to, and in UTC for nodes which don't belong to any NodePool.
+type Checker struct {
+	client    client.Client
+	windows   []window
+	now       time.Time
+	locations map[string]*time.Location
+	// requeueAfter is the shortest wait until the window of a denied node starts
+	requeueAfter time.Duration
+}
+
+// NewChecker creates a Checker for the maintenance windows at the given time.
+func NewChecker(c client.Client, windows []appsv1alpha1.MaintenanceWindow, now time.Time) (*Checker, error) {
+	parsed, err := parseWindows(windows)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{
+		client:    c,
+		windows:   parsed,
+		now:       now,
+		locations: make(map[string]*time.Location),
+	}, nil
+}
+
+// Allowed checks whether the node is in the maintenance windows, it's always true if no window is specified.
+func (c *Checker) Allowed(nodeName string) (bool, error) {
+	if len(c.windows) == 0 {
+		return true, nil
+	}
+
+	loc, err := c.nodeLocation(nodeName)
+	if err != nil {
+		return false, err
+	}
+	in, wait := inWindows(c.windows, c.now.In(loc))
+	if !in && wait > 0 && (c.requeueAfter == 0 || wait < c.requeueAfter) {
+		c.requeueAfter = wait
+	}
+	return in, nil
+}
+
+// RequeueAfter returns how long to wait until the window of the earliest denied node starts,
+// zero is returned if no node is denied.
+func (c *Checker) RequeueAfter() time.Duration {
+	return c.requeueAfter
+}
+
+// nodeLocation returns the time zone of NodePool that the node belongs to.
+func (c *Checker) nodeLocation(nodeName string) (*time.Location, error) {
+	node := &corev1.Node{}
+	if err := c.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return time.UTC, nil
+		}
+		return nil, err
+	}
+
+	poolName := node.Labels[apps.NodePoolLabel]
+	if len(poolName) == 0 {
+		return time.UTC, nil
+	}
+	if loc, ok := c.locations[poolName]; ok {
+		return loc, nil
+	}
+
+	loc := time.UTC
+	np := &appsv1beta1.NodePool{}
+	if err := c.client.Get(context.TODO(), types.NamespacedName{Name: poolName}, np); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if len(np.Spec.TimeZone) != 0 {
+		if loc, err = time.LoadLocation(np.Spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone of NodePool %s, %v", poolName, err)
+		}
+	}
+	c.locations[poolName] = loc
+	return loc, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestWindowsFromAnnotations(t *testing.T) {
+	windows, err := WindowsFromAnnotations(map[string]string{
+		WindowsAnnotation: `[{"schedule":"0 2 * * *","duration":"2h"}]`,
+	})
+	if err != nil {
+		t.Fatalf("failed to get windows, %v", err)
+	}
+	if len(windows) != 1 || windows[0].Schedule != "0 2 * * *" || windows[0].Duration.Duration != 2*time.Hour {
+		t.Errorf("unexpected windows %v", windows)
+	}
+
+	if _, err := WindowsFromAnnotations(map[string]string{WindowsAnnotation: "0 2 * * *"}); err == nil {
+		t.Errorf("expect error for invalid annotation")
+	}
+}
+
+func TestCheckerAllowed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+
+	newNode := func(name, pool string) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(pool) != 0 {
+			node.Labels = map[string]string{apps.NodePoolLabel: pool}
+		}
+		return node
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNode("node-shanghai", "shanghai"),
+		newNode("node-berlin", "berlin"),
+		newNode("node-utc", "utc"),
+		newNode("node-default", ""),
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "shanghai"}, Spec: appsv1beta1.NodePoolSpec{TimeZone: "Asia/Shanghai"}},
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "berlin"}, Spec: appsv1beta1.NodePoolSpec{TimeZone: "Europe/Berlin"}},
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "utc"}},
+	).Build()
+
+	// window from 2am to 4am in the local time of each site
+	windows := []appsv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}}}
+	// 2023-06-14 19:00 UTC is 03:00 of the next day in Shanghai and 21:00 in Berlin
+	now := time.Date(2023, 6, 14, 19, 0, 0, 0, time.UTC)
+
+	checker, err := NewChecker(c, windows, now)
+	if err != nil {
+		t.Fatalf("failed to create checker, %v", err)
+	}
+	expect := map[string]bool{
+		"node-shanghai": true,
+		"node-berlin":   false,
+		"node-utc":      false,
+		"node-default":  false,
+		"node-unknown":  false,
+	}
+	for node, want := range expect {
+		if got, err := checker.Allowed(node); err != nil || got != want {
+			t.Errorf("expect node %s allowed %v, but got %v, %v", node, want, got, err)
+		}
+	}
+	// the window of Berlin starts at 00:00 UTC, which is the earliest one among denied nodes
+	if wait := checker.RequeueAfter(); wait != 5*time.Hour {
+		t.Errorf("expect requeue after 5h, but got %v", wait)
+	}
+
+	checker, _ = NewChecker(c, nil, now)
+	if allowed, _ := checker.Allowed("node-berlin"); !allowed || checker.RequeueAfter() != 0 {
+		t.Errorf("expect nodes are always allowed without windows")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+)
+
+// Phase is the phase of moving node into another NodePool.
+type Phase string
+
+const (
+	// PhaseDraining means the node is cordoned, and pool-bound pods on the node are being evicted.
+	PhaseDraining Phase = "Draining"
+	// PhaseRelabeling means the node is drained, and will be moved into the target NodePool.
+	PhaseRelabeling Phase = "Relabeling"
+	// PhaseWaitingGateway means the node is in the target NodePool, and waits for the raven
+	// gateway of the target NodePool to take over the node.
+	PhaseWaitingGateway Phase = "WaitingGateway"
+)
+
+// Status is the progress of moving node into another NodePool,
+// it is recorded in the annotation nodepool.openyurt.io/migration-status.
+type Status struct {
+	Phase      Phase  `json:"phase"`
+	SourcePool string `json:"sourcePool"`
+	TargetPool string `json:"targetPool"`
+	// Cordoned is true if the node is cordoned by migration, and will be uncordoned at the end.
+	Cordoned       bool        `json:"cordoned"`
+	PhaseStartTime metav1.Time `json:"phaseStartTime"`
+}
+
+// GetStatus returns the migration status of the node, nil is returned if the node is not in migration.
+func GetStatus(node *corev1.Node) (*Status, error) {
+	raw, ok := node.Annotations[apps.AnnotationMigrationStatus]
+	if !ok {
+		return nil, nil
+	}
+	status := &Status{}
+	if err := json.Unmarshal([]byte(raw), status); err != nil {
+		return nil, fmt.Errorf("could not decode migration status of node %s, %w", node.Name, err)
+	}
+	return status, nil
+}
+
+// IsRelabeling checks whether the node is going to be moved into the specified NodePool by migration.
+func IsRelabeling(node *corev1.Node, targetPool string) bool {
+	status, err := GetStatus(node)
+	if err != nil || status == nil {
+		return false
+	}
+	return status.Phase == PhaseRelabeling && status.TargetPool == targetPool
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	KindYurtStaticSet = "YurtStaticSet"
+	KindDaemonSet     = "DaemonSet"
+
+	// StatePending means the node is not up-to-date and the upgrade is not started.
+	StatePending = "pending"
+	// StateApproved means the upgrade of node is allowed or started, but not finished yet.
+	StateApproved = "approved"
+	// StateUpgraded means the node is running the latest pod.
+	StateUpgraded = "upgraded"
+	// StateFailed means the upgrade of node failed.
+	StateFailed = "failed"
+)
+
+// UpgradeNodes is the number of nodes in each upgrade state of workloads.
+var UpgradeNodes = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "yurt_manager",
+		Name:      "upgrade_nodes",
+		Help:      "number of nodes in each upgrade state of YurtStaticSet and DaemonSet. state: pending, approved, upgraded, failed",
+	},
+	[]string{"kind", "namespace", "name", "state"})
+
+func init() {
+	metrics.Registry.MustRegister(UpgradeNodes)
+}
+
+// Progress is the number of nodes in each upgrade state of a workload.
+type Progress struct {
+	Pending  int
+	Approved int
+	Upgraded int
+	Failed   int
+}
+
+// Record exports the upgrade progress of the workload.
+func Record(kind, namespace, name string, p Progress) {
+	UpgradeNodes.WithLabelValues(kind, namespace, name, StatePending).Set(float64(p.Pending))
+	UpgradeNodes.WithLabelValues(kind, namespace, name, StateApproved).Set(float64(p.Approved))
+	UpgradeNodes.WithLabelValues(kind, namespace, name, StateUpgraded).Set(float64(p.Upgraded))
+	UpgradeNodes.WithLabelValues(kind, namespace, name, StateFailed).Set(float64(p.Failed))
+}
+
+// Forget removes the upgrade progress of the deleted workload.
+func Forget(kind, namespace, name string) {
+	for _, state := range []string{StatePending, StateApproved, StateUpgraded, StateFailed} {
+		UpgradeNodes.DeleteLabelValues(kind, namespace, name, state)
+	}
+}
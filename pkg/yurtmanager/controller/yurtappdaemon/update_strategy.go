@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappdaemon
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappdaemon/workloadcontroller"
+)
+
+var defaultMaxUnavailable = intstr.FromInt(1)
+
+// maxUnavailableNodePools resolves the number of nodepools whose workloads can be unavailable
+// during the update, at least one nodepool is allowed so the update can always go on.
+func maxUnavailableNodePools(strategy *unitv1alpha1.YurtAppDaemonUpdateStrategy, nodePoolNum int) int {
+	maxUnavailable := &defaultMaxUnavailable
+	if strategy.MaxUnavailable != nil {
+		maxUnavailable = strategy.MaxUnavailable
+	}
+
+	num, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, nodePoolNum, true)
+	if err != nil || num < 1 {
+		return 1
+	}
+	return num
+}
+
+// sortWorkloadsByUpdateOrder sorts workloads by the order of their nodepools in UpdateStrategy,
+// and the workloads of nodepools which are not listed are sorted by nodepool name at last.
+func sortWorkloadsByUpdateOrder(strategy *unitv1alpha1.YurtAppDaemonUpdateStrategy, workloads []*workloadcontroller.Workload) {
+	order := make(map[string]int, len(strategy.NodePools))
+	for i, np := range strategy.NodePools {
+		if _, ok := order[np]; !ok {
+			order[np] = i
+		}
+	}
+	rank := func(np string) int {
+		if i, ok := order[np]; ok {
+			return i
+		}
+		return len(strategy.NodePools)
+	}
+
+	sort.SliceStable(workloads, func(i, j int) bool {
+		ri, rj := rank(workloads[i].GetNodePoolName()), rank(workloads[j].GetNodePoolName())
+		if ri != rj {
+			return ri < rj
+		}
+		return workloads[i].GetNodePoolName() < workloads[j].GetNodePoolName()
+	})
+}
+
+// filterWorkloadsByUpdateStrategy returns the workloads which are allowed to be updated now by UpdateStrategy.
+// The workloads which are already unavailable are always updated, and the available ones are updated in order
+// only if the number of unavailable nodepools doesn't exceed maxUnavailable, so the update moves on to the next
+// nodepools after the former ones are updated and ready.
+func filterWorkloadsByUpdateStrategy(instance *unitv1alpha1.YurtAppDaemon, currentNodepoolToWorkload map[string]*workloadcontroller.Workload,
+	allNameToNodePools map[string]unitv1alpha1.NodePool, needUpdate []*workloadcontroller.Workload) []*workloadcontroller.Workload {
+	strategy := instance.Spec.UpdateStrategy
+	if strategy == nil || len(needUpdate) == 0 {
+		return needUpdate
+	}
+
+	pending := make(map[string]bool, len(needUpdate))
+	for _, load := range needUpdate {
+		pending[load.GetNodePoolName()] = true
+	}
+
+	var unavailable int
+	for npName, load := range currentNodepoolToWorkload {
+		if _, ok := allNameToNodePools[npName]; !ok || pending[npName] {
+			continue
+		}
+		if !load.IsAvailable() {
+			unavailable++
+		}
+	}
+
+	sorted := make([]*workloadcontroller.Workload, len(needUpdate))
+	copy(sorted, needUpdate)
+	sortWorkloadsByUpdateOrder(strategy, sorted)
+
+	budget := maxUnavailableNodePools(strategy, len(allNameToNodePools)) - unavailable
+	var allowed []*workloadcontroller.Workload
+	for _, load := range sorted {
+		if !load.IsAvailable() {
+			allowed = append(allowed, load)
+			continue
+		}
+		if budget > 0 {
+			allowed = append(allowed, load)
+			budget--
+		}
+	}
+
+	klog.V(4).Infof("YurtAppDaemon[%s/%s] %d of %d workloads are allowed to be updated by update strategy, %d nodepools are unavailable",
+		instance.GetNamespace(), instance.GetName(), len(allowed), len(needUpdate), unavailable)
+	return allowed
+}
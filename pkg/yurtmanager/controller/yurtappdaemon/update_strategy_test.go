@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappdaemon
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappdaemon/workloadcontroller"
+)
+
+func newTestWorkload(nodepool string, available bool) *workloadcontroller.Workload {
+	w := &workloadcontroller.Workload{
+		Name: "yad-" + nodepool,
+		Spec: workloadcontroller.WorkloadSpec{
+			Ref: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "yad-" + nodepool,
+					Annotations: map[string]string{apps.AnnotationRefNodePool: nodepool},
+				},
+			},
+		},
+		Status: workloadcontroller.WorkloadStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2},
+	}
+	if !available {
+		w.Status.ReadyReplicas = 1
+	}
+	return w
+}
+
+func TestFilterWorkloadsByUpdateStrategy(t *testing.T) {
+	fiftyPercent := intstr.FromString("50%")
+	testcases := map[string]struct {
+		strategy    *unitv1alpha1.YurtAppDaemonUpdateStrategy
+		available   map[string]bool
+		needUpdate  []string
+		expectAllow []string
+	}{
+		"update all workloads without strategy": {
+			available:   map[string]bool{"a": true, "b": true, "c": true, "d": true},
+			needUpdate:  []string{"a", "b", "c"},
+			expectAllow: []string{"a", "b", "c"},
+		},
+		"update one nodepool by default": {
+			strategy:    &unitv1alpha1.YurtAppDaemonUpdateStrategy{NodePools: []string{"c"}},
+			available:   map[string]bool{"a": true, "b": true, "c": true, "d": true},
+			needUpdate:  []string{"a", "b", "c"},
+			expectAllow: []string{"c"},
+		},
+		"wait for the updated nodepool to be available": {
+			strategy:   &unitv1alpha1.YurtAppDaemonUpdateStrategy{},
+			available:  map[string]bool{"a": false, "b": true, "c": true, "d": true},
+			needUpdate: []string{"b", "c"},
+		},
+		"update nodepools by percentage": {
+			strategy:    &unitv1alpha1.YurtAppDaemonUpdateStrategy{MaxUnavailable: &fiftyPercent, NodePools: []string{"d", "c"}},
+			available:   map[string]bool{"a": true, "b": true, "c": true, "d": true},
+			needUpdate:  []string{"a", "b", "c", "d"},
+			expectAllow: []string{"d", "c"},
+		},
+		"unavailable workloads are always updated": {
+			strategy:    &unitv1alpha1.YurtAppDaemonUpdateStrategy{},
+			available:   map[string]bool{"a": false, "b": true, "c": false, "d": true},
+			needUpdate:  []string{"b", "c", "d"},
+			expectAllow: []string{"c"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			yad := &unitv1alpha1.YurtAppDaemon{
+				ObjectMeta: metav1.ObjectMeta{Name: "yad", Namespace: "default"},
+				Spec:       unitv1alpha1.YurtAppDaemonSpec{UpdateStrategy: tc.strategy},
+			}
+			currentNodepoolToWorkload := map[string]*workloadcontroller.Workload{}
+			allNameToNodePools := map[string]unitv1alpha1.NodePool{}
+			for np, available := range tc.available {
+				currentNodepoolToWorkload[np] = newTestWorkload(np, available)
+				allNameToNodePools[np] = unitv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: np}}
+			}
+			var needUpdate []*workloadcontroller.Workload
+			for _, np := range tc.needUpdate {
+				needUpdate = append(needUpdate, currentNodepoolToWorkload[np])
+			}
+
+			var got []string
+			for _, load := range filterWorkloadsByUpdateStrategy(yad, currentNodepoolToWorkload, allNameToNodePools, needUpdate) {
+				got = append(got, load.GetNodePoolName())
+			}
+			if !reflect.DeepEqual(got, tc.expectAllow) {
+				t.Errorf("expect allowed nodepools %v, but got %v", tc.expectAllow, got)
+			}
+		})
+	}
+}
+
+// fakeWorkloadControl records the nodepools of updated workloads.
+type fakeWorkloadControl struct {
+	workloadcontroller.WorkloadController
+	lock    sync.Mutex
+	updated []string
+}
+
+func (c *fakeWorkloadControl) UpdateWorkload(load *workloadcontroller.Workload, _ *unitv1alpha1.YurtAppDaemon, _ unitv1alpha1.NodePool, _ string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.updated = append(c.updated, load.GetNodePoolName())
+	return nil
+}
+
+func TestManageWorkloadsWithUpdateStrategy(t *testing.T) {
+	yad := &unitv1alpha1.YurtAppDaemon{
+		ObjectMeta: metav1.ObjectMeta{Name: "yad", Namespace: "default"},
+		Spec:       unitv1alpha1.YurtAppDaemonSpec{UpdateStrategy: &unitv1alpha1.YurtAppDaemonUpdateStrategy{}},
+	}
+	currentNodepoolToWorkload := map[string]*workloadcontroller.Workload{}
+	allNameToNodePools := map[string]unitv1alpha1.NodePool{}
+	for _, np := range []string{"a", "b", "c", "d"} {
+		currentNodepoolToWorkload[np] = newTestWorkload(np, true)
+		allNameToNodePools[np] = unitv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: np}}
+	}
+
+	control := &fakeWorkloadControl{}
+	r := &ReconcileYurtAppDaemon{
+		recorder: record.NewFakeRecorder(10),
+		controls: map[unitv1alpha1.TemplateType]workloadcontroller.WorkloadController{
+			unitv1alpha1.DeploymentTemplateType: control,
+		},
+	}
+	status, err := r.manageWorkloads(yad, currentNodepoolToWorkload, allNameToNodePools, "v2", unitv1alpha1.DeploymentTemplateType)
+	if err != nil {
+		t.Fatalf("could not manage workloads, %v", err)
+	}
+
+	// only one nodepool is allowed to be unavailable by default, so the other
+	// workloads are left for the next reconcile.
+	if expect := []string{"a"}; !reflect.DeepEqual(control.updated, expect) {
+		t.Errorf("expect updated nodepools %v, but got %v", expect, control.updated)
+	}
+	cond := GetYurtAppDaemonCondition(*status, unitv1alpha1.WorkLoadUpdated)
+	if cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != "Updating" {
+		t.Errorf("expect workloads are waiting to be updated, but got condition %v", cond)
+	}
+}
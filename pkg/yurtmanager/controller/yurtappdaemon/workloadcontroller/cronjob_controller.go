@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcontroller
+
+import (
+	"context"
+	"errors"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/refmanager"
+)
+
+// CronJobControllor manages one CronJob for each nodepool of YurtAppDaemon.
+type CronJobControllor struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (c *CronJobControllor) GetTemplateType() v1alpha1.TemplateType {
+	return v1alpha1.CronJobTemplateType
+}
+
+func (c *CronJobControllor) DeleteWorkload(yad *v1alpha1.YurtAppDaemon, load *Workload) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare delete CronJob[%s/%s]", yad.GetNamespace(),
+		yad.GetName(), load.Namespace, load.Name)
+
+	obj, ok := load.Spec.Ref.(client.Object)
+	if !ok {
+		return errors.New("fail to convert runtime.Object to client.Object")
+	}
+	return c.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
+
+// applyTemplate updates the object to the latest revision, depending on the YurtAppDaemon.
+func (c *CronJobControllor) applyTemplate(scheme *runtime.Scheme, yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string, cronJob *batchv1.CronJob) error {
+	if cronJob.Labels == nil {
+		cronJob.Labels = map[string]string{}
+	}
+	for k, v := range yad.Spec.WorkloadTemplate.CronJobTemplate.Labels {
+		cronJob.Labels[k] = v
+	}
+	for k, v := range yad.Spec.Selector.MatchLabels {
+		cronJob.Labels[k] = v
+	}
+	cronJob.Labels[apps.ControllerRevisionHashLabelKey] = revision
+	cronJob.Labels[apps.PoolNameLabelKey] = nodepool.GetName()
+
+	if cronJob.Annotations == nil {
+		cronJob.Annotations = map[string]string{}
+	}
+	for k, v := range yad.Spec.WorkloadTemplate.CronJobTemplate.Annotations {
+		cronJob.Annotations[k] = v
+	}
+	cronJob.Annotations[apps.AnnotationRefNodePool] = nodepool.GetName()
+
+	cronJob.Namespace = yad.GetNamespace()
+	cronJob.GenerateName = getWorkloadPrefix(yad.GetName(), nodepool.GetName())
+
+	cronJob.Spec = *yad.Spec.WorkloadTemplate.CronJobTemplate.Spec.DeepCopy()
+	applyNodePoolToPodTemplate(&cronJob.Spec.JobTemplate.Spec.Template, nodepool.GetName(), nodepool.Spec.Taints, revision)
+
+	return controllerutil.SetControllerReference(yad, cronJob, scheme)
+}
+
+func (c *CronJobControllor) ObjectKey(load *Workload) client.ObjectKey {
+	return types.NamespacedName{
+		Namespace: load.Namespace,
+		Name:      load.Name,
+	}
+}
+
+func (c *CronJobControllor) UpdateWorkload(load *Workload, yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare update CronJob[%s/%s]", yad.GetNamespace(),
+		yad.GetName(), load.Namespace, load.Name)
+
+	cronJob := &batchv1.CronJob{}
+	var updateError error
+	for i := 0; i < updateRetries; i++ {
+		getError := c.Client.Get(context.TODO(), c.ObjectKey(load), cronJob)
+		if getError != nil {
+			return getError
+		}
+
+		if err := c.applyTemplate(c.Scheme, yad, nodepool, revision, cronJob); err != nil {
+			return err
+		}
+		updateError = c.Client.Update(context.TODO(), cronJob)
+		if updateError == nil {
+			break
+		}
+	}
+
+	return updateError
+}
+
+func (c *CronJobControllor) CreateWorkload(yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare create new cronjob by nodepool %s ", yad.GetNamespace(), yad.GetName(), nodepool.GetName())
+
+	cronJob := batchv1.CronJob{}
+	if err := c.applyTemplate(c.Scheme, yad, nodepool, revision, &cronJob); err != nil {
+		klog.Errorf("YurtAppDaemon[%s/%s] failed to apply template, when create cronjob: %v", yad.GetNamespace(),
+			yad.GetName(), err)
+		return err
+	}
+	return c.Client.Create(context.TODO(), &cronJob)
+}
+
+func (c *CronJobControllor) GetAllWorkloads(yad *v1alpha1.YurtAppDaemon) ([]*Workload, error) {
+	allCronJobs := batchv1.CronJobList{}
+	selector, err := metav1.LabelSelectorAsSelector(yad.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Client.List(context.TODO(), &allCronJobs, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	manager, err := refmanager.New(c.Client, yad.Spec.Selector, yad, c.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]metav1.Object, 0, len(allCronJobs.Items))
+	for i := 0; i < len(allCronJobs.Items); i++ {
+		t := allCronJobs.Items[i]
+		selected = append(selected, &t)
+	}
+
+	objs, err := manager.ClaimOwnedObjects(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads := make([]*Workload, 0, len(objs))
+	for i, o := range objs {
+		cronJob := o.(*batchv1.CronJob)
+		podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+		// a suspended CronJob will not schedule any Job, so it is regarded as unavailable.
+		availableCondition := corev1.ConditionTrue
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+			availableCondition = corev1.ConditionFalse
+		}
+		w := &Workload{
+			Name:      o.GetName(),
+			Namespace: o.GetNamespace(),
+			Kind:      cronJob.Kind,
+			Spec: WorkloadSpec{
+				Ref:          objs[i],
+				NodeSelector: podSpec.NodeSelector,
+				Tolerations:  podSpec.Tolerations,
+			},
+			Status: WorkloadStatus{
+				ObservedGeneration: cronJob.Generation,
+				Replicas:           int32(len(cronJob.Status.Active)),
+				ReadyReplicas:      int32(len(cronJob.Status.Active)),
+				UpdatedReplicas:    int32(len(cronJob.Status.Active)),
+				AvailableCondition: availableCondition,
+			},
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, nil
+}
+
+var _ WorkloadController = &CronJobControllor{}
@@ -203,8 +203,10 @@ func (d *DeploymentControllor) GetAllWorkloads(yad *v1alpha1.YurtAppDaemon) ([]*
 				Tolerations:  spec.Template.Spec.Tolerations,
 			},
 			Status: WorkloadStatus{
+				ObservedGeneration: deploy.Status.ObservedGeneration,
 				Replicas:           deploy.Status.Replicas,
 				ReadyReplicas:      deploy.Status.ReadyReplicas,
+				UpdatedReplicas:    deploy.Status.UpdatedReplicas,
 				AvailableCondition: availableCondition,
 			},
 		}
@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcontroller
+
+import (
+	"context"
+	"errors"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/refmanager"
+)
+
+// JobControllor manages one Job for each nodepool of YurtAppDaemon.
+type JobControllor struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (j *JobControllor) GetTemplateType() v1alpha1.TemplateType {
+	return v1alpha1.JobTemplateType
+}
+
+func (j *JobControllor) DeleteWorkload(yad *v1alpha1.YurtAppDaemon, load *Workload) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare delete Job[%s/%s]", yad.GetNamespace(),
+		yad.GetName(), load.Namespace, load.Name)
+
+	obj, ok := load.Spec.Ref.(client.Object)
+	if !ok {
+		return errors.New("fail to convert runtime.Object to client.Object")
+	}
+	return j.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
+
+// applyTemplate updates the object to the latest revision, depending on the YurtAppDaemon.
+func (j *JobControllor) applyTemplate(scheme *runtime.Scheme, yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string, job *batchv1.Job) error {
+	if job.Labels == nil {
+		job.Labels = map[string]string{}
+	}
+	for k, v := range yad.Spec.WorkloadTemplate.JobTemplate.Labels {
+		job.Labels[k] = v
+	}
+	for k, v := range yad.Spec.Selector.MatchLabels {
+		job.Labels[k] = v
+	}
+	job.Labels[apps.ControllerRevisionHashLabelKey] = revision
+	job.Labels[apps.PoolNameLabelKey] = nodepool.GetName()
+
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	for k, v := range yad.Spec.WorkloadTemplate.JobTemplate.Annotations {
+		job.Annotations[k] = v
+	}
+	job.Annotations[apps.AnnotationRefNodePool] = nodepool.GetName()
+
+	job.Namespace = yad.GetNamespace()
+	job.GenerateName = getWorkloadPrefix(yad.GetName(), nodepool.GetName())
+
+	// selector of job is generated by kube-apiserver, so it is not set here.
+	job.Spec = *yad.Spec.WorkloadTemplate.JobTemplate.Spec.DeepCopy()
+	applyNodePoolToPodTemplate(&job.Spec.Template, nodepool.GetName(), nodepool.Spec.Taints, revision)
+
+	return controllerutil.SetControllerReference(yad, job, scheme)
+}
+
+func (j *JobControllor) ObjectKey(load *Workload) client.ObjectKey {
+	return types.NamespacedName{
+		Namespace: load.Namespace,
+		Name:      load.Name,
+	}
+}
+
+// UpdateWorkload recreates the Job with the latest revision, because the pod template
+// of a Job can not be updated.
+func (j *JobControllor) UpdateWorkload(load *Workload, yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare recreate Job[%s/%s]", yad.GetNamespace(),
+		yad.GetName(), load.Namespace, load.Name)
+
+	// the Job is created again even if it has been deleted by others
+	if err := j.DeleteWorkload(yad, load); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return j.CreateWorkload(yad, nodepool, revision)
+}
+
+func (j *JobControllor) CreateWorkload(yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) error {
+	klog.Infof("YurtAppDaemon[%s/%s] prepare create new job by nodepool %s ", yad.GetNamespace(), yad.GetName(), nodepool.GetName())
+
+	job := batchv1.Job{}
+	if err := j.applyTemplate(j.Scheme, yad, nodepool, revision, &job); err != nil {
+		klog.Errorf("YurtAppDaemon[%s/%s] failed to apply template, when create job: %v", yad.GetNamespace(),
+			yad.GetName(), err)
+		return err
+	}
+	return j.Client.Create(context.TODO(), &job)
+}
+
+func (j *JobControllor) GetAllWorkloads(yad *v1alpha1.YurtAppDaemon) ([]*Workload, error) {
+	allJobs := batchv1.JobList{}
+	selector, err := metav1.LabelSelectorAsSelector(yad.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.Client.List(context.TODO(), &allJobs, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	manager, err := refmanager.New(j.Client, yad.Spec.Selector, yad, j.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]metav1.Object, 0, len(allJobs.Items))
+	for i := 0; i < len(allJobs.Items); i++ {
+		t := allJobs.Items[i]
+		selected = append(selected, &t)
+	}
+
+	objs, err := manager.ClaimOwnedObjects(selected)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads := make([]*Workload, 0, len(objs))
+	for i, o := range objs {
+		job := o.(*batchv1.Job)
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		w := &Workload{
+			Name:      o.GetName(),
+			Namespace: o.GetNamespace(),
+			Kind:      job.Kind,
+			Spec: WorkloadSpec{
+				Ref:          objs[i],
+				NodeSelector: job.Spec.Template.Spec.NodeSelector,
+				Tolerations:  job.Spec.Template.Spec.Tolerations,
+			},
+			Status: WorkloadStatus{
+				ObservedGeneration: job.Generation,
+				Replicas:           completions,
+				ReadyReplicas:      job.Status.Succeeded,
+				UpdatedReplicas:    completions,
+				AvailableCondition: jobAvailableCondition(job),
+			},
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads, nil
+}
+
+// jobAvailableCondition regards a completed Job as available and a failed Job as unavailable.
+func jobAvailableCondition(job *batchv1.Job) corev1.ConditionStatus {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return corev1.ConditionTrue
+		case batchv1.JobFailed:
+			return corev1.ConditionFalse
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+var _ WorkloadController = &JobControllor{}
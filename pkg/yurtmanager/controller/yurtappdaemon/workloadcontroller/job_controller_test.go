@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcontroller
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclint "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func newJobTestYurtAppDaemon() *v1alpha1.YurtAppDaemon {
+	return &v1alpha1.YurtAppDaemon{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-system",
+			Name:      "yad",
+			UID:       "yad-uid",
+		},
+		Spec: v1alpha1.YurtAppDaemonSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "demo"},
+			},
+			WorkloadTemplate: v1alpha1.WorkloadTemplate{
+				JobTemplate: &v1alpha1.JobTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{"app": "demo"},
+					},
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								RestartPolicy: corev1.RestartPolicyNever,
+								Containers:    []corev1.Container{{Name: "demo", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestJobApplyTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes clint-go custom resource, %v", err)
+	}
+	jc := JobControllor{
+		Client: fakeclint.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	nodepool := v1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Spec: v1alpha1.NodePoolSpec{
+			Taints: []corev1.Taint{{Key: "edge", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	job := &batchv1.Job{}
+	if err := jc.applyTemplate(scheme, newJobTestYurtAppDaemon(), nodepool, "v1", job); err != nil {
+		t.Fatalf("failed to apply template, %v", err)
+	}
+
+	if job.Labels[apps.PoolNameLabelKey] != "hangzhou" || job.Labels[apps.ControllerRevisionHashLabelKey] != "v1" || job.Labels["app"] != "demo" {
+		t.Errorf("unexpected labels of job, %v", job.Labels)
+	}
+	if job.Spec.Selector != nil {
+		t.Errorf("expect selector of job is not set, but got %v", job.Spec.Selector)
+	}
+	if job.Spec.Template.Spec.NodeSelector[apps.NodePoolLabel] != "hangzhou" {
+		t.Errorf("unexpected node selector of job, %v", job.Spec.Template.Spec.NodeSelector)
+	}
+	if len(job.Spec.Template.Spec.Tolerations) != 1 || job.Spec.Template.Spec.Tolerations[0].Key != "edge" {
+		t.Errorf("unexpected tolerations of job, %v", job.Spec.Template.Spec.Tolerations)
+	}
+	if len(job.OwnerReferences) != 1 || job.OwnerReferences[0].Name != "yad" {
+		t.Errorf("unexpected owner references of job, %v", job.OwnerReferences)
+	}
+}
+
+func TestJobUpdateWorkload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes clint-go custom resource, %v", err)
+	}
+	jc := JobControllor{
+		Client: fakeclint.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+
+	// the Job has been deleted by others, so it's created directly.
+	yad := newJobTestYurtAppDaemon()
+	deleted := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "yad-hangzhou-abcde", Namespace: yad.Namespace}}
+	load := &Workload{Name: deleted.Name, Namespace: deleted.Namespace, Spec: WorkloadSpec{Ref: deleted}}
+	nodepool := v1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"}}
+	if err := jc.UpdateWorkload(load, yad, nodepool, "v2"); err != nil {
+		t.Fatalf("failed to update workload, %v", err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := jc.Client.List(context.TODO(), jobs); err != nil {
+		t.Fatalf("failed to list jobs, %v", err)
+	}
+	if len(jobs.Items) != 1 || jobs.Items[0].Labels[apps.ControllerRevisionHashLabelKey] != "v2" {
+		t.Errorf("expect job of revision v2 is created, but got %v", jobs.Items)
+	}
+}
+
+func TestJobAvailableCondition(t *testing.T) {
+	testcases := map[string]struct {
+		conditions []batchv1.JobCondition
+		expect     corev1.ConditionStatus
+	}{
+		"job is running": {
+			expect: corev1.ConditionUnknown,
+		},
+		"job is completed": {
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			expect:     corev1.ConditionTrue,
+		},
+		"job is failed": {
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+			expect:     corev1.ConditionFalse,
+		},
+		"job is not failed": {
+			conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionFalse}},
+			expect:     corev1.ConditionUnknown,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: tc.conditions}}
+			if got := jobAvailableCondition(job); got != tc.expect {
+				t.Errorf("expect condition %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+}
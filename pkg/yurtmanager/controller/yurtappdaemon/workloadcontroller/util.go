@@ -51,3 +51,22 @@ func TaintsToTolerations(taints []corev1.Taint) []corev1.Toleration {
 	}
 	return tolerations
 }
+
+// applyNodePoolToPodTemplate makes pods of the template be scheduled to the nodes of nodepool,
+// and labels pods with the nodepool name and revision.
+func applyNodePoolToPodTemplate(template *corev1.PodTemplateSpec, nodepoolName string, taints []corev1.Taint, revision string) {
+	// set RequiredDuringSchedulingIgnoredDuringExecution nil
+	if template.Spec.Affinity != nil && template.Spec.Affinity.NodeAffinity != nil &&
+		template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = nil
+	}
+
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[apps.PoolNameLabelKey] = nodepoolName
+	template.Labels[apps.ControllerRevisionHashLabelKey] = revision
+
+	template.Spec.NodeSelector = CreateNodeSelectorByNodepoolName(nodepoolName)
+	template.Spec.Tolerations = append(template.Spec.Tolerations, TaintsToTolerations(taints)...)
+}
@@ -40,8 +40,10 @@ type WorkloadSpec struct {
 
 // WorkloadStatus stores the observed state of the Workload.
 type WorkloadStatus struct {
+	ObservedGeneration int64
 	Replicas           int32
 	ReadyReplicas      int32
+	UpdatedReplicas    int32
 	AvailableCondition corev1.ConditionStatus
 }
 
@@ -64,3 +66,11 @@ func (w *Workload) GetNodeSelector() map[string]string {
 func (w *Workload) GetKind() string {
 	return w.Kind
 }
+
+// IsAvailable checks the latest spec of workload is observed, and all the replicas are updated and ready.
+func (w *Workload) IsAvailable() bool {
+	if w.Status.ObservedGeneration < w.Spec.Ref.GetGeneration() {
+		return false
+	}
+	return w.Status.UpdatedReplicas >= w.Status.Replicas && w.Status.ReadyReplicas >= w.Status.Replicas
+}
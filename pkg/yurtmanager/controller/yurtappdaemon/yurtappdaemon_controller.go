@@ -23,6 +23,7 @@ import (
 	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -101,6 +102,18 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+
+	// Watch for changes to workloads, so the update goes on to the next nodepools
+	// when the workloads of former nodepools become available.
+	for _, workload := range []client.Object{&appsv1.Deployment{}, &batchv1.Job{}} {
+		err = c.Watch(&source.Kind{Type: workload}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &unitv1alpha1.YurtAppDaemon{},
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -124,6 +137,8 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		controls: map[unitv1alpha1.TemplateType]workloadcontroller.WorkloadController{
 			//			unitv1alpha1.StatefulSetTemplateType: &StatefulSetControllor{Client: mgr.GetClient(), scheme: mgr.GetScheme()},
 			unitv1alpha1.DeploymentTemplateType: &workloadcontroller.DeploymentControllor{Client: mgr.GetClient(), Scheme: mgr.GetScheme()},
+			unitv1alpha1.JobTemplateType:        &workloadcontroller.JobControllor{Client: mgr.GetClient(), Scheme: mgr.GetScheme()},
+			unitv1alpha1.CronJobTemplateType:    &workloadcontroller.CronJobControllor{Client: mgr.GetClient(), Scheme: mgr.GetScheme()},
 		},
 	}
 }
@@ -134,6 +149,8 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
@@ -315,21 +332,25 @@ func (r *ReconcileYurtAppDaemon) manageWorkloads(instance *unitv1alpha1.YurtAppD
 		SetYurtAppDaemonCondition(newStatus, NewYurtAppDaemonCondition(unitv1alpha1.WorkLoadProvisioned, corev1.ConditionTrue, "", ""))
 	}
 
-	if len(needUpdate) > 0 {
-		_, updateErr = util.SlowStartBatch(len(needUpdate), slowStartInitialBatchSize, func(index int) error {
-			u := needUpdate[index]
+	allowedUpdate := filterWorkloadsByUpdateStrategy(instance, currentNodepoolToWorkload, allNameToNodePools, needUpdate)
+	if len(allowedUpdate) > 0 {
+		_, updateErr = util.SlowStartBatch(len(allowedUpdate), slowStartInitialBatchSize, func(index int) error {
+			u := allowedUpdate[index]
 			updateWorkloadErr := r.controls[templateType].UpdateWorkload(u, instance, allNameToNodePools[u.GetNodePoolName()], expectedRevision)
 			if updateWorkloadErr != nil {
 				r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed %s", eventTypeWorkloadsUpdated),
 					fmt.Sprintf("Error updating workload type(%s) %s when updating: %s", templateType, u.Name, updateWorkloadErr))
 				klog.Errorf("YurtAppDaemon[%s/%s] update workload[%s/%s/%s] error %v", instance.GetNamespace(), instance.GetName(),
-					templateType, u.Namespace, u.Name, err)
+					templateType, u.Namespace, u.Name, updateWorkloadErr)
 			}
 			return updateWorkloadErr
 		})
 	}
 
-	if updateErr == nil {
+	if updateErr == nil && len(allowedUpdate) < len(needUpdate) {
+		SetYurtAppDaemonCondition(newStatus, NewYurtAppDaemonCondition(unitv1alpha1.WorkLoadUpdated, corev1.ConditionFalse, "Updating",
+			fmt.Sprintf("%d workloads are waiting to be updated by update strategy", len(needUpdate)-len(allowedUpdate))))
+	} else if updateErr == nil {
 		SetYurtAppDaemonCondition(newStatus, NewYurtAppDaemonCondition(unitv1alpha1.WorkLoadUpdated, corev1.ConditionTrue, "", ""))
 	} else {
 		SetYurtAppDaemonCondition(newStatus, NewYurtAppDaemonCondition(unitv1alpha1.WorkLoadUpdated, corev1.ConditionFalse, "Error", updateErr.Error()))
@@ -468,10 +489,15 @@ func (r *ReconcileYurtAppDaemon) getTemplateControls(instance *unitv1alpha1.Yurt
 		return r.controls[unitv1alpha1.StatefulSetTemplateType], unitv1alpha1.StatefulSetTemplateType, nil
 	case instance.Spec.WorkloadTemplate.DeploymentTemplate != nil:
 		return r.controls[unitv1alpha1.DeploymentTemplateType], unitv1alpha1.DeploymentTemplateType, nil
+	case instance.Spec.WorkloadTemplate.JobTemplate != nil:
+		return r.controls[unitv1alpha1.JobTemplateType], unitv1alpha1.JobTemplateType, nil
+	case instance.Spec.WorkloadTemplate.CronJobTemplate != nil:
+		return r.controls[unitv1alpha1.CronJobTemplateType], unitv1alpha1.CronJobTemplateType, nil
 	default:
 		klog.Errorf("The appropriate WorkloadTemplate was not found")
-		return nil, "", fmt.Errorf("The appropriate WorkloadTemplate was not found, Now Support(%s/%s)",
-			unitv1alpha1.StatefulSetTemplateType, unitv1alpha1.DeploymentTemplateType)
+		return nil, "", fmt.Errorf("The appropriate WorkloadTemplate was not found, Now Support(%s/%s/%s/%s)",
+			unitv1alpha1.StatefulSetTemplateType, unitv1alpha1.DeploymentTemplateType,
+			unitv1alpha1.JobTemplateType, unitv1alpha1.CronJobTemplateType)
 	}
 }
 
@@ -40,7 +40,7 @@ type Adapter interface {
 	// GetAvailableStatus returns the available condition status of the workload
 	GetAvailableStatus(set metav1.Object) (conditionStatus corev1.ConditionStatus, err error)
 	// GetPoolFailure returns failure information of the pool.
-	GetPoolFailure() *string
+	GetPoolFailure(pool metav1.Object) *string
 	// ApplyPoolTemplate updates the pool to the latest revision.
 	ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName, revision string, replicas int32, pool runtime.Object) error
 	// IsExpected checks the pool is the expected revision or not.
@@ -48,9 +48,12 @@ type Adapter interface {
 	IsExpected(pool metav1.Object, revision string) bool
 	// PostUpdate does some works after pool updated
 	PostUpdate(yas *alpha1.YurtAppSet, pool runtime.Object, revision string) error
+	// CanAdopt checks the workload which is not owned by YurtAppSet can be adopted as the pool.
+	CanAdopt(yas *alpha1.YurtAppSet, poolName string, pool metav1.Object) bool
 }
 
 type ReplicasInfo struct {
-	Replicas      int32
-	ReadyReplicas int32
+	Replicas        int32
+	ReadyReplicas   int32
+	UpdatedReplicas int32
 }
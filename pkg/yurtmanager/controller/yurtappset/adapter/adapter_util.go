@@ -18,18 +18,33 @@ limitations under the License.
 package adapter
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/klog/v2"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
 )
 
 func getPoolPrefix(controllerName, poolName string) string {
@@ -40,6 +55,49 @@ func getPoolPrefix(controllerName, poolName string) string {
 	return prefix
 }
 
+// maxStatefulSetNameLength leaves room for the suffix of controller revision hash label,
+// which is in the format '<statefulset-name>-<hash>' and limited to 63 characters.
+const maxStatefulSetNameLength = 52
+
+// getStatefulSetName returns the stable name of StatefulSet in the format '<controller-name>-<pool-name>',
+// empty string is returned if the name is too long or invalid, and the name prefix should be used instead.
+func getStatefulSetName(controllerName, poolName string) string {
+	name := fmt.Sprintf("%s-%s", controllerName, poolName)
+	if len(name) > maxStatefulSetNameLength || len(validation.NameIsDNSLabel(name, false)) != 0 {
+		return ""
+	}
+	return name
+}
+
+// mergeVolumeClaimTemplates returns the volumeClaimTemplates of pool, the templates of pool
+// override the templates with the same name in workload template, and the others are appended.
+func mergeVolumeClaimTemplates(templates, poolTemplates []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	if len(poolTemplates) == 0 {
+		return templates
+	}
+
+	merged := make([]corev1.PersistentVolumeClaim, 0, len(templates)+len(poolTemplates))
+	overridden := make(map[string]bool, len(poolTemplates))
+	for i := range templates {
+		claim := templates[i]
+		for j := range poolTemplates {
+			if poolTemplates[j].Name == claim.Name {
+				claim = poolTemplates[j]
+				overridden[claim.Name] = true
+				break
+			}
+		}
+		merged = append(merged, *claim.DeepCopy())
+	}
+
+	for i := range poolTemplates {
+		if !overridden[poolTemplates[i].Name] {
+			merged = append(merged, *poolTemplates[i].DeepCopy())
+		}
+	}
+	return merged
+}
+
 func attachNodeAffinityAndTolerations(podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) {
 	attachNodeAffinity(podSpec, pool)
 	attachTolerations(podSpec, pool)
@@ -74,6 +132,367 @@ func attachNodeAffinity(podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) {
 	}
 }
 
+// attachNodePoolConstraints adds the operating systems and architectures allowed by the NodePool
+// of pool into the required node affinity, so pods of the pool will not be scheduled onto
+// the nodes which are flagged for violating the constraints.
+func attachNodePoolConstraints(c client.Client, podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) error {
+	np, err := getPoolNodePool(c, pool)
+	if err != nil || np == nil {
+		return err
+	}
+	constraints := np.Spec.Constraints
+	if constraints == nil {
+		return nil
+	}
+
+	var expressions []corev1.NodeSelectorRequirement
+	if len(constraints.OperatingSystems) != 0 {
+		expressions = append(expressions, corev1.NodeSelectorRequirement{
+			Key:      corev1.LabelOSStable,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   constraints.OperatingSystems,
+		})
+	}
+	if len(constraints.Architectures) != 0 {
+		expressions = append(expressions, corev1.NodeSelectorRequirement{
+			Key:      corev1.LabelArchStable,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   constraints.Architectures,
+		})
+	}
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	attachNodeAffinity(podSpec, &appsv1alpha1.Pool{
+		NodeSelectorTerm: corev1.NodeSelectorTerm{MatchExpressions: expressions},
+	})
+	return nil
+}
+
+// attachNodePoolSelectorAndTolerations injects the nodeSelector matching the NodePool of pool when the
+// pool declares no nodeSelectorTerm, and tolerates the taints of the NodePool, so the users don't need
+// to maintain them in the workloadTemplate.
+func attachNodePoolSelectorAndTolerations(c client.Client, podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) error {
+	if len(pool.NodeSelectorTerm.MatchExpressions) == 0 && len(pool.NodeSelectorTerm.MatchFields) == 0 {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = map[string]string{}
+		}
+		podSpec.NodeSelector[apps.NodePoolLabel] = pool.Name
+	}
+
+	np, err := getPoolNodePool(c, pool)
+	if err != nil || np == nil {
+		return err
+	}
+
+	for i := range np.Spec.Taints {
+		if isTaintTolerated(podSpec.Tolerations, &np.Spec.Taints[i]) {
+			continue
+		}
+		podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{
+			Key:      np.Spec.Taints[i].Key,
+			Operator: corev1.TolerationOpExists,
+			Effect:   np.Spec.Taints[i].Effect,
+		})
+	}
+	return nil
+}
+
+// env vars injected into containers of pool when gateway config injection is enabled.
+const (
+	EnvGatewayName            = "OPENYURT_GATEWAY_NAME"
+	EnvGatewayTunnelEndpoints = "OPENYURT_GATEWAY_TUNNEL_ENDPOINTS"
+	EnvGatewayProxyEndpoints  = "OPENYURT_GATEWAY_PROXY_ENDPOINTS"
+	EnvGatewayProxyHTTPPort   = "OPENYURT_GATEWAY_PROXY_HTTP_PORT"
+	EnvGatewayProxyHTTPSPort  = "OPENYURT_GATEWAY_PROXY_HTTPS_PORT"
+)
+
+// attachGatewayConfig injects the raven gateway of the NodePool of pool into the containers as env vars
+// when YurtAppSet opts in by annotation, so edge apps which dial the cloud through the gateway don't need
+// to hardcode the addresses. The env vars declared in the workloadTemplate are kept as they are, and the
+// hash of injected config is recorded on the workload to find out the workloads need to be refreshed.
+func attachGatewayConfig(c client.Client, yas *appsv1alpha1.YurtAppSet, obj metav1.Object, podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) error {
+	envs, err := poolGatewayEnvs(c, yas, pool)
+	if err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	if len(envs) == 0 {
+		delete(annotations, apps.AnnotationGatewayConfigHash)
+		obj.SetAnnotations(annotations)
+		return nil
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[apps.AnnotationGatewayConfigHash] = hashEnvs(envs)
+	obj.SetAnnotations(annotations)
+
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Env = mergeEnvs(podSpec.InitContainers[i].Env, envs)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = mergeEnvs(podSpec.Containers[i].Env, envs)
+	}
+	return nil
+}
+
+// GatewayConfigHash returns the hash of gateway config which should be injected into the workload of pool,
+// empty string is returned if no gateway config is injected.
+func GatewayConfigHash(c client.Client, yas *appsv1alpha1.YurtAppSet, pool *appsv1alpha1.Pool) (string, error) {
+	envs, err := poolGatewayEnvs(c, yas, pool)
+	if err != nil || len(envs) == 0 {
+		return "", err
+	}
+	return hashEnvs(envs), nil
+}
+
+// poolGatewayEnvs returns the env vars of the raven gateway of the NodePool of pool, nil is returned
+// if YurtAppSet doesn't opt in, or the NodePool has no gateway.
+func poolGatewayEnvs(c client.Client, yas *appsv1alpha1.YurtAppSet, pool *appsv1alpha1.Pool) ([]corev1.EnvVar, error) {
+	if yas.Annotations[apps.AnnotationInjectGatewayConfig] != "true" {
+		return nil, nil
+	}
+
+	np, err := getPoolNodePool(c, pool)
+	if err != nil || np == nil || len(np.Spec.Gateway) == 0 {
+		return nil, err
+	}
+
+	var gw ravenv1beta1.Gateway
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: np.Spec.Gateway}, &gw); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("gateway %s of pool %s is not found, skip injecting gateway config", np.Spec.Gateway, pool.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return gatewayEnvs(&gw), nil
+}
+
+func hashEnvs(envs []corev1.EnvVar) string {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, envs)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// gatewayEnvs returns the env vars which record the name, active endpoints and proxy ports of gateway.
+func gatewayEnvs(gw *ravenv1beta1.Gateway) []corev1.EnvVar {
+	var tunnelEndpoints, proxyEndpoints []string
+	for _, ep := range gw.Status.ActiveEndpoints {
+		if ep == nil || len(ep.PublicIP) == 0 {
+			continue
+		}
+		addr := ep.PublicIP
+		if ep.Port != 0 {
+			addr = net.JoinHostPort(ep.PublicIP, strconv.Itoa(ep.Port))
+		}
+		switch ep.Type {
+		case ravenv1beta1.Tunnel:
+			tunnelEndpoints = append(tunnelEndpoints, addr)
+		case ravenv1beta1.Proxy:
+			proxyEndpoints = append(proxyEndpoints, addr)
+		}
+	}
+	sort.Strings(tunnelEndpoints)
+	sort.Strings(proxyEndpoints)
+
+	envs := []corev1.EnvVar{
+		{Name: EnvGatewayName, Value: gw.Name},
+		{Name: EnvGatewayTunnelEndpoints, Value: strings.Join(tunnelEndpoints, ",")},
+		{Name: EnvGatewayProxyEndpoints, Value: strings.Join(proxyEndpoints, ",")},
+	}
+	if len(gw.Spec.ProxyConfig.ProxyHTTPPort) != 0 {
+		envs = append(envs, corev1.EnvVar{Name: EnvGatewayProxyHTTPPort, Value: gw.Spec.ProxyConfig.ProxyHTTPPort})
+	}
+	if len(gw.Spec.ProxyConfig.ProxyHTTPSPort) != 0 {
+		envs = append(envs, corev1.EnvVar{Name: EnvGatewayProxyHTTPSPort, Value: gw.Spec.ProxyConfig.ProxyHTTPSPort})
+	}
+	return envs
+}
+
+// mergeEnvs appends the envs which are not declared in the container.
+func mergeEnvs(containerEnvs, envs []corev1.EnvVar) []corev1.EnvVar {
+	declared := make(map[string]bool, len(containerEnvs))
+	for _, env := range containerEnvs {
+		declared[env.Name] = true
+	}
+	for _, env := range envs {
+		if !declared[env.Name] {
+			containerEnvs = append(containerEnvs, env)
+		}
+	}
+	return containerEnvs
+}
+
+// PoolObjectName returns the name of object instantiated for pool from the config template or bundle template.
+func PoolObjectName(yasName, poolName, templateName string) string {
+	return fmt.Sprintf("%s-%s-%s", yasName, poolName, templateName)
+}
+
+// PoolConfigVariables are the variables of pool which can be referenced by config templates.
+type PoolConfigVariables struct {
+	PoolName     string
+	NodePoolName string
+	Region       string
+	Zone         string
+	Site         string
+	Gateway      string
+	GatewayIP    string
+}
+
+// RenderConfigTemplate renders the data of config template with the variables of pool.
+func RenderConfigTemplate(tmpl *appsv1alpha1.PoolConfigTemplate, vars *PoolConfigVariables) (map[string]string, error) {
+	data := make(map[string]string, len(tmpl.Data))
+	for key, value := range tmpl.Data {
+		t, err := template.New(key).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse key %s of config template %s: %v", key, tmpl.Name, err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, vars); err != nil {
+			return nil, fmt.Errorf("fail to render key %s of config template %s: %v", key, tmpl.Name, err)
+		}
+		data[key] = buf.String()
+	}
+	return data, nil
+}
+
+// attachConfigTemplates adds the ConfigMaps or Secrets rendered for pool to podSpec as volumes,
+// and mounts them into containers when the mount path of template is specified.
+func attachConfigTemplates(yas *appsv1alpha1.YurtAppSet, podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) {
+	for _, tmpl := range yas.Spec.ConfigTemplates {
+		volume := corev1.Volume{Name: tmpl.Name}
+		configName := PoolObjectName(yas.Name, pool.Name, tmpl.Name)
+		if tmpl.Kind == appsv1alpha1.SecretConfigTemplateKind {
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: configName}
+		} else {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: configName},
+			}
+		}
+
+		replaced := false
+		for i := range podSpec.Volumes {
+			if podSpec.Volumes[i].Name == tmpl.Name {
+				podSpec.Volumes[i] = volume
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			podSpec.Volumes = append(podSpec.Volumes, volume)
+		}
+
+		if len(tmpl.MountPath) == 0 {
+			continue
+		}
+		mount := corev1.VolumeMount{Name: tmpl.Name, MountPath: tmpl.MountPath, ReadOnly: true}
+		for i := range podSpec.InitContainers {
+			podSpec.InitContainers[i].VolumeMounts = mergeVolumeMount(podSpec.InitContainers[i].VolumeMounts, mount)
+		}
+		for i := range podSpec.Containers {
+			podSpec.Containers[i].VolumeMounts = mergeVolumeMount(podSpec.Containers[i].VolumeMounts, mount)
+		}
+	}
+}
+
+// attachBundleReferences replaces the references to ConfigMaps of bundle in podSpec with the ConfigMaps of pool.
+func attachBundleReferences(yas *appsv1alpha1.YurtAppSet, podSpec *corev1.PodSpec, pool *appsv1alpha1.Pool) {
+	names := map[string]string{}
+	for _, tmpl := range yas.Spec.Bundle {
+		if tmpl.ConfigMapTemplate != nil {
+			names[tmpl.Name] = PoolObjectName(yas.Name, pool.Name, tmpl.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	replace := func(name *string) {
+		if poolName, ok := names[*name]; ok {
+			*name = poolName
+		}
+	}
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		if volume.ConfigMap != nil {
+			replace(&volume.ConfigMap.Name)
+		}
+		if volume.Projected != nil {
+			for j := range volume.Projected.Sources {
+				if source := volume.Projected.Sources[j].ConfigMap; source != nil {
+					replace(&source.Name)
+				}
+			}
+		}
+	}
+
+	containers := make([]*corev1.Container, 0, len(podSpec.InitContainers)+len(podSpec.Containers))
+	for i := range podSpec.InitContainers {
+		containers = append(containers, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		containers = append(containers, &podSpec.Containers[i])
+	}
+	for _, container := range containers {
+		for j := range container.EnvFrom {
+			if ref := container.EnvFrom[j].ConfigMapRef; ref != nil {
+				replace(&ref.Name)
+			}
+		}
+		for j := range container.Env {
+			if from := container.Env[j].ValueFrom; from != nil && from.ConfigMapKeyRef != nil {
+				replace(&from.ConfigMapKeyRef.Name)
+			}
+		}
+	}
+}
+
+// mergeVolumeMount appends mount if the volume is not mounted by the container.
+func mergeVolumeMount(mounts []corev1.VolumeMount, mount corev1.VolumeMount) []corev1.VolumeMount {
+	for _, m := range mounts {
+		if m.Name == mount.Name {
+			return mounts
+		}
+	}
+	return append(mounts, mount)
+}
+
+func isTaintTolerated(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPoolNodePool returns the NodePool selected by the pool, nil is returned if the NodePool is not found.
+func getPoolNodePool(c client.Client, pool *appsv1alpha1.Pool) (*appsv1beta1.NodePool, error) {
+	var np appsv1beta1.NodePool
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: PoolNodePoolName(pool)}, &np); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &np, nil
+}
+
+// PoolNodePoolName returns the NodePool selected by the pool, the NodePool is selected by
+// the match expression on label apps.openyurt.io/nodepool, or has the same name as the pool.
+func PoolNodePoolName(pool *appsv1alpha1.Pool) string {
+	for _, expr := range pool.NodeSelectorTerm.MatchExpressions {
+		if expr.Key == apps.NodePoolLabel && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+			return expr.Values[0]
+		}
+	}
+	return pool.Name
+}
+
 func attachTolerations(podSpec *corev1.PodSpec, poolConfig *appsv1alpha1.Pool) {
 
 	if poolConfig.Tolerations == nil {
@@ -19,14 +19,19 @@ package adapter
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
 )
 
 func TestGetCurrentPartitionForStrategyOnDelete(t *testing.T) {
@@ -168,3 +173,444 @@ func TestCreateNewPatchedObject(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachNodePoolConstraints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	arm64Pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "arm64-pool"},
+		Spec: appsv1beta1.NodePoolSpec{
+			Constraints: &appsv1beta1.NodePoolConstraints{
+				OperatingSystems: []string{"linux"},
+				Architectures:    []string{"arm64"},
+			},
+		},
+	}
+	plainPool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "plain-pool"},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(arm64Pool, plainPool).Build()
+
+	poolExpression := corev1.NodeSelectorRequirement{
+		Key:      unitv1alpha1.NodePoolLabel,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"arm64-pool"},
+	}
+	testcases := map[string]struct {
+		pool   *appsv1alpha1.Pool
+		wanted []corev1.NodeSelectorRequirement
+	}{
+		"pool selects nodepool by label": {
+			pool: &appsv1alpha1.Pool{
+				Name:             "arm",
+				NodeSelectorTerm: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{poolExpression}},
+			},
+			wanted: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelOSStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}},
+				{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}},
+			},
+		},
+		"pool has the same name as nodepool": {
+			pool: &appsv1alpha1.Pool{Name: "arm64-pool"},
+			wanted: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelOSStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"linux"}},
+				{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}},
+			},
+		},
+		"nodepool without constraints": {
+			pool: &appsv1alpha1.Pool{Name: "plain-pool"},
+		},
+		"nodepool is not found": {
+			pool: &appsv1alpha1.Pool{Name: "unknown"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{}
+			if err := attachNodePoolConstraints(c, podSpec, tc.pool); err != nil {
+				t.Fatalf("failed to attach constraints, %v", err)
+			}
+
+			var got []corev1.NodeSelectorRequirement
+			if podSpec.Affinity != nil {
+				got = podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
+			}
+			if !reflect.DeepEqual(got, tc.wanted) {
+				t.Errorf("expect match expressions %v, but got %v", tc.wanted, got)
+			}
+		})
+	}
+}
+
+func TestAttachNodePoolSelectorAndTolerations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	taintedPool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "tainted-pool"},
+		Spec: appsv1beta1.NodePoolSpec{
+			Taints: []corev1.Taint{
+				{Key: "edge", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(taintedPool).Build()
+
+	testcases := map[string]struct {
+		pool              *appsv1alpha1.Pool
+		tolerations       []corev1.Toleration
+		wantNodeSelector  map[string]string
+		wantTolerationKey []string
+	}{
+		"pool without nodeSelectorTerm": {
+			pool:              &appsv1alpha1.Pool{Name: "tainted-pool"},
+			wantNodeSelector:  map[string]string{unitv1alpha1.NodePoolLabel: "tainted-pool"},
+			wantTolerationKey: []string{"edge", "gpu"},
+		},
+		"pool with nodeSelectorTerm": {
+			pool: &appsv1alpha1.Pool{
+				Name: "hangzhou",
+				NodeSelectorTerm: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: unitv1alpha1.NodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"tainted-pool"}},
+				}},
+			},
+			wantTolerationKey: []string{"edge", "gpu"},
+		},
+		"taint is tolerated by template": {
+			pool:              &appsv1alpha1.Pool{Name: "tainted-pool"},
+			tolerations:       []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+			wantNodeSelector:  map[string]string{unitv1alpha1.NodePoolLabel: "tainted-pool"},
+			wantTolerationKey: []string{"gpu", "edge"},
+		},
+		"nodepool is not found": {
+			pool:             &appsv1alpha1.Pool{Name: "unknown"},
+			wantNodeSelector: map[string]string{unitv1alpha1.NodePoolLabel: "unknown"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Tolerations: tc.tolerations}
+			if err := attachNodePoolSelectorAndTolerations(c, podSpec, tc.pool); err != nil {
+				t.Fatalf("failed to attach nodeSelector and tolerations, %v", err)
+			}
+
+			if !reflect.DeepEqual(podSpec.NodeSelector, tc.wantNodeSelector) {
+				t.Errorf("expect nodeSelector %v, but got %v", tc.wantNodeSelector, podSpec.NodeSelector)
+			}
+			var keys []string
+			for _, toleration := range podSpec.Tolerations {
+				keys = append(keys, toleration.Key)
+			}
+			if !reflect.DeepEqual(keys, tc.wantTolerationKey) {
+				t.Errorf("expect tolerations %v, but got %v", tc.wantTolerationKey, keys)
+			}
+		})
+	}
+}
+
+func TestAttachGatewayConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := ravenv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add raven custom resource, %v", err)
+	}
+	np := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Spec:       appsv1beta1.NodePoolSpec{Gateway: "gw-hangzhou"},
+	}
+	gw := &ravenv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw-hangzhou"},
+		Spec: ravenv1beta1.GatewaySpec{
+			ProxyConfig: ravenv1beta1.ProxyConfiguration{ProxyHTTPPort: "10255"},
+		},
+		Status: ravenv1beta1.GatewayStatus{
+			ActiveEndpoints: []*ravenv1beta1.Endpoint{
+				{NodeName: "node-b", Type: ravenv1beta1.Tunnel, PublicIP: "1.1.1.2", Port: 4500},
+				{NodeName: "node-a", Type: ravenv1beta1.Tunnel, PublicIP: "1.1.1.1", Port: 4500},
+				{NodeName: "node-a", Type: ravenv1beta1.Proxy, PublicIP: "1.1.1.1", Port: 10262},
+			},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(np, gw).Build()
+
+	testcases := map[string]struct {
+		annotations map[string]string
+		pool        *appsv1alpha1.Pool
+		env         []corev1.EnvVar
+		wantEnv     []corev1.EnvVar
+	}{
+		"injection is not enabled": {
+			pool: &appsv1alpha1.Pool{Name: "hangzhou"},
+		},
+		"inject gateway config": {
+			annotations: map[string]string{unitv1alpha1.AnnotationInjectGatewayConfig: "true"},
+			pool:        &appsv1alpha1.Pool{Name: "hangzhou"},
+			wantEnv: []corev1.EnvVar{
+				{Name: EnvGatewayName, Value: "gw-hangzhou"},
+				{Name: EnvGatewayTunnelEndpoints, Value: "1.1.1.1:4500,1.1.1.2:4500"},
+				{Name: EnvGatewayProxyEndpoints, Value: "1.1.1.1:10262"},
+				{Name: EnvGatewayProxyHTTPPort, Value: "10255"},
+			},
+		},
+		"keep env declared in template": {
+			annotations: map[string]string{unitv1alpha1.AnnotationInjectGatewayConfig: "true"},
+			pool:        &appsv1alpha1.Pool{Name: "hangzhou"},
+			env:         []corev1.EnvVar{{Name: EnvGatewayName, Value: "foo"}},
+			wantEnv: []corev1.EnvVar{
+				{Name: EnvGatewayName, Value: "foo"},
+				{Name: EnvGatewayTunnelEndpoints, Value: "1.1.1.1:4500,1.1.1.2:4500"},
+				{Name: EnvGatewayProxyEndpoints, Value: "1.1.1.1:10262"},
+				{Name: EnvGatewayProxyHTTPPort, Value: "10255"},
+			},
+		},
+		"nodepool is not found": {
+			annotations: map[string]string{unitv1alpha1.AnnotationInjectGatewayConfig: "true"},
+			pool:        &appsv1alpha1.Pool{Name: "unknown"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			yas := &appsv1alpha1.YurtAppSet{ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: tc.annotations}}
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Env: tc.env}}}
+			workload := &metav1.ObjectMeta{Annotations: map[string]string{unitv1alpha1.AnnotationGatewayConfigHash: "stale"}}
+			if err := attachGatewayConfig(c, yas, workload, podSpec, tc.pool); err != nil {
+				t.Fatalf("failed to attach gateway config, %v", err)
+			}
+			if _, ok := workload.Annotations[unitv1alpha1.AnnotationGatewayConfigHash]; ok != (len(tc.wantEnv) != 0) {
+				t.Errorf("expect gateway config hash is recorded %v, but got annotations %v", len(tc.wantEnv) != 0, workload.Annotations)
+			}
+
+			if !reflect.DeepEqual(podSpec.Containers[0].Env, tc.wantEnv) {
+				t.Errorf("expect env %v, but got %v", tc.wantEnv, podSpec.Containers[0].Env)
+			}
+		})
+	}
+}
+
+func TestAttachConfigTemplates(t *testing.T) {
+	testcases := map[string]struct {
+		templates   []appsv1alpha1.PoolConfigTemplate
+		volumes     []corev1.Volume
+		mounts      []corev1.VolumeMount
+		wantVolumes []corev1.Volume
+		wantMounts  []corev1.VolumeMount
+	}{
+		"no config templates": {},
+		"append configmap volume and mount": {
+			templates: []appsv1alpha1.PoolConfigTemplate{
+				{Name: "conf", Kind: appsv1alpha1.ConfigMapConfigTemplateKind, MountPath: "/etc/app"},
+			},
+			wantVolumes: []corev1.Volume{
+				{Name: "conf", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "foo-hangzhou-conf"},
+				}}},
+			},
+			wantMounts: []corev1.VolumeMount{{Name: "conf", MountPath: "/etc/app", ReadOnly: true}},
+		},
+		"replace placeholder volume with secret": {
+			templates: []appsv1alpha1.PoolConfigTemplate{
+				{Name: "cert", Kind: appsv1alpha1.SecretConfigTemplateKind},
+			},
+			volumes: []corev1.Volume{{Name: "cert", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			mounts:  []corev1.VolumeMount{{Name: "cert", MountPath: "/etc/cert"}},
+			wantVolumes: []corev1.Volume{
+				{Name: "cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "foo-hangzhou-cert"}}},
+			},
+			wantMounts: []corev1.VolumeMount{{Name: "cert", MountPath: "/etc/cert"}},
+		},
+		"keep volume mounted in template": {
+			templates: []appsv1alpha1.PoolConfigTemplate{
+				{Name: "conf", Kind: appsv1alpha1.ConfigMapConfigTemplateKind, MountPath: "/etc/app"},
+			},
+			mounts: []corev1.VolumeMount{{Name: "conf", MountPath: "/etc/conf"}},
+			wantVolumes: []corev1.Volume{
+				{Name: "conf", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "foo-hangzhou-conf"},
+				}}},
+			},
+			wantMounts: []corev1.VolumeMount{{Name: "conf", MountPath: "/etc/conf"}},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			yas := &appsv1alpha1.YurtAppSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Spec:       appsv1alpha1.YurtAppSetSpec{ConfigTemplates: tc.templates},
+			}
+			podSpec := &corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", VolumeMounts: tc.mounts}},
+				Volumes:    tc.volumes,
+			}
+			attachConfigTemplates(yas, podSpec, &appsv1alpha1.Pool{Name: "hangzhou"})
+
+			if !reflect.DeepEqual(podSpec.Volumes, tc.wantVolumes) {
+				t.Errorf("expect volumes %v, but got %v", tc.wantVolumes, podSpec.Volumes)
+			}
+			if !reflect.DeepEqual(podSpec.Containers[0].VolumeMounts, tc.wantMounts) {
+				t.Errorf("expect volume mounts %v, but got %v", tc.wantMounts, podSpec.Containers[0].VolumeMounts)
+			}
+		})
+	}
+}
+
+func TestAttachBundleReferences(t *testing.T) {
+	yas := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Bundle: []appsv1alpha1.BundleTemplate{
+				{Name: "conf", ConfigMapTemplate: &appsv1alpha1.ConfigMapTemplateSpec{}},
+				{Name: "svc", ServiceTemplate: &appsv1alpha1.ServiceTemplateSpec{}},
+			},
+		},
+	}
+	podSpec := &corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "conf", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "conf"},
+			}}},
+			{Name: "other", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "other"},
+			}}},
+		},
+		Containers: []corev1.Container{{
+			Name: "app",
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "conf"}}},
+			},
+			Env: []corev1.EnvVar{
+				{Name: "SVC", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "svc"}, Key: "addr",
+				}}},
+			},
+		}},
+	}
+	attachBundleReferences(yas, podSpec, &appsv1alpha1.Pool{Name: "hangzhou"})
+
+	if name := podSpec.Volumes[0].ConfigMap.Name; name != "foo-hangzhou-conf" {
+		t.Errorf("expect configmap foo-hangzhou-conf of volume, but got %s", name)
+	}
+	if name := podSpec.Volumes[1].ConfigMap.Name; name != "other" {
+		t.Errorf("expect configmap other of volume, but got %s", name)
+	}
+	if name := podSpec.Containers[0].EnvFrom[0].ConfigMapRef.Name; name != "foo-hangzhou-conf" {
+		t.Errorf("expect configmap foo-hangzhou-conf of envFrom, but got %s", name)
+	}
+	if name := podSpec.Containers[0].Env[0].ValueFrom.ConfigMapKeyRef.Name; name != "svc" {
+		t.Errorf("expect configmap svc of env, but got %s", name)
+	}
+}
+
+func TestRenderConfigTemplate(t *testing.T) {
+	vars := &PoolConfigVariables{PoolName: "hangzhou", Region: "cn-east", GatewayIP: "1.1.1.1"}
+	testcases := map[string]struct {
+		data     map[string]string
+		wantData map[string]string
+		wantErr  bool
+	}{
+		"render pool variables": {
+			data:     map[string]string{"app.conf": "pool={{ .PoolName }}\nregion={{ .Region }}\ngateway={{ .GatewayIP }}"},
+			wantData: map[string]string{"app.conf": "pool=hangzhou\nregion=cn-east\ngateway=1.1.1.1"},
+		},
+		"invalid template": {
+			data:    map[string]string{"app.conf": "pool={{ .PoolName "},
+			wantErr: true,
+		},
+		"unknown variable": {
+			data:    map[string]string{"app.conf": "{{ .Unknown }}"},
+			wantErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			data, err := RenderConfigTemplate(&appsv1alpha1.PoolConfigTemplate{Name: "conf", Data: tc.data}, vars)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(data, tc.wantData) {
+				t.Errorf("expect data %v, but got %v", tc.wantData, data)
+			}
+		})
+	}
+}
+
+func TestGetStatefulSetName(t *testing.T) {
+	testcases := map[string]struct {
+		controllerName string
+		poolName       string
+		expect         string
+	}{
+		"stable name": {
+			controllerName: "foo",
+			poolName:       "hangzhou",
+			expect:         "foo-hangzhou",
+		},
+		"name is too long": {
+			controllerName: "foo-with-a-very-long-name-for-stateful-workload",
+			poolName:       "hangzhou",
+			expect:         "",
+		},
+		"name is not a dns label": {
+			controllerName: "foo.bar",
+			poolName:       "hangzhou",
+			expect:         "",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := getStatefulSetName(tc.controllerName, tc.poolName); got != tc.expect {
+				t.Errorf("expect name %q, but got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestMergeVolumeClaimTemplates(t *testing.T) {
+	newClaim := func(name, storageClass string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+		}
+	}
+
+	testcases := map[string]struct {
+		templates     []corev1.PersistentVolumeClaim
+		poolTemplates []corev1.PersistentVolumeClaim
+		expect        []corev1.PersistentVolumeClaim
+	}{
+		"no pool templates": {
+			templates: []corev1.PersistentVolumeClaim{newClaim("data", "standard")},
+			expect:    []corev1.PersistentVolumeClaim{newClaim("data", "standard")},
+		},
+		"pool template overrides template with the same name": {
+			templates:     []corev1.PersistentVolumeClaim{newClaim("data", "standard"), newClaim("log", "standard")},
+			poolTemplates: []corev1.PersistentVolumeClaim{newClaim("data", "local-path")},
+			expect:        []corev1.PersistentVolumeClaim{newClaim("data", "local-path"), newClaim("log", "standard")},
+		},
+		"pool template is appended": {
+			templates:     []corev1.PersistentVolumeClaim{newClaim("data", "standard")},
+			poolTemplates: []corev1.PersistentVolumeClaim{newClaim("cache", "local-path")},
+			expect:        []corev1.PersistentVolumeClaim{newClaim("data", "standard"), newClaim("cache", "local-path")},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got := mergeVolumeClaimTemplates(tc.templates, tc.poolTemplates)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect volumeClaimTemplates %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
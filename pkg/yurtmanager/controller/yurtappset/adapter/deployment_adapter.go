@@ -22,6 +22,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,8 +64,9 @@ func (a *DeploymentAdapter) GetDetails(obj metav1.Object) (ReplicasInfo, error)
 		specReplicas = *set.Spec.Replicas
 	}
 	replicasInfo := ReplicasInfo{
-		Replicas:      specReplicas,
-		ReadyReplicas: set.Status.ReadyReplicas,
+		Replicas:        specReplicas,
+		ReadyReplicas:   set.Status.ReadyReplicas,
+		UpdatedReplicas: set.Status.UpdatedReplicas,
 	}
 	return replicasInfo, nil
 }
@@ -81,8 +83,17 @@ func (a *DeploymentAdapter) GetAvailableStatus(obj metav1.Object) (conditionStat
 }
 
 // GetPoolFailure returns the failure information of the pool.
-// Deployment has no condition.
-func (a *DeploymentAdapter) GetPoolFailure() *string {
+// It is extracted from the ReplicaFailure condition, or the Progressing condition
+// when the deployment fails to make progress.
+func (a *DeploymentAdapter) GetPoolFailure(obj metav1.Object) *string {
+	dp := obj.(*appsv1.Deployment)
+	for _, condition := range dp.Status.Conditions {
+		if (condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue) ||
+			(condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse) {
+			message := fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			return &message
+		}
+	}
 	return nil
 }
 
@@ -133,7 +144,10 @@ func (a *DeploymentAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName,
 		return err
 	}
 
-	set.Spec.Selector = selectors
+	// the selector of Deployment is immutable, so the one of adopted Deployment is kept
+	if set.Spec.Selector == nil {
+		set.Spec.Selector = selectors
+	}
 	set.Spec.Replicas = &replicas
 
 	set.Spec.Strategy = *yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Strategy.DeepCopy()
@@ -150,6 +164,17 @@ func (a *DeploymentAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName,
 	set.Spec.ProgressDeadlineSeconds = yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.ProgressDeadlineSeconds
 
 	attachNodeAffinityAndTolerations(&set.Spec.Template.Spec, poolConfig)
+	if err := attachNodePoolConstraints(a.Client, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	if err := attachNodePoolSelectorAndTolerations(a.Client, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	if err := attachGatewayConfig(a.Client, yas, set, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	attachConfigTemplates(yas, &set.Spec.Template.Spec, poolConfig)
+	attachBundleReferences(yas, &set.Spec.Template.Spec, poolConfig)
 
 	if !PoolHasPatch(poolConfig, set) {
 		klog.Infof("Deployment[%s/%s-] has no patches, do not need strategicmerge", set.Namespace,
@@ -181,3 +206,23 @@ func (a *DeploymentAdapter) PostUpdate(yas *alpha1.YurtAppSet, obj runtime.Objec
 func (a *DeploymentAdapter) IsExpected(obj metav1.Object, revision string) bool {
 	return obj.GetLabels()[apps.ControllerRevisionHashLabelKey] != revision
 }
+
+// CanAdopt checks the selector of Deployment selects the pods of pool, so the Deployment
+// can be adopted without changing its selector.
+func (a *DeploymentAdapter) CanAdopt(yas *alpha1.YurtAppSet, poolName string, obj metav1.Object) bool {
+	set, ok := obj.(*appsv1.Deployment)
+	if !ok || set.Spec.Selector == nil || yas.Spec.WorkloadTemplate.DeploymentTemplate == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return false
+	}
+
+	podLabels := labels.Set{}
+	for k, v := range yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Labels {
+		podLabels[k] = v
+	}
+	podLabels[apps.PoolNameLabelKey] = poolName
+	return selector.Matches(podLabels)
+}
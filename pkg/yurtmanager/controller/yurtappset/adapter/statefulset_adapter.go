@@ -68,8 +68,9 @@ func (a *StatefulSetAdapter) GetDetails(obj metav1.Object) (ReplicasInfo, error)
 		specReplicas = *set.Spec.Replicas
 	}
 	replicasInfo := ReplicasInfo{
-		Replicas:      specReplicas,
-		ReadyReplicas: set.Status.ReadyReplicas,
+		Replicas:        specReplicas,
+		ReadyReplicas:   set.Status.ReadyReplicas,
+		UpdatedReplicas: set.Status.UpdatedReplicas,
 	}
 
 	return replicasInfo, nil
@@ -87,7 +88,7 @@ func (a *StatefulSetAdapter) GetAvailableStatus(obj metav1.Object) (conditionSta
 
 // GetPoolFailure returns the failure information of the pool.
 // StatefulSet has no condition.
-func (a *StatefulSetAdapter) GetPoolFailure() *string {
+func (a *StatefulSetAdapter) GetPoolFailure(obj metav1.Object) *string {
 	return nil
 }
 
@@ -130,6 +131,11 @@ func (a *StatefulSetAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName,
 	}
 
 	set.GenerateName = getPoolPrefix(yas.Name, poolName)
+	// StatefulSet is named stably, so its pods and persistent volume claims
+	// keep the same names even if the StatefulSet is recreated.
+	if len(set.Name) == 0 {
+		set.Name = getStatefulSetName(yas.Name, poolName)
+	}
 
 	selectors := yas.Spec.Selector.DeepCopy()
 	selectors.MatchLabels[apps.PoolNameLabelKey] = poolName
@@ -152,9 +158,21 @@ func (a *StatefulSetAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName,
 	set.Spec.RevisionHistoryLimit = yas.Spec.RevisionHistoryLimit
 	set.Spec.PodManagementPolicy = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.PodManagementPolicy
 	set.Spec.ServiceName = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.ServiceName
-	set.Spec.VolumeClaimTemplates = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.VolumeClaimTemplates
+	set.Spec.VolumeClaimTemplates = mergeVolumeClaimTemplates(yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.VolumeClaimTemplates,
+		poolConfig.VolumeClaimTemplates)
 
 	attachNodeAffinityAndTolerations(&set.Spec.Template.Spec, poolConfig)
+	if err := attachNodePoolConstraints(a.Client, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	if err := attachNodePoolSelectorAndTolerations(a.Client, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	if err := attachGatewayConfig(a.Client, yas, set, &set.Spec.Template.Spec, poolConfig); err != nil {
+		return err
+	}
+	attachConfigTemplates(yas, &set.Spec.Template.Spec, poolConfig)
+	attachBundleReferences(yas, &set.Spec.Template.Spec, poolConfig)
 
 	if !PoolHasPatch(poolConfig, set) {
 		klog.Infof("StatefulSet[%s/%s-] has no patches, do not need strategicmerge", set.Namespace,
@@ -197,6 +215,12 @@ func (a *StatefulSetAdapter) IsExpected(obj metav1.Object, revision string) bool
 	return obj.GetLabels()[apps.ControllerRevisionHashLabelKey] != revision
 }
 
+// CanAdopt always returns false, because most of the spec of StatefulSet like volumeClaimTemplates
+// can't be updated to the template of YurtAppSet.
+func (a *StatefulSetAdapter) CanAdopt(yas *alpha1.YurtAppSet, poolName string, obj metav1.Object) bool {
+	return false
+}
+
 /*
 func (a *StatefulSetAdapter) getStatefulSetPods(set *appsv1.StatefulSet) ([]*corev1.Pod, error) {
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
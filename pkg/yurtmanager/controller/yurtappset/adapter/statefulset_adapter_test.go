@@ -29,6 +29,7 @@ import (
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 )
 
 func TestStatefulSetAdapter_ApplyPoolTemplate(t *testing.T) {
@@ -175,6 +176,10 @@ func TestStatefulSetAdapter_ApplyPoolTemplate(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -225,6 +230,10 @@ func TestStatefulSetAdapter_GetDetails(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+// bundleObjectMeta returns the metadata of object instantiated for pool from the bundle template.
+func bundleObjectMeta(yas *unitv1alpha1.YurtAppSet, poolName, templateName string, meta *metav1.ObjectMeta) metav1.ObjectMeta {
+	objMeta := metav1.ObjectMeta{
+		Namespace:   yas.Namespace,
+		Name:        adapter.PoolObjectName(yas.Name, poolName, templateName),
+		Labels:      map[string]string{},
+		Annotations: map[string]string{},
+	}
+	for k, v := range meta.Labels {
+		objMeta.Labels[k] = v
+	}
+	for k, v := range meta.Annotations {
+		objMeta.Annotations[k] = v
+	}
+	objMeta.Labels[apps.PoolNameLabelKey] = poolName
+	objMeta.Labels[apps.BundleTemplateLabelKey] = templateName
+	return objMeta
+}
+
+// newPoolService instantiates the Service of pool from the bundle template, the Service only selects the pods of pool.
+func newPoolService(yas *unitv1alpha1.YurtAppSet, poolName string, tmpl *unitv1alpha1.BundleTemplate) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: bundleObjectMeta(yas, poolName, tmpl.Name, &tmpl.ServiceTemplate.ObjectMeta),
+		Spec:       *tmpl.ServiceTemplate.Spec.DeepCopy(),
+	}
+	if svc.Spec.Type != corev1.ServiceTypeExternalName {
+		selector := map[string]string{}
+		if len(svc.Spec.Selector) != 0 {
+			selector = svc.Spec.Selector
+		} else if yas.Spec.Selector != nil {
+			for k, v := range yas.Spec.Selector.MatchLabels {
+				selector[k] = v
+			}
+		}
+		selector[apps.PoolNameLabelKey] = poolName
+		svc.Spec.Selector = selector
+	}
+
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, []interface{}{svc.Labels, svc.Annotations, svc.Spec})
+	svc.Annotations[apps.AnnotationBundleTemplateHash] = rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+	return svc
+}
+
+// newBundleObjects instantiates the bundle templates of YurtAppSet for each pool, and returns
+// the Services and ConfigMaps indexed by name.
+func (r *ReconcileYurtAppSet) newBundleObjects(yas *unitv1alpha1.YurtAppSet) (map[string]*corev1.Service, map[string]*corev1.ConfigMap, error) {
+	services, configMaps := map[string]*corev1.Service{}, map[string]*corev1.ConfigMap{}
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		for j := range yas.Spec.Bundle {
+			tmpl := &yas.Spec.Bundle[j]
+
+			var obj client.Object
+			switch {
+			case tmpl.ServiceTemplate != nil:
+				svc := newPoolService(yas, pool.Name, tmpl)
+				services[svc.Name] = svc
+				obj = svc
+			case tmpl.ConfigMapTemplate != nil:
+				cm := &corev1.ConfigMap{ObjectMeta: bundleObjectMeta(yas, pool.Name, tmpl.Name, &tmpl.ConfigMapTemplate.ObjectMeta)}
+				if tmpl.ConfigMapTemplate.Data != nil {
+					cm.Data = map[string]string{}
+					for k, v := range tmpl.ConfigMapTemplate.Data {
+						cm.Data[k] = v
+					}
+				}
+				configMaps[cm.Name] = cm
+				obj = cm
+			default:
+				continue
+			}
+			if err := controllerutil.SetControllerReference(yas, obj, r.scheme); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return services, configMaps, nil
+}
+
+// manageBundle creates or updates the objects instantiated for each pool from the bundle templates,
+// and deletes the ones which are not needed any more.
+func (r *ReconcileYurtAppSet) manageBundle(yas *unitv1alpha1.YurtAppSet) error {
+	services, configMaps, err := r.newBundleObjects(yas)
+	if err != nil {
+		return err
+	}
+	if err := r.syncPoolConfigMaps(yas, apps.BundleTemplateLabelKey, configMaps); err != nil {
+		return err
+	}
+	return r.syncPoolServices(yas, services)
+}
+
+// syncPoolServices makes the Services instantiated from bundle templates match the expected ones.
+// The Service is updated only when the hash of template changes, and the annotations added by others
+// and the cluster ips allocated are kept.
+func (r *ReconcileYurtAppSet) syncPoolServices(yas *unitv1alpha1.YurtAppSet, expected map[string]*corev1.Service) error {
+	svcList := &corev1.ServiceList{}
+	if err := r.List(context.TODO(), svcList, client.InNamespace(yas.Namespace), client.HasLabels{apps.BundleTemplateLabelKey}); err != nil {
+		return fmt.Errorf("fail to list Services: %v", err)
+	}
+
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		if !metav1.IsControlledBy(svc, yas) {
+			continue
+		}
+
+		want, ok := expected[svc.Name]
+		if !ok {
+			klog.V(4).Infof("YurtAppSet %s/%s deletes Service %s", yas.Namespace, yas.Name, svc.Name)
+			if err := r.Delete(context.TODO(), svc); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("fail to delete Service %s: %v", svc.Name, err)
+			}
+			continue
+		}
+		delete(expected, svc.Name)
+
+		if svc.Annotations[apps.AnnotationBundleTemplateHash] == want.Annotations[apps.AnnotationBundleTemplateHash] {
+			continue
+		}
+		svc.Labels = want.Labels
+		if svc.Annotations == nil {
+			svc.Annotations = map[string]string{}
+		}
+		for k, v := range want.Annotations {
+			svc.Annotations[k] = v
+		}
+		if len(want.Spec.ClusterIP) == 0 {
+			want.Spec.ClusterIP = svc.Spec.ClusterIP
+			want.Spec.ClusterIPs = svc.Spec.ClusterIPs
+		}
+		if len(want.Spec.IPFamilies) == 0 {
+			want.Spec.IPFamilies = svc.Spec.IPFamilies
+		}
+		svc.Spec = want.Spec
+		klog.V(4).Infof("YurtAppSet %s/%s updates Service %s", yas.Namespace, yas.Name, svc.Name)
+		if err := r.Update(context.TODO(), svc); err != nil {
+			return fmt.Errorf("fail to update Service %s: %v", svc.Name, err)
+		}
+	}
+
+	for _, svc := range expected {
+		klog.V(4).Infof("YurtAppSet %s/%s creates Service %s", yas.Namespace, yas.Name, svc.Name)
+		if err := r.Create(context.TODO(), svc); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("fail to create Service %s: %v", svc.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func TestManageBundle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes resource, %v", err)
+	}
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+
+	svcTemplate := appsv1alpha1.BundleTemplate{
+		Name: "svc",
+		ServiceTemplate: &appsv1alpha1.ServiceTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "edge"}},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+		},
+	}
+	confTemplate := appsv1alpha1.BundleTemplate{
+		Name:              "conf",
+		ConfigMapTemplate: &appsv1alpha1.ConfigMapTemplateSpec{Data: map[string]string{"app.conf": "foo"}},
+	}
+	newYurtAppSet := func(bundle ...appsv1alpha1.BundleTemplate) *appsv1alpha1.YurtAppSet {
+		return &appsv1alpha1.YurtAppSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-uid"},
+			Spec: appsv1alpha1.YurtAppSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+				Topology: appsv1alpha1.Topology{Pools: []appsv1alpha1.Pool{{Name: "hangzhou"}, {Name: "beijing"}}},
+				Bundle:   bundle,
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		existing       *appsv1alpha1.YurtAppSet
+		yas            *appsv1alpha1.YurtAppSet
+		wantServices   []string
+		wantConfigMaps []string
+	}{
+		"instantiate bundle for pools": {
+			yas:            newYurtAppSet(svcTemplate, confTemplate),
+			wantServices:   []string{"foo-beijing-svc", "foo-hangzhou-svc"},
+			wantConfigMaps: []string{"foo-beijing-conf", "foo-hangzhou-conf"},
+		},
+		"delete objects of removed template": {
+			existing:     newYurtAppSet(svcTemplate, confTemplate),
+			yas:          newYurtAppSet(svcTemplate),
+			wantServices: []string{"foo-beijing-svc", "foo-hangzhou-svc"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			r := &ReconcileYurtAppSet{Client: fakeclient.NewClientBuilder().WithScheme(scheme).Build(), scheme: scheme}
+			if tc.existing != nil {
+				if err := r.manageBundle(tc.existing); err != nil {
+					t.Fatalf("failed to manage existing bundle, %v", err)
+				}
+			}
+			if err := r.manageBundle(tc.yas); err != nil {
+				t.Fatalf("failed to manage bundle, %v", err)
+			}
+
+			svcList := &corev1.ServiceList{}
+			if err := r.List(context.TODO(), svcList, client.InNamespace("default")); err != nil {
+				t.Fatalf("failed to list services, %v", err)
+			}
+			var services []string
+			for _, svc := range svcList.Items {
+				services = append(services, svc.Name)
+				pool := svc.Labels[apps.PoolNameLabelKey]
+				wantSelector := map[string]string{"app": "demo", apps.PoolNameLabelKey: pool}
+				if !reflect.DeepEqual(svc.Spec.Selector, wantSelector) {
+					t.Errorf("expect selector %v of service %s, but got %v", wantSelector, svc.Name, svc.Spec.Selector)
+				}
+				if svc.Labels["tier"] != "edge" || !metav1.IsControlledBy(&svc, tc.yas) {
+					t.Errorf("service %s is not instantiated from template", svc.Name)
+				}
+			}
+			sort.Strings(services)
+			if !reflect.DeepEqual(services, tc.wantServices) {
+				t.Errorf("expect services %v, but got %v", tc.wantServices, services)
+			}
+
+			cmList := &corev1.ConfigMapList{}
+			if err := r.List(context.TODO(), cmList, client.InNamespace("default")); err != nil {
+				t.Fatalf("failed to list configmaps, %v", err)
+			}
+			var configMaps []string
+			for _, cm := range cmList.Items {
+				configMaps = append(configMaps, cm.Name)
+			}
+			sort.Strings(configMaps)
+			if !reflect.DeepEqual(configMaps, tc.wantConfigMaps) {
+				t.Errorf("expect configmaps %v, but got %v", tc.wantConfigMaps, configMaps)
+			}
+		})
+	}
+}
+
+func TestManageBundleWithoutChanges(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes resource, %v", err)
+	}
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	yas := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-uid"},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Topology: appsv1alpha1.Topology{Pools: []appsv1alpha1.Pool{{Name: "hangzhou"}}},
+			Bundle: []appsv1alpha1.BundleTemplate{
+				{Name: "conf", ConfigMapTemplate: &appsv1alpha1.ConfigMapTemplateSpec{Data: map[string]string{"app.conf": "foo"}}},
+			},
+		},
+	}
+	r := &ReconcileYurtAppSet{Client: fakeclient.NewClientBuilder().WithScheme(scheme).Build(), scheme: scheme}
+	key := client.ObjectKey{Namespace: "default", Name: "foo-hangzhou-conf"}
+
+	if err := r.manageBundle(yas); err != nil {
+		t.Fatalf("failed to manage bundle, %v", err)
+	}
+	created := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), key, created); err != nil {
+		t.Fatalf("failed to get configmap, %v", err)
+	}
+
+	// the ConfigMap is not updated when the bundle doesn't change
+	if err := r.manageBundle(yas); err != nil {
+		t.Fatalf("failed to manage bundle, %v", err)
+	}
+	got := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), key, got); err != nil {
+		t.Fatalf("failed to get configmap, %v", err)
+	}
+	if got.ResourceVersion != created.ResourceVersion {
+		t.Errorf("expect configmap is not updated, but resource version changes from %s to %s", created.ResourceVersion, got.ResourceVersion)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+// poolConfigVariables collects the variables of pool from its NodePool and the raven Gateway of the NodePool.
+// The variables which can't be found are left empty.
+func (r *ReconcileYurtAppSet) poolConfigVariables(pool *unitv1alpha1.Pool) (*adapter.PoolConfigVariables, error) {
+	vars := &adapter.PoolConfigVariables{
+		PoolName:     pool.Name,
+		NodePoolName: adapter.PoolNodePoolName(pool),
+	}
+
+	np := &appsv1beta1.NodePool{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: vars.NodePoolName}, np); err != nil {
+		if errors.IsNotFound(err) {
+			return vars, nil
+		}
+		return nil, fmt.Errorf("fail to get NodePool of pool %s: %v", pool.Name, err)
+	}
+	if np.Spec.Topology != nil {
+		vars.Region = np.Spec.Topology.Region
+		vars.Zone = np.Spec.Topology.Zone
+		vars.Site = np.Spec.Topology.Site
+	}
+	if len(np.Spec.Gateway) == 0 {
+		return vars, nil
+	}
+	vars.Gateway = np.Spec.Gateway
+
+	gw := &ravenv1beta1.Gateway{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: np.Spec.Gateway}, gw); err != nil {
+		if errors.IsNotFound(err) {
+			return vars, nil
+		}
+		return nil, fmt.Errorf("fail to get Gateway of pool %s: %v", pool.Name, err)
+	}
+	for _, ep := range gw.Status.ActiveEndpoints {
+		if ep != nil && len(ep.PublicIP) != 0 {
+			vars.GatewayIP = ep.PublicIP
+			break
+		}
+	}
+	return vars, nil
+}
+
+// newPoolConfigs renders the config templates of YurtAppSet for each pool, and returns
+// the ConfigMaps and Secrets indexed by name.
+func (r *ReconcileYurtAppSet) newPoolConfigs(yas *unitv1alpha1.YurtAppSet) (map[string]*corev1.ConfigMap, map[string]*corev1.Secret, error) {
+	configMaps, secrets := map[string]*corev1.ConfigMap{}, map[string]*corev1.Secret{}
+	if len(yas.Spec.ConfigTemplates) == 0 {
+		return configMaps, secrets, nil
+	}
+
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		vars, err := r.poolConfigVariables(pool)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for j := range yas.Spec.ConfigTemplates {
+			tmpl := &yas.Spec.ConfigTemplates[j]
+			data, err := adapter.RenderConfigTemplate(tmpl, vars)
+			if err != nil {
+				return nil, nil, fmt.Errorf("fail to render config template %s of pool %s: %v", tmpl.Name, pool.Name, err)
+			}
+
+			objMeta := metav1.ObjectMeta{
+				Namespace: yas.Namespace,
+				Name:      adapter.PoolObjectName(yas.Name, pool.Name, tmpl.Name),
+				Labels: map[string]string{
+					apps.PoolNameLabelKey:       pool.Name,
+					apps.ConfigTemplateLabelKey: tmpl.Name,
+				},
+			}
+
+			var obj client.Object
+			if tmpl.Kind == unitv1alpha1.SecretConfigTemplateKind {
+				secret := &corev1.Secret{ObjectMeta: objMeta, Type: corev1.SecretTypeOpaque, Data: map[string][]byte{}}
+				for k, v := range data {
+					secret.Data[k] = []byte(v)
+				}
+				secrets[secret.Name] = secret
+				obj = secret
+			} else {
+				configMap := &corev1.ConfigMap{ObjectMeta: objMeta, Data: data}
+				configMaps[configMap.Name] = configMap
+				obj = configMap
+			}
+			if err := controllerutil.SetControllerReference(yas, obj, r.scheme); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return configMaps, secrets, nil
+}
+
+// manageConfigTemplates creates or updates the ConfigMaps and Secrets rendered for each pool,
+// and deletes the ones which are not needed any more.
+func (r *ReconcileYurtAppSet) manageConfigTemplates(yas *unitv1alpha1.YurtAppSet) error {
+	configMaps, secrets, err := r.newPoolConfigs(yas)
+	if err != nil {
+		return err
+	}
+	if err := r.syncPoolConfigMaps(yas, apps.ConfigTemplateLabelKey, configMaps); err != nil {
+		return err
+	}
+	return r.syncPoolSecrets(yas, secrets)
+}
+
+// syncPoolConfigMaps makes the ConfigMaps with labelKey owned by YurtAppSet match the expected ones.
+func (r *ReconcileYurtAppSet) syncPoolConfigMaps(yas *unitv1alpha1.YurtAppSet, labelKey string, expected map[string]*corev1.ConfigMap) error {
+	cmList := &corev1.ConfigMapList{}
+	if err := r.List(context.TODO(), cmList, client.InNamespace(yas.Namespace), client.HasLabels{labelKey}); err != nil {
+		return fmt.Errorf("fail to list ConfigMaps: %v", err)
+	}
+
+	for i := range cmList.Items {
+		cm := &cmList.Items[i]
+		if !metav1.IsControlledBy(cm, yas) {
+			continue
+		}
+
+		want, ok := expected[cm.Name]
+		if !ok {
+			klog.V(4).Infof("YurtAppSet %s/%s deletes ConfigMap %s", yas.Namespace, yas.Name, cm.Name)
+			if err := r.Delete(context.TODO(), cm); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("fail to delete ConfigMap %s: %v", cm.Name, err)
+			}
+			continue
+		}
+		delete(expected, cm.Name)
+
+		// the empty maps are not kept by apiserver, so they are compared as nil
+		want.Labels, want.Annotations, want.Data = nilIfEmpty(want.Labels), nilIfEmpty(want.Annotations), nilIfEmpty(want.Data)
+		if reflect.DeepEqual(cm.Labels, want.Labels) && reflect.DeepEqual(cm.Annotations, want.Annotations) &&
+			reflect.DeepEqual(cm.Data, want.Data) {
+			continue
+		}
+		cm.Labels = want.Labels
+		cm.Annotations = want.Annotations
+		cm.Data = want.Data
+		klog.V(4).Infof("YurtAppSet %s/%s updates ConfigMap %s", yas.Namespace, yas.Name, cm.Name)
+		if err := r.Update(context.TODO(), cm); err != nil {
+			return fmt.Errorf("fail to update ConfigMap %s: %v", cm.Name, err)
+		}
+	}
+
+	for _, cm := range expected {
+		klog.V(4).Infof("YurtAppSet %s/%s creates ConfigMap %s", yas.Namespace, yas.Name, cm.Name)
+		if err := r.Create(context.TODO(), cm); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("fail to create ConfigMap %s: %v", cm.Name, err)
+		}
+	}
+	return nil
+}
+
+func nilIfEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// syncPoolSecrets makes the Secrets rendered from config templates match the expected ones. Secrets are
+// read from apiserver directly instead of the cache, so that the Secrets in the cluster are not cached.
+func (r *ReconcileYurtAppSet) syncPoolSecrets(yas *unitv1alpha1.YurtAppSet, expected map[string]*corev1.Secret) error {
+	for _, want := range expected {
+		secret := &corev1.Secret{}
+		err := r.apiReader.Get(context.TODO(), types.NamespacedName{Namespace: want.Namespace, Name: want.Name}, secret)
+		if errors.IsNotFound(err) {
+			klog.V(4).Infof("YurtAppSet %s/%s creates Secret %s", yas.Namespace, yas.Name, want.Name)
+			if err := r.Create(context.TODO(), want); err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("fail to create Secret %s: %v", want.Name, err)
+			}
+			continue
+		} else if err != nil {
+			return fmt.Errorf("fail to get Secret %s: %v", want.Name, err)
+		}
+
+		if !metav1.IsControlledBy(secret, yas) {
+			continue
+		}
+		if reflect.DeepEqual(secret.Labels, want.Labels) && reflect.DeepEqual(secret.Data, want.Data) {
+			continue
+		}
+		secret.Labels = want.Labels
+		secret.Data = want.Data
+		klog.V(4).Infof("YurtAppSet %s/%s updates Secret %s", yas.Namespace, yas.Name, secret.Name)
+		if err := r.Update(context.TODO(), secret); err != nil {
+			return fmt.Errorf("fail to update Secret %s: %v", secret.Name, err)
+		}
+	}
+
+	// the Secrets of removed pools or config templates are deleted
+	secretList := &corev1.SecretList{}
+	if err := r.apiReader.List(context.TODO(), secretList, client.InNamespace(yas.Namespace), client.HasLabels{apps.ConfigTemplateLabelKey}); err != nil {
+		return fmt.Errorf("fail to list Secrets: %v", err)
+	}
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if _, ok := expected[secret.Name]; ok || !metav1.IsControlledBy(secret, yas) {
+			continue
+		}
+		klog.V(4).Infof("YurtAppSet %s/%s deletes Secret %s", yas.Namespace, yas.Name, secret.Name)
+		if err := r.Delete(context.TODO(), secret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("fail to delete Secret %s: %v", secret.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+func TestManageConfigTemplates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme, appsv1alpha1.AddToScheme, appsv1beta1.AddToScheme, ravenv1beta1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatalf("failed to add scheme, %v", err)
+		}
+	}
+
+	np := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Spec: appsv1beta1.NodePoolSpec{
+			Gateway:  "gw-hangzhou",
+			Topology: &appsv1beta1.NodePoolTopology{Region: "cn-east"},
+		},
+	}
+	gw := &ravenv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw-hangzhou"},
+		Status: ravenv1beta1.GatewayStatus{
+			ActiveEndpoints: []*ravenv1beta1.Endpoint{{NodeName: "node-a", Type: ravenv1beta1.Tunnel, PublicIP: "1.1.1.1"}},
+		},
+	}
+	newYurtAppSet := func(templates ...appsv1alpha1.PoolConfigTemplate) *appsv1alpha1.YurtAppSet {
+		return &appsv1alpha1.YurtAppSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-uid"},
+			Spec: appsv1alpha1.YurtAppSetSpec{
+				Topology:        appsv1alpha1.Topology{Pools: []appsv1alpha1.Pool{{Name: "hangzhou"}, {Name: "beijing"}}},
+				ConfigTemplates: templates,
+			},
+		}
+	}
+	confTemplate := appsv1alpha1.PoolConfigTemplate{
+		Name: "conf",
+		Kind: appsv1alpha1.ConfigMapConfigTemplateKind,
+		Data: map[string]string{"app.conf": "{{ .PoolName }},{{ .Region }},{{ .Gateway }},{{ .GatewayIP }}"},
+	}
+	certTemplate := appsv1alpha1.PoolConfigTemplate{
+		Name: "cert",
+		Kind: appsv1alpha1.SecretConfigTemplateKind,
+		Data: map[string]string{"pool": "{{ .PoolName }}"},
+	}
+
+	testcases := map[string]struct {
+		yas            *appsv1alpha1.YurtAppSet
+		existing       *appsv1alpha1.YurtAppSet
+		wantConfigMaps map[string]string
+		wantSecrets    map[string]string
+	}{
+		"render configmaps for pools": {
+			yas: newYurtAppSet(confTemplate),
+			wantConfigMaps: map[string]string{
+				"foo-hangzhou-conf": "hangzhou,cn-east,gw-hangzhou,1.1.1.1",
+				"foo-beijing-conf":  "beijing,,,",
+			},
+			wantSecrets: map[string]string{},
+		},
+		"render secrets for pools": {
+			yas:            newYurtAppSet(certTemplate),
+			wantConfigMaps: map[string]string{},
+			wantSecrets: map[string]string{
+				"foo-hangzhou-cert": "hangzhou",
+				"foo-beijing-cert":  "beijing",
+			},
+		},
+		"delete configs of removed template": {
+			yas:            newYurtAppSet(certTemplate),
+			existing:       newYurtAppSet(confTemplate, certTemplate),
+			wantConfigMaps: map[string]string{},
+			wantSecrets: map[string]string{
+				"foo-hangzhou-cert": "hangzhou",
+				"foo-beijing-cert":  "beijing",
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(np, gw).Build()
+			r := &ReconcileYurtAppSet{Client: c, apiReader: c, scheme: scheme}
+			if tc.existing != nil {
+				if err := r.manageConfigTemplates(tc.existing); err != nil {
+					t.Fatalf("failed to manage existing config templates, %v", err)
+				}
+			}
+			if err := r.manageConfigTemplates(tc.yas); err != nil {
+				t.Fatalf("failed to manage config templates, %v", err)
+			}
+
+			cmList := &corev1.ConfigMapList{}
+			if err := r.List(context.TODO(), cmList, client.InNamespace("default")); err != nil {
+				t.Fatalf("failed to list configmaps, %v", err)
+			}
+			configMaps := map[string]string{}
+			for _, cm := range cmList.Items {
+				if !metav1.IsControlledBy(&cm, tc.yas) {
+					t.Errorf("configmap %s is not controlled by YurtAppSet", cm.Name)
+				}
+				configMaps[cm.Name] = cm.Data["app.conf"]
+			}
+			if !reflect.DeepEqual(configMaps, tc.wantConfigMaps) {
+				t.Errorf("expect configmaps %v, but got %v", tc.wantConfigMaps, configMaps)
+			}
+
+			secretList := &corev1.SecretList{}
+			if err := r.List(context.TODO(), secretList, client.InNamespace("default")); err != nil {
+				t.Fatalf("failed to list secrets, %v", err)
+			}
+			secrets := map[string]string{}
+			for _, secret := range secretList.Items {
+				secrets[secret.Name] = string(secret.Data["pool"])
+			}
+			if !reflect.DeepEqual(secrets, tc.wantSecrets) {
+				t.Errorf("expect secrets %v, but got %v", tc.wantSecrets, secrets)
+			}
+		})
+	}
+}
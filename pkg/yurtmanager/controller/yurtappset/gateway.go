@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+var gatewayResource = ravenv1beta1.SchemeGroupVersion.WithResource("gateways")
+
+// applyGatewayConfigHashes records the hash of gateway config which should be injected into the workload
+// of each pool, so the workloads are updated when the gateway of their NodePools changes.
+func (r *ReconcileYurtAppSet) applyGatewayConfigHashes(yas *unitv1alpha1.YurtAppSet, nextPatches map[string]YurtAppSetPatches) error {
+	if yas.Annotations[apps.AnnotationInjectGatewayConfig] != "true" {
+		return nil
+	}
+
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		hash, err := adapter.GatewayConfigHash(r.Client, yas, pool)
+		if err != nil {
+			return fmt.Errorf("fail to get gateway config of pool %s: %v", pool.Name, err)
+		}
+		patches := nextPatches[pool.Name]
+		patches.GatewayConfigHash = hash
+		nextPatches[pool.Name] = patches
+	}
+	return nil
+}
+
+// enqueueYurtAppSetsForGateway returns a map func which enqueues YurtAppSets injecting the config
+// of the Gateway into the workloads of pools whose NodePools use the Gateway.
+func enqueueYurtAppSetsForGateway(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		yasList := &unitv1alpha1.YurtAppSetList{}
+		if err := c.List(context.TODO(), yasList); err != nil {
+			klog.Errorf("fail to list YurtAppSets for Gateway %s: %v", obj.GetName(), err)
+			return nil
+		}
+		npList := &appsv1beta1.NodePoolList{}
+		if err := c.List(context.TODO(), npList); err != nil {
+			klog.Errorf("fail to list NodePools for Gateway %s: %v", obj.GetName(), err)
+			return nil
+		}
+		nodePools := make(map[string]bool)
+		for i := range npList.Items {
+			if npList.Items[i].Spec.Gateway == obj.GetName() {
+				nodePools[npList.Items[i].Name] = true
+			}
+		}
+		if len(nodePools) == 0 {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, yas := range yasList.Items {
+			if yas.Annotations[apps.AnnotationInjectGatewayConfig] != "true" {
+				continue
+			}
+			for i := range yas.Spec.Topology.Pools {
+				if nodePools[adapter.PoolNodePoolName(&yas.Spec.Topology.Pools[i])] {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Namespace: yas.Namespace, Name: yas.Name},
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
+)
+
+func newGatewayTestClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := ravenv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add raven custom resource, %v", err)
+	}
+	return fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestApplyGatewayConfigHashes(t *testing.T) {
+	np := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		Spec:       appsv1beta1.NodePoolSpec{Gateway: "gw-hangzhou"},
+	}
+	gw := &ravenv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw-hangzhou"},
+		Status: ravenv1beta1.GatewayStatus{
+			ActiveEndpoints: []*ravenv1beta1.Endpoint{
+				{NodeName: "node-a", Type: ravenv1beta1.Tunnel, PublicIP: "1.1.1.1", Port: 4500},
+			},
+		},
+	}
+	c := newGatewayTestClient(t, np, gw)
+	r := &ReconcileYurtAppSet{Client: c}
+
+	yas := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{apps.AnnotationInjectGatewayConfig: "true"},
+		},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Topology: appsv1alpha1.Topology{
+				Pools: []appsv1alpha1.Pool{{Name: "hangzhou"}, {Name: "beijing"}},
+			},
+		},
+	}
+
+	nextPatches := GetNextPatches(yas)
+	if err := r.applyGatewayConfigHashes(yas, nextPatches); err != nil {
+		t.Fatalf("failed to apply gateway config hashes, %v", err)
+	}
+	hash := nextPatches["hangzhou"].GatewayConfigHash
+	if len(hash) == 0 {
+		t.Errorf("expect gateway config hash of pool hangzhou")
+	}
+	if len(nextPatches["beijing"].GatewayConfigHash) != 0 {
+		t.Errorf("expect no gateway config hash of pool beijing without gateway, but got %s", nextPatches["beijing"].GatewayConfigHash)
+	}
+
+	// the hash changes with the endpoints of gateway, so the workload is updated.
+	gw.Status.ActiveEndpoints[0].PublicIP = "1.1.1.2"
+	if err := c.Update(context.TODO(), gw); err != nil {
+		t.Fatalf("failed to update gateway, %v", err)
+	}
+	nextPatches = GetNextPatches(yas)
+	if err := r.applyGatewayConfigHashes(yas, nextPatches); err != nil {
+		t.Fatalf("failed to apply gateway config hashes, %v", err)
+	}
+	if nextPatches["hangzhou"].GatewayConfigHash == hash {
+		t.Errorf("expect gateway config hash changes with the endpoints of gateway")
+	}
+}
+
+func TestEnqueueYurtAppSetsForGateway(t *testing.T) {
+	np := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou-pool"},
+		Spec:       appsv1beta1.NodePoolSpec{Gateway: "gw-hangzhou"},
+	}
+	newYurtAppSet := func(name string, inject bool) *appsv1alpha1.YurtAppSet {
+		yas := &appsv1alpha1.YurtAppSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: appsv1alpha1.YurtAppSetSpec{
+				Topology: appsv1alpha1.Topology{
+					Pools: []appsv1alpha1.Pool{{Name: "hangzhou-pool"}},
+				},
+			},
+		}
+		if inject {
+			yas.Annotations = map[string]string{apps.AnnotationInjectGatewayConfig: "true"}
+		}
+		return yas
+	}
+	c := newGatewayTestClient(t, np, newYurtAppSet("foo", true), newYurtAppSet("bar", false))
+
+	requests := enqueueYurtAppSetsForGateway(c)(&ravenv1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gw-hangzhou"}})
+	if len(requests) != 1 || requests[0].Name != "foo" {
+		t.Errorf("expect only YurtAppSet foo is enqueued, but got %v", requests)
+	}
+	if requests := enqueueYurtAppSetsForGateway(c)(&ravenv1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "gw-beijing"}}); len(requests) != 0 {
+		t.Errorf("expect no YurtAppSet is enqueued for unused gateway, but got %v", requests)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// autoscalingBounds returns the min and max replicas of the HorizontalPodAutoscaler of pool,
+// the bounds of pool override the ones of YurtAppSet.
+func autoscalingBounds(autoscaling *unitv1alpha1.YurtAppSetAutoscaling, pool *unitv1alpha1.Pool) (int32, int32) {
+	minReplicas, maxReplicas := int32(1), autoscaling.MaxReplicas
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+	if pool.Autoscaling != nil {
+		if pool.Autoscaling.MinReplicas != nil {
+			minReplicas = *pool.Autoscaling.MinReplicas
+		}
+		if pool.Autoscaling.MaxReplicas != nil {
+			maxReplicas = *pool.Autoscaling.MaxReplicas
+		}
+	}
+	return minReplicas, maxReplicas
+}
+
+// applyAutoscalingReplicas sets the replicas of pools in nextPatches when autoscaling is enabled.
+// The replicas scaled by HorizontalPodAutoscaler are kept, so YurtAppSet doesn't fight with it,
+// and a new pool starts with the min replicas.
+func applyAutoscalingReplicas(yas *unitv1alpha1.YurtAppSet, nameToPool map[string]*Pool, nextPatches map[string]YurtAppSetPatches) {
+	if yas.Spec.Autoscaling == nil {
+		return
+	}
+
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		minReplicas, maxReplicas := autoscalingBounds(yas.Spec.Autoscaling, pool)
+
+		replicas := minReplicas
+		if p, ok := nameToPool[pool.Name]; ok {
+			replicas = p.Status.ReplicasInfo.Replicas
+			if replicas < minReplicas {
+				replicas = minReplicas
+			}
+			if replicas > maxReplicas {
+				replicas = maxReplicas
+			}
+		}
+
+		patches := nextPatches[pool.Name]
+		patches.Replicas = replicas
+		nextPatches[pool.Name] = patches
+	}
+}
+
+// newHorizontalPodAutoscaler generates the HorizontalPodAutoscaler which scales the workload of pool.
+func (r *ReconcileYurtAppSet) newHorizontalPodAutoscaler(yas *unitv1alpha1.YurtAppSet, pool *unitv1alpha1.Pool,
+	workloadName string, poolType unitv1alpha1.TemplateType) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	minReplicas, maxReplicas := autoscalingBounds(yas.Spec.Autoscaling, pool)
+
+	labels := map[string]string{}
+	if yas.Spec.Selector != nil {
+		for k, v := range yas.Spec.Selector.MatchLabels {
+			labels[k] = v
+		}
+	}
+	labels[apps.PoolNameLabelKey] = pool.Name
+
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: yas.Namespace,
+			Name:      workloadName,
+			Labels:    labels,
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       string(poolType),
+				Name:       workloadName,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+		},
+	}
+	for i := range yas.Spec.Autoscaling.Metrics {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, *yas.Spec.Autoscaling.Metrics[i].DeepCopy())
+	}
+
+	if err := controllerutil.SetControllerReference(yas, hpa, r.scheme); err != nil {
+		return nil, err
+	}
+	return hpa, nil
+}
+
+// manageHorizontalPodAutoscalers creates or updates a HorizontalPodAutoscaler for the workload of each pool,
+// and deletes the HorizontalPodAutoscalers which are not needed any more.
+func (r *ReconcileYurtAppSet) manageHorizontalPodAutoscalers(yas *unitv1alpha1.YurtAppSet, nameToPool map[string]*Pool,
+	poolType unitv1alpha1.TemplateType) error {
+	expected := map[string]*autoscalingv2beta2.HorizontalPodAutoscaler{}
+	if yas.Spec.Autoscaling != nil {
+		for i := range yas.Spec.Topology.Pools {
+			pool := &yas.Spec.Topology.Pools[i]
+			p, ok := nameToPool[pool.Name]
+			if !ok || p.Spec.PoolRef == nil {
+				continue
+			}
+
+			hpa, err := r.newHorizontalPodAutoscaler(yas, pool, p.Spec.PoolRef.GetName(), poolType)
+			if err != nil {
+				return fmt.Errorf("fail to generate HorizontalPodAutoscaler of pool %s: %v", pool.Name, err)
+			}
+			expected[hpa.Name] = hpa
+		}
+	}
+
+	hpaList := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+	if err := r.List(context.TODO(), hpaList, client.InNamespace(yas.Namespace)); err != nil {
+		return fmt.Errorf("fail to list HorizontalPodAutoscalers: %v", err)
+	}
+
+	for i := range hpaList.Items {
+		hpa := &hpaList.Items[i]
+		if !metav1.IsControlledBy(hpa, yas) {
+			continue
+		}
+
+		want, ok := expected[hpa.Name]
+		if !ok {
+			klog.V(4).Infof("YurtAppSet %s/%s deletes HorizontalPodAutoscaler %s", yas.Namespace, yas.Name, hpa.Name)
+			if err := r.Delete(context.TODO(), hpa); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("fail to delete HorizontalPodAutoscaler %s: %v", hpa.Name, err)
+			}
+			continue
+		}
+		delete(expected, hpa.Name)
+
+		if reflect.DeepEqual(hpa.Labels, want.Labels) && reflect.DeepEqual(hpa.Spec, want.Spec) {
+			continue
+		}
+		hpa.Labels = want.Labels
+		hpa.Spec = want.Spec
+		klog.V(4).Infof("YurtAppSet %s/%s updates HorizontalPodAutoscaler %s", yas.Namespace, yas.Name, hpa.Name)
+		if err := r.Update(context.TODO(), hpa); err != nil {
+			return fmt.Errorf("fail to update HorizontalPodAutoscaler %s: %v", hpa.Name, err)
+		}
+	}
+
+	for _, hpa := range expected {
+		klog.V(4).Infof("YurtAppSet %s/%s creates HorizontalPodAutoscaler %s", yas.Namespace, yas.Name, hpa.Name)
+		if err := r.Create(context.TODO(), hpa); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("fail to create HorizontalPodAutoscaler %s: %v", hpa.Name, err)
+		}
+	}
+	return nil
+}
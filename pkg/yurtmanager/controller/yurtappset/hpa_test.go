@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+func TestApplyAutoscalingReplicas(t *testing.T) {
+	two, five := int32(2), int32(5)
+	yas := &appsv1alpha1.YurtAppSet{
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Autoscaling: &appsv1alpha1.YurtAppSetAutoscaling{MaxReplicas: 10},
+			Topology: appsv1alpha1.Topology{
+				Pools: []appsv1alpha1.Pool{
+					{Name: "hangzhou"},
+					{Name: "beijing", Autoscaling: &appsv1alpha1.PoolAutoscaling{MaxReplicas: &five}},
+					{Name: "shanghai", Autoscaling: &appsv1alpha1.PoolAutoscaling{MinReplicas: &two}},
+				},
+			},
+		},
+	}
+	nameToPool := map[string]*Pool{
+		"hangzhou": {Name: "hangzhou", Status: PoolStatus{ReplicasInfo: adapter.ReplicasInfo{Replicas: 4}}},
+		"beijing":  {Name: "beijing", Status: PoolStatus{ReplicasInfo: adapter.ReplicasInfo{Replicas: 8}}},
+	}
+
+	nextPatches := GetNextPatches(yas)
+	applyAutoscalingReplicas(yas, nameToPool, nextPatches)
+
+	expect := map[string]int32{"hangzhou": 4, "beijing": 5, "shanghai": 2}
+	for name, replicas := range expect {
+		if nextPatches[name].Replicas != replicas {
+			t.Errorf("expect replicas %d of pool %s, but got %d", replicas, name, nextPatches[name].Replicas)
+		}
+	}
+}
+
+func TestManageHorizontalPodAutoscalers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes resource, %v", err)
+	}
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+
+	three := int32(3)
+	yas := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "foo-uid"},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+			Topology: appsv1alpha1.Topology{
+				Pools: []appsv1alpha1.Pool{
+					{Name: "hangzhou", Autoscaling: &appsv1alpha1.PoolAutoscaling{MinReplicas: &three}},
+					{Name: "beijing"},
+				},
+			},
+		},
+	}
+	nameToPool := map[string]*Pool{
+		"hangzhou": {Name: "hangzhou", Spec: PoolSpec{PoolRef: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo-hangzhou-abcde"}}}},
+		"beijing":  {Name: "beijing", Spec: PoolSpec{PoolRef: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo-beijing-fghij"}}}},
+	}
+
+	testcases := map[string]struct {
+		autoscaling *appsv1alpha1.YurtAppSetAutoscaling
+		expect      map[string]int32
+	}{
+		"generate hpa for each pool": {
+			autoscaling: &appsv1alpha1.YurtAppSetAutoscaling{MaxReplicas: 10},
+			expect:      map[string]int32{"foo-hangzhou-abcde": 3, "foo-beijing-fghij": 1},
+		},
+		"update bounds of hpa": {
+			autoscaling: &appsv1alpha1.YurtAppSetAutoscaling{MinReplicas: &three, MaxReplicas: 6},
+			expect:      map[string]int32{"foo-hangzhou-abcde": 3, "foo-beijing-fghij": 3},
+		},
+		"delete hpa when autoscaling is disabled": {
+			expect: map[string]int32{},
+		},
+	}
+
+	r := &ReconcileYurtAppSet{
+		Client: fakeclient.NewClientBuilder().WithScheme(scheme).Build(),
+		scheme: scheme,
+	}
+	// the cases are run in order, so the hpa generated by the former case is updated or deleted by the latter ones.
+	for _, k := range []string{"generate hpa for each pool", "update bounds of hpa", "delete hpa when autoscaling is disabled"} {
+		tc := testcases[k]
+		t.Run(k, func(t *testing.T) {
+			yas.Spec.Autoscaling = tc.autoscaling
+			if err := r.manageHorizontalPodAutoscalers(yas, nameToPool, appsv1alpha1.DeploymentTemplateType); err != nil {
+				t.Fatalf("failed to manage hpa, %v", err)
+			}
+
+			hpaList := &autoscalingv2beta2.HorizontalPodAutoscalerList{}
+			if err := r.List(context.TODO(), hpaList, client.InNamespace("default")); err != nil {
+				t.Fatalf("failed to list hpa, %v", err)
+			}
+			if len(hpaList.Items) != len(tc.expect) {
+				t.Fatalf("expect %d hpa, but got %d", len(tc.expect), len(hpaList.Items))
+			}
+			for _, hpa := range hpaList.Items {
+				minReplicas, ok := tc.expect[hpa.Name]
+				if !ok {
+					t.Errorf("unexpected hpa %s", hpa.Name)
+					continue
+				}
+				if *hpa.Spec.MinReplicas != minReplicas {
+					t.Errorf("expect min replicas %d of hpa %s, but got %d", minReplicas, hpa.Name, *hpa.Spec.MinReplicas)
+				}
+				if hpa.Spec.ScaleTargetRef.Kind != "Deployment" || hpa.Spec.ScaleTargetRef.Name != hpa.Name {
+					t.Errorf("unexpected scale target %v of hpa %s", hpa.Spec.ScaleTargetRef, hpa.Name)
+				}
+			}
+		})
+	}
+}
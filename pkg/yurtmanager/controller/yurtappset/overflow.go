@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"k8s.io/klog/v2"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// applyOverflowReplicas shifts the replicas beyond the capacity of pools to their overflow pools in nextPatches,
+// and returns the replicas shifted. The replicas are computed from spec every time, so they are shifted back
+// as soon as the capacity of pools recovers.
+func (r *ReconcileYurtAppSet) applyOverflowReplicas(yas *unitv1alpha1.YurtAppSet,
+	nextPatches map[string]YurtAppSetPatches) ([]unitv1alpha1.YurtAppSetOverflowReplicas, error) {
+	capacities := map[string]int32{}
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		if pool.Overflow == nil {
+			continue
+		}
+
+		readyNodes, err := r.poolReadyNodes(pool)
+		if err != nil {
+			return nil, err
+		}
+		capacities[pool.Name] = readyNodes * pool.Overflow.ReplicasPerNode
+	}
+
+	var overflows []unitv1alpha1.YurtAppSetOverflowReplicas
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		if pool.Overflow == nil {
+			continue
+		}
+
+		excess := nextPatches[pool.Name].Replicas - capacities[pool.Name]
+		for _, target := range pool.Overflow.Pools {
+			if excess <= 0 {
+				break
+			}
+			if _, ok := nextPatches[target]; !ok || target == pool.Name {
+				continue
+			}
+
+			shift := excess
+			if capacity, ok := capacities[target]; ok {
+				if spare := capacity - nextPatches[target].Replicas; spare < shift {
+					shift = spare
+				}
+			}
+			if shift <= 0 {
+				continue
+			}
+
+			from, to := nextPatches[pool.Name], nextPatches[target]
+			from.Replicas -= shift
+			to.Replicas += shift
+			nextPatches[pool.Name], nextPatches[target] = from, to
+			excess -= shift
+
+			overflows = append(overflows, unitv1alpha1.YurtAppSetOverflowReplicas{
+				Pool:         pool.Name,
+				OverflowPool: target,
+				Replicas:     shift,
+			})
+			klog.V(4).Infof("YurtAppSet %s/%s shifts %d replicas from pool %s to overflow pool %s",
+				yas.Namespace, yas.Name, shift, pool.Name, target)
+		}
+	}
+	return overflows, nil
+}
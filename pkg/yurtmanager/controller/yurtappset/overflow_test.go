@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestApplyOverflowReplicas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+
+	newPool := func(name string, replicas int32, overflow *appsv1alpha1.PoolOverflow) appsv1alpha1.Pool {
+		return appsv1alpha1.Pool{Name: name, Replicas: &replicas, Overflow: overflow}
+	}
+	testcases := map[string]struct {
+		readyNodes     map[string]int32
+		pools          []appsv1alpha1.Pool
+		expectReplicas map[string]int32
+		expectOverflow []appsv1alpha1.YurtAppSetOverflowReplicas
+	}{
+		"capacity is enough": {
+			readyNodes: map[string]int32{"hangzhou": 3},
+			pools: []appsv1alpha1.Pool{
+				newPool("hangzhou", 3, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 1, Pools: []string{"beijing"}}),
+				newPool("beijing", 1, nil),
+			},
+			expectReplicas: map[string]int32{"hangzhou": 3, "beijing": 1},
+		},
+		"shift replicas to overflow pool": {
+			readyNodes: map[string]int32{"hangzhou": 1},
+			pools: []appsv1alpha1.Pool{
+				newPool("hangzhou", 3, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 1, Pools: []string{"beijing"}}),
+				newPool("beijing", 1, nil),
+			},
+			expectReplicas: map[string]int32{"hangzhou": 1, "beijing": 3},
+			expectOverflow: []appsv1alpha1.YurtAppSetOverflowReplicas{
+				{Pool: "hangzhou", OverflowPool: "beijing", Replicas: 2},
+			},
+		},
+		"respect capacity of overflow pools": {
+			readyNodes: map[string]int32{"hangzhou": 0, "shanghai": 2},
+			pools: []appsv1alpha1.Pool{
+				newPool("hangzhou", 4, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 2, Pools: []string{"shanghai", "beijing"}}),
+				newPool("shanghai", 3, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 2, Pools: []string{"beijing"}}),
+				newPool("beijing", 1, nil),
+			},
+			expectReplicas: map[string]int32{"hangzhou": 0, "shanghai": 4, "beijing": 4},
+			expectOverflow: []appsv1alpha1.YurtAppSetOverflowReplicas{
+				{Pool: "hangzhou", OverflowPool: "shanghai", Replicas: 1},
+				{Pool: "hangzhou", OverflowPool: "beijing", Replicas: 3},
+			},
+		},
+		"keep replicas without spare capacity": {
+			readyNodes: map[string]int32{"hangzhou": 1, "shanghai": 1},
+			pools: []appsv1alpha1.Pool{
+				newPool("hangzhou", 2, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 1, Pools: []string{"shanghai"}}),
+				newPool("shanghai", 1, &appsv1alpha1.PoolOverflow{ReplicasPerNode: 1, Pools: []string{"hangzhou"}}),
+			},
+			expectReplicas: map[string]int32{"hangzhou": 2, "shanghai": 1},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			builder := fakeclient.NewClientBuilder().WithScheme(scheme)
+			for name, readyNodes := range tc.readyNodes {
+				builder.WithObjects(&appsv1beta1.NodePool{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Status:     appsv1beta1.NodePoolStatus{ReadyNodeNum: readyNodes},
+				})
+			}
+			r := &ReconcileYurtAppSet{Client: builder.Build()}
+
+			yas := &appsv1alpha1.YurtAppSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+				Spec:       appsv1alpha1.YurtAppSetSpec{Topology: appsv1alpha1.Topology{Pools: tc.pools}},
+			}
+			nextPatches := GetNextPatches(yas)
+			overflows, err := r.applyOverflowReplicas(yas, nextPatches)
+			if err != nil {
+				t.Fatalf("failed to apply overflow replicas, %v", err)
+			}
+
+			for name, replicas := range tc.expectReplicas {
+				if nextPatches[name].Replicas != replicas {
+					t.Errorf("expect replicas %d of pool %s, but got %d", replicas, name, nextPatches[name].Replicas)
+				}
+			}
+			if !reflect.DeepEqual(overflows, tc.expectOverflow) {
+				t.Errorf("expect overflow replicas %v, but got %v", tc.expectOverflow, overflows)
+			}
+		})
+	}
+}
@@ -43,6 +43,7 @@ type PoolStatus struct {
 	ObservedGeneration int64
 	adapter.ReplicasInfo
 	PatchInfo          string
+	GatewayConfigHash  string
 	AvailableCondition v1.ConditionStatus
 }
 
@@ -20,13 +20,16 @@ package yurtappset
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
@@ -49,6 +52,13 @@ func (m *PoolControl) GetAllPools(yas *alpha1.YurtAppSet) (pools []*Pool, err er
 		return nil, err
 	}
 
+	var adopted []metav1.Object
+	if yas.Annotations[apps.AnnotationAdoptExistingWorkloads] == "true" {
+		if adopted, err = m.adoptExistingWorkloads(yas); err != nil {
+			return nil, err
+		}
+	}
+
 	setList := m.adapter.NewResourceListObject()
 	cliSetList, ok := setList.(client.ObjectList)
 	if !ok {
@@ -66,8 +76,16 @@ func (m *PoolControl) GetAllPools(yas *alpha1.YurtAppSet) (pools []*Pool, err er
 
 	v := reflect.ValueOf(setList).Elem().FieldByName("Items")
 	selected := make([]metav1.Object, v.Len())
+	listed := map[string]bool{}
 	for i := 0; i < v.Len(); i++ {
 		selected[i] = v.Index(i).Addr().Interface().(metav1.Object)
+		listed[selected[i].GetName()] = true
+	}
+	// the workloads just adopted may not be observed by the cache yet
+	for _, set := range adopted {
+		if !listed[set.GetName()] {
+			selected = append(selected, set)
+		}
 	}
 	claimedSets, err := manager.ClaimOwnedObjects(selected)
 	if err != nil {
@@ -84,12 +102,92 @@ func (m *PoolControl) GetAllPools(yas *alpha1.YurtAppSet) (pools []*Pool, err er
 	return pools, nil
 }
 
+// adoptExistingWorkloads takes ownership of the existing workloads which are not owned by any controller,
+// so pools reuse them instead of creating duplicates. At most one workload is adopted for each pool
+// which has no workload yet, and the workload is labeled to be selected by the YurtAppSet.
+func (m *PoolControl) adoptExistingWorkloads(yas *alpha1.YurtAppSet) ([]metav1.Object, error) {
+	selector, err := metav1.LabelSelectorAsSelector(yas.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	setList := m.adapter.NewResourceListObject()
+	cliSetList, ok := setList.(client.ObjectList)
+	if !ok {
+		return nil, errors.New("fail to convert runtime object to client.ObjectList")
+	}
+	if err := m.Client.List(context.TODO(), cliSetList, client.InNamespace(yas.Namespace)); err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(setList).Elem().FieldByName("Items")
+	owned := map[string]bool{}
+	var orphans []client.Object
+	for i := 0; i < v.Len(); i++ {
+		set := v.Index(i).Addr().Interface().(client.Object)
+		controllerRef := metav1.GetControllerOf(set)
+		switch {
+		case controllerRef == nil:
+			orphans = append(orphans, set)
+		case controllerRef.UID == yas.UID:
+			owned[set.GetLabels()[apps.PoolNameLabelKey]] = true
+		}
+	}
+
+	var adopted []metav1.Object
+	for i := range yas.Spec.Topology.Pools {
+		poolName := yas.Spec.Topology.Pools[i].Name
+		if owned[poolName] {
+			continue
+		}
+
+		for _, set := range orphans {
+			if metav1.GetControllerOf(set) != nil {
+				continue
+			}
+			if set.GetLabels()[apps.PoolNameLabelKey] != poolName && set.GetName() != fmt.Sprintf("%s-%s", yas.Name, poolName) {
+				continue
+			}
+			if !m.adapter.CanAdopt(yas, poolName, set) {
+				klog.Warningf("YurtAppSet %s/%s can't adopt %s as pool %s, its selector doesn't select the pods of pool",
+					yas.Namespace, yas.Name, set.GetName(), poolName)
+				continue
+			}
+
+			setLabels := map[string]string{}
+			for k, v := range set.GetLabels() {
+				setLabels[k] = v
+			}
+			for k, v := range yas.Spec.Selector.MatchLabels {
+				setLabels[k] = v
+			}
+			setLabels[apps.PoolNameLabelKey] = poolName
+			if !selector.Matches(labels.Set(setLabels)) {
+				continue
+			}
+			set.SetLabels(setLabels)
+			if err := controllerutil.SetControllerReference(yas, set, m.scheme); err != nil {
+				return nil, err
+			}
+			if err := m.Client.Update(context.TODO(), set); err != nil {
+				return nil, fmt.Errorf("fail to adopt %s as pool %s: %v", set.GetName(), poolName, err)
+			}
+			klog.Infof("YurtAppSet %s/%s adopts %s as pool %s", yas.Namespace, yas.Name, set.GetName(), poolName)
+			adopted = append(adopted, set)
+			break
+		}
+	}
+	return adopted, nil
+}
+
 // CreatePool creates the Pool depending on the inputs.
 func (m *PoolControl) CreatePool(yas *alpha1.YurtAppSet, poolName string, revision string,
 	replicas int32) error {
 
 	set := m.adapter.NewResourceObject()
-	m.adapter.ApplyPoolTemplate(yas, poolName, revision, replicas, set)
+	if err := m.adapter.ApplyPoolTemplate(yas, poolName, revision, replicas, set); err != nil {
+		return err
+	}
 
 	klog.V(4).Infof("Have %d replicas when creating Pool for YurtAppSet %s/%s", replicas, yas.Namespace, yas.Name)
 	cliSet, ok := set.(client.Object)
@@ -141,7 +239,7 @@ func (m *PoolControl) DeletePool(pool *Pool) error {
 
 // GetPoolFailure return the error message extracted form Pool workload status conditions.
 func (m *PoolControl) GetPoolFailure(pool *Pool) *string {
-	return m.adapter.GetPoolFailure()
+	return m.adapter.GetPoolFailure(pool.Spec.PoolRef)
 }
 
 // IsExpected checks the pool is expected revision or not.
@@ -177,6 +275,7 @@ func (m *PoolControl) convertToPool(set metav1.Object) (*Pool, error) {
 	if data, ok := set.GetAnnotations()[apps.AnnotationPatchKey]; ok {
 		pool.Status.PatchInfo = data
 	}
+	pool.Status.GatewayConfigHash = set.GetAnnotations()[apps.AnnotationGatewayConfigHash]
 	return pool, nil
 }
 
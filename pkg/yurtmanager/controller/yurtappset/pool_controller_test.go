@@ -18,6 +18,7 @@ limitations under the License.
 package yurtappset
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -28,7 +29,9 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	fakeclint "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 	adpt "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
 )
 
@@ -120,6 +123,10 @@ func TestPoolControl_GetAllPools(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -142,6 +149,79 @@ func TestPoolControl_GetAllPools(t *testing.T) {
 	}
 }
 
+func TestPoolControl_AdoptExistingWorkloads(t *testing.T) {
+	instance := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "foo-ns",
+			UID:         "foo-uid",
+			Annotations: map[string]string{apps.AnnotationAdoptExistingWorkloads: "true"},
+		},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+			WorkloadTemplate: appsv1alpha1.WorkloadTemplate{
+				DeploymentTemplate: &appsv1alpha1.DeploymentTemplateSpec{
+					Spec: appsv1.DeploymentSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+						},
+					},
+				},
+			},
+			Topology: appsv1alpha1.Topology{
+				Pools: []appsv1alpha1.Pool{{Name: "hangzhou"}, {Name: "beijing"}, {Name: "shanghai"}},
+			},
+		},
+	}
+	newDeployment := func(name string, labels, selector map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "foo-ns", Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &one,
+				Selector: &metav1.LabelSelector{MatchLabels: selector},
+			},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add kubernetes resource, %v", err)
+	}
+	fc := fakeclint.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		instance,
+		// adopted by name
+		newDeployment("foo-hangzhou", nil, map[string]string{"app": "foo"}),
+		// adopted by label
+		newDeployment("legacy-beijing", map[string]string{apps.PoolNameLabelKey: "beijing"}, map[string]string{"app": "foo"}),
+		// selector doesn't select the pods of pool
+		newDeployment("foo-shanghai", nil, map[string]string{"app": "bar"}),
+	).Build()
+	pc := PoolControl{
+		Client:  fc,
+		scheme:  scheme,
+		adapter: &adpt.DeploymentAdapter{Client: fc, Scheme: scheme},
+	}
+
+	pools, err := pc.GetAllPools(instance)
+	if err != nil {
+		t.Fatalf("failed to get the pools of yurtappset, %v", err)
+	}
+	got := map[string]string{}
+	for _, pool := range pools {
+		got[pool.Name] = pool.Spec.PoolRef.GetName()
+		if !metav1.IsControlledBy(pool.Spec.PoolRef, instance) {
+			t.Errorf("pool %s is not controlled by yurtappset", pool.Name)
+		}
+	}
+	expect := map[string]string{"hangzhou": "foo-hangzhou", "beijing": "legacy-beijing"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("expect pools %v, but got %v", expect, got)
+	}
+}
+
 func TestPoolControl_UpdatePool(t *testing.T) {
 
 	instance := &appsv1alpha1.YurtAppSet{
@@ -210,6 +290,10 @@ func TestPoolControl_UpdatePool(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -290,6 +374,10 @@ func TestPoolControl_DeletePool(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// calculatePoolStatuses aggregates the status of workload in each pool. The pool statuses are
+// sorted by pool name, and the pools which are not ready, not updated or failed are returned too.
+func calculatePoolStatuses(oldStatuses []unitv1alpha1.YurtAppSetPoolStatus, nameToPool map[string]*Pool,
+	expectedRevision string, control ControlInterface) ([]unitv1alpha1.YurtAppSetPoolStatus, []string) {
+	oldConditions := make(map[string][]unitv1alpha1.YurtAppSetCondition, len(oldStatuses))
+	for _, status := range oldStatuses {
+		oldConditions[status.Name] = status.Conditions
+	}
+
+	names := make([]string, 0, len(nameToPool))
+	for name := range nameToPool {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var notReadyPools []string
+	poolStatuses := make([]unitv1alpha1.YurtAppSetPoolStatus, 0, len(names))
+	for _, name := range names {
+		pool := nameToPool[name]
+		status := unitv1alpha1.YurtAppSetPoolStatus{
+			Name:            name,
+			WorkloadName:    pool.Spec.PoolRef.GetName(),
+			Revision:        pool.Spec.PoolRef.GetLabels()[apps.ControllerRevisionHashLabelKey],
+			Replicas:        pool.Status.Replicas,
+			ReadyReplicas:   pool.Status.ReadyReplicas,
+			UpdatedReplicas: pool.Status.UpdatedReplicas,
+			Conditions:      oldConditions[name],
+		}
+
+		notReady := false
+		if pool.Status.ReadyReplicas >= pool.Status.Replicas {
+			status.Conditions = setPoolCondition(status.Conditions, NewYurtAppSetCondition(unitv1alpha1.PoolReady, corev1.ConditionTrue, "", ""))
+		} else {
+			notReady = true
+			status.Conditions = setPoolCondition(status.Conditions, NewYurtAppSetCondition(unitv1alpha1.PoolReady, corev1.ConditionFalse, "NotReady",
+				fmt.Sprintf("%d/%d replicas are ready", pool.Status.ReadyReplicas, pool.Status.Replicas)))
+		}
+
+		if !control.IsExpected(pool, expectedRevision) && pool.Status.UpdatedReplicas >= pool.Status.Replicas {
+			status.Conditions = setPoolCondition(status.Conditions, NewYurtAppSetCondition(unitv1alpha1.PoolUpdated, corev1.ConditionTrue, "", ""))
+		} else {
+			notReady = true
+			status.Conditions = setPoolCondition(status.Conditions, NewYurtAppSetCondition(unitv1alpha1.PoolUpdated, corev1.ConditionFalse, "Updating",
+				fmt.Sprintf("%d/%d replicas are updated to revision %s", pool.Status.UpdatedReplicas, pool.Status.Replicas, expectedRevision)))
+		}
+
+		if failure := control.GetPoolFailure(pool); failure != nil {
+			notReady = true
+			status.Conditions = setPoolCondition(status.Conditions, NewYurtAppSetCondition(unitv1alpha1.PoolFailure, corev1.ConditionTrue, "Error", *failure))
+		} else {
+			status.Conditions = filterOutCondition(status.Conditions, unitv1alpha1.PoolFailure)
+		}
+
+		if notReady {
+			notReadyPools = append(notReadyPools, name)
+		}
+		poolStatuses = append(poolStatuses, status)
+	}
+
+	return poolStatuses, notReadyPools
+}
+
+// setPoolCondition updates the conditions of pool to include the provided condition, the last
+// transition time is kept if the status of condition is not changed.
+func setPoolCondition(conditions []unitv1alpha1.YurtAppSetCondition, condition *unitv1alpha1.YurtAppSetCondition) []unitv1alpha1.YurtAppSetCondition {
+	for _, c := range conditions {
+		if c.Type != condition.Type {
+			continue
+		}
+		if c.Status == condition.Status && c.Reason == condition.Reason && c.Message == condition.Message {
+			return conditions
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+		break
+	}
+	return append(filterOutCondition(conditions, condition.Type), *condition)
+}
+
+// getPoolCondition returns the condition of pool with the provided type.
+func getPoolCondition(status unitv1alpha1.YurtAppSetPoolStatus, condType unitv1alpha1.YurtAppSetConditionType) *unitv1alpha1.YurtAppSetCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+func TestCalculatePoolStatuses(t *testing.T) {
+	control := &PoolControl{adapter: &adapter.DeploymentAdapter{}}
+	failedPool := newRolloutPool("shanghai", "v2", 2, 2, 2)
+	failedPool.Spec.PoolRef.(*appsv1.Deployment).Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: "timeout"},
+	}
+
+	testcases := map[string]struct {
+		nameToPool         map[string]*Pool
+		expectPools        []string
+		expectNotReady     []string
+		expectPoolFailures map[string]string
+	}{
+		"all pools are ready and updated": {
+			nameToPool: map[string]*Pool{
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 2, 2),
+				"beijing":  newRolloutPool("beijing", "v2", 3, 3, 3),
+			},
+			expectPools: []string{"beijing", "hangzhou"},
+		},
+		"pools are not ready or not updated": {
+			nameToPool: map[string]*Pool{
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 1, 2),
+				"beijing":  newRolloutPool("beijing", "v1", 3, 3, 3),
+				"shenzhen": newRolloutPool("shenzhen", "v2", 2, 2, 2),
+			},
+			expectPools:    []string{"beijing", "hangzhou", "shenzhen"},
+			expectNotReady: []string{"beijing", "hangzhou"},
+		},
+		"pool is failed": {
+			nameToPool: map[string]*Pool{
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 2, 2),
+				"shanghai": failedPool,
+			},
+			expectPools:        []string{"hangzhou", "shanghai"},
+			expectNotReady:     []string{"shanghai"},
+			expectPoolFailures: map[string]string{"shanghai": "ProgressDeadlineExceeded: timeout"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			statuses, notReady := calculatePoolStatuses(nil, tc.nameToPool, "v2", control)
+			var pools []string
+			for _, status := range statuses {
+				pools = append(pools, status.Name)
+				condition := getPoolCondition(status, appsv1alpha1.PoolFailure)
+				if expect, ok := tc.expectPoolFailures[status.Name]; ok {
+					if condition == nil || condition.Message != expect {
+						t.Errorf("expect failure %q of pool %s, but got %v", expect, status.Name, condition)
+					}
+				} else if condition != nil {
+					t.Errorf("expect no failure of pool %s, but got %v", status.Name, condition)
+				}
+			}
+			if !reflect.DeepEqual(pools, tc.expectPools) {
+				t.Errorf("expect pools %v, but got %v", tc.expectPools, pools)
+			}
+			if !reflect.DeepEqual(notReady, tc.expectNotReady) {
+				t.Errorf("expect not ready pools %v, but got %v", tc.expectNotReady, notReady)
+			}
+		})
+	}
+}
+
+func TestSetPoolCondition(t *testing.T) {
+	lastTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	conditions := []appsv1alpha1.YurtAppSetCondition{
+		{Type: appsv1alpha1.PoolReady, Status: corev1.ConditionFalse, Reason: "NotReady", Message: "1/2 replicas are ready", LastTransitionTime: lastTime},
+	}
+
+	conditions = setPoolCondition(conditions, NewYurtAppSetCondition(appsv1alpha1.PoolReady, corev1.ConditionFalse, "NotReady", "0/2 replicas are ready"))
+	if len(conditions) != 1 || conditions[0].Message != "0/2 replicas are ready" || !conditions[0].LastTransitionTime.Equal(&lastTime) {
+		t.Errorf("expect message is updated and transition time is kept, but got %v", conditions)
+	}
+
+	conditions = setPoolCondition(conditions, NewYurtAppSetCondition(appsv1alpha1.PoolReady, corev1.ConditionTrue, "", ""))
+	if len(conditions) != 1 || conditions[0].Status != corev1.ConditionTrue || conditions[0].LastTransitionTime.Equal(&lastTime) {
+		t.Errorf("expect condition transits to true, but got %v", conditions)
+	}
+}
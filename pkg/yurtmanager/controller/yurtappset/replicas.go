@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+// proportionalReplicas computes the replicas of pool from the number of ready nodes,
+// and clamps the result into [min, max].
+func proportionalReplicas(p *unitv1alpha1.ProportionalReplicas, readyNodes int32) int32 {
+	replicas := (readyNodes*p.Ratio + 99) / 100
+	if p.Min != nil && replicas < *p.Min {
+		replicas = *p.Min
+	}
+	if p.Max != nil && replicas > *p.Max {
+		replicas = *p.Max
+	}
+	return replicas
+}
+
+// applyProportionalReplicas sets the replicas of pools with ProportionalReplicas in nextPatches
+// according to the number of ready nodes in their NodePools.
+func (r *ReconcileYurtAppSet) applyProportionalReplicas(yas *unitv1alpha1.YurtAppSet, nextPatches map[string]YurtAppSetPatches) error {
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		if pool.ProportionalReplicas == nil {
+			continue
+		}
+
+		readyNodes, err := r.poolReadyNodes(pool)
+		if err != nil {
+			return err
+		}
+
+		patches := nextPatches[pool.Name]
+		patches.Replicas = proportionalReplicas(pool.ProportionalReplicas, readyNodes)
+		nextPatches[pool.Name] = patches
+		klog.V(4).Infof("YurtAppSet %s/%s computes replicas %d of pool %s from %d ready nodes",
+			yas.Namespace, yas.Name, patches.Replicas, pool.Name, readyNodes)
+	}
+	return nil
+}
+
+// poolReadyNodes returns the number of ready nodes in the NodePool of pool, 0 is returned if the NodePool is not found.
+func (r *ReconcileYurtAppSet) poolReadyNodes(pool *unitv1alpha1.Pool) (int32, error) {
+	np := &appsv1beta1.NodePool{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: adapter.PoolNodePoolName(pool)}, np); err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("fail to get NodePool of pool %s: %v", pool.Name, err)
+	}
+	return np.Status.ReadyNodeNum, nil
+}
+
+// enqueueYurtAppSetsForNodePool returns a map func which enqueues YurtAppSets whose pools
+// compute replicas or capacity from the ready nodes of the NodePool, render config templates
+// with the variables of the NodePool, spread replicas by the labels and topology of the NodePool,
+// or inject the config of the gateway of the NodePool.
+func enqueueYurtAppSetsForNodePool(c client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		yasList := &unitv1alpha1.YurtAppSetList{}
+		if err := c.List(context.TODO(), yasList); err != nil {
+			klog.Errorf("fail to list YurtAppSets for NodePool %s: %v", obj.GetName(), err)
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, yas := range yasList.Items {
+			for i := range yas.Spec.Topology.Pools {
+				pool := &yas.Spec.Topology.Pools[i]
+				dependent := pool.ProportionalReplicas != nil || pool.Overflow != nil || len(yas.Spec.ConfigTemplates) != 0 ||
+					len(yas.Spec.Topology.SpreadConstraints) != 0 || yas.Annotations[apps.AnnotationInjectGatewayConfig] == "true"
+				if dependent && adapter.PoolNodePoolName(pool) == obj.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Namespace: yas.Namespace, Name: yas.Name},
+					})
+					break
+				}
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestProportionalReplicas(t *testing.T) {
+	one, three := int32(1), int32(3)
+	testcases := map[string]struct {
+		proportional appsv1alpha1.ProportionalReplicas
+		readyNodes   int32
+		expect       int32
+	}{
+		"one replica per node": {
+			proportional: appsv1alpha1.ProportionalReplicas{Ratio: 100},
+			readyNodes:   4,
+			expect:       4,
+		},
+		"round up replicas": {
+			proportional: appsv1alpha1.ProportionalReplicas{Ratio: 50},
+			readyNodes:   3,
+			expect:       2,
+		},
+		"clamp to min": {
+			proportional: appsv1alpha1.ProportionalReplicas{Ratio: 50, Min: &one},
+			readyNodes:   0,
+			expect:       1,
+		},
+		"clamp to max": {
+			proportional: appsv1alpha1.ProportionalReplicas{Ratio: 200, Max: &three},
+			readyNodes:   5,
+			expect:       3,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := proportionalReplicas(&tc.proportional, tc.readyNodes); got != tc.expect {
+				t.Errorf("expect replicas %d, but got %d", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestApplyProportionalReplicas(t *testing.T) {
+	two := int32(2)
+	scheme := runtime.NewScheme()
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	np := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou-pool"},
+		Status:     appsv1beta1.NodePoolStatus{ReadyNodeNum: 5},
+	}
+	r := &ReconcileYurtAppSet{Client: fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(np).Build()}
+
+	yas := &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Topology: appsv1alpha1.Topology{
+				Pools: []appsv1alpha1.Pool{
+					{
+						Name: "hangzhou",
+						NodeSelectorTerm: corev1.NodeSelectorTerm{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: apps.NodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"hangzhou-pool"}},
+							},
+						},
+						ProportionalReplicas: &appsv1alpha1.ProportionalReplicas{Ratio: 40},
+					},
+					{
+						Name:                 "beijing",
+						ProportionalReplicas: &appsv1alpha1.ProportionalReplicas{Ratio: 100, Min: &two},
+					},
+					{
+						Name:     "shanghai",
+						Replicas: &two,
+					},
+				},
+			},
+		},
+	}
+
+	nextPatches := GetNextPatches(yas)
+	if err := r.applyProportionalReplicas(yas, nextPatches); err != nil {
+		t.Fatalf("failed to apply proportional replicas, %v", err)
+	}
+
+	expect := map[string]int32{"hangzhou": 2, "beijing": 2, "shanghai": 2}
+	for name, replicas := range expect {
+		if nextPatches[name].Replicas != replicas {
+			t.Errorf("expect replicas %d of pool %s, but got %d", replicas, name, nextPatches[name].Replicas)
+		}
+	}
+}
@@ -280,6 +280,22 @@ func getYurtAppSetPatch(yas *appsalphav1.YurtAppSet) ([]byte, error) {
 	template := spec["workloadTemplate"].(map[string]interface{})
 	specCopy["workloadTemplate"] = template
 	template["$patch"] = "replace"
+	// config templates change the volumes of workloads, so they are part of the revision
+	if configTemplates, ok := spec["configTemplates"]; ok {
+		specCopy["configTemplates"] = configTemplates
+	}
+	// only the ConfigMaps of bundle are referenced by workloads
+	if bundle, ok := spec["bundle"].([]interface{}); ok {
+		var configMaps []interface{}
+		for _, tmpl := range bundle {
+			if t, ok := tmpl.(map[string]interface{}); ok && t["configMapTemplate"] != nil {
+				configMaps = append(configMaps, tmpl)
+			}
+		}
+		if len(configMaps) != 0 {
+			specCopy["bundle"] = configMaps
+		}
+	}
 	objCopy["spec"] = specCopy
 	patch, err := json.Marshal(objCopy)
 	return patch, err
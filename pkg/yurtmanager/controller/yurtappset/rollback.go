@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// rollback reverts the spec stored in the revision specified by RollbackTo, including workloadTemplate,
+// configTemplates and the ConfigMaps of bundle, and clears RollbackTo. The reverted spec is rolled out
+// across pools as the newest revision.
+func (r *ReconcileYurtAppSet) rollback(yas *unitv1alpha1.YurtAppSet) error {
+	revisions, err := r.controlledHistories(yas)
+	if err != nil {
+		return err
+	}
+	history.SortControllerRevisions(revisions)
+
+	toRevision := yas.Spec.RollbackTo.Revision
+	target := findRollbackRevision(revisions, toRevision)
+	if target == nil {
+		klog.Warningf("YurtAppSet %s/%s could not find revision %d to rollback", yas.Namespace, yas.Name, toRevision)
+		r.recorder.Eventf(yas.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeRollback),
+			"Unable to find revision %d to rollback", toRevision)
+		yas.Spec.RollbackTo = nil
+		return r.Client.Update(context.TODO(), yas)
+	}
+
+	spec, err := getRevisionSpec(target)
+	if err != nil {
+		return err
+	}
+	restoreRevisionSpec(yas, spec)
+	yas.Spec.RollbackTo = nil
+	if err := r.Client.Update(context.TODO(), yas); err != nil {
+		return err
+	}
+
+	klog.Infof("YurtAppSet %s/%s is rolled back to revision %d(%s)", yas.Namespace, yas.Name, target.Revision, target.Name)
+	r.recorder.Eventf(yas.DeepCopy(), corev1.EventTypeNormal, fmt.Sprintf("Successful%s", eventTypeRollback),
+		"Rolled back to revision %d(%s)", target.Revision, target.Name)
+	return nil
+}
+
+// findRollbackRevision returns the revision to rollback to from revisions sorted by Revision,
+// the last revision before the newest one is returned if toRevision is 0.
+func findRollbackRevision(revisions []*apps.ControllerRevision, toRevision int64) *apps.ControllerRevision {
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return nil
+		}
+		return revisions[len(revisions)-2]
+	}
+
+	for _, revision := range revisions {
+		if revision.Revision == toRevision {
+			return revision
+		}
+	}
+	return nil
+}
+
+// getRevisionSpec decodes the spec fields stored in revision by getYurtAppSetPatch.
+func getRevisionSpec(revision *apps.ControllerRevision) (*unitv1alpha1.YurtAppSetSpec, error) {
+	patch := struct {
+		Spec unitv1alpha1.YurtAppSetSpec `json:"spec"`
+	}{}
+	if err := json.Unmarshal(revision.Data.Raw, &patch); err != nil {
+		return nil, fmt.Errorf("could not decode spec from revision %s, %v", revision.Name, err)
+	}
+	return &patch.Spec, nil
+}
+
+// restoreRevisionSpec reverts the spec fields of YurtAppSet which are stored in revision. Only the
+// ConfigMaps of bundle are stored in revision, so the other bundle templates are kept as they are.
+func restoreRevisionSpec(yas *unitv1alpha1.YurtAppSet, spec *unitv1alpha1.YurtAppSetSpec) {
+	yas.Spec.WorkloadTemplate = spec.WorkloadTemplate
+	yas.Spec.ConfigTemplates = spec.ConfigTemplates
+
+	var bundle []unitv1alpha1.BundleTemplate
+	for _, tmpl := range yas.Spec.Bundle {
+		if tmpl.ConfigMapTemplate == nil {
+			bundle = append(bundle, tmpl)
+		}
+	}
+	for _, tmpl := range spec.Bundle {
+		if tmpl.ConfigMapTemplate != nil {
+			bundle = append(bundle, tmpl)
+		}
+	}
+	yas.Spec.Bundle = bundle
+}
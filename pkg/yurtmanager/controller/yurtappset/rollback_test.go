@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func TestFindRollbackRevision(t *testing.T) {
+	revisions := []*apps.ControllerRevision{
+		{ObjectMeta: metav1.ObjectMeta{Name: "yas-1"}, Revision: 1},
+		{ObjectMeta: metav1.ObjectMeta{Name: "yas-2"}, Revision: 2},
+		{ObjectMeta: metav1.ObjectMeta{Name: "yas-3"}, Revision: 3},
+	}
+	testcases := map[string]struct {
+		revisions  []*apps.ControllerRevision
+		toRevision int64
+		expect     string
+	}{
+		"rollback to the last revision": {
+			revisions: revisions,
+			expect:    "yas-2",
+		},
+		"rollback to the specified revision": {
+			revisions:  revisions,
+			toRevision: 1,
+			expect:     "yas-1",
+		},
+		"specified revision is not found": {
+			revisions:  revisions,
+			toRevision: 5,
+		},
+		"no last revision": {
+			revisions: revisions[:1],
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var got string
+			if revision := findRollbackRevision(tc.revisions, tc.toRevision); revision != nil {
+				got = revision.Name
+			}
+			if got != tc.expect {
+				t.Errorf("expect revision %q, but got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestRestoreRevisionSpec(t *testing.T) {
+	replicas := int32(2)
+	template := unitv1alpha1.WorkloadTemplate{
+		DeploymentTemplate: &unitv1alpha1.DeploymentTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+			Spec: apps.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "demo", Image: "nginx:1.19"}}},
+				},
+			},
+		},
+	}
+	configTemplates := []unitv1alpha1.PoolConfigTemplate{{Name: "conf", Data: map[string]string{"pool": "{{ .PoolName }}"}}}
+	configMapBundle := unitv1alpha1.BundleTemplate{
+		Name:              "settings",
+		ConfigMapTemplate: &unitv1alpha1.ConfigMapTemplateSpec{Data: map[string]string{"level": "info"}},
+	}
+	serviceBundle := unitv1alpha1.BundleTemplate{
+		Name:            "svc",
+		ServiceTemplate: &unitv1alpha1.ServiceTemplateSpec{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}}},
+	}
+	old := &unitv1alpha1.YurtAppSet{
+		Spec: unitv1alpha1.YurtAppSetSpec{
+			WorkloadTemplate: template,
+			ConfigTemplates:  configTemplates,
+			Bundle:           []unitv1alpha1.BundleTemplate{configMapBundle},
+		},
+	}
+	patch, err := getYurtAppSetPatch(old)
+	if err != nil {
+		t.Fatalf("failed to get patch of YurtAppSet, %v", err)
+	}
+	spec, err := getRevisionSpec(&apps.ControllerRevision{Data: runtime.RawExtension{Raw: patch}})
+	if err != nil {
+		t.Fatalf("failed to get spec from revision, %v", err)
+	}
+
+	// the newer spec changes all the fields stored in revision, and adds a Service to bundle
+	yas := old.DeepCopy()
+	yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Spec.Containers[0].Image = "nginx:1.20"
+	yas.Spec.ConfigTemplates = nil
+	yas.Spec.Bundle = []unitv1alpha1.BundleTemplate{serviceBundle, *configMapBundle.DeepCopy()}
+	yas.Spec.Bundle[1].ConfigMapTemplate.Data["level"] = "debug"
+
+	restoreRevisionSpec(yas, spec)
+	if !reflect.DeepEqual(yas.Spec.WorkloadTemplate, template) {
+		t.Errorf("expect workloadTemplate %v, but got %v", template, yas.Spec.WorkloadTemplate)
+	}
+	if !reflect.DeepEqual(yas.Spec.ConfigTemplates, configTemplates) {
+		t.Errorf("expect configTemplates %v, but got %v", configTemplates, yas.Spec.ConfigTemplates)
+	}
+	if expect := []unitv1alpha1.BundleTemplate{serviceBundle, configMapBundle}; !reflect.DeepEqual(yas.Spec.Bundle, expect) {
+		t.Errorf("expect bundle %v, but got %v", expect, yas.Spec.Bundle)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+const defaultMinReadyPercent int32 = 100
+
+// rolloutSteps returns the pools of YurtAppSet in rollout order, and pools in the same step
+// are updated at the same time. The pools listed in RolloutStrategy are updated one by one,
+// and the other pools are updated together at last.
+func rolloutSteps(yas *unitv1alpha1.YurtAppSet) [][]string {
+	topologyPools := sets.String{}
+	for _, pool := range yas.Spec.Topology.Pools {
+		topologyPools.Insert(pool.Name)
+	}
+
+	var steps [][]string
+	listedPools := sets.String{}
+	for _, pool := range yas.Spec.RolloutStrategy.Pools {
+		if !topologyPools.Has(pool.Name) || listedPools.Has(pool.Name) {
+			continue
+		}
+		listedPools.Insert(pool.Name)
+		steps = append(steps, []string{pool.Name})
+	}
+
+	if restPools := topologyPools.Difference(listedPools); restPools.Len() != 0 {
+		steps = append(steps, restPools.List())
+	}
+	return steps
+}
+
+// minReadyPercent returns the health gate of the pool in RolloutStrategy.
+func minReadyPercent(yas *unitv1alpha1.YurtAppSet, poolName string) int32 {
+	for _, pool := range yas.Spec.RolloutStrategy.Pools {
+		if pool.Name == poolName && pool.MinReadyPercent != nil {
+			return *pool.MinReadyPercent
+		}
+	}
+	return defaultMinReadyPercent
+}
+
+// isPoolRolledOut checks the pool is updated to the revision and passes its health gate,
+// which means enough replicas of the pool are updated and ready.
+func (r *ReconcileYurtAppSet) isPoolRolledOut(yas *unitv1alpha1.YurtAppSet, pool *Pool, revision string,
+	poolType unitv1alpha1.TemplateType) bool {
+	if pool == nil || r.poolControls[poolType].IsExpected(pool, revision) {
+		return false
+	}
+
+	if pool.Status.ObservedGeneration < pool.Spec.PoolRef.GetGeneration() {
+		return false
+	}
+
+	ready := pool.Status.ReadyReplicas
+	if pool.Status.UpdatedReplicas < ready {
+		ready = pool.Status.UpdatedReplicas
+	}
+	return ready*100 >= minReadyPercent(yas, pool.Name)*pool.Status.Replicas
+}
+
+// rolloutPools walks through the pools in rollout order, and returns the pools which are
+// allowed to be updated to the revision and the progress of rollout. Only the pools in the
+// first step which is not rolled out are allowed, and none of them if rollout is paused.
+func (r *ReconcileYurtAppSet) rolloutPools(yas *unitv1alpha1.YurtAppSet, nameToPool map[string]*Pool,
+	revision string, poolType unitv1alpha1.TemplateType) (sets.String, *unitv1alpha1.YurtAppSetRolloutStatus) {
+	allowed := sets.String{}
+	status := &unitv1alpha1.YurtAppSetRolloutStatus{Revision: revision}
+	for _, step := range rolloutSteps(yas) {
+		rolledOut := true
+		for _, name := range step {
+			if !r.isPoolRolledOut(yas, nameToPool[name], revision, poolType) {
+				rolledOut = false
+				break
+			}
+		}
+
+		if rolledOut {
+			status.UpdatedPools = append(status.UpdatedPools, step...)
+			continue
+		}
+
+		status.UpdatingPools = step
+		if !yas.Spec.RolloutStrategy.Paused {
+			allowed.Insert(step...)
+		}
+		break
+	}
+	return allowed, status
+}
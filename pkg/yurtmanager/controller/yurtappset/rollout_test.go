@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+func newRolloutPool(name, revision string, replicas, ready, updated int32) *Pool {
+	return &Pool{
+		Name: name,
+		Spec: PoolSpec{
+			PoolRef: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{apps.ControllerRevisionHashLabelKey: revision},
+				},
+			},
+		},
+		Status: PoolStatus{
+			ReplicasInfo: adapter.ReplicasInfo{
+				Replicas:        replicas,
+				ReadyReplicas:   ready,
+				UpdatedReplicas: updated,
+			},
+		},
+	}
+}
+
+func TestRolloutPools(t *testing.T) {
+	fifty := int32(50)
+	pools := appsv1alpha1.Topology{Pools: []appsv1alpha1.Pool{{Name: "beijing"}, {Name: "hangzhou"}, {Name: "shanghai"}, {Name: "shenzhen"}}}
+	testcases := map[string]struct {
+		strategy       *appsv1alpha1.YurtAppSetRolloutStrategy
+		nameToPool     map[string]*Pool
+		expectAllowed  sets.String
+		expectUpdated  []string
+		expectUpdating []string
+	}{
+		"first pool is allowed to be updated": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou"}, {Name: "beijing"}}},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v1", 2, 2, 2),
+				"hangzhou": newRolloutPool("hangzhou", "v1", 2, 2, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString("hangzhou"),
+			expectUpdating: []string{"hangzhou"},
+		},
+		"unhealthy pool blocks the rollout": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou"}, {Name: "beijing"}}},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v1", 2, 2, 2),
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 1, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString("hangzhou"),
+			expectUpdating: []string{"hangzhou"},
+		},
+		"health gate with min ready percent": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou", MinReadyPercent: &fifty}, {Name: "beijing"}}},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v1", 2, 2, 2),
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 1, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString("beijing"),
+			expectUpdated:  []string{"hangzhou"},
+			expectUpdating: []string{"beijing"},
+		},
+		"unlisted pools are updated together at last": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou"}, {Name: "beijing"}}},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v2", 2, 2, 2),
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 2, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString("shanghai", "shenzhen"),
+			expectUpdated:  []string{"hangzhou", "beijing"},
+			expectUpdating: []string{"shanghai", "shenzhen"},
+		},
+		"paused rollout allows no pool": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou"}}, Paused: true},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v1", 2, 2, 2),
+				"hangzhou": newRolloutPool("hangzhou", "v2", 2, 2, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString(),
+			expectUpdated:  []string{"hangzhou"},
+			expectUpdating: []string{"beijing", "shanghai", "shenzhen"},
+		},
+		"pool not provisioned blocks the rollout": {
+			strategy: &appsv1alpha1.YurtAppSetRolloutStrategy{Pools: []appsv1alpha1.RolloutPool{{Name: "hangzhou"}, {Name: "beijing"}}},
+			nameToPool: map[string]*Pool{
+				"beijing":  newRolloutPool("beijing", "v1", 2, 2, 2),
+				"shanghai": newRolloutPool("shanghai", "v1", 2, 2, 2),
+				"shenzhen": newRolloutPool("shenzhen", "v1", 2, 2, 2),
+			},
+			expectAllowed:  sets.NewString("hangzhou"),
+			expectUpdating: []string{"hangzhou"},
+		},
+	}
+
+	r := &ReconcileYurtAppSet{
+		poolControls: map[appsv1alpha1.TemplateType]ControlInterface{
+			appsv1alpha1.DeploymentTemplateType: &PoolControl{adapter: &adapter.DeploymentAdapter{}},
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			yas := &appsv1alpha1.YurtAppSet{
+				Spec: appsv1alpha1.YurtAppSetSpec{
+					Topology:        pools,
+					RolloutStrategy: tc.strategy,
+				},
+			}
+
+			allowed, status := r.rolloutPools(yas, tc.nameToPool, "v2", appsv1alpha1.DeploymentTemplateType)
+			if !allowed.Equal(tc.expectAllowed) {
+				t.Errorf("expect allowed pools %v, but got %v", tc.expectAllowed.List(), allowed.List())
+			}
+			if status.Revision != "v2" {
+				t.Errorf("expect rollout revision v2, but got %s", status.Revision)
+			}
+			if !reflect.DeepEqual(status.UpdatedPools, tc.expectUpdated) {
+				t.Errorf("expect updated pools %v, but got %v", tc.expectUpdated, status.UpdatedPools)
+			}
+			if !reflect.DeepEqual(status.UpdatingPools, tc.expectUpdating) {
+				t.Errorf("expect updating pools %v, but got %v", tc.expectUpdating, status.UpdatingPools)
+			}
+		})
+	}
+}
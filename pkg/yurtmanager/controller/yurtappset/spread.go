@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
+)
+
+// spreadDomain returns the domain of pool under the topology key, an empty string is returned
+// if the NodePool of pool has no such topology.
+func spreadDomain(key unitv1alpha1.SpreadTopologyKey, pool *unitv1alpha1.Pool, np *appsv1beta1.NodePool) string {
+	if len(key) == 0 || key == unitv1alpha1.PoolSpreadTopologyKey {
+		return pool.Name
+	}
+	if np == nil || np.Spec.Topology == nil {
+		return ""
+	}
+	switch key {
+	case unitv1alpha1.RegionSpreadTopologyKey:
+		return np.Spec.Topology.Region
+	case unitv1alpha1.ZoneSpreadTopologyKey:
+		return np.Spec.Topology.Zone
+	case unitv1alpha1.SiteSpreadTopologyKey:
+		return np.Spec.Topology.Site
+	}
+	return ""
+}
+
+// spreadSelectedPools returns whether each pool is selected by the constraint, a pool whose NodePool
+// is not found is only selected when the constraint selects all pools.
+func spreadSelectedPools(constraint *unitv1alpha1.PoolSpreadConstraint, pools []unitv1alpha1.Pool,
+	nodePools map[string]*appsv1beta1.NodePool) ([]bool, error) {
+	selected := make([]bool, len(pools))
+	if constraint.PoolSelector == nil {
+		for i := range selected {
+			selected[i] = true
+		}
+		return selected, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(constraint.PoolSelector)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if np := nodePools[pools[i].Name]; np != nil {
+			selected[i] = selector.Matches(labels.Set(np.Labels))
+		}
+	}
+	return selected, nil
+}
+
+// spreadReplicas moves the replicas between pools to satisfy the spread constraints, and returns
+// the messages of constraints which can't be satisfied. The lower limits of pools are satisfied first,
+// then the replicas beyond the upper limit of each domain are moved to the pools out of the domain.
+func spreadReplicas(constraints []unitv1alpha1.PoolSpreadConstraint, pools []unitv1alpha1.Pool,
+	nodePools map[string]*appsv1beta1.NodePool, replicas map[string]int32) ([]string, error) {
+	selections := make([][]bool, len(constraints))
+	floors := make([]int32, len(pools))
+	for i := range constraints {
+		selected, err := spreadSelectedPools(&constraints[i], pools, nodePools)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool selector of spread constraint %d: %v", i, err)
+		}
+		selections[i] = selected
+		if constraints[i].MinReplicasPerPool == nil {
+			continue
+		}
+		for j := range pools {
+			if selected[j] && floors[j] < *constraints[i].MinReplicasPerPool {
+				floors[j] = *constraints[i].MinReplicasPerPool
+			}
+		}
+	}
+
+	// takeOne removes a replica from the pool with the most replicas above its lower limit among
+	// the candidates, and returns false if no candidate can spare a replica.
+	takeOne := func(candidate func(int) bool) bool {
+		from := -1
+		for j := range pools {
+			if !candidate(j) || replicas[pools[j].Name] <= floors[j] {
+				continue
+			}
+			if from == -1 || replicas[pools[j].Name]-floors[j] > replicas[pools[from].Name]-floors[from] {
+				from = j
+			}
+		}
+		if from == -1 {
+			return false
+		}
+		replicas[pools[from].Name]--
+		return true
+	}
+
+	for j := range pools {
+		for replicas[pools[j].Name] < floors[j] {
+			self := j
+			takeOne(func(k int) bool { return k != self })
+			replicas[pools[j].Name]++
+		}
+	}
+
+	var unsatisfied []string
+	for i := range constraints {
+		constraint := &constraints[i]
+		if constraint.MaxPercentPerDomain == nil {
+			continue
+		}
+
+		var total int32
+		for j := range pools {
+			total += replicas[pools[j].Name]
+		}
+		limit := total * *constraint.MaxPercentPerDomain / 100
+
+		domains := make([]string, len(pools))
+		domainReplicas := map[string]int32{}
+		for j := range pools {
+			if selections[i][j] {
+				domains[j] = spreadDomain(constraint.TopologyKey, &pools[j], nodePools[pools[j].Name])
+			}
+			if len(domains[j]) != 0 {
+				domainReplicas[domains[j]] += replicas[pools[j].Name]
+			}
+		}
+
+		names := make([]string, 0, len(domainReplicas))
+		for domain := range domainReplicas {
+			names = append(names, domain)
+		}
+		sort.Strings(names)
+		for _, domain := range names {
+			for domainReplicas[domain] > limit {
+				to := -1
+				for j := range pools {
+					if domains[j] == domain || (len(domains[j]) != 0 && domainReplicas[domains[j]] >= limit) {
+						continue
+					}
+					if to == -1 || replicas[pools[j].Name] < replicas[pools[to].Name] {
+						to = j
+					}
+				}
+				if to == -1 || !takeOne(func(k int) bool { return domains[k] == domain }) {
+					unsatisfied = append(unsatisfied, fmt.Sprintf("domain %s has %d replicas beyond %d%% of total replicas %d",
+						domain, domainReplicas[domain], *constraint.MaxPercentPerDomain, total))
+					break
+				}
+				replicas[pools[to].Name]++
+				domainReplicas[domain]--
+				if len(domains[to]) != 0 {
+					domainReplicas[domains[to]]++
+				}
+			}
+		}
+	}
+	return unsatisfied, nil
+}
+
+// applySpreadConstraints moves the replicas of pools in nextPatches to satisfy the spread constraints
+// of YurtAppSet, and returns the messages of constraints which can't be satisfied.
+func (r *ReconcileYurtAppSet) applySpreadConstraints(yas *unitv1alpha1.YurtAppSet, nextPatches map[string]YurtAppSetPatches) ([]string, error) {
+	if len(yas.Spec.Topology.SpreadConstraints) == 0 {
+		return nil, nil
+	}
+
+	nodePools := map[string]*appsv1beta1.NodePool{}
+	replicas := map[string]int32{}
+	for i := range yas.Spec.Topology.Pools {
+		pool := &yas.Spec.Topology.Pools[i]
+		np := &appsv1beta1.NodePool{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: adapter.PoolNodePoolName(pool)}, np); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("fail to get NodePool of pool %s: %v", pool.Name, err)
+			}
+			np = nil
+		}
+		nodePools[pool.Name] = np
+		replicas[pool.Name] = nextPatches[pool.Name].Replicas
+	}
+
+	unsatisfied, err := spreadReplicas(yas.Spec.Topology.SpreadConstraints, yas.Spec.Topology.Pools, nodePools, replicas)
+	if err != nil {
+		return nil, err
+	}
+	for name, n := range replicas {
+		if patches := nextPatches[name]; patches.Replicas != n {
+			klog.V(4).Infof("YurtAppSet %s/%s spreads replicas of pool %s from %d to %d",
+				yas.Namespace, yas.Name, name, patches.Replicas, n)
+			patches.Replicas = n
+			nextPatches[name] = patches
+		}
+	}
+	return unsatisfied, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestSpreadReplicas(t *testing.T) {
+	newNodePool := func(name, region string, labels map[string]string) *appsv1beta1.NodePool {
+		return &appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       appsv1beta1.NodePoolSpec{Topology: &appsv1beta1.NodePoolTopology{Region: region}},
+		}
+	}
+	value := func(v int32) *int32 { return &v }
+	pools := []appsv1alpha1.Pool{{Name: "hangzhou"}, {Name: "shanghai"}, {Name: "beijing"}}
+	nodePools := map[string]*appsv1beta1.NodePool{
+		"hangzhou": newNodePool("hangzhou", "east", map[string]string{"tier": "edge"}),
+		"shanghai": newNodePool("shanghai", "east", map[string]string{"tier": "edge"}),
+		"beijing":  newNodePool("beijing", "north", nil),
+	}
+
+	testcases := map[string]struct {
+		constraints []appsv1alpha1.PoolSpreadConstraint
+		replicas    map[string]int32
+		expect      map[string]int32
+		unsatisfied int
+	}{
+		"take min replicas from the pool with most replicas": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{{MinReplicasPerPool: value(1)}},
+			replicas:    map[string]int32{"hangzhou": 5, "shanghai": 0, "beijing": 2},
+			expect:      map[string]int32{"hangzhou": 4, "shanghai": 1, "beijing": 2},
+		},
+		"min replicas only for selected pools": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{{
+				PoolSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "edge"}},
+				MinReplicasPerPool: value(2),
+			}},
+			replicas: map[string]int32{"hangzhou": 0, "shanghai": 0, "beijing": 3},
+			expect:   map[string]int32{"hangzhou": 2, "shanghai": 2, "beijing": 0},
+		},
+		"grow total replicas when no pool can spare": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{{MinReplicasPerPool: value(1)}},
+			replicas:    map[string]int32{"hangzhou": 1, "shanghai": 0, "beijing": 0},
+			expect:      map[string]int32{"hangzhou": 1, "shanghai": 1, "beijing": 1},
+		},
+		"move replicas beyond max percent of region": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{{
+				TopologyKey:         appsv1alpha1.RegionSpreadTopologyKey,
+				MaxPercentPerDomain: value(60),
+			}},
+			replicas: map[string]int32{"hangzhou": 6, "shanghai": 2, "beijing": 2},
+			expect:   map[string]int32{"hangzhou": 4, "shanghai": 2, "beijing": 4},
+		},
+		"keep min replicas when moving replicas out of domain": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{
+				{MinReplicasPerPool: value(2)},
+				{MaxPercentPerDomain: value(40)},
+			},
+			replicas: map[string]int32{"hangzhou": 2, "shanghai": 6, "beijing": 2},
+			expect:   map[string]int32{"hangzhou": 3, "shanghai": 4, "beijing": 3},
+		},
+		"report unsatisfied max percent": {
+			constraints: []appsv1alpha1.PoolSpreadConstraint{{
+				TopologyKey:         appsv1alpha1.RegionSpreadTopologyKey,
+				MaxPercentPerDomain: value(30),
+			}},
+			replicas:    map[string]int32{"hangzhou": 5, "shanghai": 5, "beijing": 0},
+			expect:      map[string]int32{"hangzhou": 3, "shanghai": 4, "beijing": 3},
+			unsatisfied: 1,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			unsatisfied, err := spreadReplicas(tc.constraints, pools, nodePools, tc.replicas)
+			if err != nil {
+				t.Fatalf("failed to spread replicas, %v", err)
+			}
+			if !reflect.DeepEqual(tc.replicas, tc.expect) {
+				t.Errorf("expect replicas %v, but got %v", tc.expect, tc.replicas)
+			}
+			if len(unsatisfied) != tc.unsatisfied {
+				t.Errorf("expect %d unsatisfied constraints, but got %v", tc.unsatisfied, unsatisfied)
+			}
+		})
+	}
+}
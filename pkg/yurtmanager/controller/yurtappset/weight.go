@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+// poolWeight returns the weight of pool, which defaults to 1.
+func poolWeight(pool *unitv1alpha1.Pool) int64 {
+	if pool.Weight == nil {
+		return 1
+	}
+	return int64(*pool.Weight)
+}
+
+// distributeReplicasByWeight distributes the total replicas across pools in proportion to their weights.
+// The replicas left by rounding down are given to the pools with the largest remainders, and the
+// pools declared earlier win the ties.
+func distributeReplicasByWeight(total int32, pools []unitv1alpha1.Pool) map[string]int32 {
+	replicas := make(map[string]int32, len(pools))
+	var totalWeight int64
+	for i := range pools {
+		totalWeight += poolWeight(&pools[i])
+		replicas[pools[i].Name] = 0
+	}
+	if totalWeight == 0 {
+		return replicas
+	}
+
+	remainders := make([]int64, len(pools))
+	left := total
+	for i := range pools {
+		share := int64(total) * poolWeight(&pools[i])
+		replicas[pools[i].Name] = int32(share / totalWeight)
+		remainders[i] = share % totalWeight
+		left -= replicas[pools[i].Name]
+	}
+
+	order := make([]int, len(pools))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; left > 0 && i < len(order); i++ {
+		if remainders[order[i]] == 0 {
+			break
+		}
+		replicas[pools[order[i]].Name]++
+		left--
+	}
+	return replicas
+}
+
+// applyWeightedReplicas sets the replicas of pools in nextPatches by distributing the replicas
+// of YurtAppSet across pools by weight.
+func applyWeightedReplicas(yas *unitv1alpha1.YurtAppSet, nextPatches map[string]YurtAppSetPatches) {
+	if yas.Spec.Replicas == nil {
+		return
+	}
+
+	for name, replicas := range distributeReplicasByWeight(*yas.Spec.Replicas, yas.Spec.Topology.Pools) {
+		patches := nextPatches[name]
+		patches.Replicas = replicas
+		nextPatches[name] = patches
+	}
+	klog.V(4).Infof("YurtAppSet %s/%s distributes %d replicas across pools by weight", yas.Namespace, yas.Name, *yas.Spec.Replicas)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func TestDistributeReplicasByWeight(t *testing.T) {
+	newPool := func(name string, weight *int32) appsv1alpha1.Pool {
+		return appsv1alpha1.Pool{Name: name, Weight: weight}
+	}
+	weight := func(w int32) *int32 { return &w }
+
+	testcases := map[string]struct {
+		total  int32
+		pools  []appsv1alpha1.Pool
+		expect map[string]int32
+	}{
+		"distribute by weights": {
+			total:  10,
+			pools:  []appsv1alpha1.Pool{newPool("hangzhou", weight(70)), newPool("beijing", weight(30))},
+			expect: map[string]int32{"hangzhou": 7, "beijing": 3},
+		},
+		"give remainders to largest fractions": {
+			total:  6,
+			pools:  []appsv1alpha1.Pool{newPool("hangzhou", weight(70)), newPool("beijing", weight(30))},
+			expect: map[string]int32{"hangzhou": 4, "beijing": 2},
+		},
+		"pools declared earlier win ties": {
+			total:  4,
+			pools:  []appsv1alpha1.Pool{newPool("hangzhou", nil), newPool("beijing", nil), newPool("shanghai", nil)},
+			expect: map[string]int32{"hangzhou": 2, "beijing": 1, "shanghai": 1},
+		},
+		"pool with zero weight": {
+			total:  3,
+			pools:  []appsv1alpha1.Pool{newPool("hangzhou", weight(0)), newPool("beijing", nil)},
+			expect: map[string]int32{"hangzhou": 0, "beijing": 3},
+		},
+		"all pools with zero weight": {
+			total:  3,
+			pools:  []appsv1alpha1.Pool{newPool("hangzhou", weight(0))},
+			expect: map[string]int32{"hangzhou": 0},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			got := distributeReplicasByWeight(tc.total, tc.pools)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect replicas %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
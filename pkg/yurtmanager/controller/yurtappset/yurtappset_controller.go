@@ -28,6 +28,7 @@ import (
 	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -43,6 +44,8 @@ import (
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
 	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	ravenv1beta1 "github.com/openyurtio/openyurt/pkg/apis/raven/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
 )
 
@@ -61,6 +64,11 @@ const (
 	eventTypeDupPoolsDelete     = "DeleteDuplicatedPools"
 	eventTypePoolsUpdate        = "UpdatePool"
 	eventTypeTemplateController = "TemplateController"
+	eventTypeRollback           = "Rollback"
+	eventTypeAutoscaling        = "Autoscaling"
+	eventTypeConfigTemplate     = "ConfigTemplate"
+	eventTypeBundle             = "Bundle"
+	eventTypeSpreadConstraints  = "SpreadConstraints"
 
 	slowStartInitialBatchSize = 1
 )
@@ -85,8 +93,9 @@ func Add(c *config.CompletedConfig, mgr manager.Manager) error {
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(c *config.CompletedConfig, mgr manager.Manager) reconcile.Reconciler {
 	return &ReconcileYurtAppSet{
-		Client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+		scheme:    mgr.GetScheme(),
 
 		recorder: mgr.GetEventRecorderFor(names.YurtAppSetController),
 		poolControls: map[unitv1alpha1.TemplateType]ControlInterface{
@@ -128,6 +137,47 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(&source.Kind{Type: &autoscalingv2beta2.HorizontalPodAutoscaler{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &unitv1alpha1.YurtAppSet{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &unitv1alpha1.YurtAppSet{},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &unitv1alpha1.YurtAppSet{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// recompute proportional replicas, capacity and config variables of pools when NodePool changes
+	err = c.Watch(&source.Kind{Type: &appsv1beta1.NodePool{}}, handler.EnqueueRequestsFromMapFunc(enqueueYurtAppSetsForNodePool(mgr.GetClient())))
+	if err != nil {
+		return err
+	}
+
+	// refresh the gateway config injected into workloads when Gateway changes, raven is an optional
+	// component, so Gateway is watched only when it's installed.
+	if _, err := mgr.GetRESTMapper().KindFor(gatewayResource); err != nil {
+		klog.Infof("resource %s doesn't exist, gateway config of YurtAppSet will not be refreshed", gatewayResource.String())
+	} else {
+		err = c.Watch(&source.Kind{Type: &ravenv1beta1.Gateway{}}, handler.EnqueueRequestsFromMapFunc(enqueueYurtAppSetsForGateway(mgr.GetClient())))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -136,7 +186,9 @@ var _ reconcile.Reconciler = &ReconcileYurtAppSet{}
 // ReconcileYurtAppSet reconciles a YurtAppSet object
 type ReconcileYurtAppSet struct {
 	client.Client
-	scheme *runtime.Scheme
+	// apiReader reads Secrets from apiserver directly, so Secrets in the cluster are not cached.
+	apiReader client.Reader
+	scheme    *runtime.Scheme
 
 	recorder     record.EventRecorder
 	poolControls map[unitv1alpha1.TemplateType]ControlInterface
@@ -152,6 +204,12 @@ type ReconcileYurtAppSet struct {
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=raven.openyurt.io,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a YurtAppSet object and makes changes based on the state read
 // and what is in the YurtAppSet.Spec
@@ -170,6 +228,16 @@ func (r *ReconcileYurtAppSet) Reconcile(_ context.Context, request reconcile.Req
 	if instance.DeletionTimestamp != nil {
 		return reconcile.Result{}, nil
 	}
+
+	if instance.Spec.RollbackTo != nil {
+		// the reverted workloadTemplate will be reconciled when the update of YurtAppSet is observed.
+		if err := r.rollback(instance); err != nil {
+			klog.Errorf("Fail to rollback YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+			r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeRollback), err.Error())
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
 	oldStatus := instance.Status.DeepCopy()
 
 	currentRevision, updatedRevision, collisionCount, err := r.constructYurtAppSetRevisions(instance)
@@ -195,7 +263,46 @@ func (r *ReconcileYurtAppSet) Reconcile(_ context.Context, request reconcile.Req
 		return reconcile.Result{}, nil
 	}
 
+	if err := r.manageConfigTemplates(instance); err != nil {
+		klog.Errorf("Fail to manage config templates of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeConfigTemplate), err.Error())
+		return reconcile.Result{}, err
+	}
+
+	if err := r.manageBundle(instance); err != nil {
+		klog.Errorf("Fail to manage bundle of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeBundle), err.Error())
+		return reconcile.Result{}, err
+	}
+
 	nextPatches := GetNextPatches(instance)
+	applyWeightedReplicas(instance, nextPatches)
+	if err := r.applyProportionalReplicas(instance, nextPatches); err != nil {
+		klog.Errorf("Fail to compute proportional replicas of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), err.Error())
+		return reconcile.Result{}, err
+	}
+	unsatisfied, err := r.applySpreadConstraints(instance, nextPatches)
+	if err != nil {
+		klog.Errorf("Fail to apply spread constraints of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSpreadConstraints), err.Error())
+		return reconcile.Result{}, err
+	}
+	for _, msg := range unsatisfied {
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Unsatisfied%s", eventTypeSpreadConstraints), msg)
+	}
+	applyAutoscalingReplicas(instance, nameToPool, nextPatches)
+	overflows, err := r.applyOverflowReplicas(instance, nextPatches)
+	if err != nil {
+		klog.Errorf("Fail to shift overflow replicas of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), err.Error())
+		return reconcile.Result{}, err
+	}
+	if err := r.applyGatewayConfigHashes(instance, nextPatches); err != nil {
+		klog.Errorf("Fail to compute gateway config of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), err.Error())
+		return reconcile.Result{}, err
+	}
 	klog.V(4).Infof("Get YurtAppSet %s/%s next Patches %v", instance.Namespace, instance.Name, nextPatches)
 
 	expectedRevision := currentRevision
@@ -208,7 +315,14 @@ func (r *ReconcileYurtAppSet) Reconcile(_ context.Context, request reconcile.Req
 		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), err.Error())
 	}
 
-	return r.updateStatus(instance, newStatus, oldStatus, nameToPool, currentRevision, collisionCount, control)
+	newStatus.OverflowReplicas = overflows
+
+	if err := r.manageHorizontalPodAutoscalers(instance, nameToPool, poolType); err != nil {
+		klog.Errorf("Fail to manage HorizontalPodAutoscalers of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeAutoscaling), err.Error())
+	}
+
+	return r.updateStatus(instance, newStatus, oldStatus, nameToPool, currentRevision, expectedRevision, collisionCount, control)
 }
 
 func (r *ReconcileYurtAppSet) getNameToPool(instance *unitv1alpha1.YurtAppSet, control ControlInterface) (map[string]*Pool, error) {
@@ -279,17 +393,17 @@ func (r *ReconcileYurtAppSet) classifyPoolByPoolName(pools []*Pool) map[string][
 }
 
 func (r *ReconcileYurtAppSet) updateStatus(instance *unitv1alpha1.YurtAppSet, newStatus, oldStatus *unitv1alpha1.YurtAppSetStatus,
-	nameToPool map[string]*Pool, currentRevision *appsv1.ControllerRevision,
+	nameToPool map[string]*Pool, currentRevision, expectedRevision *appsv1.ControllerRevision,
 	collisionCount int32, control ControlInterface) (reconcile.Result, error) {
 
-	newStatus = r.calculateStatus(instance, newStatus, nameToPool, currentRevision, collisionCount, control)
+	newStatus = r.calculateStatus(instance, newStatus, nameToPool, currentRevision, expectedRevision, collisionCount, control)
 	_, err := r.updateYurtAppSet(instance, oldStatus, newStatus)
 
 	return reconcile.Result{}, err
 }
 
 func (r *ReconcileYurtAppSet) calculateStatus(instance *unitv1alpha1.YurtAppSet, newStatus *unitv1alpha1.YurtAppSetStatus,
-	nameToPool map[string]*Pool, currentRevision *appsv1.ControllerRevision,
+	nameToPool map[string]*Pool, currentRevision, expectedRevision *appsv1.ControllerRevision,
 	collisionCount int32, control ControlInterface) *unitv1alpha1.YurtAppSetStatus {
 
 	newStatus.CollisionCount = &collisionCount
@@ -331,10 +445,11 @@ func (r *ReconcileYurtAppSet) calculateStatus(instance *unitv1alpha1.YurtAppSet,
 
 	newStatus.TemplateType = getPoolTemplateType(instance)
 
-	for _, pool := range nameToPool {
-		failureMessage := control.GetPoolFailure(pool)
-		if failureMessage != nil {
-			poolFailure = failureMessage
+	newStatus.PoolStatuses, newStatus.NotReadyPools = calculatePoolStatuses(newStatus.PoolStatuses, nameToPool, expectedRevision.Name, control)
+	for _, poolStatus := range newStatus.PoolStatuses {
+		if condition := getPoolCondition(poolStatus, unitv1alpha1.PoolFailure); condition != nil {
+			message := fmt.Sprintf("pool %s: %s", poolStatus.Name, condition.Message)
+			poolFailure = &message
 			break
 		}
 	}
@@ -368,7 +483,11 @@ func (r *ReconcileYurtAppSet) updateYurtAppSet(yas *unitv1alpha1.YurtAppSet, old
 		oldStatus.ReadyReplicas == newStatus.ReadyReplicas &&
 		yas.Generation == newStatus.ObservedGeneration &&
 		reflect.DeepEqual(oldStatus.WorkloadSummaries, newStatus.WorkloadSummaries) &&
-		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) {
+		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) &&
+		reflect.DeepEqual(oldStatus.RolloutStatus, newStatus.RolloutStatus) &&
+		reflect.DeepEqual(oldStatus.PoolStatuses, newStatus.PoolStatuses) &&
+		reflect.DeepEqual(oldStatus.NotReadyPools, newStatus.NotReadyPools) &&
+		reflect.DeepEqual(oldStatus.OverflowReplicas, newStatus.OverflowReplicas) {
 		return yas, nil
 	}
 
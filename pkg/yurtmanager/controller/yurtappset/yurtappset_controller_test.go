@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
 )
 
@@ -136,6 +137,10 @@ func TestReconcileYurtAppSet_Reconcile(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -144,8 +149,9 @@ func TestReconcileYurtAppSet_Reconcile(t *testing.T) {
 
 	var req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "foo-ns"}}
 	ryas := ReconcileYurtAppSet{
-		Client: fc,
-		scheme: scheme,
+		Client:    fc,
+		apiReader: fc,
+		scheme:    scheme,
 		poolControls: map[appsv1alpha1.TemplateType]ControlInterface{
 			appsv1alpha1.StatefulSetTemplateType: &PoolControl{
 				Client:  fc,
@@ -285,6 +291,10 @@ func TestReconcileYurtAppSet_UpdateYurtAppSet(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := appsv1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -35,8 +35,9 @@ import (
 const updateRetries = 5
 
 type YurtAppSetPatches struct {
-	Replicas int32
-	Patch    string
+	Replicas          int32
+	Patch             string
+	GatewayConfigHash string
 }
 
 func getPoolNameFrom(metaObj metav1.Object) (string, error) {
@@ -51,12 +51,33 @@ func (r *ReconcileYurtAppSet) managePools(yas *unitv1alpha1.YurtAppSet,
 		SetYurtAppSetCondition(newStatus, NewYurtAppSetCondition(unitv1alpha1.PoolProvisioned, corev1.ConditionTrue, "", ""))
 	}
 
+	var rolloutAllowed sets.String
+	if yas.Spec.RolloutStrategy != nil {
+		rolloutAllowed, newStatus.RolloutStatus = r.rolloutPools(yas, nameToPool, expectedRevision.Name, poolType)
+		if yas.Spec.RolloutStrategy.Paused && len(newStatus.RolloutStatus.UpdatingPools) != 0 {
+			SetYurtAppSetCondition(newStatus, NewYurtAppSetCondition(unitv1alpha1.RolloutPaused, corev1.ConditionTrue, "Paused",
+				fmt.Sprintf("rollout of revision %s is paused at pools %v", expectedRevision.Name, newStatus.RolloutStatus.UpdatingPools)))
+		} else {
+			RemoveYurtAppSetCondition(newStatus, unitv1alpha1.RolloutPaused)
+		}
+	} else {
+		newStatus.RolloutStatus = nil
+		RemoveYurtAppSetCondition(newStatus, unitv1alpha1.RolloutPaused)
+	}
+
 	var needUpdate []string
 	for _, name := range exists.List() {
 		pool := nameToPool[name]
-		if r.poolControls[poolType].IsExpected(pool, expectedRevision.Name) ||
+		outdated := r.poolControls[poolType].IsExpected(pool, expectedRevision.Name)
+		if outdated && rolloutAllowed != nil && !rolloutAllowed.Has(name) {
+			klog.V(4).Infof("YurtAppSet %s/%s holds Pool %s at old revision for rollout", yas.Namespace, yas.Name, name)
+			continue
+		}
+
+		if outdated ||
 			pool.Status.ReplicasInfo.Replicas != nextPatches[name].Replicas ||
-			pool.Status.PatchInfo != nextPatches[name].Patch {
+			pool.Status.PatchInfo != nextPatches[name].Patch ||
+			pool.Status.GatewayConfigHash != nextPatches[name].GatewayConfigHash {
 			needUpdate = append(needUpdate, name)
 		}
 	}
@@ -50,6 +50,8 @@ const (
 	publicKeyBlockType   = "PUBLIC KEY"
 	rsaKeySize           = 2048
 	certDuration         = time.Hour * 24 * 365 * 100 // certificate validity time
+	// certs are rotated when this fraction of validity period has passed
+	certRotationThreshold = 0.8
 )
 
 // NewPrivateKey creates an RSA private key
@@ -209,6 +211,14 @@ func IsCertFromCA(cert *x509.Certificate, caCert *x509.Certificate) bool {
 	return true
 }
 
+// NeedRotate checks whether the cert should be rotated. Certs are rotated when 80% of validity period
+// has passed, so there is enough time to distribute the new ones before the old ones expire.
+func NeedRotate(cert *x509.Certificate, now time.Time) bool {
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	deadline := cert.NotBefore.Add(time.Duration(float64(validity) * certRotationThreshold))
+	return now.After(deadline)
+}
+
 func initYurtCoordinatorCert(client client.Interface, cfg CertConfig, caCert *x509.Certificate, caKey crypto.Signer, stopCh <-chan struct{}) error {
 	key, err := NewPrivateKey()
 	if err != nil {
@@ -262,6 +272,16 @@ func EncodeCertPEM(c *x509.Certificate) ([]byte, error) {
 	return pem.EncodeToMemory(&block), nil
 }
 
+// EncodeCertsPEM returns PEM-endcoded data of all certificates, it's used for CA bundle
+func EncodeCertsPEM(certs []*x509.Certificate) []byte {
+	var data []byte
+	for _, c := range certs {
+		certPEM, _ := EncodeCertPEM(c)
+		data = append(data, certPEM...)
+	}
+	return data
+}
+
 // EncodePublicKeyPEM returns PEM-encoded public data
 func EncodePublicKeyPEM(key crypto.PublicKey) ([]byte, error) {
 	if key == nil {
@@ -392,6 +412,23 @@ func WriteCertAndKeyIntoSecret(clientSet client.Interface, certName, secretName
 	return nil
 }
 
+// WriteCABundleIntoSecret is used for writing CA bundle into secret as ${bundleName}.crt
+func WriteCABundleIntoSecret(clientSet client.Interface, bundleName, secretName string, caCerts []*x509.Certificate) error {
+	secretClient, err := NewSecretClient(clientSet, YurtCoordinatorNS, secretName)
+	if err != nil {
+		return err
+	}
+
+	err = secretClient.AddData(fmt.Sprintf("%s.crt", bundleName), EncodeCertsPEM(caCerts))
+	if err != nil {
+		return errors.Wrapf(err, "fail to write %s.crt into secret %s", bundleName, secretName)
+	}
+
+	klog.Infof(Format("successfully write %s bundle into %s", bundleName, secretName))
+
+	return nil
+}
+
 func WriteKubeConfigIntoSecret(clientSet client.Interface, secretName, kubeConfigName string, kubeConfigByte []byte) error {
 	secretClient, err := NewSecretClient(clientSet, YurtCoordinatorNS, secretName)
 	if err != nil {
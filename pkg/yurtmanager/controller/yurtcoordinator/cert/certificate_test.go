@@ -20,6 +20,7 @@ import (
 	"crypto/x509"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -441,3 +442,34 @@ func TestIsCertFromCA(t *testing.T) {
 	assert.Equal(t, true, IsCertFromCA(ca1Cert1, caCert1))
 	assert.Equal(t, false, IsCertFromCA(ca1Cert1, caCert2))
 }
+
+func TestNeedRotate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		cert   *x509.Certificate
+		expect bool
+	}{
+		{
+			"cert is newly issued",
+			&x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(99 * time.Hour)},
+			false,
+		},
+		{
+			"cert is going to expire",
+			&x509.Certificate{NotBefore: now.Add(-90 * time.Hour), NotAfter: now.Add(10 * time.Hour)},
+			true,
+		},
+		{
+			"cert is expired",
+			&x509.Certificate{NotBefore: now.Add(-100 * time.Hour), NotAfter: now.Add(-time.Hour)},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, NeedRotate(tt.cert, now))
+		})
+	}
+}
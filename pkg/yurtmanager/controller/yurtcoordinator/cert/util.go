@@ -18,8 +18,11 @@ package yurtcoordinatorcert
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
@@ -27,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	client "k8s.io/client-go/kubernetes"
+	certutil "k8s.io/client-go/util/cert"
 	"k8s.io/klog/v2"
 
 	"github.com/openyurtio/openyurt/pkg/util/ip"
@@ -73,3 +77,50 @@ func waitUntilSVCReady(clientSet client.Interface, serviceName string, stopCh <-
 
 	return ips, dnsnames, nil
 }
+
+// loadCABundleFromSecret loads the CA bundle from yurt-coordinator CA secret, and makes sure
+// current CA is in the bundle. Only current CA is returned if the bundle can not be loaded.
+func loadCABundleFromSecret(clientSet client.Interface, caCert *x509.Certificate) []*x509.Certificate {
+	caBundle := []*x509.Certificate{caCert}
+	secret, err := clientSet.CoreV1().Secrets(YurtCoordinatorNS).Get(context.TODO(), YurtCoordinatorCASecretName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf(Format("fail to get secret %s for CA bundle, %v", YurtCoordinatorCASecretName, err))
+		return caBundle
+	}
+
+	bundleBytes, ok := secret.Data[fmt.Sprintf("%s.crt", YurtCoordinatorCABundleName)]
+	if !ok {
+		return caBundle
+	}
+	certs, err := certutil.ParseCertsPEM(bundleBytes)
+	if err != nil {
+		klog.Errorf(Format("couldn't parse CA bundle in secret %s, %v", YurtCoordinatorCASecretName, err))
+		return caBundle
+	}
+	for _, cert := range certs {
+		if !cert.Equal(caCert) {
+			caBundle = append(caBundle, cert)
+		}
+	}
+	return filterExpiredCerts(caBundle, time.Now())
+}
+
+// filterExpiredCerts removes the expired certs
+func filterExpiredCerts(certs []*x509.Certificate, now time.Time) []*x509.Certificate {
+	validCerts := make([]*x509.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		if now.Before(cert.NotAfter) {
+			validCerts = append(validCerts, cert)
+		}
+	}
+	return validCerts
+}
+
+// removeCertFromStore removes the current cert of certmanager from local store,
+// so certmanager will request a new cert instead of loading the old one.
+func removeCertFromStore(componentName string) {
+	currentPath := filepath.Join(certDir, fmt.Sprintf("%s-current.pem", componentName))
+	if err := os.Remove(currentPath); err != nil && !os.IsNotExist(err) {
+		klog.Errorf(Format("fail to remove %s, %v", currentPath, err))
+	}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package yurtcoordinatorcert
 
 import (
+	"crypto/x509"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -137,3 +139,12 @@ func TestWaitUntilSVCReady(t *testing.T) {
 	expectIPS := ip.ParseIPList([]string{"xxxx"})
 	assert.Equal(t, expectIPS, ips)
 }
+
+func TestFilterExpiredCerts(t *testing.T) {
+	now := time.Now()
+	valid := &x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)}
+	expired := &x509.Certificate{NotBefore: now.Add(-2 * time.Hour), NotAfter: now.Add(-time.Hour)}
+
+	certs := filterExpiredCerts([]*x509.Certificate{valid, expired}, now)
+	assert.Equal(t, []*x509.Certificate{valid}, certs)
+}
@@ -23,6 +23,8 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	certificatesv1 "k8s.io/api/certificates/v1"
@@ -56,13 +58,21 @@ var (
 const (
 	// tmp file directory for certmanager to write cert files
 	certDir = "/tmp"
+	// period to check whether certs are going to expire
+	certRotationCheckPeriod = 12 * time.Hour
 
 	ComponentName               = "yurt-controller-manager_yurtcoordinator"
 	YurtCoordinatorAPIServerSVC = "yurt-coordinator-apiserver"
 	YurtCoordinatorETCDSVC      = "yurt-coordinator-etcd"
 
-	// CA certs contains the yurt-coordinator CA certs
+	// CA certs contains the yurt-coordinator CA certs, and the CA bundle which contains
+	// current CA and the old CAs that are not expired yet.
+	// - ca.crt
+	// - ca.key
+	// - ca-bundle.crt
 	YurtCoordinatorCASecretName = "yurt-coordinator-ca-certs"
+	// CA bundle name in yurt-coordinator CA secret
+	YurtCoordinatorCABundleName = "ca-bundle"
 	// Static certs is shared among all yurt-coordinator system, which contains:
 	// - ca.crt
 	// - apiserver-etcd-client.crt
@@ -224,20 +234,18 @@ func Add(cfg *appconfig.CompletedConfig, mgr manager.Manager) error {
 	}
 	r.caCert = caCert
 	r.caKey = caKey
-	r.reuseCA = reuseCA
+	r.caBundle = []*x509.Certificate{caCert}
+	if reuseCA {
+		r.caBundle = loadCABundleFromSecret(r.kubeClient, caCert)
+	}
 
 	// prepare all independent certs
 	if err := r.initYurtCoordinator(allIndependentCerts, nil); err != nil {
 		return err
 	}
 
-	// prepare ca cert in static secret
-	if err := WriteCertAndKeyIntoSecret(r.kubeClient, "ca", YurtCoordinatorStaticSecretName, r.caCert, nil); err != nil {
-		return err
-	}
-
-	// prepare ca cert in yurthub secret
-	if err := WriteCertAndKeyIntoSecret(r.kubeClient, "ca", YurtCoordinatorYurthubClientSecretName, r.caCert, nil); err != nil {
+	// prepare ca bundle in static secret and yurthub secret
+	if err := r.distributeCABundle(); err != nil {
 		return err
 	}
 
@@ -283,10 +291,13 @@ var _ reconcile.Reconciler = &ReconcileYurtCoordinatorCert{}
 
 // ReconcileYurtCoordinatorCert reconciles a YurtCoordinatorcert object
 type ReconcileYurtCoordinatorCert struct {
+	sync.Mutex
 	kubeClient client.Interface
 	caCert     *x509.Certificate
 	caKey      crypto.Signer
-	reuseCA    bool
+	// caBundle contains current CA and the old CAs which are not expired,
+	// so the certs signed by old CA can still be verified before they are rotated.
+	caBundle []*x509.Certificate
 }
 
 // InjectConfig will prepare kube client for YurtCoordinatorCert
@@ -311,25 +322,86 @@ func (r *ReconcileYurtCoordinatorCert) Reconcile(ctx context.Context, request re
 	// We strongly recommend use Format() to  encapsulation because Format() can print logs by module
 	// @kadisi
 	klog.Infof(Format("Reconcile YurtCoordinatorCert %s/%s", request.Namespace, request.Name))
-	// 1. prepare apiserver-kubelet-client cert
+	r.Lock()
+	defer r.Unlock()
+
+	// 1. rotate CA if it's going to expire, and distribute the CA bundle
+	if err := r.rotateCA(); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.distributeCABundle(); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// 2. rotate independent certs if they're going to expire or signed by old CA
+	if err := r.initYurtCoordinator(allIndependentCerts, ctx.Done()); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// 3. prepare apiserver-kubelet-client cert
 	if err := initAPIServerClientCert(r.kubeClient, ctx.Done()); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// 2. prepare node-lease-proxy-client cert
+	// 4. prepare node-lease-proxy-client cert
 	if err := initNodeLeaseProxyClient(r.kubeClient, ctx.Done()); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// 3. prepare certs based on service
+	// 5. prepare certs based on service
 	if request.NamespacedName.Namespace == YurtCoordinatorNS {
+		var err error
 		if request.NamespacedName.Name == YurtCoordinatorAPIServerSVC {
-			return reconcile.Result{}, r.initYurtCoordinator(certsDependOnAPIServerSvc, ctx.Done())
+			err = r.initYurtCoordinator(certsDependOnAPIServerSvc, ctx.Done())
 		} else if request.NamespacedName.Name == YurtCoordinatorETCDSVC {
-			return reconcile.Result{}, r.initYurtCoordinator(certsDependOnETCDSvc, ctx.Done())
+			err = r.initYurtCoordinator(certsDependOnETCDSvc, ctx.Done())
+		}
+		if err != nil {
+			return reconcile.Result{}, err
 		}
 	}
-	return reconcile.Result{}, nil
+
+	// check certs periodically, so they can be rotated before expiry
+	return reconcile.Result{RequeueAfter: certRotationCheckPeriod}, nil
+}
+
+// rotateCA creates a new CA when current CA is going to expire. The old CA is kept in
+// the CA bundle until it expires, and all certs signed by it will be rotated.
+func (r *ReconcileYurtCoordinatorCert) rotateCA() error {
+	if !NeedRotate(r.caCert, time.Now()) {
+		return nil
+	}
+
+	klog.Infof(Format("CA is going to expire at %v, rotate it", r.caCert.NotAfter))
+	caCert, caKey, err := NewSelfSignedCA()
+	if err != nil {
+		return errors.Wrap(err, "fail to new self CA assets when rotating CA")
+	}
+	caBundle := filterExpiredCerts(append([]*x509.Certificate{caCert}, r.caBundle...), time.Now())
+
+	// write CA bundle before CA, so the old CA will not be lost if new CA is written
+	if err := WriteCABundleIntoSecret(r.kubeClient, YurtCoordinatorCABundleName, YurtCoordinatorCASecretName, caBundle); err != nil {
+		return err
+	}
+	if err := WriteCertAndKeyIntoSecret(r.kubeClient, "ca", YurtCoordinatorCASecretName, caCert, caKey); err != nil {
+		return errors.Wrap(err, "fail to write CA assets into secret when rotating CA")
+	}
+
+	r.caCert = caCert
+	r.caKey = caKey
+	r.caBundle = caBundle
+	return nil
+}
+
+// distributeCABundle writes the CA bundle as ca.crt into the secrets used by yurt-coordinator and yurthub.
+func (r *ReconcileYurtCoordinatorCert) distributeCABundle() error {
+	r.caBundle = filterExpiredCerts(r.caBundle, time.Now())
+	for _, secretName := range []string{YurtCoordinatorStaticSecretName, YurtCoordinatorYurthubClientSecretName} {
+		if err := WriteCABundleIntoSecret(r.kubeClient, "ca", secretName, r.caBundle); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *ReconcileYurtCoordinatorCert) initYurtCoordinator(allSelfSignedCerts []CertConfig, stopCh <-chan struct{}) error {
@@ -340,50 +412,52 @@ func (r *ReconcileYurtCoordinatorCert) initYurtCoordinator(allSelfSignedCerts []
 	// prepare selfsigned certs
 	var selfSignedCerts []CertConfig
 
-	if r.reuseCA {
-		// if CA is reused
-		// then we can check if there are selfsigned certs can be reused too
-		for _, certConf := range allSelfSignedCerts {
+	// check if there are selfsigned certs can be reused, the certs which are not
+	// signed by current CA(e.g. CA is newly created or rotated) will be recreated.
+	for _, certConf := range allSelfSignedCerts {
 
-			// 1.1 check if cert exist
-			cert, _, err := loadCertAndKeyFromSecret(r.kubeClient, certConf)
-			if err != nil {
-				klog.Infof(Format("can not load cert %s from %s secret", certConf.CertName, certConf.SecretName))
-				selfSignedCerts = append(selfSignedCerts, certConf)
-				continue
-			}
+		// 1.1 check if cert exist
+		cert, _, err := loadCertAndKeyFromSecret(r.kubeClient, certConf)
+		if err != nil {
+			klog.Infof(Format("can not load cert %s from %s secret", certConf.CertName, certConf.SecretName))
+			selfSignedCerts = append(selfSignedCerts, certConf)
+			continue
+		}
 
-			// 1.2 check if cert is authorized by current CA
-			if !IsCertFromCA(cert, r.caCert) {
-				klog.Infof(Format("existing cert %s is not authorized by current CA", certConf.CertName))
-				selfSignedCerts = append(selfSignedCerts, certConf)
-				continue
-			}
+		// 1.2 check if cert is authorized by current CA
+		if !IsCertFromCA(cert, r.caCert) {
+			klog.Infof(Format("existing cert %s is not authorized by current CA", certConf.CertName))
+			selfSignedCerts = append(selfSignedCerts, certConf)
+			continue
+		}
 
-			// 1.3 check has dynamic attrs changed
-			if certConf.certInit != nil {
-				// receive dynamic IP addresses
-				ips, _, err := certConf.certInit(r.kubeClient, stopCh)
-				if err != nil {
-					// if cert init failed, skip this cert
-					klog.Errorf(Format("fail to init cert %s when checking dynamic attrs: %v", certConf.CertName, err))
+		// 1.3 check if cert is going to expire
+		if NeedRotate(cert, time.Now()) {
+			klog.Infof(Format("cert %s is going to expire at %v", certConf.CertName, cert.NotAfter))
+			selfSignedCerts = append(selfSignedCerts, certConf)
+			continue
+		}
+
+		// 1.4 check has dynamic attrs changed
+		if certConf.certInit != nil {
+			// receive dynamic IP addresses
+			ips, _, err := certConf.certInit(r.kubeClient, stopCh)
+			if err != nil {
+				// if cert init failed, skip this cert
+				klog.Errorf(Format("fail to init cert %s when checking dynamic attrs: %v", certConf.CertName, err))
+				continue
+			} else {
+				// check if dynamic IP addresses already exist in cert
+				changed := ip.SearchAllIP(cert.IPAddresses, ips)
+				if changed {
+					klog.Infof(Format("cert %s IP has changed", certConf.CertName))
+					selfSignedCerts = append(selfSignedCerts, certConf)
 					continue
-				} else {
-					// check if dynamic IP addresses already exist in cert
-					changed := ip.SearchAllIP(cert.IPAddresses, ips)
-					if changed {
-						klog.Infof(Format("cert %s IP has changed", certConf.CertName))
-						selfSignedCerts = append(selfSignedCerts, certConf)
-						continue
-					}
 				}
 			}
-
-			klog.Infof(Format("cert %s not change, reuse it", certConf.CertName))
 		}
-	} else {
-		// create all certs with new CA
-		selfSignedCerts = allSelfSignedCerts
+
+		klog.Infof(Format("cert %s not change, reuse it", certConf.CertName))
 	}
 
 	// create selfsigned certs
@@ -430,20 +504,24 @@ func initCA(clientSet client.Interface) (caCert *x509.Certificate, caKey crypto.
 }
 
 func initAPIServerClientCert(clientSet client.Interface, stopCh <-chan struct{}) error {
+	componentName := fmt.Sprintf("%s-%s", ComponentName, "apiserver-client")
 	if cert, _, err := loadCertAndKeyFromSecret(clientSet, CertConfig{
 		SecretName:   YurtCoordinatorStaticSecretName,
 		CertName:     "apiserver-kubelet-client",
 		IsKubeConfig: false,
-	}); cert != nil {
+	}); cert != nil && !NeedRotate(cert, time.Now()) {
 		klog.Infof("apiserver-kubelet-client cert has already existed in secret %s", YurtCoordinatorStaticSecretName)
 		return nil
+	} else if cert != nil {
+		klog.Infof("apiserver-kubelet-client cert in secret(%s) is going to expire at %v, and new cert will be created", YurtCoordinatorStaticSecretName, cert.NotAfter)
+		removeCertFromStore(componentName)
 	} else if err != nil {
 		klog.Errorf("fail to get apiserver-kubelet-client cert in secret(%s), %v, and new cert will be created", YurtCoordinatorStaticSecretName, err)
 	}
 
 	certMgr, err := certfactory.NewCertManagerFactory(clientSet).New(&certfactory.CertManagerConfig{
 		CertDir:        certDir,
-		ComponentName:  fmt.Sprintf("%s-%s", ComponentName, "apiserver-client"),
+		ComponentName:  componentName,
 		SignerName:     certificatesv1.KubeAPIServerClientSignerName,
 		ForServerUsage: false,
 		CommonName:     YurtCoordinatorAPIServerCN,
@@ -452,6 +530,7 @@ func initAPIServerClientCert(clientSet client.Interface, stopCh <-chan struct{})
 	if err != nil {
 		return err
 	}
+	defer certMgr.Stop()
 
 	return WriteCertIntoSecret(clientSet, "apiserver-kubelet-client", YurtCoordinatorStaticSecretName, certMgr, stopCh)
 }
@@ -461,9 +540,12 @@ func initNodeLeaseProxyClient(clientSet client.Interface, stopCh <-chan struct{}
 		SecretName:   YurtCoordinatorYurthubClientSecretName,
 		CertName:     "node-lease-proxy-client",
 		IsKubeConfig: false,
-	}); cert != nil {
+	}); cert != nil && !NeedRotate(cert, time.Now()) {
 		klog.Infof("node-lease-proxy-client cert has already existed in secret %s", YurtCoordinatorYurthubClientSecretName)
 		return nil
+	} else if cert != nil {
+		klog.Infof("node-lease-proxy-client cert in secret(%s) is going to expire at %v, and new cert will be created", YurtCoordinatorYurthubClientSecretName, cert.NotAfter)
+		removeCertFromStore("yurthub")
 	} else if err != nil {
 		klog.Errorf("fail to get node-lease-proxy-client cert in secret(%s), %v, and new cert will be created", YurtCoordinatorYurthubClientSecretName, err)
 	}
@@ -478,6 +560,7 @@ func initNodeLeaseProxyClient(clientSet client.Interface, stopCh <-chan struct{}
 	if err != nil {
 		return err
 	}
+	defer certMgr.Stop()
 
 	return WriteCertIntoSecret(clientSet, "node-lease-proxy-client", YurtCoordinatorYurthubClientSecretName, certMgr, stopCh)
 }
@@ -17,13 +17,20 @@ limitations under the License.
 package yurtcoordinatorcert
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	certutil "k8s.io/client-go/util/cert"
 	"k8s.io/client-go/util/keyutil"
 )
 
@@ -93,3 +100,50 @@ func TestInitSAKeyPair(t *testing.T) {
 	}
 
 }
+
+func TestRotateCA(t *testing.T) {
+	// prepare a CA which is going to expire in one year
+	caKey, _ := NewPrivateKey()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: YurtCoordinatorOrg},
+		NotBefore:             time.Now().Add(-9 * 365 * 24 * time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(cryptorand.Reader, tmpl, tmpl, caKey.Public(), caKey)
+	assert.Nil(t, err)
+	oldCA, _ := x509.ParseCertificate(der)
+
+	client := fake.NewSimpleClientset()
+	assert.Nil(t, WriteCertAndKeyIntoSecret(client, "ca", YurtCoordinatorCASecretName, oldCA, caKey))
+	r := &ReconcileYurtCoordinatorCert{
+		kubeClient: client,
+		caCert:     oldCA,
+		caKey:      caKey,
+		caBundle:   loadCABundleFromSecret(client, oldCA),
+	}
+
+	assert.Nil(t, r.rotateCA())
+	assert.False(t, r.caCert.Equal(oldCA))
+	assert.Equal(t, 2, len(r.caBundle))
+
+	// CA is not rotated again
+	newCA := r.caCert
+	assert.Nil(t, r.rotateCA())
+	assert.True(t, r.caCert.Equal(newCA))
+
+	// CA bundle is stored and distributed
+	caBundle := loadCABundleFromSecret(client, newCA)
+	assert.Equal(t, 2, len(caBundle))
+	assert.Nil(t, r.distributeCABundle())
+	for _, secretName := range []string{YurtCoordinatorStaticSecretName, YurtCoordinatorYurthubClientSecretName} {
+		secret, err := client.CoreV1().Secrets(YurtCoordinatorNS).Get(context.TODO(), secretName, metav1.GetOptions{})
+		assert.Nil(t, err)
+		certs, err := certutil.ParseCertsPEM(secret.Data["ca.crt"])
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(certs))
+	}
+}
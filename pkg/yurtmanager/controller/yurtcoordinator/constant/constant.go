@@ -24,6 +24,7 @@ const (
 	NodeNotSchedulableTaint = "node.openyurt.io/unschedulable"
 
 	// PodBindingAnnotation can be added into pod annotation, which indicates that this pod will be bound to the node that it is scheduled to.
+	// It can also be added into Deployment, StatefulSet or YurtAppSet annotation, and will be propagated to the pods of workload.
 	PodBindingAnnotation = "apps.openyurt.io/binding"
 
 	// number of lease intervals passed before we taint/detaint node as unschedulable
@@ -23,17 +23,23 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtcoordinator/constant"
 )
@@ -95,6 +101,34 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// the pods recreated by workloads should be bound as well, so the node of pod is
+	// reconciled when pod becomes running or its binding annotation is changed.
+	podPredicates := predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			oldPod, ok := evt.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := evt.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return oldPod.Spec.NodeName != newPod.Spec.NodeName ||
+				oldPod.Status.Phase != newPod.Status.Phase ||
+				oldPod.Annotations[constant.PodBindingAnnotation] != newPod.Annotations[constant.PodBindingAnnotation]
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return false
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(mapPodToNode), podPredicates)
+	if err != nil {
+		return err
+	}
+
 	klog.V(4).Info(Format("registering the field indexers of podbinding controller"))
 	err = mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Pod{}, "spec.nodeName", func(rawObj client.Object) []string {
 		pod, ok := rawObj.(*corev1.Pod)
@@ -109,11 +143,24 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return err
 }
 
+func mapPodToNode(obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || len(pod.Spec.NodeName) == 0 {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+}
+
 func (r *ReconcilePodBinding) InjectClient(c client.Client) error {
 	r.Client = c
 	return nil
 }
 
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=replicasets;deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=yurtappsets,verbs=get;list;watch
+
 // Reconcile reads that state of Node in cluster and makes changes if node autonomy state has been changed
 func (r *ReconcilePodBinding) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	var err error
@@ -151,8 +198,13 @@ func (r *ReconcilePodBinding) processNode(node *corev1.Node) error {
 			continue
 		}
 
+		// propagate the pod binding annotation of workload to pod
+		if err := r.propagateWorkloadBinding(pod); err != nil {
+			klog.Errorf(Format("failed to propagate binding annotation of workload to pod(%s/%s), %v", pod.Namespace, pod.Name, err))
+		}
+
 		// pod binding takes precedence against node autonomy
-		if isPodBoundenToNode(node) {
+		if isPodBoundenToNode(node) || isPodBounden(pod) {
 			if err := r.configureTolerationForPod(pod, nil); err != nil {
 				klog.Errorf(Format("failed to configure toleration of pod, %v", err))
 			}
@@ -205,6 +257,74 @@ func (r *ReconcilePodBinding) configureTolerationForPod(pod *corev1.Pod, tolerat
 	return nil
 }
 
+// propagateWorkloadBinding sets the pod binding annotation of the workload that pod belongs to into pod,
+// so the pods of workload are bound to nodes without mutating them one by one, even if they are recreated.
+func (r *ReconcilePodBinding) propagateWorkloadBinding(pod *corev1.Pod) error {
+	binding, ok, err := r.getWorkloadBinding(pod)
+	if err != nil || !ok {
+		return err
+	}
+	if pod.Annotations != nil && pod.Annotations[constant.PodBindingAnnotation] == binding {
+		return nil
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[constant.PodBindingAnnotation] = binding
+	klog.V(4).Infof(Format("pod(%s/%s) => binding=%s", pod.Namespace, pod.Name, binding))
+	return r.Patch(context.TODO(), pod, patch)
+}
+
+// getWorkloadBinding returns the pod binding annotation of the workload that pod belongs to, the workloads
+// are Deployment(owns pods by ReplicaSet), StatefulSet and the YurtAppSet which manages them. The annotation
+// of workload takes precedence against the one of YurtAppSet.
+func (r *ReconcilePodBinding) getWorkloadBinding(pod *corev1.Pod) (string, bool, error) {
+	owner := metav1.GetControllerOf(pod)
+	for owner != nil {
+		obj := newWorkloadObject(owner)
+		if obj == nil {
+			return "", false, nil
+		}
+		if err := r.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}, obj); err != nil {
+			return "", false, client.IgnoreNotFound(err)
+		}
+
+		// ReplicaSet is managed by Deployment, so only the annotation of Deployment is respected.
+		if _, ok := obj.(*appsv1.ReplicaSet); !ok {
+			if binding, ok := obj.GetAnnotations()[constant.PodBindingAnnotation]; ok {
+				return binding, true, nil
+			}
+		}
+		owner = metav1.GetControllerOf(obj)
+	}
+	return "", false, nil
+}
+
+func newWorkloadObject(owner *metav1.OwnerReference) client.Object {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case gv.Group == appsv1.GroupName && owner.Kind == "ReplicaSet":
+		return &appsv1.ReplicaSet{}
+	case gv.Group == appsv1.GroupName && owner.Kind == "Deployment":
+		return &appsv1.Deployment{}
+	case gv.Group == appsv1.GroupName && owner.Kind == "StatefulSet":
+		return &appsv1.StatefulSet{}
+	case gv.Group == appsv1alpha1.GroupVersion.Group && owner.Kind == "YurtAppSet":
+		return &appsv1alpha1.YurtAppSet{}
+	}
+	return nil
+}
+
+func isPodBounden(pod *corev1.Pod) bool {
+	return pod.Annotations != nil && pod.Annotations[constant.PodBindingAnnotation] == "true"
+}
+
 func isPodBoundenToNode(node *corev1.Node) bool {
 	if node.Annotations != nil &&
 		(node.Annotations[projectinfo.GetAutonomyAnnotation()] == "true" ||
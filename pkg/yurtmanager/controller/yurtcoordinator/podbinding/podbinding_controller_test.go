@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtcoordinator/constant"
 )
 
 var (
@@ -427,3 +430,133 @@ func Test_newReconciler(t *testing.T) {
 		})
 	}
 }
+
+func controllerRef(apiVersion, kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, Controller: &isController}}
+}
+
+func TestPropagateWorkloadBinding(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	if err := appsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add yurt custom resource")
+	}
+	workloads := []client.Object{
+		&appsv1alpha1.YurtAppSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "yas",
+				Namespace:   metav1.NamespaceDefault,
+				Annotations: map[string]string{constant.PodBindingAnnotation: "true"},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "yas-hangzhou",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps.openyurt.io/v1alpha1", "YurtAppSet", "yas"),
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "yas-hangzhou-rs",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps/v1", "Deployment", "yas-hangzhou"),
+			},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "sts",
+				Namespace:   metav1.NamespaceDefault,
+				Annotations: map[string]string{constant.PodBindingAnnotation: "false"},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deploy",
+				Namespace: metav1.NamespaceDefault,
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "deploy-rs",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps/v1", "Deployment", "deploy"),
+				Annotations:     map[string]string{constant.PodBindingAnnotation: "true"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		binding string
+		bounden bool
+	}{
+		{
+			name: "pod of YurtAppSet",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod1",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "yas-hangzhou-rs"),
+			}},
+			binding: "true",
+			bounden: true,
+		},
+		{
+			name: "pod of StatefulSet",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod2",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps/v1", "StatefulSet", "sts"),
+				Annotations:     map[string]string{constant.PodBindingAnnotation: "true"},
+			}},
+			binding: "false",
+			bounden: false,
+		},
+		{
+			name: "annotation of ReplicaSet is ignored",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod3",
+				Namespace:       metav1.NamespaceDefault,
+				OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "deploy-rs"),
+			}},
+			binding: "",
+			bounden: false,
+		},
+		{
+			name: "pod without workload",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod4",
+				Namespace:   metav1.NamespaceDefault,
+				Annotations: map[string]string{constant.PodBindingAnnotation: "true"},
+			}},
+			binding: "true",
+			bounden: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(workloads...).WithObjects(tt.pod).Build()
+			r := &ReconcilePodBinding{
+				Client: c,
+			}
+			if err := r.propagateWorkloadBinding(tt.pod); err != nil {
+				t.Fatalf("propagateWorkloadBinding() error = %v", err)
+			}
+
+			pod := &corev1.Pod{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Namespace: tt.pod.Namespace, Name: tt.pod.Name}, pod); err != nil {
+				t.Fatalf("failed to get pod, %v", err)
+			}
+			if pod.Annotations[constant.PodBindingAnnotation] != tt.binding {
+				t.Errorf("expect binding annotation %q, but got %q", tt.binding, pod.Annotations[constant.PodBindingAnnotation])
+			}
+			if got := isPodBounden(pod); got != tt.bounden {
+				t.Errorf("isPodBounden() = %v, want %v", got, tt.bounden)
+			}
+		})
+	}
+}
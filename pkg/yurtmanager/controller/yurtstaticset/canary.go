@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the License);
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an AS IS BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtstaticset
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/upgradeinfo"
+)
+
+// defaultCanarySoakPeriod is used when the soak period of canary is not specified
+const defaultCanarySoakPeriod = 10 * time.Minute
+
+// syncCanary updates the canary status of YurtStaticSet, and returns the nodes which are allowed to be upgraded
+// and how long to wait until the canary NodePools finish soaking. Nil nodes are returned if the upgrade is
+// not restricted, which means no canary is specified or the canary has been promoted.
+func (r *ReconcileYurtStaticSet) syncCanary(instance *appsv1alpha1.YurtStaticSet, infos map[string]*upgradeinfo.UpgradeInfo,
+	hash string, now time.Time) (sets.String, time.Duration, error) {
+	canary := instance.Spec.UpgradeStrategy.Canary
+	if canary == nil {
+		instance.Status.CanaryStatus = nil
+		return nil, 0, nil
+	}
+
+	status := instance.Status.CanaryStatus
+	if status == nil || status.Hash != hash {
+		status = &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: hash}
+		instance.Status.CanaryStatus = status
+	}
+	if status.Promoted {
+		return nil, 0, nil
+	}
+
+	canaryNodes, err := r.canaryNodes(canary, infos)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The canary NodePools are healthy when all static pods in them are upgraded, ready and pass the health gate
+	healthy := canaryNodes.Len() != 0
+	for _, node := range canaryNodes.UnsortedList() {
+		if info := infos[node]; info.UpgradeNeeded || info.HealthGatePending || !info.StaticPodReady {
+			healthy = false
+			break
+		}
+	}
+	if canaryNodes.Len() == 0 {
+		klog.Warningf(Format("No canary nodes found for YurtStaticSet %s/%s, the upgrade is paused", instance.Namespace, instance.Name))
+	}
+	if !healthy {
+		status.HealthySince = nil
+		return canaryNodes, 0, nil
+	}
+
+	if status.HealthySince == nil {
+		status.HealthySince = &metav1.Time{Time: now}
+	}
+	soakPeriod := defaultCanarySoakPeriod
+	if canary.SoakPeriod != nil {
+		soakPeriod = canary.SoakPeriod.Duration
+	}
+	if remaining := soakPeriod - now.Sub(status.HealthySince.Time); remaining > 0 {
+		return canaryNodes, remaining, nil
+	}
+
+	status.Promoted = true
+	r.recorder.Eventf(instance, corev1.EventTypeNormal, "CanaryPromoted",
+		"canary nodes %v have been healthy for %s, upgrade is promoted to the remaining nodes", canaryNodes.List(), soakPeriod)
+	return nil, 0, nil
+}
+
+// canaryNodes returns the nodes in the canary NodePools among the nodes running the static pod
+func (r *ReconcileYurtStaticSet) canaryNodes(canary *appsv1alpha1.YurtStaticSetCanaryStrategy,
+	infos map[string]*upgradeinfo.UpgradeInfo) (sets.String, error) {
+	selector, err := metav1.LabelSelectorAsSelector(canary.PoolSelector)
+	if err != nil {
+		return nil, err
+	}
+	npList := &appsv1beta1.NodePoolList{}
+	if err := r.List(context.TODO(), npList); err != nil {
+		return nil, err
+	}
+	pools := sets.NewString()
+	for _, np := range npList.Items {
+		if selector.Matches(labels.Set(np.Labels)) {
+			pools.Insert(np.Name)
+		}
+	}
+
+	nodes := sets.NewString()
+	for nodeName, info := range infos {
+		if info.StaticPod == nil {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+			if kerr.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pools.Has(node.Labels[apps.NodePoolLabel]) {
+			nodes.Insert(nodeName)
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the License);
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an AS IS BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtstaticset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/upgradeinfo"
+)
+
+func TestSyncCanary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+	newNode := func(name, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{apps.NodePoolLabel: pool}}}
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "canary", Labels: map[string]string{"stage": "canary"}}},
+		&appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "edge"}},
+		newNode("node1", "canary"), newNode("node2", "edge"), newNode("node3", "edge"),
+	).Build()
+
+	now := time.Now()
+	newInfos := func(canaryUpgraded bool) map[string]*upgradeinfo.UpgradeInfo {
+		return map[string]*upgradeinfo.UpgradeInfo{
+			"node1": {StaticPod: &corev1.Pod{}, UpgradeNeeded: !canaryUpgraded, StaticPodReady: true},
+			"node2": {StaticPod: &corev1.Pod{}, UpgradeNeeded: true, StaticPodReady: true},
+			"node3": {StaticPod: &corev1.Pod{}, UpgradeNeeded: true, StaticPodReady: true},
+		}
+	}
+	testcases := map[string]struct {
+		status         *appsv1alpha1.YurtStaticSetCanaryStatus
+		canaryUpgraded bool
+		expectNodes    sets.String
+		expectRequeue  time.Duration
+		expectStatus   *appsv1alpha1.YurtStaticSetCanaryStatus
+	}{
+		"canary is upgrading": {
+			expectNodes:  sets.NewString("node1"),
+			expectStatus: &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v2"},
+		},
+		"canary starts soaking": {
+			canaryUpgraded: true,
+			expectNodes:    sets.NewString("node1"),
+			expectRequeue:  time.Hour,
+			expectStatus:   &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v2", HealthySince: &metav1.Time{Time: now}},
+		},
+		"canary is promoted after soaking": {
+			status:         &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v2", HealthySince: &metav1.Time{Time: now.Add(-time.Hour)}},
+			canaryUpgraded: true,
+			expectStatus:   &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v2", HealthySince: &metav1.Time{Time: now.Add(-time.Hour)}, Promoted: true},
+		},
+		"canary is reset for new hash": {
+			status:       &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v1", Promoted: true},
+			expectNodes:  sets.NewString("node1"),
+			expectStatus: &appsv1alpha1.YurtStaticSetCanaryStatus{Hash: "v2"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			instance := &appsv1alpha1.YurtStaticSet{
+				Spec: appsv1alpha1.YurtStaticSetSpec{UpgradeStrategy: appsv1alpha1.YurtStaticSetUpgradeStrategy{
+					Canary: &appsv1alpha1.YurtStaticSetCanaryStrategy{
+						PoolSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"stage": "canary"}},
+						SoakPeriod:   &metav1.Duration{Duration: time.Hour},
+					},
+				}},
+				Status: appsv1alpha1.YurtStaticSetStatus{CanaryStatus: tc.status},
+			}
+			r := &ReconcileYurtStaticSet{Client: c, recorder: record.NewFakeRecorder(10)}
+
+			nodes, requeue, err := r.syncCanary(instance, newInfos(tc.canaryUpgraded), "v2", now)
+			if err != nil {
+				t.Fatalf("failed to sync canary, %v", err)
+			}
+			if !reflect.DeepEqual(nodes, tc.expectNodes) {
+				t.Errorf("expect canary nodes %v, but got %v", tc.expectNodes, nodes)
+			}
+			if requeue != tc.expectRequeue {
+				t.Errorf("expect requeue after %v, but got %v", tc.expectRequeue, requeue)
+			}
+			got := instance.Status.CanaryStatus
+			if got.Hash != tc.expectStatus.Hash || got.Promoted != tc.expectStatus.Promoted ||
+				(got.HealthySince == nil) != (tc.expectStatus.HealthySince == nil) ||
+				(got.HealthySince != nil && !got.HealthySince.Equal(tc.expectStatus.HealthySince)) {
+				t.Errorf("expect canary status %+v, but got %+v", tc.expectStatus, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtstaticset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/maintenance"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/upgradeinfo"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
+)
+
+const (
+	// PrePullWorkerPodPrefix is the name prefix of worker pod which pre-pulls the images of static pods
+	PrePullWorkerPodPrefix     = "yss-prepull-worker-"
+	PrePullWorkerContainerName = "prepull-worker"
+
+	hostRootVolumeName      = "host-root"
+	hostRootVolumeMountPath = "/openyurt"
+
+	PrePullArgTmpl = "/usr/local/bin/entry.sh prepull --images=%s"
+)
+
+// defaultMaxPullingNodesPerPool is used when the max pulling nodes per pool of pre-pull is not specified
+var defaultMaxPullingNodesPerPool = intstr.FromInt(1)
+
+// syncPrePull pre-pulls the images of latest static pods to the nodes which need to be upgraded, and returns the nodes
+// whose images are pulled and how long to wait until the pre-pull window of a waiting node starts. Nil nodes are
+// returned if pre-pull is not specified, which means the upgrade is not restricted.
+func (r *ReconcileYurtStaticSet) syncPrePull(instance *appsv1alpha1.YurtStaticSet, infos map[string]*upgradeinfo.UpgradeInfo,
+	hash string, now time.Time) (sets.String, time.Duration, error) {
+	prePull := instance.Spec.UpgradeStrategy.PrePull
+
+	podList := &corev1.PodList{}
+	if err := r.List(context.TODO(), podList, &client.ListOptions{Namespace: instance.Namespace}); err != nil {
+		return nil, 0, err
+	}
+
+	// The pre-pull worker pods are kept until their nodes are upgraded
+	pulled, pulling := sets.NewString(), sets.NewString()
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !strings.HasPrefix(pod.Name, PrePullWorkerPodPrefix+instance.Name+"-") || pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		node := pod.Spec.NodeName
+		info := infos[node]
+		stale := prePull == nil || pod.Annotations[StaticPodHashAnnotation] != hash || info == nil || !info.UpgradeNeeded
+		if !stale && pod.Status.Phase == corev1.PodFailed {
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, "PrePullFailed",
+				"fail to pre-pull images of static pod on node %s, it will be retried", node)
+			stale = true
+		}
+		if stale {
+			if err := r.Delete(context.TODO(), pod, &client.DeleteOptions{}); err != nil && !kerr.IsNotFound(err) {
+				return nil, 0, err
+			}
+			klog.V(4).Infof(Format("Delete pre-pull worker pod %v", pod.Name))
+			continue
+		}
+
+		if pod.Status.Phase == corev1.PodSucceeded {
+			pulled.Insert(node)
+		} else {
+			pulling.Insert(node)
+		}
+	}
+
+	if prePull == nil {
+		return nil, 0, nil
+	}
+
+	checker, err := maintenance.NewChecker(r.Client, prePull.Windows, now)
+	if err != nil {
+		return nil, 0, err
+	}
+	nodePools, err := r.nodePools(infos)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The number of nodes in each pool which are running the static pod and pulling images
+	poolSizes, poolPulling := make(map[string]int), make(map[string]int)
+	for node, pool := range nodePools {
+		poolSizes[pool]++
+		if pulling.Has(node) {
+			poolPulling[pool]++
+		}
+	}
+
+	maxPulling := prePull.MaxPullingNodesPerPool
+	if maxPulling == nil {
+		maxPulling = &defaultMaxPullingNodesPerPool
+	}
+
+	var waiting []string
+	for node, info := range infos {
+		if info.StaticPod != nil && info.UpgradeNeeded && info.NodeReady && !info.RolledBack &&
+			!pulled.Has(node) && !pulling.Has(node) {
+			waiting = append(waiting, node)
+		}
+	}
+	sort.Strings(waiting)
+
+	for _, node := range waiting {
+		pool := nodePools[node]
+		max, err := intstr.GetScaledValueFromIntOrPercent(maxPulling, poolSizes[pool], true)
+		if err != nil {
+			return nil, 0, err
+		}
+		if max < 1 {
+			max = 1
+		}
+		if poolPulling[pool] >= max {
+			continue
+		}
+
+		ok, err := checker.Allowed(node)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := r.createPrePullWorker(instance, node, hash); err != nil {
+			return nil, 0, err
+		}
+		poolPulling[pool]++
+	}
+
+	return pulled, checker.RequeueAfter(), nil
+}
+
+// nodePools returns the NodePool of each node which is running the static pod
+func (r *ReconcileYurtStaticSet) nodePools(infos map[string]*upgradeinfo.UpgradeInfo) (map[string]string, error) {
+	pools := make(map[string]string)
+	for nodeName, info := range infos {
+		if info.StaticPod == nil {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+			if kerr.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		pools[nodeName] = node.Labels[apps.NodePoolLabel]
+	}
+	return pools, nil
+}
+
+// createPrePullWorker creates the worker which pulls the images of static pods to the given node
+func (r *ReconcileYurtStaticSet) createPrePullWorker(instance *appsv1alpha1.YurtStaticSet, nodeName, hash string) error {
+	node := &corev1.Node{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		return err
+	}
+	images, err := staticPodImages(instance, upgradeutil.TemplateValuesFromNode(node))
+	if err != nil {
+		return err
+	}
+
+	hostPathDirectory := corev1.HostPathDirectory
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PrePullWorkerPodPrefix + instance.Name + "-" + util.Hyphen(nodeName, hash),
+			Namespace: instance.Namespace,
+			Annotations: map[string]string{
+				StaticPodHashAnnotation: hash,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			HostPID:       true,
+			HostNetwork:   true,
+			RestartPolicy: corev1.RestartPolicyOnFailure,
+			Containers: []corev1.Container{{
+				Name:            PrePullWorkerContainerName,
+				Image:           r.Configuration.UpgradeWorkerImage,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"/bin/sh", "-c"},
+				Args:            []string{fmt.Sprintf(PrePullArgTmpl, strings.Join(images, ","))},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      hostRootVolumeName,
+					MountPath: hostRootVolumeMountPath,
+				}},
+				SecurityContext: &corev1.SecurityContext{
+					Privileged: &True,
+				},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: hostRootVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: "/",
+						Type: &hostPathDirectory,
+					},
+				},
+			}},
+		},
+	}
+	if err := controllerutil.SetControllerReference(instance, pod, r.Scheme()); err != nil {
+		return err
+	}
+
+	if err := r.Create(context.TODO(), pod, &client.CreateOptions{}); err != nil {
+		return err
+	}
+	klog.Infof(Format("Create pre-pull worker %s of YurtStaticSet %s", pod.Name, instance.Name))
+	return nil
+}
+
+// staticPodImages returns the images of the main static pod and the bundled ones, the placeholders
+// in images are rendered with the template values of node
+func staticPodImages(instance *appsv1alpha1.YurtStaticSet, values *upgradeutil.TemplateValues) ([]string, error) {
+	templates := []*corev1.PodTemplateSpec{&instance.Spec.Template}
+	for i := range instance.Spec.BundledManifests {
+		templates = append(templates, &instance.Spec.BundledManifests[i].Template)
+	}
+
+	images := sets.NewString()
+	for _, template := range templates {
+		containers := append(append([]corev1.Container{}, template.Spec.InitContainers...), template.Spec.Containers...)
+		for _, c := range containers {
+			image, err := upgradeutil.RenderManifest(c.Image, values)
+			if err != nil {
+				return nil, err
+			}
+			if len(image) != 0 {
+				images.Insert(image)
+			}
+		}
+	}
+	return images.List(), nil
+}
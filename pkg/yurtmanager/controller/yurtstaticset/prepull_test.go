@@ -0,0 +1,162 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtstaticset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/upgradeinfo"
+)
+
+func TestSyncPrePull(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+	newNode := func(name, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{apps.NodePoolLabel: pool}}}
+	}
+	newWorker := func(node, hash string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        PrePullWorkerPodPrefix + TestStaticPodName + "-" + node + "-" + hash,
+				Namespace:   metav1.NamespaceDefault,
+				Annotations: map[string]string{StaticPodHashAnnotation: hash},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	newInfos := func() map[string]*upgradeinfo.UpgradeInfo {
+		infos := make(map[string]*upgradeinfo.UpgradeInfo)
+		for _, node := range []string{"node1", "node2", "node3", "node4"} {
+			infos[node] = &upgradeinfo.UpgradeInfo{StaticPod: &corev1.Pod{}, UpgradeNeeded: true, NodeReady: true}
+		}
+		return infos
+	}
+	twoNodes := intstr.FromString("50%")
+
+	testcases := map[string]struct {
+		prePull       *appsv1alpha1.YurtStaticSetPrePullStrategy
+		expectPulled  sets.String
+		expectWorkers sets.String
+	}{
+		"pre-pull is not specified": {
+			expectWorkers: sets.NewString(),
+		},
+		"one node pulls in each pool": {
+			prePull:       &appsv1alpha1.YurtStaticSetPrePullStrategy{},
+			expectPulled:  sets.NewString("node1"),
+			expectWorkers: sets.NewString("node1-v2", "node2-v2", "node4-v2"),
+		},
+		"half of nodes pull in each pool": {
+			prePull:       &appsv1alpha1.YurtStaticSetPrePullStrategy{MaxPullingNodesPerPool: &twoNodes},
+			expectPulled:  sets.NewString("node1"),
+			expectWorkers: sets.NewString("node1-v2", "node2-v2", "node3-v2", "node4-v2"),
+		},
+		"out of pre-pull windows": {
+			prePull: &appsv1alpha1.YurtStaticSetPrePullStrategy{Windows: []appsv1alpha1.MaintenanceWindow{
+				{Schedule: "0 0 1 1 *", Duration: metav1.Duration{Duration: time.Minute}},
+			}},
+			expectPulled:  sets.NewString("node1"),
+			expectWorkers: sets.NewString("node1-v2"),
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			instance := &appsv1alpha1.YurtStaticSet{
+				ObjectMeta: metav1.ObjectMeta{Name: TestStaticPodName, Namespace: metav1.NamespaceDefault},
+				Spec: appsv1alpha1.YurtStaticSetSpec{
+					UpgradeStrategy: appsv1alpha1.YurtStaticSetUpgradeStrategy{PrePull: tc.prePull},
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "nginx", Image: TestStaticPodImage}},
+					}},
+				},
+			}
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+				instance,
+				newNode("node1", "a"), newNode("node2", "a"), newNode("node3", "a"), newNode("node4", "b"),
+				newWorker("node1", "v2", corev1.PodSucceeded), newWorker("node4", "v1", corev1.PodRunning),
+			).Build()
+			r := &ReconcileYurtStaticSet{Client: c, recorder: record.NewFakeRecorder(10)}
+
+			// Jan 2nd is out of the window which starts at 00:00 on Jan 1st
+			now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+			pulled, _, err := r.syncPrePull(instance, newInfos(), "v2", now)
+			if err != nil {
+				t.Fatalf("failed to sync pre-pull, %v", err)
+			}
+			if !reflect.DeepEqual(pulled, tc.expectPulled) {
+				t.Errorf("expect pulled nodes %v, but got %v", tc.expectPulled, pulled)
+			}
+
+			podList := &corev1.PodList{}
+			if err := c.List(context.TODO(), podList); err != nil {
+				t.Fatal(err)
+			}
+			workers := sets.NewString()
+			for _, pod := range podList.Items {
+				workers.Insert(pod.Spec.NodeName + "-" + pod.Annotations[StaticPodHashAnnotation])
+			}
+			if !workers.Equal(tc.expectWorkers) {
+				t.Errorf("expect pre-pull workers %v, but got %v", tc.expectWorkers.List(), workers.List())
+			}
+		})
+	}
+}
+
+func TestStaticPodImages(t *testing.T) {
+	instance := &appsv1alpha1.YurtStaticSet{
+		Spec: appsv1alpha1.YurtStaticSetSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox"}},
+				Containers:     []corev1.Container{{Name: "nginx", Image: "{{POOL_NAME}}.registry/nginx"}},
+			}},
+			BundledManifests: []appsv1alpha1.YurtStaticSetBundledManifest{{
+				StaticPodManifest: "mirror",
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "mirror", Image: "busybox"}},
+				}},
+			}},
+		},
+	}
+
+	images, err := staticPodImages(instance, &upgradeutil.TemplateValues{PoolName: "hangzhou"})
+	if err != nil {
+		t.Fatalf("failed to get images, %v", err)
+	}
+	if expect := []string{"busybox", "hangzhou.registry/nginx"}; !reflect.DeepEqual(images, expect) {
+		t.Errorf("expect images %v, but got %v", expect, images)
+	}
+}
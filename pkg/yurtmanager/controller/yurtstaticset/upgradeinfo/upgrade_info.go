@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	upgrade "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade"
 	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
 )
@@ -64,6 +66,14 @@ type UpgradeInfo struct {
 
 	// Indicate whether the node is ready. It's used in AdvancedRollingUpdate mode.
 	NodeReady bool
+
+	// Indicate whether the latest static pod failed to be ready and the previous manifest is restored.
+	// If true, the node will not be upgraded again until the static pod spec changes.
+	RolledBack bool
+
+	// Indicate whether the node is running the latest static pod which has not passed the health gate yet.
+	// If true, the node is not counted as upgraded.
+	HealthGatePending bool
 }
 
 // New constructs the upgrade information for nodes which have the target static pod
@@ -99,9 +109,28 @@ func New(c client.Client, instance *appsv1alpha1.YurtStaticSet, workerPodName, h
 		}
 	}
 
+	if instance.Spec.UpgradeStrategy.HealthGate != nil {
+		for _, info := range infos {
+			info.HealthGatePending = healthGatePending(info, hash)
+		}
+	}
+
 	return infos, nil
 }
 
+// healthGatePending checks whether the latest static pod is still checked against the health gate,
+// by the running worker pod in AdvancedRollingUpdate mode or by YurtHub in OTA mode
+func healthGatePending(info *UpgradeInfo, hash string) bool {
+	if info.StaticPod == nil || info.UpgradeNeeded {
+		return false
+	}
+	if info.WorkerPodRunning && !info.WorkerPodDeleteNeeded {
+		return true
+	}
+	reason, h := util.GetPodOTAUpgradeState(info.StaticPod)
+	return reason == util.OTAUpgradeConfirmedReason && h == hash
+}
+
 func initStaticPodInfo(instance *appsv1alpha1.YurtStaticSet, c client.Client, nodeName, hash string,
 	pod *corev1.Pod, infos map[string]*UpgradeInfo) error {
 
@@ -119,6 +148,12 @@ func initStaticPodInfo(instance *appsv1alpha1.YurtStaticSet, c client.Client, no
 		infos[nodeName].UpgradeNeeded = true
 	}
 
+	// The latest manifest is rolled back by YurtHub in OTA upgrade mode
+	if reason, h := util.GetPodOTAUpgradeState(pod); infos[nodeName].UpgradeNeeded &&
+		reason == util.OTAUpgradeRolledBackReason && h == hash {
+		infos[nodeName].RolledBack = true
+	}
+
 	// Sets the ready status static pod
 	if podutils.IsPodReady(pod) {
 		infos[nodeName].StaticPodReady = true
@@ -142,6 +177,16 @@ func initWorkerPodInfo(nodeName, hash string, pod *corev1.Pod, infos map[string]
 	infos[nodeName].WorkerPodStatusPhase = pod.Status.Phase
 	switch pod.Status.Phase {
 	case corev1.PodFailed:
+		if pod.Annotations[StaticPodHashAnnotation] != hash {
+			// The failed worker pod is out-of-date, just delete it
+			break
+		}
+		if workerPodRolledBack(pod) {
+			// The latest static pod failed to be ready and the previous manifest is restored,
+			// keep the worker pod to record the rollback of this node
+			infos[nodeName].RolledBack = true
+			break
+		}
 		// The worker pod is failed, then some irreparable failure has occurred. Just stop reconcile and update status
 		return fmt.Errorf("fail to init worker pod info, cause worker pod %s failed", pod.Name)
 	case corev1.PodSucceeded:
@@ -159,6 +204,16 @@ func initWorkerPodInfo(nodeName, hash string, pod *corev1.Pod, infos map[string]
 	return nil
 }
 
+// workerPodRolledBack checks whether the worker pod terminated after rolling back the static pod
+func workerPodRolledBack(pod *corev1.Pod) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && strings.HasPrefix(cs.State.Terminated.Message, upgrade.RolledBackTerminationMessage) {
+			return true
+		}
+	}
+	return false
+}
+
 // match check if the given YurtStaticSet's template matches the pod.
 func match(instance *appsv1alpha1.YurtStaticSet, pod *corev1.Pod) bool {
 
@@ -231,11 +286,12 @@ func match(instance *appsv1alpha1.YurtStaticSet, pod *corev1.Pod) bool {
 // 1. node is ready
 // 2. node needs to be upgraded
 // 3. no latest worker pod running on the node
+// 4. the latest static pod is not rolled back on the node
 // On these nodes, new worker pods need to be created for AdvancedRollingUpdate mode
 func ReadyUpgradeWaitingNodes(infos map[string]*UpgradeInfo) []string {
 	var nodes []string
 	for node, info := range infos {
-		if info.UpgradeNeeded && !info.WorkerPodRunning && info.NodeReady {
+		if info.UpgradeNeeded && !info.WorkerPodRunning && info.NodeReady && !info.RolledBack {
 			nodes = append(nodes, node)
 		}
 	}
@@ -271,7 +327,7 @@ func CalculateOperateInfoFromUpgradeInfoMap(infos map[string]*UpgradeInfo) (int3
 			if info.StaticPodReady {
 				readyNumber++
 			}
-			if !info.UpgradeNeeded {
+			if !info.UpgradeNeeded && !info.HealthGatePending {
 				upgradedNumber++
 			}
 		}
@@ -288,3 +344,38 @@ func CalculateOperateInfoFromUpgradeInfoMap(infos map[string]*UpgradeInfo) (int3
 	}
 	return upgradedNumber, readyNumber, allSucceeded, deletePods
 }
+
+// NodeUpgradeStatuses returns the upgrade state of static pod on each node sorted by node name,
+// the state reported by YurtHub for an out-of-date manifest is ignored.
+func NodeUpgradeStatuses(infos map[string]*UpgradeInfo, hash string) []appsv1alpha1.YurtStaticSetNodeStatus {
+	var statuses []appsv1alpha1.YurtStaticSetNodeStatus
+	for node, info := range infos {
+		if info.StaticPod == nil {
+			continue
+		}
+
+		state := appsv1alpha1.NodeUpgradePending
+		if !info.UpgradeNeeded && info.HealthGatePending {
+			state = appsv1alpha1.NodeUpgradeUpgrading
+		} else if !info.UpgradeNeeded {
+			state = appsv1alpha1.NodeUpgradeUpgraded
+		} else if info.RolledBack {
+			state = appsv1alpha1.NodeUpgradeRolledBack
+		} else if info.WorkerPodRunning && !info.WorkerPodDeleteNeeded {
+			state = appsv1alpha1.NodeUpgradeUpgrading
+		} else if reason, h := util.GetPodOTAUpgradeState(info.StaticPod); h == hash {
+			switch reason {
+			case util.OTAUpgradeStagedReason:
+				state = appsv1alpha1.NodeUpgradeStaged
+			case util.OTAUpgradeConfirmedReason:
+				state = appsv1alpha1.NodeUpgradeUpgrading
+			}
+		}
+		statuses = append(statuses, appsv1alpha1.YurtStaticSetNodeStatus{NodeName: node, State: state})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NodeName < statuses[j].NodeName
+	})
+	return statuses
+}
@@ -29,6 +29,7 @@ import (
 
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
 	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
 )
 
 const (
@@ -356,6 +357,151 @@ func preparePods() []*corev1.Pod {
 	return podList
 }
 
+func TestNodeUpgradeStatuses(t *testing.T) {
+	newStaticPod := func(reason, hash string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		if len(reason) != 0 {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:    util.PodOTAUpgrade,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: hash,
+			}}
+		}
+		return pod
+	}
+	infos := map[string]*UpgradeInfo{
+		"node1": {StaticPod: newStaticPod("", ""), UpgradeNeeded: true},
+		"node2": {StaticPod: newStaticPod(util.OTAUpgradeStagedReason, "hash2"), UpgradeNeeded: true},
+		"node3": {StaticPod: newStaticPod(util.OTAUpgradeConfirmedReason, "hash2"), UpgradeNeeded: true},
+		"node4": {StaticPod: newStaticPod(util.OTAUpgradeStagedReason, "hash1"), UpgradeNeeded: true},
+		"node5": {StaticPod: newStaticPod(util.OTAUpgradeConfirmedReason, "hash2")},
+		"node6": {WorkerPod: &corev1.Pod{}},
+		"node7": {StaticPod: newStaticPod("", ""), UpgradeNeeded: true, RolledBack: true},
+		"node8": {StaticPod: newStaticPod("", ""), UpgradeNeeded: true, WorkerPod: &corev1.Pod{}, WorkerPodRunning: true},
+		"node9": {StaticPod: newStaticPod(util.OTAUpgradeConfirmedReason, "hash2"), HealthGatePending: true},
+	}
+
+	expect := []appsv1alpha1.YurtStaticSetNodeStatus{
+		{NodeName: "node1", State: appsv1alpha1.NodeUpgradePending},
+		{NodeName: "node2", State: appsv1alpha1.NodeUpgradeStaged},
+		{NodeName: "node3", State: appsv1alpha1.NodeUpgradeUpgrading},
+		{NodeName: "node4", State: appsv1alpha1.NodeUpgradePending},
+		{NodeName: "node5", State: appsv1alpha1.NodeUpgradeUpgraded},
+		{NodeName: "node7", State: appsv1alpha1.NodeUpgradeRolledBack},
+		{NodeName: "node8", State: appsv1alpha1.NodeUpgradeUpgrading},
+		{NodeName: "node9", State: appsv1alpha1.NodeUpgradeUpgrading},
+	}
+	if got := NodeUpgradeStatuses(infos, "hash2"); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("NodeUpgradeStatuses got %v, want %v", got, expect)
+	}
+}
+
+func TestHealthGatePending(t *testing.T) {
+	newStaticPod := func(reason, hash string) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:    util.PodOTAUpgrade,
+			Status:  corev1.ConditionTrue,
+			Reason:  reason,
+			Message: hash,
+		}}}}
+	}
+
+	testcases := map[string]struct {
+		info   *UpgradeInfo
+		expect bool
+	}{
+		"static pod needs upgrade": {
+			info: &UpgradeInfo{StaticPod: &corev1.Pod{}, UpgradeNeeded: true, WorkerPodRunning: true},
+		},
+		"worker pod is checking health gate": {
+			info:   &UpgradeInfo{StaticPod: &corev1.Pod{}, WorkerPodRunning: true},
+			expect: true,
+		},
+		"out-of-date worker pod is running": {
+			info: &UpgradeInfo{StaticPod: &corev1.Pod{}, WorkerPodRunning: true, WorkerPodDeleteNeeded: true},
+		},
+		"yurthub is checking health gate": {
+			info:   &UpgradeInfo{StaticPod: newStaticPod(util.OTAUpgradeConfirmedReason, "hash2")},
+			expect: true,
+		},
+		"yurthub verified static pod": {
+			info: &UpgradeInfo{StaticPod: newStaticPod(util.OTAUpgradeVerifiedReason, "hash2")},
+		},
+		"yurthub confirmed an out-of-date manifest": {
+			info: &UpgradeInfo{StaticPod: newStaticPod(util.OTAUpgradeConfirmedReason, "hash1")},
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := healthGatePending(tc.info, "hash2"); got != tc.expect {
+				t.Errorf("expect health gate pending %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+
+	infos := map[string]*UpgradeInfo{
+		"node1": {StaticPod: &corev1.Pod{}},
+		"node2": {StaticPod: &corev1.Pod{}, HealthGatePending: true},
+	}
+	if upgraded, _, _, _ := CalculateOperateInfoFromUpgradeInfoMap(infos); upgraded != 1 {
+		t.Errorf("expect 1 node upgraded, but got %d", upgraded)
+	}
+}
+
+func TestInitWorkerPodInfo(t *testing.T) {
+	newWorkerPod := func(hash, message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker",
+				Annotations: map[string]string{StaticPodHashAnnotation: hash},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+				}},
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		pod          *corev1.Pod
+		rolledBack   bool
+		deleteNeeded bool
+		wantErr      bool
+	}{
+		"latest worker pod rolled back the static pod": {
+			pod:        newWorkerPod("hash2", "RolledBack: static pod is rolled back to the previous manifest"),
+			rolledBack: true,
+		},
+		"out-of-date worker pod failed": {
+			pod:          newWorkerPod("hash1", ""),
+			deleteNeeded: true,
+		},
+		"latest worker pod failed": {
+			pod:     newWorkerPod("hash2", "fail to copy manifest"),
+			wantErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			infos := map[string]*UpgradeInfo{}
+			err := initWorkerPodInfo("node1", "hash2", tc.pod, infos)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("initWorkerPodInfo() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if infos["node1"].RolledBack != tc.rolledBack {
+				t.Errorf("expect rolled back %v, but got %v", tc.rolledBack, infos["node1"].RolledBack)
+			}
+			if infos["node1"].WorkerPodDeleteNeeded != tc.deleteNeeded {
+				t.Errorf("expect delete needed %v, but got %v", tc.deleteNeeded, infos["node1"].WorkerPodDeleteNeeded)
+			}
+		})
+	}
+}
+
 func TestMatch(t *testing.T) {
 	pods := preparePods()
 	tests := []struct {
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	corev1 "k8s.io/api/core/v1"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	upgrade "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade"
 	nodeutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/node"
 	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
 )
@@ -43,7 +45,38 @@ const (
 	// PodNeedUpgrade indicates whether the pod is able to upgrade.
 	PodNeedUpgrade corev1.PodConditionType = "PodNeedUpgrade"
 
+	// PodOTAUpgrade is set by YurtHub to report the OTA upgrade state of static pod, its reason
+	// is the state and its message is the hash of the upgrade manifest.
+	PodOTAUpgrade corev1.PodConditionType = "PodOTAUpgrade"
+
+	// OTAUpgradeStagedReason means the upgrade manifest is staged and waits for confirmation.
+	OTAUpgradeStagedReason = "Staged"
+	// OTAUpgradeConfirmedReason means the upgrade manifest has replaced the static pod manifest.
+	OTAUpgradeConfirmedReason = "Confirmed"
+	// OTAUpgradeVerifiedReason means the upgraded static pod is ready and passes the health gate.
+	OTAUpgradeVerifiedReason = "Verified"
+	// OTAUpgradeCancelledReason means the staged upgrade manifest is removed before confirmation.
+	OTAUpgradeCancelledReason = "Cancelled"
+	// OTAUpgradeRolledBackReason means the upgraded static pod failed to be ready within the rollback
+	// window and the previous manifest is restored.
+	OTAUpgradeRolledBackReason = "RolledBack"
+
 	StaticPodHashAnnotation = "openyurt.io/static-pod-hash"
+	// RollbackWindowAnnotation records the rollback window of YurtStaticSet on its configmap,
+	// so that YurtHub can verify the static pod after an OTA upgrade is confirmed.
+	RollbackWindowAnnotation = "openyurt.io/static-pod-rollback-window"
+	// ManifestSignatureAnnotation is set on YurtStaticSet by users to sign the manifest in its configmap,
+	// and it's synced to the configmap so that nodes can verify the manifest before upgrade.
+	ManifestSignatureAnnotation = "openyurt.io/static-pod-manifest-signature"
+	// BundleSignaturesAnnotation is set on YurtStaticSet by users to sign the bundled manifests, its value is
+	// a json object from the manifest file names to their signatures.
+	BundleSignaturesAnnotation = "openyurt.io/static-pod-bundle-signatures"
+	// StaticPodBundleAnnotation records the bundled manifests of YurtStaticSet on its configmap in json,
+	// so that YurtHub can upgrade them together with the main manifest.
+	StaticPodBundleAnnotation = "openyurt.io/static-pod-bundle"
+	// HealthGateAnnotation records the health gate of YurtStaticSet on its configmap in json,
+	// so that YurtHub can check the static pod after an OTA upgrade is confirmed.
+	HealthGateAnnotation = "openyurt.io/static-pod-health-gate"
 )
 
 var (
@@ -69,6 +102,15 @@ func UnavailableCount(us *appsv1alpha1.YurtStaticSetUpgradeStrategy, numberToUpg
 	return intstr.GetScaledValueFromIntOrPercent(us.MaxUnavailable, numberToUpgrade, true)
 }
 
+// RollbackWindow returns the duration in which the upgraded static pod must be ready,
+// the default timeout of node-servant is returned if it's not specified.
+func RollbackWindow(us *appsv1alpha1.YurtStaticSetUpgradeStrategy) time.Duration {
+	if us == nil || us.RollbackWindow == nil || us.RollbackWindow.Duration <= 0 {
+		return upgrade.DefaultStaticPodRunningCheckTimeout
+	}
+	return us.RollbackWindow.Duration
+}
+
 // ComputeHash returns a hash value calculated from pod template
 func ComputeHash(template *corev1.PodTemplateSpec) string {
 	podSpecHasher := fnv.New32a()
@@ -77,6 +119,18 @@ func ComputeHash(template *corev1.PodTemplateSpec) string {
 	return rand.SafeEncodeString(fmt.Sprint(podSpecHasher.Sum32()))
 }
 
+// ComputeSpecHash returns a hash value calculated from the pod template and bundled manifests of YurtStaticSet,
+// it's the same as ComputeHash of the pod template if there is no bundled manifest.
+func ComputeSpecHash(spec *appsv1alpha1.YurtStaticSetSpec) string {
+	if len(spec.BundledManifests) == 0 {
+		return ComputeHash(&spec.Template)
+	}
+	hasher := fnv.New32a()
+	DeepHashObject(hasher, []interface{}{spec.Template, spec.BundledManifests})
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
 // DeepHashObject writes specified object to hash using the spew library
 // which follows pointers and prints actual values of the nested objects
 // ensuring the hash does not change when a pointer changes.
@@ -134,3 +188,13 @@ func SetPodUpgradeCondition(c client.Client, status corev1.ConditionStatus, pod
 
 	return nil
 }
+
+// GetPodOTAUpgradeState returns the OTA upgrade state reported by YurtHub and the hash of the upgrade manifest,
+// empty strings are returned if no upgrade is staged or confirmed.
+func GetPodOTAUpgradeState(pod *corev1.Pod) (string, string) {
+	_, cond := podutil.GetPodConditionFromList(pod.Status.Conditions, PodOTAUpgrade)
+	if cond == nil || cond.Status != corev1.ConditionTrue {
+		return "", ""
+	}
+	return cond.Reason, cond.Message
+}
@@ -18,15 +18,19 @@ package yurtstaticset
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,8 +47,11 @@ import (
 	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
 	"github.com/openyurtio/openyurt/cmd/yurt-manager/names"
 	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	upgradeutil "github.com/openyurtio/openyurt/pkg/node-servant/static-pod-upgrade/util"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/maintenance"
 	nodeutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/node"
 	podutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/pod"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/progress"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/config"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/upgradeinfo"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtstaticset/util"
@@ -58,6 +65,8 @@ var (
 	concurrentReconciles = 3
 	controllerResource   = appsv1alpha1.SchemeGroupVersion.WithResource("yurtstaticsets")
 	True                 = true
+
+	hostPathDirectoryOrCreate = corev1.HostPathDirectoryOrCreate
 )
 
 const (
@@ -68,12 +77,13 @@ const (
 	configMapVolumeName      = "configmap"
 	configMapVolumeMountPath = "/data"
 	hostPathVolumeSourcePath = hostPathVolumeMountPath
+	publicKeyVolumeName      = "public-key"
 
 	// UpgradeWorkerPodPrefix is the name prefix of worker pod which used for static pod upgrade
 	UpgradeWorkerPodPrefix     = "yss-upgrade-worker-"
 	UpgradeWorkerContainerName = "upgrade-worker"
 
-	ArgTmpl = "/usr/local/bin/node-servant static-pod-upgrade --name=%s --namespace=%s --manifest=%s --hash=%s --mode=%s"
+	ArgTmpl = "/usr/local/bin/node-servant static-pod-upgrade --name=%s --namespace=%s --manifest=%s --hash=%s --mode=%s --timeout=%s"
 )
 
 // upgradeWorker is the pod template used for static pod upgrade
@@ -101,6 +111,11 @@ var (
 						Name:      configMapVolumeName,
 						MountPath: configMapVolumeMountPath,
 					},
+					{
+						Name:      publicKeyVolumeName,
+						MountPath: filepath.Dir(upgradeutil.DefaultManifestPublicKeyPath),
+						ReadOnly:  true,
+					},
 				},
 				ImagePullPolicy: corev1.PullIfNotPresent,
 				SecurityContext: &corev1.SecurityContext{
@@ -113,6 +128,13 @@ var (
 					HostPath: &corev1.HostPathVolumeSource{
 						Path: hostPathVolumeSourcePath,
 					},
+				}}, {
+				Name: publicKeyVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: filepath.Dir(upgradeutil.DefaultManifestPublicKeyPath),
+						Type: &hostPathDirectoryOrCreate,
+					},
 				}},
 			},
 		},
@@ -270,6 +292,7 @@ func nodeTurnReady(evt event.UpdateEvent) bool {
 //+kubebuilder:rbac:groups=core,resources=pods/status,verbs=update
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a YurtStaticSet object and makes changes based on the state read
 // and what is in the YurtStaticSet.Spec
@@ -285,6 +308,7 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 	if err := r.Get(context.TODO(), request.NamespacedName, instance); err != nil {
 		// if the yurtStaticSet does not exist, delete the specified configmap if exist.
 		if kerr.IsNotFound(err) {
+			progress.Forget(progress.KindYurtStaticSet, request.Namespace, request.Name)
 			return reconcile.Result{}, r.deleteConfigMap(request.Name, request.Namespace)
 		}
 		klog.Errorf("Fail to get YurtStaticSet %v, %v", request.NamespacedName, err)
@@ -294,6 +318,7 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 	if instance.DeletionTimestamp != nil {
 		// handle the deletion event
 		// delete the configMap which is created by yurtStaticSet
+		progress.Forget(progress.KindYurtStaticSet, request.Namespace, request.Name)
 		return reconcile.Result{}, r.deleteConfigMap(request.Name, request.Namespace)
 	}
 
@@ -319,18 +344,19 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 	// 1. Automatically added to the annotation of static pods to facilitate checking if the running static pods are up-to-date
 	// 2. Automatically added to the annotation of worker pods to facilitate checking if the worker pods are up-to-date
 	// 3. Added to YurtStaticSet's corresponding configmap to facilitate checking if the configmap is up-to-date
-	latestHash := util.ComputeHash(&instance.Spec.Template)
+	// The bundled manifests share the hash value, so that they are upgraded together with the main one
+	latestHash := util.ComputeSpecHash(&instance.Spec)
 
-	// The latest static pod manifest generated from user-specified template
+	// The latest static pod manifests generated from user-specified templates
 	// The above hash value will be added to the annotation
-	latestManifest, err := util.GenStaticPodManifest(&instance.Spec.Template, latestHash)
+	latestManifests, err := genStaticPodManifests(instance, latestHash)
 	if err != nil {
 		klog.Errorf(Format("Fail to generate static pod manifest of YurtStaticSet %v, %v", request.NamespacedName, err))
 		return ctrl.Result{}, err
 	}
 
 	// Sync the corresponding configmap to the latest state
-	if err := r.syncConfigMap(instance, latestHash, latestManifest); err != nil {
+	if err := r.syncConfigMap(instance, latestHash, latestManifests); err != nil {
 		klog.Errorf(Format("Fail to sync the corresponding configmap of YurtStaticSet %v, %v", request.NamespacedName, err))
 		return ctrl.Result{}, err
 	}
@@ -349,6 +375,12 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 			request.NamespacedName, err))
 		return ctrl.Result{}, err
 	}
+	// Report the upgrade state of static pod on each node, and record the newly rolled back nodes
+	lastNodeStatuses := instance.Status.NodeStatuses
+	instance.Status.NodeStatuses = upgradeinfo.NodeUpgradeStatuses(upgradeInfos, latestHash)
+	r.recordRollbacks(instance, lastNodeStatuses)
+	recordProgress(instance)
+
 	totalNumber = int32(len(upgradeInfos))
 	// There are no nodes running target static pods in the cluster
 	if totalNumber == 0 {
@@ -365,6 +397,14 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 		return reconcile.Result{}, err
 	}
 
+	// Pre-pull the images of latest static pods, the nodes are only upgraded after their images are pulled
+	// Put this here because we need to clean up the pre-pull worker pods of the upgraded nodes first
+	pulledNodes, prePullWait, err := r.syncPrePull(instance, upgradeInfos, latestHash, time.Now())
+	if err != nil {
+		klog.Errorf(Format("Fail to pre-pull images of YurtStaticSet %v, %v", request.NamespacedName, err))
+		return ctrl.Result{}, err
+	}
+
 	// If all nodes have been upgraded, just return
 	// Put this here because we need to clean up the worker pods first
 	if totalNumber == upgradedNumber {
@@ -372,6 +412,21 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 		return r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
 	}
 
+	// Static pods are only upgraded on the nodes in maintenance windows
+	checker, err := maintenance.NewChecker(r.Client, instance.Spec.UpgradeStrategy.MaintenanceWindows, time.Now())
+	if err != nil {
+		klog.Errorf(Format("Fail to parse maintenance windows of YurtStaticSet %v, %v", request.NamespacedName, err))
+		return ctrl.Result{}, err
+	}
+
+	// Static pods are only upgraded on the canary nodes until the canary is promoted
+	canaryNodes, soakRemaining, err := r.syncCanary(instance, upgradeInfos, latestHash, time.Now())
+	if err != nil {
+		klog.Errorf(Format("Fail to sync canary of YurtStaticSet %v, %v", request.NamespacedName, err))
+		return ctrl.Result{}, err
+	}
+	restrictedNodes := intersectNodes(canaryNodes, pulledNodes)
+
 	switch instance.Spec.UpgradeStrategy.Type {
 	// AdvancedRollingUpdate Upgrade is to automate the upgrade process for the target static pods on ready nodes
 	// It supports rolling update and the max-unavailable number can be specified by users
@@ -381,28 +436,88 @@ func (r *ReconcileYurtStaticSet) Reconcile(_ context.Context, request reconcile.
 			return r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
 		}
 
-		if err := r.advancedRollingUpdate(instance, upgradeInfos, latestHash); err != nil {
+		if err := r.advancedRollingUpdate(instance, upgradeInfos, latestHash, checker, restrictedNodes); err != nil {
 			klog.Errorf(Format("Fail to AdvancedRollingUpdate upgrade of YurtStaticSet %v, %v", request.NamespacedName, err))
 			return ctrl.Result{}, err
 		}
-		return r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
+		// Requeue when the maintenance or pre-pull window of a waiting node starts or the canary finishes soaking
+		result, err := r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
+		if err == nil {
+			result.RequeueAfter = shortestRequeue(checker.RequeueAfter(), soakRemaining, prePullWait)
+		}
+		return result, err
 
 	// OTA Upgrade can help users control the timing of static pods upgrade
 	// It will set PodNeedUpgrade condition and work with YurtHub component
 	case appsv1alpha1.OTAUpgradeStrategyType:
-		if err := r.otaUpgrade(upgradeInfos); err != nil {
+		if err := r.otaUpgrade(upgradeInfos, checker, restrictedNodes); err != nil {
 			klog.Errorf(Format("Fail to OTA upgrade of YurtStaticSet %v, %v", request.NamespacedName, err))
 			return ctrl.Result{}, err
 		}
-		return r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
+		// Requeue when the maintenance or pre-pull window of a waiting node starts or the canary finishes soaking
+		result, err := r.updateYurtStaticSetStatus(instance, totalNumber, readyNumber, upgradedNumber)
+		if err == nil {
+			result.RequeueAfter = shortestRequeue(checker.RequeueAfter(), soakRemaining, prePullWait)
+		}
+		return result, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// genStaticPodManifests generates the main manifest and the bundled ones of YurtStaticSet
+func genStaticPodManifests(instance *appsv1alpha1.YurtStaticSet, hash string) (map[string]string, error) {
+	manifests := make(map[string]string, len(instance.Spec.BundledManifests)+1)
+	manifest, err := util.GenStaticPodManifest(&instance.Spec.Template, hash)
+	if err != nil {
+		return nil, err
+	}
+	manifests[instance.Spec.StaticPodManifest] = manifest
+
+	for i := range instance.Spec.BundledManifests {
+		bundled := &instance.Spec.BundledManifests[i]
+		if manifest, err = util.GenStaticPodManifest(&bundled.Template, hash); err != nil {
+			return nil, err
+		}
+		manifests[bundled.StaticPodManifest] = manifest
+	}
+	return manifests, nil
+}
+
+// staticPodBundle returns the bundled manifests of YurtStaticSet in json with their signatures,
+// an empty string is returned if there is no bundled manifest
+func staticPodBundle(instance *appsv1alpha1.YurtStaticSet) (string, error) {
+	signatures := make(map[string]string)
+	if v, ok := instance.Annotations[util.BundleSignaturesAnnotation]; ok {
+		if err := json.Unmarshal([]byte(v), &signatures); err != nil {
+			return "", fmt.Errorf("invalid annotation %s, %v", util.BundleSignaturesAnnotation, err)
+		}
+	}
+
+	bundle := make([]upgradeutil.BundledManifest, 0, len(instance.Spec.BundledManifests))
+	for _, bundled := range instance.Spec.BundledManifests {
+		bundle = append(bundle, upgradeutil.BundledManifest{
+			Manifest:  bundled.StaticPodManifest,
+			Name:      bundled.Template.Name,
+			Signature: signatures[bundled.StaticPodManifest],
+		})
+	}
+	return upgradeutil.EncodeBundle(bundle)
+}
+
 // syncConfigMap moves the target yurtstaticset's corresponding configmap to the latest state
-func (r *ReconcileYurtStaticSet) syncConfigMap(instance *appsv1alpha1.YurtStaticSet, hash, data string) error {
+func (r *ReconcileYurtStaticSet) syncConfigMap(instance *appsv1alpha1.YurtStaticSet, hash string, manifests map[string]string) error {
 	cmName := util.WithConfigMapPrefix(instance.Name)
+	rollbackWindow := util.RollbackWindow(&instance.Spec.UpgradeStrategy).String()
+	signature := instance.Annotations[util.ManifestSignatureAnnotation]
+	bundle, err := staticPodBundle(instance)
+	if err != nil {
+		return err
+	}
+	healthGate, err := upgradeutil.EncodeHealthGate(instance.Spec.UpgradeStrategy.HealthGate)
+	if err != nil {
+		return err
+	}
 	cm := &corev1.ConfigMap{}
 	if err := r.Get(context.TODO(), types.NamespacedName{Name: cmName, Namespace: instance.Namespace}, cm); err != nil {
 		// if the configmap does not exist, then create a new one
@@ -412,13 +527,15 @@ func (r *ReconcileYurtStaticSet) syncConfigMap(instance *appsv1alpha1.YurtStatic
 					Name:      cmName,
 					Namespace: instance.Namespace,
 					Annotations: map[string]string{
-						StaticPodHashAnnotation: hash,
+						StaticPodHashAnnotation:          hash,
+						util.RollbackWindowAnnotation:    rollbackWindow,
+						util.ManifestSignatureAnnotation: signature,
+						util.StaticPodBundleAnnotation:   bundle,
+						util.HealthGateAnnotation:        healthGate,
 					},
 				},
 
-				Data: map[string]string{
-					instance.Spec.StaticPodManifest: data,
-				},
+				Data: manifests,
 			}
 			if err := r.Create(context.TODO(), cm, &client.CreateOptions{}); err != nil {
 				return err
@@ -428,10 +545,17 @@ func (r *ReconcileYurtStaticSet) syncConfigMap(instance *appsv1alpha1.YurtStatic
 		return err
 	}
 
-	// if the hash value in the annotation of the cm does not match the latest hash, then update the data in the cm
-	if cm.Annotations[StaticPodHashAnnotation] != hash {
-		cm.Annotations[StaticPodHashAnnotation] = hash
-		cm.Data[instance.Spec.StaticPodManifest] = data
+	// if the hash value, the rollback window, the signature, the bundle or the health gate in the annotation
+	// of the cm does not match the latest one, then update the cm
+	if cm.Annotations[StaticPodHashAnnotation] != hash || cm.Annotations[util.RollbackWindowAnnotation] != rollbackWindow ||
+		cm.Annotations[util.ManifestSignatureAnnotation] != signature || cm.Annotations[util.StaticPodBundleAnnotation] != bundle ||
+		cm.Annotations[util.HealthGateAnnotation] != healthGate {
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, StaticPodHashAnnotation, hash)
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, util.RollbackWindowAnnotation, rollbackWindow)
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, util.ManifestSignatureAnnotation, signature)
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, util.StaticPodBundleAnnotation, bundle)
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, util.HealthGateAnnotation, healthGate)
+		cm.Data = manifests
 
 		if err := r.Update(context.TODO(), cm, &client.UpdateOptions{}); err != nil {
 			return err
@@ -442,9 +566,13 @@ func (r *ReconcileYurtStaticSet) syncConfigMap(instance *appsv1alpha1.YurtStatic
 }
 
 // advancedRollingUpdate automatically rolling upgrade the target static pods in cluster
-func (r *ReconcileYurtStaticSet) advancedRollingUpdate(instance *appsv1alpha1.YurtStaticSet, infos map[string]*upgradeinfo.UpgradeInfo, hash string) error {
+func (r *ReconcileYurtStaticSet) advancedRollingUpdate(instance *appsv1alpha1.YurtStaticSet, infos map[string]*upgradeinfo.UpgradeInfo,
+	hash string, checker *maintenance.Checker, restrictedNodes sets.String) error {
 	// readyUpgradeWaitingNodes represents nodes that need to create worker pods
-	readyUpgradeWaitingNodes := upgradeinfo.ReadyUpgradeWaitingNodes(infos)
+	readyUpgradeWaitingNodes, err := allowedNodes(upgradeinfo.ReadyUpgradeWaitingNodes(infos), checker, restrictedNodes)
+	if err != nil {
+		return err
+	}
 
 	waitingNumber := len(readyUpgradeWaitingNodes)
 	if waitingNumber == 0 {
@@ -469,13 +597,24 @@ func (r *ReconcileYurtStaticSet) advancedRollingUpdate(instance *appsv1alpha1.Yu
 	return nil
 }
 
-// otaUpgrade adds condition PodNeedUpgrade to the target static pods
-func (r *ReconcileYurtStaticSet) otaUpgrade(infos map[string]*upgradeinfo.UpgradeInfo) error {
+// otaUpgrade adds condition PodNeedUpgrade to the target static pods, the static pods out of
+// maintenance windows or the restricted nodes are not allowed to be upgraded
+func (r *ReconcileYurtStaticSet) otaUpgrade(infos map[string]*upgradeinfo.UpgradeInfo, checker *maintenance.Checker,
+	restrictedNodes sets.String) error {
 	upgradeNeededNodes, upgradedNodes := upgradeinfo.ListOutUpgradeNeededNodesAndUpgradedNodes(infos)
+	allowed, err := allowedNodes(upgradeNeededNodes, checker, restrictedNodes)
+	if err != nil {
+		return err
+	}
+	allowedSet := sets.NewString(allowed...)
 
 	// Set condition for upgrade needed static pods
 	for _, n := range upgradeNeededNodes {
-		if err := util.SetPodUpgradeCondition(r.Client, corev1.ConditionTrue, infos[n].StaticPod); err != nil {
+		status := corev1.ConditionTrue
+		if !allowedSet.Has(n) {
+			status = corev1.ConditionFalse
+		}
+		if err := util.SetPodUpgradeCondition(r.Client, status, infos[n].StaticPod); err != nil {
 			return err
 		}
 	}
@@ -490,6 +629,80 @@ func (r *ReconcileYurtStaticSet) otaUpgrade(infos map[string]*upgradeinfo.Upgrad
 	return nil
 }
 
+// allowedNodes returns the nodes in maintenance windows, only the restricted nodes are returned if restrictedNodes is not nil
+func allowedNodes(nodes []string, checker *maintenance.Checker, restrictedNodes sets.String) ([]string, error) {
+	var allowed []string
+	for _, n := range nodes {
+		if restrictedNodes != nil && !restrictedNodes.Has(n) {
+			continue
+		}
+		ok, err := checker.Allowed(n)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, n)
+		}
+	}
+	return allowed, nil
+}
+
+// intersectNodes returns the nodes in both sets, a nil set means all nodes and nil is returned if both are nil
+func intersectNodes(a, b sets.String) sets.String {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return a.Intersection(b)
+}
+
+// shortestRequeue returns the shortest positive duration, zero is returned if none is positive
+func shortestRequeue(durations ...time.Duration) time.Duration {
+	var shortest time.Duration
+	for _, d := range durations {
+		if d > 0 && (shortest == 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	return shortest
+}
+
+// recordRollbacks emits an event for each node whose static pod is newly rolled back
+func (r *ReconcileYurtStaticSet) recordRollbacks(instance *appsv1alpha1.YurtStaticSet, lastStatuses []appsv1alpha1.YurtStaticSetNodeStatus) {
+	rolledBack := make(map[string]bool)
+	for _, status := range lastStatuses {
+		rolledBack[status.NodeName] = status.State == appsv1alpha1.NodeUpgradeRolledBack
+	}
+
+	for _, status := range instance.Status.NodeStatuses {
+		if status.State == appsv1alpha1.NodeUpgradeRolledBack && !rolledBack[status.NodeName] {
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, "StaticPodRolledBack",
+				"static pod on node %s failed to be ready within %s, and the previous manifest is restored",
+				status.NodeName, util.RollbackWindow(&instance.Spec.UpgradeStrategy))
+		}
+	}
+}
+
+// recordProgress exports the number of nodes in each upgrade state of YurtStaticSet
+func recordProgress(instance *appsv1alpha1.YurtStaticSet) {
+	var p progress.Progress
+	for _, status := range instance.Status.NodeStatuses {
+		switch status.State {
+		case appsv1alpha1.NodeUpgradePending:
+			p.Pending++
+		case appsv1alpha1.NodeUpgradeStaged, appsv1alpha1.NodeUpgradeUpgrading:
+			p.Approved++
+		case appsv1alpha1.NodeUpgradeUpgraded:
+			p.Upgraded++
+		case appsv1alpha1.NodeUpgradeRolledBack:
+			p.Failed++
+		}
+	}
+	progress.Record(progress.KindYurtStaticSet, instance.Namespace, instance.Name, p)
+}
+
 // removeUnusedPods delete pods, include two situations: out-of-date worker pods and succeeded worker pods
 func (r *ReconcileYurtStaticSet) removeUnusedPods(pods []*corev1.Pod) error {
 	for _, pod := range pods {
@@ -520,8 +733,39 @@ func createUpgradeWorker(c client.Client, instance *appsv1alpha1.YurtStaticSet,
 			},
 		})
 		pod.Spec.Containers[0].Args = []string{fmt.Sprintf(ArgTmpl, util.Hyphen(instance.Name, node), instance.Namespace,
-			instance.Spec.StaticPodManifest, hash, mode)}
+			instance.Spec.StaticPodManifest, hash, mode, util.RollbackWindow(&instance.Spec.UpgradeStrategy))}
 		pod.Spec.Containers[0].Image = img
+
+		// The placeholders in manifest are rendered with the values of node by the worker
+		env, err := templateValuesEnv(c, node)
+		if err != nil {
+			return err
+		}
+		// The bundled manifests are upgraded together by the worker, their pods are suffixed with node name
+		bundle, err := staticPodBundle(instance)
+		if err != nil {
+			return err
+		}
+		// The upgrade succeeds only after the static pod passes the health gate
+		healthGate, err := upgradeutil.EncodeHealthGate(instance.Spec.UpgradeStrategy.HealthGate)
+		if err != nil {
+			return err
+		}
+		pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, env, corev1.EnvVar{
+			Name:  upgradeutil.ManifestSignatureEnv,
+			Value: instance.Annotations[util.ManifestSignatureAnnotation],
+		}, corev1.EnvVar{
+			Name:  upgradeutil.BundleEnv,
+			Value: bundle,
+		}, corev1.EnvVar{
+			Name:  upgradeutil.HealthGateEnv,
+			Value: healthGate,
+		}, corev1.EnvVar{
+			Name: upgradeutil.NodeNameEnv,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+			},
+		})
 		if err := controllerutil.SetControllerReference(instance, pod, c.Scheme()); err != nil {
 			return err
 		}
@@ -535,6 +779,19 @@ func createUpgradeWorker(c client.Client, instance *appsv1alpha1.YurtStaticSet,
 	return nil
 }
 
+// templateValuesEnv returns the env which contains the template values of the given node
+func templateValuesEnv(c client.Client, nodeName string) (corev1.EnvVar, error) {
+	node := &corev1.Node{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		return corev1.EnvVar{}, err
+	}
+	values, err := json.Marshal(upgradeutil.TemplateValuesFromNode(node))
+	if err != nil {
+		return corev1.EnvVar{}, err
+	}
+	return corev1.EnvVar{Name: upgradeutil.TemplateValuesEnv, Value: string(values)}, nil
+}
+
 // updateYurtStaticSetStatus set the status of instance to the given values
 func (r *ReconcileYurtStaticSet) updateYurtStaticSetStatus(instance *appsv1alpha1.YurtStaticSet, totalNum, readyNum, upgradedNum int32) (reconcile.Result, error) {
 	instance.Status.TotalNumber = totalNum
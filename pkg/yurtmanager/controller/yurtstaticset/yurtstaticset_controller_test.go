@@ -218,3 +218,63 @@ func TestReconcileYurtStaticSetDeleteConfigMap(t *testing.T) {
 		})
 	}
 }
+
+func TestStaticPodBundle(t *testing.T) {
+	instance := &appsv1alpha1.YurtStaticSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TestStaticPodName,
+			Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{
+				util.BundleSignaturesAnnotation: `{"mirror":"sig"}`,
+			},
+		},
+		Spec: appsv1alpha1.YurtStaticSetSpec{
+			StaticPodManifest: TestStaticPodName,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: TestStaticPodName},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: TestStaticPodImage}}},
+			},
+		},
+	}
+	if hash := util.ComputeSpecHash(&instance.Spec); hash != util.ComputeHash(&instance.Spec.Template) {
+		t.Errorf("expect spec hash %s equals to template hash without bundle", hash)
+	}
+	if bundle, err := staticPodBundle(instance); err != nil || bundle != "" {
+		t.Errorf("expect empty bundle, but got %q, %v", bundle, err)
+	}
+
+	instance.Spec.BundledManifests = []appsv1alpha1.YurtStaticSetBundledManifest{
+		{
+			StaticPodManifest: "mirror",
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx-mirror"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "mirror", Image: TestStaticPodImage}}},
+			},
+		},
+	}
+	hash := util.ComputeSpecHash(&instance.Spec)
+	if hash == util.ComputeHash(&instance.Spec.Template) {
+		t.Errorf("expect spec hash changed by bundled manifests")
+	}
+
+	manifests, err := genStaticPodManifests(instance, hash)
+	if err != nil {
+		t.Fatalf("fail to generate manifests, %v", err)
+	}
+	if len(manifests) != 2 || manifests[TestStaticPodName] == "" || manifests["mirror"] == "" {
+		t.Errorf("expect manifests of nginx and mirror, but got %v", manifests)
+	}
+
+	bundle, err := staticPodBundle(instance)
+	if err != nil {
+		t.Fatalf("fail to get bundle, %v", err)
+	}
+	if expected := `[{"manifest":"mirror","name":"nginx-mirror","signature":"sig"}]`; bundle != expected {
+		t.Errorf("expect bundle %s, but got %s", expected, bundle)
+	}
+
+	instance.Annotations[util.BundleSignaturesAnnotation] = "{"
+	if _, err := staticPodBundle(instance); err == nil {
+		t.Errorf("expect error of invalid signatures annotation")
+	}
+}
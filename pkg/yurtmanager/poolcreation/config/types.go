@@ -0,0 +1,28 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// NodePoolCreationConfiguration contains elements describing the nodepool creation service, which
+// creates nodepools from the templates configured in cluster for the nodes which are joining.
+type NodePoolCreationConfiguration struct {
+	// TemplatesConfigMap is the namespace/name of configmap which contains the nodepool templates, the keys
+	// are the names of templates and the values are NodePool manifests. Nodepool creation is disabled if it's empty.
+	TemplatesConfigMap string
+	// AllowedGroups are the groups of users who are allowed to create nodepools, the users are authenticated
+	// by their bearer tokens, like bootstrap tokens which are in the group system:bootstrappers.
+	AllowedGroups []string
+}
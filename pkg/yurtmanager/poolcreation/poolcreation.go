@@ -0,0 +1,215 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolcreation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/util/poolcreation"
+)
+
+const (
+	maxCreationRequestSize = 16 * 1024
+)
+
+// AuthenticateFunc authenticates the bearer token in request and returns the user of it.
+type AuthenticateFunc func(ctx context.Context, token string) (*authenticationv1.UserInfo, error)
+
+// Handler creates nodepools from the templates configured in cluster for the joining nodes, only
+// the users in allowed groups, like bootstrap tokens, are allowed to create nodepools.
+type Handler struct {
+	client        client.Client
+	templates     types.NamespacedName
+	allowedGroups sets.String
+	authenticate  AuthenticateFunc
+}
+
+// NewHandler creates a nodepool creation handler, the templates are read from the configmap.
+func NewHandler(c client.Client, templates types.NamespacedName, allowedGroups []string, authenticate AuthenticateFunc) *Handler {
+	return &Handler{
+		client:        c,
+		templates:     templates,
+		allowedGroups: sets.NewString(allowedGroups...),
+		authenticate:  authenticate,
+	}
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=create
+
+// SetupWithManager registers the nodepool creation handler on the webhook server of yurt-manager
+// if the templates configmap is configured.
+func SetupWithManager(c *appconfig.CompletedConfig, mgr manager.Manager) error {
+	cfg := c.ComponentConfig.NodePoolCreation
+	if len(cfg.TemplatesConfigMap) == 0 {
+		klog.Infof("no nodepool templates are configured, nodepool creation is disabled")
+		return nil
+	}
+	parts := strings.Split(cfg.TemplatesConfigMap, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("nodepool templates configmap %s should be in the format of namespace/name", cfg.TemplatesConfigMap)
+	}
+
+	templates := types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	mgr.GetWebhookServer().Register(poolcreation.CreationPath, NewHandler(mgr.GetClient(), templates, cfg.AllowedGroups, TokenReviewAuthenticator(mgr.GetClient())))
+	klog.Infof("nodepool creation is registered on %s with templates in %s", poolcreation.CreationPath, cfg.TemplatesConfigMap)
+	return nil
+}
+
+// TokenReviewAuthenticator authenticates bearer tokens by TokenReview of kube-apiserver.
+func TokenReviewAuthenticator(c client.Client) AuthenticateFunc {
+	return func(ctx context.Context, token string) (*authenticationv1.UserInfo, error) {
+		review := &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}
+		if err := c.Create(ctx, review); err != nil {
+			return nil, err
+		}
+		if !review.Status.Authenticated {
+			return nil, fmt.Errorf("token is not authenticated, %s", review.Status.Error)
+		}
+		return &review.Status.User, nil
+	}
+}
+
+// ServeHTTP authenticates the request and creates the nodepool from template if it doesn't exist.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 || token == r.Header.Get("Authorization") {
+		http.Error(w, "bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	user, err := h.authenticate(r.Context(), token)
+	if err != nil {
+		klog.Warningf("could not authenticate nodepool creation request, %v", err)
+		http.Error(w, "token is not authenticated", http.StatusUnauthorized)
+		return
+	}
+	if !h.allowedGroups.HasAny(user.Groups...) {
+		klog.Warningf("user %s is not allowed to create nodepools, groups %v", user.Username, user.Groups)
+		http.Error(w, "user is not allowed to create nodepools", http.StatusForbidden)
+		return
+	}
+
+	var req poolcreation.CreationRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxCreationRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode request, %v", err), http.StatusBadRequest)
+		return
+	}
+	if errs := validation.IsDNS1123Label(req.Name); len(errs) != 0 {
+		http.Error(w, fmt.Sprintf("nodepool name %q is invalid, %s", req.Name, strings.Join(errs, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.createNodePool(r.Context(), &req, user.Username)
+	if err != nil {
+		if apierrors.IsBadRequest(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		klog.Errorf("could not create nodepool %s from template %s, %v", req.Name, req.Template, err)
+		http.Error(w, "could not create nodepool", http.StatusInternalServerError)
+		return
+	}
+	if resp.Created {
+		klog.Infof("nodepool %s is created from template %s by %s(node %s)", req.Name, req.Template, user.Username, req.NodeName)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		klog.Errorf("could not write nodepool creation response, %v", err)
+	}
+}
+
+// createNodePool creates the nodepool from template, the existing nodepool is not changed.
+func (h *Handler) createNodePool(ctx context.Context, req *poolcreation.CreationRequest, username string) (*poolcreation.CreationResponse, error) {
+	resp := &poolcreation.CreationResponse{Name: req.Name}
+	var existing appsv1beta1.NodePool
+	if err := h.client.Get(ctx, types.NamespacedName{Name: req.Name}, &existing); err == nil {
+		return resp, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	np, err := h.renderTemplate(ctx, req.Template)
+	if err != nil {
+		return nil, err
+	}
+	np.Name = req.Name
+	if np.Annotations == nil {
+		np.Annotations = map[string]string{}
+	}
+	np.Annotations[apps.AnnotationNodePoolTemplate] = req.Template
+	np.Annotations[apps.AnnotationNodePoolCreatedBy] = username
+	if err := h.client.Create(ctx, np); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return resp, nil
+		}
+		return nil, err
+	}
+	resp.Created = true
+	return resp, nil
+}
+
+// renderTemplate returns the nodepool in template, only the labels, annotations and spec of template are kept.
+func (h *Handler) renderTemplate(ctx context.Context, name string) (*appsv1beta1.NodePool, error) {
+	var cm corev1.ConfigMap
+	if err := h.client.Get(ctx, h.templates, &cm); err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[name]
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("nodepool template %s is not found", name))
+	}
+
+	var template appsv1beta1.NodePool
+	if err := yaml.UnmarshalStrict([]byte(data), &template); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("nodepool template %s is invalid, %v", name, err))
+	}
+	return &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolcreation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/util/poolcreation"
+)
+
+const edgeSiteTemplate = `apiVersion: apps.openyurt.io/v1beta1
+kind: NodePool
+metadata:
+  labels:
+    site.example.com/tier: edge
+spec:
+  type: Edge
+  hostNetwork: true
+`
+
+func fakeAuthenticate(_ context.Context, token string) (*authenticationv1.UserInfo, error) {
+	switch token {
+	case "abcdef.0123456789abcdef":
+		return &authenticationv1.UserInfo{Username: "system:bootstrap:abcdef", Groups: []string{"system:bootstrappers", "system:authenticated"}}, nil
+	case "serviceaccount-token":
+		return &authenticationv1.UserInfo{Username: "system:serviceaccount:default:default", Groups: []string{"system:serviceaccounts"}}, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func TestServeHTTP(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
+
+	testcases := map[string]struct {
+		method     string
+		token      string
+		body       string
+		statusCode int
+		created    bool
+	}{
+		"get is not allowed": {
+			method:     http.MethodGet,
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		"token is required": {
+			method:     http.MethodPost,
+			body:       `{"name":"hangzhou","template":"edge-site"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		"token is not authenticated": {
+			method:     http.MethodPost,
+			token:      "invalid",
+			body:       `{"name":"hangzhou","template":"edge-site"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		"user is not in allowed groups": {
+			method:     http.MethodPost,
+			token:      "serviceaccount-token",
+			body:       `{"name":"hangzhou","template":"edge-site"}`,
+			statusCode: http.StatusForbidden,
+		},
+		"invalid nodepool name": {
+			method:     http.MethodPost,
+			token:      "abcdef.0123456789abcdef",
+			body:       `{"name":"Hang_Zhou","template":"edge-site"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		"template is not found": {
+			method:     http.MethodPost,
+			token:      "abcdef.0123456789abcdef",
+			body:       `{"name":"hangzhou","template":"cloud-site"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		"nodepool exists": {
+			method:     http.MethodPost,
+			token:      "abcdef.0123456789abcdef",
+			body:       `{"name":"beijing","template":"edge-site"}`,
+			statusCode: http.StatusOK,
+		},
+		"nodepool is created": {
+			method:     http.MethodPost,
+			token:      "abcdef.0123456789abcdef",
+			body:       `{"name":"hangzhou","template":"edge-site","nodeName":"node-a"}`,
+			statusCode: http.StatusOK,
+			created:    true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			templates := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "nodepool-templates"},
+				Data:       map[string]string{"edge-site": edgeSiteTemplate},
+			}
+			existing := &appsv1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "beijing"}}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(templates, existing).Build()
+			h := NewHandler(c, types.NamespacedName{Namespace: "kube-system", Name: "nodepool-templates"}, []string{"system:bootstrappers"}, fakeAuthenticate)
+
+			req := httptest.NewRequest(tc.method, poolcreation.CreationPath, strings.NewReader(tc.body))
+			if len(tc.token) != 0 {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tc.statusCode {
+				t.Fatalf("expect status code %d, but got %d: %s", tc.statusCode, w.Code, w.Body.String())
+			}
+			if tc.statusCode != http.StatusOK {
+				return
+			}
+
+			var resp poolcreation.CreationResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("could not decode response, %v", err)
+			}
+			if resp.Created != tc.created {
+				t.Errorf("expect created %v, but got %v", tc.created, resp.Created)
+			}
+			if !tc.created {
+				return
+			}
+
+			var np appsv1beta1.NodePool
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: resp.Name}, &np); err != nil {
+				t.Fatalf("could not get nodepool, %v", err)
+			}
+			if np.Spec.Type != appsv1beta1.Edge || !np.Spec.HostNetwork || np.Labels["site.example.com/tier"] != "edge" {
+				t.Errorf("nodepool is not created from template, %#v", np)
+			}
+			if np.Annotations[apps.AnnotationNodePoolTemplate] != "edge-site" || np.Annotations[apps.AnnotationNodePoolCreatedBy] != "system:bootstrap:abcdef" {
+				t.Errorf("unexpected annotations %v", np.Annotations)
+			}
+		})
+	}
+}
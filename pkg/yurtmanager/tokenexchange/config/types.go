@@ -0,0 +1,42 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TokenExchangeConfiguration contains elements describing the token exchange service, which
+// exchanges external identities of joining nodes for short-lived bootstrap tokens.
+type TokenExchangeConfiguration struct {
+	// OIDCIssuerURL is the issuer of OIDC id tokens, OIDC identity is not accepted if it's empty.
+	OIDCIssuerURL string
+	// OIDCAudiences are the accepted audiences of OIDC id tokens.
+	OIDCAudiences []string
+
+	// InstanceIdentityCertFile is the PEM file of certificates which sign cloud instance identity
+	// documents, instance identity is not accepted if it's empty.
+	InstanceIdentityCertFile string
+	// InstanceIdentityAccounts are the cloud accounts whose instances are allowed to join,
+	// it's required when InstanceIdentityCertFile is specified.
+	InstanceIdentityAccounts []string
+	// InstanceIdentityMaxAge is the max age of instances which are allowed to join by instance identity.
+	InstanceIdentityMaxAge metav1.Duration
+
+	// TokenTTL is the lifetime of exchanged bootstrap tokens.
+	TokenTTL metav1.Duration
+}
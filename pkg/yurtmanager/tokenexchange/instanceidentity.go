@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+// instanceIdentityDocument is the common part of instance identity documents of cloud providers.
+type instanceIdentityDocument struct {
+	AccountID  string `json:"accountId"`
+	InstanceID string `json:"instanceId"`
+	// PendingTime is the time when the instance is launched.
+	PendingTime time.Time `json:"pendingTime"`
+}
+
+// instanceIdentityVerifier verifies instance identity documents which are signed by cloud provider.
+type instanceIdentityVerifier struct {
+	certs    []*x509.Certificate
+	accounts sets.String
+	maxAge   time.Duration
+	now      func() time.Time
+}
+
+// NewInstanceIdentityVerifier creates a verifier for instance identity documents which are signed by
+// certificates in certFile, only instances of accounts are accepted, because the signing certificates of
+// public clouds are shared by all the accounts. Instance identity documents don't expire, so only the
+// documents of instances launched within maxAge are accepted, and the node is required to be named after
+// the instance, so a leaked document can neither be replayed indefinitely nor join as other nodes.
+func NewInstanceIdentityVerifier(certFile string, accounts []string, maxAge time.Duration) (Verifier, error) {
+	if len(accounts) == 0 {
+		return nil, errors.New("accounts of instance identity documents should be specified")
+	}
+	if maxAge <= 0 {
+		return nil, errors.New("max age of instance identity documents should be positive")
+	}
+
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse certificates in %s, %w", certFile, err)
+	}
+
+	return &instanceIdentityVerifier{
+		certs:    certs,
+		accounts: sets.NewString(accounts...),
+		maxAge:   maxAge,
+		now:      time.Now,
+	}, nil
+}
+
+func (v *instanceIdentityVerifier) Verify(_ context.Context, req *token.ExchangeRequest) (string, error) {
+	if len(req.Document) == 0 || len(req.Signature) == 0 {
+		return "", errors.New("instance identity document or signature is empty")
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return "", fmt.Errorf("could not decode signature, %w", err)
+	}
+	if err := v.verifySignature([]byte(req.Document), sig); err != nil {
+		return "", err
+	}
+
+	var doc instanceIdentityDocument
+	if err := json.Unmarshal([]byte(req.Document), &doc); err != nil {
+		return "", fmt.Errorf("could not decode instance identity document, %w", err)
+	}
+	if len(doc.AccountID) == 0 || len(doc.InstanceID) == 0 {
+		return "", errors.New("account or instance is not found in instance identity document")
+	}
+	if !v.accounts.Has(doc.AccountID) {
+		return "", fmt.Errorf("account %s is not allowed", doc.AccountID)
+	}
+	if doc.PendingTime.IsZero() {
+		return "", errors.New("pending time is not found in instance identity document")
+	}
+	if age := v.now().Sub(doc.PendingTime); age > v.maxAge {
+		return "", fmt.Errorf("instance %s is launched %s ago, which is older than %s", doc.InstanceID, age.Round(time.Second), v.maxAge)
+	}
+	if req.NodeName != strings.ToLower(doc.InstanceID) {
+		return "", fmt.Errorf("node name %s is not the name of instance %s", req.NodeName, doc.InstanceID)
+	}
+
+	return fmt.Sprintf("iid:%s/%s", doc.AccountID, doc.InstanceID), nil
+}
+
+func (v *instanceIdentityVerifier) verifySignature(document, sig []byte) error {
+	digest := sha256.Sum256(document)
+	now := v.now()
+	for _, cert := range v.certs {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			continue
+		}
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+	}
+	return errors.New("signature of instance identity document is not verified")
+}
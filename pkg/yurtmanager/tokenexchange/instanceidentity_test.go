@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+func TestInstanceIdentityVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key, %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key, %v", err)
+	}
+	cert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "instance-identity"}, key)
+	if err != nil {
+		t.Fatalf("could not create certificate, %v", err)
+	}
+	certFile := filepath.Join(t.TempDir(), "iid.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatalf("could not write certificate, %v", err)
+	}
+
+	sign := func(k *rsa.PrivateKey, doc string) string {
+		digest := sha256.Sum256([]byte(doc))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("could not sign document, %v", err)
+		}
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+	now := time.Now()
+	doc := `{"accountId":"123456","instanceId":"i-abc","region":"us-east-1","pendingTime":"` + now.Add(-10*time.Minute).UTC().Format(time.RFC3339) + `"}`
+	oldDoc := `{"accountId":"123456","instanceId":"i-abc","region":"us-east-1","pendingTime":"` + now.Add(-2*time.Hour).UTC().Format(time.RFC3339) + `"}`
+	noTimeDoc := `{"accountId":"123456","instanceId":"i-abc","region":"us-east-1"}`
+
+	testcases := map[string]struct {
+		accounts  []string
+		nodeName  string
+		document  string
+		signature string
+		identity  string
+		isErr     bool
+	}{
+		"valid document of allowed account": {
+			document:  doc,
+			signature: sign(key, doc),
+			identity:  "iid:123456/i-abc",
+		},
+		"account is not allowed": {
+			accounts:  []string{"654321"},
+			document:  doc,
+			signature: sign(key, doc),
+			isErr:     true,
+		},
+		"instance is launched long ago": {
+			document:  oldDoc,
+			signature: sign(key, oldDoc),
+			isErr:     true,
+		},
+		"document without pending time": {
+			document:  noTimeDoc,
+			signature: sign(key, noTimeDoc),
+			isErr:     true,
+		},
+		"node is not named after instance": {
+			nodeName:  "node-a",
+			document:  doc,
+			signature: sign(key, doc),
+			isErr:     true,
+		},
+		"signed by other key": {
+			document:  doc,
+			signature: sign(otherKey, doc),
+			isErr:     true,
+		},
+		"document is tampered": {
+			document:  `{"accountId":"123456","instanceId":"i-def","region":"us-east-1"}`,
+			signature: sign(key, doc),
+			isErr:     true,
+		},
+		"signature is not base64": {
+			document:  doc,
+			signature: "!!!",
+			isErr:     true,
+		},
+		"document without instance": {
+			document:  `{"accountId":"123456"}`,
+			signature: sign(key, `{"accountId":"123456"}`),
+			isErr:     true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			accounts := tc.accounts
+			if len(accounts) == 0 {
+				accounts = []string{"123456"}
+			}
+			nodeName := tc.nodeName
+			if len(nodeName) == 0 {
+				nodeName = "i-abc"
+			}
+			v, err := NewInstanceIdentityVerifier(certFile, accounts, time.Hour)
+			if err != nil {
+				t.Fatalf("could not create verifier, %v", err)
+			}
+
+			identity, err := v.Verify(context.TODO(), &token.ExchangeRequest{
+				Type:      token.IdentityTypeInstanceIdentity,
+				NodeName:  nodeName,
+				Document:  tc.document,
+				Signature: tc.signature,
+			})
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if identity != tc.identity {
+				t.Errorf("expect identity %s, but got %s", tc.identity, identity)
+			}
+		})
+	}
+}
+
+func TestNewInstanceIdentityVerifier(t *testing.T) {
+	if _, err := NewInstanceIdentityVerifier("iid.pem", nil, time.Hour); err == nil {
+		t.Errorf("expect error when accounts are not specified")
+	}
+	if _, err := NewInstanceIdentityVerifier("iid.pem", []string{"123456"}, 0); err == nil {
+		t.Errorf("expect error when max age is not positive")
+	}
+}
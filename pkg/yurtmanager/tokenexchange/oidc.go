@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+const (
+	oidcDiscoveryPath   = "/.well-known/openid-configuration"
+	maxOIDCResponseSize = 1024 * 1024
+
+	// maxIDTokenAge is the max age of id tokens which are accepted, so that the leaked id tokens
+	// which have long lifetime can't be exchanged after they're issued for a while.
+	maxIDTokenAge = 10 * time.Minute
+)
+
+// oidcVerifier verifies OIDC id tokens against the keys published by issuer.
+type oidcVerifier struct {
+	issuer    string
+	audiences []string
+	client    *http.Client
+	now       func() time.Time
+
+	sync.Mutex
+	keys *jose.JSONWebKeySet
+}
+
+// NewOIDCVerifier creates a verifier for OIDC id tokens which are issued by issuer for one of audiences.
+func NewOIDCVerifier(issuer string, audiences []string) Verifier {
+	return &oidcVerifier{
+		issuer:    strings.TrimSuffix(issuer, "/"),
+		audiences: audiences,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		now:       time.Now,
+	}
+}
+
+func (v *oidcVerifier) Verify(ctx context.Context, req *token.ExchangeRequest) (string, error) {
+	if len(req.Token) == 0 {
+		return "", errors.New("id token is empty")
+	}
+	tok, err := jwt.ParseSigned(req.Token)
+	if err != nil {
+		return "", err
+	}
+	if len(tok.Headers) == 0 {
+		return "", errors.New("id token has no header")
+	}
+
+	keys, err := v.keySet(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return "", err
+	}
+	var claims jwt.Claims
+	if err := tok.Claims(keys, &claims); err != nil {
+		return "", err
+	}
+	if claims.Expiry == nil {
+		return "", errors.New("id token has no expiration")
+	}
+	if claims.IssuedAt == nil {
+		return "", errors.New("id token has no issued at")
+	}
+	now := v.now()
+	if err := claims.Validate(jwt.Expected{Issuer: v.issuer, Time: now}); err != nil {
+		return "", err
+	}
+	if age := now.Sub(claims.IssuedAt.Time()); age > maxIDTokenAge {
+		return "", fmt.Errorf("id token is issued %s ago, it's older than %s", age.Round(time.Second), maxIDTokenAge)
+	}
+	if !v.audienceAccepted(claims.Audience) {
+		return "", fmt.Errorf("audience %v is not accepted", []string(claims.Audience))
+	}
+	if len(claims.Subject) == 0 {
+		return "", errors.New("id token has no subject")
+	}
+
+	return fmt.Sprintf("oidc:%s#%s", v.issuer, claims.Subject), nil
+}
+
+func (v *oidcVerifier) audienceAccepted(aud jwt.Audience) bool {
+	for i := range v.audiences {
+		if aud.Contains(v.audiences[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySet returns the cached keys of issuer, and keys are refreshed when the kid is unknown
+// so that rotation of keys in issuer can be handled.
+func (v *oidcVerifier) keySet(ctx context.Context, kid string) (*jose.JSONWebKeySet, error) {
+	v.Lock()
+	defer v.Unlock()
+	if v.keys != nil && len(v.keys.Key(kid)) != 0 {
+		return v.keys, nil
+	}
+
+	keys, err := v.fetchKeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys.Key(kid)) == 0 {
+		return nil, fmt.Errorf("key %q is not found in issuer %s", kid, v.issuer)
+	}
+	v.keys = keys
+	return keys, nil
+}
+
+func (v *oidcVerifier) fetchKeySet(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, v.issuer+oidcDiscoveryPath, &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != v.issuer {
+		return nil, fmt.Errorf("issuer %s in discovery document mismatches with %s", discovery.Issuer, v.issuer)
+	}
+	if len(discovery.JWKSURI) == 0 {
+		return nil, fmt.Errorf("jwks_uri is not found in discovery document of %s", v.issuer)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := v.getJSON(ctx, discovery.JWKSURI, &keys); err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, obj interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not get %s, status code %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseSize)).Decode(obj)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+func TestOIDCVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key, %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key, %v", err)
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	issuer = srv.URL
+
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	sign := func(k *rsa.PrivateKey, kid string, claims jwt.Claims) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: k}, (&jose.SignerOptions{}).WithHeader("kid", kid))
+		if err != nil {
+			t.Fatalf("could not create signer, %v", err)
+		}
+		raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		if err != nil {
+			t.Fatalf("could not sign token, %v", err)
+		}
+		return raw
+	}
+	validClaims := func() jwt.Claims {
+		return jwt.Claims{
+			Issuer:   issuer,
+			Subject:  "node-a",
+			Audience: jwt.Audience{"openyurt"},
+			Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt: jwt.NewNumericDate(now.Add(-time.Minute)),
+		}
+	}
+
+	testcases := map[string]struct {
+		token    func() string
+		identity string
+		isErr    bool
+	}{
+		"valid id token": {
+			token:    func() string { return sign(key, "k1", validClaims()) },
+			identity: "oidc:" + issuer + "#node-a",
+		},
+		"signed by unknown key": {
+			token: func() string { return sign(otherKey, "k2", validClaims()) },
+			isErr: true,
+		},
+		"signed by other key with known kid": {
+			token: func() string { return sign(otherKey, "k1", validClaims()) },
+			isErr: true,
+		},
+		"expired id token": {
+			token: func() string {
+				c := validClaims()
+				c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+		"id token without expiration": {
+			token: func() string {
+				c := validClaims()
+				c.Expiry = nil
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+		"id token without issued at": {
+			token: func() string {
+				c := validClaims()
+				c.IssuedAt = nil
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+		"id token is issued long ago": {
+			token: func() string {
+				c := validClaims()
+				c.IssuedAt = jwt.NewNumericDate(now.Add(-maxIDTokenAge - time.Minute))
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+		"audience is not accepted": {
+			token: func() string {
+				c := validClaims()
+				c.Audience = jwt.Audience{"others"}
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+		"issuer mismatches": {
+			token: func() string {
+				c := validClaims()
+				c.Issuer = "https://others"
+				return sign(key, "k1", c)
+			},
+			isErr: true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			v := NewOIDCVerifier(issuer, []string{"openyurt"}).(*oidcVerifier)
+			v.client = srv.Client()
+			v.now = func() time.Time { return now }
+
+			identity, err := v.Verify(context.TODO(), &token.ExchangeRequest{Type: token.IdentityTypeOIDC, Token: tc.token()})
+			if tc.isErr != (err != nil) {
+				t.Fatalf("expect error %v, but got %v", tc.isErr, err)
+			}
+			if identity != tc.identity {
+				t.Errorf("expect identity %s, but got %s", tc.identity, identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	appconfig "github.com/openyurtio/openyurt/cmd/yurt-manager/app/config"
+	bootstraptokenv1 "github.com/openyurtio/openyurt/pkg/util/kubernetes/kubeadm/app/apis/bootstraptoken/v1"
+	kubeadmconstants "github.com/openyurtio/openyurt/pkg/util/kubernetes/kubeadm/app/constants"
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+const (
+	maxExchangeRequestSize = 64 * 1024
+
+	// exchangeQPS and exchangeBurst limit the rate of token exchanges, every exchange which is not
+	// served by a reused token creates a bootstrap token.
+	exchangeQPS   = 5
+	exchangeBurst = 50
+
+	// identityHashLabel records the hash of identity which the bootstrap token is exchanged for.
+	identityHashLabel = "openyurt.io/token-exchange-identity"
+)
+
+// errNodeMismatch means the identity is bound to another node by a valid bootstrap token.
+var errNodeMismatch = errors.New("identity is bound to another node")
+
+// Verifier verifies the external identity in the exchange request and returns
+// a human readable identity of the joining node.
+type Verifier interface {
+	Verify(ctx context.Context, req *token.ExchangeRequest) (string, error)
+}
+
+// Handler exchanges verified external identities for short-lived bootstrap tokens. One bootstrap
+// token is issued for each identity, and it's bound to the node which it's exchanged for by
+// token.BoundNodeAnnotation, so that replayed identities neither create more tokens nor get tokens
+// for other nodes, and csrs of other nodes requested by the token are not approved.
+type Handler struct {
+	client client.Client
+	// reader reads bootstrap tokens from apiserver directly, so Secrets in the cluster are not cached.
+	reader    client.Reader
+	verifiers map[string]Verifier
+	tokenTTL  time.Duration
+	limiter   *rate.Limiter
+	now       func() time.Time
+
+	// lock serializes the exchanges, so only one token is created for concurrent requests of an identity.
+	lock sync.Mutex
+}
+
+// NewHandler creates a token exchange handler, verifiers are indexed by identity type.
+func NewHandler(c client.Client, reader client.Reader, verifiers map[string]Verifier, tokenTTL time.Duration) *Handler {
+	return &Handler{
+		client:    c,
+		reader:    reader,
+		verifiers: verifiers,
+		tokenTTL:  tokenTTL,
+		limiter:   rate.NewLimiter(exchangeQPS, exchangeBurst),
+		now:       time.Now,
+	}
+}
+
+// SetupWithManager registers the token exchange handler on the webhook server of yurt-manager
+// if any identity provider is configured.
+func SetupWithManager(c *appconfig.CompletedConfig, mgr manager.Manager) error {
+	cfg := c.ComponentConfig.TokenExchange
+	verifiers := make(map[string]Verifier)
+	if len(cfg.OIDCIssuerURL) != 0 {
+		verifiers[token.IdentityTypeOIDC] = NewOIDCVerifier(cfg.OIDCIssuerURL, cfg.OIDCAudiences)
+	}
+	if len(cfg.InstanceIdentityCertFile) != 0 {
+		v, err := NewInstanceIdentityVerifier(cfg.InstanceIdentityCertFile, cfg.InstanceIdentityAccounts, cfg.InstanceIdentityMaxAge.Duration)
+		if err != nil {
+			return err
+		}
+		verifiers[token.IdentityTypeInstanceIdentity] = v
+	}
+	if len(verifiers) == 0 {
+		klog.Infof("no identity provider is configured, token exchange is disabled")
+		return nil
+	}
+
+	mgr.GetWebhookServer().Register(token.ExchangePath, NewHandler(mgr.GetClient(), mgr.GetAPIReader(), verifiers, cfg.TokenTTL.Duration))
+	klog.Infof("token exchange is registered on %s", token.ExchangePath)
+	return nil
+}
+
+// ServeHTTP verifies the identity in request and responds a bootstrap token for it.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.limiter.Allow() {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many token exchange requests", http.StatusTooManyRequests)
+		return
+	}
+
+	var req token.ExchangeRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxExchangeRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode request, %v", err), http.StatusBadRequest)
+		return
+	}
+	if errs := validation.IsDNS1123Subdomain(req.NodeName); len(errs) != 0 {
+		http.Error(w, fmt.Sprintf("node name %q is invalid, %s", req.NodeName, strings.Join(errs, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	verifier, ok := h.verifiers[req.Type]
+	if !ok {
+		http.Error(w, fmt.Sprintf("identity type %q is not supported", req.Type), http.StatusBadRequest)
+		return
+	}
+
+	identity, err := verifier.Verify(r.Context(), &req)
+	if err != nil {
+		klog.Warningf("could not verify %s identity for node %s, %v", req.Type, req.NodeName, err)
+		http.Error(w, "identity is not verified", http.StatusUnauthorized)
+		return
+	}
+
+	resp, err := h.exchangeBootstrapToken(r.Context(), identity, req.NodeName)
+	if errors.Is(err, errNodeMismatch) {
+		klog.Warningf("could not exchange bootstrap token for %s(node %s), %v", identity, req.NodeName, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if err != nil {
+		klog.Errorf("could not create bootstrap token for %s, %v", identity, err)
+		http.Error(w, "could not create bootstrap token", http.StatusInternalServerError)
+		return
+	}
+	klog.Infof("bootstrap token is exchanged for %s(node %s), expires at %s", identity, req.NodeName, resp.Expiration.Format(time.RFC3339))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		klog.Errorf("could not write token exchange response, %v", err)
+	}
+}
+
+// exchangeBootstrapToken returns the bootstrap token exchanged for identity before if it's valid for at least
+// half of its lifetime, otherwise a new one is created. The identity can't be exchanged for other nodes
+// until all the tokens exchanged for it expire.
+func (h *Handler) exchangeBootstrapToken(ctx context.Context, identity, nodeName string) (*token.ExchangeResponse, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var secrets corev1.SecretList
+	if err := h.reader.List(ctx, &secrets, client.InNamespace(metav1.NamespaceSystem),
+		client.MatchingLabels{identityHashLabel: identityHash(identity)}); err != nil {
+		return nil, err
+	}
+
+	now := h.now()
+	var reused *token.ExchangeResponse
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		expiration, err := time.Parse(time.RFC3339, string(secret.Data[bootstrapapi.BootstrapTokenExpirationKey]))
+		if err != nil || !now.Before(expiration) {
+			continue
+		}
+		if bound := secret.Annotations[token.BoundNodeAnnotation]; bound != nodeName {
+			return nil, fmt.Errorf("%w %s", errNodeMismatch, bound)
+		}
+		if reused == nil && expiration.Sub(now) >= h.tokenTTL/2 {
+			reused = &token.ExchangeResponse{
+				Token: bootstraputil.TokenFromIDAndSecret(string(secret.Data[bootstrapapi.BootstrapTokenIDKey]),
+					string(secret.Data[bootstrapapi.BootstrapTokenSecretKey])),
+				Expiration: expiration,
+			}
+		}
+	}
+	if reused != nil {
+		return reused, nil
+	}
+	return h.createBootstrapToken(ctx, identity, nodeName)
+}
+
+func (h *Handler) createBootstrapToken(ctx context.Context, identity, nodeName string) (*token.ExchangeResponse, error) {
+	tokenStr, err := bootstraputil.GenerateBootstrapToken()
+	if err != nil {
+		return nil, err
+	}
+	bts, err := bootstraptokenv1.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+
+	expiration := h.now().Add(h.tokenTTL).Truncate(time.Second)
+	secret := bootstraptokenv1.BootstrapTokenToSecret(&bootstraptokenv1.BootstrapToken{
+		Token:       bts,
+		Description: fmt.Sprintf("exchanged for %s by node %s", identity, nodeName),
+		Expires:     &metav1.Time{Time: expiration},
+		Usages:      kubeadmconstants.DefaultTokenUsages,
+		Groups:      kubeadmconstants.DefaultTokenGroups,
+	})
+	secret.Labels = map[string]string{identityHashLabel: identityHash(identity)}
+	secret.Annotations = map[string]string{token.BoundNodeAnnotation: nodeName}
+	if err := h.client.Create(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return &token.ExchangeResponse{
+		Token:      bts.String(),
+		Expiration: expiration,
+	}, nil
+}
+
+// identityHash returns the hash of identity which can be used as a label value.
+func identityHash(identity string) string {
+	sum := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bootstraptokenv1 "github.com/openyurtio/openyurt/pkg/util/kubernetes/kubeadm/app/apis/bootstraptoken/v1"
+	"github.com/openyurtio/openyurt/pkg/util/token"
+)
+
+type fakeVerifier struct {
+	identity string
+	err      error
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, _ *token.ExchangeRequest) (string, error) {
+	return v.identity, v.err
+}
+
+func TestServeHTTP(t *testing.T) {
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	verifiers := map[string]Verifier{
+		token.IdentityTypeOIDC:             &fakeVerifier{identity: "oidc:https://issuer#node-a"},
+		token.IdentityTypeInstanceIdentity: &fakeVerifier{err: errors.New("bad signature")},
+	}
+
+	testcases := map[string]struct {
+		method     string
+		body       string
+		statusCode int
+	}{
+		"get is not allowed": {
+			method:     http.MethodGet,
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		"invalid request body": {
+			method:     http.MethodPost,
+			body:       "{",
+			statusCode: http.StatusBadRequest,
+		},
+		"unsupported identity type": {
+			method:     http.MethodPost,
+			body:       `{"type":"unknown"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		"identity is not verified": {
+			method:     http.MethodPost,
+			body:       `{"type":"instance-identity","document":"{}","signature":"c2ln","nodeName":"node-a"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		"node name is required": {
+			method:     http.MethodPost,
+			body:       `{"type":"oidc","token":"id-token"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		"identity is exchanged": {
+			method:     http.MethodPost,
+			body:       `{"type":"oidc","token":"id-token","nodeName":"node-a"}`,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			h := NewHandler(c, c, verifiers, 15*time.Minute)
+			h.now = func() time.Time { return now }
+
+			req := httptest.NewRequest(tc.method, token.ExchangePath, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tc.statusCode {
+				t.Fatalf("expect status code %d, but got %d: %s", tc.statusCode, w.Code, w.Body.String())
+			}
+			if tc.statusCode != http.StatusOK {
+				return
+			}
+
+			var resp token.ExchangeResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("could not decode response, %v", err)
+			}
+			if !resp.Expiration.Equal(now.Add(15 * time.Minute)) {
+				t.Errorf("expect expiration %v, but got %v", now.Add(15*time.Minute), resp.Expiration)
+			}
+			bts, err := bootstraptokenv1.NewBootstrapTokenString(resp.Token)
+			if err != nil {
+				t.Fatalf("invalid token %s, %v", resp.Token, err)
+			}
+
+			var secret corev1.Secret
+			key := types.NamespacedName{Namespace: "kube-system", Name: bootstraputil.BootstrapTokenSecretName(bts.ID)}
+			if err := c.Get(context.TODO(), key, &secret); err != nil {
+				t.Fatalf("could not get bootstrap token secret, %v", err)
+			}
+			if secret.Type != corev1.SecretType(bootstrapapi.SecretTypeBootstrapToken) {
+				t.Errorf("expect secret type %s, but got %s", bootstrapapi.SecretTypeBootstrapToken, secret.Type)
+			}
+			expected := map[string]string{
+				bootstrapapi.BootstrapTokenSecretKey:           bts.Secret,
+				bootstrapapi.BootstrapTokenExpirationKey:       "2023-08-01T10:15:00Z",
+				bootstrapapi.BootstrapTokenUsageAuthentication: "true",
+				bootstrapapi.BootstrapTokenUsageSigningKey:     "true",
+				bootstrapapi.BootstrapTokenExtraGroupsKey:      "system:bootstrappers:kubeadm:default-node-token",
+				bootstrapapi.BootstrapTokenDescriptionKey:      "exchanged for oidc:https://issuer#node-a by node node-a",
+			}
+			for k, v := range expected {
+				if string(secret.Data[k]) != v {
+					t.Errorf("expect %s=%s, but got %s", k, v, string(secret.Data[k]))
+				}
+			}
+		})
+	}
+}
+
+func TestExchangeBootstrapToken(t *testing.T) {
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	h := NewHandler(c, c, nil, 15*time.Minute)
+	h.now = func() time.Time { return now }
+	ctx := context.TODO()
+
+	first, err := h.exchangeBootstrapToken(ctx, "iid:123/i-a", "node-a")
+	if err != nil {
+		t.Fatalf("could not exchange bootstrap token, %v", err)
+	}
+
+	// the token is reused for the replayed identity
+	now = now.Add(5 * time.Minute)
+	resp, err := h.exchangeBootstrapToken(ctx, "iid:123/i-a", "node-a")
+	if err != nil {
+		t.Fatalf("could not exchange bootstrap token, %v", err)
+	}
+	if *resp != *first {
+		t.Errorf("expect token %v is reused, but got %v", first, resp)
+	}
+
+	// the identity is bound to node-a while its token is valid
+	if _, err := h.exchangeBootstrapToken(ctx, "iid:123/i-a", "node-b"); !errors.Is(err, errNodeMismatch) {
+		t.Errorf("expect node mismatch error, but got %v", err)
+	}
+	if _, err := h.exchangeBootstrapToken(ctx, "iid:123/i-b", "node-b"); err != nil {
+		t.Errorf("could not exchange bootstrap token for another identity, %v", err)
+	}
+
+	// a new token is created when the token is going to expire
+	now = now.Add(5 * time.Minute)
+	resp, err = h.exchangeBootstrapToken(ctx, "iid:123/i-a", "node-a")
+	if err != nil {
+		t.Fatalf("could not exchange bootstrap token, %v", err)
+	}
+	if resp.Token == first.Token || !resp.Expiration.Equal(now.Add(15*time.Minute)) {
+		t.Errorf("expect a new token is created, but got %v", resp)
+	}
+
+	var secrets corev1.SecretList
+	if err := c.List(ctx, &secrets); err != nil {
+		t.Fatalf("could not list secrets, %v", err)
+	}
+	if len(secrets.Items) != 3 {
+		t.Errorf("expect 3 bootstrap tokens, but got %d", len(secrets.Items))
+	}
+}
+
+func TestServeHTTPRateLimit(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	h := NewHandler(c, c, map[string]Verifier{token.IdentityTypeOIDC: &fakeVerifier{identity: "oidc:https://issuer#node-a"}}, 15*time.Minute)
+
+	var limited int
+	for i := 0; i < exchangeBurst+1; i++ {
+		req := httptest.NewRequest(http.MethodPost, token.ExchangePath, strings.NewReader(`{"type":"oidc","token":"id-token","nodeName":"node-a"}`))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code == http.StatusTooManyRequests {
+			limited++
+		}
+	}
+	if limited == 0 {
+		t.Errorf("expect requests beyond the burst are limited")
+	}
+}
@@ -24,11 +24,14 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
 )
 
@@ -122,35 +125,100 @@ func (webhook *DeploymentRenderHandler) Default(ctx context.Context, obj runtime
 	}
 	render := overriders[0]
 
+	nodepoolLabels, err := webhook.getNodePoolLabels(ctx, instance, nodepool, render.Entries)
+	if err != nil {
+		return err
+	}
+
 	for _, entry := range render.Entries {
-		for _, pool := range entry.Pools {
-			if pool[0] == '-' && pool[1:] == nodepool {
-				continue
+		matched, err := matchEntry(&entry, nodepool, nodepoolLabels)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		// Replace items
+		replaceItems(deployment, entry.Items)
+		// json patch
+		for i, patch := range entry.Patches {
+			if strings.Contains(string(patch.Value.Raw), "{{nodepool}}") {
+				newPatchString := strings.ReplaceAll(string(patch.Value.Raw), "{{nodepool}}", nodepool)
+				entry.Patches[i].Value = apiextensionsv1.JSON{Raw: []byte(newPatchString)}
 			}
-			if pool == nodepool || pool == "*" {
-				// Replace items
-				replaceItems(deployment, entry.Items)
-				// json patch
-				for i, patch := range entry.Patches {
-					if strings.Contains(string(patch.Value.Raw), "{{nodepool}}") {
-						newPatchString := strings.ReplaceAll(string(patch.Value.Raw), "{{nodepool}}", nodepool)
-						entry.Patches[i].Value = apiextensionsv1.JSON{Raw: []byte(newPatchString)}
-					}
-				}
-				// Implement injection
-				dataStruct := v1.Deployment{}
-				pc := PatchControl{
-					patches:     entry.Patches,
-					patchObject: deployment,
-					dataStruct:  dataStruct,
-				}
-				if err := pc.jsonMergePatch(); err != nil {
-					klog.Infof("fail to update patches for deployment: %v", err)
-					return err
-				}
+		}
+		// Implement injection
+		dataStruct := v1.Deployment{}
+		pc := PatchControl{
+			patches:     entry.Patches,
+			patchObject: deployment,
+			dataStruct:  dataStruct,
+		}
+		if err := pc.jsonMergePatch(); err != nil {
+			klog.Infof("fail to update patches for deployment: %v", err)
+			return err
+		}
+		if err := applyJSONPatch(deployment, entry.JSONPatch, nodepool); err != nil {
+			klog.Infof("fail to apply json patch for deployment: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// matchEntry checks the entry targets the pool, the pool is matched by name in Pools or
+// by the labels of its nodepool with PoolSelector, and the pool excluded by "-" prefix is never matched.
+func matchEntry(entry *v1alpha1.Entry, pool string, nodepoolLabels map[string]string) (bool, error) {
+	matched := false
+	for _, p := range entry.Pools {
+		if len(p) > 1 && p[0] == '-' && p[1:] == pool {
+			return false, nil
+		}
+		if p == pool || p == "*" {
+			matched = true
+		}
+	}
+	if matched || entry.PoolSelector == nil {
+		return matched, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(entry.PoolSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid pool selector, %v", err)
+	}
+	return selector.Matches(labels.Set(nodepoolLabels)), nil
+}
+
+// getNodePoolLabels returns the labels of nodepool of the pool, it is only fetched when the
+// entries select pools by PoolSelector.
+func (webhook *DeploymentRenderHandler) getNodePoolLabels(ctx context.Context, instance client.Object, pool string, entries []v1alpha1.Entry) (map[string]string, error) {
+	needed := false
+	for i := range entries {
+		if entries[i].PoolSelector != nil {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	nodepoolName := pool
+	if yas, ok := instance.(*v1alpha1.YurtAppSet); ok {
+		for i := range yas.Spec.Topology.Pools {
+			if yas.Spec.Topology.Pools[i].Name == pool {
+				nodepoolName = adapter.PoolNodePoolName(&yas.Spec.Topology.Pools[i])
 				break
 			}
 		}
 	}
-	return nil
+
+	var np appsv1beta1.NodePool
+	if err := webhook.Client.Get(ctx, client.ObjectKey{Name: nodepoolName}, &np); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return np.Labels, nil
 }
@@ -28,6 +28,7 @@ import (
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 )
 
 var (
@@ -305,6 +306,10 @@ func TestDeploymentRenderHandler_Default(t *testing.T) {
 		t.Logf("failed to add yurt custom resource")
 		return
 	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Logf("failed to add yurt custom resource")
+		return
+	}
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
 		t.Logf("failed to add kubernetes clint-go custom resource")
 		return
@@ -324,3 +329,56 @@ func TestDeploymentRenderHandler_Default(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchEntry(t *testing.T) {
+	gpuSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"site-class": "gpu"}}
+	testcases := map[string]struct {
+		entry          v1alpha1.Entry
+		pool           string
+		nodepoolLabels map[string]string
+		expect         bool
+	}{
+		"pool is matched by name": {
+			entry:  v1alpha1.Entry{Pools: []string{"hangzhou"}},
+			pool:   "hangzhou",
+			expect: true,
+		},
+		"pool is matched by wildcard": {
+			entry:  v1alpha1.Entry{Pools: []string{"*"}},
+			pool:   "hangzhou",
+			expect: true,
+		},
+		"pool is excluded": {
+			entry: v1alpha1.Entry{Pools: []string{"*", "-hangzhou"}},
+			pool:  "hangzhou",
+		},
+		"pool is matched by selector": {
+			entry:          v1alpha1.Entry{PoolSelector: gpuSelector},
+			pool:           "hangzhou",
+			nodepoolLabels: map[string]string{"site-class": "gpu"},
+			expect:         true,
+		},
+		"pool is not matched by selector": {
+			entry:          v1alpha1.Entry{PoolSelector: gpuSelector},
+			pool:           "hangzhou",
+			nodepoolLabels: map[string]string{"site-class": "cpu"},
+		},
+		"pool matched by selector is excluded": {
+			entry:          v1alpha1.Entry{Pools: []string{"-hangzhou"}, PoolSelector: gpuSelector},
+			pool:           "hangzhou",
+			nodepoolLabels: map[string]string{"site-class": "gpu"},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			matched, err := matchEntry(&tc.entry, tc.pool, tc.nodepoolLabels)
+			if err != nil {
+				t.Fatalf("failed to match entry, %v", err)
+			}
+			if matched != tc.expect {
+				t.Errorf("expect matched %v, but got %v", tc.expect, matched)
+			}
+		})
+	}
+}
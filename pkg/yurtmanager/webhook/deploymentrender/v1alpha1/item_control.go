@@ -17,12 +17,17 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
 )
 
 func replaceItems(deployment *v1.Deployment, items []v1alpha1.Item) {
+	claimed := sets.NewString()
 	for _, item := range items {
 		switch {
 		case item.Replicas != nil:
@@ -38,6 +43,61 @@ func replaceItems(deployment *v1.Deployment, items []v1alpha1.Item) {
 					deployment.Spec.Template.Spec.InitContainers[i].Image = item.Image.ImageClaim
 				}
 			}
+			claimed.Insert(item.Image.ContainerName)
+		}
+	}
+
+	// the first registry item matching the image of a container wins
+	for _, item := range items {
+		if item.ImageRegistry == nil {
+			continue
+		}
+		rewriteContainerImages(deployment.Spec.Template.Spec.Containers, item.ImageRegistry, claimed)
+		rewriteContainerImages(deployment.Spec.Template.Spec.InitContainers, item.ImageRegistry, claimed)
+	}
+}
+
+// rewriteContainerImages rewrites the images of containers which are not claimed, and the containers
+// rewritten are added to claimed.
+func rewriteContainerImages(containers []corev1.Container, registry *v1alpha1.ImageRegistryItem, claimed sets.String) {
+	for i := range containers {
+		if claimed.Has(containers[i].Name) {
+			continue
+		}
+		if image, ok := rewriteImage(containers[i].Image, registry); ok {
+			containers[i].Image = image
+			claimed.Insert(containers[i].Name)
 		}
 	}
 }
+
+// rewriteImage replaces the prefix From of image with To, and the registry of image is replaced if From
+// is empty. The image is also matched in its fully qualified form, like docker.io/library/nginx for nginx.
+func rewriteImage(image string, registry *v1alpha1.ImageRegistryItem) (string, bool) {
+	domain, remainder := splitImageDomain(image)
+	to := strings.TrimSuffix(registry.To, "/")
+	if len(registry.From) == 0 {
+		return to + "/" + remainder, true
+	}
+
+	from := strings.TrimSuffix(registry.From, "/")
+	for _, name := range []string{image, domain + "/" + remainder} {
+		if strings.HasPrefix(name, from+"/") {
+			return to + name[len(from):], true
+		}
+	}
+	return image, false
+}
+
+// splitImageDomain splits image into the registry domain and the remainder like docker does, the domain
+// defaults to docker.io and the official images in docker.io are prefixed with library/.
+func splitImageDomain(image string) (string, string) {
+	i := strings.IndexRune(image, '/')
+	if i == -1 {
+		return "docker.io", "library/" + image
+	}
+	if !strings.ContainsAny(image[:i], ".:") && image[:i] != "localhost" {
+		return "docker.io", image
+	}
+	return image[:i], image[i+1:]
+}
@@ -102,3 +102,56 @@ func TestReplaceItems(t *testing.T) {
 	}
 	replaceItems(testItemDeployment, items)
 }
+
+func TestReplaceItemsWithImageRegistry(t *testing.T) {
+	deployment := testItemDeployment.DeepCopy()
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "nginx", Image: "nginx:1.19"},
+		{Name: "agent", Image: "registry.example.com/edge/agent:v1"},
+		{Name: "proxy", Image: "quay.io/edge/proxy:v1"},
+		{Name: "claimed", Image: "busybox"},
+	}
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{
+		{Name: "init", Image: "openyurt/init:v1"},
+	}
+	items := []v1alpha1.Item{
+		{
+			Image: &v1alpha1.ImageItem{
+				ContainerName: "claimed",
+				ImageClaim:    "busybox:1.36",
+			},
+		},
+		{
+			ImageRegistry: &v1alpha1.ImageRegistryItem{
+				From: "docker.io/library",
+				To:   "eu.mirror.example.com/library/",
+			},
+		},
+		{
+			ImageRegistry: &v1alpha1.ImageRegistryItem{
+				From: "registry.example.com",
+				To:   "eu.registry.example.com",
+			},
+		},
+		{
+			ImageRegistry: &v1alpha1.ImageRegistryItem{
+				To: "eu.mirror.example.com",
+			},
+		},
+	}
+	replaceItems(deployment, items)
+
+	expect := map[string]string{
+		"nginx":   "eu.mirror.example.com/library/nginx:1.19",
+		"agent":   "eu.registry.example.com/edge/agent:v1",
+		"proxy":   "eu.mirror.example.com/edge/proxy:v1",
+		"claimed": "busybox:1.36",
+		"init":    "eu.mirror.example.com/openyurt/init:v1",
+	}
+	containers := append(deployment.Spec.Template.Spec.Containers, deployment.Spec.Template.Spec.InitContainers...)
+	for _, c := range containers {
+		if c.Image != expect[c.Name] {
+			t.Errorf("expect image %s of container %s, but got %s", expect[c.Name], c.Name, c.Image)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"bytes"
 	"encoding/json"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -69,3 +70,35 @@ func (pc *PatchControl) jsonMergePatch() error {
 	}
 	return json.Unmarshal(patchedData, &pc.patchObject)
 }
+
+// applyJSONPatch applies the RFC 6902 JSON patch to the deployment, and {{nodepool}}
+// in the patch is replaced by the name of nodepool.
+func applyJSONPatch(deployment *appsv1.Deployment, operations []v1alpha1.JSONPatchOperation, nodepool string) error {
+	if len(operations) == 0 {
+		return nil
+	}
+	patchBytes, err := json.Marshal(operations)
+	if err != nil {
+		return err
+	}
+	patchBytes = bytes.ReplaceAll(patchBytes, []byte("{{nodepool}}"), []byte(nodepool))
+	patchObj, err := jsonpatch.DecodePatch(patchBytes)
+	if err != nil {
+		return err
+	}
+
+	deploymentData, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	patchedData, err := patchObj.Apply(deploymentData)
+	if err != nil {
+		return err
+	}
+	patched := &appsv1.Deployment{}
+	if err := json.Unmarshal(patchedData, patched); err != nil {
+		return err
+	}
+	*deployment = *patched
+	return nil
+}
@@ -92,3 +92,47 @@ func TestJsonMergePatch(t *testing.T) {
 	}
 	t.Logf("image:%v", testPatchDeployment.Spec.Template.Spec.Containers[0].Name)
 }
+
+func TestApplyJSONPatch(t *testing.T) {
+	deployment := testPatchDeployment.DeepCopy()
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+	operations := []v1alpha1.JSONPatchOperation{
+		{
+			Op:    "add",
+			Path:  "/spec/template/spec/initContainers/0",
+			Value: &apiextensionsv1.JSON{Raw: []byte(`{"name":"prepare","image":"prepare:{{nodepool}}"}`)},
+		},
+		{
+			Op:    "test",
+			Path:  "/spec/template/spec/initContainers/1/name",
+			Value: &apiextensionsv1.JSON{Raw: []byte(`"init"`)},
+		},
+		{
+			Op:   "remove",
+			Path: "/spec/template/metadata/labels/app",
+		},
+	}
+
+	if err := applyJSONPatch(deployment, operations, "hangzhou"); err != nil {
+		t.Fatalf("fail to apply json patch, %v", err)
+	}
+
+	initContainers := deployment.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 2 || initContainers[0].Name != "prepare" || initContainers[0].Image != "prepare:hangzhou" {
+		t.Errorf("expect init container prepare:hangzhou is inserted at index 0, but got %v", initContainers)
+	}
+	if _, ok := deployment.Spec.Template.Labels["app"]; ok {
+		t.Errorf("expect label app is removed, but got %v", deployment.Spec.Template.Labels)
+	}
+
+	failedTest := []v1alpha1.JSONPatchOperation{
+		{
+			Op:    "test",
+			Path:  "/spec/template/spec/initContainers/0/name",
+			Value: &apiextensionsv1.JSON{Raw: []byte(`"init"`)},
+		},
+	}
+	if err := applyJSONPatch(deployment, failedTest, "hangzhou"); err == nil {
+		t.Errorf("expect json patch failed for test operation, but got nil")
+	}
+}
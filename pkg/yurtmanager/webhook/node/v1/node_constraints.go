@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the License);
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an AS IS BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// checkNodePoolConstraints returns the constraints of NodePool that the node doesn't satisfy.
+func checkNodePoolConstraints(node *v1.Node, constraints *appsv1beta1.NodePoolConstraints) []string {
+	if constraints == nil {
+		return nil
+	}
+
+	var violations []string
+	keys := make([]string, 0, len(constraints.RequiredLabels))
+	for k := range constraints.RequiredLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want := constraints.RequiredLabels[k]
+		if got, ok := node.Labels[k]; !ok {
+			violations = append(violations, fmt.Sprintf("label %s is required", k))
+		} else if len(want) != 0 && got != want {
+			violations = append(violations, fmt.Sprintf("label %s should be %s, but got %s", k, want, got))
+		}
+	}
+
+	if len(constraints.OperatingSystems) != 0 {
+		os := node.Labels[v1.LabelOSStable]
+		if len(os) == 0 {
+			os = node.Status.NodeInfo.OperatingSystem
+		}
+		if !containsString(constraints.OperatingSystems, os) {
+			violations = append(violations, fmt.Sprintf("operating system %q is not in %v", os, constraints.OperatingSystems))
+		}
+	}
+
+	if len(constraints.Architectures) != 0 {
+		arch := node.Labels[v1.LabelArchStable]
+		if len(arch) == 0 {
+			arch = node.Status.NodeInfo.Architecture
+		}
+		if !containsString(constraints.Architectures, arch) {
+			violations = append(violations, fmt.Sprintf("architecture %q is not in %v", arch, constraints.Architectures))
+		}
+	}
+
+	if len(constraints.MinKernelVersion) != 0 {
+		if msg := checkKernelVersion(node.Status.NodeInfo.KernelVersion, constraints.MinKernelVersion); len(msg) != 0 {
+			violations = append(violations, msg)
+		}
+	}
+
+	if constraints.RequirePublicIP && net.ParseIP(node.Labels[apps.NodePublicIPLabel]) == nil {
+		violations = append(violations, fmt.Sprintf("label %s with a valid ip is required", apps.NodePublicIPLabel))
+	}
+
+	return violations
+}
+
+func checkKernelVersion(kernelVersion, minKernelVersion string) string {
+	minVersion, err := version.ParseGeneric(minKernelVersion)
+	if err != nil {
+		return fmt.Sprintf("min kernel version %q can not be parsed, %v", minKernelVersion, err)
+	}
+
+	if len(kernelVersion) == 0 {
+		return "kernel version is not reported"
+	}
+	v, err := version.ParseGeneric(kernelVersion)
+	if err != nil {
+		return fmt.Sprintf("kernel version %q can not be parsed, %v", kernelVersion, err)
+	}
+	if !v.AtLeast(minVersion) {
+		return fmt.Sprintf("kernel version %s is lower than %s", kernelVersion, minKernelVersion)
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for i := range list {
+		if strings.EqualFold(list[i], s) {
+			return true
+		}
+	}
+	return false
+}
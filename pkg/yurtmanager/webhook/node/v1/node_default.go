@@ -57,5 +57,17 @@ func (webhook *NodeHandler) Default(ctx context.Context, obj runtime.Object, req
 	if np.Spec.HostNetwork {
 		node.Labels[apps.NodePoolHostNetworkLabel] = "true"
 	}
+
+	// record the violations of NodePool constraints for the node
+	if np.Spec.Constraints != nil && np.Spec.Constraints.Enforcement == appsv1beta1.EnforcementFlag {
+		if violations := checkNodePoolConstraints(node, np.Spec.Constraints); len(violations) != 0 {
+			if node.Annotations == nil {
+				node.Annotations = make(map[string]string)
+			}
+			node.Annotations[apps.AnnotationConstraintViolations] = strings.Join(violations, "; ")
+			return nil
+		}
+	}
+	delete(node.Annotations, apps.AnnotationConstraintViolations)
 	return nil
 }
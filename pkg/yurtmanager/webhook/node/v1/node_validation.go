@@ -19,19 +19,29 @@ package v1
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	migrationutil "github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/migration"
 )
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
-func (webhook *NodeHandler) ValidateCreate(_ context.Context, obj runtime.Object, req admission.Request) error {
-	return nil
+func (webhook *NodeHandler) ValidateCreate(ctx context.Context, obj runtime.Object, req admission.Request) error {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Node but got a %T", obj))
+	}
+
+	return webhook.validateNodePoolConstraints(ctx, node)
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
@@ -45,18 +55,97 @@ func (webhook *NodeHandler) ValidateUpdate(ctx context.Context, oldObj, newObj r
 		return apierrors.NewBadRequest(fmt.Sprintf("expected a Node} but got a %T", oldObj))
 	}
 
-	if allErrs := validateNodeUpdate(newNode, oldNode, req); len(allErrs) > 0 {
+	// nodes are allowed to be detached from the deleting NodePool
+	if webhook.isDetachingFromDeletingPool(ctx, newNode, oldNode) {
+		return nil
+	}
+
+	oldNp := oldNode.Labels[apps.NodePoolLabel]
+	newNp := newNode.Labels[apps.NodePoolLabel]
+	// node is allowed to be moved into the target NodePool by node migration
+	relabeling := len(oldNp) != 0 && oldNp != newNp && migrationutil.IsRelabeling(oldNode, newNp)
+	if relabeling {
+		if allErrs := webhook.validateNodeRelabeling(ctx, newNode); len(allErrs) > 0 {
+			return apierrors.NewInvalid(v1.SchemeGroupVersion.WithKind("Node").GroupKind(), newNode.Name, allErrs)
+		}
+	} else if allErrs := validateNodeUpdate(newNode, oldNode, req); len(allErrs) > 0 {
 		return apierrors.NewInvalid(v1.SchemeGroupVersion.WithKind("Node").GroupKind(), newNode.Name, allErrs)
 	}
 
+	// only the node which is joining into a NodePool or moved into another NodePool is checked against the constraints
+	if len(oldNp) == 0 || relabeling {
+		return webhook.validateNodePoolConstraints(ctx, newNode)
+	}
 	return nil
 }
 
+// validateNodeRelabeling checks the pool related labels of node which is moved into the target NodePool
+// by node migration, the type and hostnetwork labels should be the ones of the target NodePool.
+func (webhook *NodeHandler) validateNodeRelabeling(ctx context.Context, node *v1.Node) field.ErrorList {
+	var np appsv1beta1.NodePool
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: node.Labels[apps.NodePoolLabel]}, &np); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("labels").Child(apps.NodePoolLabel), err)}
+	}
+
+	var errList field.ErrorList
+	if npType := strings.ToLower(string(np.Spec.Type)); node.Labels[apps.NodePoolTypeLabel] != npType {
+		errList = append(errList, field.Invalid(field.NewPath("metadata").Child("labels").Child(apps.NodePoolTypeLabel), node.Labels[apps.NodePoolTypeLabel],
+			fmt.Sprintf("nodepool.openyurt.io/type should be %q of NodePool %s", npType, np.Name)))
+	}
+
+	if hostNetwork := node.Labels[apps.NodePoolHostNetworkLabel] == "true"; hostNetwork != np.Spec.HostNetwork {
+		errList = append(errList, field.Invalid(field.NewPath("metadata").Child("labels").Child(apps.NodePoolHostNetworkLabel), node.Labels[apps.NodePoolHostNetworkLabel],
+			fmt.Sprintf("nodepool.openyurt.io/hostnetwork should be consistent with NodePool %s", np.Name)))
+	}
+	return errList
+}
+
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
 func (webhook *NodeHandler) ValidateDelete(_ context.Context, obj runtime.Object, req admission.Request) error {
 	return nil
 }
 
+// validateNodePoolConstraints rejects the node if it doesn't satisfy the constraints of NodePool
+// which it belongs to, and the enforcement of constraints is Reject.
+func (webhook *NodeHandler) validateNodePoolConstraints(ctx context.Context, node *v1.Node) error {
+	npName := node.Labels[apps.NodePoolLabel]
+	if len(npName) == 0 {
+		return nil
+	}
+
+	var np appsv1beta1.NodePool
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: npName}, &np); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if np.Spec.Constraints == nil || np.Spec.Constraints.Enforcement == appsv1beta1.EnforcementFlag {
+		return nil
+	}
+
+	if violations := checkNodePoolConstraints(node, np.Spec.Constraints); len(violations) != 0 {
+		return apierrors.NewInvalid(v1.SchemeGroupVersion.WithKind("Node").GroupKind(), node.Name, field.ErrorList{
+			field.Forbidden(field.NewPath("metadata").Child("labels").Child(apps.NodePoolLabel),
+				fmt.Sprintf("node doesn't satisfy the constraints of NodePool %s: %s", npName, strings.Join(violations, "; "))),
+		})
+	}
+	return nil
+}
+
+// isDetachingFromDeletingPool checks whether the nodepool label is removed from the node
+// because the NodePool which the node belongs to is deleting.
+func (webhook *NodeHandler) isDetachingFromDeletingPool(ctx context.Context, newNode, oldNode *v1.Node) bool {
+	oldNp := oldNode.Labels[apps.NodePoolLabel]
+	if len(oldNp) == 0 || len(newNode.Labels[apps.NodePoolLabel]) != 0 {
+		return false
+	}
+
+	var np appsv1beta1.NodePool
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: oldNp}, &np); err != nil {
+		return apierrors.IsNotFound(err)
+	}
+	return np.DeletionTimestamp != nil
+}
+
 func validateNodeUpdate(newNode, oldNode *v1.Node, req admission.Request) field.ErrorList {
 	oldNp := oldNode.Labels[apps.NodePoolLabel]
 	newNp := newNode.Labels[apps.NodePoolLabel]
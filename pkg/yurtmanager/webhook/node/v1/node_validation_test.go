@@ -25,9 +25,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/openyurtio/openyurt/pkg/apis"
 	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 )
 
 func TestValidateUpdate(t *testing.T) {
@@ -124,6 +129,123 @@ func TestValidateUpdate(t *testing.T) {
 			},
 			errCode: 0,
 		},
+		"node is moved into another pool by migration": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:            "hangzhou",
+						apps.NodePoolTypeLabel:        "edge",
+						apps.NodePoolHostNetworkLabel: "true",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationMigrateTo:       "shanghai",
+						apps.AnnotationMigrationStatus: `{"phase":"Relabeling","sourcePool":"hangzhou","targetPool":"shanghai","cordoned":true,"phaseStartTime":null}`,
+					},
+				},
+			},
+			newNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "shanghai",
+						apps.NodePoolTypeLabel: "cloud",
+					},
+				},
+			},
+			errCode: 0,
+		},
+		"node is moved into another pool by migration with labels of source pool": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:            "hangzhou",
+						apps.NodePoolTypeLabel:        "edge",
+						apps.NodePoolHostNetworkLabel: "true",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationMigrateTo:       "shanghai",
+						apps.AnnotationMigrationStatus: `{"phase":"Relabeling","sourcePool":"hangzhou","targetPool":"shanghai","cordoned":true,"phaseStartTime":null}`,
+					},
+				},
+			},
+			newNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:            "shanghai",
+						apps.NodePoolTypeLabel:        "edge",
+						apps.NodePoolHostNetworkLabel: "true",
+					},
+				},
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node is moved into another pool by migration without satisfying constraints": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "hangzhou",
+						apps.NodePoolTypeLabel: "edge",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationMigrateTo:       "guangzhou",
+						apps.AnnotationMigrationStatus: `{"phase":"Relabeling","sourcePool":"hangzhou","targetPool":"guangzhou","cordoned":true,"phaseStartTime":null}`,
+					},
+				},
+			},
+			newNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "guangzhou",
+						apps.NodePoolTypeLabel: "edge",
+					},
+				},
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node is moved into another pool before draining is completed": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel: "hangzhou",
+					},
+					Annotations: map[string]string{
+						apps.AnnotationMigrateTo:       "shanghai",
+						apps.AnnotationMigrationStatus: `{"phase":"Draining","sourcePool":"hangzhou","targetPool":"shanghai","cordoned":true,"phaseStartTime":null}`,
+					},
+				},
+			},
+			newNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel: "shanghai",
+					},
+				},
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node is detached from deleting pool": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "beijing",
+						apps.NodePoolTypeLabel: "edge",
+					},
+				},
+			},
+			newNode: &corev1.Node{},
+			errCode: 0,
+		},
+		"node is detached from pool": {
+			oldNode: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						apps.NodePoolLabel:     "hangzhou",
+						apps.NodePoolTypeLabel: "edge",
+					},
+				},
+			},
+			newNode: &corev1.Node{},
+			errCode: http.StatusUnprocessableEntity,
+		},
 		"it is a normal node update without init labels": {
 			oldNode: &corev1.Node{},
 			newNode: &corev1.Node{
@@ -139,9 +261,43 @@ func TestValidateUpdate(t *testing.T) {
 		},
 	}
 
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+	now := metav1.Now()
+	pools := []client.Object{
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "hangzhou"},
+		},
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "shanghai"},
+			Spec:       appsv1beta1.NodePoolSpec{Type: appsv1beta1.Cloud},
+		},
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: "guangzhou"},
+			Spec: appsv1beta1.NodePoolSpec{
+				Type: appsv1beta1.Edge,
+				Constraints: &appsv1beta1.NodePoolConstraints{
+					RequiredLabels: map[string]string{"gpu": ""},
+					Enforcement:    appsv1beta1.EnforcementReject,
+				},
+			},
+		},
+		&appsv1beta1.NodePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "beijing",
+				Finalizers:        []string{appsv1beta1.NodePoolFinalizer},
+				DeletionTimestamp: &now,
+			},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pools...).Build()
+
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			h := &NodeHandler{}
+			h := &NodeHandler{Client: c}
 			err := h.ValidateUpdate(context.TODO(), tc.oldNode, tc.newNode, admission.Request{})
 			if tc.errCode == 0 && err != nil {
 				t.Errorf("Expected error code %d, got %v", tc.errCode, err)
@@ -154,3 +310,112 @@ func TestValidateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCreate(t *testing.T) {
+	pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "hangzhou",
+		},
+		Spec: appsv1beta1.NodePoolSpec{
+			Type: appsv1beta1.Edge,
+			Constraints: &appsv1beta1.NodePoolConstraints{
+				RequiredLabels:   map[string]string{"gpu": ""},
+				OperatingSystems: []string{"linux"},
+				Architectures:    []string{"amd64", "arm64"},
+				MinKernelVersion: "4.19",
+				RequirePublicIP:  true,
+			},
+		},
+	}
+	validNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Labels: map[string]string{
+				apps.NodePoolLabel:     "hangzhou",
+				apps.NodePublicIPLabel: "1.2.3.4",
+				corev1.LabelOSStable:   "linux",
+				corev1.LabelArchStable: "arm64",
+				"gpu":                  "a100",
+			},
+		},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{
+				KernelVersion: "5.15.0-1034-azure",
+			},
+		},
+	}
+
+	testcases := map[string]struct {
+		node    runtime.Object
+		modify  func(node *corev1.Node)
+		errCode int
+	}{
+		"it is not a node": {
+			node:    &corev1.Pod{},
+			errCode: http.StatusBadRequest,
+		},
+		"node satisfies the constraints": {
+			node:    validNode,
+			errCode: 0,
+		},
+		"node without nodepool": {
+			node:    &corev1.Node{},
+			errCode: 0,
+		},
+		"node misses required label": {
+			node: validNode,
+			modify: func(node *corev1.Node) {
+				delete(node.Labels, "gpu")
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node with unsupported architecture": {
+			node: validNode,
+			modify: func(node *corev1.Node) {
+				node.Labels[corev1.LabelArchStable] = "riscv64"
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node with lower kernel version": {
+			node: validNode,
+			modify: func(node *corev1.Node) {
+				node.Status.NodeInfo.KernelVersion = "3.10.0-1160.el7.x86_64"
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+		"node without public ip": {
+			node: validNode,
+			modify: func(node *corev1.Node) {
+				delete(node.Labels, apps.NodePublicIPLabel)
+			},
+			errCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pool).Build()
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			obj := tc.node.DeepCopyObject()
+			if tc.modify != nil {
+				tc.modify(obj.(*corev1.Node))
+			}
+
+			h := &NodeHandler{Client: c}
+			err := h.ValidateCreate(context.TODO(), obj, admission.Request{})
+			if tc.errCode == 0 && err != nil {
+				t.Errorf("Expected error code %d, got %v", tc.errCode, err)
+			} else if tc.errCode != 0 {
+				statusErr, ok := err.(*errors.StatusError)
+				if !ok || tc.errCode != int(statusErr.Status().Code) {
+					t.Errorf("Expected error code %d, got %v", tc.errCode, err)
+				}
+			}
+		})
+	}
+}
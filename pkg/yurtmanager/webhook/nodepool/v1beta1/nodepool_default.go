@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/projectinfo"
 )
 
 // Default satisfies the defaulting webhook interface.
@@ -38,6 +39,15 @@ func (webhook *NodePoolHandler) Default(ctx context.Context, obj runtime.Object)
 		np.Spec.Type = v1beta1.Edge
 	}
 
+	if np.Spec.Type == v1beta1.Satellite {
+		setSatelliteDefaults(&np.Spec)
+	}
+
+	// specify default deletion policy as ForbidIfNonEmpty
+	if len(np.Spec.DeletionPolicy) == 0 {
+		np.Spec.DeletionPolicy = v1beta1.ForbidIfNonEmpty
+	}
+
 	// init node pool status
 	np.Status = v1beta1.NodePoolStatus{
 		ReadyNodeNum:   0,
@@ -47,3 +57,36 @@ func (webhook *NodePoolHandler) Default(ctx context.Context, obj runtime.Object)
 
 	return nil
 }
+
+// setSatelliteDefaults sets the defaults for Satellite NodePool, the nodes are required to have
+// public ip and registered as tunnel endpoints of gateway, the node autonomy is enabled so the
+// heartbeats of nodes can be delegated through yurt coordinator when the link is down, and pods
+// tolerate the unreachable nodes for a longer time.
+func setSatelliteDefaults(spec *v1beta1.NodePoolSpec) {
+	// nodes of satellite pool are connected from other pools directly, so the public ip
+	// is always required, even if the other constraints are specified.
+	if spec.Constraints == nil {
+		spec.Constraints = &v1beta1.NodePoolConstraints{}
+	}
+	spec.Constraints.RequirePublicIP = true
+
+	if spec.GatewayEndpoints == nil {
+		enabled := true
+		spec.GatewayEndpoints = &enabled
+	}
+
+	if _, ok := spec.Annotations[projectinfo.GetAutonomyAnnotation()]; !ok {
+		if spec.Annotations == nil {
+			spec.Annotations = make(map[string]string)
+		}
+		spec.Annotations[projectinfo.GetAutonomyAnnotation()] = "true"
+	}
+
+	if spec.PodDefaults == nil {
+		spec.PodDefaults = &v1beta1.NodePoolPodDefaults{}
+	}
+	if spec.PodDefaults.UnreachableTolerationSeconds == nil {
+		seconds := v1beta1.DefaultSatelliteTolerationSeconds
+		spec.PodDefaults.UnreachableTolerationSeconds = &seconds
+	}
+}
@@ -29,6 +29,8 @@ import (
 )
 
 func TestDefault(t *testing.T) {
+	enabled, disabled := true, false
+	satelliteSeconds, seconds := v1beta1.DefaultSatelliteTolerationSeconds, int64(600)
 	testcases := map[string]struct {
 		obj            runtime.Object
 		errHappened    bool
@@ -52,8 +54,34 @@ func TestDefault(t *testing.T) {
 					Name: "foo",
 				},
 				Spec: v1beta1.NodePoolSpec{
-					HostNetwork: true,
-					Type:        v1beta1.Edge,
+					HostNetwork:    true,
+					Type:           v1beta1.Edge,
+					DeletionPolicy: v1beta1.ForbidIfNonEmpty,
+				},
+				Status: v1beta1.NodePoolStatus{
+					ReadyNodeNum:   0,
+					UnreadyNodeNum: 0,
+					Nodes:          []string{},
+				},
+			},
+		},
+		"nodepool has deletion policy": {
+			obj: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type:           v1beta1.Edge,
+					DeletionPolicy: v1beta1.Orphan,
+				},
+			},
+			wantedNodePool: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type:           v1beta1.Edge,
+					DeletionPolicy: v1beta1.Orphan,
 				},
 				Status: v1beta1.NodePoolStatus{
 					ReadyNodeNum:   0,
@@ -77,8 +105,88 @@ func TestDefault(t *testing.T) {
 					Name: "foo",
 				},
 				Spec: v1beta1.NodePoolSpec{
-					HostNetwork: true,
-					Type:        v1beta1.Cloud,
+					HostNetwork:    true,
+					Type:           v1beta1.Cloud,
+					DeletionPolicy: v1beta1.ForbidIfNonEmpty,
+				},
+				Status: v1beta1.NodePoolStatus{
+					ReadyNodeNum:   0,
+					UnreadyNodeNum: 0,
+					Nodes:          []string{},
+				},
+			},
+		},
+		"satellite nodepool": {
+			obj: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type: v1beta1.Satellite,
+				},
+			},
+			wantedNodePool: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type:           v1beta1.Satellite,
+					DeletionPolicy: v1beta1.ForbidIfNonEmpty,
+					Annotations: map[string]string{
+						"node.beta.openyurt.io/autonomy": "true",
+					},
+					Constraints: &v1beta1.NodePoolConstraints{
+						RequirePublicIP: true,
+					},
+					GatewayEndpoints: &enabled,
+					PodDefaults: &v1beta1.NodePoolPodDefaults{
+						UnreachableTolerationSeconds: &satelliteSeconds,
+					},
+				},
+				Status: v1beta1.NodePoolStatus{
+					ReadyNodeNum:   0,
+					UnreadyNodeNum: 0,
+					Nodes:          []string{},
+				},
+			},
+		},
+		"satellite nodepool overrides defaults": {
+			obj: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type: v1beta1.Satellite,
+					Annotations: map[string]string{
+						"node.beta.openyurt.io/autonomy": "false",
+					},
+					Constraints: &v1beta1.NodePoolConstraints{
+						Enforcement: v1beta1.EnforcementFlag,
+					},
+					GatewayEndpoints: &disabled,
+					PodDefaults: &v1beta1.NodePoolPodDefaults{
+						UnreachableTolerationSeconds: &seconds,
+					},
+				},
+			},
+			wantedNodePool: &v1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v1beta1.NodePoolSpec{
+					Type:           v1beta1.Satellite,
+					DeletionPolicy: v1beta1.ForbidIfNonEmpty,
+					Annotations: map[string]string{
+						"node.beta.openyurt.io/autonomy": "false",
+					},
+					Constraints: &v1beta1.NodePoolConstraints{
+						RequirePublicIP: true,
+						Enforcement:     v1beta1.EnforcementFlag,
+					},
+					GatewayEndpoints: &disabled,
+					PodDefaults: &v1beta1.NodePoolPodDefaults{
+						UnreachableTolerationSeconds: &seconds,
+					},
 				},
 				Status: v1beta1.NodePoolStatus{
 					ReadyNodeNum:   0,
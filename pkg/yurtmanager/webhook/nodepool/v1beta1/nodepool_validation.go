@@ -20,11 +20,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -101,15 +105,68 @@ func validateNodePoolSpec(spec *appsv1beta1.NodePoolSpec) field.ErrorList {
 		return allErrs
 	}
 
-	// NodePool type should be Edge or Cloud
-	if spec.Type != appsv1beta1.Edge && spec.Type != appsv1beta1.Cloud {
-		return []*field.Error{field.Invalid(field.NewPath("spec").Child("type"), spec.Type, "pool type should be Edge or Cloud")}
+	// NodePool type should be Edge, Cloud or Satellite
+	if spec.Type != appsv1beta1.Edge && spec.Type != appsv1beta1.Cloud && spec.Type != appsv1beta1.Satellite {
+		return []*field.Error{field.Invalid(field.NewPath("spec").Child("type"), spec.Type, "pool type should be Edge, Cloud or Satellite")}
 	}
 
 	// Cloud NodePool can not set HostNetwork=true
 	if spec.Type == appsv1beta1.Cloud && spec.HostNetwork {
 		return []*field.Error{field.Invalid(field.NewPath("spec").Child("hostNetwork"), spec.HostNetwork, "Cloud NodePool cloud not support hostNetwork")}
 	}
+
+	// Gateway should be a valid name of raven Gateway
+	if len(spec.Gateway) != 0 {
+		if errs := apivalidation.NameIsDNSSubdomain(spec.Gateway, false); len(errs) != 0 {
+			return []*field.Error{field.Invalid(field.NewPath("spec").Child("gateway"), spec.Gateway, strings.Join(errs, ", "))}
+		}
+	}
+
+	// topology should be valid label values
+	if topology := spec.Topology; topology != nil {
+		fldPath := field.NewPath("spec").Child("topology")
+		for name, value := range map[string]string{"region": topology.Region, "zone": topology.Zone, "site": topology.Site} {
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				return []*field.Error{field.Invalid(fldPath.Child(name), value, strings.Join(errs, ", "))}
+			}
+		}
+	}
+
+	// time zone should be a valid IANA time zone
+	if len(spec.TimeZone) != 0 {
+		if _, err := time.LoadLocation(spec.TimeZone); err != nil {
+			return []*field.Error{field.Invalid(field.NewPath("spec").Child("timeZone"), spec.TimeZone, err.Error())}
+		}
+	}
+
+	// every registry mirror should specify the registry and endpoints
+	if spec.NodeConfig != nil {
+		for i, mirror := range spec.NodeConfig.RegistryMirrors {
+			fldPath := field.NewPath("spec").Child("nodeConfig").Child("registryMirrors").Index(i)
+			if len(strings.TrimSpace(mirror.Registry)) == 0 {
+				return []*field.Error{field.Required(fldPath.Child("registry"), "registry of mirror should be specified")}
+			}
+			if len(mirror.Endpoints) == 0 {
+				return []*field.Error{field.Required(fldPath.Child("endpoints"), "at least one endpoint of mirror should be specified")}
+			}
+		}
+	}
+
+	// DisruptionBudget should select pods by a valid selector
+	if spec.DisruptionBudget != nil {
+		fldPath := field.NewPath("spec").Child("disruptionBudget")
+		if spec.DisruptionBudget.Selector == nil {
+			return []*field.Error{field.Required(fldPath.Child("selector"), "selector of disruption budget should be specified")}
+		}
+		if _, err := metav1.LabelSelectorAsSelector(spec.DisruptionBudget.Selector); err != nil {
+			return []*field.Error{field.Invalid(fldPath.Child("selector"), spec.DisruptionBudget.Selector, err.Error())}
+		}
+	}
+
+	// MaxSize should not be less than DesiredSize
+	if spec.DesiredSize != nil && spec.MaxSize != nil && *spec.MaxSize < *spec.DesiredSize {
+		return []*field.Error{field.Invalid(field.NewPath("spec").Child("maxSize"), *spec.MaxSize, "maxSize should not be less than desiredSize")}
+	}
 	return nil
 }
 
@@ -135,6 +192,11 @@ func validateNodePoolSpecUpdate(spec, oldSpec *appsv1beta1.NodePoolSpec) field.E
 // validateNodePoolDeletion validate the nodepool deletion event, which prevents
 // the default-nodepool from being deleted
 func validateNodePoolDeletion(cli client.Client, np *appsv1beta1.NodePool) field.ErrorList {
+	// nodes will be detached from the pool by nodepool controller
+	if np.Spec.DeletionPolicy == appsv1beta1.Orphan || np.Spec.DeletionPolicy == appsv1beta1.Evacuate {
+		return nil
+	}
+
 	nodes := corev1.NodeList{}
 
 	if err := cli.List(context.TODO(), &nodes, client.MatchingLabels(map[string]string{apps.NodePoolLabel: np.Name})); err != nil {
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -47,6 +48,14 @@ func TestValidateCreate(t *testing.T) {
 			},
 			errcode: 0,
 		},
+		"it is a satellite nodepool": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type: appsv1beta1.Satellite,
+				},
+			},
+			errcode: 0,
+		},
 		"it is not a nodepool": {
 			pool:    &corev1.Node{},
 			errcode: http.StatusBadRequest,
@@ -70,6 +79,67 @@ func TestValidateCreate(t *testing.T) {
 			},
 			errcode: http.StatusUnprocessableEntity,
 		},
+		"invalid gateway name": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:    appsv1beta1.Edge,
+					Gateway: "Invalid_Gateway",
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
+		"max size is less than desired size": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:        appsv1beta1.Edge,
+					DesiredSize: pointer.Int32(3),
+					MaxSize:     pointer.Int32(2),
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
+		"disruption budget without selector": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:             appsv1beta1.Edge,
+					DisruptionBudget: &appsv1beta1.NodePoolDisruptionBudget{},
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
+		"invalid topology site": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type: appsv1beta1.Edge,
+					Topology: &appsv1beta1.NodePoolTopology{
+						Site: "hangzhou factory",
+					},
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
+		"invalid time zone": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type:     appsv1beta1.Edge,
+					TimeZone: "Asia/Nowhere",
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
+		"registry mirror without endpoints": {
+			pool: &appsv1beta1.NodePool{
+				Spec: appsv1beta1.NodePoolSpec{
+					Type: appsv1beta1.Edge,
+					NodeConfig: &appsv1beta1.NodeConfig{
+						RegistryMirrors: []appsv1beta1.RegistryMirror{
+							{Registry: "docker.io"},
+						},
+					},
+				},
+			},
+			errcode: http.StatusUnprocessableEntity,
+		},
 	}
 
 	handler := &NodePoolHandler{}
@@ -260,6 +330,17 @@ func TestValidateDelete(t *testing.T) {
 			},
 			errcode: http.StatusUnprocessableEntity,
 		},
+		"delete a nodepool with node in it and orphan policy": {
+			pool: &appsv1beta1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "hangzhou",
+				},
+				Spec: appsv1beta1.NodePoolSpec{
+					DeletionPolicy: appsv1beta1.Orphan,
+				},
+			},
+			errcode: 0,
+		},
 		"it is not a nodepool": {
 			pool:    &corev1.Node{},
 			errcode: http.StatusBadRequest,
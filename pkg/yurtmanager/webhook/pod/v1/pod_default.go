@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+// defaultTolerationSeconds is the tolerationSeconds of not-ready and unreachable taints
+// that are added by the DefaultTolerationSeconds admission plugin.
+const defaultTolerationSeconds int64 = 300
+
+// Default satisfies the defaulting webhook interface, the default PriorityClass, RuntimeClass and
+// tolerations of NodePool are set for pods which are scheduled into the NodePool.
+func (webhook *PodHandler) Default(ctx context.Context, obj runtime.Object, req admission.Request) error {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Pod but got a %T", obj))
+	}
+
+	npName := selectedNodePool(pod)
+	if len(npName) == 0 {
+		return nil
+	}
+
+	var np appsv1beta1.NodePool
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: npName}, &np); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if np.Spec.PodDefaults == nil {
+		return nil
+	}
+
+	if err := webhook.setDefaultPriorityClass(ctx, pod, np.Spec.PodDefaults.PriorityClassName); err != nil {
+		return err
+	}
+	if err := webhook.setDefaultRuntimeClass(ctx, pod, np.Spec.PodDefaults.RuntimeClassName); err != nil {
+		return err
+	}
+	setDefaultTolerationSeconds(pod, np.Spec.PodDefaults.UnreachableTolerationSeconds)
+	return nil
+}
+
+// setDefaultPriorityClass sets the PriorityClass for pod without PriorityClass. the priority admission
+// plugin has resolved the priority before mutating webhooks, so the priority is resolved here as well.
+func (webhook *PodHandler) setDefaultPriorityClass(ctx context.Context, pod *v1.Pod, className string) error {
+	if len(className) == 0 || len(pod.Spec.PriorityClassName) != 0 {
+		return nil
+	}
+
+	var pc schedulingv1.PriorityClass
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: className}, &pc); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("default PriorityClass %s of pod %s/%s is not found", className, pod.Namespace, pod.Name)
+			return nil
+		}
+		return err
+	}
+
+	pod.Spec.PriorityClassName = className
+	priority := pc.Value
+	pod.Spec.Priority = &priority
+	if pc.PreemptionPolicy != nil {
+		policy := *pc.PreemptionPolicy
+		pod.Spec.PreemptionPolicy = &policy
+	}
+	return nil
+}
+
+// setDefaultRuntimeClass sets the RuntimeClass for pod without RuntimeClass, and the overhead of
+// RuntimeClass is set as the runtimeclass admission plugin does.
+func (webhook *PodHandler) setDefaultRuntimeClass(ctx context.Context, pod *v1.Pod, className string) error {
+	if len(className) == 0 || pod.Spec.RuntimeClassName != nil {
+		return nil
+	}
+
+	var rc nodev1.RuntimeClass
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: className}, &rc); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("default RuntimeClass %s of pod %s/%s is not found", className, pod.Namespace, pod.Name)
+			return nil
+		}
+		return err
+	}
+
+	name := className
+	pod.Spec.RuntimeClassName = &name
+	if rc.Overhead != nil && pod.Spec.Overhead == nil {
+		pod.Spec.Overhead = rc.Overhead.PodFixed.DeepCopy()
+	}
+	return nil
+}
+
+// setDefaultTolerationSeconds sets the tolerationSeconds of not-ready and unreachable taints for pod,
+// the tolerations added by the DefaultTolerationSeconds admission plugin are regarded as unspecified.
+func setDefaultTolerationSeconds(pod *v1.Pod, seconds *int64) {
+	if seconds == nil {
+		return
+	}
+
+	for _, key := range []string{v1.TaintNodeNotReady, v1.TaintNodeUnreachable} {
+		tolerated := false
+		for i := range pod.Spec.Tolerations {
+			t := &pod.Spec.Tolerations[i]
+			if (len(t.Effect) != 0 && t.Effect != v1.TaintEffectNoExecute) ||
+				(t.Key != key && !(len(t.Key) == 0 && t.Operator == v1.TolerationOpExists)) {
+				continue
+			}
+			tolerated = true
+			if t.Key == key && t.TolerationSeconds != nil && *t.TolerationSeconds == defaultTolerationSeconds {
+				s := *seconds
+				t.TolerationSeconds = &s
+			}
+		}
+
+		if !tolerated {
+			s := *seconds
+			pod.Spec.Tolerations = append(pod.Spec.Tolerations, v1.Toleration{
+				Key:               key,
+				Operator:          v1.TolerationOpExists,
+				Effect:            v1.TaintEffectNoExecute,
+				TolerationSeconds: &s,
+			})
+		}
+	}
+}
+
+// selectedNodePool returns the NodePool which the pod is scheduled into. The pod is regarded as
+// scheduled into the NodePool when it selects only one NodePool by nodeSelector or required node affinity.
+func selectedNodePool(pod *v1.Pod) string {
+	if npName, ok := pod.Spec.NodeSelector[apps.NodePoolLabel]; ok {
+		return npName
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+
+	// node selector terms are ORed, so all terms should select the same NodePool
+	var npName string
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		var termPool string
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == apps.NodePoolLabel && expr.Operator == v1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				termPool = expr.Values[0]
+				break
+			}
+		}
+		if len(termPool) == 0 || (len(npName) != 0 && npName != termPool) {
+			return ""
+		}
+		npName = termPool
+	}
+	return npName
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+)
+
+func TestDefault(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	pool := &appsv1beta1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+		Spec: appsv1beta1.NodePoolSpec{
+			Type: appsv1beta1.Edge,
+			PodDefaults: &appsv1beta1.NodePoolPodDefaults{
+				PriorityClassName: "high",
+				RuntimeClassName:  "nvidia",
+			},
+		},
+	}
+	priorityClass := &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      1000,
+	}
+	runtimeClass := &nodev1.RuntimeClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nvidia"},
+		Handler:    "nvidia",
+		Overhead: &nodev1.Overhead{
+			PodFixed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(pool, priorityClass, runtimeClass).Build()
+
+	poolAffinity := func(pools ...string) *corev1.Affinity {
+		var terms []corev1.NodeSelectorTerm
+		for _, pool := range pools {
+			terms = append(terms, corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: apps.NodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{pool}},
+				},
+			})
+		}
+		return &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+			},
+		}
+	}
+
+	testcases := map[string]struct {
+		spec                  corev1.PodSpec
+		expectPriorityClass   string
+		expectRuntimeClass    string
+		expectPriority        int32
+		expectOverheadApplied bool
+	}{
+		"pod selects pool by node selector": {
+			spec: corev1.PodSpec{
+				NodeSelector: map[string]string{apps.NodePoolLabel: "gpu"},
+			},
+			expectPriorityClass:   "high",
+			expectRuntimeClass:    "nvidia",
+			expectPriority:        1000,
+			expectOverheadApplied: true,
+		},
+		"pod selects pool by node affinity": {
+			spec: corev1.PodSpec{
+				Affinity: poolAffinity("gpu", "gpu"),
+			},
+			expectPriorityClass:   "high",
+			expectRuntimeClass:    "nvidia",
+			expectPriority:        1000,
+			expectOverheadApplied: true,
+		},
+		"pod selects multiple pools by node affinity": {
+			spec: corev1.PodSpec{
+				Affinity: poolAffinity("gpu", "hangzhou"),
+			},
+		},
+		"pod specifies classes": {
+			spec: corev1.PodSpec{
+				NodeSelector:      map[string]string{apps.NodePoolLabel: "gpu"},
+				PriorityClassName: "low",
+				RuntimeClassName:  func() *string { s := "runc"; return &s }(),
+			},
+			expectPriorityClass: "low",
+			expectRuntimeClass:  "runc",
+		},
+		"pod doesn't select pool": {
+			spec: corev1.PodSpec{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       tc.spec,
+			}
+			h := &PodHandler{Client: c}
+			if err := h.Default(context.TODO(), pod, admission.Request{}); err != nil {
+				t.Fatalf("expect no error, but got %v", err)
+			}
+
+			if pod.Spec.PriorityClassName != tc.expectPriorityClass {
+				t.Errorf("expect PriorityClass %q, but got %q", tc.expectPriorityClass, pod.Spec.PriorityClassName)
+			}
+			var runtimeClass string
+			if pod.Spec.RuntimeClassName != nil {
+				runtimeClass = *pod.Spec.RuntimeClassName
+			}
+			if runtimeClass != tc.expectRuntimeClass {
+				t.Errorf("expect RuntimeClass %q, but got %q", tc.expectRuntimeClass, runtimeClass)
+			}
+			if tc.expectPriority != 0 && (pod.Spec.Priority == nil || *pod.Spec.Priority != tc.expectPriority) {
+				t.Errorf("expect priority %d, but got %v", tc.expectPriority, pod.Spec.Priority)
+			}
+			if applied := pod.Spec.Overhead != nil; applied != tc.expectOverheadApplied {
+				t.Errorf("expect overhead applied %v, but got %v", tc.expectOverheadApplied, applied)
+			}
+		})
+	}
+}
+
+func TestSetDefaultTolerationSeconds(t *testing.T) {
+	seconds := func(s int64) *int64 {
+		return &s
+	}
+	toleration := func(key string, s *int64) corev1.Toleration {
+		return corev1.Toleration{
+			Key:               key,
+			Operator:          corev1.TolerationOpExists,
+			Effect:            corev1.TaintEffectNoExecute,
+			TolerationSeconds: s,
+		}
+	}
+
+	testcases := map[string]struct {
+		tolerations []corev1.Toleration
+		seconds     *int64
+		expect      []corev1.Toleration
+	}{
+		"no default toleration seconds": {
+			tolerations: []corev1.Toleration{toleration(corev1.TaintNodeNotReady, seconds(300))},
+			expect:      []corev1.Toleration{toleration(corev1.TaintNodeNotReady, seconds(300))},
+		},
+		"pod has no tolerations": {
+			seconds: seconds(3600),
+			expect: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, seconds(3600)),
+				toleration(corev1.TaintNodeUnreachable, seconds(3600)),
+			},
+		},
+		"pod has tolerations set by admission plugin": {
+			tolerations: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, seconds(300)),
+				toleration(corev1.TaintNodeUnreachable, seconds(300)),
+			},
+			seconds: seconds(3600),
+			expect: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, seconds(3600)),
+				toleration(corev1.TaintNodeUnreachable, seconds(3600)),
+			},
+		},
+		"pod specifies toleration seconds": {
+			tolerations: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, seconds(60)),
+				toleration(corev1.TaintNodeUnreachable, nil),
+			},
+			seconds: seconds(3600),
+			expect: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, seconds(60)),
+				toleration(corev1.TaintNodeUnreachable, nil),
+			},
+		},
+		"pod tolerates all taints": {
+			tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			seconds:     seconds(3600),
+			expect:      []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Tolerations: tc.tolerations}}
+			setDefaultTolerationSeconds(pod, tc.seconds)
+			if !reflect.DeepEqual(pod.Spec.Tolerations, tc.expect) {
+				t.Errorf("expect tolerations %v, but got %v", tc.expect, pod.Spec.Tolerations)
+			}
+		})
+	}
+}
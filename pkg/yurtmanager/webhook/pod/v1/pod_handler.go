@@ -17,10 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	"context"
+	"net/http"
+
 	v1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/webhook/builder"
 	"github.com/openyurtio/openyurt/pkg/yurtmanager/webhook/util"
@@ -39,19 +43,80 @@ func (webhook *PodHandler) SetupWebhookWithManager(mgr ctrl.Manager) (string, st
 	if err != nil {
 		return "", "", err
 	}
+
+	// pods of namespace in the NodePool are listed by index for checking NodePoolQuota
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &v1.Pod{}, podQuotaScopeIndex, indexPodQuotaScope); err != nil {
+		return "", "", err
+	}
+
+	// the bindings of pods are validated in the same path of pods, so the validating
+	// webhook of pods is registered with a handler which dispatches the bindings.
+	validatePath := util.GenerateValidatePath(gvk)
+	mgr.GetWebhookServer().Register(validatePath, &admission.Webhook{
+		Handler: &podValidatingHandler{
+			webhook: webhook,
+			pods:    builder.WithCustomValidator(&v1.Pod{}, webhook).Handler,
+		},
+	})
+
 	return util.GenerateMutatePath(gvk),
-		util.GenerateValidatePath(gvk),
+		validatePath,
 		builder.WebhookManagedBy(mgr).
 			For(&v1.Pod{}).
+			WithDefaulter(webhook).
 			WithValidator(webhook).
 			Complete()
 }
 
 // +kubebuilder:webhook:path=/validate-core-openyurt-io-v1-pod,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups="",resources=pods,verbs=delete,versions=v1,name=validate.core.v1.pod.openyurt.io
+// +kubebuilder:webhook:path=/validate-core-openyurt-io-v1-pod,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups="",resources=pods;pods/binding,verbs=create,versions=v1,name=validate.quota.core.v1.pod.openyurt.io
+// +kubebuilder:webhook:path=/mutate-core-openyurt-io-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups="",resources=pods,verbs=create,versions=v1,name=mutate.core.v1.pod.openyurt.io
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepoolquotas,verbs=get;list;watch
 
 // Cluster implements a validating and defaulting webhook for PodHandler.
 type PodHandler struct {
 	Client client.Client
 }
 
+var _ builder.CustomDefaulter = &PodHandler{}
 var _ builder.CustomValidator = &PodHandler{}
+
+// podValidatingHandler validates the bindings of pods against NodePoolQuotas, and the other
+// requests of pods are handled by the validator of PodHandler.
+type podValidatingHandler struct {
+	webhook *PodHandler
+	pods    admission.Handler
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &podValidatingHandler{}
+
+// InjectDecoder injects the decoder into the handler and the validator of pods.
+func (h *podValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	if injector, ok := h.pods.(admission.DecoderInjector); ok {
+		return injector.InjectDecoder(d)
+	}
+	return nil
+}
+
+// Handle handles admission requests.
+func (h *podValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.SubResource != "binding" {
+		return h.pods.Handle(ctx, req)
+	}
+
+	binding := &v1.Binding{}
+	if err := h.decoder.Decode(req, binding); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if len(binding.Namespace) == 0 {
+		binding.Namespace = req.Namespace
+	}
+	if allErrs := h.webhook.validateBindingQuota(ctx, binding); len(allErrs) > 0 {
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
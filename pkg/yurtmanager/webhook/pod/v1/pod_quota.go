@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+const (
+	quotaRequestsPrefix = "requests."
+	quotaLimitsPrefix   = "limits."
+
+	// podQuotaScopeIndex indexes pods by the node or NodePool which the pods consume resources of.
+	podQuotaScopeIndex = "spec.nodeName.quotaScope"
+)
+
+// validatePodQuota rejects the pod if the total requests or limits of pods in the NodePool
+// exceed the NodePoolQuotas of the namespace after the pod is created or bound to a node.
+func (webhook *PodHandler) validatePodQuota(ctx context.Context, pod *v1.Pod) field.ErrorList {
+	npName, err := webhook.podNodePool(ctx, pod)
+	if err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("spec").Child("nodeName"), err)}
+	}
+	if len(npName) == 0 {
+		return nil
+	}
+
+	var quotaList appsv1alpha1.NodePoolQuotaList
+	if err := webhook.Client.List(ctx, &quotaList, client.InNamespace(pod.Namespace)); err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), err)}
+	}
+
+	var pods []*v1.Pod
+	for i := range quotaList.Items {
+		quota := &quotaList.Items[i]
+		if quota.Spec.NodePool != npName {
+			continue
+		}
+		selector, err := quotaSelector(quota)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		if pods == nil {
+			if pods, err = webhook.nodePoolPods(ctx, pod.Namespace, npName); err != nil {
+				return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("namespace"), err)}
+			}
+		}
+
+		used := make(v1.ResourceList)
+		for _, p := range pods {
+			if !isPodCounted(p) || p.Name == pod.Name || !selector.Matches(labels.Set(p.Labels)) {
+				continue
+			}
+			addQuotaUsage(used, p)
+		}
+
+		requested := make(v1.ResourceList)
+		addQuotaUsage(requested, pod)
+		if exceeded := exceededResources(quota.Spec.Hard, used, requested); len(exceeded) != 0 {
+			return field.ErrorList{field.Forbidden(field.NewPath("spec"),
+				fmt.Sprintf("exceeded NodePoolQuota %s of NodePool %s: %s", quota.Name, npName, strings.Join(exceeded, ", ")))}
+		}
+	}
+	return nil
+}
+
+// validateBindingQuota rejects the binding if the pod exceeds the NodePoolQuotas of the NodePool
+// which the pod is bound to, so the pods which are not pinned to the NodePool are limited too.
+func (webhook *PodHandler) validateBindingQuota(ctx context.Context, binding *v1.Binding) field.ErrorList {
+	var pod v1.Pod
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name}, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			// the binding is rejected by the apiserver if the pod does not exist.
+			return nil
+		}
+		return field.ErrorList{field.InternalError(field.NewPath("metadata").Child("name"), err)}
+	}
+	pod.Spec.NodeName = binding.Target.Name
+	return webhook.validatePodQuota(ctx, &pod)
+}
+
+// nodePoolPods returns the pods of namespace in the NodePool, including the pods scheduled on the nodes
+// of NodePool and the pods pinned to the NodePool which are not scheduled. the pods are listed by index.
+func (webhook *PodHandler) nodePoolPods(ctx context.Context, namespace, npName string) ([]*v1.Pod, error) {
+	var nodeList v1.NodeList
+	if err := webhook.Client.List(ctx, &nodeList, client.MatchingLabels{apps.NodePoolLabel: npName}); err != nil {
+		return nil, err
+	}
+	keys := []string{poolQuotaScope(npName)}
+	for i := range nodeList.Items {
+		keys = append(keys, nodeQuotaScope(nodeList.Items[i].Name))
+	}
+
+	listed := sets.NewString()
+	var pods []*v1.Pod
+	for _, key := range keys {
+		var podList v1.PodList
+		if err := webhook.Client.List(ctx, &podList, client.InNamespace(namespace), client.MatchingFields{podQuotaScopeIndex: key}); err != nil {
+			return nil, err
+		}
+		for i := range podList.Items {
+			p := &podList.Items[i]
+			// the scope of pod is checked again, because the index may be not supported by the client.
+			if scopes := indexPodQuotaScope(p); len(scopes) == 0 || scopes[0] != key || listed.Has(p.Name) {
+				continue
+			}
+			listed.Insert(p.Name)
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+// indexPodQuotaScope returns the scope of pod for counting the usage of NodePoolQuota, the scope is
+// the node for scheduled pods, and the NodePool selected by the pod for pods which are not scheduled.
+func indexPodQuotaScope(obj client.Object) []string {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return []string{}
+	}
+	if len(pod.Spec.NodeName) != 0 {
+		return []string{nodeQuotaScope(pod.Spec.NodeName)}
+	}
+	if pool := selectedNodePool(pod); len(pool) != 0 {
+		return []string{poolQuotaScope(pool)}
+	}
+	return []string{}
+}
+
+func nodeQuotaScope(nodeName string) string {
+	return "node/" + nodeName
+}
+
+func poolQuotaScope(poolName string) string {
+	return "nodepool/" + poolName
+}
+
+// podNodePool returns the NodePool of pod, the pool of node is used if the pod has been scheduled,
+// otherwise the pool selected by the pod is used.
+func (webhook *PodHandler) podNodePool(ctx context.Context, pod *v1.Pod) (string, error) {
+	if len(pod.Spec.NodeName) == 0 {
+		return selectedNodePool(pod), nil
+	}
+
+	var node v1.Node
+	if err := webhook.Client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return node.Labels[apps.NodePoolLabel], nil
+}
+
+func quotaSelector(quota *appsv1alpha1.NodePoolQuota) (labels.Selector, error) {
+	if quota.Spec.Selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(quota.Spec.Selector)
+}
+
+// isPodCounted checks whether the pod consumes resources of NodePool.
+func isPodCounted(pod *v1.Pod) bool {
+	return pod.DeletionTimestamp == nil && pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+}
+
+// addQuotaUsage adds the requests and limits of pod into usage with the quota resource names.
+func addQuotaUsage(usage v1.ResourceList, pod *v1.Pod) {
+	reqs, limits := resourcehelper.PodRequestsAndLimits(pod)
+	for name, quantity := range reqs {
+		key := v1.ResourceName(quotaRequestsPrefix + string(name))
+		total := usage[key]
+		total.Add(quantity)
+		usage[key] = total
+	}
+	for name, quantity := range limits {
+		key := v1.ResourceName(quotaLimitsPrefix + string(name))
+		total := usage[key]
+		total.Add(quantity)
+		usage[key] = total
+	}
+}
+
+// exceededResources returns the descriptions of resources which exceed the hard limits.
+func exceededResources(hard, used, requested v1.ResourceList) []string {
+	var exceeded []string
+	for name, limit := range hard {
+		request, ok := requested[name]
+		if !ok || request.IsZero() {
+			continue
+		}
+		total := used[name]
+		total.Add(request)
+		if total.Cmp(limit) > 0 {
+			current := used[name]
+			exceeded = append(exceeded, fmt.Sprintf("requested %s=%s, used %s=%s, limited %s=%s",
+				name, request.String(), name, current.String(), name, limit.String()))
+		}
+	}
+	return exceeded
+}
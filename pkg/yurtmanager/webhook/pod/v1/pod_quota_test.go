@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openyurtio/openyurt/pkg/apis"
+	"github.com/openyurtio/openyurt/pkg/apis/apps"
+	appsv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+)
+
+func TestValidatePodQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal("Fail to add kubernetes clint-go custom resource")
+	}
+	apis.AddToScheme(scheme)
+
+	newPod := func(name, nodeName, pool, cpu string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "team-a",
+				Labels:    map[string]string{"app": "nginx"},
+			},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{
+					{
+						Name: "nginx",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+						},
+					},
+				},
+			},
+		}
+		if len(pool) != 0 {
+			pod.Spec.NodeSelector = map[string]string{apps.NodePoolLabel: pool}
+		}
+		return pod
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node1",
+			Labels: map[string]string{apps.NodePoolLabel: "hangzhou"},
+		},
+	}
+	quota := &appsv1alpha1.NodePoolQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+		Spec: appsv1alpha1.NodePoolQuotaSpec{
+			NodePool: "hangzhou",
+			Hard:     corev1.ResourceList{"requests.cpu": resource.MustParse("2")},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(node, quota, newPod("running", "node1", "", "1")).Build()
+
+	testcases := map[string]struct {
+		pod   *corev1.Pod
+		isErr bool
+	}{
+		"pod within quota": {
+			pod: newPod("test", "", "hangzhou", "1"),
+		},
+		"pod exceeds quota": {
+			pod:   newPod("test", "", "hangzhou", "1500m"),
+			isErr: true,
+		},
+		"scheduled pod exceeds quota": {
+			pod:   newPod("test", "node1", "", "2"),
+			isErr: true,
+		},
+		"pod in another pool": {
+			pod: newPod("test", "", "shanghai", "4"),
+		},
+		"pod without pool": {
+			pod: newPod("test", "", "", "4"),
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			h := &PodHandler{Client: c}
+			err := h.ValidateCreate(context.TODO(), tc.pod, admission.Request{})
+			if tc.isErr != (err != nil) {
+				t.Errorf("expect error %v, but got %v", tc.isErr, err)
+			}
+		})
+	}
+
+	// the pod which is not pinned to the nodepool is checked when it's bound to the node of nodepool
+	unpinned := newPod("unpinned", "", "", "2")
+	if err := c.Create(context.TODO(), unpinned); err != nil {
+		t.Fatalf("could not create pod, %v", err)
+	}
+	h := &PodHandler{Client: c}
+	binding := &corev1.Binding{
+		ObjectMeta: metav1.ObjectMeta{Name: "unpinned", Namespace: "team-a"},
+		Target:     corev1.ObjectReference{Kind: "Node", Name: "node1"},
+	}
+	if errs := h.validateBindingQuota(context.TODO(), binding); len(errs) == 0 {
+		t.Errorf("expect binding exceeds quota")
+	}
+	binding.Target.Name = "node2"
+	if errs := h.validateBindingQuota(context.TODO(), binding); len(errs) != 0 {
+		t.Errorf("expect binding to node out of nodepool is allowed, but got %v", errs)
+	}
+
+	// the pod is rejected if the nodepool of pod can not be resolved
+	h = &PodHandler{Client: fakeclient.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()}
+	if errs := h.validatePodQuota(context.TODO(), newPod("test", "node1", "", "1")); len(errs) == 0 {
+		t.Errorf("expect pod is rejected when node lookup fails")
+	}
+	if errs := h.validateBindingQuota(context.TODO(), binding); len(errs) == 0 {
+		t.Errorf("expect binding is rejected when pod lookup fails")
+	}
+}
+
+func TestIndexPodQuotaScope(t *testing.T) {
+	testcases := map[string]struct {
+		pod    *corev1.Pod
+		expect []string
+	}{
+		"scheduled pod": {
+			pod:    &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node1", NodeSelector: map[string]string{apps.NodePoolLabel: "hangzhou"}}},
+			expect: []string{"node/node1"},
+		},
+		"pod pinned to nodepool": {
+			pod:    &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{apps.NodePoolLabel: "hangzhou"}}},
+			expect: []string{"nodepool/hangzhou"},
+		},
+		"pod not pinned to nodepool": {
+			pod:    &corev1.Pod{},
+			expect: []string{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := indexPodQuotaScope(tc.pod); !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect %v, but got %v", tc.expect, got)
+			}
+		})
+	}
+}
@@ -44,6 +44,14 @@ const (
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
 func (webhook *PodHandler) ValidateCreate(ctx context.Context, obj runtime.Object, req admission.Request) error {
+	po, ok := obj.(*v1.Pod)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Pod but got a %T", obj))
+	}
+
+	if allErrs := webhook.validatePodQuota(ctx, po); len(allErrs) > 0 {
+		return apierrors.NewInvalid(v1.SchemeGroupVersion.WithKind("Pod").GroupKind(), po.Name, allErrs)
+	}
 	return nil
 }
 
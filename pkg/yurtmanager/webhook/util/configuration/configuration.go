@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"time"
 
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +30,7 @@ import (
 	"k8s.io/klog/v2"
 
 	webhookutil "github.com/openyurtio/openyurt/pkg/yurtmanager/webhook/util"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/webhook/util/generator"
 )
 
 func Ensure(kubeClient clientset.Interface, handlers map[string]struct{}, caBundle []byte, webhookPort int) error {
@@ -43,6 +45,17 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]struct{}, caBund
 	oldMutatingConfig := mutatingConfig.DeepCopy()
 	oldValidatingConfig := validatingConfig.DeepCopy()
 
+	// the previous CA bundles are merged into the new ones, so the webhooks are not rejected
+	// when the serving certificate is not reloaded yet during the rotation of CA.
+	now := time.Now()
+	previousBundles := make(map[string][]byte, len(mutatingConfig.Webhooks)+len(validatingConfig.Webhooks))
+	for i := range mutatingConfig.Webhooks {
+		previousBundles["mutating/"+mutatingConfig.Webhooks[i].Name] = mutatingConfig.Webhooks[i].ClientConfig.CABundle
+	}
+	for i := range validatingConfig.Webhooks {
+		previousBundles["validating/"+validatingConfig.Webhooks[i].Name] = validatingConfig.Webhooks[i].ClientConfig.CABundle
+	}
+
 	mutatingTemplate, err := parseMutatingTemplate(mutatingConfig)
 	if err != nil {
 		return err
@@ -55,7 +68,7 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]struct{}, caBund
 	var mutatingWHs []admissionregistrationv1.MutatingWebhook
 	for i := range mutatingTemplate {
 		wh := &mutatingTemplate[i]
-		wh.ClientConfig.CABundle = caBundle
+		wh.ClientConfig.CABundle = generator.MergeCABundle(caBundle, previousBundles["mutating/"+wh.Name], now)
 		path, err := getPath(&wh.ClientConfig)
 		if err != nil {
 			return err
@@ -80,7 +93,7 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]struct{}, caBund
 	var validatingWHs []admissionregistrationv1.ValidatingWebhook
 	for i := range validatingTemplate {
 		wh := &validatingTemplate[i]
-		wh.ClientConfig.CABundle = caBundle
+		wh.ClientConfig.CABundle = generator.MergeCABundle(caBundle, previousBundles["validating/"+wh.Name], now)
 		path, err := getPath(&wh.ClientConfig)
 		if err != nil {
 			return err
@@ -30,6 +30,7 @@ import (
 	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
 	apiextensionslister "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -136,6 +137,22 @@ func New(handlers map[string]struct{}, cc *config.CompletedConfig, restCfg *rest
 				c.queue.Add("")
 			}
 		},
+		DeleteFunc: func(obj interface{}) {
+			secret, ok := obj.(*v1.Secret)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if secret, ok = tombstone.Obj.(*v1.Secret); !ok {
+					return
+				}
+			}
+			if secret.Name == secretName {
+				klog.Infof("Secret %s deleted", secretName)
+				c.queue.Add("")
+			}
+		},
 	})
 
 	admissionRegistrationInformer.MutatingWebhookConfigurations().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -258,27 +275,45 @@ func (c *Controller) sync(key string) error {
 	if err != nil {
 		return fmt.Errorf("failed to ensure certs: %v", err)
 	}
-	if err := writer.WriteCertsToDir(webhookutil.GetCertDir(), certs); err != nil {
-		return fmt.Errorf("failed to write certs to dir: %v", err)
-	}
 
 	if err := configuration.Ensure(c.kubeClient, c.handlers, certs.CACert, c.webhookPort); err != nil {
 		return fmt.Errorf("failed to ensure configuration: %v", err)
 	}
 
+	// the CA of all conversion webhooks are refreshed when the certs are synced, so the rotated
+	// certs take effect on the conversion of crds without waiting for the events of crds.
+	var crds []*apiextensionsv1.CustomResourceDefinition
 	if len(key) != 0 {
 		crd, err := c.extensionsLister.Get(key)
 		if err != nil {
 			klog.Errorf("failed to get crd(%s), %v", key, err)
 			return err
 		}
+		crds = append(crds, crd)
+	} else {
+		crds, err = c.extensionsLister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("failed to list crds, %v", err)
+			return err
+		}
+	}
 
-		if err := ensureCRDConversionCA(c.extensionsClient, crd, certs.CACert); err != nil {
+	for _, crd := range crds {
+		if !crdHasWebhookConversion(crd) {
+			continue
+		}
+		if err := ensureCRDConversionCA(c.extensionsClient, crd.DeepCopy(), certs.CACert); err != nil {
 			klog.Errorf("failed to ensure conversion configuration for crd(%s), %v", crd.Name, err)
 			return err
 		}
 	}
 
+	// the certs are written to dir after the CA bundles are updated, so the serving certificate
+	// reloaded by the webhook server is always trusted by the CA bundles.
+	if err := writer.WriteCertsToDir(webhookutil.GetCertDir(), certs); err != nil {
+		return fmt.Errorf("failed to write certs to dir: %v", err)
+	}
+
 	onceInit.Do(func() {
 		close(uninit)
 	})
@@ -291,11 +326,15 @@ func crdHasWebhookConversion(crd *apiextensionsv1.CustomResourceDefinition) bool
 		return false
 	}
 
-	if conversion.Strategy == apiextensionsv1.WebhookConverter {
-		return true
+	if conversion.Strategy != apiextensionsv1.WebhookConverter {
+		return false
 	}
 
-	return false
+	// only the conversion webhooks served by yurt-manager are managed
+	if conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil || conversion.Webhook.ClientConfig.Service == nil {
+		return false
+	}
+	return conversion.Webhook.ClientConfig.Service.Name == webhookutil.GetServiceName()
 }
 
 func ensureCRDConversionCA(client apiextensionsclientset.Interface, crd *apiextensionsv1.CustomResourceDefinition, newCABundle []byte) error {
@@ -305,11 +344,12 @@ func ensureCRDConversionCA(client apiextensionsclientset.Interface, crd *apiexte
 		return nil
 	}
 
-	if bytes.Equal(crd.Spec.Conversion.Webhook.ClientConfig.CABundle, newCABundle) {
+	caBundle := generator.MergeCABundle(newCABundle, crd.Spec.Conversion.Webhook.ClientConfig.CABundle, time.Now())
+	if bytes.Equal(crd.Spec.Conversion.Webhook.ClientConfig.CABundle, caBundle) {
 		return nil
 	}
 
-	crd.Spec.Conversion.Webhook.ClientConfig.CABundle = newCABundle
+	crd.Spec.Conversion.Webhook.ClientConfig.CABundle = caBundle
 	// update crd
 	_, err := client.ApiextensionsV1().CustomResourceDefinitions().Update(context.TODO(), crd, metav1.UpdateOptions{})
 	return err
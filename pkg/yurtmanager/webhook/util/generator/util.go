@@ -21,8 +21,15 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"time"
+
+	"k8s.io/client-go/util/cert"
 )
 
+// CARotationOverlap is the period that the previous CA certificates are kept in the CA bundle
+// after a new CA certificate is issued, so the serving certificates signed by the previous CA
+// are still trusted before all of the webhook servers reload the new serving certificate.
+const CARotationOverlap = time.Hour
+
 // ValidCACert treats cert and key are valid if they meet the following requirements:
 // - key and cert are valid pair
 // - caCert is the root ca of cert
@@ -59,3 +66,34 @@ func ValidCACert(key, cert, caCert []byte, dnsName string, time time.Time) bool
 	_, err = c.Verify(ops)
 	return err == nil
 }
+
+// MergeCABundle returns the CA bundle for caCert. the unexpired CA certificates in previousBundle
+// are appended to the bundle when caCert is issued within CARotationOverlap, and are dropped after then.
+func MergeCABundle(caCert, previousBundle []byte, now time.Time) []byte {
+	current, err := cert.ParseCertsPEM(caCert)
+	if err != nil || now.After(current[0].NotBefore.Add(CARotationOverlap)) {
+		return caCert
+	}
+	previous, err := cert.ParseCertsPEM(previousBundle)
+	if err != nil {
+		return caCert
+	}
+
+	bundle := append([]byte{}, caCert...)
+	for i, c := range previous {
+		if now.After(c.NotAfter) || containsCert(current, c) || containsCert(previous[:i], c) {
+			continue
+		}
+		bundle = append(bundle, EncodeCertPEM(c)...)
+	}
+	return bundle
+}
+
+func containsCert(certs []*x509.Certificate, c *x509.Certificate) bool {
+	for i := range certs {
+		if certs[i].Equal(c) {
+			return true
+		}
+	}
+	return false
+}
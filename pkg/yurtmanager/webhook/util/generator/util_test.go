@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/cert"
+)
+
+func newCACert(t *testing.T, name string) []byte {
+	key, err := NewPrivateKey()
+	if err != nil {
+		t.Fatalf("could not create private key, %v", err)
+	}
+	caCert, err := cert.NewSelfSignedCACert(cert.Config{CommonName: name}, key)
+	if err != nil {
+		t.Fatalf("could not create ca cert, %v", err)
+	}
+	return EncodeCertPEM(caCert)
+}
+
+func TestMergeCABundle(t *testing.T) {
+	oldCA := newCACert(t, "old-ca")
+	newCA := newCACert(t, "new-ca")
+	now := time.Now()
+
+	testcases := map[string]struct {
+		caCert         []byte
+		previousBundle []byte
+		now            time.Time
+		expect         []byte
+	}{
+		"no previous bundle": {
+			caCert: newCA,
+			now:    now,
+			expect: newCA,
+		},
+		"ca is not changed": {
+			caCert:         newCA,
+			previousBundle: newCA,
+			now:            now,
+			expect:         newCA,
+		},
+		"previous ca is kept during rotation": {
+			caCert:         newCA,
+			previousBundle: oldCA,
+			now:            now,
+			expect:         append(append([]byte{}, newCA...), oldCA...),
+		},
+		"merged bundle is stable during rotation": {
+			caCert:         newCA,
+			previousBundle: append(append([]byte{}, newCA...), oldCA...),
+			now:            now,
+			expect:         append(append([]byte{}, newCA...), oldCA...),
+		},
+		"previous ca is dropped after rotation": {
+			caCert:         newCA,
+			previousBundle: append(append([]byte{}, newCA...), oldCA...),
+			now:            now.Add(CARotationOverlap + time.Minute),
+			expect:         newCA,
+		},
+		"invalid previous bundle": {
+			caCert:         newCA,
+			previousBundle: []byte("invalid"),
+			now:            now,
+			expect:         newCA,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := MergeCABundle(tc.caCert, tc.previousBundle, tc.now); !bytes.Equal(got, tc.expect) {
+				t.Errorf("expect bundle %s, but got %s", tc.expect, got)
+			}
+		})
+	}
+}
@@ -29,6 +29,8 @@ import (
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
 	"k8s.io/kubernetes/pkg/apis/core"
@@ -108,6 +110,32 @@ func validateYurtAppDaemonSpec(c client.Client, spec *v1alpha1.YurtAppDaemonSpec
 		allErrs = append(allErrs, validateWorkLoadTemplate(&(spec.WorkloadTemplate), selector, fldPath.Child("template"))...)
 	}
 
+	if spec.UpdateStrategy != nil {
+		allErrs = append(allErrs, validateUpdateStrategy(spec.UpdateStrategy, fldPath.Child("updateStrategy"))...)
+	}
+
+	return allErrs
+}
+
+// validateUpdateStrategy checks maxUnavailable is a positive number or percentage,
+// and each nodepool is listed only once in the update order.
+func validateUpdateStrategy(strategy *v1alpha1.YurtAppDaemonUpdateStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if strategy.MaxUnavailable != nil {
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*strategy.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*strategy.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+		if value, err := intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, 100, true); err == nil && value == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), strategy.MaxUnavailable.String(), "must be greater than 0"))
+		}
+	}
+
+	nodePools := sets.String{}
+	for i, np := range strategy.NodePools {
+		if nodePools.Has(np) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("nodepools").Index(i), np))
+		}
+		nodePools.Insert(np)
+	}
 	return allErrs
 }
 
@@ -122,11 +150,17 @@ func validateWorkLoadTemplate(template *v1alpha1.WorkloadTemplate, selector labe
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.JobTemplate != nil {
+		templateCount++
+	}
+	if template.CronJobTemplate != nil {
+		templateCount++
+	}
 
 	if templateCount < 1 {
-		allErrs = append(allErrs, field.Required(fldPath, "should provide one of (statefulSetTemplate/deploymentTemplate)"))
+		allErrs = append(allErrs, field.Required(fldPath, "should provide one of (statefulSetTemplate/deploymentTemplate/jobTemplate/cronJobTemplate)"))
 	} else if templateCount > 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of (statefulSetTemplate/deploymentTemplate)"))
+		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of (statefulSetTemplate/deploymentTemplate/jobTemplate/cronJobTemplate)"))
 	}
 
 	if template.StatefulSetTemplate != nil {
@@ -162,6 +196,46 @@ func validateWorkLoadTemplate(template *v1alpha1.WorkloadTemplate, selector labe
 			fldPath.Child("deploymentTemplate", "spec", "template"), apivalidation.PodValidationOptions{})...)
 	}
 
+	if template.JobTemplate != nil {
+		labels := labels.Set(template.JobTemplate.Labels)
+		if !selector.Matches(labels) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("jobTemplate", "metadata", "labels"),
+				template.JobTemplate.Labels, "`selector` does not match template `labels`"))
+		}
+		allErrs = append(allErrs, validateJobPodTemplateSpec(&template.JobTemplate.Spec.Template,
+			fldPath.Child("jobTemplate", "spec", "template"))...)
+	}
+
+	if template.CronJobTemplate != nil {
+		labels := labels.Set(template.CronJobTemplate.Labels)
+		if !selector.Matches(labels) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cronJobTemplate", "metadata", "labels"),
+				template.CronJobTemplate.Labels, "`selector` does not match template `labels`"))
+		}
+		if len(template.CronJobTemplate.Spec.Schedule) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("cronJobTemplate", "spec", "schedule"), ""))
+		}
+		allErrs = append(allErrs, validateJobPodTemplateSpec(&template.CronJobTemplate.Spec.JobTemplate.Spec.Template,
+			fldPath.Child("cronJobTemplate", "spec", "jobTemplate", "spec", "template"))...)
+	}
+
+	return allErrs
+}
+
+// validateJobPodTemplateSpec validates the pod template of job, only OnFailure and Never
+// restartPolicy are supported by job.
+func validateJobPodTemplateSpec(template *v1.PodTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	coreTemplate, err := convertPodTemplateSpec(template)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, template, fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
+		return allErrs
+	}
+	allErrs = append(allErrs, apivalidation.ValidatePodTemplateSpec(coreTemplate, fldPath, apivalidation.PodValidationOptions{})...)
+	if coreTemplate.Spec.RestartPolicy != core.RestartPolicyOnFailure && coreTemplate.Spec.RestartPolicy != core.RestartPolicyNever {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("spec", "restartPolicy"), coreTemplate.Spec.RestartPolicy,
+			[]string{string(core.RestartPolicyOnFailure), string(core.RestartPolicyNever)}))
+	}
 	return allErrs
 }
 
@@ -18,9 +18,13 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,7 +43,13 @@ func (webhook *YurtAppOverriderHandler) ValidateCreate(ctx context.Context, obj
 	if err := webhook.validateOneToOneBinding(ctx, overrider); err != nil {
 		return err
 	}
-	return nil
+	if err := validateEntryPools(overrider); err != nil {
+		return err
+	}
+	if err := validateImageRegistries(overrider); err != nil {
+		return err
+	}
+	return validateJSONPatch(overrider)
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
@@ -62,7 +72,13 @@ func (webhook *YurtAppOverriderHandler) ValidateUpdate(ctx context.Context, oldO
 	if err := webhook.validateOneToOneBinding(ctx, newOverrider); err != nil {
 		return err
 	}
-	return nil
+	if err := validateEntryPools(newOverrider); err != nil {
+		return err
+	}
+	if err := validateImageRegistries(newOverrider); err != nil {
+		return err
+	}
+	return validateJSONPatch(newOverrider)
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
@@ -109,3 +125,54 @@ func (webhook *YurtAppOverriderHandler) validateOneToOneBinding(ctx context.Cont
 	}
 	return nil
 }
+
+// validateEntryPools checks each entry targets pools by names or a valid pool selector
+func validateEntryPools(app *v1alpha1.YurtAppOverrider) error {
+	for i, entry := range app.Entries {
+		if len(entry.Pools) == 0 && entry.PoolSelector == nil {
+			return fmt.Errorf("either pools or poolSelector should be specified in entry %d", i)
+		}
+		if entry.PoolSelector == nil {
+			continue
+		}
+		if _, err := metav1.LabelSelectorAsSelector(entry.PoolSelector); err != nil {
+			return fmt.Errorf("invalid pool selector of entry %d, %v", i, err)
+		}
+	}
+	return nil
+}
+
+// validateImageRegistries checks the image registry items of each entry rewrite images to a registry
+func validateImageRegistries(app *v1alpha1.YurtAppOverrider) error {
+	for i, entry := range app.Entries {
+		for j, item := range entry.Items {
+			if item.ImageRegistry == nil {
+				continue
+			}
+			if item.Image != nil || item.Replicas != nil {
+				return fmt.Errorf("only one of image, imageRegistry and replicas can be specified in item %d of entry %d", j, i)
+			}
+			if len(strings.Trim(item.ImageRegistry.To, "/")) == 0 {
+				return fmt.Errorf("to of imageRegistry should be specified in item %d of entry %d", j, i)
+			}
+		}
+	}
+	return nil
+}
+
+// validateJSONPatch checks the json patch of each entry can be decoded as RFC 6902 JSON patch
+func validateJSONPatch(app *v1alpha1.YurtAppOverrider) error {
+	for i, entry := range app.Entries {
+		if len(entry.JSONPatch) == 0 {
+			continue
+		}
+		patchBytes, err := json.Marshal(entry.JSONPatch)
+		if err != nil {
+			return fmt.Errorf("could not encode json patch of entry %d, %v", i, err)
+		}
+		if _, err := jsonpatch.DecodePatch(patchBytes); err != nil {
+			return fmt.Errorf("invalid json patch of entry %d, %v", i, err)
+		}
+	}
+	return nil
+}
@@ -18,17 +18,22 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
@@ -38,6 +43,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	unitv1alpha1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/yurtappset/adapter"
 )
 
 // ValidateYurtAppSetSpec tests if required fields in the YurtAppSet spec are set.
@@ -101,11 +108,379 @@ func validateYurtAppSetSpec(c client.Client, spec *unitv1alpha1.YurtAppSetSpec,
 			allErrs = append(allErrs, apivalidation.ValidateTolerations(coreTolerations, fldPath.Child("topology", "pools").Index(i).Child("tolerations"))...)
 		}
 
+		if pool.Replicas != nil && pool.ProportionalReplicas != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("proportionalReplicas"),
+				"replicas and proportionalReplicas of pool are mutually exclusive, remove one of them"))
+		}
+		if pool.Replicas != nil && spec.Autoscaling != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("replicas"),
+				"replicas of pool are managed by autoscaling, set autoscaling of pool instead"))
+		}
+		if spec.Replicas != nil {
+			if pool.Replicas != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("replicas"),
+					"replicas of pool are distributed from replicas of YurtAppSet, set weight of pool instead"))
+			}
+			if pool.ProportionalReplicas != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("proportionalReplicas"),
+					"proportionalReplicas can not be used together with replicas of YurtAppSet"))
+			}
+		}
+		if pool.Weight != nil {
+			if spec.Replicas == nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("weight"),
+					"weight only takes effect when replicas of YurtAppSet are set"))
+			} else if *pool.Weight < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("topology", "pools").Index(i).Child("weight"), *pool.Weight,
+					"must be greater than or equal to 0"))
+			}
+		}
+
+		if pool.Patch != nil && selector != nil {
+			allErrs = append(allErrs, validatePoolPatch(&spec.WorkloadTemplate, &pool, selector, fldPath.Child("topology", "pools").Index(i).Child("patch"))...)
+		}
+
+		if c != nil {
+			allErrs = append(allErrs, validatePoolNodePool(c, &pool, fldPath.Child("topology", "pools").Index(i).Child("name"))...)
+		}
+
+		if pool.ProportionalReplicas != nil {
+			allErrs = append(allErrs, validateProportionalReplicas(pool.ProportionalReplicas, fldPath.Child("topology", "pools").Index(i).Child("proportionalReplicas"))...)
+		}
+
+		if spec.Autoscaling != nil {
+			if pool.ProportionalReplicas != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("topology", "pools").Index(i).Child("proportionalReplicas"),
+					"proportionalReplicas can not be used together with autoscaling"))
+			}
+			allErrs = append(allErrs, validateAutoscaling(spec.Autoscaling, &pool, fldPath.Child("topology", "pools").Index(i))...)
+		}
+
+		if len(pool.VolumeClaimTemplates) != 0 {
+			allErrs = append(allErrs, validatePoolVolumeClaimTemplates(spec, &pool, fldPath.Child("topology", "pools").Index(i).Child("volumeClaimTemplates"))...)
+		}
+	}
+
+	if spec.Replicas != nil {
+		if *spec.Replicas < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+		}
+		if spec.Autoscaling != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("replicas"), "replicas can not be used together with autoscaling"))
+		}
+	}
+
+	for i := range spec.Topology.Pools {
+		if pool := &spec.Topology.Pools[i]; pool.Overflow != nil {
+			allErrs = append(allErrs, validatePoolOverflow(spec, pool, poolNames, fldPath.Child("topology", "pools").Index(i).Child("overflow"))...)
+		}
+	}
+
+	for i := range spec.Topology.SpreadConstraints {
+		allErrs = append(allErrs, validateSpreadConstraint(spec, &spec.Topology.SpreadConstraints[i], fldPath.Child("topology", "spreadConstraints").Index(i))...)
+	}
+
+	if spec.RolloutStrategy != nil {
+		allErrs = append(allErrs, validateRolloutStrategy(spec.RolloutStrategy, poolNames, fldPath.Child("rolloutStrategy"))...)
+	}
+
+	if spec.RollbackTo != nil && spec.RollbackTo.Revision < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rollbackTo", "revision"), spec.RollbackTo.Revision, "must be greater than or equal to 0"))
+	}
+
+	if len(spec.ConfigTemplates) != 0 {
+		allErrs = append(allErrs, validateConfigTemplates(spec, fldPath.Child("configTemplates"))...)
+	}
+
+	if len(spec.Bundle) != 0 {
+		allErrs = append(allErrs, validateBundle(spec, fldPath.Child("bundle"))...)
 	}
 
 	return allErrs
 }
 
+// validatePoolOverflow checks the overflow pools are other pools in topology, and replicas of pool
+// are not managed by autoscaling.
+func validatePoolOverflow(spec *unitv1alpha1.YurtAppSetSpec, pool *unitv1alpha1.Pool, poolNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Autoscaling != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "overflow can not be used together with autoscaling"))
+	}
+	if pool.Overflow.ReplicasPerNode < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicasPerNode"), pool.Overflow.ReplicasPerNode, "must be greater than 0"))
+	}
+	if len(pool.Overflow.Pools) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("pools"), "at least one overflow pool is required"))
+	}
+
+	overflowPools := sets.String{}
+	for i, name := range pool.Overflow.Pools {
+		switch {
+		case name == pool.Name:
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pools").Index(i), name, "pool can not overflow to itself"))
+		case !poolNames.Has(name):
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("pools").Index(i), name))
+		case overflowPools.Has(name):
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("pools").Index(i), name))
+		}
+		overflowPools.Insert(name)
+	}
+	return allErrs
+}
+
+// validateSpreadConstraint checks the spread constraint has at least one limit, and the limits are
+// in range and don't move replicas managed by autoscaling.
+func validateSpreadConstraint(spec *unitv1alpha1.YurtAppSetSpec, constraint *unitv1alpha1.PoolSpreadConstraint, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Autoscaling != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "spreadConstraints can not be used together with autoscaling"))
+	}
+	if constraint.PoolSelector != nil {
+		allErrs = append(allErrs, unversionedvalidation.ValidateLabelSelector(constraint.PoolSelector, fldPath.Child("poolSelector"))...)
+	}
+	if constraint.MinReplicasPerPool == nil && constraint.MaxPercentPerDomain == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of minReplicasPerPool and maxPercentPerDomain is required"))
+	}
+	if constraint.MinReplicasPerPool != nil && *constraint.MinReplicasPerPool < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicasPerPool"), *constraint.MinReplicasPerPool, "must be greater than or equal to 0"))
+	}
+	if constraint.MaxPercentPerDomain != nil && (*constraint.MaxPercentPerDomain < 1 || *constraint.MaxPercentPerDomain > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxPercentPerDomain"), *constraint.MaxPercentPerDomain, "must be between 1 and 100"))
+	}
+
+	switch constraint.TopologyKey {
+	case "", unitv1alpha1.PoolSpreadTopologyKey, unitv1alpha1.RegionSpreadTopologyKey,
+		unitv1alpha1.ZoneSpreadTopologyKey, unitv1alpha1.SiteSpreadTopologyKey:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("topologyKey"), constraint.TopologyKey,
+			[]string{string(unitv1alpha1.PoolSpreadTopologyKey), string(unitv1alpha1.RegionSpreadTopologyKey),
+				string(unitv1alpha1.ZoneSpreadTopologyKey), string(unitv1alpha1.SiteSpreadTopologyKey)}))
+	}
+	return allErrs
+}
+
+// validatePoolNodePool checks the NodePool selected by the pool exists.
+func validatePoolNodePool(c client.Client, pool *unitv1alpha1.Pool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	npName := adapter.PoolNodePoolName(pool)
+	np := &appsv1beta1.NodePool{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: npName}, np); apierrors.IsNotFound(err) {
+		allErrs = append(allErrs, field.Invalid(fldPath, pool.Name,
+			fmt.Sprintf("NodePool %s selected by the pool is not found, create the NodePool first or correct the pool", npName)))
+	} else if err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath, fmt.Errorf("fail to get NodePool %s: %v", npName, err)))
+	}
+	return allErrs
+}
+
+// validatePoolPatch checks the patch of pool applies cleanly against the workload template,
+// and the fields managed by YurtAppSet are not overridden by the patch.
+func validatePoolPatch(template *unitv1alpha1.WorkloadTemplate, pool *unitv1alpha1.Pool, selector labels.Selector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	patchMap := map[string]interface{}{}
+	if err := json.Unmarshal(pool.Patch.Raw, &patchMap); err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(pool.Patch.Raw), fmt.Sprintf("patch should be a JSON object: %v", err)))
+	}
+	if patchSpec, ok := patchMap["spec"].(map[string]interface{}); ok {
+		if _, ok := patchSpec["replicas"]; ok {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spec", "replicas"),
+				"replicas of pool workload can not be patched, set replicas of pool instead"))
+		}
+		if _, ok := patchSpec["selector"]; ok {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("spec", "selector"),
+				"selector of pool workload can not be patched, it is inherited from the selector of YurtAppSet"))
+		}
+	}
+	if len(allErrs) != 0 {
+		return allErrs
+	}
+
+	var podTemplate *v1.PodTemplateSpec
+	switch {
+	case template.StatefulSetTemplate != nil:
+		set := &appsv1.StatefulSet{ObjectMeta: template.StatefulSetTemplate.ObjectMeta, Spec: template.StatefulSetTemplate.Spec}
+		patched := &appsv1.StatefulSet{}
+		if err := adapter.StrategicMergeByPatches(set, pool.Patch, patched); err != nil {
+			return append(allErrs, field.Invalid(fldPath, string(pool.Patch.Raw), fmt.Sprintf("patch can not be applied against statefulSetTemplate: %v", err)))
+		}
+		podTemplate = &patched.Spec.Template
+	case template.DeploymentTemplate != nil:
+		deploy := &appsv1.Deployment{ObjectMeta: template.DeploymentTemplate.ObjectMeta, Spec: template.DeploymentTemplate.Spec}
+		patched := &appsv1.Deployment{}
+		if err := adapter.StrategicMergeByPatches(deploy, pool.Patch, patched); err != nil {
+			return append(allErrs, field.Invalid(fldPath, string(pool.Patch.Raw), fmt.Sprintf("patch can not be applied against deploymentTemplate: %v", err)))
+		}
+		podTemplate = &patched.Spec.Template
+	default:
+		return allErrs
+	}
+
+	coreTemplate, err := convertPodTemplateSpec(podTemplate)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(pool.Patch.Raw), fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
+	}
+	allErrs = append(allErrs, validatePodTemplateSpec(coreTemplate, selector, fldPath.Child("spec", "template"))...)
+	allErrs = append(allErrs, apivalidation.ValidatePodTemplateSpec(coreTemplate, fldPath.Child("spec", "template"), apivalidation.PodValidationOptions{})...)
+	return allErrs
+}
+
+// validateProportionalReplicas checks the ratio is positive and the limits of replicas are valid.
+func validateProportionalReplicas(p *unitv1alpha1.ProportionalReplicas, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if p.Ratio <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ratio"), p.Ratio, "must be greater than 0"))
+	}
+	if p.Min != nil && *p.Min < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("min"), *p.Min, "must be greater than or equal to 0"))
+	}
+	if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("max"), *p.Max, "must be greater than or equal to min"))
+	}
+	return allErrs
+}
+
+// validateAutoscaling checks the bounds of replicas of the HorizontalPodAutoscaler generated for pool are valid.
+func validateAutoscaling(autoscaling *unitv1alpha1.YurtAppSetAutoscaling, pool *unitv1alpha1.Pool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	minReplicas, maxReplicas := int32(1), autoscaling.MaxReplicas
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+	if pool.Autoscaling != nil {
+		if pool.Autoscaling.MinReplicas != nil {
+			minReplicas = *pool.Autoscaling.MinReplicas
+		}
+		if pool.Autoscaling.MaxReplicas != nil {
+			maxReplicas = *pool.Autoscaling.MaxReplicas
+		}
+	}
+
+	if minReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("autoscaling", "minReplicas"), minReplicas, "must be greater than or equal to 1"))
+	}
+	if maxReplicas < minReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("autoscaling", "maxReplicas"), maxReplicas, "must be greater than or equal to minReplicas"))
+	}
+	return allErrs
+}
+
+// validatePoolVolumeClaimTemplates checks the volumeClaimTemplates of pool are set only for StatefulSetTemplate and named uniquely.
+func validatePoolVolumeClaimTemplates(spec *unitv1alpha1.YurtAppSetSpec, pool *unitv1alpha1.Pool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.WorkloadTemplate.StatefulSetTemplate == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only supported for statefulSetTemplate"))
+		return allErrs
+	}
+
+	claimNames := sets.String{}
+	for i, claim := range pool.VolumeClaimTemplates {
+		if len(claim.Name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("metadata", "name"), ""))
+			continue
+		}
+		if claimNames.Has(claim.Name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("metadata", "name"), claim.Name))
+		}
+		claimNames.Insert(claim.Name)
+	}
+	return allErrs
+}
+
+// validateConfigTemplates checks the config templates are named uniquely, and their data can be rendered.
+func validateConfigTemplates(spec *unitv1alpha1.YurtAppSetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	templateNames := sets.String{}
+	for i := range spec.ConfigTemplates {
+		tmpl := &spec.ConfigTemplates[i]
+		if errs := apimachineryvalidation.NameIsDNSLabel(tmpl.Name, false); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), tmpl.Name, strings.Join(errs, ", ")))
+		}
+		if templateNames.Has(tmpl.Name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("name"), tmpl.Name))
+		}
+		templateNames.Insert(tmpl.Name)
+
+		switch tmpl.Kind {
+		case "", unitv1alpha1.ConfigMapConfigTemplateKind, unitv1alpha1.SecretConfigTemplateKind:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i).Child("kind"), tmpl.Kind,
+				[]string{string(unitv1alpha1.ConfigMapConfigTemplateKind), string(unitv1alpha1.SecretConfigTemplateKind)}))
+		}
+
+		for key := range tmpl.Data {
+			for _, msg := range validation.IsConfigMapKey(key) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("data").Key(key), key, msg))
+			}
+		}
+		// variables are not known until the pool is reconciled, so the template is rendered with empty variables
+		// for checking the syntax and the variables referenced.
+		if _, err := adapter.RenderConfigTemplate(tmpl, &adapter.PoolConfigVariables{}); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("data"), tmpl.Name, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateBundle checks the bundle templates are named uniquely and each of them describes exactly one object.
+func validateBundle(spec *unitv1alpha1.YurtAppSetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	configTemplateNames := sets.String{}
+	for _, tmpl := range spec.ConfigTemplates {
+		configTemplateNames.Insert(tmpl.Name)
+	}
+
+	templateNames := sets.String{}
+	for i := range spec.Bundle {
+		tmpl := &spec.Bundle[i]
+		if errs := apimachineryvalidation.NameIsDNSLabel(tmpl.Name, false); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), tmpl.Name, strings.Join(errs, ", ")))
+		}
+		if templateNames.Has(tmpl.Name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("name"), tmpl.Name))
+		}
+		templateNames.Insert(tmpl.Name)
+
+		switch {
+		case tmpl.ServiceTemplate == nil && tmpl.ConfigMapTemplate == nil:
+			allErrs = append(allErrs, field.Required(fldPath.Index(i), "one of serviceTemplate and configMapTemplate is required"))
+		case tmpl.ServiceTemplate != nil && tmpl.ConfigMapTemplate != nil:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i), "serviceTemplate and configMapTemplate are mutually exclusive"))
+		case tmpl.ConfigMapTemplate != nil:
+			// the ConfigMaps of config templates and bundle of pool share the same naming
+			if configTemplateNames.Has(tmpl.Name) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), tmpl.Name, "conflicts with the name of config template"))
+			}
+			for key := range tmpl.ConfigMapTemplate.Data {
+				for _, msg := range validation.IsConfigMapKey(key) {
+					allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("configMapTemplate", "data").Key(key), key, msg))
+				}
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateRolloutStrategy checks the pools in rollout order are declared in topology and not duplicated.
+func validateRolloutStrategy(strategy *unitv1alpha1.YurtAppSetRolloutStrategy, poolNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	rolloutPools := sets.String{}
+	for i, pool := range strategy.Pools {
+		if !poolNames.Has(pool.Name) {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("pools").Index(i).Child("name"), pool.Name))
+		}
+		if rolloutPools.Has(pool.Name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("pools").Index(i).Child("name"), pool.Name))
+		}
+		rolloutPools.Insert(pool.Name)
+
+		if pool.MinReadyPercent != nil && (*pool.MinReadyPercent < 0 || *pool.MinReadyPercent > 100) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("pools").Index(i).Child("minReadyPercent"), *pool.MinReadyPercent,
+				"must be between 0 and 100"))
+		}
+	}
+	return allErrs
+}
+
 // validateYurtAppSet validates a YurtAppSet.
 func validateYurtAppSet(c client.Client, yurtAppSet *unitv1alpha1.YurtAppSet) field.ErrorList {
 	allErrs := apivalidation.ValidateObjectMeta(&yurtAppSet.ObjectMeta, true, apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
@@ -154,6 +529,9 @@ func validateYurtAppSetTopology(topology, oldTopology *unitv1alpha1.Topology, fl
 			if !apiequality.Semantic.DeepEqual(oldPool.Tolerations, pool.Tolerations) {
 				allErrs = append(allErrs, field.Forbidden(fldPath.Child("pools").Index(i).Child("tolerations"), "may not be changed in an update"))
 			}
+			if !apiequality.Semantic.DeepEqual(oldPool.VolumeClaimTemplates, pool.VolumeClaimTemplates) {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("pools").Index(i).Child("volumeClaimTemplates"), "may not be changed in an update"))
+			}
 		}
 	}
 	return allErrs
@@ -184,6 +562,12 @@ func validatePoolTemplate(template *unitv1alpha1.WorkloadTemplate, spec *unitv1a
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.JobTemplate != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("jobTemplate"), "only supported by YurtAppDaemon"))
+	}
+	if template.CronJobTemplate != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("cronJobTemplate"), "only supported by YurtAppDaemon"))
+	}
 
 	if templateCount < 1 {
 		allErrs = append(allErrs, field.Required(fldPath, "should provide one of (statefulSetTemplate/deploymentTemplate/daemonSetTemplate)"))
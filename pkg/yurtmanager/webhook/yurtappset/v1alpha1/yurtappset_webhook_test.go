@@ -21,8 +21,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/apis/apps/v1beta1"
 )
 
 var defaultAppSet = &v1alpha1.YurtAppSet{
@@ -85,6 +88,169 @@ func TestYurtAppSetValidator(t *testing.T) {
 		t.Fatal("topology dup should not fail")
 	}
 
+	rolloutAppSet := defaultAppSet.DeepCopy()
+	rolloutAppSet.Spec.RolloutStrategy = &v1alpha1.YurtAppSetRolloutStrategy{Pools: []v1alpha1.RolloutPool{{Name: "beijing"}}}
+	if err := webhook.ValidateCreate(context.TODO(), rolloutAppSet); err != nil {
+		t.Fatal("rollout strategy with pool in topology should create success", err)
+	}
+
+	unknownRolloutPool := defaultAppSet.DeepCopy()
+	unknownRolloutPool.Spec.RolloutStrategy = &v1alpha1.YurtAppSetRolloutStrategy{Pools: []v1alpha1.RolloutPool{{Name: "hangzhou"}}}
+	if err := webhook.ValidateCreate(context.TODO(), unknownRolloutPool); err == nil {
+		t.Fatal("rollout strategy with pool not in topology should fail")
+	}
+
+	volumeClaimAppSet := defaultAppSet.DeepCopy()
+	volumeClaimAppSet.Spec.Topology.Pools[0].VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}}
+	if err := webhook.ValidateCreate(context.TODO(), volumeClaimAppSet); err == nil {
+		t.Fatal("pool volumeClaimTemplates with deployment template should fail")
+	}
+
+	minReplicas, maxReplicas := int32(3), int32(1)
+	proportionalAppSet := defaultAppSet.DeepCopy()
+	proportionalAppSet.Spec.Topology.Pools[0].ProportionalReplicas = &v1alpha1.ProportionalReplicas{Ratio: 50, Min: &minReplicas, Max: &maxReplicas}
+	if err := webhook.ValidateCreate(context.TODO(), proportionalAppSet); err == nil {
+		t.Fatal("proportional replicas with min greater than max should fail")
+	}
+
+	replicas := int32(2)
+	conflictReplicasAppSet := defaultAppSet.DeepCopy()
+	conflictReplicasAppSet.Spec.Topology.Pools[0].Replicas = &replicas
+	conflictReplicasAppSet.Spec.Topology.Pools[0].ProportionalReplicas = &v1alpha1.ProportionalReplicas{Ratio: 50}
+	if err := webhook.ValidateCreate(context.TODO(), conflictReplicasAppSet); err == nil {
+		t.Fatal("pool with both replicas and proportional replicas should fail")
+	}
+
+	totalReplicas, weight := int32(10), int32(70)
+	weightAppSet := defaultAppSet.DeepCopy()
+	weightAppSet.Spec.Replicas = &totalReplicas
+	weightAppSet.Spec.Topology.Pools[0].Replicas = nil
+	weightAppSet.Spec.Topology.Pools[0].Weight = &weight
+	if err := webhook.ValidateCreate(context.TODO(), weightAppSet); err != nil {
+		t.Fatal("pool with weight should create success", err)
+	}
+
+	weightWithReplicasAppSet := weightAppSet.DeepCopy()
+	weightWithReplicasAppSet.Spec.Topology.Pools[0].Replicas = &replicas
+	if err := webhook.ValidateCreate(context.TODO(), weightWithReplicasAppSet); err == nil {
+		t.Fatal("pool with replicas when replicas of YurtAppSet are set should fail")
+	}
+
+	weightWithoutReplicasAppSet := weightAppSet.DeepCopy()
+	weightWithoutReplicasAppSet.Spec.Replicas = nil
+	if err := webhook.ValidateCreate(context.TODO(), weightWithoutReplicasAppSet); err == nil {
+		t.Fatal("pool with weight when replicas of YurtAppSet are not set should fail")
+	}
+
+	minPerPool, maxPercent := int32(1), int32(40)
+	spreadAppSet := defaultAppSet.DeepCopy()
+	spreadAppSet.Spec.Topology.SpreadConstraints = []v1alpha1.PoolSpreadConstraint{{
+		MinReplicasPerPool:  &minPerPool,
+		TopologyKey:         v1alpha1.RegionSpreadTopologyKey,
+		MaxPercentPerDomain: &maxPercent,
+	}}
+	if err := webhook.ValidateCreate(context.TODO(), spreadAppSet); err != nil {
+		t.Fatal("spread constraints should create success", err)
+	}
+
+	emptySpreadAppSet := defaultAppSet.DeepCopy()
+	emptySpreadAppSet.Spec.Topology.SpreadConstraints = []v1alpha1.PoolSpreadConstraint{{
+		TopologyKey: v1alpha1.ZoneSpreadTopologyKey,
+	}}
+	if err := webhook.ValidateCreate(context.TODO(), emptySpreadAppSet); err == nil {
+		t.Fatal("spread constraint without limits should fail")
+	}
+
+	patchAppSet := defaultAppSet.DeepCopy()
+	patchAppSet.Spec.Topology.Pools[0].Patch = &runtime.RawExtension{
+		Raw: []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"demo","image":"nginx:1.19"}]}}}}`),
+	}
+	if err := webhook.ValidateCreate(context.TODO(), patchAppSet); err != nil {
+		t.Fatal("pool with valid patch should create success", err)
+	}
+
+	invalidPatches := map[string]string{
+		"patch replicas":         `{"spec":{"replicas":3}}`,
+		"patch selector":         `{"spec":{"selector":{"matchLabels":{"app":"demo2"}}}}`,
+		"patch mismatched label": `{"spec":{"template":{"metadata":{"labels":{"app":"demo2"}}}}}`,
+		"patch invalid template": `{"spec":{"template":{"spec":{"containers":[{"name":"demo","image":""}]}}}}`,
+		"patch not applied":      `{"spec":{"template":{"spec":{"containers":"demo"}}}}`,
+	}
+	for name, patch := range invalidPatches {
+		invalidPatchAppSet := defaultAppSet.DeepCopy()
+		invalidPatchAppSet.Spec.Topology.Pools[0].Patch = &runtime.RawExtension{Raw: []byte(patch)}
+		if err := webhook.ValidateCreate(context.TODO(), invalidPatchAppSet); err == nil {
+			t.Fatalf("pool with invalid patch(%s) should fail", name)
+		}
+	}
+
+	configTemplateAppSet := defaultAppSet.DeepCopy()
+	configTemplateAppSet.Spec.ConfigTemplates = []v1alpha1.PoolConfigTemplate{
+		{Name: "conf", Kind: v1alpha1.ConfigMapConfigTemplateKind, Data: map[string]string{"app.conf": "pool={{ .PoolName }}"}},
+	}
+	if err := webhook.ValidateCreate(context.TODO(), configTemplateAppSet); err != nil {
+		t.Fatal("valid config template should create success", err)
+	}
+
+	invalidConfigTemplates := map[string]v1alpha1.PoolConfigTemplate{
+		"invalid name":     {Name: "Conf", Data: map[string]string{"app.conf": "foo"}},
+		"invalid key":      {Name: "conf", Data: map[string]string{"app/conf": "foo"}},
+		"invalid template": {Name: "conf", Data: map[string]string{"app.conf": "{{ .PoolName "}},
+		"unknown variable": {Name: "conf", Data: map[string]string{"app.conf": "{{ .Unknown }}"}},
+	}
+	for name, tmpl := range invalidConfigTemplates {
+		invalidConfigTemplateAppSet := defaultAppSet.DeepCopy()
+		invalidConfigTemplateAppSet.Spec.ConfigTemplates = []v1alpha1.PoolConfigTemplate{tmpl}
+		if err := webhook.ValidateCreate(context.TODO(), invalidConfigTemplateAppSet); err == nil {
+			t.Fatalf("config template with %s should fail", name)
+		}
+	}
+
+	dupConfigTemplateAppSet := configTemplateAppSet.DeepCopy()
+	dupConfigTemplateAppSet.Spec.ConfigTemplates = append(dupConfigTemplateAppSet.Spec.ConfigTemplates, dupConfigTemplateAppSet.Spec.ConfigTemplates[0])
+	if err := webhook.ValidateCreate(context.TODO(), dupConfigTemplateAppSet); err == nil {
+		t.Fatal("duplicated config templates should fail")
+	}
+
+	bundleAppSet := defaultAppSet.DeepCopy()
+	bundleAppSet.Spec.Bundle = []v1alpha1.BundleTemplate{
+		{Name: "svc", ServiceTemplate: &v1alpha1.ServiceTemplateSpec{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}}}},
+		{Name: "conf", ConfigMapTemplate: &v1alpha1.ConfigMapTemplateSpec{Data: map[string]string{"app.conf": "foo"}}},
+	}
+	if err := webhook.ValidateCreate(context.TODO(), bundleAppSet); err != nil {
+		t.Fatal("valid bundle should create success", err)
+	}
+
+	invalidBundles := map[string]v1alpha1.BundleTemplate{
+		"no template":       {Name: "svc"},
+		"both templates":    {Name: "svc", ServiceTemplate: &v1alpha1.ServiceTemplateSpec{}, ConfigMapTemplate: &v1alpha1.ConfigMapTemplateSpec{}},
+		"invalid data key":  {Name: "conf", ConfigMapTemplate: &v1alpha1.ConfigMapTemplateSpec{Data: map[string]string{"app/conf": "foo"}}},
+		"duplicated name":   bundleAppSet.Spec.Bundle[0],
+		"config conflicted": {Name: "conf-template", ConfigMapTemplate: &v1alpha1.ConfigMapTemplateSpec{}},
+	}
+	for name, tmpl := range invalidBundles {
+		invalidBundleAppSet := bundleAppSet.DeepCopy()
+		invalidBundleAppSet.Spec.ConfigTemplates = []v1alpha1.PoolConfigTemplate{{Name: "conf-template", Data: map[string]string{"app.conf": "foo"}}}
+		invalidBundleAppSet.Spec.Bundle = append(invalidBundleAppSet.Spec.Bundle, tmpl)
+		if err := webhook.ValidateCreate(context.TODO(), invalidBundleAppSet); err == nil {
+			t.Fatalf("bundle with %s should fail", name)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add yurt custom resource, %v", err)
+	}
+	nodePoolWebhook := &YurtAppSetHandler{Client: fakeclient.NewClientBuilder().WithScheme(scheme).Build()}
+	if err := nodePoolWebhook.ValidateCreate(context.TODO(), defaultAppSet); err == nil {
+		t.Fatal("pool whose nodepool is not found should fail")
+	}
+	nodePoolWebhook.Client = fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithObjects(&v1beta1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "beijing"}}).Build()
+	if err := nodePoolWebhook.ValidateCreate(context.TODO(), defaultAppSet); err != nil {
+		t.Fatal("pool whose nodepool exists should create success", err)
+	}
+
 	updateAppSet := defaultAppSet.DeepCopy()
 	updateAppSet.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo2"}}
 	if err := webhook.ValidateUpdate(context.TODO(), defaultAppSet, updateAppSet); err == nil {
@@ -21,7 +21,10 @@ import (
 	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/apis/core"
@@ -29,6 +32,7 @@ import (
 	k8s_validation "k8s.io/kubernetes/pkg/apis/core/validation"
 
 	"github.com/openyurtio/openyurt/pkg/apis/apps/v1alpha1"
+	"github.com/openyurtio/openyurt/pkg/yurtmanager/controller/util/maintenance"
 )
 
 const (
@@ -90,6 +94,10 @@ func validate(obj *v1alpha1.YurtStaticSet) error {
 		allErrs = append(allErrs, e...)
 	}
 
+	if e := validateBundledManifests(&obj.Spec); len(e) > 0 {
+		allErrs = append(allErrs, e...)
+	}
+
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind(YurtStaticSetKind).GroupKind(), obj.Name, allErrs)
 	}
@@ -99,6 +107,32 @@ func validate(obj *v1alpha1.YurtStaticSet) error {
 	return nil
 }
 
+// validateBundledManifests validates the static pods bundled with the main one, their manifests must be unique.
+func validateBundledManifests(spec *v1alpha1.YurtStaticSetSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	manifests := sets.NewString(spec.StaticPodManifest)
+	for i := range spec.BundledManifests {
+		bundled := &spec.BundledManifests[i]
+		fldPath := field.NewPath("spec").Child("bundledManifests").Index(i)
+		if bundled.StaticPodManifest == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("staticPodManifest"), "staticPodManifest is required"))
+		} else if manifests.Has(bundled.StaticPodManifest) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("staticPodManifest"), bundled.StaticPodManifest))
+		}
+		manifests.Insert(bundled.StaticPodManifest)
+
+		outPodTemplateSpec := &core.PodTemplateSpec{}
+		if err := k8s_api_v1.Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec(&bundled.Template, outPodTemplateSpec, nil); err != nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("template"),
+				"template filed should be corev1.PodTemplateSpec type"))
+			continue
+		}
+		allErrs = append(allErrs, k8s_validation.ValidatePodTemplateSpec(outPodTemplateSpec, fldPath.Child("template"),
+			k8s_validation.PodValidationOptions{})...)
+	}
+	return allErrs
+}
+
 // validateYurtStaticSetSpec validates the YurtStaticSet spec.
 func validateYurtStaticSetSpec(spec *v1alpha1.YurtStaticSetSpec) field.ErrorList {
 	var allErrs field.ErrorList
@@ -120,6 +154,60 @@ func validateYurtStaticSetSpec(spec *v1alpha1.YurtStaticSetSpec) field.ErrorList
 			"max-unavailable is required in AdvancedRollingUpdate mode"))
 	}
 
+	if strategy.RollbackWindow != nil && strategy.RollbackWindow.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("upgradeStrategy").Child("rollbackWindow"),
+			strategy.RollbackWindow.Duration.String(), "rollback window must be positive"))
+	}
+
+	if err := maintenance.ValidateWindows(strategy.MaintenanceWindows); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("upgradeStrategy").Child("maintenanceWindows"),
+			strategy.MaintenanceWindows, err.Error()))
+	}
+
+	if canary := strategy.Canary; canary != nil {
+		canaryPath := field.NewPath("spec").Child("upgradeStrategy").Child("canary")
+		if canary.PoolSelector == nil {
+			allErrs = append(allErrs, field.Required(canaryPath.Child("poolSelector"), "canary pool selector is required"))
+		} else if _, err := metav1.LabelSelectorAsSelector(canary.PoolSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(canaryPath.Child("poolSelector"), canary.PoolSelector, err.Error()))
+		}
+		if canary.SoakPeriod != nil && canary.SoakPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(canaryPath.Child("soakPeriod"),
+				canary.SoakPeriod.Duration.String(), "soak period must not be negative"))
+		}
+	}
+
+	if prePull := strategy.PrePull; prePull != nil {
+		prePullPath := field.NewPath("spec").Child("upgradeStrategy").Child("prePull")
+		if err := maintenance.ValidateWindows(prePull.Windows); err != nil {
+			allErrs = append(allErrs, field.Invalid(prePullPath.Child("windows"), prePull.Windows, err.Error()))
+		}
+		if max := prePull.MaxPullingNodesPerPool; max != nil {
+			if v, err := intstr.GetScaledValueFromIntOrPercent(max, 100, true); err != nil || v <= 0 {
+				allErrs = append(allErrs, field.Invalid(prePullPath.Child("maxPullingNodesPerPool"), max.String(),
+					"max pulling nodes per pool must be a positive number or percentage"))
+			}
+		}
+	}
+
+	if gate := strategy.HealthGate; gate != nil {
+		gatePath := field.NewPath("spec").Child("upgradeStrategy").Child("healthGate")
+		if gate.ReadinessPassCount < 0 {
+			allErrs = append(allErrs, field.Invalid(gatePath.Child("readinessPassCount"), gate.ReadinessPassCount,
+				"readiness pass count must not be negative"))
+		}
+		if gate.MinUptime != nil && gate.MinUptime.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(gatePath.Child("minUptime"), gate.MinUptime.Duration.String(),
+				"min uptime must not be negative"))
+		}
+		if gate.HTTPGet != nil {
+			if port := gate.HTTPGet.Port; port.Type != intstr.Int || port.IntValue() < 1 || port.IntValue() > 65535 {
+				allErrs = append(allErrs, field.Invalid(gatePath.Child("httpGet").Child("port"), port.String(),
+					"port must be a number between 1 and 65535"))
+			}
+		}
+	}
+
 	if allErrs != nil {
 		return allErrs
 	}
@@ -59,7 +59,7 @@ type ClusterConverter struct {
 	ClientSet                 kubeclientset.Interface
 	CloudNodes                []string
 	EdgeNodes                 []string
-	WaitServantJobTimeout     time.Duration
+	ServantJobOptions         kubeutil.ServantJobOptions
 	YurthubHealthCheckTimeout time.Duration
 	KubeConfigPath            string
 	YurtManagerImage          string
@@ -177,7 +177,7 @@ func (c *ClusterConverter) deployYurthub() error {
 	if len(c.EdgeNodes) != 0 {
 		convertCtx["working_mode"] = string(util.WorkingModeEdge)
 		convertCtx["configmap_name"] = yssYurtHubName
-		if err = kubeutil.RunServantJobs(c.ClientSet, c.WaitServantJobTimeout, func(nodeName string) (*batchv1.Job, error) {
+		if err = kubeutil.RunServantJobs(c.ClientSet, c.ServantJobOptions, func(nodeName string) (*batchv1.Job, error) {
 			return nodeservant.RenderNodeServantJob("convert", convertCtx, nodeName)
 		}, c.EdgeNodes, os.Stderr, false); err != nil {
 			// print logs of yurthub
@@ -213,7 +213,7 @@ func (c *ClusterConverter) deployYurthub() error {
 	convertCtx["working_mode"] = string(util.WorkingModeCloud)
 	convertCtx["configmap_name"] = yssYurtHubCloudName
 	klog.Infof("convert context for cloud nodes(%q): %#+v", c.CloudNodes, convertCtx)
-	if err = kubeutil.RunServantJobs(c.ClientSet, c.WaitServantJobTimeout, func(nodeName string) (*batchv1.Job, error) {
+	if err = kubeutil.RunServantJobs(c.ClientSet, c.ServantJobOptions, func(nodeName string) (*batchv1.Job, error) {
 		return nodeservant.RenderNodeServantJob("convert", convertCtx, nodeName)
 	}, c.CloudNodes, os.Stderr, false); err != nil {
 		return err
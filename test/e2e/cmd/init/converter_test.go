@@ -32,7 +32,7 @@ func NewClusterConverter(ki *Initializer) *ClusterConverter {
 		ClientSet:                 ki.kubeClient,
 		CloudNodes:                ki.CloudNodes,
 		EdgeNodes:                 ki.EdgeNodes,
-		WaitServantJobTimeout:     yurtutil.DefaultWaitServantJobTimeout,
+		ServantJobOptions:         yurtutil.NewServantJobOptions(),
 		YurthubHealthCheckTimeout: defaultYurthubHealthCheckTimeout,
 		KubeConfigPath:            ki.KubeConfig,
 		YurtManagerImage:          ki.YurtManagerImage,
@@ -115,6 +115,7 @@ type kindOptions struct {
 	IgnoreError       bool
 	EnableDummyIf     bool
 	DisableDefaultCNI bool
+	ServantJobOptions kubeutil.ServantJobOptions
 }
 
 func newKindOptions() *kindOptions {
@@ -128,6 +129,7 @@ func newKindOptions() *kindOptions {
 		IgnoreError:       false,
 		EnableDummyIf:     true,
 		DisableDefaultCNI: false,
+		ServantJobOptions: kubeutil.NewServantJobOptions(),
 	}
 }
 
@@ -141,6 +143,9 @@ func (o *kindOptions) Validate() error {
 	if err := validateOpenYurtVersion(o.OpenYurtVersion, o.IgnoreError); err != nil {
 		return err
 	}
+	if err := o.ServantJobOptions.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -194,6 +199,7 @@ func (o *kindOptions) Config() *initializerConfig {
 		NodeServantImage:  fmt.Sprintf(nodeServantImageFormat, o.OpenYurtVersion),
 		EnableDummyIf:     o.EnableDummyIf,
 		DisableDefaultCNI: o.DisableDefaultCNI,
+		ServantJobOptions: o.ServantJobOptions,
 	}
 }
 
@@ -222,6 +228,14 @@ func addFlags(flagset *pflag.FlagSet, o *kindOptions) {
 	flagset.BoolVar(&o.DisableDefaultCNI, "disable-default-cni", o.DisableDefaultCNI,
 		"Disable the default cni of kind cluster which is kindnet. "+
 			"If this option is set, you should check the ready status of pods by yourself after installing your CNI.")
+	flagset.IntVar(&o.ServantJobOptions.Parallelism, "servant-job-parallelism", o.ServantJobOptions.Parallelism,
+		"The max number of nodes that run node-servant jobs at the same time, 0 means no limit.")
+	flagset.IntVar(&o.ServantJobOptions.RetryLimit, "servant-job-retries", o.ServantJobOptions.RetryLimit,
+		"The max number of times that the failed node-servant job of a node is rerun.")
+	flagset.DurationVar(&o.ServantJobOptions.Backoff, "servant-job-backoff", o.ServantJobOptions.Backoff,
+		"The wait before rerunning a failed node-servant job, it's doubled for each subsequent retry of the node.")
+	flagset.DurationVar(&o.ServantJobOptions.Timeout, "servant-job-timeout", o.ServantJobOptions.Timeout,
+		"The timeout of waiting for the node-servant job of a node to be succeeded in each attempt.")
 }
 
 type initializerConfig struct {
@@ -239,6 +253,7 @@ type initializerConfig struct {
 	NodeServantImage  string
 	EnableDummyIf     bool
 	DisableDefaultCNI bool
+	ServantJobOptions kubeutil.ServantJobOptions
 }
 
 type Initializer struct {
@@ -496,7 +511,7 @@ func (ki *Initializer) deployOpenYurt() error {
 		ClientSet:                 ki.kubeClient,
 		CloudNodes:                ki.CloudNodes,
 		EdgeNodes:                 ki.EdgeNodes,
-		WaitServantJobTimeout:     kubeutil.DefaultWaitServantJobTimeout,
+		ServantJobOptions:         ki.ServantJobOptions,
 		YurthubHealthCheckTimeout: defaultYurthubHealthCheckTimeout,
 		KubeConfigPath:            ki.KubeConfig,
 		YurtManagerImage:          ki.YurtManagerImage,
@@ -36,6 +36,7 @@ import (
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
@@ -54,6 +55,8 @@ const (
 	SystemNamespace = "kube-system"
 	// DefaultWaitServantJobTimeout specifies the timeout value of waiting for the ServantJob to be succeeded
 	DefaultWaitServantJobTimeout = time.Minute * 5
+	// DefaultServantJobBackoff specifies the wait before retrying a failed ServantJob for the first time
+	DefaultServantJobBackoff = time.Second * 10
 )
 
 var (
@@ -264,14 +267,56 @@ func PrintPodLog(client kubeclientset.Interface, pod *corev1.Pod, w io.Writer) e
 	return nil
 }
 
+// ServantJobOptions controls how servant jobs are run on nodes.
+type ServantJobOptions struct {
+	// Parallelism is the max number of nodes that run servant jobs at the same time, 0 means no limit.
+	Parallelism int
+	// RetryLimit is the max number of times that the failed job of a node is rerun.
+	RetryLimit int
+	// Backoff is the wait before the first retry of a node, and it's doubled for each subsequent retry.
+	Backoff time.Duration
+	// Timeout is the timeout of waiting for the job of a node to be succeeded in each attempt.
+	Timeout time.Duration
+}
+
+// NewServantJobOptions returns the default options which run jobs on all nodes at once without retry.
+func NewServantJobOptions() ServantJobOptions {
+	return ServantJobOptions{
+		Backoff: DefaultServantJobBackoff,
+		Timeout: DefaultWaitServantJobTimeout,
+	}
+}
+
+// Validate checks the servant job options.
+func (o ServantJobOptions) Validate() error {
+	if o.Parallelism < 0 {
+		return fmt.Errorf("the parallelism of servant jobs must not be negative")
+	}
+	if o.RetryLimit < 0 {
+		return fmt.Errorf("the retry limit of servant jobs must not be negative")
+	}
+	if o.Backoff < 0 {
+		return fmt.Errorf("the backoff of servant jobs must not be negative")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("the timeout of servant jobs must be positive")
+	}
+	return nil
+}
+
 // RunServantJobs launch servant jobs on specified nodes and wait all jobs to finish.
+// At most opts.Parallelism jobs are running at the same time, and the failed job of a node
+// is deleted and rerun until it succeeds or opts.RetryLimit is reached.
 // Succeed jobs will be deleted when finished. Failed jobs are preserved for diagnosis.
 func RunServantJobs(
 	cliSet kubeclientset.Interface,
-	waitServantJobTimeout time.Duration,
+	opts ServantJobOptions,
 	getJob func(nodeName string) (*batchv1.Job, error),
 	nodeNames []string, ww io.Writer,
 	waitForTimeout bool) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
 	var wg sync.WaitGroup
 
 	jobByNodeName := make(map[string]*batchv1.Job)
@@ -283,6 +328,11 @@ func RunServantJobs(
 		jobByNodeName[nodeName] = job
 	}
 
+	// sem limits the number of running jobs, it's nil if the parallelism is not limited
+	var sem chan struct{}
+	if opts.Parallelism > 0 {
+		sem = make(chan struct{}, opts.Parallelism)
+	}
 	res := make(chan string, len(nodeNames))
 	errCh := make(chan error, len(nodeNames))
 	for _, nodeName := range nodeNames {
@@ -290,7 +340,11 @@ func RunServantJobs(
 		job := jobByNodeName[nodeName]
 		go func() {
 			defer wg.Done()
-			if err := RunJobAndCleanup(cliSet, job, waitServantJobTimeout, CheckServantJobPeriod, waitForTimeout); err != nil {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			if err := runServantJobWithRetry(cliSet, job, opts, waitForTimeout); err != nil {
 				errCh <- fmt.Errorf("[ERROR] fail to run servant job(%s): %w", job.GetName(), err)
 			} else {
 				res <- fmt.Sprintf("\t[INFO] servant job(%s) has succeeded\n", job.GetName())
@@ -315,6 +369,42 @@ func RunServantJobs(
 	return nil
 }
 
+// runServantJobWithRetry runs the job until it succeeds or the retry limit is reached,
+// the failed job is deleted before it's rerun.
+func runServantJobWithRetry(cliSet kubeclientset.Interface, job *batchv1.Job, opts ServantJobOptions, waitForTimeout bool) error {
+	backoff := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := RunJobAndCleanup(cliSet, job.DeepCopy(), opts.Timeout, CheckServantJobPeriod, waitForTimeout)
+		if err == nil || attempt >= opts.RetryLimit {
+			return err
+		}
+
+		klog.Warningf("servant job(%s) failed, retry(%d/%d) after %s, %v", job.GetName(), attempt+1, opts.RetryLimit, backoff, err)
+		if err := deleteJobAndWait(cliSet, job, opts.Timeout); err != nil {
+			return fmt.Errorf("fail to delete failed job before retry, %w", err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deleteJobAndWait deletes the job and its pods, and waits until the job is removed
+func deleteJobAndWait(cliSet kubeclientset.Interface, job *batchv1.Job, timeout time.Duration) error {
+	err := cliSet.BatchV1().Jobs(job.GetNamespace()).Delete(context.Background(), job.GetName(), metav1.DeleteOptions{
+		PropagationPolicy: &PropagationPolicy,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return wait.PollImmediate(CheckServantJobPeriod, timeout, func() (bool, error) {
+		_, err := cliSet.BatchV1().Jobs(job.GetNamespace()).Get(context.Background(), job.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
 func GetOrCreateJoinTokenString(cliSet kubeclientset.Interface) (string, error) {
 	tokenSelector := fields.SelectorFromSet(
 		map[string]string{
@@ -17,14 +17,20 @@ limitations under the License.
 package kubernetes
 
 import (
+	"bytes"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 
 	"github.com/openyurtio/openyurt/pkg/projectinfo"
 	"github.com/openyurtio/openyurt/test/e2e/cmd/init/constants"
@@ -432,7 +438,7 @@ func TestRunServantJobs(t *testing.T) {
 
 	for _, v := range cases {
 		fakeKubeClient := clientsetfake.NewSimpleClientset()
-		err := RunServantJobs(fakeKubeClient, time.Second, func(nodeName string) (*batchv1.Job, error) {
+		err := RunServantJobs(fakeKubeClient, ServantJobOptions{Timeout: time.Second}, func(nodeName string) (*batchv1.Job, error) {
 			return nodeservant.RenderNodeServantJob("convert", convertCtx, nodeName)
 		}, v.nodeName, ww, false)
 		if err != nil {
@@ -441,3 +447,92 @@ func TestRunServantJobs(t *testing.T) {
 	}
 }
 */
+
+func TestRunServantJobsWithOptions(t *testing.T) {
+	period := CheckServantJobPeriod
+	CheckServantJobPeriod = time.Millisecond
+	defer func() { CheckServantJobPeriod = period }()
+
+	completions := int32(1)
+	getJob := func(nodeName string) (*batchv1.Job, error) {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: SystemNamespace, Name: "node-servant-convert-" + nodeName},
+			Spec:       batchv1.JobSpec{Completions: &completions},
+			Status:     batchv1.JobStatus{Succeeded: 1},
+		}, nil
+	}
+
+	cases := map[string]struct {
+		opts     ServantJobOptions
+		failures map[string]int
+		wantErr  bool
+	}{
+		"limit parallelism": {
+			opts: ServantJobOptions{Parallelism: 2, Timeout: time.Second},
+		},
+		"retry failed jobs": {
+			opts:     ServantJobOptions{Parallelism: 2, RetryLimit: 2, Backoff: time.Millisecond, Timeout: time.Second},
+			failures: map[string]int{"node-servant-convert-node1": 2, "node-servant-convert-node3": 1},
+		},
+		"retry limit reached": {
+			opts:     ServantJobOptions{RetryLimit: 1, Backoff: time.Millisecond, Timeout: time.Second},
+			failures: map[string]int{"node-servant-convert-node2": 2},
+			wantErr:  true,
+		},
+		"invalid options": {
+			opts:    ServantJobOptions{Parallelism: -1, Timeout: time.Second},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := clientsetfake.NewSimpleClientset()
+			var lock sync.Mutex
+			var running, maxRunning int
+			failing := make(map[string]bool)
+			client.PrependReactor("create", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				name := action.(clienttesting.CreateAction).GetObject().(*batchv1.Job).Name
+				failing[name] = tc.failures[name] > 0
+				if failing[name] {
+					tc.failures[name]--
+				}
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				return false, nil, nil
+			})
+			client.PrependReactor("delete", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				running--
+				return false, nil, nil
+			})
+			// the job fails by disappearing during waiting in the first failures[name] attempts
+			client.PrependReactor("get", "jobs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				name := action.(clienttesting.GetAction).GetName()
+				if failing[name] {
+					return true, nil, apierrors.NewNotFound(batchv1.Resource("jobs"), name)
+				}
+				return false, nil, nil
+			})
+
+			var out bytes.Buffer
+			err := RunServantJobs(client, tc.opts, getJob, []string{"node1", "node2", "node3", "node4", "node5"}, &out, false)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expect error %v, but got %v", tc.wantErr, err)
+			}
+			if tc.opts.Parallelism > 0 && maxRunning > tc.opts.Parallelism {
+				t.Errorf("expect at most %d running jobs, but got %d", tc.opts.Parallelism, maxRunning)
+			}
+			if !tc.wantErr && strings.Count(out.String(), "has succeeded") != 5 {
+				t.Errorf("expect all jobs succeeded, but got %q", out.String())
+			}
+		})
+	}
+}